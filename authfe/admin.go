@@ -54,6 +54,7 @@ func adminRoot(w http.ResponseWriter, r *http.Request) {
 			<li><a href="/admin/cortex/ruler_ring">Cortex Ruler Ring</a></li>
 			<li><a href="/admin/cortex/alertmanager/status">Cortex Alertmanager Status</a></li>
 			<li><a href="/admin/cortex/all_user_stats">Cortex user stats</a></li>
+			<li><a href="/admin/authfe/routes">Authfe Routes</a></li>
 			<li>Billing
 				<ul>
 					<li><a href="/admin/billing/organizations">Organizations</a></li>
@@ -61,6 +62,7 @@ func adminRoot(w http.ResponseWriter, r *http.Request) {
 					<li><a href="/admin/billing/uploader">Uploader</a></li>
 					<li><a href="/admin/billing/enforcer">Enforcer</a></li>
 					<li><a href="/admin/billing/invoice-verify">Invoice Verifier</a></li>
+					<li><a href="/admin/billing/reconciliation">Reconciliation</a></li>
 				</ul>
 			</li>
 			<li><a href="/admin/esh/?base_uri=/admin/elasticsearch/">Elasticsearch Head</a></li>
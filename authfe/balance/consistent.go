@@ -27,11 +27,14 @@ var _ Balancer = &consistentWrapper{}
 
 // NewSRVConsistent creates a load-balancer given a DNS SRV name like
 // _http._tcp.collectionh.scope.svc.cluster.local.
-func NewSRVConsistent(name, hostAndPort string, loadFactor float64) Balancer {
+// Unhealthy endpoints are excluded if health checking is enabled.
+func NewSRVConsistent(name, hostAndPort string, loadFactor float64, health HealthConfig) Balancer {
 	logger := gokitAdapter{i: logging.Global()}
 	// Poll DNS for updates every 5 seconds
 	instancer := dnssrv.NewInstancer(hostAndPort, 5*time.Second, logger)
-	return NewConsistentWrapper(name, instancer, loadFactor)
+	return NewHealthChecked(instancer, health, func(instancer sd.Instancer) Balancer {
+		return NewConsistentWrapper(name, instancer, loadFactor)
+	})
 }
 
 // NewConsistentWrapper creates a load-balancer given an instancer; mostly for testing.
@@ -105,6 +108,10 @@ func (c *consistentWrapper) Put(endpoint Endpoint) {
 	c.c.Put(endpoint)
 }
 
+func (c *consistentWrapper) Observe(Endpoint, bool) {
+	// no-op
+}
+
 func (c *consistentWrapper) Close() {
 	c.instancer.Deregister(c.ch)
 	close(c.ch)
@@ -0,0 +1,399 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/sd"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/logging"
+)
+
+const (
+	defaultEjectionTime        = 30 * time.Second
+	defaultMaxEjectionTime     = 5 * time.Minute
+	defaultMaxEjectedPercent   = 50
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+)
+
+// HealthConfig holds the configuration for outlier detection and active health
+// checking of endpoints.
+type HealthConfig struct {
+	// Name used for metrics and reporting
+	Name string
+
+	// ConsecutiveFailures is the number of failed requests in a row (5xx
+	// responses or connection errors) after which an endpoint is ejected.
+	// Zero disables outlier detection.
+	ConsecutiveFailures int
+
+	// EjectionTime is how long an endpoint is ejected for the first time. Every
+	// subsequent ejection doubles it, up to MaxEjectionTime.
+	// Defaults to 30s and 5m.
+	EjectionTime    time.Duration
+	MaxEjectionTime time.Duration
+
+	// MaxEjectedPercent is the maximum percentage of endpoints that can be
+	// ejected at any one time, so that a failure of the whole service doesn't
+	// leave us with nowhere to send requests. At least one endpoint is always
+	// kept. Defaults to 50.
+	MaxEjectedPercent int
+
+	// HealthCheckPath, when set, enables active health checking: every
+	// HealthCheckInterval each endpoint is sent a GET for this path, and is
+	// ejected for as long as it fails to respond with a 2xx within
+	// HealthCheckTimeout. Defaults to 10s and 2s.
+	HealthCheckPath     string
+	HealthCheckInterval time.Duration
+	HealthCheckTimeout  time.Duration
+}
+
+// Enabled returns true if either outlier detection or active health checking is configured.
+func (cfg HealthConfig) Enabled() bool {
+	return cfg.ConsecutiveFailures > 0 || cfg.HealthCheckPath != ""
+}
+
+// Prometheus metrics
+var (
+	ejectedEndpointsGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: filename,
+		Name:      "lb_endpoints_ejected",
+		Help:      "Number of endpoints currently ejected from this service.",
+	}, []string{"name"})
+	ejectionsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: filename,
+		Name:      "lb_ejections_total",
+		Help:      "Number of times an endpoint was ejected, by reason.",
+	}, []string{"name", "reason"})
+)
+
+// Per-endpoint health state.
+type endpointHealth struct {
+	consecutiveFailures int
+	ejections           int // Number of ejections since the endpoint last served a request successfully.
+	ejectedUntil        time.Time
+	probeFailed         bool
+}
+
+func (h *endpointHealth) healthy(now time.Time) bool {
+	return !h.probeFailed && !now.Before(h.ejectedUntil)
+}
+
+// HealthInstancer wraps an sd.Instancer, removing unhealthy instances from the
+// events it passes on. Since both load-balancing algorithms consume an
+// Instancer, they don't need to know anything about endpoint health.
+type HealthInstancer struct {
+	cfg    HealthConfig
+	client *http.Client
+	now    func() time.Time
+
+	upstream sd.Instancer
+	ch       chan sd.Event
+	changed  chan struct{}
+	quit     chan struct{}
+	wg       sync.WaitGroup
+
+	mtx         sync.Mutex
+	instances   []string // As last reported by upstream.
+	err         error
+	health      map[string]*endpointHealth
+	subscribers map[chan<- sd.Event]struct{}
+}
+
+var _ sd.Instancer = &HealthInstancer{}
+
+// NewHealthInstancer creates a HealthInstancer filtering the instances reported by upstream.
+func NewHealthInstancer(upstream sd.Instancer, cfg HealthConfig) *HealthInstancer {
+	if cfg.EjectionTime <= 0 {
+		cfg.EjectionTime = defaultEjectionTime
+	}
+	if cfg.MaxEjectionTime < cfg.EjectionTime {
+		cfg.MaxEjectionTime = defaultMaxEjectionTime
+		if cfg.MaxEjectionTime < cfg.EjectionTime {
+			cfg.MaxEjectionTime = cfg.EjectionTime
+		}
+	}
+	if cfg.MaxEjectedPercent <= 0 || cfg.MaxEjectedPercent > 100 {
+		cfg.MaxEjectedPercent = defaultMaxEjectedPercent
+	}
+	if cfg.HealthCheckInterval <= 0 {
+		cfg.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if cfg.HealthCheckTimeout <= 0 {
+		cfg.HealthCheckTimeout = defaultHealthCheckTimeout
+	}
+	h := &HealthInstancer{
+		cfg:         cfg,
+		client:      &http.Client{Timeout: cfg.HealthCheckTimeout},
+		now:         time.Now,
+		upstream:    upstream,
+		ch:          make(chan sd.Event),
+		changed:     make(chan struct{}, 1),
+		quit:        make(chan struct{}),
+		health:      make(map[string]*endpointHealth),
+		subscribers: make(map[chan<- sd.Event]struct{}),
+	}
+	ejectedEndpointsGauge.WithLabelValues(cfg.Name).Set(0)
+	h.wg.Add(1)
+	go h.loop()
+	upstream.Register(h.ch)
+	if cfg.HealthCheckPath != "" {
+		h.wg.Add(1)
+		go h.probeLoop()
+	}
+	return h
+}
+
+// Register implements sd.Instancer.
+func (h *HealthInstancer) Register(ch chan<- sd.Event) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.subscribers[ch] = struct{}{}
+	ch <- h.eventLocked()
+}
+
+// Deregister implements sd.Instancer.
+func (h *HealthInstancer) Deregister(ch chan<- sd.Event) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	delete(h.subscribers, ch)
+}
+
+// Stop implements sd.Instancer.
+func (h *HealthInstancer) Stop() {
+	h.upstream.Deregister(h.ch)
+	close(h.quit)
+	h.wg.Wait()
+}
+
+// Observe records the outcome of a request to an endpoint.
+func (h *HealthInstancer) Observe(endpoint Endpoint, success bool) {
+	if h.cfg.ConsecutiveFailures <= 0 {
+		return
+	}
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	eh, ok := h.health[endpoint.Key()]
+	if !ok {
+		// Not (or no longer) known to upstream
+		return
+	}
+	now := h.now()
+	if success {
+		eh.consecutiveFailures = 0
+		if eh.healthy(now) {
+			eh.ejections = 0
+		}
+		return
+	}
+	eh.consecutiveFailures++
+	if eh.consecutiveFailures < h.cfg.ConsecutiveFailures || !eh.healthy(now) || !h.canEjectLocked(now) {
+		return
+	}
+
+	ejectionTime := h.cfg.EjectionTime << uint(eh.ejections)
+	if ejectionTime > h.cfg.MaxEjectionTime || ejectionTime <= 0 {
+		ejectionTime = h.cfg.MaxEjectionTime
+	}
+	eh.ejections++
+	eh.consecutiveFailures = 0
+	eh.ejectedUntil = now.Add(ejectionTime)
+	logging.Global().Warnf("balance: %s: ejecting endpoint %s for %s after %d consecutive failures", h.cfg.Name, endpoint.Key(), ejectionTime, h.cfg.ConsecutiveFailures)
+	ejectionsCounter.WithLabelValues(h.cfg.Name, "outlier").Inc()
+	h.notify()
+	time.AfterFunc(ejectionTime, h.notify)
+}
+
+// canEjectLocked returns true if one more endpoint may be ejected without
+// exceeding MaxEjectedPercent.
+func (h *HealthInstancer) canEjectLocked(now time.Time) bool {
+	ejected := 0
+	for _, instance := range h.instances {
+		if !h.health[instance].healthy(now) {
+			ejected++
+		}
+	}
+	if ejected+1 >= len(h.instances) {
+		return false
+	}
+	return (ejected+1)*100 <= len(h.instances)*h.cfg.MaxEjectedPercent
+}
+
+// notify asks the loop to recompute and broadcast the set of healthy instances.
+func (h *HealthInstancer) notify() {
+	select {
+	case h.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (h *HealthInstancer) loop() {
+	defer h.wg.Done()
+	for {
+		select {
+		case event := <-h.ch:
+			h.update(event)
+		case <-h.changed:
+			h.broadcast()
+		case <-h.quit:
+			return
+		}
+	}
+}
+
+func (h *HealthInstancer) update(event sd.Event) {
+	h.mtx.Lock()
+	if event.Err != nil {
+		// Keep using the previous instances; pass the error on to subscribers.
+		h.err = event.Err
+	} else {
+		h.err = nil
+		h.instances = event.Instances
+		known := make(map[string]*endpointHealth, len(event.Instances))
+		for _, instance := range event.Instances {
+			if eh, ok := h.health[instance]; ok {
+				known[instance] = eh
+			} else {
+				known[instance] = &endpointHealth{}
+			}
+		}
+		h.health = known
+	}
+	h.mtx.Unlock()
+	h.broadcast()
+}
+
+func (h *HealthInstancer) broadcast() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	event := h.eventLocked()
+	for ch := range h.subscribers {
+		ch <- event
+	}
+}
+
+// eventLocked returns the event to send to subscribers: all healthy instances.
+func (h *HealthInstancer) eventLocked() sd.Event {
+	if h.err != nil {
+		return sd.Event{Err: h.err}
+	}
+	now := h.now()
+	healthy := make([]string, 0, len(h.instances))
+	for _, instance := range h.instances {
+		if h.health[instance].healthy(now) {
+			healthy = append(healthy, instance)
+		}
+	}
+	if len(healthy) == 0 {
+		// Better to try unhealthy endpoints than to have none at all.
+		healthy = h.instances
+	}
+	ejectedEndpointsGauge.WithLabelValues(h.cfg.Name).Set(float64(len(h.instances) - len(healthy)))
+	return sd.Event{Instances: healthy}
+}
+
+func (h *HealthInstancer) probeLoop() {
+	defer h.wg.Done()
+	ticker := time.NewTicker(h.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.probeAll()
+		case <-h.quit:
+			return
+		}
+	}
+}
+
+func (h *HealthInstancer) probeAll() {
+	h.mtx.Lock()
+	instances := h.instances
+	h.mtx.Unlock()
+
+	results := make([]error, len(instances))
+	var wg sync.WaitGroup
+	wg.Add(len(instances))
+	for i, instance := range instances {
+		go func(i int, instance string) {
+			defer wg.Done()
+			results[i] = h.probe(instance)
+		}(i, instance)
+	}
+	wg.Wait()
+
+	changed := false
+	h.mtx.Lock()
+	for i, instance := range instances {
+		eh, ok := h.health[instance]
+		if !ok {
+			continue
+		}
+		failed := results[i] != nil
+		if failed == eh.probeFailed {
+			continue
+		}
+		if failed {
+			logging.Global().Warnf("balance: %s: ejecting endpoint %s: health check failed: %v", h.cfg.Name, instance, results[i])
+			ejectionsCounter.WithLabelValues(h.cfg.Name, "health_check").Inc()
+		} else {
+			logging.Global().Infof("balance: %s: endpoint %s passed health check", h.cfg.Name, instance)
+		}
+		eh.probeFailed = failed
+		changed = true
+	}
+	h.mtx.Unlock()
+	if changed {
+		h.broadcast()
+	}
+}
+
+func (h *HealthInstancer) probe(instance string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.cfg.HealthCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequest("GET", fmt.Sprintf("http://%s%s", instance, h.cfg.HealthCheckPath), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := h.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NewHealthChecked creates a load-balancer with factory, which will only be
+// told about the healthy instances from instancer. If health checking is not
+// enabled in cfg, the load-balancer sees all instances.
+func NewHealthChecked(instancer sd.Instancer, cfg HealthConfig, factory func(sd.Instancer) Balancer) Balancer {
+	if !cfg.Enabled() {
+		return factory(instancer)
+	}
+	health := NewHealthInstancer(instancer, cfg)
+	return &healthChecked{Balancer: factory(health), health: health}
+}
+
+// healthChecked is a Balancer whose endpoints come from a HealthInstancer.
+type healthChecked struct {
+	Balancer
+	health *HealthInstancer
+}
+
+func (hc *healthChecked) Observe(endpoint Endpoint, success bool) {
+	hc.health.Observe(endpoint, success)
+}
+
+func (hc *healthChecked) Close() {
+	hc.Balancer.Close()
+	hc.health.Stop()
+}
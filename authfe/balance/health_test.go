@@ -0,0 +1,127 @@
+package balance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/sd"
+	"github.com/stretchr/testify/assert"
+)
+
+// Get n endpoints from a balancer, returning how many times each was picked.
+func distribution(t *testing.T, b Balancer, n int) map[string]int {
+	result := map[string]int{}
+	for i := 0; i < n; i++ {
+		e, err := b.Get("1")
+		assert.NoError(t, err)
+		b.Put(e)
+		result[e.Key()]++
+	}
+	return result
+}
+
+func TestOutlierEjection(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		factory func(sd.Instancer) Balancer
+	}{
+		{"round-robin", NewRoundRobin},
+		{"consistent", func(i sd.Instancer) Balancer { return NewConsistentWrapper("test", i, 0) }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewHealthChecked(sd.FixedInstancer{"a:80", "b:80", "c:80"}, HealthConfig{
+				Name:                "test",
+				ConsecutiveFailures: 3,
+				EjectionTime:        100 * time.Millisecond,
+				MaxEjectedPercent:   50,
+			}, test.factory)
+			defer b.Close()
+
+			// Fail whichever endpoint the balancer picks for our key
+			e, err := b.Get("1")
+			assert.NoError(t, err)
+			bad := e.Key()
+			b.Observe(e, false)
+			b.Observe(e, false)
+			b.Observe(e, true) // a success resets the count
+			b.Observe(e, false)
+			b.Observe(e, false)
+			assert.Contains(t, distribution(t, b, 30), bad)
+			b.Observe(e, false)
+
+			assert.NotContains(t, eventually(t, b, bad, false), bad)
+
+			// Ejecting a second endpoint would exceed MaxEjectedPercent
+			e, err = b.Get("1")
+			assert.NoError(t, err)
+			for i := 0; i < 3; i++ {
+				b.Observe(e, false)
+			}
+			time.Sleep(10 * time.Millisecond)
+			assert.Contains(t, distribution(t, b, 30), e.Key())
+
+			// The ejected endpoint comes back after EjectionTime
+			assert.Contains(t, eventually(t, b, bad, true), bad)
+		})
+	}
+}
+
+// eventually polls until the endpoint's presence in the balancer matches present,
+// returning the last distribution seen.
+func eventually(t *testing.T, b Balancer, key string, present bool) map[string]int {
+	var d map[string]int
+	for i := 0; i < 50; i++ {
+		d = distribution(t, b, 30)
+		if _, ok := d[key]; ok == present {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return d
+}
+
+func TestHealthCheck(t *testing.T) {
+	healthy := int32(1)
+	checked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/healthz", r.URL.Path)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer checked.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer other.Close()
+
+	checkedURL, _ := url.Parse(checked.URL)
+	otherURL, _ := url.Parse(other.URL)
+	b := NewHealthChecked(sd.FixedInstancer{checkedURL.Host, otherURL.Host}, HealthConfig{
+		Name:                "test",
+		HealthCheckPath:     "/healthz",
+		HealthCheckInterval: 10 * time.Millisecond,
+	}, NewRoundRobin)
+	defer b.Close()
+
+	assert.Contains(t, distribution(t, b, 10), checkedURL.Host)
+	atomic.StoreInt32(&healthy, 0)
+	assert.NotContains(t, eventually(t, b, checkedURL.Host, false), checkedURL.Host)
+	atomic.StoreInt32(&healthy, 1)
+	assert.Contains(t, eventually(t, b, checkedURL.Host, true), checkedURL.Host)
+}
+
+func TestHealthCheckKeepsLastEndpoint(t *testing.T) {
+	b := NewHealthChecked(sd.FixedInstancer{"a:80"}, HealthConfig{
+		Name:                "test",
+		ConsecutiveFailures: 1,
+	}, NewRoundRobin)
+	defer b.Close()
+
+	e, err := b.Get("1")
+	assert.NoError(t, err)
+	b.Observe(e, false)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, map[string]int{"a:80": 10}, distribution(t, b, 10))
+}
@@ -22,6 +22,8 @@ type Balancer interface {
 	Get(key string) (Endpoint, error)
 	// Put releases the Endpoint when it has finished processing the request.
 	Put(endpoint Endpoint)
+	// Observe records whether a request to the Endpoint succeeded, for outlier detection.
+	Observe(endpoint Endpoint, success bool)
 	// Shut down any goroutines, dispose of any resources
 	Close()
 }
@@ -39,11 +41,12 @@ var _ Balancer = &roundRobin{}
 
 // NewSRVRoundRobin creates a load-balancer given a DNS SRV name like
 // _http._tcp.collectionh.scope.svc.cluster.local.
-func NewSRVRoundRobin(hostAndPort string) Balancer {
+// Unhealthy endpoints are excluded if health checking is enabled.
+func NewSRVRoundRobin(hostAndPort string, health HealthConfig) Balancer {
 	logger := gokitAdapter{i: logging.Global()}
 	// Poll DNS for updates every 5 seconds
 	instancer := dnssrv.NewInstancer(hostAndPort, 5*time.Second, logger)
-	return NewRoundRobin(instancer)
+	return NewHealthChecked(instancer, health, NewRoundRobin)
 }
 
 // NewRoundRobin creates a load-balancer given an instancer; mostly for testing.
@@ -85,6 +88,10 @@ func (rr *roundRobin) Put(Endpoint) {
 	// no-op
 }
 
+func (rr *roundRobin) Observe(Endpoint, bool) {
+	// no-op
+}
+
 func (rr *roundRobin) Close() {
 	rr.endpointer.Close()
 }
@@ -21,7 +21,6 @@ type Config struct {
 	authType              string
 	authURL               string
 	externalUI            bool
-	fluentHost            string
 	gcpWebhookSecret      string
 	listen, privateListen string
 	logLevel              string
@@ -40,6 +39,17 @@ type Config struct {
 	// External hostnames
 	launcherServiceExternalHost string
 
+	// Per-organization rate limits for probe routes
+	uploadRateLimit rateLimitConfig
+	accessRateLimit rateLimitConfig
+
+	// Analytics events
+	events eventsConfig
+
+	// Declarative routes, in addition to the built-in ones
+	routeTableFile           string
+	routeTableReloadInterval time.Duration
+
 	// User-visible services - keep alphabetically sorted pls
 	billingAPIHost         proxyConfig
 	billingUIHost          proxyConfig
@@ -169,7 +179,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&c.authType, "authenticator", "web", "What authenticator to use: web | grpc | mock")
 	f.StringVar(&c.authURL, "authenticator.url", "users:4772", "Where to find the authenticator service")
 	f.BoolVar(&c.externalUI, "externalUI", true, "Point to externally hosted static UI assets")
-	f.StringVar(&c.fluentHost, "fluent", "", "Hostname & port for fluent")
+	c.events.RegisterFlags(f)
 	f.StringVar(&c.listen, "listen", ":80", "HTTP server listen address")
 	f.StringVar(&c.privateListen, "private-listen", ":8080", "HTTP server listen address (private endpoints)")
 	f.StringVar(&c.logLevel, "log.level", "info", "Logging level to use: debug | info | warn | error")
@@ -188,6 +198,14 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	// External hostnames
 	f.StringVar(&c.launcherServiceExternalHost, "launcher-service-external-host", "get.weave.works", "External hostname used for the launcher service")
 
+	// Rate limits
+	c.uploadRateLimit.RegisterFlags("ratelimit.upload", f)
+	c.accessRateLimit.RegisterFlags("ratelimit.access", f)
+
+	// Route table
+	f.StringVar(&c.routeTableFile, "routes.file", "", "YAML or JSON file of routes to serve in addition to, and in preference to, the built-in ones")
+	f.DurationVar(&c.routeTableReloadInterval, "routes.reload-interval", 10*time.Second, "How often to check the routes file for changes (0 disables reloading)")
+
 	for name, proxyCfg := range c.proxies() {
 		proxyCfg.RegisterFlags(name, f)
 	}
@@ -213,6 +231,10 @@ type proxyConfig struct {
 	protocol    string
 	readOnly    bool
 	loadFactor  float64
+	health      balance.HealthConfig
+	retries     int
+	hedgeAfter  time.Duration
+	retryBudget float64
 
 	// Set this based on the flags
 	http.Handler
@@ -225,4 +247,15 @@ func (p *proxyConfig) RegisterFlags(name string, f *flag.FlagSet) {
 	f.StringVar(&p.protocol, name+".protocol", "http", fmt.Sprintf("Protocol to connect to this %s service via (Must be: http or https)", name))
 	f.BoolVar(&p.readOnly, name+".readonly", false, fmt.Sprintf("Make %s service, read-only (will only accept GETs)", name))
 	f.Float64Var(&p.loadFactor, name+".load-factor", 0, fmt.Sprintf("Use bounded-load consistent balancing for %s service, with max load on one endpoint this times average", name))
+	f.IntVar(&p.retries, name+".retries", 0, fmt.Sprintf("Number of times to retry idempotent requests to %s service which fail to connect, against another endpoint", name))
+	f.DurationVar(&p.hedgeAfter, name+".hedge-after", 0, fmt.Sprintf("Send a second, hedged, idempotent request to another %s service endpoint if there is no response within this time (0 disables hedging)", name))
+	f.Float64Var(&p.retryBudget, name+".retry-budget", 0.1, fmt.Sprintf("Maximum ratio of retried and hedged to original requests for %s service", name))
+	p.health.Name = name
+	f.IntVar(&p.health.ConsecutiveFailures, name+".outlier.consecutive-failures", 0, fmt.Sprintf("Eject %s service endpoints after this many consecutive 5xx responses or connection errors (0 disables outlier detection)", name))
+	f.DurationVar(&p.health.EjectionTime, name+".outlier.ejection-time", 30*time.Second, fmt.Sprintf("How long to eject %s service endpoints for; doubled on each subsequent ejection", name))
+	f.DurationVar(&p.health.MaxEjectionTime, name+".outlier.max-ejection-time", 5*time.Minute, fmt.Sprintf("Maximum time to eject %s service endpoints for", name))
+	f.IntVar(&p.health.MaxEjectedPercent, name+".outlier.max-ejected-percent", 50, fmt.Sprintf("Maximum percentage of %s service endpoints which can be ejected at once", name))
+	f.StringVar(&p.health.HealthCheckPath, name+".healthcheck.path", "", fmt.Sprintf("Path to actively health check %s service endpoints on (empty disables health checks)", name))
+	f.DurationVar(&p.health.HealthCheckInterval, name+".healthcheck.interval", 10*time.Second, fmt.Sprintf("How often to health check %s service endpoints", name))
+	f.DurationVar(&p.health.HealthCheckTimeout, name+".healthcheck.timeout", 2*time.Second, fmt.Sprintf("Timeout for health checks of %s service endpoints", name))
 }
@@ -2,95 +2,130 @@ package main
 
 import (
 	"fmt"
-	"net"
 	"net/http"
-	"strconv"
-	"strings"
 	"time"
 
-	"github.com/fluent/fluent-logger-golang/fluent"
 	log "github.com/sirupsen/logrus"
 )
 
-const maxBufferedEvents = 1000
+const (
+	maxBufferedEvents = 1000
+	// Retries of a batch of events before it is discarded, backing off
+	// exponentially from initialSinkBackoff.
+	maxSinkRetries     = 8
+	initialSinkBackoff = 100 * time.Millisecond
+)
 
 // Event is a user event to be sent to out analytics system
 type Event struct {
-	ID             string `msg:"event"`
-	SessionID      string `msg:"session_id"`
-	Product        string `msg:"product"`
-	Version        string `msg:"version"`
-	UserAgent      string `msg:"user_agent"`
-	ClientID       string `msg:"client_id"`
-	OrganizationID string `msg:"org_id"`
-	UserID         string `msg:"user_id"`
-	IPAddress      string `msg:"ip_address"`
-	Values         string `msg:"values"`
+	ID             string `msg:"event" json:"event"`
+	SessionID      string `msg:"session_id" json:"session_id"`
+	Product        string `msg:"product" json:"product"`
+	Version        string `msg:"version" json:"version"`
+	UserAgent      string `msg:"user_agent" json:"user_agent"`
+	ClientID       string `msg:"client_id" json:"client_id"`
+	OrganizationID string `msg:"org_id" json:"org_id"`
+	UserID         string `msg:"user_id" json:"user_id"`
+	IPAddress      string `msg:"ip_address" json:"ip_address"`
+	Values         string `msg:"values" json:"values"`
+}
+
+// record returns the event as a fluentd record.
+func (e *Event) record() map[string]interface{} {
+	return map[string]interface{}{
+		"event":      e.ID,
+		"session_id": e.SessionID,
+		"product":    e.Product,
+		"version":    e.Version,
+		"user_agent": e.UserAgent,
+		"client_id":  e.ClientID,
+		"org_id":     e.OrganizationID,
+		"user_id":    e.UserID,
+		"ip_address": e.IPAddress,
+		"values":     e.Values,
+	}
 }
 
 // TimedEvent tracks the time an event occurred
 type TimedEvent struct {
-	Event *Event
-	Time  time.Time
+	Event *Event    `json:"event"`
+	Time  time.Time `json:"time"`
 }
 
-// EventLogger logs events to the analytics system
+// EventLogger logs events to the analytics system, sending them to a sink in batches.
 type EventLogger struct {
-	stop   chan struct{}
-	events chan TimedEvent
-	logger *fluent.Fluent
+	stop          chan struct{}
+	done          chan struct{}
+	events        chan TimedEvent
+	sink          EventSink
+	batchSize     int
+	flushInterval time.Duration
 }
 
 // NewEventLogger creates a new EventLogger.
-func NewEventLogger(fluentHostPort string) (*EventLogger, error) {
-	host, port, err := net.SplitHostPort(fluentHostPort)
-	if err != nil {
-		return nil, err
-	}
-	intPort, err := strconv.Atoi(port)
-	if err != nil {
-		return nil, err
+func NewEventLogger(sink EventSink, batchSize int, flushInterval time.Duration) *EventLogger {
+	if batchSize <= 0 {
+		batchSize = 1
 	}
-	logger, err := fluent.New(fluent.Config{
-		FluentPort:   intPort,
-		FluentHost:   host,
-		AsyncConnect: true,
-		MaxRetry:     -1,
-	})
-	if err != nil {
-		return nil, err
-	}
-
 	el := &EventLogger{
-		stop:   make(chan struct{}),
-		events: make(chan TimedEvent, maxBufferedEvents),
-		logger: logger,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		events:        make(chan TimedEvent, maxBufferedEvents),
+		sink:          sink,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
 	}
 	go el.logLoop()
-	return el, nil
+	return el
 }
 
-func (el *EventLogger) post(e TimedEvent) {
-	err := el.logger.PostWithTime("events", e.Time, *e.Event)
-	if err != nil {
-		log.Warnf("EventLogger: failed to log event: %v, %v", *e.Event, err)
-		// Fluentd takes a few seconds to start up
-		for i := 0; i < 10 && strings.Contains(err.Error(), "connecting"); i++ {
-			time.Sleep(1 * time.Second)
-			err = el.logger.PostWithTime("events", e.Time, *e.Event)
-			if err == nil {
-				return
-			}
+// post sends a batch of events to the sink, retrying with backoff
+// unless we're stopping.
+func (el *EventLogger) post(batch []TimedEvent) {
+	backoff := initialSinkBackoff
+	for i := 0; ; i++ {
+		err := el.sink.Write(batch)
+		if err == nil {
+			return
 		}
-		log.Warnf("EventLogger: failed to log event: %v, %v", *e.Event, err)
+		if i == maxSinkRetries {
+			eventsDiscardedCount.Add(float64(len(batch)))
+			log.Warnf("EventLogger: failed to log %d events, discarding: %v", len(batch), err)
+			return
+		}
+		log.Debugf("EventLogger: failed to log %d events, retrying in %s: %v", len(batch), backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-el.stop:
+			eventsDiscardedCount.Add(float64(len(batch)))
+			log.Warnf("EventLogger: stopping, discarding %d events: %v", len(batch), err)
+			return
+		}
+		backoff *= 2
 	}
 }
 
 func (el *EventLogger) logLoop() {
+	defer close(el.done)
+	ticker := time.NewTicker(el.flushInterval)
+	defer ticker.Stop()
+
+	var batch []TimedEvent
+	flush := func() {
+		if len(batch) > 0 {
+			el.post(batch)
+			batch = nil
+		}
+	}
 	for done := false; !done; {
 		select {
 		case event := <-el.events:
-			el.post(event)
+			batch = append(batch, event)
+			if len(batch) >= el.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
 		case <-el.stop:
 			done = true
 		}
@@ -100,18 +135,25 @@ func (el *EventLogger) logLoop() {
 	for done := false; !done; {
 		select {
 		case event := <-el.events:
-			el.post(event)
+			batch = append(batch, event)
+			if len(batch) >= el.batchSize {
+				flush()
+			}
 		default:
 			done = true
 		}
 	}
+	flush()
 
-	el.logger.Close()
+	if err := el.sink.Close(); err != nil {
+		log.Warnf("EventLogger: error closing sink: %v", err)
+	}
 }
 
-// Close closes and deallocates the event logger
+// Close flushes any buffered events, and closes the sink
 func (el *EventLogger) Close() error {
 	close(el.stop)
+	<-el.done
 	return nil
 }
 
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fluent/fluent-logger-golang/fluent"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/weaveworks/service/common"
+)
+
+const fluentTag = "events"
+
+var eventSinkErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: common.PrometheusNamespace,
+	Name:      "event_sink_errors_total",
+	Help:      "Total number of failed attempts to write a batch of events to a sink.",
+}, []string{"sink"})
+
+func init() {
+	prometheus.MustRegister(eventSinkErrors)
+}
+
+// EventSink is where the EventLogger sends batches of events.
+type EventSink interface {
+	// Write writes a batch of events. If an error is returned, some of the
+	// events may have been written.
+	Write(events []TimedEvent) error
+	Close() error
+}
+
+type eventsConfig struct {
+	// Values set by flags.
+	sink          string
+	fluentHost    string
+	batchSize     int
+	flushInterval time.Duration
+	spoolDir      string
+	spoolMaxBytes int64
+	spoolRetry    time.Duration
+}
+
+func (c *eventsConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&c.fluentHost, "fluent", "", "Hostname & port for fluent")
+	f.StringVar(&c.sink, "events.sink", "fluent", "Where to send events: fluent (requires -fluent), or stdout as JSON lines")
+	f.IntVar(&c.batchSize, "events.batch-size", 100, "Maximum number of events to send in one batch")
+	f.DurationVar(&c.flushInterval, "events.flush-interval", 1*time.Second, "Maximum time to wait before sending a partial batch of events")
+	f.StringVar(&c.spoolDir, "events.spool.dir", "", "Directory in which to spool events before sending them, so they survive sink outages (empty disables spooling)")
+	f.Int64Var(&c.spoolMaxBytes, "events.spool.max-size", 100*1024*1024, "Maximum size of the event spool in bytes; the oldest events are discarded beyond this")
+	f.DurationVar(&c.spoolRetry, "events.spool.retry-interval", 5*time.Second, "How long to wait before retrying to send spooled events after a failure")
+}
+
+// enabled returns true if events should be logged at all.
+func (c eventsConfig) enabled() bool {
+	return c.sink == "stdout" || c.fluentHost != ""
+}
+
+// newEventSink builds the sink described by the config.
+func newEventSink(c eventsConfig, stdout io.Writer) (EventSink, error) {
+	var sink EventSink
+	switch c.sink {
+	case "fluent":
+		if c.fluentHost == "" {
+			return nil, fmt.Errorf("no fluent host given")
+		}
+		sink = newFluentSink(c.fluentHost)
+	case "stdout":
+		sink = newJSONLinesSink(stdout)
+	default:
+		return nil, fmt.Errorf("unknown event sink %q", c.sink)
+	}
+	if c.spoolDir != "" {
+		return newSpoolSink(c.spoolDir, c.spoolMaxBytes, c.batchSize, c.spoolRetry, sink)
+	}
+	return sink, nil
+}
+
+// fluentSink sends events to fluentd using the forward protocol, one
+// message per batch.
+type fluentSink struct {
+	hostPort string
+	timeout  time.Duration
+
+	mtx  sync.Mutex
+	conn net.Conn
+}
+
+func newFluentSink(hostPort string) *fluentSink {
+	return &fluentSink{
+		hostPort: hostPort,
+		timeout:  3 * time.Second,
+	}
+}
+
+// Write implements EventSink
+func (s *fluentSink) Write(events []TimedEvent) error {
+	msg := fluent.Forward{Tag: fluentTag}
+	for _, e := range events {
+		msg.Entries = append(msg.Entries, fluent.Entry{Time: e.Time.Unix(), Record: e.Event.record()})
+	}
+	buf, err := msg.MarshalMsg(nil)
+	if err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.conn == nil {
+		conn, err := net.DialTimeout("tcp", s.hostPort, s.timeout)
+		if err != nil {
+			eventSinkErrors.WithLabelValues("fluent").Inc()
+			return err
+		}
+		s.conn = conn
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	if _, err := s.conn.Write(buf); err != nil {
+		eventSinkErrors.WithLabelValues("fluent").Inc()
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Close implements EventSink
+func (s *fluentSink) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// jsonLinesSink writes events to a writer, one JSON object per line.
+type jsonLinesSink struct {
+	mtx sync.Mutex
+	enc *json.Encoder
+}
+
+func newJSONLinesSink(w io.Writer) *jsonLinesSink {
+	return &jsonLinesSink{enc: json.NewEncoder(w)}
+}
+
+// Write implements EventSink
+func (s *jsonLinesSink) Write(events []TimedEvent) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, e := range events {
+		if err := s.enc.Encode(e); err != nil {
+			eventSinkErrors.WithLabelValues("stdout").Inc()
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implements EventSink
+func (s *jsonLinesSink) Close() error {
+	return nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fluent/fluent-logger-golang/fluent"
+	"github.com/stretchr/testify/assert"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// memorySink records events, failing while broken is set.
+type memorySink struct {
+	mtx     sync.Mutex
+	broken  bool
+	batches [][]TimedEvent
+	closed  bool
+}
+
+func (s *memorySink) Write(events []TimedEvent) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.broken {
+		return errors.New("broken")
+	}
+	s.batches = append(s.batches, events)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *memorySink) setBroken(broken bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.broken = broken
+}
+
+func (s *memorySink) ids() []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	ids := []string{}
+	for _, batch := range s.batches {
+		for _, e := range batch {
+			ids = append(ids, e.Event.ID)
+		}
+	}
+	return ids
+}
+
+// waitFor polls until cond is true, failing the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+	}
+}
+
+func eventIDs(from, to int) []string {
+	ids := []string{}
+	for i := from; i < to; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+	return ids
+}
+
+func timedEvents(from, to int) []TimedEvent {
+	var events []TimedEvent
+	for _, id := range eventIDs(from, to) {
+		events = append(events, TimedEvent{Event: &Event{ID: id, OrganizationID: "org"}, Time: time.Unix(1500000000, 0).UTC()})
+	}
+	return events
+}
+
+func TestEventLoggerBatches(t *testing.T) {
+	sink := &memorySink{}
+	el := NewEventLogger(sink, 3, time.Hour)
+	for _, id := range eventIDs(0, 7) {
+		assert.NoError(t, el.LogEvent(Event{ID: id}))
+	}
+	assert.NoError(t, el.Close())
+
+	// Two full batches, and the remainder flushed on close
+	assert.Len(t, sink.batches, 3)
+	assert.Equal(t, eventIDs(0, 7), sink.ids())
+	assert.True(t, sink.closed)
+	assert.Error(t, el.LogEvent(Event{ID: "late"}))
+}
+
+func TestEventLoggerRetries(t *testing.T) {
+	sink := &memorySink{broken: true}
+	el := NewEventLogger(sink, 1, time.Hour)
+	assert.NoError(t, el.LogEvent(Event{ID: "0"}))
+	time.Sleep(2 * initialSinkBackoff)
+	sink.setBroken(false)
+	waitFor(t, func() bool { return len(sink.ids()) == 1 })
+	el.Close()
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := newJSONLinesSink(&buf)
+	assert.NoError(t, sink.Write(timedEvents(0, 2)))
+	assert.Equal(t, `{"event":{"event":"0","session_id":"","product":"","version":"","user_agent":"","client_id":"","org_id":"org","user_id":"","ip_address":"","values":""},"time":"2017-07-14T02:40:00Z"}
+{"event":{"event":"1","session_id":"","product":"","version":"","user_agent":"","client_id":"","org_id":"org","user_id":"","ip_address":"","values":""},"time":"2017-07-14T02:40:00Z"}
+`, buf.String())
+}
+
+func TestFluentSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	received := make(chan fluent.Forward)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				r := msgp.NewReader(conn)
+				for {
+					var msg fluent.Forward
+					if err := msg.DecodeMsg(r); err != nil {
+						return
+					}
+					received <- msg
+				}
+			}()
+		}
+	}()
+
+	sink := newFluentSink(listener.Addr().String())
+	assert.NoError(t, sink.Write(timedEvents(0, 3)))
+	msg := <-received
+	assert.Equal(t, fluentTag, msg.Tag)
+	assert.Len(t, msg.Entries, 3)
+	assert.Equal(t, int64(1500000000), msg.Entries[0].Time)
+	record := msg.Entries[2].Record.(map[string]interface{})
+	assert.Equal(t, "2", record["event"])
+	assert.Equal(t, "org", record["org_id"])
+	assert.NoError(t, sink.Close())
+
+	// Errors once fluentd has gone away
+	listener.Close()
+	assert.Error(t, sink.Write(timedEvents(0, 1)))
+}
+
+func TestSpoolSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	next := &memorySink{}
+	spool, err := newSpoolSink(dir, 1<<20, 10, 10*time.Millisecond, next)
+	assert.NoError(t, err)
+
+	assert.NoError(t, spool.Write(timedEvents(0, 5)))
+	waitFor(t, func() bool { return len(next.ids()) == 5 })
+
+	// Events are kept while the next sink is down, and sent in order when it comes back
+	next.setBroken(true)
+	for i := 5; i < 30; i += 5 {
+		assert.NoError(t, spool.Write(timedEvents(i, i+5)))
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, next.ids(), 5)
+	next.setBroken(false)
+	waitFor(t, func() bool { return len(next.ids()) == 30 })
+	assert.Equal(t, eventIDs(0, 30), next.ids())
+	for _, batch := range next.batches {
+		assert.True(t, len(batch) <= 10)
+	}
+	assert.NoError(t, spool.Close())
+	assert.True(t, next.closed)
+
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestSpoolSinkRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Events spooled while the next sink is down are sent after a restart
+	spool, err := newSpoolSink(dir, 1<<20, 10, time.Hour, &memorySink{broken: true})
+	assert.NoError(t, err)
+	assert.NoError(t, spool.Write(timedEvents(0, 5)))
+	assert.NoError(t, spool.Close())
+
+	next := &memorySink{}
+	spool, err = newSpoolSink(dir, 1<<20, 10, 10*time.Millisecond, next)
+	assert.NoError(t, err)
+	waitFor(t, func() bool { return len(next.ids()) == 5 })
+	assert.Equal(t, eventIDs(0, 5), next.ids())
+	assert.NoError(t, spool.Close())
+}
+
+func TestSpoolSinkMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	line, err := json.Marshal(timedEvents(10, 11)[0])
+	assert.NoError(t, err)
+	eventSize := int64(len(line) + 1)
+
+	// Room for 16 events in segments of 2
+	next := &memorySink{broken: true}
+	spool, err := newSpoolSink(dir, 16*eventSize, 10, time.Hour, next)
+	assert.NoError(t, err)
+	for i := 0; i < 40; i++ {
+		assert.NoError(t, spool.Write(timedEvents(i, i+1)))
+	}
+	spool.mtx.Lock()
+	assert.True(t, spool.size() <= 16*eventSize)
+	spool.mtx.Unlock()
+
+	// Only the newest events are sent
+	next.setBroken(false)
+	assert.NoError(t, spool.send())
+	assert.Equal(t, eventIDs(24, 40), next.ids())
+	assert.NoError(t, spool.Close())
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/service/common"
+)
+
+const (
+	spoolSegmentSuffix = ".jsonl"
+	// The spool is split into this many segments, so that the oldest
+	// events can be discarded without rewriting the whole spool.
+	spoolSegmentsPerSpool = 8
+)
+
+var eventSpoolBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: common.PrometheusNamespace,
+	Name:      "event_spool_bytes",
+	Help:      "Size of the events spooled on disk waiting to be sent.",
+})
+
+func init() {
+	prometheus.MustRegister(eventSpoolBytes)
+}
+
+type spoolSegment struct {
+	seq  uint64
+	size int64
+	// How far into the segment events have been sent. Not persisted, so
+	// events may be sent twice if authfe restarts.
+	sent int64
+}
+
+// spoolSink is a write-ahead spool in front of another sink. Events are
+// appended to files on disk, and sent to the next sink in the background,
+// so events are kept during outages of the next sink and across restarts.
+// Once the spool reaches maxBytes, the oldest events are discarded.
+type spoolSink struct {
+	dir           string
+	maxBytes      int64
+	segmentBytes  int64
+	batchSize     int
+	retryInterval time.Duration
+	next          EventSink
+
+	mtx      sync.Mutex
+	current  *os.File
+	segments []*spoolSegment // oldest first, the last one being current
+
+	// Held while sending, so events are sent in order and only once
+	sendMtx sync.Mutex
+	// Only accessed by the send loop
+	retryAt time.Time
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newSpoolSink(dir string, maxBytes int64, batchSize int, retryInterval time.Duration, next EventSink) (*spoolSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &spoolSink{
+		dir:           dir,
+		maxBytes:      maxBytes,
+		segmentBytes:  maxBytes / spoolSegmentsPerSpool,
+		batchSize:     batchSize,
+		retryInterval: retryInterval,
+		next:          next,
+		wake:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	// Pick up events spooled before a restart
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range files {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(fi.Name(), spoolSegmentSuffix), 10, 64)
+		if err != nil || !strings.HasSuffix(fi.Name(), spoolSegmentSuffix) {
+			continue
+		}
+		s.segments = append(s.segments, &spoolSegment{seq: seq, size: fi.Size()})
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+	if len(s.segments) > 0 {
+		log.Infof("EventLogger: found %d spooled event files in %s", len(s.segments), dir)
+	}
+	if err := s.openSegment(); err != nil {
+		return nil, err
+	}
+	s.updateSize()
+
+	go s.loop()
+	return s, nil
+}
+
+func (s *spoolSink) segmentPath(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolSegmentSuffix))
+}
+
+// openSegment starts a new segment to append to. Must be called with mtx held.
+func (s *spoolSink) openSegment() error {
+	var seq uint64
+	if len(s.segments) > 0 {
+		seq = s.segments[len(s.segments)-1].seq + 1
+	}
+	f, err := os.OpenFile(s.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if s.current != nil {
+		s.current.Close()
+	}
+	s.current = f
+	s.segments = append(s.segments, &spoolSegment{seq: seq})
+	return nil
+}
+
+func (s *spoolSink) currentSegment() *spoolSegment {
+	return s.segments[len(s.segments)-1]
+}
+
+// Write implements EventSink
+func (s *spoolSink) Write(events []TimedEvent) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	s.mtx.Lock()
+	n, err := s.current.Write(buf.Bytes())
+	s.currentSegment().size += int64(n)
+	if err == nil && s.currentSegment().size >= s.segmentBytes {
+		err = s.openSegment()
+	}
+	s.discardOldest()
+	s.updateSize()
+	s.mtx.Unlock()
+	if err != nil {
+		eventSinkErrors.WithLabelValues("spool").Inc()
+		return err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// discardOldest deletes the oldest segments while the spool is over its
+// size limit. Must be called with mtx held.
+func (s *spoolSink) discardOldest() {
+	for s.size() > s.maxBytes && len(s.segments) > 1 {
+		oldest := s.segments[0]
+		if n, err := countLines(s.segmentPath(oldest.seq), oldest.sent); err == nil {
+			eventsDiscardedCount.Add(float64(n))
+			log.Warnf("EventLogger: spool full, discarding %d events", n)
+		}
+		if err := os.Remove(s.segmentPath(oldest.seq)); err != nil {
+			log.Errorf("EventLogger: failed to remove spooled events: %v", err)
+		}
+		s.segments = s.segments[1:]
+	}
+}
+
+// size returns the bytes in the spool still to be sent. Must be called with mtx held.
+func (s *spoolSink) size() int64 {
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size - seg.sent
+	}
+	return total
+}
+
+func (s *spoolSink) updateSize() {
+	eventSpoolBytes.Set(float64(s.size()))
+}
+
+func (s *spoolSink) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.wake:
+			if time.Now().Before(s.retryAt) {
+				continue
+			}
+		case <-ticker.C:
+		case <-s.stop:
+			return
+		}
+		if err := s.send(); err != nil {
+			log.Warnf("EventLogger: failed to send spooled events, will retry in %s: %v", s.retryInterval, err)
+			s.retryAt = time.Now().Add(s.retryInterval)
+		}
+	}
+}
+
+// send sends all spooled events to the next sink, oldest first.
+func (s *spoolSink) send() error {
+	s.sendMtx.Lock()
+	defer s.sendMtx.Unlock()
+	for {
+		s.mtx.Lock()
+		if s.currentSegment().size > s.currentSegment().sent {
+			// Stop appending to the current segment, so it can be sent and removed.
+			if err := s.openSegment(); err != nil {
+				s.mtx.Unlock()
+				return err
+			}
+		}
+		if len(s.segments) == 1 {
+			s.mtx.Unlock()
+			return nil
+		}
+		seg := *s.segments[0]
+		s.mtx.Unlock()
+
+		sent, err := s.sendSegment(seg)
+
+		s.mtx.Lock()
+		// The segment may have been discarded while we were sending it.
+		if len(s.segments) > 1 && s.segments[0].seq == seg.seq {
+			s.segments[0].sent = sent
+			if sent >= seg.size {
+				os.Remove(s.segmentPath(seg.seq))
+				s.segments = s.segments[1:]
+			}
+		}
+		s.updateSize()
+		s.mtx.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sendSegment sends the events in a segment in batches, returning how far
+// into the segment it got.
+func (s *spoolSink) sendSegment(seg spoolSegment) (int64, error) {
+	f, err := os.Open(s.segmentPath(seg.seq))
+	if os.IsNotExist(err) {
+		return seg.size, nil
+	} else if err != nil {
+		return seg.sent, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(seg.sent, io.SeekStart); err != nil {
+		return seg.sent, err
+	}
+
+	sent := seg.sent
+	r := bufio.NewReader(f)
+	var (
+		batch     []TimedEvent
+		batchSize int64
+	)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// Ignore any partially-written last line
+			break
+		} else if err != nil {
+			return sent, err
+		}
+		batchSize += int64(len(line))
+		var e TimedEvent
+		if err := json.Unmarshal(line, &e); err != nil || e.Event == nil {
+			log.Warnf("EventLogger: skipping corrupt spooled event in %s: %v", f.Name(), err)
+		} else {
+			batch = append(batch, e)
+		}
+		if len(batch) >= s.batchSize {
+			if err := s.next.Write(batch); err != nil {
+				return sent, err
+			}
+			sent += batchSize
+			batch, batchSize = nil, 0
+		}
+	}
+	if len(batch) > 0 {
+		if err := s.next.Write(batch); err != nil {
+			return sent, err
+		}
+	}
+	return seg.size, nil
+}
+
+// Close stops sending events, making one last attempt to send any spooled.
+func (s *spoolSink) Close() error {
+	close(s.stop)
+	<-s.done
+	if err := s.send(); err != nil {
+		log.Warnf("EventLogger: failed to send spooled events on shutdown, they will be sent on restart: %v", err)
+	}
+	s.mtx.Lock()
+	s.current.Close()
+	// Don't leave an empty segment behind
+	if seg := s.currentSegment(); seg.size == 0 {
+		os.Remove(s.segmentPath(seg.seq))
+	}
+	s.mtx.Unlock()
+	return s.next.Close()
+}
+
+func countLines(path string, offset int64) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n := 0
+	r := bufio.NewReader(f)
+	for {
+		_, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			return n, nil
+		} else if err != nil {
+			return n, err
+		}
+		n++
+	}
+}
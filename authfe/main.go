@@ -4,6 +4,7 @@ import (
 	"flag"
 	"net"
 	"net/http"
+	"os"
 	"regexp"
 	"strings"
 	"time"
@@ -96,9 +97,9 @@ func main() {
 		// Optional load balancer is applied if the address looks like an SRV name
 		if strings.Contains(proxyCfg.hostAndPort, "._tcp.") {
 			if proxyCfg.loadFactor != 0 {
-				proxyCfg.balancer = balance.NewSRVConsistent(name, proxyCfg.hostAndPort, proxyCfg.loadFactor)
+				proxyCfg.balancer = balance.NewSRVConsistent(name, proxyCfg.hostAndPort, proxyCfg.loadFactor, proxyCfg.health)
 			} else {
-				proxyCfg.balancer = balance.NewSRVRoundRobin(proxyCfg.hostAndPort)
+				proxyCfg.balancer = balance.NewSRVRoundRobin(proxyCfg.hostAndPort, proxyCfg.health)
 			}
 		}
 		handler, err := newProxy(*proxyCfg)
@@ -129,12 +130,12 @@ func main() {
 	}
 
 	var eventLogger *EventLogger
-	if cfg.fluentHost != "" {
-		var err error
-		eventLogger, err = NewEventLogger(cfg.fluentHost)
+	if cfg.events.enabled() {
+		sink, err := newEventSink(cfg.events, os.Stdout)
 		if err != nil {
 			log.Fatalf("Error setting up event logging: %v", err)
 		}
+		eventLogger = NewEventLogger(sink, cfg.events.batchSize, cfg.events.flushInterval)
 		defer eventLogger.Close()
 	}
 
@@ -172,10 +173,29 @@ func main() {
 		log.Fatal(err)
 	}
 
-	r, err := routes(cfg, authenticator, ghIntegration, eventLogger)
+	var (
+		table         *RouteTable
+		tableContents []byte
+	)
+	if cfg.routeTableFile != "" {
+		table, tableContents, err = loadRouteTable(cfg.routeTableFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	r, err := routes(cfg, authenticator, ghIntegration, eventLogger, table)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if cfg.routeTableFile != "" && cfg.routeTableReloadInterval > 0 {
+		reloader := newRouteTableReloader(cfg.routeTableFile, tableContents, r, func(table *RouteTable) (http.Handler, error) {
+			return routes(cfg, authenticator, ghIntegration, eventLogger, table)
+		})
+		stop := make(chan struct{})
+		defer close(stop)
+		go reloader.run(cfg.routeTableReloadInterval, stop)
+		r = reloader
+	}
 
 	server := &graceful.Server{
 		Timeout: cfg.stopTimeout,
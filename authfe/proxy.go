@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net"
@@ -36,6 +37,7 @@ func newProxy(cfg proxyConfig) (http.Handler, error) {
 type httpProxy struct {
 	proxyConfig
 	reverseProxy httputil.ReverseProxy
+	retryBudget  *retryBudget
 }
 
 func newHTTPProxy(cfg proxyConfig) (*httpProxy, error) {
@@ -46,13 +48,18 @@ func newHTTPProxy(cfg proxyConfig) (*httpProxy, error) {
 
 	// Make all transformations outside of the director since
 	// they are also required when proxying websockets
-	return &httpProxy{
+	p := &httpProxy{
 		proxyConfig: cfg,
 		reverseProxy: httputil.ReverseProxy{
-			Director:  func(*http.Request) {},
-			Transport: proxyTransport,
+			Director: func(*http.Request) {},
 		},
-	}, nil
+		retryBudget: newRetryBudget(cfg.retryBudget),
+	}
+	// Trace outside of retries, so that all attempts show up in one span.
+	p.reverseProxy.Transport = &nethttp.Transport{
+		RoundTripper: &attemptTransport{proxy: p, next: proxyTransport},
+	}
+	return p, nil
 }
 
 var readOnlyMethods = map[string]struct{}{
@@ -61,35 +68,31 @@ var readOnlyMethods = map[string]struct{}{
 	http.MethodOptions: {},
 }
 
-var proxyTransportNoKeepAlives http.RoundTripper = &nethttp.Transport{
-	RoundTripper: &http.Transport{
-		// No connection pooling, increases latency, but ensures fair load-balancing.
-		DisableKeepAlives: true,
-
-		// Rest are from http.DefaultTransport
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-	},
+var proxyTransportNoKeepAlives http.RoundTripper = &http.Transport{
+	// No connection pooling, increases latency, but ensures fair load-balancing.
+	DisableKeepAlives: true,
+
+	// Rest are from http.DefaultTransport
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: 1 * time.Second,
 }
 
-var proxyTransportWithKeepAlives http.RoundTripper = &nethttp.Transport{
-	RoundTripper: &http.Transport{
-		// mostly the same as http.DefaultTransport
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		MaxIdleConns:          100,
-		MaxIdleConnsPerHost:   100, // Avoid Go bug https://github.com/golang/go/issues/13801
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-	},
+var proxyTransportWithKeepAlives http.RoundTripper = &http.Transport{
+	// mostly the same as http.DefaultTransport
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	MaxIdleConns:          100,
+	MaxIdleConnsPerHost:   100, // Avoid Go bug https://github.com/golang/go/issues/13801
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: 1 * time.Second,
 }
 
 func (p *httpProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -123,7 +126,7 @@ func (p *httpProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	hostAndPort := p.hostAndPort
 	var endpoint balance.Endpoint
 	if p.balancer != nil {
-		key := r.Header.Get(user.OrgIDHeaderName)
+		key := balancerKey(r)
 		var err error
 		endpoint, err = p.balancer.Get(key)
 		if err != nil {
@@ -145,14 +148,16 @@ func (p *httpProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Detect whether we should do websockets
 	if middleware.IsWSHandshakeRequest(r) {
 		logger.Debugf("proxy: detected websocket handshake")
-		proxyWS(w, r)
+		err := proxyWS(w, r)
 		if endpoint != nil {
 			p.balancer.Put(endpoint)
+			p.balancer.Observe(endpoint, err == nil)
 		}
 		return
 	}
 
-	// Proxy request
+	// Proxy request; the endpoint is observed by the transport, which may also try others.
+	r = r.WithContext(context.WithValue(r.Context(), proxyEndpointKey{}, endpoint))
 	p.reverseProxy.ServeHTTP(w, r)
 
 	if endpoint != nil {
@@ -160,7 +165,8 @@ func (p *httpProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func proxyWS(w http.ResponseWriter, r *http.Request) {
+// proxyWS proxies a websocket connection, returning an error if the backend could not be reached.
+func proxyWS(w http.ResponseWriter, r *http.Request) error {
 	wsRequestCount.Inc()
 	wsConnections.Inc()
 	defer wsConnections.Dec()
@@ -176,7 +182,7 @@ func proxyWS(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		logger.Errorf("proxy: websocket: error dialing backend %q: %v", address, err)
 		w.WriteHeader(http.StatusBadGateway)
-		return
+		return err
 	}
 	defer targetConn.Close()
 
@@ -185,13 +191,13 @@ func proxyWS(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		logger.Errorf("proxy: websocket: error casting to Hijacker on request to %q", address)
 		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return nil
 	}
 	clientConn, _, err := hijacker.Hijack()
 	if err != nil {
 		logger.Errorf("proxy: websocket: Hijack error: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return nil
 	}
 	defer clientConn.Close()
 
@@ -199,7 +205,7 @@ func proxyWS(w http.ResponseWriter, r *http.Request) {
 	logger.Debugf("proxy: websocket: writing original request to %s%s", address, r.URL.Opaque)
 	if err := r.Write(targetConn); err != nil {
 		logger.Errorf("proxy: websocket: error copying request to target: %v", err)
-		return
+		return err
 	}
 
 	// Copy websocket payload back and forth between our client and the target host
@@ -210,6 +216,7 @@ func proxyWS(w http.ResponseWriter, r *http.Request) {
 	go copyStream(targetConn, clientConn, &wg, "proxy: websocket: \"client2server\"")
 	wg.Wait()
 	logger.Debugf("proxy: websocket: connection closed")
+	return nil
 }
 
 type closeWriter interface {
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/weaveworks/common/logging"
+	"github.com/weaveworks/common/user"
+
+	"github.com/weaveworks/service/authfe/balance"
+	"github.com/weaveworks/service/common"
+)
+
+const (
+	// Retries the budget starts with and can save up, so that a quiet
+	// service can still retry a few requests.
+	minRetryBudget = 10
+	maxRetryBudget = 100
+)
+
+var (
+	proxyAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: common.PrometheusNamespace,
+		Name:      "proxy_extra_attempts_total",
+		Help:      "Total number of extra attempts made to a backend, by kind (retry or hedge).",
+	}, []string{"service", "kind"})
+	proxyRetryBudgetExhausted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: common.PrometheusNamespace,
+		Name:      "proxy_retry_budget_exhausted_total",
+		Help:      "Total number of retries or hedges not made because the retry budget was exhausted.",
+	}, []string{"service"})
+)
+
+func init() {
+	prometheus.MustRegister(proxyAttempts)
+	prometheus.MustRegister(proxyRetryBudgetExhausted)
+}
+
+type proxyEndpointKey struct{}
+
+// balancerKey returns the affinity key used to pick an endpoint for a request.
+func balancerKey(r *http.Request) string {
+	return r.Header.Get(user.OrgIDHeaderName)
+}
+
+// retryBudget limits retries and hedged requests to a fraction of all
+// requests, so that they can't amplify an outage.
+type retryBudget struct {
+	mtx     sync.Mutex
+	ratio   float64
+	balance float64
+}
+
+func newRetryBudget(ratio float64) *retryBudget {
+	return &retryBudget{ratio: ratio, balance: minRetryBudget}
+}
+
+// deposit is called for each request which could be retried.
+func (b *retryBudget) deposit() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.balance += b.ratio
+	if b.balance > maxRetryBudget {
+		b.balance = maxRetryBudget
+	}
+}
+
+// withdraw returns true if there is budget left for another attempt.
+func (b *retryBudget) withdraw() bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.balance < 1 {
+		return false
+	}
+	b.balance--
+	return true
+}
+
+// attemptTransport sends requests to the backends of an httpProxy. Idempotent
+// requests which fail to connect are retried against another endpoint, and
+// if configured, slow ones are hedged by sending a second request to another
+// endpoint and using whichever response arrives first.
+type attemptTransport struct {
+	proxy *httpProxy
+	next  http.RoundTripper
+}
+
+type attemptResult struct {
+	endpoint balance.Endpoint
+	primary  bool
+	resp     *http.Response
+	err      error
+	cancel   context.CancelFunc
+	attempt  int
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *attemptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := t.proxy
+	primary, _ := req.Context().Value(proxyEndpointKey{}).(balance.Endpoint)
+
+	if !t.idempotent(req) || (p.retries <= 0 && p.hedgeAfter <= 0) {
+		resp, err := t.next.RoundTrip(req)
+		// A client going away says nothing about the endpoint's health.
+		if !errors.Is(err, context.Canceled) {
+			t.observe(primary, resp, err)
+		}
+		return resp, err
+	}
+	p.retryBudget.deposit()
+
+	results := make(chan attemptResult, p.retries+2)
+	tried := []balance.Endpoint{primary}
+	inflight := 0
+	var cancels []context.CancelFunc
+	launch := func(endpoint balance.Endpoint, isPrimary bool) {
+		inflight++
+		ctx, cancel := context.WithCancel(req.Context())
+		n := len(cancels)
+		cancels = append(cancels, cancel)
+		attempt := req.Clone(ctx)
+		if endpoint != nil {
+			attempt.Host = endpoint.HostAndPort()
+			attempt.URL.Host = endpoint.HostAndPort()
+		}
+		go func() {
+			resp, err := t.next.RoundTrip(attempt)
+			results <- attemptResult{endpoint: endpoint, primary: isPrimary, resp: resp, err: err, cancel: cancel, attempt: n}
+		}()
+	}
+	// another launches an attempt on an endpoint we haven't tried yet, if the budget allows.
+	another := func(kind string) {
+		endpoint, ok := t.nextEndpoint(balancerKey(req), tried)
+		if !ok {
+			return
+		}
+		if !p.retryBudget.withdraw() {
+			proxyRetryBudgetExhausted.WithLabelValues(p.name).Inc()
+			if endpoint != nil {
+				p.balancer.Put(endpoint)
+			}
+			return
+		}
+		tried = append(tried, endpoint)
+		proxyAttempts.WithLabelValues(p.name, kind).Inc()
+		launch(endpoint, false)
+	}
+
+	launch(primary, true)
+	var hedge <-chan time.Time
+	if p.hedgeAfter > 0 {
+		timer := time.NewTimer(p.hedgeAfter)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+	retries := p.retries
+	var lastErr error
+	for inflight > 0 {
+		select {
+		case <-hedge:
+			hedge = nil
+			another("hedge")
+		case result := <-results:
+			inflight--
+			if !errors.Is(result.err, context.Canceled) {
+				t.observe(result.endpoint, result.resp, result.err)
+			}
+			if result.err == nil {
+				// Use this response; abandon any other attempts still in flight.
+				if inflight > 0 {
+					for i, cancel := range cancels {
+						if i != result.attempt {
+							cancel()
+						}
+					}
+					go t.abandon(results, inflight)
+				}
+				result.resp.Body = releaseOnClose{ReadCloser: result.resp.Body, release: func() { t.release(result) }}
+				return result.resp, nil
+			}
+			t.release(result)
+			lastErr = result.err
+			if retries > 0 && isConnectError(result.err) {
+				retries--
+				user.LogWith(req.Context(), logging.Global()).Debugf("proxy: retrying %s %s after error: %v", req.Method, req.URL.Path, result.err)
+				another("retry")
+			}
+		}
+	}
+	return nil, lastErr
+}
+
+// idempotent returns true if the request can safely be sent more than once.
+func (t *attemptTransport) idempotent(req *http.Request) bool {
+	if _, ok := readOnlyMethods[req.Method]; !ok {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody
+}
+
+// nextEndpoint picks an endpoint which hasn't been tried yet. Without a
+// balancer, the only option is to try the same host and port again.
+func (t *attemptTransport) nextEndpoint(key string, tried []balance.Endpoint) (balance.Endpoint, bool) {
+	if t.proxy.balancer == nil {
+		return nil, true
+	}
+	// Vary the key so consistent hashing considers other endpoints.
+	for i := 0; i < len(tried)+2; i++ {
+		endpoint, err := t.proxy.balancer.Get(fmt.Sprintf("%s/%d", key, i))
+		if err != nil {
+			return nil, false
+		}
+		if !containsEndpoint(tried, endpoint) {
+			return endpoint, true
+		}
+		t.proxy.balancer.Put(endpoint)
+	}
+	return nil, false
+}
+
+func containsEndpoint(endpoints []balance.Endpoint, endpoint balance.Endpoint) bool {
+	for _, e := range endpoints {
+		if e != nil && e.Key() == endpoint.Key() {
+			return true
+		}
+	}
+	return false
+}
+
+// release cancels an attempt, and returns its endpoint to the balancer if it
+// was obtained by the transport rather than the proxy.
+func (t *attemptTransport) release(result attemptResult) {
+	result.cancel()
+	if !result.primary && result.endpoint != nil {
+		t.proxy.balancer.Put(result.endpoint)
+	}
+}
+
+// abandon cleans up attempts which lost the race.
+func (t *attemptTransport) abandon(results <-chan attemptResult, inflight int) {
+	for ; inflight > 0; inflight-- {
+		result := <-results
+		if result.resp != nil {
+			result.resp.Body.Close()
+		}
+		t.release(result)
+	}
+}
+
+func (t *attemptTransport) observe(endpoint balance.Endpoint, resp *http.Response, err error) {
+	if endpoint == nil {
+		return
+	}
+	t.proxy.balancer.Observe(endpoint, err == nil && resp.StatusCode < 500)
+}
+
+// isConnectError returns true if the request never reached the backend.
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// releaseOnClose releases the attempt which produced a response once its body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
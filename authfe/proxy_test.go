@@ -3,9 +3,12 @@ package main
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -215,3 +218,171 @@ func TestProxyReadOnly(t *testing.T) {
 	assert.Equal(t, resp.StatusCode, http.StatusServiceUnavailable)
 	assert.True(t, atomic.LoadUint32(&handlerCalled) == 1, "Server was called")
 }
+
+// Test that a backend which keeps failing stops receiving requests
+func TestProxyOutlierEjection(t *testing.T) {
+	var goodCalls, badCalls uint32
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint32(&goodCalls, 1)
+	}))
+	defer good.Close()
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint32(&badCalls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer bad.Close()
+	goodURL, _ := url.Parse(good.URL)
+	badURL, _ := url.Parse(bad.URL)
+
+	balancer := balance.NewHealthChecked(sd.FixedInstancer{goodURL.Host, badURL.Host}, balance.HealthConfig{
+		Name:                "test",
+		ConsecutiveFailures: 2,
+		EjectionTime:        time.Minute,
+	}, balance.NewRoundRobin)
+	defer balancer.Close()
+	proxy, _ := newProxy(proxyConfig{balancer: balancer, protocol: "http"})
+	proxyServer := httptest.NewServer(proxy)
+	defer proxyServer.Close()
+
+	for i := 0; i < 20; i++ {
+		resp, err := http.Get(proxyServer.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, uint32(2), atomic.LoadUint32(&badCalls))
+	assert.Equal(t, uint32(18), atomic.LoadUint32(&goodCalls))
+}
+
+func TestProxyClientCancelNotEjected(t *testing.T) {
+	var calls [2]uint32
+	servers := []string{}
+	for i := range calls {
+		n := &calls[i]
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("slow") != "" {
+				<-r.Context().Done()
+				return
+			}
+			atomic.AddUint32(n, 1)
+		}))
+		defer server.Close()
+		serverURL, _ := url.Parse(server.URL)
+		servers = append(servers, serverURL.Host)
+	}
+
+	balancer := balance.NewHealthChecked(sd.FixedInstancer(servers), balance.HealthConfig{
+		Name:                "test",
+		ConsecutiveFailures: 2,
+		EjectionTime:        time.Minute,
+	}, balance.NewRoundRobin)
+	defer balancer.Close()
+	proxy, _ := newProxy(proxyConfig{balancer: balancer, protocol: "http"})
+	proxyServer := httptest.NewServer(proxy)
+	defer proxyServer.Close()
+
+	// Clients giving up on slow requests don't count against the endpoints.
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+	for i := 0; i < 4; i++ {
+		_, err := client.Get(proxyServer.URL + "?slow=1")
+		assert.Error(t, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	for i := 0; i < 20; i++ {
+		resp, err := http.Get(proxyServer.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, uint32(10), atomic.LoadUint32(&calls[0]))
+	assert.Equal(t, uint32(10), atomic.LoadUint32(&calls[1]))
+}
+
+// An address nothing is listening on
+func deadHost(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	l.Close()
+	return l.Addr().String()
+}
+
+func TestProxyRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	balancer := balance.NewRoundRobin(sd.FixedInstancer{deadHost(t), serverURL.Host})
+	defer balancer.Close()
+	proxy, _ := newProxy(proxyConfig{balancer: balancer, protocol: "http", retries: 1, retryBudget: 1})
+	proxyServer := httptest.NewServer(proxy)
+	defer proxyServer.Close()
+
+	// Idempotent requests get retried against the other endpoint
+	for i := 0; i < 10; i++ {
+		resp, err := http.Get(proxyServer.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	// Others don't
+	codes := map[int]int{}
+	for i := 0; i < 10; i++ {
+		resp, err := http.Post(proxyServer.URL, "text/plain", strings.NewReader("foo"))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		codes[resp.StatusCode]++
+	}
+	assert.Equal(t, map[int]int{http.StatusOK: 5, http.StatusBadGateway: 5}, codes)
+}
+
+func TestProxyHedge(t *testing.T) {
+	var slowCalls, fastCalls uint32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint32(&slowCalls, 1)
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte("slow"))
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint32(&fastCalls, 1)
+		w.Write([]byte("fast"))
+	}))
+	defer fast.Close()
+	slowURL, _ := url.Parse(slow.URL)
+	fastURL, _ := url.Parse(fast.URL)
+
+	balancer := balance.NewRoundRobin(sd.FixedInstancer{slowURL.Host, fastURL.Host})
+	defer balancer.Close()
+	proxy, _ := newProxy(proxyConfig{balancer: balancer, protocol: "http", hedgeAfter: 20 * time.Millisecond, retryBudget: 1})
+	proxyServer := httptest.NewServer(proxy)
+	defer proxyServer.Close()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		resp, err := http.Get(proxyServer.URL)
+		assert.NoError(t, err)
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "fast", string(body))
+	}
+	assert.True(t, time.Since(start) < 500*time.Millisecond, "hedged requests should not wait for the slow server")
+	assert.True(t, atomic.LoadUint32(&slowCalls) > 0, "slow server should have been tried")
+	assert.Equal(t, uint32(4), atomic.LoadUint32(&fastCalls))
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := newRetryBudget(0.5)
+	for i := 0; i < minRetryBudget; i++ {
+		assert.True(t, b.withdraw())
+	}
+	assert.False(t, b.withdraw())
+	b.deposit()
+	assert.False(t, b.withdraw())
+	b.deposit()
+	assert.True(t, b.withdraw())
+	assert.False(t, b.withdraw())
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+
+	"github.com/weaveworks/common/logging"
+	"github.com/weaveworks/common/middleware"
+	"github.com/weaveworks/common/user"
+	"github.com/weaveworks/service/common"
+	"github.com/weaveworks/service/common/featureflag"
+)
+
+const rateLimiterCacheSize = 10000
+
+var rateLimitedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: common.PrometheusNamespace,
+	Name:      "rate_limited_requests_total",
+	Help:      "Total number of requests rejected because the organization exceeded its rate limit.",
+}, []string{"org", "route"})
+
+func init() {
+	prometheus.MustRegister(rateLimitedRequests)
+}
+
+// rateLimit is a token bucket: refilled at rate requests per second, holding at most burst tokens.
+type rateLimit struct {
+	rate  float64
+	burst int
+}
+
+// parseRateLimit parses a limit of the form "<rate>" or "<rate>:<burst>".
+// If no burst is given, it defaults to the rate (rounded up).
+func parseRateLimit(s string) (rateLimit, error) {
+	parts := strings.SplitN(s, ":", 2)
+	r, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || r < 0 {
+		return rateLimit{}, fmt.Errorf("invalid rate %q", parts[0])
+	}
+	l := rateLimit{rate: r, burst: int(math.Ceil(r))}
+	if len(parts) == 2 {
+		b, err := strconv.Atoi(parts[1])
+		if err != nil || b < 0 {
+			return rateLimit{}, fmt.Errorf("invalid burst %q", parts[1])
+		}
+		l.burst = b
+	}
+	return l, nil
+}
+
+type rateLimitConfig struct {
+	// Values set by flags.
+	rate           float64
+	burst          int
+	routeOverrides common.ArrayFlags // prefix=rate[:burst]
+}
+
+func (c *rateLimitConfig) RegisterFlags(name string, f *flag.FlagSet) {
+	f.Float64Var(&c.rate, name+".rate", 0, fmt.Sprintf("Requests per second permitted per organization on %s routes (0 disables rate limiting)", name))
+	f.IntVar(&c.burst, name+".burst", 0, fmt.Sprintf("Maximum burst of requests permitted per organization on %s routes (defaults to the rate)", name))
+	f.Var(&c.routeOverrides, name+".route-override", fmt.Sprintf("Override the %s rate limit for a route, in format /absolute/prefix=rate[:burst]. May be repeated.", name))
+}
+
+// RateLimitMiddleware rejects requests once an organization exceeds its request rate on a route.
+// It must be placed after a middleware which authenticates the organization, e.g. AuthProbeMiddleware,
+// as the org ID and feature flags are read from the request headers.
+//
+// The limit for an organization can be overridden with the featureflag.RateLimit flag
+// ("rate-limit:<rate>[:<burst>]"), or lifted completely with featureflag.NoRateLimit.
+type RateLimitMiddleware struct {
+	FeatureFlagsHeader string
+
+	defaultLimit   rateLimit
+	routeOverrides map[string]rateLimit
+
+	mtx      sync.Mutex
+	limiters gcache.Cache
+}
+
+type rateLimiterKey struct {
+	orgID string
+	route string
+}
+
+type rateLimiterEntry struct {
+	limit   rateLimit
+	limiter *rate.Limiter
+}
+
+// newRateLimitMiddleware builds a RateLimitMiddleware from config. If rate limiting
+// is disabled, middleware.Identity is returned.
+func newRateLimitMiddleware(cfg rateLimitConfig, featureFlagsHeader string) (middleware.Interface, error) {
+	if cfg.rate <= 0 {
+		return middleware.Identity, nil
+	}
+	defaultLimit := rateLimit{rate: cfg.rate, burst: cfg.burst}
+	if defaultLimit.burst <= 0 {
+		defaultLimit.burst = int(math.Ceil(cfg.rate))
+	}
+	overrides := map[string]rateLimit{}
+	for _, o := range cfg.routeOverrides {
+		parts := strings.SplitN(o, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid rate limit route override %q", o)
+		}
+		l, err := parseRateLimit(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit route override %q: %v", o, err)
+		}
+		overrides[filepath.Clean(parts[0])] = l
+	}
+	return &RateLimitMiddleware{
+		FeatureFlagsHeader: featureFlagsHeader,
+		defaultLimit:       defaultLimit,
+		routeOverrides:     overrides,
+		limiters:           gcache.New(rateLimiterCacheSize).LRU().Build(),
+	}, nil
+}
+
+// Wrap implements middleware.Interface
+func (m *RateLimitMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID := r.Header.Get(user.OrgIDHeaderName)
+		if orgID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		route := routeTemplate(r)
+
+		limit, ok := m.limitFor(route, strings.Fields(r.Header.Get(m.FeatureFlagsHeader)))
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		now := time.Now()
+		reservation := m.limiter(rateLimiterKey{orgID: orgID, route: route}, limit).ReserveN(now, 1)
+		if !reservation.OK() {
+			m.reject(w, r, orgID, route, time.Second)
+			return
+		}
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			m.reject(w, r, orgID, route, delay)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (m *RateLimitMiddleware) reject(w http.ResponseWriter, r *http.Request, orgID, route string, retryAfter time.Duration) {
+	rateLimitedRequests.WithLabelValues(orgID, route).Inc()
+	user.LogWith(r.Context(), logging.Global()).Debugf("Rate limited request to %s", route)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, "Too many requests", http.StatusTooManyRequests)
+}
+
+// limitFor returns the limit applying to a route for an organization with
+// the given feature flags, or false if the request should not be limited.
+func (m *RateLimitMiddleware) limitFor(route string, flags []string) (rateLimit, bool) {
+	if featureflag.HasFeatureAllFlags([]string{featureflag.NoRateLimit}, flags) {
+		return rateLimit{}, false
+	}
+	if value, ok := featureflag.GetFeatureFlagValue(featureflag.RateLimit, flags); ok {
+		if l, err := parseRateLimit(value); err == nil {
+			return l, true
+		}
+		logging.Global().Warnf("Ignoring invalid %s feature flag value %q", featureflag.RateLimit, value)
+	}
+	if l, ok := m.routeOverrides[route]; ok {
+		return l, true
+	}
+	return m.defaultLimit, true
+}
+
+// limiter returns the token bucket for a key, replacing it if the limit has changed.
+func (m *RateLimitMiddleware) limiter(key rateLimiterKey, limit rateLimit) *rate.Limiter {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if v, err := m.limiters.Get(key); err == nil {
+		entry := v.(rateLimiterEntry)
+		if entry.limit == limit {
+			return entry.limiter
+		}
+	}
+	entry := rateLimiterEntry{
+		limit:   limit,
+		limiter: rate.NewLimiter(rate.Limit(limit.rate), limit.burst),
+	}
+	m.limiters.Set(key, entry)
+	return entry.limiter
+}
+
+// routeTemplate returns the path prefix of the matched route, e.g. /api/prom/push
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return filepath.Clean(tmpl)
+		}
+	}
+	return "other"
+}
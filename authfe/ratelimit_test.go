@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/service/common/featureflag"
+	users "github.com/weaveworks/service/users/client"
+)
+
+func TestRateLimitRoutes(t *testing.T) {
+	cfg := Config{
+		launcherServiceExternalHost: "get.weave.works",
+		uploadRateLimit: rateLimitConfig{
+			rate:           0.001,
+			burst:          2,
+			routeOverrides: []string{"/api/prom/push=0.001:1"},
+		},
+	}
+	authenticator, err := users.New("mock", "users:4772", users.CachingClientConfig{})
+	assert.NoError(t, err)
+	for name, proxyCfg := range cfg.proxies() {
+		handler, err := newProxy(proxyConfig{name: name, protocol: "mock"})
+		assert.NoError(t, err)
+		proxyCfg.Handler = handler
+	}
+	handler, err := routes(cfg, authenticator, nil, nil, nil)
+	assert.NoError(t, err)
+
+	get := func(method, url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, url, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Scope-Probe token=token")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Default limit, burst of 2
+	assert.Equal(t, http.StatusOK, get("POST", "/api/report").Code)
+	assert.Equal(t, http.StatusOK, get("POST", "/api/report").Code)
+	rr := get("POST", "/api/report")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+
+	// Route override, burst of 1, limited separately from /api/report
+	assert.Equal(t, http.StatusOK, get("POST", "/api/prom/push").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get("POST", "/api/prom/push").Code)
+
+	// Data access routes are not limited
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, get("GET", "/api/prom/api/v1/query").Code)
+	}
+}
+
+func TestRateLimitFeatureFlags(t *testing.T) {
+	mid, err := newRateLimitMiddleware(rateLimitConfig{
+		rate:           10,
+		routeOverrides: []string{"/api/report=5:50"},
+	}, featureFlagsHeader)
+	assert.NoError(t, err)
+	m := mid.(*RateLimitMiddleware)
+
+	for _, tc := range []struct {
+		route   string
+		flags   []string
+		limited bool
+		limit   rateLimit
+	}{
+		{route: "/api/flux", limited: true, limit: rateLimit{rate: 10, burst: 10}},
+		{route: "/api/report", limited: true, limit: rateLimit{rate: 5, burst: 50}},
+		{route: "/api/report", flags: []string{featureflag.RateLimit + ":100"}, limited: true, limit: rateLimit{rate: 100, burst: 100}},
+		{route: "/api/report", flags: []string{featureflag.RateLimit + ":100:20"}, limited: true, limit: rateLimit{rate: 100, burst: 20}},
+		{route: "/api/report", flags: []string{featureflag.RateLimit + ":bogus"}, limited: true, limit: rateLimit{rate: 5, burst: 50}},
+		{route: "/api/report", flags: []string{featureflag.Billing, featureflag.NoRateLimit}, limited: false},
+	} {
+		limit, limited := m.limitFor(tc.route, tc.flags)
+		assert.Equal(t, tc.limited, limited, "%s %v", tc.route, tc.flags)
+		if tc.limited {
+			assert.Equal(t, tc.limit, limit, "%s %v", tc.route, tc.flags)
+		}
+	}
+
+	_, err = newRateLimitMiddleware(rateLimitConfig{rate: 1, routeOverrides: []string{"/api/report"}}, featureFlagsHeader)
+	assert.Error(t, err)
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"github.com/weaveworks/common/middleware"
+	"gopkg.in/yaml.v2"
+
+	"github.com/weaveworks/service/common"
+)
+
+var routeTableReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: common.PrometheusNamespace,
+	Name:      "route_table_reloads_total",
+	Help:      "Total number of attempts to reload the route table, by result.",
+}, []string{"result"})
+
+func init() {
+	prometheus.MustRegister(routeTableReloads)
+}
+
+// RouteTable is a declarative list of routes, loaded from a YAML or JSON file.
+// Its routes take precedence over the built-in ones.
+type RouteTable struct {
+	Routes []RouteTableEntry `yaml:"routes" json:"routes"`
+}
+
+// RouteTableEntry routes a path prefix to a backend.
+type RouteTableEntry struct {
+	// Prefix of the path to match, e.g. /admin/foo. May contain mux variables such as {orgExternalID}.
+	Prefix string `yaml:"prefix" json:"prefix"`
+	// Methods to match; all methods if empty.
+	Methods []string `yaml:"methods,omitempty" json:"methods,omitempty"`
+	// Backend is either the name of a proxy configured by flags, e.g. "users",
+	// or the URL of a service, e.g. http://foo.default.svc.cluster.local:80
+	Backend string `yaml:"backend" json:"backend"`
+	// Middleware to apply, by name, outermost first. See routes() for the names available.
+	Middleware []string `yaml:"middleware,omitempty" json:"middleware,omitempty"`
+	// CSRFExempt disables CSRF token checks for this prefix.
+	CSRFExempt bool `yaml:"csrfExempt,omitempty" json:"csrfExempt,omitempty"`
+	// Rewrite replaces matches of a regular expression in the path before proxying.
+	Rewrite *PathRewrite `yaml:"rewrite,omitempty" json:"rewrite,omitempty"`
+}
+
+// PathRewrite describes a regular expression replacement on the request path.
+type PathRewrite struct {
+	Regexp      string `yaml:"regexp" json:"regexp"`
+	Replacement string `yaml:"replacement" json:"replacement"`
+}
+
+var validMethods = map[string]struct{}{
+	http.MethodGet: {}, http.MethodHead: {}, http.MethodPost: {}, http.MethodPut: {},
+	http.MethodPatch: {}, http.MethodDelete: {}, http.MethodOptions: {},
+}
+
+// parseRouteTable parses and validates the structure of a route table.
+// Backend and middleware names can only be checked when building the routes.
+func parseRouteTable(buf []byte) (*RouteTable, error) {
+	var table RouteTable
+	if err := yaml.UnmarshalStrict(buf, &table); err != nil {
+		return nil, err
+	}
+	for i, e := range table.Routes {
+		if !strings.HasPrefix(e.Prefix, "/") {
+			return nil, fmt.Errorf("route %d: prefix %q must start with /", i, e.Prefix)
+		}
+		if e.Backend == "" {
+			return nil, fmt.Errorf("route %d (%s): no backend", i, e.Prefix)
+		}
+		for _, m := range e.Methods {
+			if _, ok := validMethods[m]; !ok {
+				return nil, fmt.Errorf("route %d (%s): invalid method %q", i, e.Prefix, m)
+			}
+		}
+		if e.Rewrite != nil {
+			if _, err := regexp.Compile(e.Rewrite.Regexp); err != nil {
+				return nil, fmt.Errorf("route %d (%s): invalid rewrite: %v", i, e.Prefix, err)
+			}
+		}
+	}
+	return &table, nil
+}
+
+func loadRouteTable(filename string) (*RouteTable, []byte, error) {
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	table, err := parseRouteTable(buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading route table %s: %v", filename, err)
+	}
+	return table, buf, nil
+}
+
+// routables converts the table into routes, resolving backend and middleware names.
+func (t *RouteTable) routables(backends map[string]*proxyConfig, middlewares map[string]middleware.Interface) ([]Routable, error) {
+	if t == nil {
+		return nil, nil
+	}
+	var result []Routable
+	for i, e := range t.Routes {
+		handler, err := resolveBackend(e.Backend, backends)
+		if err != nil {
+			return nil, fmt.Errorf("route %d (%s): %v", i, e.Prefix, err)
+		}
+		if e.Rewrite != nil {
+			handler = middleware.PathRewrite(regexp.MustCompile(e.Rewrite.Regexp), e.Rewrite.Replacement).Wrap(handler)
+		}
+
+		mids := make([]middleware.Interface, 0, len(e.Middleware))
+		for _, name := range e.Middleware {
+			mid, ok := middlewares[name]
+			if !ok {
+				return nil, fmt.Errorf("route %d (%s): unknown middleware %q", i, e.Prefix, name)
+			}
+			mids = append(mids, mid)
+		}
+
+		var route PrefixRoutable = Prefix{e.Prefix, handler}
+		if len(e.Methods) > 0 {
+			route = PrefixMethods{e.Prefix, e.Methods, handler}
+		}
+		result = append(result, MiddlewarePrefix{"", []PrefixRoutable{route}, middleware.Merge(mids...)})
+	}
+	return result, nil
+}
+
+// csrfExemptPrefixes returns the prefixes of routes exempt from CSRF checks.
+func (t *RouteTable) csrfExemptPrefixes() []string {
+	if t == nil {
+		return nil
+	}
+	var result []string
+	for _, e := range t.Routes {
+		if e.CSRFExempt {
+			result = append(result, e.Prefix)
+		}
+	}
+	return result
+}
+
+func resolveBackend(backend string, backends map[string]*proxyConfig) (http.Handler, error) {
+	if proxyCfg, ok := backends[backend]; ok {
+		if proxyCfg.Handler == nil {
+			return nil, fmt.Errorf("backend %q is not configured", backend)
+		}
+		return proxyCfg.Handler, nil
+	}
+	u, err := url.Parse(backend)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("unknown backend %q: must be a configured service or a URL", backend)
+	}
+	return newProxy(proxyConfig{name: u.Host, hostAndPort: u.Host, protocol: u.Scheme})
+}
+
+// routeTableHandler dumps the route table in use and all the routes registered on a router.
+func routeTableHandler(table *RouteTable, r *mux.Router) http.Handler {
+	type route struct {
+		Path    string   `json:"path"`
+		Methods []string `json:"methods,omitempty"`
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var routes []route
+		r.Walk(func(rt *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			path, err := rt.GetPathTemplate()
+			if err != nil {
+				// Hostname-specific route
+				path, _ = rt.GetHostTemplate()
+			}
+			methods, _ := rt.GetMethods()
+			sort.Strings(methods)
+			routes = append(routes, route{Path: path, Methods: methods})
+			return nil
+		})
+		if table == nil {
+			table = &RouteTable{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Table  *RouteTable `json:"table"`
+			Routes []route     `json:"routes"`
+		}{table, routes})
+	})
+}
+
+// routeTableReloader serves requests with routes built from the latest valid
+// version of a route table file.
+type routeTableReloader struct {
+	filename string
+	build    func(*RouteTable) (http.Handler, error)
+
+	mtx      sync.RWMutex
+	handler  http.Handler
+	contents []byte
+}
+
+func newRouteTableReloader(filename string, contents []byte, handler http.Handler, build func(*RouteTable) (http.Handler, error)) *routeTableReloader {
+	return &routeTableReloader{
+		filename: filename,
+		build:    build,
+		handler:  handler,
+		contents: contents,
+	}
+}
+
+func (l *routeTableReloader) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mtx.RLock()
+	handler := l.handler
+	l.mtx.RUnlock()
+	handler.ServeHTTP(w, r)
+}
+
+// run checks the file for changes every interval, until stop is closed.
+func (l *routeTableReloader) run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.reload(); err != nil {
+				log.Errorf("Error reloading route table, keeping previous routes: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (l *routeTableReloader) reload() error {
+	buf, err := ioutil.ReadFile(l.filename)
+	if err != nil {
+		routeTableReloads.WithLabelValues("failure").Inc()
+		return err
+	}
+	l.mtx.RLock()
+	unchanged := bytes.Equal(buf, l.contents)
+	l.mtx.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	table, err := parseRouteTable(buf)
+	if err == nil {
+		var handler http.Handler
+		handler, err = l.build(table)
+		if err == nil {
+			l.mtx.Lock()
+			l.handler = handler
+			l.contents = buf
+			l.mtx.Unlock()
+			routeTableReloads.WithLabelValues("success").Inc()
+			log.Infof("Reloaded route table from %s: %d routes", l.filename, len(table.Routes))
+			return nil
+		}
+	}
+	routeTableReloads.WithLabelValues("failure").Inc()
+	return err
+}
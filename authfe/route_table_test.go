@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	service_users "github.com/weaveworks/service/users"
+	users "github.com/weaveworks/service/users/client"
+)
+
+func TestParseRouteTable(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		valid bool
+	}{
+		{"empty", ``, true},
+		{"yaml", `
+routes:
+- prefix: /api/foo
+  methods: [GET, POST]
+  backend: users
+  middleware: [auth-user, log-ui]
+  csrfExempt: true
+  rewrite: {regexp: "^/api/foo", replacement: ""}
+`, true},
+		{"json", `{"routes": [{"prefix": "/api/foo", "backend": "http://foo:80"}]}`, true},
+		{"unknown field", `{"routes": [{"prefix": "/api/foo", "backend": "users", "bogus": 1}]}`, false},
+		{"relative prefix", `{"routes": [{"prefix": "api/foo", "backend": "users"}]}`, false},
+		{"no backend", `{"routes": [{"prefix": "/api/foo"}]}`, false},
+		{"bad method", `{"routes": [{"prefix": "/api/foo", "backend": "users", "methods": ["FETCH"]}]}`, false},
+		{"bad rewrite", `{"routes": [{"prefix": "/api/foo", "backend": "users", "rewrite": {"regexp": "("}}]}`, false},
+	} {
+		_, err := parseRouteTable([]byte(tc.input))
+		assert.Equal(t, tc.valid, err == nil, "%s: %v", tc.name, err)
+	}
+}
+
+func routeTableTestConfig(t *testing.T) (Config, service_users.UsersClient) {
+	cfg := Config{
+		launcherServiceExternalHost: "get.weave.works",
+	}
+	authenticator, err := users.New("mock", "users:4772", users.CachingClientConfig{})
+	assert.NoError(t, err)
+	for name, proxyCfg := range cfg.proxies() {
+		handler, err := newProxy(proxyConfig{name: name, protocol: "mock"})
+		assert.NoError(t, err)
+		proxyCfg.Handler = handler
+	}
+	return cfg, authenticator
+}
+
+func TestRouteTableRoutes(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "backend %s", r.URL.Path)
+	}))
+	defer backend.Close()
+
+	cfg, authenticator := routeTableTestConfig(t)
+	table, err := parseRouteTable([]byte(fmt.Sprintf(`
+routes:
+- prefix: /api/users/special
+  backend: notebooks
+- prefix: /api/rewritten
+  backend: %s
+  csrfExempt: true
+  rewrite: {regexp: "^/api/rewritten", replacement: "/v1"}
+- prefix: /api/readonly
+  methods: [GET]
+  backend: notebooks
+  middleware: [log-ui]
+`, backend.URL)))
+	assert.NoError(t, err)
+	handler, err := routes(cfg, authenticator, nil, nil, table)
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		method, url  string
+		expectedCode int
+		expectedBody string
+	}{
+		// Table routes take precedence over the built-in ones
+		{"GET", "/api/users/special/foo", 200, "notebooks"},
+		{"GET", "/api/users/other", 200, "users"},
+		// URL backend with path rewrite, exempt from CSRF checks
+		{"POST", "/api/rewritten/bar", 200, "backend /v1/bar"},
+		// Method restrictions; other methods fall through to the built-in routes
+		{"GET", "/api/readonly", 200, "notebooks"},
+		{"HEAD", "/api/readonly", 200, "ui-server"},
+	} {
+		req, err := http.NewRequest(tc.method, tc.url, nil)
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, tc.expectedCode, rr.Code, "%s %s", tc.method, tc.url)
+		if tc.expectedBody != "" {
+			assert.Equal(t, tc.expectedBody, rr.Body.String(), "%s %s", tc.method, tc.url)
+		}
+	}
+
+	// Unknown backends and middleware are rejected
+	for _, input := range []string{
+		`{"routes": [{"prefix": "/api/foo", "backend": "nonexistent"}]}`,
+		`{"routes": [{"prefix": "/api/foo", "backend": "users", "middleware": ["nonexistent"]}]}`,
+	} {
+		table, err := parseRouteTable([]byte(input))
+		assert.NoError(t, err)
+		_, err = routes(cfg, authenticator, nil, nil, table)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestRouteTableHandler(t *testing.T) {
+	table := &RouteTable{Routes: []RouteTableEntry{{Prefix: "/api/foo", Backend: "users"}}}
+	r := newRouter()
+	Prefix{"/api/foo", noopHandler}.RegisterRoutes(r)
+
+	rr := httptest.NewRecorder()
+	routeTableHandler(table, r).ServeHTTP(rr, httptest.NewRequest("GET", "/admin/authfe/routes", nil))
+	assert.Equal(t, 200, rr.Code)
+
+	var dump struct {
+		Table  RouteTable
+		Routes []struct{ Path string }
+	}
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&dump))
+	assert.Equal(t, *table, dump.Table)
+	assert.Len(t, dump.Routes, 1)
+	assert.Equal(t, "/api/foo", dump.Routes[0].Path)
+}
+
+func TestRouteTableReload(t *testing.T) {
+	file, err := ioutil.TempFile("", "routes")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+	write := func(s string) {
+		assert.NoError(t, ioutil.WriteFile(file.Name(), []byte(s), 0644))
+	}
+
+	cfg, authenticator := routeTableTestConfig(t)
+	build := func(table *RouteTable) (http.Handler, error) {
+		return routes(cfg, authenticator, nil, nil, table)
+	}
+	get := func(h http.Handler) string {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", "/api/users/special", nil))
+		return rr.Body.String()
+	}
+
+	write(`{"routes": [{"prefix": "/api/users/special", "backend": "notebooks"}]}`)
+	table, contents, err := loadRouteTable(file.Name())
+	assert.NoError(t, err)
+	handler, err := build(table)
+	assert.NoError(t, err)
+	reloader := newRouteTableReloader(file.Name(), contents, handler, build)
+	assert.Equal(t, "notebooks", get(reloader))
+
+	// Valid change is picked up
+	write(`{"routes": [{"prefix": "/api/users/special", "backend": "billing-api"}]}`)
+	assert.NoError(t, reloader.reload())
+	assert.Equal(t, "billing-api", get(reloader))
+
+	// Invalid change is rejected, and the previous routes are kept
+	write(`{"routes": [{"prefix": "/api/users/special", "backend": "nonexistent"}]}`)
+	assert.Error(t, reloader.reload())
+	assert.Equal(t, "billing-api", get(reloader))
+
+	// Removing the route reverts to the built-in routes
+	write(`routes: []`)
+	assert.NoError(t, reloader.reload())
+	assert.Equal(t, "users", get(reloader))
+}
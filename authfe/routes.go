@@ -62,7 +62,9 @@ var noopHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 })
 
-func routes(c Config, authenticator users.UsersClient, ghIntegration *users_client.TokenRequester, eventLogger *EventLogger) (http.Handler, error) {
+// routes builds the handler for all public routes. Routes from the (optional)
+// route table take precedence over the built-in ones below.
+func routes(c Config, authenticator users.UsersClient, ghIntegration *users_client.TokenRequester, eventLogger *EventLogger, table *RouteTable) (http.Handler, error) {
 	launcherServiceLogger, probeHTTPlogger, uiHTTPlogger, analyticsLogger, webhooksLogger := middleware.Identity, middleware.Identity, middleware.Identity, middleware.Identity, middleware.Identity
 	if eventLogger != nil {
 		launcherServiceLogger = HTTPEventLogger{
@@ -148,7 +150,61 @@ func routes(c Config, authenticator users.UsersClient, ghIntegration *users_clie
 		})
 	})
 
+	uploadRateLimitMiddleware, err := newRateLimitMiddleware(c.uploadRateLimit, featureFlagsHeader)
+	if err != nil {
+		return nil, err
+	}
+	accessRateLimitMiddleware, err := newRateLimitMiddleware(c.accessRateLimit, featureFlagsHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	authProbeUploadMiddleware := users_client.AuthProbeMiddleware{
+		UsersClient:        authenticator,
+		FeatureFlagsHeader: featureFlagsHeader,
+		AuthorizeFor:       users.INSTANCE_DATA_UPLOAD,
+	}
+	authProbeAccessMiddleware := users_client.AuthProbeMiddleware{
+		UsersClient:        authenticator,
+		FeatureFlagsHeader: featureFlagsHeader,
+		AuthorizeFor:       users.INSTANCE_DATA_ACCESS,
+	}
+	authAdminMiddleware := users_client.AuthAdminMiddleware{
+		UsersClient: authenticator,
+	}
+
+	// Middleware which can be referred to by name in the route table
+	namedMiddleware := map[string]middleware.Interface{
+		"auth-probe-upload": authProbeUploadMiddleware,
+		"auth-probe-access": authProbeAccessMiddleware,
+		"auth-org":          authUserOrgDataAccessMiddleware,
+		"auth-org-billing":  billingAuthMiddleware,
+		"auth-user":         authUserMiddleware,
+		"auth-admin":        authAdminMiddleware,
+		"auth-webhook":      webhooksMiddleware,
+		"auth-gcp-webhook":  gcpWebhookSecretMiddleware,
+		"user-permissions":  userPermissionsMiddleware,
+		"scope-censor":      scopeCensorMiddleware,
+		"github-token":      fluxGHTokenMiddleware,
+		"no-cache-on-root":  noCacheOnRoot,
+		"ratelimit-upload":  uploadRateLimitMiddleware,
+		"ratelimit-access":  accessRateLimitMiddleware,
+		"log-probe":         probeHTTPlogger,
+		"log-ui":            uiHTTPlogger,
+		"log-analytics":     analyticsLogger,
+		"log-webhooks":      webhooksLogger,
+		"log-launcher":      launcherServiceLogger,
+		"strip-app-prefix":  middleware.PathRewrite(regexp.MustCompile("^/api/app/[^/]+"), ""),
+	}
+	tableRoutes, err := table.routables(c.proxies(), namedMiddleware)
+	if err != nil {
+		return nil, err
+	}
+
 	r := newRouter()
+	for _, route := range tableRoutes {
+		route.RegisterRoutes(r)
+	}
 
 	// Routes authenticated using header credentials
 	dataUploadRoutes := MiddlewarePrefix{
@@ -163,11 +219,8 @@ func routes(c Config, authenticator users.UsersClient, ghIntegration *users_clie
 			PrefixMethods{"/notification/external/events", []string{"POST"}, c.notificationEventHost},
 		},
 		middleware.Merge(
-			users_client.AuthProbeMiddleware{
-				UsersClient:        authenticator,
-				FeatureFlagsHeader: featureFlagsHeader,
-				AuthorizeFor:       users.INSTANCE_DATA_UPLOAD,
-			},
+			authProbeUploadMiddleware,
+			uploadRateLimitMiddleware,
 			probeHTTPlogger,
 		),
 	}
@@ -182,11 +235,8 @@ func routes(c Config, authenticator users.UsersClient, ghIntegration *users_clie
 			{"/prom", c.promQuerierHost},
 		}),
 		middleware.Merge(
-			users_client.AuthProbeMiddleware{
-				UsersClient:        authenticator,
-				FeatureFlagsHeader: featureFlagsHeader,
-				AuthorizeFor:       users.INSTANCE_DATA_ACCESS,
-			},
+			authProbeAccessMiddleware,
+			accessRateLimitMiddleware,
 			probeHTTPlogger,
 		),
 	}
@@ -270,6 +320,19 @@ func routes(c Config, authenticator users.UsersClient, ghIntegration *users_clie
 			),
 		},
 
+		// Users grant us access to their cloud accounts outside of any
+		// organization, as providers send them back to a fixed URL.
+		MiddlewarePrefix{
+			"/api/gcp/providers",
+			[]PrefixRoutable{
+				Prefix{"/", c.gcpServiceHost},
+			},
+			middleware.Merge(
+				authUserMiddleware,
+				uiHTTPlogger,
+			),
+		},
+
 		// Google Single Sign-On for GCP Cloud Launcher integration.
 		MiddlewarePrefix{
 			"/api/users/gcp/sso/login",
@@ -349,6 +412,7 @@ func routes(c Config, authenticator users.UsersClient, ghIntegration *users_clie
 				{"/billing/enforcer", trimPrefix("/admin/billing/enforcer", c.billingEnforcerHost)},
 				{"/billing/uploader", trimPrefix("/admin/billing/uploader", c.billingUploaderHost)},
 				{"/billing/invoice-verify", c.billingAPIHost},
+				{"/billing/reconciliation", c.billingAPIHost},
 				{"/kubediff", trimPrefix("/admin/kubediff", c.kubediffHost)},
 				{"/terradiff", trimPrefix("/admin/terradiff", c.terradiffHost)},
 				{"/ansiblediff", trimPrefix("/admin/ansiblediff", c.ansiblediffHost)},
@@ -370,6 +434,7 @@ func routes(c Config, authenticator users.UsersClient, ghIntegration *users_clie
 				{"/corp-atlantis", trimPrefix("/admin/corp-atlantis", c.corpAtlantisHost)},
 				{"/corp-terradiff", trimPrefix("/admin/corp-terradiff", c.corpTerradiffHost)},
 				{"/corpdiff", trimPrefix("/admin/corpdiff", c.corpDiffHost)},
+				{"/authfe/routes", routeTableHandler(table, r)},
 				{"/", http.HandlerFunc(adminRoot)},
 			}),
 			middleware.Merge(
@@ -382,9 +447,7 @@ func routes(c Config, authenticator users.UsersClient, ghIntegration *users_clie
 						http.Redirect(w, r, fmt.Sprintf("/login?%s", q.Encode()), 302)
 					}),
 				},
-				users_client.AuthAdminMiddleware{
-					UsersClient: authenticator,
-				},
+				authAdminMiddleware,
 			),
 		},
 
@@ -444,6 +507,8 @@ func routes(c Config, authenticator users.UsersClient, ghIntegration *users_clie
 		`/api/app/[a-zA-Z0-9_-]+/api/prom/alertmanager`, // Regex copy-pasted from users/organization.go
 		"/api/users/signup_webhook",                     // Validated by explicit token in the users service
 		"/api/users/org/platform_version",               // Also validated by explicit token
+		`/api/users/sso/[a-zA-Z0-9_-]+/callback`,        // SAML responses POSTed by identity providers, validated by their signature
+		"/api/users/scim/",                              // Provisioning by teams' identity providers, validated by the team's SCIM token
 		"/webhooks",                                     // POSTed to by external services
 
 		"/admin/corp-atlantis", // GitHub webhook (/events) & discarding locks (/locks)
@@ -452,6 +517,7 @@ func routes(c Config, authenticator users.UsersClient, ghIntegration *users_clie
 		"/admin/prod-grafana",
 		"/admin/kibana", // kibana has the same issue with CSRF tokens as grafana
 	)
+	csrfExemptPrefixes = append(csrfExemptPrefixes, table.csrfExemptPrefixes()...)
 
 	// Strip csrf_token cookies set by the nosurf middleware because the nosurf
 	// middleware does not allow us to exclude paths for setting cookies, only
@@ -32,7 +32,7 @@ func TestRoutes(t *testing.T) {
 	}
 
 	// Create the routes handler
-	handler, err := routes(cfg, authenticator, nil, nil)
+	handler, err := routes(cfg, authenticator, nil, nil, nil)
 	assert.NoError(t, err, "Error creating the routes handler")
 
 	tests := []struct {
@@ -7,25 +7,25 @@ import (
 	"time"
 
 	"github.com/weaveworks/common/instrument"
+	"github.com/weaveworks/service/billing-aggregator/usage"
 	"github.com/weaveworks/service/billing-api/db"
-	"github.com/weaveworks/service/common/bigquery"
 )
 
 const batchSize = 100
 
-// Aggregate reads events from bigquery and stores them in the database.
+// Aggregate reads aggregated events from a usage source, e.g. BigQuery, and stores them in the database.
 type Aggregate struct {
-	bigqueryClient bigquery.Client
-	db             db.DB
-	collector      *instrument.JobCollector
+	source    usage.Source
+	db        db.DB
+	collector *instrument.JobCollector
 }
 
 // NewAggregate creates an Aggregate instance.
-func NewAggregate(bigquery bigquery.Client, db db.DB, collector *instrument.JobCollector) *Aggregate {
+func NewAggregate(source usage.Source, db db.DB, collector *instrument.JobCollector) *Aggregate {
 	return &Aggregate{
-		bigqueryClient: bigquery,
-		db:             db,
-		collector:      collector,
+		source:    source,
+		db:        db,
+		collector: collector,
 	}
 }
 
@@ -48,12 +48,12 @@ func (j *Aggregate) Do(since *time.Time) error {
 	}
 	since = &t
 	return instrument.CollectedRequest(context.Background(), "Aggregate.Do", j.collector, nil, func(ctx context.Context) error {
-		aggs, err := j.bigqueryClient.Aggregates(ctx, *since)
+		aggs, err := j.source.Aggregates(ctx, *since)
 		if err != nil {
 			return err
 		}
 
-		log.Infof("Received %d records from BigQuery since %q", len(aggs), since)
+		log.Infof("Received %d records from usage source since %q", len(aggs), since)
 
 		for _, agg := range aggs {
 			log.Debugf("%+v", agg)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"net/http"
 	"time"
@@ -14,8 +15,8 @@ import (
 	"github.com/weaveworks/common/server"
 	"github.com/weaveworks/common/tracing"
 	"github.com/weaveworks/service/billing-aggregator/job"
+	"github.com/weaveworks/service/billing-aggregator/usage"
 	"github.com/weaveworks/service/billing-api/db"
-	"github.com/weaveworks/service/common/bigquery"
 	"github.com/weaveworks/service/common/dbconfig"
 )
 
@@ -49,12 +50,12 @@ func main() {
 			"cron-spec",
 			"0 10 * * * *", // Hourly at 10 minutes past - Seconds, Minutes, Hours, Day of month, Month, Day of week
 			"Cron spec for periodic query execution.")
-		serverConfig   server.Config
-		bigQueryConfig bigquery.Config
-		dbConfig       dbconfig.Config
+		serverConfig server.Config
+		usageConfig  usage.Config
+		dbConfig     dbconfig.Config
 	)
 	serverConfig.RegisterFlags(flag.CommandLine)
-	bigQueryConfig.RegisterFlags(flag.CommandLine)
+	usageConfig.RegisterFlags(flag.CommandLine)
 	dbConfig.RegisterFlags(flag.CommandLine, "postgres://postgres@billing-db/billing?sslmode=disable", "Database to use.", "/migrations", "Migrations directory.")
 	flag.Parse()
 	serverConfig.MetricsNamespace = "billing"
@@ -67,16 +68,16 @@ func main() {
 	traceCloser := tracing.NewFromEnv("billing-aggregator")
 	defer traceCloser.Close()
 
-	bigqueryClient, err := bigquery.New(context.Background(), bigQueryConfig)
+	billingDB, err := db.New(dbConfig)
 	if err != nil {
-		log.Fatalf("Error initialising BigQuery client: %v", err)
+		log.Fatalf("Error initialising database client: %v", err)
 	}
+	defer billingDB.Close(context.Background())
 
-	db, err := db.New(dbConfig)
+	source, err := usage.New(context.Background(), usageConfig, billingDB)
 	if err != nil {
-		log.Fatalf("Error initialising database client: %v", err)
+		log.Fatalf("Error initialising usage source: %v", err)
 	}
-	defer db.Close(context.Background())
 
 	server, err := server.New(serverConfig)
 	if err != nil {
@@ -85,7 +86,7 @@ func main() {
 	defer server.Shutdown()
 
 	c := cron.New()
-	job := job.NewAggregate(bigqueryClient, db, jobCollector)
+	job := job.NewAggregate(source, billingDB, jobCollector)
 	c.AddJob(*cronSpec, job)
 	c.Start()
 	defer c.Stop()
@@ -113,6 +114,21 @@ func main() {
 			w.Write([]byte("Success"))
 		}
 	})
+	// Sources other than BigQuery take events directly, in the absence of billing-ingester.
+	if ingester, ok := source.(usage.Ingester); ok {
+		server.HTTP.Path("/events").Methods("POST").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var events []db.Event
+			if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := ingester.Ingest(r.Context(), events); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
 	// healthCheck handles a very simple health check
 	server.HTTP.Path("/healthcheck").Methods("GET").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
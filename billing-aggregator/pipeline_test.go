@@ -0,0 +1,154 @@
+package main_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/common/instrument"
+	aggregatorjob "github.com/weaveworks/service/billing-aggregator/job"
+	"github.com/weaveworks/service/billing-aggregator/usage"
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/billing-api/db/dbtest"
+	"github.com/weaveworks/service/billing-api/routes"
+	uploaderjob "github.com/weaveworks/service/billing-uploader/job"
+	uploaderusage "github.com/weaveworks/service/billing-uploader/job/usage"
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/common/zuora/mockzuora"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/mock_users"
+)
+
+// recordingZuoraClient is a Zuora client recording the usage uploaded to it.
+type recordingZuoraClient struct {
+	mockzuora.StubClient
+	uploaded []byte
+}
+
+func (z *recordingZuoraClient) GetAccount(ctx context.Context, zuoraAccountNumber string) (*zuora.Account, error) {
+	return &zuora.Account{
+		PaymentProviderID: "P" + zuoraAccountNumber,
+		Subscription: &zuora.AccountSubscription{
+			SubscriptionNumber: "S" + zuoraAccountNumber,
+			ChargeNumber:       "C" + zuoraAccountNumber,
+		},
+	}, nil
+}
+
+func (z *recordingZuoraClient) GetProductsUnitSet(ctx context.Context, productIDs []string) (map[string]bool, error) {
+	return map[string]bool{"node-seconds": true}, nil
+}
+
+func (z *recordingZuoraClient) UploadUsage(ctx context.Context, r io.Reader, id string) (zuora.UsageUploadID, error) {
+	var err error
+	z.uploaded, err = ioutil.ReadAll(r)
+	return "", err
+}
+
+// TestPipeline follows usage events from their ingestion to their upload to
+// Zuora, and to their display by billing-api.
+func TestPipeline(t *testing.T) {
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	start := time.Date(2017, 11, 27, 0, 0, 0, 0, time.UTC)
+	now := start.Add(2 * 24 * time.Hour)
+	org := users.Organization{
+		ID:                 "100",
+		ExternalID:         "pipeline-test",
+		ZuoraAccountNumber: "Wpipeline-test",
+		TrialExpiresAt:     start.Add(-24 * time.Hour),
+	}
+
+	u := mock_users.NewMockUsersClient(ctrl)
+	u.EXPECT().
+		GetBillableOrganizations(gomock.Any(), gomock.Any()).
+		Return(&users.GetBillableOrganizationsResponse{Organizations: []users.Organization{org}}, nil).
+		AnyTimes()
+	u.EXPECT().
+		GetOrganization(gomock.Any(), gomock.Any()).
+		Return(&users.GetOrganizationResponse{Organization: org}, nil).
+		AnyTimes()
+
+	// Ingest events, as billing-ingester would.
+	source := usage.NewDatabaseSource(d)
+	require.NoError(t, source.Ingest(ctx, []db.Event{
+		{UniqueKey: "1", InternalInstanceID: "100", AmountType: "node-seconds", AmountValue: 3600, OccurredAt: start, ReceivedAt: start.Add(10 * time.Minute)},
+		{UniqueKey: "2", InternalInstanceID: "100", AmountType: "node-seconds", AmountValue: 1800, OccurredAt: start, ReceivedAt: start.Add(40 * time.Minute)},
+		{UniqueKey: "3", InternalInstanceID: "100", AmountType: "node-seconds", AmountValue: 7200, OccurredAt: start, ReceivedAt: start.Add(70 * time.Minute)},
+		{UniqueKey: "4", InternalInstanceID: "100", AmountType: "flux-release", AmountValue: 1, OccurredAt: start, ReceivedAt: start.Add(15 * time.Minute)},
+	}))
+	// Retried by the client, and so deduplicated.
+	require.NoError(t, source.Ingest(ctx, []db.Event{
+		{UniqueKey: "2", InternalInstanceID: "100", AmountType: "node-seconds", AmountValue: 1800, OccurredAt: start, ReceivedAt: start.Add(41 * time.Minute)},
+	}))
+
+	// Aggregate them into the billing database.
+	aggregate := aggregatorjob.NewAggregate(source, d, instrument.NewJobCollector("billing_TestPipeline_aggregate"))
+	require.NoError(t, aggregate.Do(&start))
+	// Aggregating again doesn't count usage twice.
+	require.NoError(t, aggregate.Do(&start))
+	aggs, err := d.GetAggregates(ctx, "100", start, now)
+	require.NoError(t, err)
+	require.Len(t, aggs, 3)
+
+	// Upload them to Zuora, which only charges this account for node-seconds.
+	z := &recordingZuoraClient{}
+	upload := uploaderjob.NewUsageUpload(d, u, uploaderusage.NewZuora(z, d), instrument.NewJobCollector("billing_TestPipeline_upload"))
+	require.NoError(t, upload.Do(now))
+	records, err := csv.NewReader(bytes.NewReader(z.uploaded)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2) // headers + one row
+	assert.Equal(t, []string{
+		"PWpipeline-test",
+		"node-seconds",
+		"12600",
+		"11/27/2017",
+		"11/29/2017",
+		"SWpipeline-test",
+		"CWpipeline-test",
+	}, records[1][0:7])
+
+	// Show them in billing-api.
+	api, err := routes.New(routes.Config{}, d, u, z, nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/billing/pipeline-test/usage?start=2017-11-27T00:00:00Z&end=2017-11-28T00:00:00Z", nil)
+	api.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	var usages []routes.Usage
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&usages))
+	require.Len(t, usages, 2)
+	assert.Equal(t, []int64{5400, 7200}, []int64{usages[0].NodeSeconds, usages[1].NodeSeconds})
+
+	// Broken down per amount type.
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/api/billing/pipeline-test/usage?start=2017-11-27T00:00:00Z&end=2017-11-28T00:00:00Z&version=2", nil)
+	api.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	var breakdown routes.UsageBreakdown
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&breakdown))
+	require.Len(t, breakdown.Types, 2)
+	assert.Equal(t, "flux-release", breakdown.Types[0].AmountType)
+	assert.Equal(t, "deploys", breakdown.Types[0].Product)
+	assert.Equal(t, int64(1), breakdown.Types[0].Total)
+	assert.Equal(t, "node-seconds", breakdown.Types[1].AmountType)
+	assert.Equal(t, "nodes", breakdown.Types[1].Product)
+	assert.Equal(t, int64(12600), breakdown.Types[1].Total)
+	assert.Len(t, breakdown.Types[1].Buckets, 2)
+}
@@ -0,0 +1,32 @@
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/weaveworks/service/billing-api/db"
+)
+
+// DatabaseSource stores events in the billing database, and aggregates them there.
+type DatabaseSource struct {
+	db  db.DB
+	now func() time.Time
+}
+
+// NewDatabaseSource creates a source storing events in the provided database.
+func NewDatabaseSource(d db.DB) *DatabaseSource {
+	return &DatabaseSource{db: d, now: time.Now}
+}
+
+// Ingest stores events, ignoring those already stored.
+func (s *DatabaseSource) Ingest(ctx context.Context, events []db.Event) error {
+	if err := validate(events, s.now().UTC()); err != nil {
+		return err
+	}
+	return s.db.InsertEvents(ctx, events)
+}
+
+// Aggregates sums up the stored events received since the provided time.
+func (s *DatabaseSource) Aggregates(ctx context.Context, since time.Time) ([]db.Aggregate, error) {
+	return s.db.GetEventAggregates(ctx, since)
+}
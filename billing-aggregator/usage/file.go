@@ -0,0 +1,79 @@
+package usage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/weaveworks/service/billing-api/db"
+)
+
+// FileSource reads events from a file of newline-delimited JSON, e.g. as
+// written by fluentd's file output, and aggregates them in memory. It is meant
+// for local development, rather than for large amounts of usage.
+type FileSource struct {
+	path string
+	now  func() time.Time
+	mtx  sync.Mutex
+}
+
+// NewFileSource creates a source reading events from the provided file.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path, now: time.Now}
+}
+
+// Ingest appends events to the file, creating it if needed.
+func (s *FileSource) Ingest(ctx context.Context, events []db.Event) error {
+	if err := validate(events, s.now().UTC()); err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Aggregates reads all events in the file, and sums up those received since
+// the provided time. A missing file has no events.
+func (s *FileSource) Aggregates(ctx context.Context, since time.Time) ([]db.Aggregate, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []db.Event
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var e db.Event
+		if err := dec.Decode(&e); err != nil {
+			return nil, fmt.Errorf("reading events from %s: %v", s.path, err)
+		}
+		events = append(events, e)
+	}
+	return db.AggregateEvents(events, since), nil
+}
@@ -0,0 +1,83 @@
+package usage
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/common/bigquery"
+)
+
+// Names of the supported usage sources.
+const (
+	BigQuery = "bigquery"
+	Database = "database"
+	File     = "file"
+)
+
+// Source computes hourly usage aggregates from the events sent by
+// billing-ingester. bigquery.DefaultClient is one.
+type Source interface {
+	// Aggregates sums up the events received since the provided time, per
+	// instance, amount type and hour.
+	Aggregates(ctx context.Context, since time.Time) ([]db.Aggregate, error)
+}
+
+// Ingester is implemented by sources which events can be sent to directly,
+// rather than through billing-ingester.
+type Ingester interface {
+	Ingest(ctx context.Context, events []db.Event) error
+}
+
+// Config holds settings for the usage source.
+type Config struct {
+	Source   string
+	File     string
+	BigQuery bigquery.Config
+}
+
+// RegisterFlags registers configuration variables.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&cfg.Source, "usage.source", BigQuery, fmt.Sprintf("Where to read usage events from: %q, %q (the events table of the billing database) or %q.", BigQuery, Database, File))
+	f.StringVar(&cfg.File, "usage.file", "", "File of newline-delimited JSON usage events, with -usage.source=file.")
+	cfg.BigQuery.RegisterFlags(f)
+}
+
+// New creates the configured usage source. The database is used as the
+// event store with -usage.source=database.
+func New(ctx context.Context, cfg Config, d db.DB) (Source, error) {
+	switch cfg.Source {
+	case BigQuery:
+		client, err := bigquery.New(ctx, cfg.BigQuery)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case Database:
+		return NewDatabaseSource(d), nil
+	case File:
+		if cfg.File == "" {
+			return nil, fmt.Errorf("-usage.file is required with -usage.source=%s", File)
+		}
+		return NewFileSource(cfg.File), nil
+	default:
+		return nil, fmt.Errorf("unknown usage source %q, expected one of: %s, %s, %s", cfg.Source, BigQuery, Database, File)
+	}
+}
+
+// validate checks events have the fields required by the BigQuery schema, and
+// sets their reception time, as billing-ingester would.
+func validate(events []db.Event, now time.Time) error {
+	for i := range events {
+		e := &events[i]
+		if e.UniqueKey == "" || e.InternalInstanceID == "" || e.AmountType == "" || e.OccurredAt.IsZero() {
+			return fmt.Errorf("event %d: unique_key, internal_instance_id, amount_type and occurred_at are required", i)
+		}
+		if e.ReceivedAt.IsZero() {
+			e.ReceivedAt = now
+		}
+	}
+	return nil
+}
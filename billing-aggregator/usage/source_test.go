@@ -0,0 +1,96 @@
+package usage_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/billing-aggregator/usage"
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/billing-api/db/dbtest"
+)
+
+var (
+	bucket = time.Date(2018, 06, 15, 9, 0, 0, 0, time.UTC)
+	events = []db.Event{
+		{UniqueKey: "a", InternalInstanceID: "100", AmountType: "node-seconds", AmountValue: 10, OccurredAt: bucket, ReceivedAt: bucket.Add(5 * time.Minute)},
+		{UniqueKey: "b", InternalInstanceID: "100", AmountType: "node-seconds", AmountValue: 20, OccurredAt: bucket, ReceivedAt: bucket.Add(55 * time.Minute)},
+		{UniqueKey: "c", InternalInstanceID: "100", AmountType: "container-seconds", AmountValue: 30, OccurredAt: bucket, ReceivedAt: bucket.Add(10 * time.Minute)},
+		{UniqueKey: "d", InternalInstanceID: "101", AmountType: "node-seconds", AmountValue: 40, OccurredAt: bucket, ReceivedAt: bucket.Add(65 * time.Minute)},
+		// Received before the aggregation starts.
+		{UniqueKey: "e", InternalInstanceID: "100", AmountType: "node-seconds", AmountValue: 50, OccurredAt: bucket, ReceivedAt: bucket.Add(-5 * time.Minute)},
+	}
+	// Sent again by a client retrying.
+	duplicate = db.Event{UniqueKey: "a", InternalInstanceID: "100", AmountType: "node-seconds", AmountValue: 10, OccurredAt: bucket, ReceivedAt: bucket.Add(6 * time.Minute)}
+
+	expected = []db.Aggregate{
+		{InstanceID: "100", BucketStart: bucket, AmountType: "container-seconds", AmountValue: 30},
+		{InstanceID: "100", BucketStart: bucket, AmountType: "node-seconds", AmountValue: 30},
+		{InstanceID: "101", BucketStart: bucket.Add(time.Hour), AmountType: "node-seconds", AmountValue: 40},
+	}
+)
+
+type ingestingSource interface {
+	usage.Source
+	usage.Ingester
+}
+
+func testSource(t *testing.T, source ingestingSource) {
+	ctx := context.Background()
+	require.NoError(t, source.Ingest(ctx, events))
+	require.NoError(t, source.Ingest(ctx, []db.Event{duplicate}))
+
+	aggs, err := source.Aggregates(ctx, bucket)
+	require.NoError(t, err)
+	assert.Equal(t, expected, aggs)
+
+	aggs, err = source.Aggregates(ctx, bucket.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, expected[2:], aggs)
+
+	err = source.Ingest(ctx, []db.Event{{UniqueKey: "f", AmountType: "node-seconds", OccurredAt: bucket}})
+	assert.Error(t, err)
+}
+
+func TestDatabaseSource(t *testing.T) {
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+
+	testSource(t, usage.NewDatabaseSource(d))
+}
+
+func TestFileSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "usage")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.json")
+
+	source := usage.NewFileSource(path)
+	aggs, err := source.Aggregates(context.Background(), bucket)
+	assert.NoError(t, err)
+	assert.Empty(t, aggs)
+
+	testSource(t, source)
+}
+
+func TestNew(t *testing.T) {
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+	ctx := context.Background()
+
+	source, err := usage.New(ctx, usage.Config{Source: usage.Database}, d)
+	assert.NoError(t, err)
+	assert.IsType(t, &usage.DatabaseSource{}, source)
+
+	_, err = usage.New(ctx, usage.Config{Source: usage.File}, d)
+	assert.Error(t, err)
+
+	_, err = usage.New(ctx, usage.Config{Source: "nope"}, d)
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package budgets
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/common/instrument"
+	"github.com/weaveworks/common/logging"
+	"github.com/weaveworks/common/user"
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/billing-api/forecast"
+	"github.com/weaveworks/service/notification-eventmanager/types"
+	"github.com/weaveworks/service/users"
+)
+
+var notificationsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "billing",
+	Subsystem: "budgets",
+	Name:      "notifications_total",
+	Help:      "The number of budget thresholds crossed notified about",
+})
+
+func init() {
+	prometheus.MustRegister(notificationsTotal)
+}
+
+// Job notifies instances whose cost so far this month crossed a threshold of
+// their budget. Each threshold is notified about at most once a month, and
+// only the highest one crossed since the job last ran.
+type Job struct {
+	db         db.DB
+	users      users.UsersClient
+	forecaster *forecast.Forecaster
+	notifier   Notifier
+	collector  *instrument.JobCollector
+}
+
+// NewJob creates a Job.
+func NewJob(db db.DB, users users.UsersClient, forecaster *forecast.Forecaster, notifier Notifier, collector *instrument.JobCollector) *Job {
+	return &Job{
+		db:         db,
+		users:      users,
+		forecaster: forecaster,
+		notifier:   notifier,
+		collector:  collector,
+	}
+}
+
+// Run starts the job and logs errors.
+func (j *Job) Run() {
+	if err := j.Do(time.Now()); err != nil {
+		log.Errorf("Error running budgets job: %v", err)
+	}
+}
+
+// Do checks all budgets against the cost of their instance in the month of
+// now, and returns an error if it fails.
+func (j *Job) Do(now time.Time) error {
+	return instrument.CollectedRequest(context.Background(), "Budgets.Do", j.collector, nil, func(ctx context.Context) error {
+		logger := user.LogWith(ctx, logging.Global())
+
+		budgets, err := j.db.GetBudgets(ctx)
+		if err != nil {
+			return err
+		}
+		for _, b := range budgets {
+			if err := j.check(ctx, b, now); err != nil {
+				logger.Errorf("Failed to check budget of instance %v: %v", b.InstanceID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (j *Job) check(ctx context.Context, b db.Budget, now time.Time) error {
+	resp, err := j.users.GetOrganization(ctx, &users.GetOrganizationRequest{
+		ID: &users.GetOrganizationRequest_InternalID{InternalID: b.InstanceID},
+	})
+	if err != nil {
+		return err
+	}
+	fc, err := j.forecaster.Forecast(ctx, &resp.Organization, b.Currency, now)
+	if err != nil {
+		return err
+	}
+
+	notified := 0
+	if b.NotifiedMonth.Equal(fc.Start) {
+		notified = b.NotifiedThreshold
+	}
+	threshold := crossedThreshold(b, fc.MonthToDate, notified)
+	if threshold == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(types.BudgetData{
+		Month:       fc.Start.Format("2006-01"),
+		Threshold:   threshold,
+		Budget:      b.Amount,
+		Currency:    b.Currency,
+		MonthToDate: fc.MonthToDate,
+		Projected:   fc.Projected,
+	})
+	if err != nil {
+		return err
+	}
+	if err := j.notifier.Notify(ctx, types.Event{
+		Type:       types.BillingBudgetType,
+		InstanceID: b.InstanceID,
+		Timestamp:  now,
+		Data:       data,
+	}); err != nil {
+		return err
+	}
+	notificationsTotal.Inc()
+	return j.db.SetBudgetNotified(ctx, b.InstanceID, fc.Start, threshold)
+}
+
+// crossedThreshold returns the highest threshold of the budget the cost
+// crossed above the one already notified, or 0 if there is none.
+func crossedThreshold(b db.Budget, cost float64, notified int) int {
+	crossed := 0
+	for _, t := range b.Thresholds {
+		if t > notified && t > crossed && cost >= b.Amount*float64(t)/100 {
+			crossed = t
+		}
+	}
+	return crossed
+}
@@ -0,0 +1,105 @@
+package budgets_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/common/instrument"
+	"github.com/weaveworks/service/billing-api/budgets"
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/billing-api/db/dbtest"
+	"github.com/weaveworks/service/billing-api/forecast"
+	"github.com/weaveworks/service/common/constants/billing"
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/notification-eventmanager/types"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/mock_users"
+)
+
+type stubRates struct{}
+
+func (stubRates) GetCurrentRates(ctx context.Context) (zuora.RateMap, error) {
+	return zuora.RateMap{billing.UsageNodeSeconds: {"USD": 0.01}}, nil
+}
+
+type stubNotifier struct {
+	events []types.Event
+}
+
+func (n *stubNotifier) Notify(ctx context.Context, ev types.Event) error {
+	n.events = append(n.events, ev)
+	return nil
+}
+
+func TestJob_Do(t *testing.T) {
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	u := mock_users.NewMockUsersClient(ctrl)
+	u.EXPECT().
+		GetOrganization(gomock.Any(), gomock.Any()).
+		Return(&users.GetOrganizationResponse{Organization: users.Organization{ID: "100"}}, nil).
+		AnyTimes()
+	n := &stubNotifier{}
+	job := budgets.NewJob(d, u, forecast.NewForecaster(d, stubRates{}, nil), n, instrument.NewJobCollector("test"))
+
+	_, err := d.SetBudget(ctx, "100", 100, "USD", []int{50, 80, 100})
+	require.NoError(t, err)
+	start := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+	insert := func(bucketStart time.Time, value int64) {
+		require.NoError(t, d.InsertAggregates(ctx, []db.Aggregate{
+			{InstanceID: "100", BucketStart: bucketStart, AmountType: billing.UsageNodeSeconds, AmountValue: value},
+		}))
+	}
+
+	// 40 USD: no threshold crossed.
+	insert(start, 4000)
+	require.NoError(t, job.Do(start.AddDate(0, 0, 1)))
+	assert.Len(t, n.events, 0)
+
+	// 90 USD: only the highest threshold crossed is notified.
+	insert(start.Add(time.Hour), 5000)
+	require.NoError(t, job.Do(start.AddDate(0, 0, 2)))
+	require.Len(t, n.events, 1)
+	assert.Equal(t, types.BillingBudgetType, n.events[0].Type)
+	assert.Equal(t, "100", n.events[0].InstanceID)
+	var data types.BudgetData
+	require.NoError(t, json.Unmarshal(n.events[0].Data, &data))
+	assert.Equal(t, 80, data.Threshold)
+	assert.Equal(t, "2018-06", data.Month)
+	assert.InDelta(t, 90, data.MonthToDate, 1e-9)
+
+	// Thresholds are notified about once.
+	require.NoError(t, job.Do(start.AddDate(0, 0, 3)))
+	assert.Len(t, n.events, 1)
+
+	// 110 USD.
+	insert(start.Add(2*time.Hour), 2000)
+	require.NoError(t, job.Do(start.AddDate(0, 0, 4)))
+	require.Len(t, n.events, 2)
+	require.NoError(t, json.Unmarshal(n.events[1].Data, &data))
+	assert.Equal(t, 100, data.Threshold)
+
+	// A new month starts over.
+	next := start.AddDate(0, 1, 0)
+	insert(next, 6000)
+	require.NoError(t, job.Do(next.AddDate(0, 0, 1)))
+	require.Len(t, n.events, 3)
+	require.NoError(t, json.Unmarshal(n.events[2].Data, &data))
+	assert.Equal(t, 50, data.Threshold)
+	assert.Equal(t, "2018-07", data.Month)
+
+	b, err := d.GetBudget(ctx, "100")
+	require.NoError(t, err)
+	assert.Equal(t, next, b.NotifiedMonth.UTC())
+	assert.Equal(t, 50, b.NotifiedThreshold)
+}
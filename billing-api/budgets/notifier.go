@@ -0,0 +1,65 @@
+package budgets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/common/user"
+	"github.com/weaveworks/service/notification-eventmanager/types"
+)
+
+// Notifier sends events to the members of an instance.
+type Notifier interface {
+	Notify(ctx context.Context, ev types.Event) error
+}
+
+// EventNotifier posts events to the notification service.
+type EventNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewEventNotifier creates an EventNotifier posting to the notification
+// service's events URL.
+func NewEventNotifier(url string) *EventNotifier {
+	return &EventNotifier{url: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Notify posts the event on behalf of its instance.
+func (n *EventNotifier) Notify(ctx context.Context, ev types.Event) error {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(ev); err != nil {
+		return errors.Wrap(err, "encoding event")
+	}
+
+	req, err := http.NewRequest("POST", n.url, buf)
+	if err != nil {
+		return errors.Wrap(err, "constructing HTTP request")
+	}
+
+	req = req.WithContext(user.InjectOrgID(ctx, ev.InstanceID))
+	if err := user.InjectOrgIDIntoHTTPRequest(req.Context(), req); err != nil {
+		return errors.Wrap(err, "injecting orgID into HTTP request")
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "executing HTTP POST to notification service")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+		return fmt.Errorf("%s from eventmanager (%s)", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
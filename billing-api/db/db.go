@@ -20,10 +20,31 @@ type Aggregate struct {
 	UploadID    int64
 }
 
+// Event represents a database row in table `events`. It follows the schema of
+// the events billing-ingester sends to BigQuery.
+type Event struct {
+	UniqueKey          string    `json:"unique_key"`
+	InternalInstanceID string    `json:"internal_instance_id"`
+	AmountType         string    `json:"amount_type"`
+	AmountValue        int64     `json:"amount_value"`
+	OccurredAt         time.Time `json:"occurred_at"`
+	ReceivedAt         time.Time `json:"received_at"`
+	Metadata           string    `json:"metadata,omitempty"`
+}
+
 // UsageUpload represents a database row in table `usage_uploads`.
 type UsageUpload struct {
 	ID       int64
 	Uploader string
+	// Name is what the upload was sent to the billing provider as.
+	Name string
+	// ImportID identifies the upload at the billing provider, if it has such
+	// an id, e.g. the Zuora usage import id.
+	ImportID  string
+	StartedAt time.Time
+	// ReconciledAt is when the upload was compared with what the billing
+	// provider accepted. It is zero until then.
+	ReconciledAt time.Time
 }
 
 // PostTrialInvoice represents a database row in table `post_trial_invoices`.
@@ -34,6 +55,50 @@ type PostTrialInvoice struct {
 	CreatedAt          time.Time
 }
 
+// StripeCustomer represents a database row in table `stripe_customers`.
+type StripeCustomer struct {
+	TeamID     string
+	CustomerID string
+	CreatedAt  time.Time
+}
+
+// Budget represents a database row in table `budgets`: the monthly budget
+// of an instance.
+type Budget struct {
+	InstanceID string
+	Amount     float64
+	Currency   string
+	// Thresholds are percentages of Amount; teams are notified when their
+	// month-to-date cost crosses one of them.
+	Thresholds []int
+	// NotifiedThreshold is the highest threshold notified about in NotifiedMonth.
+	NotifiedMonth     time.Time
+	NotifiedThreshold int
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// Discrepancy represents a database row in table
+// `reconciliation_discrepancies`: usage of a product by an instance in an
+// upload, of which the billing provider accepted less than was uploaded.
+type Discrepancy struct {
+	ID         int64
+	UploadID   int64
+	Uploader   string
+	InstanceID string
+	Product    string
+	// AmountTypes are those of the aggregates the product is metered in.
+	AmountTypes []string
+	Expected    int64
+	Accepted    int64
+	Reason      string
+	DetectedAt  time.Time
+	// ResolvedAt is zero until an admin resolves the discrepancy.
+	ResolvedAt time.Time
+	// Requeued tells whether the aggregates were uploaded again when resolving it.
+	Requeued bool
+}
+
 // DB is the interface for the database.
 type DB interface {
 	InsertAggregates(ctx context.Context, aggregates []Aggregate) error
@@ -52,6 +117,27 @@ type DB interface {
 	DeleteUsageUpload(ctx context.Context, uploader string, uploadID int64) error
 	// GetLatestUsageUpload finds the latest usage upload, optionally matching the given uploader name
 	GetLatestUsageUpload(ctx context.Context, uploader string) (*UsageUpload, error)
+	// SetUsageUploadImport records what an upload was sent to the billing provider as.
+	SetUsageUploadImport(ctx context.Context, uploadID int64, name, importID string) error
+	// GetUsageUploadsToReconcile returns the uploads of the uploader that weren't reconciled yet.
+	GetUsageUploadsToReconcile(ctx context.Context, uploader string) ([]UsageUpload, error)
+	// SetUsageUploadReconciled records that an upload was reconciled.
+	SetUsageUploadReconciled(ctx context.Context, uploadID int64) error
+
+	InsertDiscrepancies(ctx context.Context, discrepancies []Discrepancy) error
+	// GetDiscrepancies returns the discrepancies which haven't been resolved.
+	GetDiscrepancies(ctx context.Context) ([]Discrepancy, error)
+	// GetDiscrepancy returns a discrepancy, or nil if there is none with the id.
+	GetDiscrepancy(ctx context.Context, id int64) (*Discrepancy, error)
+	// ResolveDiscrepancy marks a discrepancy resolved. With requeue, its
+	// aggregates are unlinked from their upload, so that they are uploaded again.
+	ResolveDiscrepancy(ctx context.Context, id int64, requeue bool) error
+
+	// InsertEvents stores usage events, ignoring those whose unique key was already stored.
+	InsertEvents(ctx context.Context, events []Event) error
+	// GetEventAggregates sums up the events received since the provided time,
+	// into hourly aggregates per instance and amount type.
+	GetEventAggregates(ctx context.Context, since time.Time) ([]Aggregate, error)
 
 	GetMonthSums(ctx context.Context, instanceIDs []string, from, through time.Time) (map[string][]Aggregate, error)
 
@@ -65,6 +151,28 @@ type DB interface {
 	// SetTeamBillingAccountProvider makes sure a team has a billing
 	// account reflecting the given provider name.
 	SetTeamBillingAccountProvider(ctx context.Context, teamID, providerName string) (*grpc.BillingAccount, error)
+	// GetBillingAccountProviders returns the provider of each team whose
+	// billing account isn't billed through the default one, by team ID.
+	GetBillingAccountProviders(ctx context.Context) (map[string]string, error)
+
+	// SetStripeCustomer records the Stripe customer a team is billed as.
+	SetStripeCustomer(ctx context.Context, teamID, customerID string) error
+	// FindStripeCustomerByTeamID returns the Stripe customer of a team, or nil if it has none.
+	FindStripeCustomerByTeamID(ctx context.Context, teamID string) (*StripeCustomer, error)
+	// GetStripeCustomers returns the Stripe customers of all teams.
+	GetStripeCustomers(ctx context.Context) ([]StripeCustomer, error)
+
+	// SetBudget sets the monthly budget of an instance. Changing a budget
+	// forgets about the thresholds already notified.
+	SetBudget(ctx context.Context, instanceID string, amount float64, currency string, thresholds []int) (*Budget, error)
+	// GetBudget returns the budget of an instance, or nil if it has none.
+	GetBudget(ctx context.Context, instanceID string) (*Budget, error)
+	// GetBudgets returns the budgets of all instances.
+	GetBudgets(ctx context.Context) ([]Budget, error)
+	DeleteBudget(ctx context.Context, instanceID string) error
+	// SetBudgetNotified records that an instance was notified about a
+	// threshold of its budget in a month.
+	SetBudgetNotified(ctx context.Context, instanceID string, month time.Time, threshold int) error
 
 	// Transaction runs the given function in a transaction. If fn returns
 	// an error the txn will be rolled back.
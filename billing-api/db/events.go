@@ -0,0 +1,54 @@
+package db
+
+import (
+	"sort"
+	"time"
+)
+
+type eventKey struct {
+	instanceID  string
+	bucketStart time.Time
+	amountType  string
+}
+
+// AggregateEvents sums up the events received since the provided time, into
+// hourly aggregates per instance and amount type, the same way aggregates are
+// computed from the events stored in BigQuery. Events sharing a unique key
+// are only counted once.
+func AggregateEvents(events []Event, since time.Time) []Aggregate {
+	seen := map[string]struct{}{}
+	sums := map[eventKey]int64{}
+	for _, e := range events {
+		if e.ReceivedAt.IsZero() || e.ReceivedAt.Before(since) {
+			continue
+		}
+		if _, ok := seen[e.UniqueKey]; ok {
+			continue
+		}
+		seen[e.UniqueKey] = struct{}{}
+		sums[eventKey{
+			instanceID:  e.InternalInstanceID,
+			bucketStart: e.ReceivedAt.UTC().Truncate(time.Hour),
+			amountType:  e.AmountType,
+		}] += e.AmountValue
+	}
+	aggs := make([]Aggregate, 0, len(sums))
+	for k, sum := range sums {
+		aggs = append(aggs, Aggregate{
+			InstanceID:  k.instanceID,
+			BucketStart: k.bucketStart,
+			AmountType:  k.amountType,
+			AmountValue: sum,
+		})
+	}
+	sort.Slice(aggs, func(i, j int) bool {
+		if !aggs[i].BucketStart.Equal(aggs[j].BucketStart) {
+			return aggs[i].BucketStart.Before(aggs[j].BucketStart)
+		}
+		if aggs[i].InstanceID != aggs[j].InstanceID {
+			return aggs[i].InstanceID < aggs[j].InstanceID
+		}
+		return aggs[i].AmountType < aggs[j].AmountType
+	})
+	return aggs
+}
@@ -16,8 +16,13 @@ type memory struct {
 	mtx                     sync.RWMutex
 	aggregatesSet           map[int]Aggregate // To allow for O(1) presence checks.
 	uploads                 []*UsageUpload
+	events                  []Event
+	eventKeys               map[string]struct{}
 	postTrialInvoices       map[string]PostTrialInvoice
 	billingAccountsByTeamID map[string]*grpc.BillingAccount
+	stripeCustomers         map[string]StripeCustomer
+	budgets                 map[string]Budget
+	discrepancies           []Discrepancy
 }
 
 // New creates a new in-memory database
@@ -25,7 +30,10 @@ func newMemory() *memory {
 	return &memory{
 		aggregatesSet:           make(map[int]Aggregate),
 		postTrialInvoices:       make(map[string]PostTrialInvoice),
+		eventKeys:               make(map[string]struct{}),
 		billingAccountsByTeamID: make(map[string]*grpc.BillingAccount),
+		stripeCustomers:         make(map[string]StripeCustomer),
+		budgets:                 make(map[string]Budget),
 		uploads:                 []*UsageUpload{},
 	}
 }
@@ -158,7 +166,7 @@ func (db *memory) InsertUsageUpload(ctx context.Context, uploader string, aggreg
 	defer db.mtx.RUnlock()
 
 	uploadID := int64(len(db.uploads) + 1) // for this in-memory DB we're using 0 as a proxy for a DB null
-	db.uploads = append(db.uploads, &UsageUpload{ID: uploadID, Uploader: uploader})
+	db.uploads = append(db.uploads, &UsageUpload{ID: uploadID, Uploader: uploader, StartedAt: time.Now()})
 	for _, id := range aggregateIDs {
 		agg := db.aggregatesSet[id]
 		agg.UploadID = uploadID
@@ -182,6 +190,25 @@ func (db *memory) DeleteUsageUpload(ctx context.Context, uploader string, upload
 	return nil
 }
 
+func (db *memory) InsertEvents(ctx context.Context, events []Event) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	for _, e := range events {
+		if _, ok := db.eventKeys[e.UniqueKey]; ok {
+			continue
+		}
+		db.eventKeys[e.UniqueKey] = struct{}{}
+		db.events = append(db.events, e)
+	}
+	return nil
+}
+
+func (db *memory) GetEventAggregates(ctx context.Context, since time.Time) ([]Aggregate, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	return AggregateEvents(db.events, since), nil
+}
+
 func (db *memory) GetMonthSums(ctx context.Context, instanceIDs []string, from, through time.Time) (map[string][]Aggregate, error) {
 	db.mtx.RLock()
 	defer db.mtx.RUnlock()
@@ -265,11 +292,125 @@ func (db *memory) FindBillingAccountByTeamID(ctx context.Context, teamID string)
 func (db *memory) SetTeamBillingAccountProvider(ctx context.Context, teamID, providerName string) (*grpc.BillingAccount, error) {
 	db.mtx.Lock()
 	defer db.mtx.Unlock()
-	if providerName != provider.External {
+	if providerName != provider.External && providerName != provider.Stripe {
 		providerName = ""
 	}
-	db.billingAccountsByTeamID[teamID].Provider = providerName
-	return db.billingAccountsByTeamID[teamID], nil
+	account, ok := db.billingAccountsByTeamID[teamID]
+	if !ok {
+		account = &grpc.BillingAccount{ID: uint32(len(db.billingAccountsByTeamID) + 1), CreatedAt: time.Now()}
+		db.billingAccountsByTeamID[teamID] = account
+	}
+	account.Provider = providerName
+	return account, nil
+}
+
+func (db *memory) GetBillingAccountProviders(ctx context.Context) (map[string]string, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	providers := map[string]string{}
+	for teamID, account := range db.billingAccountsByTeamID {
+		if account.Provider != "" {
+			providers[teamID] = account.Provider
+		}
+	}
+	return providers, nil
+}
+
+func (db *memory) SetStripeCustomer(ctx context.Context, teamID, customerID string) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	for _, c := range db.stripeCustomers {
+		if c.CustomerID == customerID && c.TeamID != teamID {
+			return fmt.Errorf("stripe customer %s already belongs to team %s", customerID, c.TeamID)
+		}
+	}
+	c, ok := db.stripeCustomers[teamID]
+	if !ok {
+		c = StripeCustomer{TeamID: teamID, CreatedAt: time.Now()}
+	}
+	c.CustomerID = customerID
+	db.stripeCustomers[teamID] = c
+	return nil
+}
+
+func (db *memory) FindStripeCustomerByTeamID(ctx context.Context, teamID string) (*StripeCustomer, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	c, ok := db.stripeCustomers[teamID]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (db *memory) GetStripeCustomers(ctx context.Context) ([]StripeCustomer, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	customers := []StripeCustomer{}
+	for _, c := range db.stripeCustomers {
+		customers = append(customers, c)
+	}
+	sort.Slice(customers, func(i, j int) bool { return customers[i].TeamID < customers[j].TeamID })
+	return customers, nil
+}
+
+func (db *memory) SetBudget(ctx context.Context, instanceID string, amount float64, currency string, thresholds []int) (*Budget, error) {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	now := time.Now()
+	b, ok := db.budgets[instanceID]
+	if !ok {
+		b = Budget{InstanceID: instanceID, CreatedAt: now}
+	}
+	b.Amount = amount
+	b.Currency = currency
+	b.Thresholds = append([]int{}, thresholds...)
+	b.NotifiedMonth = time.Time{}
+	b.NotifiedThreshold = 0
+	b.UpdatedAt = now
+	db.budgets[instanceID] = b
+	return &b, nil
+}
+
+func (db *memory) GetBudget(ctx context.Context, instanceID string) (*Budget, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	b, ok := db.budgets[instanceID]
+	if !ok {
+		return nil, nil
+	}
+	return &b, nil
+}
+
+func (db *memory) GetBudgets(ctx context.Context) ([]Budget, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	budgets := []Budget{}
+	for _, b := range db.budgets {
+		budgets = append(budgets, b)
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].InstanceID < budgets[j].InstanceID })
+	return budgets, nil
+}
+
+func (db *memory) DeleteBudget(ctx context.Context, instanceID string) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	delete(db.budgets, instanceID)
+	return nil
+}
+
+func (db *memory) SetBudgetNotified(ctx context.Context, instanceID string, month time.Time, threshold int) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	b, ok := db.budgets[instanceID]
+	if !ok {
+		return nil
+	}
+	b.NotifiedMonth = month
+	b.NotifiedThreshold = threshold
+	db.budgets[instanceID] = b
+	return nil
 }
 
 func (db *memory) Transaction(f func(DB) error) error {
@@ -279,3 +420,103 @@ func (db *memory) Transaction(f func(DB) error) error {
 func (db *memory) Close(ctx context.Context) (err error) {
 	return nil
 }
+
+func (db *memory) SetUsageUploadImport(ctx context.Context, uploadID int64, name, importID string) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	if upload := db.upload(uploadID); upload != nil {
+		upload.Name = name
+		upload.ImportID = importID
+	}
+	return nil
+}
+
+func (db *memory) GetUsageUploadsToReconcile(ctx context.Context, uploader string) ([]UsageUpload, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	uploads := []UsageUpload{}
+	for _, upload := range db.uploads {
+		if upload != nil && upload.Uploader == uploader && upload.ReconciledAt.IsZero() {
+			uploads = append(uploads, *upload)
+		}
+	}
+	return uploads, nil
+}
+
+func (db *memory) SetUsageUploadReconciled(ctx context.Context, uploadID int64) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	if upload := db.upload(uploadID); upload != nil {
+		upload.ReconciledAt = time.Now()
+	}
+	return nil
+}
+
+func (db *memory) upload(uploadID int64) *UsageUpload {
+	if uploadID < 1 || uploadID > int64(len(db.uploads)) {
+		return nil
+	}
+	return db.uploads[uploadID-1]
+}
+
+func (db *memory) InsertDiscrepancies(ctx context.Context, discrepancies []Discrepancy) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	now := time.Now()
+	for _, d := range discrepancies {
+		d.ID = int64(len(db.discrepancies) + 1)
+		d.DetectedAt = now
+		db.discrepancies = append(db.discrepancies, d)
+	}
+	return nil
+}
+
+func (db *memory) GetDiscrepancies(ctx context.Context) ([]Discrepancy, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	discrepancies := []Discrepancy{}
+	for _, d := range db.discrepancies {
+		if d.ResolvedAt.IsZero() {
+			discrepancies = append(discrepancies, d)
+		}
+	}
+	return discrepancies, nil
+}
+
+func (db *memory) GetDiscrepancy(ctx context.Context, id int64) (*Discrepancy, error) {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+	if id < 1 || id > int64(len(db.discrepancies)) {
+		return nil, nil
+	}
+	d := db.discrepancies[id-1]
+	return &d, nil
+}
+
+func (db *memory) ResolveDiscrepancy(ctx context.Context, id int64, requeue bool) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+	if id < 1 || id > int64(len(db.discrepancies)) {
+		return nil
+	}
+	d := &db.discrepancies[id-1]
+	if !d.ResolvedAt.IsZero() {
+		return nil
+	}
+	d.ResolvedAt = time.Now()
+	d.Requeued = requeue
+	if !requeue {
+		return nil
+	}
+	amountTypes := map[string]bool{}
+	for _, t := range d.AmountTypes {
+		amountTypes[t] = true
+	}
+	for aggID, agg := range db.aggregatesSet {
+		if agg.UploadID == d.UploadID && agg.InstanceID == d.InstanceID && amountTypes[agg.AmountType] {
+			agg.UploadID = 0
+			db.aggregatesSet[aggID] = agg
+		}
+	}
+	return nil
+}
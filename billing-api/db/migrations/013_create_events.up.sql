@@ -0,0 +1,13 @@
+-- Stores usage events, as sent to BigQuery by billing-ingester, for deployments
+-- and tests which aggregate usage without BigQuery.
+CREATE TABLE IF NOT EXISTS events (
+  unique_key           text PRIMARY KEY,
+  internal_instance_id text NOT NULL,
+  amount_type          text NOT NULL,
+  amount_value         bigint NOT NULL,
+  occurred_at          timestamp with time zone NOT NULL,
+  received_at          timestamp with time zone NOT NULL,
+  metadata             text
+);
+
+CREATE INDEX idx_events_received_at ON events USING btree (received_at);
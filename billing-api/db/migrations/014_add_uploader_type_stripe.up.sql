@@ -0,0 +1,9 @@
+-- Add 'stripe' to uploader_type, the same way 'gcp' was added in 006_add_uploader_type_gcp.
+ALTER TYPE uploader_type RENAME TO uploader_type_old;
+CREATE TYPE uploader_type AS enum ('zuora', 'gcp', 'stripe');
+
+-- Alter all previous values:
+ALTER TABLE usage_uploads ALTER COLUMN uploader TYPE uploader_type USING uploader::text::uploader_type;
+
+-- Drop the old enum:
+DROP TYPE uploader_type_old;
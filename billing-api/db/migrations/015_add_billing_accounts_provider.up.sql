@@ -0,0 +1,12 @@
+-- The provider a billing account is billed through: '' for Zuora or GCP, which
+-- are decided per instance, 'external' or 'stripe'.
+-- billed_externally is kept in sync with it, for older readers.
+ALTER TABLE billing_accounts ADD COLUMN provider TEXT NOT NULL DEFAULT '';
+UPDATE billing_accounts SET provider = 'external' WHERE billed_externally;
+
+-- Stripe customers of teams billed through Stripe.
+CREATE TABLE IF NOT EXISTS stripe_customers (
+  team_id     TEXT PRIMARY KEY, -- REFERENCES users.teams.id
+  customer_id TEXT NOT NULL UNIQUE,
+  created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,13 @@
+-- Monthly budgets of instances, and the highest of their thresholds (in
+-- percents of the amount) their cost crossed, and were notified about, in
+-- notified_month.
+CREATE TABLE IF NOT EXISTS budgets (
+  instance_id        TEXT PRIMARY KEY, -- REFERENCES users.organizations.id
+  amount             NUMERIC NOT NULL,
+  currency           TEXT NOT NULL,
+  thresholds         INTEGER[] NOT NULL,
+  notified_month     DATE,
+  notified_threshold INTEGER NOT NULL DEFAULT 0,
+  created_at         TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at         TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,30 @@
+-- What an upload was sent to the billing provider as, and when it was last
+-- reconciled with what the provider accepted.
+ALTER TABLE usage_uploads ADD COLUMN name TEXT;
+ALTER TABLE usage_uploads ADD COLUMN import_id TEXT;
+ALTER TABLE usage_uploads ADD COLUMN reconciled_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN usage_uploads.import_id IS 'the id of the upload at the billing provider, e.g. of the Zuora usage import';
+
+-- Uploads from before reconciliation existed can't be reconciled, as we
+-- don't know their name or import id.
+UPDATE usage_uploads SET reconciled_at = now();
+
+CREATE TABLE IF NOT EXISTS reconciliation_discrepancies (
+  id           serial primary key,
+  upload_id    integer NOT NULL,
+  uploader     uploader_type NOT NULL,
+  instance_id  text NOT NULL,
+  product      text NOT NULL,
+  amount_types text[] NOT NULL,
+  expected     bigint NOT NULL,
+  accepted     bigint NOT NULL,
+  reason       text NOT NULL,
+  detected_at  timestamp with time zone NOT NULL DEFAULT now(),
+  resolved_at  timestamp with time zone,
+  requeued     boolean NOT NULL DEFAULT false
+);
+
+CREATE INDEX idx_reconciliation_discrepancies_unresolved
+ON reconciliation_discrepancies USING btree (id)
+WHERE resolved_at IS NULL;
@@ -36,6 +36,10 @@ const (
 	tableAggregates        = "aggregates"
 	tableUsageUploads      = "usage_uploads"
 	tablePostTrialInvoices = "post_trial_invoices"
+	tableEvents            = "events"
+	tableStripeCustomers   = "stripe_customers"
+	tableBudgets           = "budgets"
+	tableDiscrepancies     = "reconciliation_discrepancies"
 )
 
 var aggregateColumns = []string{
@@ -276,6 +280,64 @@ func (d *postgres) DeleteUsageUpload(ctx context.Context, uploader string, uploa
 	return err
 }
 
+func (d *postgres) InsertEvents(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	insert := d.Insert(tableEvents).
+		Columns("unique_key", "internal_instance_id", "amount_type", "amount_value", "occurred_at", "received_at", "metadata")
+	for _, e := range events {
+		var metadata sql.NullString
+		if e.Metadata != "" {
+			metadata = sql.NullString{String: e.Metadata, Valid: true}
+		}
+		insert = insert.Values(e.UniqueKey, e.InternalInstanceID, e.AmountType, e.AmountValue, e.OccurredAt, e.ReceivedAt, metadata)
+	}
+	insert = insert.Suffix("ON CONFLICT (unique_key) DO NOTHING")
+
+	log.Debug(insert.ToSql())
+	_, err := insert.Exec()
+	return err
+}
+
+func (d *postgres) GetEventAggregates(ctx context.Context, since time.Time) ([]Aggregate, error) {
+	rows, err := d.Select(
+		"events.internal_instance_id",
+		"date_trunc('hour', events.received_at at time zone 'UTC') as bucket_start",
+		"events.amount_type",
+		"sum(events.amount_value)",
+	).
+		From(tableEvents).
+		Where(squirrel.GtOrEq{"events.received_at": since}).
+		GroupBy("events.internal_instance_id", "bucket_start", "events.amount_type").
+		OrderBy("bucket_start asc", "events.internal_instance_id asc", "events.amount_type asc").
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aggregates []Aggregate
+	for rows.Next() {
+		var aggregate Aggregate
+		if err := rows.Scan(
+			&aggregate.InstanceID,
+			&aggregate.BucketStart,
+			&aggregate.AmountType,
+			&aggregate.AmountValue,
+		); err != nil {
+			return nil, err
+		}
+		// date_trunc of a timestamp without time zone is read back as UTC.
+		aggregate.BucketStart = aggregate.BucketStart.UTC()
+		aggregates = append(aggregates, aggregate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return aggregates, nil
+}
+
 func (d *postgres) GetMonthSums(ctx context.Context, instanceIDs []string, from, through time.Time) (map[string][]Aggregate, error) {
 	rows, err := d.Select(
 		"aggregates.instance_id",
@@ -406,17 +468,40 @@ func (d postgres) SetTeamBillingAccountProvider(ctx context.Context, teamID, pro
 	return d.createTeamBillingAccount(ctx, teamID, providerName)
 }
 
+func (d postgres) GetBillingAccountProviders(ctx context.Context) (map[string]string, error) {
+	rows, err := d.Select("billing_accounts_teams.team_id", "billing_accounts.provider").
+		From("billing_accounts").
+		Join("billing_accounts_teams ON billing_accounts_teams.billing_account_id = billing_accounts.id").
+		Where("billing_accounts.deleted_at IS NULL").
+		Where("billing_accounts_teams.deleted_at IS NULL").
+		Where(squirrel.NotEq{"billing_accounts.provider": ""}).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	providers := map[string]string{}
+	for rows.Next() {
+		var teamID, providerName string
+		if err := rows.Scan(&teamID, &providerName); err != nil {
+			return nil, err
+		}
+		providers[teamID] = providerName
+	}
+	return providers, rows.Err()
+}
+
 func (d postgres) updateBillingAccount(ctx context.Context, accountID uint32, providerName string) (*grpc.BillingAccount, error) {
-	billedExternally := providerName == provider.External
-	row := d.QueryRow(`update billing_accounts set billed_externally = $1 where id = $2
-returning id, created_at, deleted_at, billed_externally`, billedExternally, accountID)
+	providerName, billedExternally := billingAccountProvider(providerName)
+	row := d.QueryRow(`update billing_accounts set provider = $1, billed_externally = $2 where id = $3
+returning id, created_at, deleted_at, provider`, providerName, billedExternally, accountID)
 	return d.scanBillingAccount(row)
 }
 
 func (d postgres) createTeamBillingAccount(ctx context.Context, teamID, providerName string) (*grpc.BillingAccount, error) {
-	billedExternally := providerName == provider.External
-	row := d.QueryRow(`insert into billing_accounts(billed_externally) values($1)
-returning id, created_at, deleted_at, billed_externally`, billedExternally)
+	providerName, billedExternally := billingAccountProvider(providerName)
+	row := d.QueryRow(`insert into billing_accounts(provider, billed_externally) values($1, $2)
+returning id, created_at, deleted_at, provider`, providerName, billedExternally)
 	ba, err := d.scanBillingAccount(row)
 	if err != nil {
 		return nil, err
@@ -428,12 +513,23 @@ returning id, created_at, deleted_at, billed_externally`, billedExternally)
 	return ba, nil
 }
 
+// billingAccountProvider returns the provider to store for a billing
+// account, and whether it is billed externally. Unknown providers are
+// stored as the default one.
+func billingAccountProvider(providerName string) (string, bool) {
+	switch providerName {
+	case provider.External, provider.Stripe:
+		return providerName, providerName == provider.External
+	}
+	return "", false
+}
+
 func (d postgres) billingAccounts() squirrel.SelectBuilder {
 	return d.Select(
 		"billing_accounts.id",
 		"billing_accounts.created_at",
 		"billing_accounts.deleted_at",
-		"billing_accounts.billed_externally",
+		"billing_accounts.provider",
 	).
 		From("billing_accounts").
 		Where("billing_accounts.deleted_at IS NULL").
@@ -458,22 +554,268 @@ func (d postgres) scanBillingAccounts(rows *sql.Rows) ([]*grpc.BillingAccount, e
 func (d postgres) scanBillingAccount(row squirrel.RowScanner) (*grpc.BillingAccount, error) {
 	a := &grpc.BillingAccount{}
 	var deletedAt pq.NullTime
-	var billedExternally bool
 	if err := row.Scan(
 		&a.ID,
 		&a.CreatedAt,
 		&deletedAt,
-		&billedExternally,
+		&a.Provider,
 	); err != nil {
 		return nil, err
 	}
 	a.DeletedAt = deletedAt.Time
-	if billedExternally {
-		a.Provider = provider.External
-	}
 	return a, nil
 }
 
+func (d postgres) SetStripeCustomer(ctx context.Context, teamID, customerID string) error {
+	insert := d.Insert(tableStripeCustomers).
+		Columns("team_id", "customer_id").
+		Values(teamID, customerID).
+		Suffix("ON CONFLICT (team_id) DO UPDATE SET customer_id = EXCLUDED.customer_id")
+
+	log.Debug(insert.ToSql())
+	_, err := insert.Exec()
+	return err
+}
+
+func (d postgres) FindStripeCustomerByTeamID(ctx context.Context, teamID string) (*StripeCustomer, error) {
+	customers, err := d.stripeCustomers(squirrel.Eq{"team_id": teamID})
+	if err != nil || len(customers) == 0 {
+		return nil, err
+	}
+	return &customers[0], nil
+}
+
+func (d postgres) GetStripeCustomers(ctx context.Context) ([]StripeCustomer, error) {
+	return d.stripeCustomers(nil)
+}
+
+func (d postgres) stripeCustomers(where squirrel.Sqlizer) ([]StripeCustomer, error) {
+	query := d.Select("team_id", "customer_id", "created_at").
+		From(tableStripeCustomers).
+		OrderBy("team_id asc")
+	if where != nil {
+		query = query.Where(where)
+	}
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	customers := []StripeCustomer{}
+	for rows.Next() {
+		var c StripeCustomer
+		if err := rows.Scan(&c.TeamID, &c.CustomerID, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	return customers, rows.Err()
+}
+
+func (d postgres) SetBudget(ctx context.Context, instanceID string, amount float64, currency string, thresholds []int) (*Budget, error) {
+	ts := pq.Int64Array{}
+	for _, t := range thresholds {
+		ts = append(ts, int64(t))
+	}
+	insert := d.Insert(tableBudgets).
+		Columns("instance_id", "amount", "currency", "thresholds").
+		Values(instanceID, amount, currency, ts).
+		Suffix(`ON CONFLICT (instance_id) DO UPDATE SET
+			amount = EXCLUDED.amount,
+			currency = EXCLUDED.currency,
+			thresholds = EXCLUDED.thresholds,
+			notified_month = NULL,
+			notified_threshold = 0,
+			updated_at = NOW()`)
+
+	log.Debug(insert.ToSql())
+	if _, err := insert.Exec(); err != nil {
+		return nil, err
+	}
+	return d.GetBudget(ctx, instanceID)
+}
+
+func (d postgres) GetBudget(ctx context.Context, instanceID string) (*Budget, error) {
+	budgets, err := d.budgets(squirrel.Eq{"instance_id": instanceID})
+	if err != nil || len(budgets) == 0 {
+		return nil, err
+	}
+	return &budgets[0], nil
+}
+
+func (d postgres) GetBudgets(ctx context.Context) ([]Budget, error) {
+	return d.budgets(nil)
+}
+
+func (d postgres) DeleteBudget(ctx context.Context, instanceID string) error {
+	_, err := d.Delete(tableBudgets).
+		Where(squirrel.Eq{"instance_id": instanceID}).
+		Exec()
+	return err
+}
+
+func (d postgres) SetBudgetNotified(ctx context.Context, instanceID string, month time.Time, threshold int) error {
+	_, err := d.Update(tableBudgets).
+		Set("notified_month", month).
+		Set("notified_threshold", threshold).
+		Where(squirrel.Eq{"instance_id": instanceID}).
+		Exec()
+	return err
+}
+
+func (d postgres) budgets(where squirrel.Sqlizer) ([]Budget, error) {
+	query := d.Select("instance_id", "amount", "currency", "thresholds", "notified_month", "notified_threshold", "created_at", "updated_at").
+		From(tableBudgets).
+		OrderBy("instance_id asc")
+	if where != nil {
+		query = query.Where(where)
+	}
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	budgets := []Budget{}
+	for rows.Next() {
+		var (
+			b             Budget
+			thresholds    pq.Int64Array
+			notifiedMonth pq.NullTime
+		)
+		if err := rows.Scan(&b.InstanceID, &b.Amount, &b.Currency, &thresholds, &notifiedMonth, &b.NotifiedThreshold, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		for _, t := range thresholds {
+			b.Thresholds = append(b.Thresholds, int(t))
+		}
+		b.NotifiedMonth = notifiedMonth.Time
+		budgets = append(budgets, b)
+	}
+	return budgets, rows.Err()
+}
+
+func (d postgres) SetUsageUploadImport(ctx context.Context, uploadID int64, name, importID string) error {
+	_, err := d.Update(tableUsageUploads).
+		Set("name", name).
+		Set("import_id", importID).
+		Where(squirrel.Eq{"id": uploadID}).
+		Exec()
+	return err
+}
+
+func (d postgres) GetUsageUploadsToReconcile(ctx context.Context, uploader string) ([]UsageUpload, error) {
+	rows, err := d.Select("id", "uploader", "name", "import_id", "started_at", "reconciled_at").
+		From(tableUsageUploads).
+		Where(squirrel.Eq{"uploader": uploader, "reconciled_at": nil}).
+		OrderBy("id asc").
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	uploads := []UsageUpload{}
+	for rows.Next() {
+		var (
+			u                       UsageUpload
+			name, importID          sql.NullString
+			startedAt, reconciledAt pq.NullTime
+		)
+		if err := rows.Scan(&u.ID, &u.Uploader, &name, &importID, &startedAt, &reconciledAt); err != nil {
+			return nil, err
+		}
+		u.Name = name.String
+		u.ImportID = importID.String
+		u.StartedAt = startedAt.Time
+		u.ReconciledAt = reconciledAt.Time
+		uploads = append(uploads, u)
+	}
+	return uploads, rows.Err()
+}
+
+func (d postgres) SetUsageUploadReconciled(ctx context.Context, uploadID int64) error {
+	_, err := d.Update(tableUsageUploads).
+		Set("reconciled_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": uploadID}).
+		Exec()
+	return err
+}
+
+func (d postgres) InsertDiscrepancies(ctx context.Context, discrepancies []Discrepancy) error {
+	if len(discrepancies) == 0 {
+		return nil
+	}
+	insert := d.Insert(tableDiscrepancies).
+		Columns("upload_id", "uploader", "instance_id", "product", "amount_types", "expected", "accepted", "reason")
+	for _, r := range discrepancies {
+		insert = insert.Values(r.UploadID, r.Uploader, r.InstanceID, r.Product, pq.StringArray(r.AmountTypes), r.Expected, r.Accepted, r.Reason)
+	}
+
+	log.Debug(insert.ToSql())
+	_, err := insert.Exec()
+	return err
+}
+
+func (d postgres) GetDiscrepancies(ctx context.Context) ([]Discrepancy, error) {
+	return d.discrepancies(squirrel.Eq{"resolved_at": nil})
+}
+
+func (d postgres) GetDiscrepancy(ctx context.Context, id int64) (*Discrepancy, error) {
+	discrepancies, err := d.discrepancies(squirrel.Eq{"id": id})
+	if err != nil || len(discrepancies) == 0 {
+		return nil, err
+	}
+	return &discrepancies[0], nil
+}
+
+func (d postgres) ResolveDiscrepancy(ctx context.Context, id int64, requeue bool) error {
+	r, err := d.GetDiscrepancy(ctx, id)
+	if err != nil || r == nil || !r.ResolvedAt.IsZero() {
+		return err
+	}
+	if requeue {
+		_, err := d.Update(tableAggregates).
+			Set("upload_id", nil).
+			Where(squirrel.Eq{"upload_id": r.UploadID, "instance_id": r.InstanceID, "amount_type": r.AmountTypes}).
+			Exec()
+		if err != nil {
+			return err
+		}
+	}
+	_, err = d.Update(tableDiscrepancies).
+		Set("resolved_at", squirrel.Expr("now()")).
+		Set("requeued", requeue).
+		Where(squirrel.Eq{"id": id}).
+		Exec()
+	return err
+}
+
+func (d postgres) discrepancies(where squirrel.Sqlizer) ([]Discrepancy, error) {
+	rows, err := d.Select("id", "upload_id", "uploader", "instance_id", "product", "amount_types", "expected", "accepted", "reason", "detected_at", "resolved_at", "requeued").
+		From(tableDiscrepancies).
+		Where(where).
+		OrderBy("id asc").
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	discrepancies := []Discrepancy{}
+	for rows.Next() {
+		var (
+			r           Discrepancy
+			amountTypes pq.StringArray
+			resolvedAt  pq.NullTime
+		)
+		if err := rows.Scan(&r.ID, &r.UploadID, &r.Uploader, &r.InstanceID, &r.Product, &amountTypes, &r.Expected, &r.Accepted, &r.Reason, &r.DetectedAt, &resolvedAt, &r.Requeued); err != nil {
+			return nil, err
+		}
+		r.AmountTypes = amountTypes
+		r.ResolvedAt = resolvedAt.Time
+		discrepancies = append(discrepancies, r)
+	}
+	return discrepancies, rows.Err()
+}
+
 // Close finishes using the db
 func (d *postgres) Close(_ context.Context) error {
 	if db, ok := d.dbProxy.(interface {
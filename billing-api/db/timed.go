@@ -91,6 +91,20 @@ func (t timed) DeleteUsageUpload(ctx context.Context, uploader string, aggregate
 	return
 }
 
+func (t timed) InsertEvents(ctx context.Context, events []Event) error {
+	return t.timeRequest(ctx, "InsertEvents", func(ctx context.Context) error {
+		return t.d.InsertEvents(ctx, events)
+	})
+}
+
+func (t timed) GetEventAggregates(ctx context.Context, since time.Time) (as []Aggregate, err error) {
+	t.timeRequest(ctx, "GetEventAggregates", func(ctx context.Context) error {
+		as, err = t.d.GetEventAggregates(ctx, since)
+		return err
+	})
+	return
+}
+
 func (t timed) GetMonthSums(ctx context.Context, instanceIDs []string, from, through time.Time) (as map[string][]Aggregate, err error) {
 	t.timeRequest(ctx, "GetMonthSums", func(ctx context.Context) error {
 		as, err = t.d.GetMonthSums(ctx, instanceIDs, from, through)
@@ -135,6 +149,120 @@ func (t timed) SetTeamBillingAccountProvider(ctx context.Context, teamID, provid
 	return
 }
 
+func (t timed) GetBillingAccountProviders(ctx context.Context) (providers map[string]string, err error) {
+	t.timeRequest(ctx, "GetBillingAccountProviders", func(ctx context.Context) error {
+		providers, err = t.d.GetBillingAccountProviders(ctx)
+		return err
+	})
+	return
+}
+
+func (t timed) SetStripeCustomer(ctx context.Context, teamID, customerID string) error {
+	return t.timeRequest(ctx, "SetStripeCustomer", func(ctx context.Context) error {
+		return t.d.SetStripeCustomer(ctx, teamID, customerID)
+	})
+}
+
+func (t timed) FindStripeCustomerByTeamID(ctx context.Context, teamID string) (customer *StripeCustomer, err error) {
+	t.timeRequest(ctx, "FindStripeCustomerByTeamID", func(ctx context.Context) error {
+		customer, err = t.d.FindStripeCustomerByTeamID(ctx, teamID)
+		return err
+	})
+	return
+}
+
+func (t timed) GetStripeCustomers(ctx context.Context) (customers []StripeCustomer, err error) {
+	t.timeRequest(ctx, "GetStripeCustomers", func(ctx context.Context) error {
+		customers, err = t.d.GetStripeCustomers(ctx)
+		return err
+	})
+	return
+}
+
+func (t timed) SetBudget(ctx context.Context, instanceID string, amount float64, currency string, thresholds []int) (b *Budget, err error) {
+	t.timeRequest(ctx, "SetBudget", func(ctx context.Context) error {
+		b, err = t.d.SetBudget(ctx, instanceID, amount, currency, thresholds)
+		return err
+	})
+	return
+}
+
+func (t timed) GetBudget(ctx context.Context, instanceID string) (b *Budget, err error) {
+	t.timeRequest(ctx, "GetBudget", func(ctx context.Context) error {
+		b, err = t.d.GetBudget(ctx, instanceID)
+		return err
+	})
+	return
+}
+
+func (t timed) GetBudgets(ctx context.Context) (bs []Budget, err error) {
+	t.timeRequest(ctx, "GetBudgets", func(ctx context.Context) error {
+		bs, err = t.d.GetBudgets(ctx)
+		return err
+	})
+	return
+}
+
+func (t timed) DeleteBudget(ctx context.Context, instanceID string) error {
+	return t.timeRequest(ctx, "DeleteBudget", func(ctx context.Context) error {
+		return t.d.DeleteBudget(ctx, instanceID)
+	})
+}
+
+func (t timed) SetBudgetNotified(ctx context.Context, instanceID string, month time.Time, threshold int) error {
+	return t.timeRequest(ctx, "SetBudgetNotified", func(ctx context.Context) error {
+		return t.d.SetBudgetNotified(ctx, instanceID, month, threshold)
+	})
+}
+
+func (t timed) SetUsageUploadImport(ctx context.Context, uploadID int64, name, importID string) error {
+	return t.timeRequest(ctx, "SetUsageUploadImport", func(ctx context.Context) error {
+		return t.d.SetUsageUploadImport(ctx, uploadID, name, importID)
+	})
+}
+
+func (t timed) GetUsageUploadsToReconcile(ctx context.Context, uploader string) (us []UsageUpload, err error) {
+	t.timeRequest(ctx, "GetUsageUploadsToReconcile", func(ctx context.Context) error {
+		us, err = t.d.GetUsageUploadsToReconcile(ctx, uploader)
+		return err
+	})
+	return
+}
+
+func (t timed) SetUsageUploadReconciled(ctx context.Context, uploadID int64) error {
+	return t.timeRequest(ctx, "SetUsageUploadReconciled", func(ctx context.Context) error {
+		return t.d.SetUsageUploadReconciled(ctx, uploadID)
+	})
+}
+
+func (t timed) InsertDiscrepancies(ctx context.Context, discrepancies []Discrepancy) error {
+	return t.timeRequest(ctx, "InsertDiscrepancies", func(ctx context.Context) error {
+		return t.d.InsertDiscrepancies(ctx, discrepancies)
+	})
+}
+
+func (t timed) GetDiscrepancies(ctx context.Context) (ds []Discrepancy, err error) {
+	t.timeRequest(ctx, "GetDiscrepancies", func(ctx context.Context) error {
+		ds, err = t.d.GetDiscrepancies(ctx)
+		return err
+	})
+	return
+}
+
+func (t timed) GetDiscrepancy(ctx context.Context, id int64) (d *Discrepancy, err error) {
+	t.timeRequest(ctx, "GetDiscrepancy", func(ctx context.Context) error {
+		d, err = t.d.GetDiscrepancy(ctx, id)
+		return err
+	})
+	return
+}
+
+func (t timed) ResolveDiscrepancy(ctx context.Context, id int64, requeue bool) error {
+	return t.timeRequest(ctx, "ResolveDiscrepancy", func(ctx context.Context) error {
+		return t.d.ResolveDiscrepancy(ctx, id, requeue)
+	})
+}
+
 func (t timed) Transaction(f func(DB) error) error {
 	// We don't time transactions as they are only used in tests
 	return t.d.Transaction(f)
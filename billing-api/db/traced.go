@@ -60,6 +60,16 @@ func (t traced) DeleteUsageUpload(ctx context.Context, uploader string, uploadID
 	return t.d.DeleteUsageUpload(ctx, uploader, uploadID)
 }
 
+func (t traced) InsertEvents(ctx context.Context, events []Event) (err error) {
+	defer func() { t.trace("InsertEvents", len(events), err) }()
+	return t.d.InsertEvents(ctx, events)
+}
+
+func (t traced) GetEventAggregates(ctx context.Context, since time.Time) (as []Aggregate, err error) {
+	defer func() { t.trace("GetEventAggregates", since, as, err) }()
+	return t.d.GetEventAggregates(ctx, since)
+}
+
 func (t traced) GetMonthSums(ctx context.Context, instanceIDs []string, from, through time.Time) (as map[string][]Aggregate, err error) {
 	defer func() { t.trace("GetMonthSums", instanceIDs, from, through, as, err) }()
 	return t.d.GetMonthSums(ctx, instanceIDs, from, through)
@@ -90,6 +100,86 @@ func (t traced) SetTeamBillingAccountProvider(ctx context.Context, teamID, provi
 	return t.d.SetTeamBillingAccountProvider(ctx, teamID, providerName)
 }
 
+func (t traced) GetBillingAccountProviders(ctx context.Context) (providers map[string]string, err error) {
+	defer func() { t.trace("GetBillingAccountProviders", len(providers), err) }()
+	return t.d.GetBillingAccountProviders(ctx)
+}
+
+func (t traced) SetStripeCustomer(ctx context.Context, teamID, customerID string) (err error) {
+	defer func() { t.trace("SetStripeCustomer", teamID, customerID, err) }()
+	return t.d.SetStripeCustomer(ctx, teamID, customerID)
+}
+
+func (t traced) FindStripeCustomerByTeamID(ctx context.Context, teamID string) (customer *StripeCustomer, err error) {
+	defer func() { t.trace("FindStripeCustomerByTeamID", teamID, customer, err) }()
+	return t.d.FindStripeCustomerByTeamID(ctx, teamID)
+}
+
+func (t traced) GetStripeCustomers(ctx context.Context) (customers []StripeCustomer, err error) {
+	defer func() { t.trace("GetStripeCustomers", len(customers), err) }()
+	return t.d.GetStripeCustomers(ctx)
+}
+
+func (t traced) SetBudget(ctx context.Context, instanceID string, amount float64, currency string, thresholds []int) (b *Budget, err error) {
+	defer func() { t.trace("SetBudget", instanceID, amount, currency, thresholds, b, err) }()
+	return t.d.SetBudget(ctx, instanceID, amount, currency, thresholds)
+}
+
+func (t traced) GetBudget(ctx context.Context, instanceID string) (b *Budget, err error) {
+	defer func() { t.trace("GetBudget", instanceID, b, err) }()
+	return t.d.GetBudget(ctx, instanceID)
+}
+
+func (t traced) GetBudgets(ctx context.Context) (bs []Budget, err error) {
+	defer func() { t.trace("GetBudgets", len(bs), err) }()
+	return t.d.GetBudgets(ctx)
+}
+
+func (t traced) DeleteBudget(ctx context.Context, instanceID string) (err error) {
+	defer func() { t.trace("DeleteBudget", instanceID, err) }()
+	return t.d.DeleteBudget(ctx, instanceID)
+}
+
+func (t traced) SetBudgetNotified(ctx context.Context, instanceID string, month time.Time, threshold int) (err error) {
+	defer func() { t.trace("SetBudgetNotified", instanceID, month, threshold, err) }()
+	return t.d.SetBudgetNotified(ctx, instanceID, month, threshold)
+}
+
+func (t traced) SetUsageUploadImport(ctx context.Context, uploadID int64, name, importID string) (err error) {
+	defer func() { t.trace("SetUsageUploadImport", uploadID, name, importID, err) }()
+	return t.d.SetUsageUploadImport(ctx, uploadID, name, importID)
+}
+
+func (t traced) GetUsageUploadsToReconcile(ctx context.Context, uploader string) (us []UsageUpload, err error) {
+	defer func() { t.trace("GetUsageUploadsToReconcile", uploader, len(us), err) }()
+	return t.d.GetUsageUploadsToReconcile(ctx, uploader)
+}
+
+func (t traced) SetUsageUploadReconciled(ctx context.Context, uploadID int64) (err error) {
+	defer func() { t.trace("SetUsageUploadReconciled", uploadID, err) }()
+	return t.d.SetUsageUploadReconciled(ctx, uploadID)
+}
+
+func (t traced) InsertDiscrepancies(ctx context.Context, discrepancies []Discrepancy) (err error) {
+	defer func() { t.trace("InsertDiscrepancies", len(discrepancies), err) }()
+	return t.d.InsertDiscrepancies(ctx, discrepancies)
+}
+
+func (t traced) GetDiscrepancies(ctx context.Context) (ds []Discrepancy, err error) {
+	defer func() { t.trace("GetDiscrepancies", len(ds), err) }()
+	return t.d.GetDiscrepancies(ctx)
+}
+
+func (t traced) GetDiscrepancy(ctx context.Context, id int64) (d *Discrepancy, err error) {
+	defer func() { t.trace("GetDiscrepancy", id, d, err) }()
+	return t.d.GetDiscrepancy(ctx, id)
+}
+
+func (t traced) ResolveDiscrepancy(ctx context.Context, id int64, requeue bool) (err error) {
+	defer func() { t.trace("ResolveDiscrepancy", id, requeue, err) }()
+	return t.d.ResolveDiscrepancy(ctx, id, requeue)
+}
+
 func (t traced) Transaction(f func(DB) error) error {
 	// We don't time transactions as they are only used in tests
 	return t.d.Transaction(f)
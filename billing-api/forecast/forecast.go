@@ -0,0 +1,197 @@
+package forecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/common/billing/catalog"
+	"github.com/weaveworks/service/common/billing/provider"
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/users"
+)
+
+// ratesTTL is how long rates fetched from Zuora are cached for.
+const ratesTTL = 1 * time.Hour
+
+// Rates gets the current price of a unit of each Zuora unit of measure, per
+// currency.
+type Rates interface {
+	GetCurrentRates(ctx context.Context) (zuora.RateMap, error)
+}
+
+// LoadRateTable reads a rate table from a JSON file mapping units of measure
+// to currencies to prices, e.g. {"node-seconds": {"USD": 0.00001}}.
+func LoadRateTable(filename string) (zuora.RateMap, error) {
+	bs, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	table := zuora.RateMap{}
+	if err := json.Unmarshal(bs, &table); err != nil {
+		return nil, fmt.Errorf("cannot parse rate table %v: %v", filename, err)
+	}
+	return table, nil
+}
+
+// Forecast is the cost of an instance for a calendar month, so far and as
+// projected for the whole month.
+type Forecast struct {
+	Currency    string
+	Start       time.Time
+	End         time.Time
+	MonthToDate float64
+	Projected   float64
+	Products    []ProductCost
+	// Unpriced are the products used this month which have no rate in the
+	// currency, and so aren't part of the cost.
+	Unpriced []string
+}
+
+// ProductCost is the cost of a product for a calendar month.
+type ProductCost struct {
+	Product     string
+	Unit        string
+	Usage       int64
+	MonthToDate float64
+	Projected   float64
+}
+
+// Forecaster estimates what instances cost from their usage. Instances billed
+// through Zuora are priced at Zuora's current rates, others at the rate table
+// if there is one.
+type Forecaster struct {
+	db    db.DB
+	zuora Rates
+	table zuora.RateMap
+
+	mtx     sync.Mutex
+	rates   zuora.RateMap
+	fetched time.Time
+}
+
+// NewForecaster creates a Forecaster. The rate table may be nil, in which case
+// all instances are priced at Zuora's rates.
+func NewForecaster(db db.DB, zuora Rates, table zuora.RateMap) *Forecaster {
+	return &Forecaster{db: db, zuora: zuora, table: table}
+}
+
+// Forecast returns the cost of the organization in the currency for the
+// calendar month of now. Usage during the organization's trial is free, so
+// isn't counted.
+func (f *Forecaster) Forecast(ctx context.Context, org *users.Organization, currency string, now time.Time) (*Forecast, error) {
+	providerName, err := f.providerName(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := f.getRates(ctx, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	from := start
+	if org.TrialExpiresAt.After(from) {
+		from = org.TrialExpiresAt
+	}
+	fc := &Forecast{Currency: currency, Start: start, End: end}
+	if !from.Before(now) {
+		return fc, nil
+	}
+	sums, err := f.db.GetMonthSums(ctx, []string{org.ID}, from, now)
+	if err != nil {
+		return nil, err
+	}
+	compute(fc, sums[org.ID], providerName, rates, from, now)
+	return fc, nil
+}
+
+// providerName returns the provider the organization is billed through.
+func (f *Forecaster) providerName(ctx context.Context, org *users.Organization) (string, error) {
+	if org.GCP != nil {
+		return provider.GCP, nil
+	}
+	if org.TeamID != "" {
+		account, err := f.db.FindBillingAccountByTeamID(ctx, org.TeamID)
+		if err != nil {
+			return "", err
+		}
+		if account != nil && account.Provider != "" {
+			return account.Provider, nil
+		}
+	}
+	return provider.Zuora, nil
+}
+
+func (f *Forecaster) getRates(ctx context.Context, providerName string) (zuora.RateMap, error) {
+	if providerName != provider.Zuora && f.table != nil {
+		return f.table, nil
+	}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.rates == nil || time.Since(f.fetched) > ratesTTL {
+		rates, err := f.zuora.GetCurrentRates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		f.rates = rates
+		f.fetched = time.Now()
+	}
+	return f.rates, nil
+}
+
+// compute prices the usage since from of the products billed through the
+// provider, and projects it linearly to the end of the forecast's month. Rates
+// are per Zuora unit of measure.
+func compute(fc *Forecast, aggs []db.Aggregate, providerName string, rates zuora.RateMap, from, now time.Time) {
+	elapsed := now.Sub(from)
+	scale := 1.0
+	if elapsed > 0 {
+		scale = float64(fc.End.Sub(from)) / float64(elapsed)
+	}
+
+	byProduct := map[string]*ProductCost{}
+	unpriced := map[string]bool{}
+	for _, agg := range aggs {
+		product, ok := catalog.Default.Product(agg.AmountType)
+		if !ok {
+			continue
+		}
+		if _, ok := catalog.Default.Charge(providerName, agg.AmountType); !ok {
+			continue
+		}
+		rate, ok := rates[product.Charges[provider.Zuora]][fc.Currency]
+		if !ok {
+			unpriced[product.Name] = true
+			continue
+		}
+		cost, ok := byProduct[product.Name]
+		if !ok {
+			cost = &ProductCost{Product: product.Name, Unit: product.Unit}
+			byProduct[product.Name] = cost
+		}
+		cost.Usage += agg.AmountValue
+		cost.MonthToDate += float64(agg.AmountValue) * rate
+	}
+
+	// Keep the catalog's order.
+	for _, product := range catalog.Default {
+		if unpriced[product.Name] {
+			fc.Unpriced = append(fc.Unpriced, product.Name)
+		}
+		cost, ok := byProduct[product.Name]
+		if !ok {
+			continue
+		}
+		cost.Projected = cost.MonthToDate * scale
+		fc.MonthToDate += cost.MonthToDate
+		fc.Projected += cost.Projected
+		fc.Products = append(fc.Products, *cost)
+	}
+}
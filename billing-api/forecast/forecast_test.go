@@ -0,0 +1,151 @@
+package forecast_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/billing-api/db/dbtest"
+	"github.com/weaveworks/service/billing-api/forecast"
+	"github.com/weaveworks/service/common/billing/provider"
+	"github.com/weaveworks/service/common/constants/billing"
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/users"
+)
+
+type stubRates struct {
+	rates zuora.RateMap
+	calls int
+}
+
+func (r *stubRates) GetCurrentRates(ctx context.Context) (zuora.RateMap, error) {
+	r.calls++
+	return r.rates, nil
+}
+
+func TestForecaster_Forecast(t *testing.T) {
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+	ctx := context.Background()
+
+	start := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+	err := d.InsertAggregates(ctx, []db.Aggregate{
+		{InstanceID: "100", BucketStart: start, AmountType: billing.UsageNodeSeconds, AmountValue: 1000},
+		{InstanceID: "100", BucketStart: start.Add(time.Hour), AmountType: billing.UsageNodeSeconds, AmountValue: 1000},
+		{InstanceID: "100", BucketStart: start, AmountType: billing.UsageFluxRelease, AmountValue: 2},
+		{InstanceID: "100", BucketStart: start, AmountType: billing.UsageFluxAutoRelease, AmountValue: 3},
+		{InstanceID: "100", BucketStart: start, AmountType: "unbilled", AmountValue: 100},
+		{InstanceID: "100", BucketStart: start.AddDate(0, -1, 0), AmountType: billing.UsageNodeSeconds, AmountValue: 1000},
+	})
+	require.NoError(t, err)
+
+	rates := &stubRates{rates: zuora.RateMap{
+		billing.UsageNodeSeconds: {"USD": 0.01},
+		"deploys":                {"USD": 1},
+	}}
+	f := forecast.NewForecaster(d, rates, nil)
+	org := &users.Organization{ID: "100"}
+
+	// 6 of the 30 days of June have passed.
+	now := start.AddDate(0, 0, 6)
+	fc, err := f.Forecast(ctx, org, "USD", now)
+	require.NoError(t, err)
+	assert.Equal(t, start, fc.Start)
+	assert.Equal(t, start.AddDate(0, 1, 0), fc.End)
+	assert.InDelta(t, 25, fc.MonthToDate, 1e-9)
+	assert.InDelta(t, 125, fc.Projected, 1e-9)
+	require.Len(t, fc.Products, 2)
+	assert.Equal(t, "nodes", fc.Products[0].Product)
+	assert.Equal(t, int64(2000), fc.Products[0].Usage)
+	assert.Equal(t, "deploys", fc.Products[1].Product)
+	assert.Equal(t, int64(5), fc.Products[1].Usage)
+	assert.InDelta(t, 25, fc.Products[1].Projected, 1e-9)
+
+	// Rates are cached.
+	_, err = f.Forecast(ctx, org, "USD", now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rates.calls)
+
+	// Products without a rate in the currency aren't priced.
+	fc, err = f.Forecast(ctx, org, "EUR", now)
+	require.NoError(t, err)
+	assert.Len(t, fc.Products, 0)
+	assert.Equal(t, []string{"nodes", "deploys"}, fc.Unpriced)
+	assert.Equal(t, 0.0, fc.MonthToDate)
+
+	// Only products billed through GCP count for its subscribers.
+	fc, err = f.Forecast(ctx, &users.Organization{ID: "100", GCP: &users.GoogleCloudPlatform{}}, "USD", now)
+	require.NoError(t, err)
+	require.Len(t, fc.Products, 1)
+	assert.Equal(t, "nodes", fc.Products[0].Product)
+	assert.InDelta(t, 20, fc.MonthToDate, 1e-9)
+}
+
+func TestForecaster_Forecast_trial(t *testing.T) {
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+	ctx := context.Background()
+
+	start := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+	err := d.InsertAggregates(ctx, []db.Aggregate{
+		{InstanceID: "100", BucketStart: start.AddDate(0, 0, 1), AmountType: billing.UsageNodeSeconds, AmountValue: 1000},
+		{InstanceID: "100", BucketStart: start.AddDate(0, 0, 4), AmountType: billing.UsageNodeSeconds, AmountValue: 500},
+	})
+	require.NoError(t, err)
+
+	rates := &stubRates{rates: zuora.RateMap{billing.UsageNodeSeconds: {"USD": 0.01}}}
+	f := forecast.NewForecaster(d, rates, nil)
+	org := &users.Organization{ID: "100", TrialExpiresAt: start.AddDate(0, 0, 3)}
+
+	// Still in trial: nothing is billed yet.
+	fc, err := f.Forecast(ctx, org, "USD", start.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	assert.Equal(t, start, fc.Start)
+	assert.Equal(t, 0.0, fc.MonthToDate)
+	assert.Equal(t, 0.0, fc.Projected)
+
+	// 3 of the 27 days billed this month have passed.
+	fc, err = f.Forecast(ctx, org, "USD", start.AddDate(0, 0, 6))
+	require.NoError(t, err)
+	assert.Equal(t, start, fc.Start)
+	assert.InDelta(t, 5, fc.MonthToDate, 1e-9)
+	assert.InDelta(t, 45, fc.Projected, 1e-9)
+}
+
+func TestForecaster_Forecast_rateTable(t *testing.T) {
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+	ctx := context.Background()
+
+	start := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+	err := d.InsertAggregates(ctx, []db.Aggregate{
+		{InstanceID: "100", BucketStart: start, AmountType: billing.UsageNodeSeconds, AmountValue: 1000},
+		{InstanceID: "200", BucketStart: start, AmountType: billing.UsageNodeSeconds, AmountValue: 1000},
+	})
+	require.NoError(t, err)
+	_, err = d.SetTeamBillingAccountProvider(ctx, "team-1", provider.Stripe)
+	require.NoError(t, err)
+
+	rates := &stubRates{rates: zuora.RateMap{billing.UsageNodeSeconds: {"USD": 0.01}}}
+	table := zuora.RateMap{billing.UsageNodeSeconds: {"USD": 0.02}}
+	f := forecast.NewForecaster(d, rates, table)
+	now := start.Add(24 * time.Hour)
+
+	fc, err := f.Forecast(ctx, &users.Organization{ID: "100", TeamID: "team-1"}, "USD", now)
+	require.NoError(t, err)
+	assert.InDelta(t, 20, fc.MonthToDate, 1e-9)
+	assert.Equal(t, 0, rates.calls)
+
+	fc, err = f.Forecast(ctx, &users.Organization{ID: "200", GCP: &users.GoogleCloudPlatform{}}, "USD", now)
+	require.NoError(t, err)
+	assert.InDelta(t, 20, fc.MonthToDate, 1e-9)
+
+	fc, err = f.Forecast(ctx, &users.Organization{ID: "200"}, "USD", now)
+	require.NoError(t, err)
+	assert.InDelta(t, 10, fc.MonthToDate, 1e-9)
+	assert.Equal(t, 1, rates.calls)
+}
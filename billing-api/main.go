@@ -4,36 +4,58 @@ import (
 	"context"
 	"flag"
 
+	"github.com/robfig/cron"
 	log "github.com/sirupsen/logrus"
+	"github.com/weaveworks/common/instrument"
 	"github.com/weaveworks/common/logging"
 	"github.com/weaveworks/common/server"
 	"github.com/weaveworks/common/tracing"
 
+	"github.com/weaveworks/service/billing-api/budgets"
 	"github.com/weaveworks/service/billing-api/db"
 	"github.com/weaveworks/service/billing-api/grpc"
+	"github.com/weaveworks/service/billing-api/reconciliation"
 	"github.com/weaveworks/service/billing-api/routes"
 	common_grpc "github.com/weaveworks/service/common/billing/grpc"
 	"github.com/weaveworks/service/common/dbconfig"
+	"github.com/weaveworks/service/common/stripe"
 	"github.com/weaveworks/service/common/users"
 	"github.com/weaveworks/service/common/zuora"
 )
 
+var jobCollector = instrument.NewJobCollector("billing")
+
+func init() {
+	jobCollector.Register()
+}
+
 // Config holds the API settings.
 type Config struct {
+	budgetsCronSpec        string
+	eventsURL              string
+	reconciliationCronSpec string
+
 	dbConfig     dbconfig.Config
 	routesConfig routes.Config
 	serverConfig server.Config
 	usersConfig  users.Config
 	zuoraConfig  zuora.Config
+	stripeConfig stripe.Config
 }
 
 // RegisterFlags registers configuration variables.
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
+	// It is scheduled to go hourly at :25 because the aggregation of usage is scheduled at :10
+	f.StringVar(&c.budgetsCronSpec, "budgets-cron-spec", "0 25 * * * *", "Cron spec for periodic execution of the budgets job, notifying instances whose cost crossed a threshold of their budget")
+	f.StringVar(&c.eventsURL, "events-url", "", "URL of the notification service to which budget events are sent. Budgets aren't checked if empty")
+	// It is scheduled to go daily at 04:30, two hours after the Zuora uploader, so that its import has finished
+	f.StringVar(&c.reconciliationCronSpec, "reconciliation-cron-spec", "0 30 4 * * *", "Cron spec for periodic execution of the reconciliation job, comparing uploaded usage with what billing providers accepted")
 	c.dbConfig.RegisterFlags(f, "postgres://postgres@billing-db/billing?sslmode=disable", "Database to use.", "/migrations", "Migrations directory.")
 	c.routesConfig.RegisterFlags(f)
 	c.serverConfig.RegisterFlags(f)
 	c.usersConfig.RegisterFlags(f)
 	c.zuoraConfig.RegisterFlags(f)
+	c.stripeConfig.RegisterFlags(f)
 }
 
 // Validate calls validation on its sub configs.
@@ -64,6 +86,10 @@ func main() {
 	}
 
 	z := zuora.New(cfg.zuoraConfig, nil)
+	var s stripe.Client
+	if cfg.stripeConfig.Enabled() {
+		s = stripe.New(cfg.stripeConfig, nil)
+	}
 
 	db, err := db.New(cfg.dbConfig)
 	if err != nil {
@@ -83,11 +109,26 @@ func main() {
 		Zuora: z,
 	}
 
-	routes, err := routes.New(cfg.routesConfig, db, users, z)
+	routes, err := routes.New(cfg.routesConfig, db, users, z, s)
 	if err != nil {
 		log.Fatalf("error initialising api: %v", err)
 	}
 	routes.RegisterRoutes(server.HTTP)
+
+	if cfg.eventsURL != "" {
+		budgetsCron := cron.New()
+		budgetsCron.AddJob(cfg.budgetsCronSpec, budgets.NewJob(db, users, routes.Forecaster, budgets.NewEventNotifier(cfg.eventsURL), jobCollector))
+		budgetsCron.Start()
+		defer budgetsCron.Stop()
+	} else {
+		log.Infof("Budgets job is disabled")
+	}
+
+	reconciliationCron := cron.New()
+	reconciliationCron.AddJob(cfg.reconciliationCronSpec, reconciliation.NewJob(db, users, z, jobCollector))
+	reconciliationCron.Start()
+	defer reconciliationCron.Stop()
+
 	log.WithField("port", cfg.serverConfig.HTTPListenPort).Infof("billing-api now serving HTTP requests")
 	common_grpc.RegisterBillingServer(server.GRPC, grpcServer)
 	log.WithField("port", cfg.serverConfig.GRPCListenPort).Infof("billing-api now serving gRPC requests")
@@ -0,0 +1,41 @@
+package payments
+
+import (
+	"context"
+
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/users"
+)
+
+// AccountRequest holds the details of a billing account to create.
+type AccountRequest struct {
+	Currency      string
+	BillToContact zuora.Contact
+	// PaymentMethodID is the payment method the account is paid with, as
+	// returned by the provider's hosted payment page.
+	PaymentMethodID string
+}
+
+// Provider manages the billing accounts, payment methods and invoices of
+// organizations. Accounts, payment methods and invoices are returned in
+// Zuora's format, which the billing API's clients already understand.
+type Provider interface {
+	// Name is the billing provider's name, e.g. "zuora" or "stripe".
+	Name() string
+
+	// CreateAccount creates a billing account for the organization, and
+	// subscribes it from the end of its trial.
+	CreateAccount(ctx context.Context, org *users.Organization, req AccountRequest) (*zuora.Account, error)
+	// GetAccount returns the organization's billing account, or
+	// zuora.ErrNotFound if it has none.
+	GetAccount(ctx context.Context, org *users.Organization) (*zuora.Account, error)
+	UpdateAccount(ctx context.Context, org *users.Organization, details *zuora.Account) (*zuora.Account, error)
+
+	// GetInvoices returns a page of the organization's invoices, most recent first.
+	GetInvoices(ctx context.Context, org *users.Organization, page, pageSize string) ([]zuora.Invoice, error)
+
+	GetPaymentMethod(ctx context.Context, org *users.Organization) (*zuora.CreditCard, error)
+	// UpdatePaymentMethod makes the payment method the one the
+	// organization's invoices are paid with.
+	UpdatePaymentMethod(ctx context.Context, org *users.Organization, paymentMethodID string) error
+}
@@ -0,0 +1,366 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/common/constants/billing"
+	"github.com/weaveworks/service/common/stripe"
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/users"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	// maxInvoices is the most invoices Stripe lists in one request.
+	maxInvoices     = 100
+	defaultPageSize = 20
+)
+
+// Stripe bills organizations through Stripe, a customer per team: all the
+// organizations of a team share the same account, payment method and
+// invoices. It implements Provider.
+type Stripe struct {
+	client stripe.Client
+	db     db.DB
+}
+
+// NewStripe creates a Stripe provider.
+func NewStripe(client stripe.Client, db db.DB) *Stripe {
+	return &Stripe{client: client, db: db}
+}
+
+// Name returns "stripe".
+func (s *Stripe) Name() string {
+	return "stripe"
+}
+
+// CreateAccount creates a Stripe customer for the organization's team, unless
+// another of its organizations already did, attaches the payment method to
+// it, and subscribes it to the node-seconds price.
+func (s *Stripe) CreateAccount(ctx context.Context, org *users.Organization, req AccountRequest) (*zuora.Account, error) {
+	if org.TeamID == "" {
+		return nil, fmt.Errorf("organization %s has no team to bill through Stripe", org.ExternalID)
+	}
+	params := customerParams(req.BillToContact)
+	params.Metadata = map[string]string{"team_id": org.TeamID}
+	if req.Currency != "" {
+		params.Metadata["currency"] = strings.ToLower(req.Currency)
+	}
+
+	existing, err := s.db.FindStripeCustomerByTeamID(ctx, org.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	var customerID string
+	if existing != nil {
+		customerID = existing.CustomerID
+		if _, err := s.client.UpdateCustomer(ctx, customerID, params); err != nil {
+			return nil, errors.Wrapf(err, "cannot update Stripe customer %s", customerID)
+		}
+	} else {
+		customer, err := s.client.CreateCustomer(ctx, params)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot create Stripe customer")
+		}
+		customerID = customer.ID
+		if err := s.db.SetStripeCustomer(ctx, org.TeamID, customerID); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.PaymentMethodID != "" {
+		if err := s.client.AttachPaymentMethod(ctx, customerID, req.PaymentMethodID); err != nil {
+			return nil, err
+		}
+	}
+
+	sub, err := s.activeSubscription(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		if _, err := s.client.CreateSubscription(ctx, customerID, org.TrialExpiresAt); err != nil {
+			return nil, errors.Wrapf(err, "cannot subscribe Stripe customer %s", customerID)
+		}
+	}
+	return s.account(ctx, customerID)
+}
+
+// GetAccount returns the Stripe customer of the organization's team, as an account.
+func (s *Stripe) GetAccount(ctx context.Context, org *users.Organization) (*zuora.Account, error) {
+	customerID, err := s.customerID(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	return s.account(ctx, customerID)
+}
+
+// UpdateAccount updates the details of the Stripe customer of the organization's team.
+func (s *Stripe) UpdateAccount(ctx context.Context, org *users.Organization, details *zuora.Account) (*zuora.Account, error) {
+	customerID, err := s.customerID(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.client.UpdateCustomer(ctx, customerID, customerParams(details.BillToContact)); err != nil {
+		return nil, err
+	}
+	return s.account(ctx, customerID)
+}
+
+// GetInvoices returns a page of the Stripe invoices of the organization's team.
+func (s *Stripe) GetInvoices(ctx context.Context, org *users.Organization, page, pageSize string) ([]zuora.Invoice, error) {
+	customerID, err := s.customerID(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	p, err := strconv.Atoi(page)
+	if err != nil || p < 1 {
+		p = 1
+	}
+	size, err := strconv.Atoi(pageSize)
+	if err != nil || size < 1 {
+		size = defaultPageSize
+	}
+	limit := p * size
+	if limit > maxInvoices {
+		limit = maxInvoices
+	}
+	invoices, err := s.client.GetInvoices(ctx, customerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := []zuora.Invoice{}
+	for i := (p - 1) * size; i < len(invoices) && i < p*size; i++ {
+		result = append(result, toZuoraInvoice(invoices[i]))
+	}
+	return result, nil
+}
+
+// GetPaymentMethod returns the card the invoices of the organization's team are paid with.
+func (s *Stripe) GetPaymentMethod(ctx context.Context, org *users.Organization) (*zuora.CreditCard, error) {
+	customerID, err := s.customerID(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	customer, err := s.client.GetCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	methods, err := s.client.GetPaymentMethods(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, pm := range methods {
+		if pm.ID == customer.InvoiceSettings.DefaultPaymentMethod && pm.Card != nil {
+			return toCreditCard(pm), nil
+		}
+	}
+	return nil, zuora.ErrNoDefaultPaymentMethod
+}
+
+// UpdatePaymentMethod attaches the payment method to the Stripe customer of
+// the organization's team, and makes it their default one.
+func (s *Stripe) UpdatePaymentMethod(ctx context.Context, org *users.Organization, paymentMethodID string) error {
+	customerID, err := s.customerID(ctx, org)
+	if err != nil {
+		return err
+	}
+	return s.client.AttachPaymentMethod(ctx, customerID, paymentMethodID)
+}
+
+// customerID returns the ID of the Stripe customer of the organization's
+// team, or zuora.ErrNotFound if it has none, like Zuora does for
+// organizations without an account.
+func (s *Stripe) customerID(ctx context.Context, org *users.Organization) (string, error) {
+	if org.TeamID == "" {
+		return "", zuora.ErrNotFound
+	}
+	customer, err := s.db.FindStripeCustomerByTeamID(ctx, org.TeamID)
+	if err != nil {
+		return "", err
+	}
+	if customer == nil {
+		return "", zuora.ErrNotFound
+	}
+	return customer.CustomerID, nil
+}
+
+// activeSubscription returns the customer's active subscription to the
+// node-seconds price, or nil if it has none.
+func (s *Stripe) activeSubscription(ctx context.Context, customerID string) (*stripe.Subscription, error) {
+	subscriptions, err := s.client.GetSubscriptions(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		if subscriptions[i].IsActive() && subscriptions[i].Item(s.client.GetConfig().PriceID) != nil {
+			return &subscriptions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Stripe) account(ctx context.Context, customerID string) (*zuora.Account, error) {
+	customer, err := s.client.GetCustomer(ctx, customerID)
+	if err == stripe.ErrNotFound {
+		return nil, zuora.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	account := &zuora.Account{
+		ZuoraID:            customer.ID,
+		Number:             customer.ID,
+		PaymentProviderID:  customer.InvoiceSettings.DefaultPaymentMethod,
+		SubscriptionStatus: zuora.SubscriptionInactive,
+		PaymentStatus:      zuora.PaymentStatus{Status: zuora.PaymentOK},
+		BillToContact:      toContact(customer),
+		BillCycleDay:       zuora.BillCycleDay,
+	}
+	sub, err := s.activeSubscription(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return account, nil
+	}
+	account.SubscriptionStatus = zuora.SubscriptionActive
+	if sub.Status == stripe.SubscriptionPastDue {
+		// Stripe doesn't tell why the latest payment failed here, so tell
+		// customers what to do when their card is declined.
+		stripeError := zuora.GetStripeError(zuora.GenericDecline)
+		account.PaymentStatus = zuora.PaymentStatus{
+			Status:      zuora.PaymentError,
+			Description: stripeError.Description,
+			Action:      stripeError.Action,
+		}
+	}
+	if sub.CurrentPeriodStart != 0 {
+		account.BillCycleDay = time.Unix(sub.CurrentPeriodStart, 0).UTC().Day()
+	}
+	item := sub.Item(s.client.GetConfig().PriceID)
+	price, _ := strconv.ParseFloat(item.Price.UnitAmountDecimal, 64)
+	if item.Price.UnitAmountDecimal == "" {
+		price = float64(item.Price.UnitAmount)
+	}
+	account.Subscription = &zuora.AccountSubscription{
+		SubscriptionNumber:    sub.ID,
+		Currency:              strings.ToUpper(item.Price.Currency),
+		ChargeID:              item.ID,
+		ChargeNumber:          item.ID,
+		Price:                 price / 100, // Stripe prices are in cents.
+		SubscriptionStartDate: time.Unix(sub.StartDate, 0).UTC().Format(dateLayout),
+	}
+	return account, nil
+}
+
+func customerParams(contact zuora.Contact) stripe.CustomerParams {
+	return stripe.CustomerParams{
+		Name:  strings.TrimSpace(contact.FirstName + " " + contact.LastName),
+		Email: contact.WorkEmail,
+		Phone: contact.WorkPhone,
+		Address: &stripe.Address{
+			Line1:      contact.Address1,
+			Line2:      contact.Address2,
+			City:       contact.City,
+			PostalCode: contact.ZipCode,
+			State:      contact.State,
+			Country:    contact.Country,
+		},
+	}
+}
+
+func toContact(customer *stripe.Customer) zuora.Contact {
+	names := strings.SplitN(customer.Name, " ", 2)
+	contact := zuora.Contact{
+		FirstName: names[0],
+		Address1:  customer.Address.Line1,
+		Address2:  customer.Address.Line2,
+		City:      customer.Address.City,
+		ZipCode:   customer.Address.PostalCode,
+		State:     customer.Address.State,
+		Country:   customer.Address.Country,
+		WorkEmail: customer.Email,
+		WorkPhone: customer.Phone,
+	}
+	if len(names) > 1 {
+		contact.LastName = names[1]
+	}
+	return contact
+}
+
+func toCreditCard(pm stripe.PaymentMethod) *zuora.CreditCard {
+	card := &zuora.CreditCard{
+		ID:                   pm.ID,
+		DefaultPaymentMethod: true,
+		CardType:             pm.Card.Brand,
+		CardNumber:           "************" + pm.Card.Last4,
+		ExpirationMonth:      pm.Card.ExpMonth,
+		ExpirationYear:       pm.Card.ExpYear,
+	}
+	details := pm.BillingDetails
+	card.CardHolderInfo.CardHolderName = details.Name
+	card.CardHolderInfo.AddressLine1 = details.Address.Line1
+	card.CardHolderInfo.AddressLine2 = details.Address.Line2
+	card.CardHolderInfo.City = details.Address.City
+	card.CardHolderInfo.State = details.Address.State
+	card.CardHolderInfo.Country = details.Address.Country
+	card.CardHolderInfo.ZipCode = details.Address.PostalCode
+	card.CardHolderInfo.Phone = details.Phone
+	card.CardHolderInfo.Email = details.Email
+	return card
+}
+
+// invoiceStatuses maps Stripe's invoice statuses to Zuora's.
+var invoiceStatuses = map[string]string{
+	"draft":         zuora.InvoiceStatusDraft,
+	"open":          zuora.InvoiceStatusPosted,
+	"paid":          zuora.InvoiceStatusPosted,
+	"void":          zuora.InvoiceStatusCanceled,
+	"uncollectible": zuora.InvoiceStatusError,
+}
+
+func toZuoraInvoice(invoice stripe.Invoice) zuora.Invoice {
+	date := func(unix int64) string {
+		if unix == 0 {
+			return ""
+		}
+		return time.Unix(unix, 0).UTC().Format(dateLayout)
+	}
+	status, ok := invoiceStatuses[invoice.Status]
+	if !ok {
+		status = zuora.InvoiceStatusError
+	}
+	result := zuora.Invoice{
+		ID:                invoice.ID,
+		AccountNumber:     invoice.Customer,
+		InvoiceDate:       date(invoice.Created),
+		InvoiceNumber:     invoice.Number,
+		DueDate:           date(invoice.DueDate),
+		InvoiceTargetDate: date(invoice.PeriodEnd),
+		Amount:            float64(invoice.Total) / 100,
+		Balance:           float64(invoice.AmountRemaining) / 100,
+		Status:            status,
+		Body:              invoice.InvoicePDF,
+		InvoiceItems:      []zuora.InvoiceItem{},
+	}
+	for _, line := range invoice.Lines.Data {
+		result.InvoiceItems = append(result.InvoiceItems, zuora.InvoiceItem{
+			ID:                line.ID,
+			ServiceStartDate:  date(line.Period.Start),
+			ServiceEndDate:    date(line.Period.End),
+			ChargeAmount:      float64(line.Amount) / 100,
+			ChargeDescription: line.Description,
+			Quantity:          float64(line.Quantity),
+			UnitOfMeasure:     billing.UsageNodeSeconds,
+			ChargeType:        "Usage",
+		})
+	}
+	return result
+}
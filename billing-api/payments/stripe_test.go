@@ -0,0 +1,90 @@
+package payments_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/billing-api/db/dbtest"
+	"github.com/weaveworks/service/billing-api/payments"
+	"github.com/weaveworks/service/common/stripe"
+	"github.com/weaveworks/service/common/stripe/mockstripe"
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/users"
+)
+
+func TestStripe_AccountPerTeam(t *testing.T) {
+	server := mockstripe.New()
+	defer server.Close()
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+	ctx := context.Background()
+	p := payments.NewStripe(stripe.New(server.Config("price_nodes"), nil), d)
+
+	foo := &users.Organization{ExternalID: "foo", TeamID: "team-1", TrialExpiresAt: time.Now().Add(24 * time.Hour)}
+	bar := &users.Organization{ExternalID: "bar", TeamID: "team-1"}
+
+	_, err := p.GetAccount(ctx, foo)
+	assert.Equal(t, zuora.ErrNotFound, err)
+
+	pmID := server.AddPaymentMethod(stripe.Card{Brand: "visa", Last4: "4242", ExpMonth: 12, ExpYear: 2030})
+	account, err := p.CreateAccount(ctx, foo, payments.AccountRequest{
+		Currency:        "USD",
+		BillToContact:   zuora.Contact{FirstName: "Foo", LastName: "Bar", WorkEmail: "foo@weave.works", Country: "GB"},
+		PaymentMethodID: pmID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, zuora.SubscriptionActive, account.SubscriptionStatus)
+	assert.Equal(t, "USD", account.Subscription.Currency)
+	assert.Equal(t, pmID, account.PaymentProviderID)
+	assert.Equal(t, "Bar", account.BillToContact.LastName)
+
+	// Organizations of the same team share the customer and its subscription.
+	again, err := p.CreateAccount(ctx, bar, payments.AccountRequest{BillToContact: account.BillToContact})
+	require.NoError(t, err)
+	assert.Equal(t, account.Number, again.Number)
+	assert.Equal(t, account.Subscription.SubscriptionNumber, again.Subscription.SubscriptionNumber)
+
+	card, err := p.GetPaymentMethod(ctx, bar)
+	require.NoError(t, err)
+	assert.Equal(t, "************4242", card.CardNumber)
+
+	declined := server.AddPaymentMethod(stripe.Card{Brand: "visa", Last4: "0002"})
+	err = p.UpdatePaymentMethod(ctx, bar, declined)
+	require.IsType(t, &stripe.Error{}, err)
+	assert.True(t, err.(*stripe.Error).IsCardError())
+
+	_, err = p.CreateAccount(ctx, &users.Organization{ExternalID: "baz"}, payments.AccountRequest{})
+	assert.Error(t, err)
+}
+
+func TestStripe_GetInvoices(t *testing.T) {
+	server := mockstripe.New()
+	defer server.Close()
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+	ctx := context.Background()
+	p := payments.NewStripe(stripe.New(server.Config("price_nodes"), nil), d)
+
+	org := &users.Organization{ExternalID: "foo", TeamID: "team-1"}
+	account, err := p.CreateAccount(ctx, org, payments.AccountRequest{})
+	require.NoError(t, err)
+	// Stripe lists the most recent invoices first.
+	for _, status := range []string{"void", "open", "paid"} {
+		server.AddInvoice(stripe.Invoice{Customer: account.Number, Status: status, Total: 1234, Created: time.Now().Unix()})
+	}
+
+	invoices, err := p.GetInvoices(ctx, org, "1", "2")
+	require.NoError(t, err)
+	require.Len(t, invoices, 2)
+	assert.Equal(t, zuora.InvoiceStatusPosted, invoices[0].Status)
+	assert.Equal(t, 12.34, invoices[0].Amount)
+
+	invoices, err = p.GetInvoices(ctx, org, "2", "2")
+	require.NoError(t, err)
+	require.Len(t, invoices, 1)
+	assert.Equal(t, zuora.InvoiceStatusCanceled, invoices[0].Status)
+}
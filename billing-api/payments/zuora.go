@@ -0,0 +1,62 @@
+package payments
+
+import (
+	"context"
+
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/users"
+)
+
+// Zuora bills organizations through Zuora, an account per organization. It
+// implements Provider.
+type Zuora struct {
+	client zuora.Client
+}
+
+// NewZuora creates a Zuora provider.
+func NewZuora(client zuora.Client) *Zuora {
+	return &Zuora{client: client}
+}
+
+// Name returns "zuora".
+func (z *Zuora) Name() string {
+	return "zuora"
+}
+
+// CreateAccount creates a Zuora account for the organization.
+func (z *Zuora) CreateAccount(ctx context.Context, org *users.Organization, req AccountRequest) (*zuora.Account, error) {
+	return z.client.CreateAccount(
+		ctx,
+		org.ExternalID,
+		req.BillToContact,
+		req.Currency,
+		req.PaymentMethodID,
+		zuora.BillCycleDay,
+		org.TrialExpiresAt,
+	)
+}
+
+// GetAccount returns the Zuora account of the organization.
+func (z *Zuora) GetAccount(ctx context.Context, org *users.Organization) (*zuora.Account, error) {
+	return z.client.GetAccount(ctx, org.ZuoraAccountNumber)
+}
+
+// UpdateAccount updates the Zuora account of the organization.
+func (z *Zuora) UpdateAccount(ctx context.Context, org *users.Organization, details *zuora.Account) (*zuora.Account, error) {
+	return z.client.UpdateAccount(ctx, org.ZuoraAccountNumber, details)
+}
+
+// GetInvoices returns the invoices of the Zuora account of the organization.
+func (z *Zuora) GetInvoices(ctx context.Context, org *users.Organization, page, pageSize string) ([]zuora.Invoice, error) {
+	return z.client.GetInvoices(ctx, org.ZuoraAccountNumber, page, pageSize)
+}
+
+// GetPaymentMethod returns the default payment method of the Zuora account of the organization.
+func (z *Zuora) GetPaymentMethod(ctx context.Context, org *users.Organization) (*zuora.CreditCard, error) {
+	return z.client.GetPaymentMethod(ctx, org.ZuoraAccountNumber)
+}
+
+// UpdatePaymentMethod makes the payment method the default one of its Zuora account.
+func (z *Zuora) UpdatePaymentMethod(ctx context.Context, org *users.Organization, paymentMethodID string) error {
+	return z.client.UpdatePaymentMethod(ctx, paymentMethodID)
+}
@@ -0,0 +1,302 @@
+package reconciliation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/common/instrument"
+	"github.com/weaveworks/common/logging"
+	"github.com/weaveworks/common/user"
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/common/billing/catalog"
+	"github.com/weaveworks/service/common/billing/provider"
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/users"
+)
+
+// usagePageSize is how many usage records are fetched from Zuora at once.
+const usagePageSize = 40
+
+var (
+	discrepanciesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "billing",
+		Subsystem: "reconciliation",
+		Name:      "discrepancies",
+		Help:      "The number of unresolved discrepancies between uploaded and accepted usage",
+	}, []string{"uploader", "product"})
+	missingAmountGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "billing",
+		Subsystem: "reconciliation",
+		Name:      "missing_amount",
+		Help:      "The usage uploaded but not accepted by billing providers, in units of the product, over unresolved discrepancies",
+	}, []string{"uploader", "product"})
+)
+
+func init() {
+	prometheus.MustRegister(discrepanciesGauge, missingAmountGauge)
+}
+
+// Job compares the usage of each upload to Zuora with what Zuora accepted, and
+// records discrepancies between the two. Each upload is reconciled once, unless
+// its import is still in progress.
+// Uploads to GCP aren't reconciled: the uploader doesn't record the outcome of
+// each operation it reports, and Service Control has no way to query them.
+type Job struct {
+	db        db.DB
+	users     users.UsersClient
+	zuora     zuora.Client
+	collector *instrument.JobCollector
+}
+
+// NewJob creates a Job.
+func NewJob(db db.DB, users users.UsersClient, zuora zuora.Client, collector *instrument.JobCollector) *Job {
+	return &Job{
+		db:        db,
+		users:     users,
+		zuora:     zuora,
+		collector: collector,
+	}
+}
+
+// Run starts the job and logs errors.
+func (j *Job) Run() {
+	if err := j.Do(); err != nil {
+		log.Errorf("Error running reconciliation job: %v", err)
+	}
+}
+
+// Do reconciles the uploads to Zuora which weren't reconciled yet, and returns
+// an error if it fails.
+func (j *Job) Do() error {
+	return instrument.CollectedRequest(context.Background(), "Reconciliation.Do", j.collector, nil, func(ctx context.Context) error {
+		logger := user.LogWith(ctx, logging.Global())
+
+		uploads, err := j.db.GetUsageUploadsToReconcile(ctx, provider.Zuora)
+		if err != nil {
+			return err
+		}
+		for _, upload := range uploads {
+			if err := j.reconcile(ctx, upload); err != nil {
+				logger.Errorf("Failed to reconcile %v usage upload %d: %v", provider.Zuora, upload.ID, err)
+			}
+		}
+		return UpdateMetrics(ctx, j.db)
+	})
+}
+
+func (j *Job) reconcile(ctx context.Context, upload db.UsageUpload) error {
+	aggs, err := j.db.GetAggregatesUploaded(ctx, upload.ID)
+	if err != nil {
+		return err
+	}
+	found, done, err := j.reconcileZuora(ctx, upload, aggs)
+	if err != nil || !done {
+		return err
+	}
+	if err := j.db.InsertDiscrepancies(ctx, found); err != nil {
+		return err
+	}
+	return j.db.SetUsageUploadReconciled(ctx, upload.ID)
+}
+
+// reconcileZuora compares the usage of the upload with the usage Zuora imported
+// from its file, per account. It returns false if the import isn't finished.
+func (j *Job) reconcileZuora(ctx context.Context, upload db.UsageUpload, aggs []db.Aggregate) ([]db.Discrepancy, bool, error) {
+	if upload.ImportID == "" {
+		// The upload failed before Zuora imported it, or predates us recording imports.
+		return nil, true, nil
+	}
+	status, err := j.zuora.GetUsageImportStatus(ctx, j.zuora.GetUsageImportStatusURL(zuora.UsageUploadID(upload.ImportID)))
+	if err != nil {
+		return nil, false, err
+	}
+	expected := expectedUsage(provider.Zuora, aggs)
+	switch status.ImportStatus {
+	case "Pending", "Processing":
+		return nil, false, nil
+	case zuora.Completed:
+	default:
+		return discrepancies(upload, expected, usageSums{}, fmt.Sprintf("import %s", status.ImportStatus)), true, nil
+	}
+
+	var results []db.Discrepancy
+	for _, instanceID := range expected.instanceIDs() {
+		accepted, err := j.zuoraUsage(ctx, upload, instanceID, expected[instanceID].earliest)
+		if err != nil {
+			return nil, false, err
+		}
+		results = append(results, discrepancies(upload, usageSums{instanceID: expected[instanceID]}, usageSums{instanceID: accepted}, "not imported")...)
+	}
+	return results, true, nil
+}
+
+// zuoraUsage sums, per product, the usage of the instance's account Zuora
+// imported from the upload's file.
+func (j *Job) zuoraUsage(ctx context.Context, upload db.UsageUpload, instanceID string, earliest time.Time) (*instanceUsage, error) {
+	resp, err := j.users.GetOrganization(ctx, &users.GetOrganizationRequest{
+		ID: &users.GetOrganizationRequest_InternalID{InternalID: instanceID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	accountNumber := resp.Organization.ZuoraAccountNumber
+	usage := newInstanceUsage()
+	if accountNumber == "" {
+		return usage, nil
+	}
+
+	// Usage is reported for the billing period an aggregate is in, which
+	// doesn't start over a month before it.
+	since := earliest.AddDate(0, -1, 0)
+	fileName := zuora.UsageFileName(upload.Name)
+	for page := 1; ; page++ {
+		records, err := j.zuora.GetUsage(ctx, accountNumber, strconv.Itoa(page), strconv.Itoa(usagePageSize))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			startDate, err := time.Parse("2006-01-02 15:04:05", r.StartDate)
+			if err != nil {
+				return nil, err
+			}
+			// Records are in reverse chronological order.
+			if startDate.Before(since) {
+				return usage, nil
+			}
+			if r.SourceName != fileName {
+				continue
+			}
+			if product, ok := productCharged(provider.Zuora, r.UnitType); ok {
+				usage.amounts[product.Name] += int64(math.Round(r.Quantity))
+			}
+		}
+		if len(records) < usagePageSize {
+			return usage, nil
+		}
+	}
+}
+
+// productCharged returns the product billed as the charge through the provider.
+func productCharged(providerName, charge string) (catalog.Product, bool) {
+	for _, p := range catalog.Default {
+		if c, ok := p.Charges[providerName]; ok && c == charge {
+			return p, true
+		}
+	}
+	return catalog.Product{}, false
+}
+
+// instanceUsage is the usage of an instance per product.
+type instanceUsage struct {
+	amounts     map[string]int64
+	amountTypes map[string]map[string]bool
+	earliest    time.Time
+}
+
+func newInstanceUsage() *instanceUsage {
+	return &instanceUsage{amounts: map[string]int64{}, amountTypes: map[string]map[string]bool{}}
+}
+
+// usageSums is the usage of instances, by instance ID.
+type usageSums map[string]*instanceUsage
+
+func (s usageSums) add(agg db.Aggregate, product catalog.Product) {
+	u, ok := s[agg.InstanceID]
+	if !ok {
+		u = newInstanceUsage()
+		s[agg.InstanceID] = u
+	}
+	u.amounts[product.Name] += agg.AmountValue
+	if u.amountTypes[product.Name] == nil {
+		u.amountTypes[product.Name] = map[string]bool{}
+	}
+	u.amountTypes[product.Name][agg.AmountType] = true
+	if u.earliest.IsZero() || agg.BucketStart.Before(u.earliest) {
+		u.earliest = agg.BucketStart
+	}
+}
+
+func (s usageSums) instanceIDs() []string {
+	ids := []string{}
+	for id := range s {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// expectedUsage sums the aggregates billed through the provider. Uploaders
+// only link the aggregates they reported to an upload, so these are what the
+// provider was sent.
+func expectedUsage(providerName string, aggs []db.Aggregate) usageSums {
+	sums := usageSums{}
+	for _, agg := range aggs {
+		product, ok := catalog.Default.Product(agg.AmountType)
+		if !ok {
+			continue
+		}
+		if _, ok := product.Charges[providerName]; ok {
+			sums.add(agg, product)
+		}
+	}
+	return sums
+}
+
+// discrepancies returns the usage of each instance and product of which less
+// was accepted than expected.
+func discrepancies(upload db.UsageUpload, expected, accepted usageSums, reason string) []db.Discrepancy {
+	var results []db.Discrepancy
+	for _, instanceID := range expected.instanceIDs() {
+		for _, product := range catalog.Default {
+			amount, ok := expected[instanceID].amounts[product.Name]
+			if !ok {
+				continue
+			}
+			var acceptedAmount int64
+			if a, ok := accepted[instanceID]; ok {
+				acceptedAmount = a.amounts[product.Name]
+			}
+			if acceptedAmount >= amount {
+				continue
+			}
+			amountTypes := []string{}
+			for t := range expected[instanceID].amountTypes[product.Name] {
+				amountTypes = append(amountTypes, t)
+			}
+			sort.Strings(amountTypes)
+			results = append(results, db.Discrepancy{
+				UploadID:    upload.ID,
+				Uploader:    upload.Uploader,
+				InstanceID:  instanceID,
+				Product:     product.Name,
+				AmountTypes: amountTypes,
+				Expected:    amount,
+				Accepted:    acceptedAmount,
+				Reason:      reason,
+			})
+		}
+	}
+	return results
+}
+
+// UpdateMetrics sets the gauges of unresolved discrepancies.
+func UpdateMetrics(ctx context.Context, d db.DB) error {
+	unresolved, err := d.GetDiscrepancies(ctx)
+	if err != nil {
+		return err
+	}
+	discrepanciesGauge.Reset()
+	missingAmountGauge.Reset()
+	for _, r := range unresolved {
+		discrepanciesGauge.WithLabelValues(r.Uploader, r.Product).Inc()
+		missingAmountGauge.WithLabelValues(r.Uploader, r.Product).Add(float64(r.Expected - r.Accepted))
+	}
+	return nil
+}
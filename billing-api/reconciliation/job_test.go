@@ -0,0 +1,133 @@
+package reconciliation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/common/instrument"
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/billing-api/db/dbtest"
+	"github.com/weaveworks/service/billing-api/reconciliation"
+	"github.com/weaveworks/service/common/billing/provider"
+	"github.com/weaveworks/service/common/constants/billing"
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/common/zuora/mockzuora"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/mock_users"
+)
+
+type stubZuora struct {
+	mockzuora.StubClient
+	status string
+	usage  []zuora.Usage
+}
+
+func (z *stubZuora) GetUsageImportStatus(ctx context.Context, url string) (zuora.ImportStatusResponse, error) {
+	return zuora.ImportStatusResponse{ImportStatus: z.status}, nil
+}
+
+func (z *stubZuora) GetUsage(ctx context.Context, zuoraAccountNumber, page, pageSize string) ([]zuora.Usage, error) {
+	if page != "1" {
+		return nil, nil
+	}
+	return z.usage, nil
+}
+
+var start = time.Date(2018, 6, 3, 0, 0, 0, 0, time.UTC)
+
+func upload(t *testing.T, d db.DB, name string, aggs []db.Aggregate) int64 {
+	ctx := context.Background()
+	require.NoError(t, d.InsertAggregates(ctx, aggs))
+	toUpload, err := d.GetAggregatesToUpload(ctx, "100", start, start.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	ids := []int{}
+	for _, agg := range toUpload {
+		ids = append(ids, agg.ID)
+	}
+	uploadID, err := d.InsertUsageUpload(ctx, provider.Zuora, ids)
+	require.NoError(t, err)
+	require.NoError(t, d.SetUsageUploadImport(ctx, uploadID, name, "import-"+name))
+	return uploadID
+}
+
+func TestJob_Do(t *testing.T) {
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	u := mock_users.NewMockUsersClient(ctrl)
+	u.EXPECT().
+		GetOrganization(gomock.Any(), gomock.Any()).
+		Return(&users.GetOrganizationResponse{Organization: users.Organization{ID: "100", ZuoraAccountNumber: "Wfoo"}}, nil).
+		AnyTimes()
+	z := &stubZuora{status: zuora.Completed}
+	job := reconciliation.NewJob(d, u, z, instrument.NewJobCollector("test"))
+
+	uploadID := upload(t, d, "1", []db.Aggregate{
+		{InstanceID: "100", BucketStart: start, AmountType: billing.UsageNodeSeconds, AmountValue: 100},
+		{InstanceID: "100", BucketStart: start, AmountType: billing.UsageSamples, AmountValue: 50},
+	})
+	z.usage = []zuora.Usage{
+		{StartDate: "2018-06-03 00:00:00", UnitType: billing.UsageNodeSeconds, Quantity: 100, SourceName: zuora.UsageFileName("1")},
+		{StartDate: "2018-06-03 00:00:00", UnitType: billing.UsageSamples, Quantity: 30, SourceName: zuora.UsageFileName("1")},
+		{StartDate: "2018-06-03 00:00:00", UnitType: billing.UsageSamples, Quantity: 20, SourceName: zuora.UsageFileName("0")},
+		{StartDate: "2018-01-03 00:00:00", UnitType: billing.UsageSamples, Quantity: 20, SourceName: zuora.UsageFileName("1")},
+	}
+
+	// Only part of the samples were imported.
+	require.NoError(t, job.Do())
+	discrepancies, err := d.GetDiscrepancies(ctx)
+	require.NoError(t, err)
+	require.Len(t, discrepancies, 1)
+	assert.Equal(t, uploadID, discrepancies[0].UploadID)
+	assert.Equal(t, "samples", discrepancies[0].Product)
+	assert.Equal(t, []string{billing.UsageSamples}, discrepancies[0].AmountTypes)
+	assert.Equal(t, int64(50), discrepancies[0].Expected)
+	assert.Equal(t, int64(30), discrepancies[0].Accepted)
+
+	// Uploads are reconciled once.
+	require.NoError(t, job.Do())
+	discrepancies, err = d.GetDiscrepancies(ctx)
+	require.NoError(t, err)
+	assert.Len(t, discrepancies, 1)
+
+	// Uploads whose import isn't finished are reconciled later, failed ones entirely mismatch.
+	require.NoError(t, d.ResolveDiscrepancy(ctx, discrepancies[0].ID, false))
+	upload(t, d, "2", []db.Aggregate{
+		{InstanceID: "100", BucketStart: start.Add(time.Hour), AmountType: billing.UsageFluxRelease, AmountValue: 2},
+		{InstanceID: "100", BucketStart: start.Add(time.Hour), AmountType: billing.UsageFluxAutoRelease, AmountValue: 3},
+	})
+	z.status = "Processing"
+	require.NoError(t, job.Do())
+	discrepancies, err = d.GetDiscrepancies(ctx)
+	require.NoError(t, err)
+	assert.Len(t, discrepancies, 0)
+
+	z.status = "Failed"
+	require.NoError(t, job.Do())
+	discrepancies, err = d.GetDiscrepancies(ctx)
+	require.NoError(t, err)
+	require.Len(t, discrepancies, 1)
+	assert.Equal(t, "deploys", discrepancies[0].Product)
+	assert.Equal(t, []string{billing.UsageFluxAutoRelease, billing.UsageFluxRelease}, discrepancies[0].AmountTypes)
+	assert.Equal(t, int64(5), discrepancies[0].Expected)
+	assert.Equal(t, int64(0), discrepancies[0].Accepted)
+	assert.Equal(t, "import Failed", discrepancies[0].Reason)
+
+	// Requeuing unlinks the aggregates from the upload.
+	require.NoError(t, d.ResolveDiscrepancy(ctx, discrepancies[0].ID, true))
+	toUpload, err := d.GetAggregatesToUpload(ctx, "100", start, start.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Len(t, toUpload, 2)
+	resolved, err := d.GetDiscrepancy(ctx, discrepancies[0].ID)
+	require.NoError(t, err)
+	assert.True(t, resolved.Requeued)
+	assert.False(t, resolved.ResolvedAt.IsZero())
+}
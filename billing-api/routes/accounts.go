@@ -16,6 +16,7 @@ import (
 	"github.com/weaveworks/common/logging"
 	"github.com/weaveworks/common/user"
 	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/billing-api/payments"
 	"github.com/weaveworks/service/billing-api/trial"
 	"github.com/weaveworks/service/common/billing/grpc"
 	"github.com/weaveworks/service/common/constants/billing"
@@ -49,11 +50,18 @@ func (a *API) createAccount(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return err
 	}
-	account, err := a.createZuoraAccount(r.Context(), logger, req, resp)
+	p, err := a.paymentProvider(ctx, &resp.Organization)
 	if err != nil {
 		return err
 	}
-	a.markOrganizationDutiful(ctx, logger, externalID, account.Number)
+	account, err := a.createProviderAccount(ctx, logger, p, req, resp)
+	if err != nil {
+		return err
+	}
+	if isZuora(p) {
+		a.setOrganizationZuoraAccount(ctx, logger, externalID, account.Number)
+	}
+	a.markOrganizationDutiful(ctx, logger, externalID)
 	_, err = a.Users.InformOrganizationBillingConfigured(
 		ctx,
 		&users.InformOrganizationBillingConfiguredRequest{
@@ -65,10 +73,11 @@ func (a *API) createAccount(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	// As the customer may have delayed setting up their account, we need to
-	// upload any historic usage data since their trial period expired
+	// upload any historic usage data since their trial period expired.
+	// Stripe customers are subscribed from now on, so their past usage isn't billed.
 	today := time.Now().UTC().Truncate(24 * time.Hour)
 	// If the trial expires today we'll catch the usage in the uploader next time it's run
-	if !resp.Organization.InTrialPeriod(today) {
+	if isZuora(p) && !resp.Organization.InTrialPeriod(today) {
 		orgID := resp.Organization.ID
 		trialExpiry := resp.Organization.TrialExpiresAt
 		usageImportID, err := a.FetchAndUploadUsage(r.Context(), account, orgID, externalID, trialExpiry, today, zuora.BillCycleDay)
@@ -107,34 +116,33 @@ func (a *API) getOrganization(ctx context.Context, externalID string) (*users.Ge
 	return resp, nil
 }
 
-func (a *API) createZuoraAccount(ctx context.Context, logger logging.Interface, req *createAccountRequest, resp *users.GetOrganizationResponse) (*zuora.Account, error) {
-	logger.Infof("Creating Zuora account for %v", req.WeaveID)
-	account, err := a.Zuora.CreateAccount(
-		ctx,
-		req.WeaveID,
-		req.BillToContact,
-		req.Currency,
-		req.PaymentMethodID,
-		zuora.BillCycleDay,
-		resp.Organization.TrialExpiresAt,
-	)
+func (a *API) createProviderAccount(ctx context.Context, logger logging.Interface, p payments.Provider, req *createAccountRequest, resp *users.GetOrganizationResponse) (*zuora.Account, error) {
+	logger.Infof("Creating %s account for %v", p.Name(), req.WeaveID)
+	account, err := p.CreateAccount(ctx, &resp.Organization, payments.AccountRequest{
+		Currency:        req.Currency,
+		BillToContact:   req.BillToContact,
+		PaymentMethodID: req.PaymentMethodID,
+	})
 	if err != nil {
-		logger.Errorf("Failed to create Zuora account for %v: %v", req.WeaveID, err)
+		logger.Errorf("Failed to create %s account for %v: %v", p.Name(), req.WeaveID, err)
 		return nil, err
 	}
 	return account, nil
 }
 
-// markOrganizationDutiful tells the user service that the organization is no longer delinquent.
-func (a *API) markOrganizationDutiful(ctx context.Context, logger logging.Interface, externalID, zuoraAccountNumber string) {
-	var err error
-	_, err = a.Users.SetOrganizationZuoraAccount(ctx, &users.SetOrganizationZuoraAccountRequest{
+// setOrganizationZuoraAccount tells the user service the number of the organization's Zuora account.
+func (a *API) setOrganizationZuoraAccount(ctx context.Context, logger logging.Interface, externalID, zuoraAccountNumber string) {
+	_, err := a.Users.SetOrganizationZuoraAccount(ctx, &users.SetOrganizationZuoraAccountRequest{
 		ExternalID: externalID, Number: zuoraAccountNumber,
 	})
 	if err != nil {
 		logger.Errorf("Failed to set Zuora account for %v to %v", externalID, zuoraAccountNumber)
 	}
+}
 
+// markOrganizationDutiful tells the user service that the organization is no longer delinquent.
+func (a *API) markOrganizationDutiful(ctx context.Context, logger logging.Interface, externalID string) {
+	var err error
 	logger.Infof("Updating users service with billing account status for %v", externalID)
 	_, err = a.Users.SetOrganizationFlag(ctx, &users.SetOrganizationFlagRequest{
 		ExternalID: externalID, Flag: orgs.RefuseDataAccess, Value: false})
@@ -185,12 +193,12 @@ func (a *API) uploadUsage(ctx context.Context, externalID string, account *zuora
 		return "", err
 	}
 	subscriptionNumber := account.Subscription.SubscriptionNumber
-	chargeNumber := account.Subscription.ChargeNumber
+	charges := account.Subscription.ChargeNumbers()
 	report, err := zuora.ReportFromAggregates(
-		a.Zuora.GetConfig(), aggs, account.PaymentProviderID, trialExpiry, today, subscriptionNumber, chargeNumber, cycleDay,
+		a.Zuora.GetConfig(), aggs, account.PaymentProviderID, trialExpiry, today, subscriptionNumber, charges, cycleDay,
 	)
 	if err != nil {
-		logger.Errorf("Failed to create usage report for %v/%v/%v: %v", externalID, subscriptionNumber, chargeNumber, err)
+		logger.Errorf("Failed to create usage report for %v/%v: %v", externalID, subscriptionNumber, err)
 		return "", err
 	}
 
@@ -203,14 +211,14 @@ func (a *API) uploadUsage(ctx context.Context, externalID string, account *zuora
 	logger.Infof("Uploading post-trial usage data for %v", externalID)
 	importID, err := a.Zuora.UploadUsage(ctx, reader, fmt.Sprintf("p-%s", externalID))
 	if err != nil {
-		logger.Errorf("Failed to upload usage report for %v/%v/%v: %v", externalID, subscriptionNumber, chargeNumber, err)
+		logger.Errorf("Failed to upload usage report for %v/%v: %v", externalID, subscriptionNumber, err)
 		return "", err
 	}
 
 	return importID, nil
 }
 
-// CreateAccount creates an account on Zuora and uploads any pending usage data.
+// CreateAccount creates an account on Zuora, or Stripe, and uploads any pending usage data.
 func (a *API) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	if _, err := a.Users.RequireOrgMemberPermissionTo(r.Context(), &users.RequireOrgMemberPermissionToRequest{
 		OrgID:        &users.RequireOrgMemberPermissionToRequest_OrgExternalID{OrgExternalID: mux.Vars(r)["id"]},
@@ -240,7 +248,7 @@ type organizationWithTrial struct {
 	Trial      trial.Trial `json:"trial"`
 }
 
-// GetAccount gets the account from Zuora.
+// GetAccount gets the account from Zuora, or Stripe.
 func (a *API) GetAccount(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	resp, err := a.Users.GetOrganization(ctx, &users.GetOrganizationRequest{
@@ -251,7 +259,12 @@ func (a *API) GetAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	account, err := a.Zuora.GetAccount(ctx, resp.Organization.ZuoraAccountNumber)
+	p, err := a.paymentProvider(ctx, &resp.Organization)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	account, err := p.GetAccount(ctx, &resp.Organization)
 	if err != nil {
 		renderError(w, r, err)
 		return
@@ -269,7 +282,7 @@ func (a *API) GetAccount(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// UpdateAccount updates the account on Zuora.
+// UpdateAccount updates the account on Zuora, or Stripe.
 func (a *API) UpdateAccount(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	orgExternalID := mux.Vars(r)["id"]
@@ -293,8 +306,12 @@ func (a *API) UpdateAccount(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, err)
 		return
 	}
-	org := resp.Organization
-	account, err := a.Zuora.UpdateAccount(ctx, org.ZuoraAccountNumber, req)
+	p, err := a.paymentProvider(ctx, &resp.Organization)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	account, err := p.UpdateAccount(ctx, &resp.Organization, req)
 	if err != nil {
 		renderError(w, r, err)
 		return
@@ -453,7 +470,12 @@ func (a *API) GetAccountStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	currency := "" // Leave currency blank by default. It is only set if a subscription exists...
-	zuoraAcct, err := a.Zuora.GetAccount(ctx, org.ZuoraAccountNumber)
+	p, err := a.paymentProvider(ctx, &org)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	zuoraAcct, err := p.GetAccount(ctx, &org)
 	if err != nil && err != zuora.ErrNotFound && err != zuora.ErrInvalidAccountNumber {
 		renderError(w, r, err)
 		return
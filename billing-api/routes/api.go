@@ -11,6 +11,8 @@ import (
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/billing-api/forecast"
+	"github.com/weaveworks/service/common/stripe"
 	"github.com/weaveworks/service/common/zuora"
 	"github.com/weaveworks/service/users"
 )
@@ -20,6 +22,7 @@ type Config struct {
 	CORSAllowOrigin string
 	AdminURL        string
 	HMACSecret      string
+	RatesFile       string
 }
 
 // RegisterFlags registers configuration variables.
@@ -27,21 +30,27 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&c.CORSAllowOrigin, "cors.allow.origin", "https://cloud.weave.works", "Sets the Access-Control-Allow-Origin header")
 	f.StringVar(&c.AdminURL, "admin.url", "/admin", "prefix root of link to organization details")
 	f.StringVar(&c.HMACSecret, "hmac.secret", "", "Secret for generating HMAC signatures")
+	f.StringVar(&c.RatesFile, "forecast.rates-file", "", "JSON file of rates per unit of measure and currency, to forecast the cost of instances not billed through Zuora. Zuora's rates are used if empty")
 }
 
 // API is the billing api
 type API struct {
 	Config
-	DB            db.DB
-	Users         users.UsersClient
-	Zuora         zuora.Client
+	DB    db.DB
+	Users users.UsersClient
+	Zuora zuora.Client
+	// Stripe bills teams whose billing account provider is Stripe. It is nil
+	// if Stripe isn't configured, in which case everyone is billed through Zuora.
+	Stripe stripe.Client
+	// Forecaster estimates the cost of organizations this month.
+	Forecaster    *forecast.Forecaster
 	adminTemplate *template.Template
 	HMACSecret    []byte
 	http.Handler
 }
 
 // New creates a new APi
-func New(cfg Config, db db.DB, users users.UsersClient, zuora zuora.Client) (*API, error) {
+func New(cfg Config, db db.DB, users users.UsersClient, zuora zuora.Client, stripe stripe.Client) (*API, error) {
 	if cfg.HMACSecret == "" {
 		log.Warn("HMAC key is empty")
 	}
@@ -57,11 +66,18 @@ func New(cfg Config, db db.DB, users users.UsersClient, zuora zuora.Client) (*AP
 		},
 	}
 
+	forecaster, err := newForecaster(cfg, db, zuora)
+	if err != nil {
+		return nil, err
+	}
+
 	a := &API{
 		Config:        cfg,
 		DB:            db,
 		Users:         users,
 		Zuora:         zuora,
+		Stripe:        stripe,
+		Forecaster:    forecaster,
 		adminTemplate: template.Must(template.New("admin").Funcs(funcMap).Parse(adminTemplate)),
 		HMACSecret:    hmac,
 	}
@@ -71,3 +87,15 @@ func New(cfg Config, db db.DB, users users.UsersClient, zuora zuora.Client) (*AP
 	a.Handler = r
 	return a, nil
 }
+
+// newForecaster creates a forecaster with the rate table of the config, if any.
+func newForecaster(cfg Config, db db.DB, z zuora.Client) (*forecast.Forecaster, error) {
+	var table zuora.RateMap
+	if cfg.RatesFile != "" {
+		var err error
+		if table, err = forecast.LoadRateTable(cfg.RatesFile); err != nil {
+			return nil, err
+		}
+	}
+	return forecast.NewForecaster(db, z, table), nil
+}
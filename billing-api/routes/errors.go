@@ -4,7 +4,10 @@ import (
 	"database/sql"
 	"net/http"
 
+	"github.com/pkg/errors"
+
 	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/common/stripe"
 	"github.com/weaveworks/service/common/zuora"
 )
 
@@ -14,14 +17,18 @@ func renderError(w http.ResponseWriter, r *http.Request, err error) {
 
 func errorStatusCode(err error) int {
 	switch err {
-	case sql.ErrNoRows, zuora.ErrNotFound, zuora.ErrNoDefaultPaymentMethod, zuora.ErrorObtainingPaymentMethod, zuora.ErrInvalidAccountNumber:
+	case sql.ErrNoRows, stripe.ErrNotFound, zuora.ErrNotFound, zuora.ErrNoDefaultPaymentMethod, zuora.ErrorObtainingPaymentMethod, zuora.ErrInvalidAccountNumber:
 		return http.StatusNotFound
-	case zuora.ErrInvalidSubscriptionStatus:
+	case zuora.ErrInvalidSubscriptionStatus, errInvalidBudgetAmount, errInvalidBudgetThreshold, errPartiallyAccepted:
 		return http.StatusBadRequest
 	case zuora.ErrNoSubscriptions:
 		return http.StatusUnprocessableEntity
 	}
 
+	if stripeErr, ok := errors.Cause(err).(*stripe.Error); ok && stripeErr.IsCardError() {
+		return http.StatusPaymentRequired
+	}
+
 	if err.Error() == zuora.ErrNotFound.Error() {
 		return http.StatusNotFound
 	}
@@ -0,0 +1,232 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/common/user"
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/common/permission"
+	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/users"
+)
+
+const defaultCurrency = "USD"
+
+// defaultBudgetThresholds are the percentages of a budget teams are notified
+// about if they don't pick any.
+var defaultBudgetThresholds = []int{50, 80, 100}
+
+var (
+	errInvalidBudgetAmount    = errors.New("budget amount must be positive")
+	errInvalidBudgetThreshold = errors.New("budget thresholds must be percentages between 1 and 1000")
+)
+
+// forecastResponse is the cost of an organization for the current calendar
+// month. Amounts are formatted with two decimals, as in the account status.
+type forecastResponse struct {
+	Currency    string            `json:"currency"`
+	Start       string            `json:"start"`
+	End         string            `json:"end"`
+	MonthToDate string            `json:"monthToDate"`
+	Projected   string            `json:"projected"`
+	Products    []productForecast `json:"products"`
+	Unpriced    []string          `json:"unpriced,omitempty"`
+	Budget      *budget           `json:"budget,omitempty"`
+}
+
+type productForecast struct {
+	Product     string `json:"product"`
+	Unit        string `json:"unit"`
+	Usage       int64  `json:"usage"`
+	MonthToDate string `json:"monthToDate"`
+	Projected   string `json:"projected"`
+}
+
+// budget is the monthly budget of an organization, and the percentages of it
+// crossing which its members are notified about.
+type budget struct {
+	Amount     float64 `json:"amount"`
+	Currency   string  `json:"currency"`
+	Thresholds []int   `json:"thresholds"`
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func budgetFromDB(b *db.Budget) *budget {
+	if b == nil {
+		return nil
+	}
+	return &budget{Amount: b.Amount, Currency: b.Currency, Thresholds: b.Thresholds}
+}
+
+// GetForecast returns the cost of an organization so far this month, and as
+// projected to the end of the month. It supports form value `currency`, which
+// otherwise defaults to the currency of the organization's budget, or USD.
+func (a *API) GetForecast(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp, err := a.getOrganization(ctx, mux.Vars(r)["id"])
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	org := resp.Organization
+
+	b, err := a.DB.GetBudget(ctx, org.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	currency := r.FormValue("currency")
+	if currency == "" && b != nil {
+		currency = b.Currency
+	}
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	fc, err := a.Forecaster.Forecast(ctx, &org, currency, time.Now())
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	products := []productForecast{}
+	for _, p := range fc.Products {
+		products = append(products, productForecast{
+			Product:     p.Product,
+			Unit:        p.Unit,
+			Usage:       p.Usage,
+			MonthToDate: formatAmount(p.MonthToDate),
+			Projected:   formatAmount(p.Projected),
+		})
+	}
+	render.JSON(w, http.StatusOK, forecastResponse{
+		Currency:    fc.Currency,
+		Start:       fc.Start.Format(dayTimeLayout),
+		End:         fc.End.Format(dayTimeLayout),
+		MonthToDate: formatAmount(fc.MonthToDate),
+		Projected:   formatAmount(fc.Projected),
+		Products:    products,
+		Unpriced:    fc.Unpriced,
+		Budget:      budgetFromDB(b),
+	})
+}
+
+// GetBudget returns the monthly budget of an organization.
+func (a *API) GetBudget(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resp, err := a.getOrganization(ctx, mux.Vars(r)["id"])
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	b, err := a.DB.GetBudget(ctx, resp.Organization.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if b == nil {
+		renderError(w, r, sql.ErrNoRows)
+		return
+	}
+	render.JSON(w, http.StatusOK, budgetFromDB(b))
+}
+
+// SetBudget sets the monthly budget of an organization. Thresholds default to
+// 50%, 80% and 100% of the budget.
+func (a *API) SetBudget(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !a.requireUpdateBilling(w, r) {
+		return
+	}
+
+	req := budget{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if req.Amount <= 0 {
+		renderError(w, r, errInvalidBudgetAmount)
+		return
+	}
+	if req.Currency == "" {
+		req.Currency = defaultCurrency
+	}
+	thresholds, err := normalizeThresholds(req.Thresholds)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	resp, err := a.getOrganization(ctx, mux.Vars(r)["id"])
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	b, err := a.DB.SetBudget(ctx, resp.Organization.ID, req.Amount, req.Currency, thresholds)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, budgetFromDB(b))
+}
+
+// DeleteBudget removes the monthly budget of an organization.
+func (a *API) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !a.requireUpdateBilling(w, r) {
+		return
+	}
+	resp, err := a.getOrganization(ctx, mux.Vars(r)["id"])
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.DB.DeleteBudget(ctx, resp.Organization.ID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) requireUpdateBilling(w http.ResponseWriter, r *http.Request) bool {
+	if _, err := a.Users.RequireOrgMemberPermissionTo(r.Context(), &users.RequireOrgMemberPermissionToRequest{
+		OrgID:        &users.RequireOrgMemberPermissionToRequest_OrgExternalID{OrgExternalID: mux.Vars(r)["id"]},
+		UserID:       r.Header.Get(user.UserIDHeaderName),
+		PermissionID: permission.UpdateBilling,
+	}); err != nil {
+		renderError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// normalizeThresholds sorts and deduplicates thresholds, defaulting them if
+// there are none.
+func normalizeThresholds(thresholds []int) ([]int, error) {
+	if len(thresholds) == 0 {
+		return defaultBudgetThresholds, nil
+	}
+	seen := map[int]bool{}
+	result := []int{}
+	for _, t := range thresholds {
+		if t < 1 || t > 1000 {
+			return nil, errInvalidBudgetThreshold
+		}
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	sort.Ints(result)
+	return result, nil
+}
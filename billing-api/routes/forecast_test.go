@@ -0,0 +1,90 @@
+package routes_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/billing-api/db/dbtest"
+	"github.com/weaveworks/service/billing-api/routes"
+	"github.com/weaveworks/service/common/constants/billing"
+	"github.com/weaveworks/service/common/zuora"
+	"github.com/weaveworks/service/common/zuora/mockzuora"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/mock_users"
+)
+
+type zuoraStubRates struct {
+	mockzuora.StubClient
+}
+
+func (z *zuoraStubRates) GetCurrentRates(ctx context.Context) (zuora.RateMap, error) {
+	return zuora.RateMap{billing.UsageNodeSeconds: {"USD": 0.01, "EUR": 0.02}}, nil
+}
+
+func TestBudgetAndForecast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+
+	u := mock_users.NewMockUsersClient(ctrl)
+	u.EXPECT().
+		GetOrganization(gomock.Any(), gomock.Any()).
+		Return(&users.GetOrganizationResponse{Organization: users.Organization{ID: "100", ExternalID: "foo-bar-99"}}, nil).
+		AnyTimes()
+	u.EXPECT().
+		RequireOrgMemberPermissionTo(gomock.Any(), gomock.Any()).
+		Return(&users.Empty{}, nil).
+		AnyTimes()
+	api, err := routes.New(routes.Config{}, d, u, &zuoraStubRates{}, nil)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	err = d.InsertAggregates(context.Background(), []db.Aggregate{
+		{InstanceID: "100", BucketStart: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), AmountType: billing.UsageNodeSeconds, AmountValue: 1234},
+	})
+	require.NoError(t, err)
+
+	do := func(method, path string, body io.Reader, expectedCode int) string {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, body)
+		api.ServeHTTP(rec, req)
+		response, err := ioutil.ReadAll(rec.Body)
+		require.NoError(t, err)
+		assert.Equal(t, expectedCode, rec.Code, string(response))
+		return string(response)
+	}
+
+	do("GET", "/api/billing/foo-bar-99/budget", nil, http.StatusNotFound)
+	do("PUT", "/api/billing/foo-bar-99/budget", strings.NewReader(`{"amount": 0}`), http.StatusBadRequest)
+	do("PUT", "/api/billing/foo-bar-99/budget", strings.NewReader(`{"amount": 10, "thresholds": [0]}`), http.StatusBadRequest)
+
+	resp := do("PUT", "/api/billing/foo-bar-99/budget", strings.NewReader(`{"amount": 10, "currency": "EUR", "thresholds": [90, 75, 90]}`), http.StatusOK)
+	assert.JSONEq(t, `{"amount": 10, "currency": "EUR", "thresholds": [75, 90]}`, resp)
+	resp = do("GET", "/api/billing/foo-bar-99/budget", nil, http.StatusOK)
+	assert.JSONEq(t, `{"amount": 10, "currency": "EUR", "thresholds": [75, 90]}`, resp)
+
+	// The forecast defaults to the budget's currency.
+	resp = do("GET", "/api/billing/foo-bar-99/forecast", nil, http.StatusOK)
+	assert.Contains(t, resp, `"currency":"EUR","start":"`+now.Format("2006-01")+`-01"`)
+	assert.Contains(t, resp, `"monthToDate":"24.68"`)
+	assert.Contains(t, resp, `"budget":{"amount":10,"currency":"EUR","thresholds":[75,90]}`)
+	resp = do("GET", "/api/billing/foo-bar-99/forecast?currency=USD", nil, http.StatusOK)
+	assert.Contains(t, resp, `"monthToDate":"12.34"`)
+
+	do("DELETE", "/api/billing/foo-bar-99/budget", nil, http.StatusNoContent)
+	do("GET", "/api/billing/foo-bar-99/budget", nil, http.StatusNotFound)
+	resp = do("PUT", "/api/billing/foo-bar-99/budget", strings.NewReader(`{"amount": 10}`), http.StatusOK)
+	assert.JSONEq(t, `{"amount": 10, "currency": "USD", "thresholds": [50, 80, 100]}`, resp)
+}
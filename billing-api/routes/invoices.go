@@ -78,10 +78,14 @@ func (a *API) GetAccountInvoices(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, err)
 		return
 	}
-	org := resp.Organization
-	invoices, err := a.Zuora.GetInvoices(
+	p, err := a.paymentProvider(r.Context(), &resp.Organization)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	invoices, err := p.GetInvoices(
 		r.Context(),
-		org.ZuoraAccountNumber,
+		&resp.Organization,
 		r.FormValue("page"),
 		r.FormValue("pageSize"),
 	)
@@ -99,11 +103,14 @@ func (a *API) GetAccountInvoices(w http.ResponseWriter, r *http.Request) {
 		if invoice.Status != zuora.InvoiceStatusPosted {
 			continue
 		}
-		invoice, err = a.mangleInvoice(externalID, invoice)
-		if err != nil {
-			logger.Errorf("Error mangling invoice: %v", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-			return
+		// Stripe invoices link to their own hosted files, only Zuora's are served by us.
+		if isZuora(p) {
+			invoice, err = a.mangleInvoice(externalID, invoice)
+			if err != nil {
+				logger.Errorf("Error mangling invoice: %v", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
 		}
 		postedInvoices = append(postedInvoices, invoice)
 	}
@@ -33,8 +33,12 @@ func (a *API) GetPaymentMethod(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, err)
 		return
 	}
-	org := resp.Organization
-	method, err := a.Zuora.GetPaymentMethod(r.Context(), org.ZuoraAccountNumber)
+	p, err := a.paymentProvider(r.Context(), &resp.Organization)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	method, err := p.GetPaymentMethod(r.Context(), &resp.Organization)
 	if err != nil {
 		renderError(w, r, err)
 		return
@@ -53,7 +57,17 @@ func (a *API) UpdatePaymentMethod(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := a.Zuora.UpdatePaymentMethod(r.Context(), mux.Vars(r)["payment_id"]); err != nil {
+	resp, err := a.getOrganization(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	p, err := a.paymentProvider(r.Context(), &resp.Organization)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := p.UpdatePaymentMethod(r.Context(), &resp.Organization, mux.Vars(r)["payment_id"]); err != nil {
 		renderError(w, r, err)
 		return
 	}
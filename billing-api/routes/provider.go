@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"context"
+
+	"github.com/weaveworks/service/billing-api/payments"
+	"github.com/weaveworks/service/common/billing/provider"
+	"github.com/weaveworks/service/users"
+)
+
+// paymentProvider returns the provider the organization is billed through:
+// Stripe if its team's billing account says so and Stripe is configured, Zuora
+// otherwise.
+func (a *API) paymentProvider(ctx context.Context, org *users.Organization) (payments.Provider, error) {
+	if a.Stripe == nil || org.TeamID == "" {
+		return payments.NewZuora(a.Zuora), nil
+	}
+	account, err := a.DB.FindBillingAccountByTeamID(ctx, org.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil && account.Provider == provider.Stripe {
+		return payments.NewStripe(a.Stripe, a.DB), nil
+	}
+	return payments.NewZuora(a.Zuora), nil
+}
+
+// isZuora tells whether the provider bills through Zuora, for what only
+// Zuora does: usage uploads, account numbers on organizations and invoice files.
+func isZuora(p payments.Provider) bool {
+	_, ok := p.(*payments.Zuora)
+	return ok
+}
@@ -0,0 +1,125 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/service/billing-api/reconciliation"
+	"github.com/weaveworks/service/common/render"
+)
+
+const reconciliationPath = "/admin/billing/reconciliation"
+
+// errPartiallyAccepted is returned when requeuing a discrepancy the billing
+// provider accepted part of, which would bill that part twice.
+var errPartiallyAccepted = errors.New("cannot requeue usage which was partially accepted")
+
+var reconciliationTemplate = template.Must(template.New("reconciliation").Parse(`
+<html>
+	<head><title>Billing Reconciliation</title></head>
+	<body>
+		<h1>Billing Reconciliation</h1>
+		<p>Usage uploaded to billing providers, of which they accepted less than was uploaded.
+		Requeuing uploads the usage again, if it is recent enough for the uploader to pick it up.</p>
+		{{if .Discrepancies}}
+		<table>
+			<thead>
+				<tr>
+					<th>Upload</th><th>Uploader</th><th>Instance</th><th>Product</th>
+					<th>Expected</th><th>Accepted</th><th>Reason</th><th>Detected</th><th></th>
+				</tr>
+			</thead>
+			<tbody>
+				{{range .Discrepancies}}
+				<tr>
+					<td>{{.UploadID}}</td>
+					<td>{{.Uploader}}</td>
+					<td>{{.InstanceID}}</td>
+					<td>{{.Product}}</td>
+					<td>{{.Expected}}</td>
+					<td>{{.Accepted}}</td>
+					<td>{{.Reason}}</td>
+					<td>{{.DetectedAt.Format "2006-01-02 15:04"}}</td>
+					<td>
+						{{if eq .Accepted 0}}
+						<form action="reconciliation/{{.ID}}/requeue" method="post">
+							<input type="hidden" name="csrf_token" value="{{$.CSRFToken}}">
+							<button type="submit">Requeue</button>
+						</form>
+						{{end}}
+						<form action="reconciliation/{{.ID}}/resolve" method="post">
+							<input type="hidden" name="csrf_token" value="{{$.CSRFToken}}">
+							<button type="submit">Resolve</button>
+						</form>
+					</td>
+				</tr>
+				{{end}}
+			</tbody>
+		</table>
+		{{else}}
+		<p>No discrepancies.</p>
+		{{end}}
+	</body>
+</html>
+`))
+
+// Reconciliation lists the discrepancies between uploaded and accepted usage
+// which haven't been resolved.
+func (a *API) Reconciliation(w http.ResponseWriter, r *http.Request) {
+	discrepancies, err := a.DB.GetDiscrepancies(r.Context())
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.HTMLTemplate(w, http.StatusOK, reconciliationTemplate, map[string]interface{}{
+		"Discrepancies": discrepancies,
+		"CSRFToken":     csrfTokenPlaceholder,
+	})
+}
+
+// RequeueDiscrepancy resolves a discrepancy by uploading its usage again.
+func (a *API) RequeueDiscrepancy(w http.ResponseWriter, r *http.Request) {
+	a.resolveDiscrepancy(w, r, true)
+}
+
+// ResolveDiscrepancy resolves a discrepancy without uploading its usage again.
+func (a *API) ResolveDiscrepancy(w http.ResponseWriter, r *http.Request) {
+	a.resolveDiscrepancy(w, r, false)
+}
+
+func (a *API) resolveDiscrepancy(w http.ResponseWriter, r *http.Request, requeue bool) {
+	ctx := r.Context()
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		renderError(w, r, sql.ErrNoRows)
+		return
+	}
+	d, err := a.DB.GetDiscrepancy(ctx, id)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if d == nil {
+		renderError(w, r, sql.ErrNoRows)
+		return
+	}
+	if requeue && d.Accepted > 0 {
+		renderError(w, r, errPartiallyAccepted)
+		return
+	}
+	if err := a.DB.ResolveDiscrepancy(ctx, id, requeue); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := reconciliation.UpdateMetrics(ctx, a.DB); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	// Redirect, as authfe only fills in the CSRF token of GET responses.
+	http.Redirect(w, r, reconciliationPath, http.StatusSeeOther)
+}
@@ -0,0 +1,58 @@
+package routes_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/billing-api/db/dbtest"
+	"github.com/weaveworks/service/billing-api/routes"
+	"github.com/weaveworks/service/common/constants/billing"
+	"github.com/weaveworks/service/common/zuora/mockzuora"
+	"github.com/weaveworks/service/users/mock_users"
+)
+
+func TestReconciliation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+	api, err := routes.New(routes.Config{}, d, mock_users.NewMockUsersClient(ctrl), &mockzuora.StubClient{}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, d.InsertDiscrepancies(context.Background(), []db.Discrepancy{
+		{UploadID: 1, Uploader: "zuora", InstanceID: "100", Product: "samples", AmountTypes: []string{billing.UsageSamples}, Expected: 50, Accepted: 30, Reason: "not imported"},
+		{UploadID: 1, Uploader: "zuora", InstanceID: "101", Product: "nodes", AmountTypes: []string{billing.UsageNodeSeconds}, Expected: 10, Reason: "not imported"},
+	}))
+
+	do := func(method, path string, expectedCode int) string {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		api.ServeHTTP(rec, req)
+		response, err := ioutil.ReadAll(rec.Body)
+		require.NoError(t, err)
+		assert.Equal(t, expectedCode, rec.Code, string(response))
+		return string(response)
+	}
+
+	resp := do("GET", "/admin/billing/reconciliation", http.StatusOK)
+	assert.Contains(t, resp, "<td>samples</td>")
+	assert.Contains(t, resp, "reconciliation/2/requeue")
+	assert.NotContains(t, resp, "reconciliation/1/requeue")
+
+	// Partially accepted usage can't be requeued, only resolved.
+	do("POST", "/admin/billing/reconciliation/1/requeue", http.StatusBadRequest)
+	do("POST", "/admin/billing/reconciliation/1/resolve", http.StatusSeeOther)
+	do("POST", "/admin/billing/reconciliation/2/requeue", http.StatusSeeOther)
+	do("POST", "/admin/billing/reconciliation/3/resolve", http.StatusNotFound)
+
+	resp = do("GET", "/admin/billing/reconciliation", http.StatusOK)
+	assert.Contains(t, resp, "No discrepancies.")
+}
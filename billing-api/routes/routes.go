@@ -17,6 +17,9 @@ func (a *API) RegisterRoutes(r *mux.Router) {
 		{"admin_csv", "GET", "/admin/billing.csv", a.ExportOrgsAndUsageAsCSV},
 		{"admin_invoice_verify", "GET", "/admin/billing/invoice-verify", a.InvoiceVerify},
 		{"admin_invoice_verify", "POST", "/admin/billing/invoice-verify", a.PerformInvoiceVerify},
+		{"admin_reconciliation", "GET", "/admin/billing/reconciliation", a.Reconciliation},
+		{"admin_reconciliation_id_requeue", "POST", "/admin/billing/reconciliation/{id}/requeue", a.RequeueDiscrepancy},
+		{"admin_reconciliation_id_resolve", "POST", "/admin/billing/reconciliation/{id}/resolve", a.ResolveDiscrepancy},
 
 		// Healthcheck
 		{"healthcheck", "GET", "/api/billing/healthcheck", a.healthcheck},
@@ -39,6 +42,12 @@ func (a *API) RegisterRoutes(r *mux.Router) {
 
 		// Usage
 		{"api_billing_id_usage", "GET", "/api/billing/{id}/usage", a.GetUsage},
+
+		// Forecast and budget
+		{"api_billing_id_forecast", "GET", "/api/billing/{id}/forecast", a.GetForecast},
+		{"api_billing_id_budget", "GET", "/api/billing/{id}/budget", a.GetBudget},
+		{"api_billing_id_budget", "PUT", "/api/billing/{id}/budget", a.SetBudget},
+		{"api_billing_id_budget", "DELETE", "/api/billing/{id}/budget", a.DeleteBudget},
 	} {
 		r.Handle(route.path, a.corsHandler(route.handler)).Methods(route.method).Name(route.name)
 	}
@@ -47,7 +56,7 @@ func (a *API) RegisterRoutes(r *mux.Router) {
 func (a *API) corsHandler(h http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", a.CORSAllowOrigin)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type")
 
 		if r.Method == "OPTIONS" {
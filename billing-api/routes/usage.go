@@ -2,10 +2,13 @@ package routes
 
 import (
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/gorilla/mux"
 
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/common/billing/catalog"
 	"github.com/weaveworks/service/common/constants/billing"
 	"github.com/weaveworks/service/common/render"
 	"github.com/weaveworks/service/users"
@@ -18,8 +21,33 @@ type Usage struct {
 	NodeSeconds int64  `json:"nodeSeconds"`
 }
 
+// UsageBreakdown is an organization's usage of each amount type over a time
+// range. It is returned instead of Usage if requested with `version=2`.
+type UsageBreakdown struct {
+	Start string        `json:"start"`
+	End   string        `json:"end"`
+	Types []UsageByType `json:"types"`
+}
+
+// UsageByType is the usage of an amount type, in total and per hour.
+type UsageByType struct {
+	AmountType string `json:"amountType"`
+	// Product is the product the amount type meters, if it's billed.
+	Product string        `json:"product,omitempty"`
+	Unit    string        `json:"unit,omitempty"`
+	Total   int64         `json:"total"`
+	Buckets []UsageBucket `json:"buckets"`
+}
+
+// UsageBucket is an hour's usage.
+type UsageBucket struct {
+	Start string `json:"start"`
+	Value int64  `json:"value"`
+}
+
 // GetUsage returns an organization's usage. It supports form values
-// `start` and `end` for time range.
+// `start` and `end` for time range, and `version=2` to break usage down
+// per amount type rather than only return node-seconds.
 func (a *API) GetUsage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	through := time.Now().UTC()
@@ -55,6 +83,11 @@ func (a *API) GetUsage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.FormValue("version") == "2" {
+		render.JSON(w, http.StatusOK, usageBreakdown(aggs, from, through))
+		return
+	}
+
 	var usages []Usage
 	for _, agg := range aggs {
 		if agg.AmountType != billing.UsageNodeSeconds {
@@ -68,6 +101,42 @@ func (a *API) GetUsage(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, http.StatusOK, usages)
 }
 
+func usageBreakdown(aggs []db.Aggregate, from, through time.Time) UsageBreakdown {
+	byType := map[string]*UsageByType{}
+	for _, agg := range aggs {
+		u, ok := byType[agg.AmountType]
+		if !ok {
+			u = &UsageByType{AmountType: agg.AmountType, Buckets: []UsageBucket{}}
+			if product, ok := catalog.Default.Product(agg.AmountType); ok {
+				u.Product = product.Name
+				u.Unit = product.Unit
+			}
+			byType[agg.AmountType] = u
+		}
+		u.Total += agg.AmountValue
+		start := render.Time(agg.BucketStart)
+		// Aggregates come ordered by bucket, and there may be more than one per hour.
+		if n := len(u.Buckets); n > 0 && u.Buckets[n-1].Start == start {
+			u.Buckets[n-1].Value += agg.AmountValue
+			continue
+		}
+		u.Buckets = append(u.Buckets, UsageBucket{Start: start, Value: agg.AmountValue})
+	}
+
+	breakdown := UsageBreakdown{
+		Start: render.Time(from),
+		End:   render.Time(through),
+		Types: []UsageByType{},
+	}
+	for _, u := range byType {
+		breakdown.Types = append(breakdown.Types, *u)
+	}
+	sort.Slice(breakdown.Types, func(i, j int) bool {
+		return breakdown.Types[i].AmountType < breakdown.Types[j].AmountType
+	})
+	return breakdown
+}
+
 func parseTime(in string) (t time.Time, err error) {
 	return time.Parse(time.RFC3339Nano, in)
 }
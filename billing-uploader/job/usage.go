@@ -3,6 +3,7 @@ package job
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/weaveworks/common/user"
 	"github.com/weaveworks/service/billing-api/db"
 	"github.com/weaveworks/service/billing-uploader/job/usage"
+	"github.com/weaveworks/service/common/billing/catalog"
 	timeutil "github.com/weaveworks/service/common/time"
 	"github.com/weaveworks/service/users"
 )
@@ -127,11 +129,19 @@ func (j *UsageUpload) Do(now time.Time) error {
 		earliest := through.Add(-7 * 24 * time.Hour)
 
 		// Reset previous report
-		j.uploader.Reset()
+		if err := j.uploader.Reset(ctx); err != nil {
+			logger.Errorf("Failed resetting uploader: %v", err)
+			return err
+		}
+		var teamIDs []string
+		if lister, ok := j.uploader.(usage.TeamLister); ok {
+			teamIDs = lister.TeamIDs()
+		}
 
 		// Look up the billing-enabled instances where the trial has expired.
 		resp, err := j.users.GetBillableOrganizations(ctx, &users.GetBillableOrganizationsRequest{
-			Now: through,
+			Now:     through,
+			TeamIDs: teamIDs,
 		})
 		if err != nil {
 			logger.Errorf("Failed getting organizations: %v", err)
@@ -179,9 +189,13 @@ func (j *UsageUpload) Do(now time.Time) error {
 				continue
 			}
 
-			if err := j.uploader.Add(ctx, org, orgFrom, through, aggs); err != nil {
+			aggs, err = j.uploader.Add(ctx, org, orgFrom, through, aggs)
+			if err != nil {
 				return errors.Wrapf(err, "cannot add aggregates to %v", org.ExternalID)
 			}
+			if len(aggs) == 0 {
+				continue
+			}
 
 			stats.record(aggs)
 		}
@@ -210,7 +224,8 @@ func (j *UsageUpload) upload(ctx context.Context, aggregateIDs []int, uploadName
 	if err != nil {
 		return err
 	}
-	if err = j.uploader.Upload(ctx, uploadName); err != nil {
+	importID, err := j.uploader.Upload(ctx, uploadName)
+	if err != nil {
 		logger.Warnf("Error uploading usage: %+v. removing usage record %d", err, uploadID)
 		// Delete upload record because we failed, so our next run will picks these aggregates up again.
 		if e := j.db.DeleteUsageUpload(ctx, j.uploader.ID(), uploadID); e != nil {
@@ -220,6 +235,69 @@ func (j *UsageUpload) upload(ctx context.Context, aggregateIDs []int, uploadName
 		}
 		return err
 	}
+	// The upload went through, so only its reconciliation is affected if we
+	// can't record what it was sent as.
+	if err := j.db.SetUsageUploadImport(ctx, uploadID, uploadName, importID); err != nil {
+		logger.Warnf("Cannot record import %q of usage upload %d: %v", importID, uploadID, err)
+	}
+	if p, ok := j.uploader.(usage.PartialUploader); ok && len(p.Failures()) > 0 {
+		if err := j.recordFailures(ctx, uploadID, p.Failures()); err != nil {
+			logger.Errorf("Cannot record the failures of usage upload %d: %v", uploadID, err)
+		}
+	}
 
 	return nil
 }
+
+// recordFailures records the usage an upload couldn't send as discrepancies,
+// one per instance and product, so that admins can requeue it once they fixed
+// what made it fail. It isn't retried automatically, as it may have been sent
+// after all. Requeuing is only offered if none of the instance's usage of the
+// product was sent.
+func (j *UsageUpload) recordFailures(ctx context.Context, uploadID int64, failures []usage.Failure) error {
+	uploaded, err := j.db.GetAggregatesUploaded(ctx, uploadID)
+	if err != nil {
+		return err
+	}
+	type key struct{ instanceID, product string }
+	failed := map[key]error{}
+	amountTypes := map[key][]string{}
+	missing := map[key]int64{}
+	for _, f := range failures {
+		for _, agg := range f.Aggregates {
+			if product, ok := catalog.Default.Product(agg.AmountType); ok {
+				k := key{agg.InstanceID, product.Name}
+				failed[k] = f.Err
+				amountTypes[k] = product.AmountTypes
+				missing[k] += agg.AmountValue
+			}
+		}
+	}
+	expected := map[key]int64{}
+	for _, agg := range uploaded {
+		if product, ok := catalog.Default.Product(agg.AmountType); ok {
+			expected[key{agg.InstanceID, product.Name}] += agg.AmountValue
+		}
+	}
+
+	var discrepancies []db.Discrepancy
+	for k, err := range failed {
+		discrepancies = append(discrepancies, db.Discrepancy{
+			UploadID:    uploadID,
+			Uploader:    j.uploader.ID(),
+			InstanceID:  k.instanceID,
+			Product:     k.product,
+			AmountTypes: amountTypes[k],
+			Expected:    expected[k],
+			Accepted:    expected[k] - missing[k],
+			Reason:      fmt.Sprintf("upload failed: %v", err),
+		})
+	}
+	sort.Slice(discrepancies, func(i, j int) bool {
+		if discrepancies[i].InstanceID != discrepancies[j].InstanceID {
+			return discrepancies[i].InstanceID < discrepancies[j].InstanceID
+		}
+		return discrepancies[i].Product < discrepancies[j].Product
+	})
+	return j.db.InsertDiscrepancies(ctx, discrepancies)
+}
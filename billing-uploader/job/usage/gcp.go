@@ -11,7 +11,8 @@ import (
 	"google.golang.org/api/servicecontrol/v1"
 
 	"github.com/weaveworks/service/billing-api/db"
-	"github.com/weaveworks/service/common/constants/billing"
+	"github.com/weaveworks/service/common/billing/catalog"
+	"github.com/weaveworks/service/common/billing/provider"
 	"github.com/weaveworks/service/common/gcp/control"
 	"github.com/weaveworks/service/common/gcp/procurement"
 	"github.com/weaveworks/service/users"
@@ -34,14 +35,17 @@ func (g *GCP) ID() string {
 }
 
 // Reset removes all current operations.
-func (g *GCP) Reset() {
+func (g *GCP) Reset(ctx context.Context) error {
 	g.ops = nil
+	return nil
 }
 
-// Add collects node-seconds aggregates.
-func (g *GCP) Add(ctx context.Context, org users.Organization, from, through time.Time, aggs []db.Aggregate) error {
+// Add collects aggregates of all products billed through GCP, as the metrics
+// of the organization's subscription level.
+func (g *GCP) Add(ctx context.Context, org users.Organization, from, through time.Time, aggs []db.Aggregate) ([]db.Aggregate, error) {
 	for _, agg := range aggs {
-		if agg.AmountType != billing.UsageNodeSeconds {
+		metric, ok := catalog.Default.Charge(provider.GCP, agg.AmountType)
+		if !ok {
 			continue
 		}
 		value := agg.AmountValue
@@ -52,21 +56,21 @@ func (g *GCP) Add(ctx context.Context, org users.Organization, from, through tim
 			StartTime:     agg.BucketStart.Format(time.RFC3339Nano),
 			EndTime:       agg.BucketStart.Add(1 * time.Hour).Format(time.RFC3339Nano), // bucket size is always 1h
 			MetricValueSets: []*servicecontrol.MetricValueSet{{
-				MetricName: fmt.Sprintf("google.weave.works/%s_nodes", org.GCP.SubscriptionLevel),
+				MetricName: fmt.Sprintf(metric, org.GCP.SubscriptionLevel),
 				MetricValues: []*servicecontrol.MetricValue{{
 					Int64Value: &value,
 				}},
 			}},
 		})
 	}
-	return nil
+	return aggs, nil
 }
 
 // Upload sends the usage to the Service Control API as metrics.
-func (g *GCP) Upload(ctx context.Context, id string) error {
+func (g *GCP) Upload(ctx context.Context, id string) (string, error) {
 	bs, _ := json.Marshal(g.ops)
 	log.Infof("Uploading GCP usage: %s", bs)
-	return g.client.Report(ctx, g.ops)
+	return "", g.client.Report(ctx, g.ops)
 }
 
 // IsSupported only picks organizations that have an activated GCP account
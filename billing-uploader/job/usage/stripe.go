@@ -0,0 +1,179 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/common/billing/provider"
+	"github.com/weaveworks/service/common/constants/billing"
+	"github.com/weaveworks/service/common/stripe"
+	"github.com/weaveworks/service/users"
+)
+
+// Stripe reports node-seconds as usage records of the subscriptions of teams
+// billed through Stripe. It implements Uploader, PartialUploader and
+// TeamLister.
+type Stripe struct {
+	client stripe.Client
+	db     db.DB
+
+	customers map[string]string                 // team ID -> Stripe customer ID
+	items     map[string]stripeSubscriptionItem // Stripe customer ID -> subscription item
+	records   []stripeUsageRecord
+	failures  []Failure
+}
+
+type stripeSubscriptionItem struct {
+	id    string
+	start time.Time // of the subscription
+}
+
+type stripeUsageRecord struct {
+	aggregate          db.Aggregate
+	subscriptionItemID string
+}
+
+// NewStripe instantiates a Stripe usage uploader.
+func NewStripe(client stripe.Client, db db.DB) *Stripe {
+	return &Stripe{client: client, db: db}
+}
+
+// ID returns an unique uploader id.
+func (s *Stripe) ID() string {
+	return "stripe"
+}
+
+// Reset removes all current usage records, and reloads the teams billed
+// through Stripe: those whose billing account's provider is Stripe, and who
+// became Stripe customers.
+func (s *Stripe) Reset(ctx context.Context) error {
+	s.records = nil
+	s.failures = nil
+	s.items = map[string]stripeSubscriptionItem{}
+	providers, err := s.db.GetBillingAccountProviders(ctx)
+	if err != nil {
+		return errors.Wrap(err, "cannot get billing account providers")
+	}
+	customers, err := s.db.GetStripeCustomers(ctx)
+	if err != nil {
+		return errors.Wrap(err, "cannot get Stripe customers")
+	}
+	s.customers = map[string]string{}
+	for _, c := range customers {
+		if providers[c.TeamID] == provider.Stripe {
+			s.customers[c.TeamID] = c.CustomerID
+		}
+	}
+	return nil
+}
+
+// TeamIDs returns the teams billed through Stripe.
+func (s *Stripe) TeamIDs() []string {
+	teamIDs := make([]string, 0, len(s.customers))
+	for teamID := range s.customers {
+		teamIDs = append(teamIDs, teamID)
+	}
+	return teamIDs
+}
+
+// Add collects node-seconds aggregates since the team's subscription
+// started, as usage of it: Stripe customers aren't billed for what they used
+// before subscribing. Teams whose subscription can't be found are skipped, so
+// that they don't hold up the others.
+func (s *Stripe) Add(ctx context.Context, org users.Organization, from, through time.Time, aggs []db.Aggregate) ([]db.Aggregate, error) {
+	item, err := s.subscriptionItem(ctx, s.customers[org.TeamID])
+	if err != nil {
+		log.Warnf("Skipping Stripe usage of team %s: %v", org.TeamID, err)
+		return nil, nil
+	}
+	var added []db.Aggregate
+	for _, agg := range aggs {
+		if agg.AmountType != billing.UsageNodeSeconds || agg.BucketStart.Before(item.start) {
+			continue
+		}
+		s.records = append(s.records, stripeUsageRecord{aggregate: agg, subscriptionItemID: item.id})
+		added = append(added, agg)
+	}
+	return added, nil
+}
+
+// subscriptionItem finds the item of the customer's active subscription which
+// bills node-seconds.
+func (s *Stripe) subscriptionItem(ctx context.Context, customerID string) (stripeSubscriptionItem, error) {
+	if item, ok := s.items[customerID]; ok {
+		return item, nil
+	}
+	subscriptions, err := s.client.GetSubscriptions(ctx, customerID)
+	if err != nil {
+		return stripeSubscriptionItem{}, errors.Wrapf(err, "cannot get subscriptions of Stripe customer %s", customerID)
+	}
+	priceID := s.client.GetConfig().PriceID
+	for _, sub := range subscriptions {
+		if !sub.IsActive() {
+			continue
+		}
+		if item := sub.Item(priceID); item != nil {
+			s.items[customerID] = stripeSubscriptionItem{id: item.ID, start: time.Unix(sub.StartDate, 0).UTC()}
+			return s.items[customerID], nil
+		}
+	}
+	return stripeSubscriptionItem{}, fmt.Errorf("Stripe customer %s has no active subscription to price %s", customerID, priceID)
+}
+
+// Upload sends the usage records to Stripe. Each aggregate is sent with its
+// own idempotency key, so that retrying after a failure doesn't count usage
+// twice. Once a record of a subscription fails, its other records aren't
+// sent, and they are all reported as Failures; other subscriptions' records
+// still are. Only if no record could be sent does Upload fail, so that the
+// whole upload is retried.
+func (s *Stripe) Upload(ctx context.Context, id string) (string, error) {
+	log.Infof("Uploading %d Stripe usage records", len(s.records))
+	s.failures = nil
+	failed := map[string]int{} // subscription item ID -> index in s.failures
+	sent := 0
+	for _, r := range s.records {
+		if i, ok := failed[r.subscriptionItemID]; ok {
+			s.failures[i].Aggregates = append(s.failures[i].Aggregates, r.aggregate)
+			continue
+		}
+		key := fmt.Sprintf("aggregate-%d", r.aggregate.ID)
+		if _, err := s.client.CreateUsageRecord(ctx, r.subscriptionItemID, r.aggregate.AmountValue, r.aggregate.BucketStart, key); err != nil {
+			failed[r.subscriptionItemID] = len(s.failures)
+			s.failures = append(s.failures, Failure{
+				Aggregates: []db.Aggregate{r.aggregate},
+				Err:        errors.Wrapf(err, "cannot create usage record for aggregate %d", r.aggregate.ID),
+			})
+			continue
+		}
+		sent++
+	}
+	if sent == 0 && len(s.failures) > 0 {
+		err := s.failures[0].Err
+		s.failures = nil
+		return "", err
+	}
+	return "", nil
+}
+
+// Failures returns the usage records of the subscriptions which the last
+// Upload couldn't send.
+func (s *Stripe) Failures() []Failure {
+	return s.failures
+}
+
+// IsSupported only picks organizations of teams billed through Stripe.
+func (s *Stripe) IsSupported(org users.Organization) bool {
+	_, ok := s.customers[org.TeamID]
+	return org.TeamID != "" && ok
+}
+
+// ThroughTime truncates to the hour, as Stripe bills usage records as they
+// come, like GCP.
+func (s *Stripe) ThroughTime(now time.Time) time.Time {
+	return now.Truncate(1 * time.Hour)
+}
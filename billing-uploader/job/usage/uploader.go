@@ -12,17 +12,40 @@ import (
 type Uploader interface {
 	// ID is an unique name to represent this uploader.
 	// IMPORTANT: when implementing the Uploader interface, values returned by ID() need to be added as
-	// valid values in the DB's enum uploader_type. See also: 005 and 006 in billing-api/db/migrations/
+	// valid values in the DB's enum uploader_type. See also: 005, 006 and 014 in billing-api/db/migrations/
 	ID() string
 	// Add records aggregates to be uploaded later. `from` and `through` are the boundaries of the time
-	// period used when looking for aggregates.
-	Add(ctx context.Context, org users.Organization, from, through time.Time, aggs []db.Aggregate) error
-	// Upload sends recorded aggregates.
-	Upload(ctx context.Context, id string) error
-	// Reset creates a fresh report.
-	Reset()
+	// period used when looking for aggregates. It returns the aggregates it recorded: the others aren't
+	// part of the upload, and are looked at again by the next one.
+	Add(ctx context.Context, org users.Organization, from, through time.Time, aggs []db.Aggregate) ([]db.Aggregate, error)
+	// Upload sends recorded aggregates. It returns the id of the upload at the
+	// usage consumer, if it has one.
+	Upload(ctx context.Context, id string) (string, error)
+	// Reset creates a fresh report, and reloads whatever the uploader needs to
+	// know which organizations it handles.
+	Reset(ctx context.Context) error
 	// IsSupported returns whether this uploader handles the given organization.
 	IsSupported(org users.Organization) bool
 	// ThroughTime returns the upper bound we want to upload usage until.
 	ThroughTime(now time.Time) time.Time
 }
+
+// Failure is usage an uploader couldn't send.
+type Failure struct {
+	Aggregates []db.Aggregate
+	Err        error
+}
+
+// PartialUploader is implemented by uploaders which send usage in several
+// requests, so that part of an upload can fail while the rest went through.
+type PartialUploader interface {
+	// Failures returns the usage the last successful Upload couldn't send.
+	Failures() []Failure
+}
+
+// TeamLister is implemented by uploaders which bill teams as a whole, rather
+// than organizations with a Zuora account or a GCP subscription.
+type TeamLister interface {
+	// TeamIDs returns the teams billed by the uploader, as of its last Reset.
+	TeamIDs() []string
+}
@@ -15,16 +15,19 @@ import (
 // Zuora sends usage data to Zuora. It implements Uploader.
 type Zuora struct {
 	cl zuora.Client
+	db db.DB
 	r  *zuora.Report
+
+	providers map[string]string // team ID -> billing account provider, if not the default
 }
 
 // NewZuora creates a Zuora instance.
-func NewZuora(client zuora.Client) *Zuora {
-	z := &Zuora{
+func NewZuora(client zuora.Client, db db.DB) *Zuora {
+	return &Zuora{
 		cl: client,
+		db: db,
+		r:  zuora.NewReport(client.GetConfig()),
 	}
-	z.Reset()
-	return z
 }
 
 // ID identifies this uploader.
@@ -32,35 +35,44 @@ func (z *Zuora) ID() string {
 	return "zuora"
 }
 
-// Reset replaces the current report with an empty one
-func (z *Zuora) Reset() {
+// Reset replaces the current report with an empty one, and reloads which
+// teams are billed through another provider.
+func (z *Zuora) Reset(ctx context.Context) error {
 	z.r = zuora.NewReport(z.cl.GetConfig())
+	providers, err := z.db.GetBillingAccountProviders(ctx)
+	if err != nil {
+		return errors.Wrap(err, "cannot get billing account providers")
+	}
+	z.providers = providers
+	return nil
 }
 
-// Add collects usage by grouping aggregates in billing periods.
-func (z *Zuora) Add(ctx context.Context, org users.Organization, from, through time.Time, aggs []db.Aggregate) error {
+// Add collects usage by grouping aggregates in billing periods. Only usage the
+// account's subscription has a charge for is reported, and so is part of the
+// upload.
+func (z *Zuora) Add(ctx context.Context, org users.Organization, from, through time.Time, aggs []db.Aggregate) ([]db.Aggregate, error) {
 	account, err := z.cl.GetAccount(ctx, org.ZuoraAccountNumber)
 	if err != nil {
-		return errors.Wrapf(err, "cannot get Zuora account")
+		return nil, errors.Wrapf(err, "cannot get Zuora account")
 	}
 	if account.PaymentProviderID == "" {
-		return fmt.Errorf("account has no Zuora payment provider")
+		return nil, fmt.Errorf("account has no Zuora payment provider")
 	}
 
 	subscriptionNumber := account.Subscription.SubscriptionNumber
-	chargeNumber := account.Subscription.ChargeNumber
+	charges := account.Subscription.ChargeNumbers()
 
-	aggs, err = zuora.FilterAggregatesForSubscription(ctx, z.cl, aggs, account)
+	filtered, err := zuora.FilterAggregatesForSubscription(ctx, z.cl, aggs, account)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	orgReport, err := zuora.ReportFromAggregates(z.cl.GetConfig(), aggs, account.PaymentProviderID, minBucketStart(aggs), through, subscriptionNumber, chargeNumber, zuora.BillCycleDay)
+	orgReport, err := zuora.ReportFromAggregates(z.cl.GetConfig(), filtered, account.PaymentProviderID, minBucketStart(filtered), through, subscriptionNumber, charges, zuora.BillCycleDay)
 	if err != nil {
-		return errors.Wrap(err, "cannot create report")
+		return nil, errors.Wrap(err, "cannot create report")
 	}
 	z.r = z.r.ConcatEntries(orgReport)
-	return nil
+	return filtered, nil
 }
 
 func minBucketStart(aggs []db.Aggregate) time.Time {
@@ -73,21 +85,24 @@ func minBucketStart(aggs []db.Aggregate) time.Time {
 	return l
 }
 
-// Upload sends usage to Zuora.
-func (z *Zuora) Upload(ctx context.Context, id string) error {
+// Upload sends usage to Zuora, and returns the id of the usage import.
+func (z *Zuora) Upload(ctx context.Context, id string) (string, error) {
 	reader, err := z.r.ToZuoraFormat()
 	if err != nil {
-		return err
+		return "", err
 	}
-	if _, err = z.cl.UploadUsage(ctx, reader, id); err != nil {
-		return err
+	importID, err := z.cl.UploadUsage(ctx, reader, id)
+	if err != nil {
+		return "", err
 	}
-	return nil
+	return string(importID), nil
 }
 
-// IsSupported returns true for organizations that have a Zuora account number.
+// IsSupported returns true for organizations that have a Zuora account
+// number, unless their team's billing account is billed through another
+// provider, e.g. Stripe.
 func (z *Zuora) IsSupported(org users.Organization) bool {
-	return org.ZuoraAccountNumber != ""
+	return org.ZuoraAccountNumber != "" && z.providers[org.TeamID] == ""
 }
 
 // ThroughTime returns time of the previous midnight.
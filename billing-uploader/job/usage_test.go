@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"io"
 	"io/ioutil"
+	"sort"
 	"strconv"
 	"testing"
 	"time"
@@ -21,6 +22,9 @@ import (
 	"github.com/weaveworks/service/billing-api/db/dbtest"
 	"github.com/weaveworks/service/billing-uploader/job"
 	"github.com/weaveworks/service/billing-uploader/job/usage"
+	"github.com/weaveworks/service/common/billing/provider"
+	"github.com/weaveworks/service/common/stripe"
+	"github.com/weaveworks/service/common/stripe/mockstripe"
 	"github.com/weaveworks/service/common/zuora"
 	"github.com/weaveworks/service/common/zuora/mockzuora"
 	"github.com/weaveworks/service/users"
@@ -91,6 +95,12 @@ var (
 			ExternalID:         "partial-trial_expires_at",
 			TrialExpiresAt:     expires,
 		},
+		{
+			ID:                 "102",
+			ZuoraAccountNumber: "Wskip-stripe",
+			ExternalID:         "skip-stripe",
+			TeamID:             "team-stripe",
+		},
 		// GCP accounts
 		{
 			ID:         "200",
@@ -157,6 +167,12 @@ var (
 			AmountType:  "node-seconds",
 			AmountValue: 4,
 		},
+		{ // skip: billed through Stripe
+			BucketStart: start.Add(1 * time.Hour),
+			InstanceID:  "102",
+			AmountType:  "node-seconds",
+			AmountValue: 5,
+		},
 
 		// GCP
 		{ // pick
@@ -201,9 +217,17 @@ func TestJobUpload_Do(t *testing.T) {
 	// prepare data
 	err := d.InsertAggregates(ctx, aggregates)
 	assert.NoError(t, err)
+	_, err = d.SetTeamBillingAccountProvider(ctx, "team-stripe", provider.Stripe)
+	assert.NoError(t, err)
 
 	{ // zuora upload
-		j := job.NewUsageUpload(d, u, usage.NewZuora(z), instrument.NewJobCollector("foo"))
+		// The subscription has no charge for samples, so they aren't
+		// reported, nor part of the upload.
+		err = d.InsertAggregates(ctx, []db.Aggregate{
+			{BucketStart: start, InstanceID: "100", AmountType: "samples", AmountValue: 100},
+		})
+		assert.NoError(t, err)
+		j := job.NewUsageUpload(d, u, usage.NewZuora(z, d), instrument.NewJobCollector("foo"))
 		err = j.Do(now)
 		assert.NoError(t, err)
 		bcsv, err := ioutil.ReadAll(z.uploadUsage)
@@ -292,7 +316,7 @@ func TestJobUpload_Do(t *testing.T) {
 		// Add one more aggregate and make sure this second run has successfully
 		// reset the previous report
 		err = d.InsertAggregates(ctx, []db.Aggregate{
-			{ // ID==7
+			{ // ID==8
 				BucketStart: start.Add(2 * time.Hour),
 				InstanceID:  "200",
 				AmountType:  "node-seconds",
@@ -313,6 +337,171 @@ func TestJobUpload_Do(t *testing.T) {
 	}
 }
 
+func TestJobUpload_Do_stripe(t *testing.T) {
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := mockstripe.New()
+	defer server.Close()
+	client := stripe.New(server.Config("price_nodes"), nil)
+	subscribe := func(teamID string) string {
+		customer, err := client.CreateCustomer(ctx, stripe.CustomerParams{Name: teamID})
+		require.NoError(t, err)
+		require.NoError(t, d.SetStripeCustomer(ctx, teamID, customer.ID))
+		_, err = d.SetTeamBillingAccountProvider(ctx, teamID, provider.Stripe)
+		require.NoError(t, err)
+		sub, err := client.CreateSubscription(ctx, customer.ID, time.Time{})
+		require.NoError(t, err)
+		server.SetSubscriptionStart(sub.ID, start)
+		return sub.Item("price_nodes").ID
+	}
+	itemID := subscribe("team-1")
+	rejectedItemID := subscribe("team-5")
+	server.RejectUsageRecords(rejectedItemID)
+	// Teams which became Stripe customers, but were moved back to another
+	// provider, aren't billed through Stripe.
+	require.NoError(t, d.SetStripeCustomer(ctx, "team-3", "cus_other"))
+	// Teams which haven't subscribed yet are skipped.
+	customer, err := client.CreateCustomer(ctx, stripe.CustomerParams{Name: "team-4"})
+	require.NoError(t, err)
+	require.NoError(t, d.SetStripeCustomer(ctx, "team-4", customer.ID))
+	_, err = d.SetTeamBillingAccountProvider(ctx, "team-4", provider.Stripe)
+	require.NoError(t, err)
+
+	u := mock_users.NewMockUsersClient(ctrl)
+	u.EXPECT().
+		GetBillableOrganizations(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req *users.GetBillableOrganizationsRequest, _ ...interface{}) (*users.GetBillableOrganizationsResponse, error) {
+			teamIDs := append([]string{}, req.TeamIDs...)
+			sort.Strings(teamIDs)
+			assert.Equal(t, []string{"team-1", "team-4", "team-5"}, teamIDs)
+			return &users.GetBillableOrganizationsResponse{
+				Organizations: []users.Organization{
+					{ID: "300", ExternalID: "stripe-team", TeamID: "team-1"},
+					{ID: "301", ExternalID: "skip-other-team", TeamID: "team-2", ZuoraAccountNumber: "Wother"},
+					{ID: "304", ExternalID: "skip-unsubscribed-team", TeamID: "team-4"},
+					{ID: "305", ExternalID: "rejected-team", TeamID: "team-5"},
+				},
+			}, nil
+		}).
+		Times(2)
+	err = d.InsertAggregates(ctx, []db.Aggregate{
+		{BucketStart: start.Add(-1 * time.Hour), InstanceID: "300", AmountType: "node-seconds", AmountValue: 1800}, // before subscribing
+		{BucketStart: start, InstanceID: "300", AmountType: "node-seconds", AmountValue: 3600},
+		{BucketStart: start.Add(1 * time.Hour), InstanceID: "300", AmountType: "node-seconds", AmountValue: 7200},
+		{BucketStart: start, InstanceID: "300", AmountType: "container-seconds", AmountValue: 1},
+		{BucketStart: start, InstanceID: "301", AmountType: "node-seconds", AmountValue: 5},
+		{BucketStart: start, InstanceID: "304", AmountType: "node-seconds", AmountValue: 6},
+		{BucketStart: start, InstanceID: "305", AmountType: "node-seconds", AmountValue: 7},
+		{BucketStart: start.Add(1 * time.Hour), InstanceID: "305", AmountType: "node-seconds", AmountValue: 8},
+	})
+	require.NoError(t, err)
+
+	j := job.NewUsageUpload(d, u, usage.NewStripe(client, d), instrument.NewJobCollector("foo"))
+	require.NoError(t, j.Do(now))
+	records := server.UsageRecords(itemID)
+	require.Len(t, records, 2)
+	assert.Equal(t, int64(3600), records[0].Quantity)
+	assert.Equal(t, start.Unix(), records[0].Timestamp)
+	assert.Equal(t, int64(7200), records[1].Quantity)
+
+	// Only what was reported is part of the upload...
+	upload, err := d.GetLatestUsageUpload(ctx, "stripe")
+	require.NoError(t, err)
+	aggs, err := d.GetAggregatesUploaded(ctx, upload.ID)
+	require.NoError(t, err)
+	uploaded := map[string]int64{}
+	for _, agg := range aggs {
+		uploaded[agg.InstanceID] += agg.AmountValue
+	}
+	assert.Equal(t, map[string]int64{"300": 3600 + 7200, "305": 7 + 8}, uploaded)
+
+	// ...and what couldn't be is a discrepancy, which admins may requeue.
+	discrepancies, err := d.GetDiscrepancies(ctx)
+	require.NoError(t, err)
+	require.Len(t, discrepancies, 1)
+	assert.Equal(t, upload.ID, discrepancies[0].UploadID)
+	assert.Equal(t, "stripe", discrepancies[0].Uploader)
+	assert.Equal(t, "305", discrepancies[0].InstanceID)
+	assert.Equal(t, "nodes", discrepancies[0].Product)
+	assert.Equal(t, int64(15), discrepancies[0].Expected)
+	assert.Equal(t, int64(0), discrepancies[0].Accepted)
+
+	// Uploaded aggregates aren't reported again.
+	require.NoError(t, j.Do(now))
+	assert.Len(t, server.UsageRecords(itemID), 2)
+}
+
+func TestJobUpload_Do_stripeError(t *testing.T) {
+	d := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, d)
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := mockstripe.New()
+	defer server.Close()
+	client := stripe.New(server.Config("price_nodes"), nil)
+	customer, err := client.CreateCustomer(ctx, stripe.CustomerParams{Name: "team-1"})
+	require.NoError(t, err)
+	require.NoError(t, d.SetStripeCustomer(ctx, "team-1", customer.ID))
+	_, err = d.SetTeamBillingAccountProvider(ctx, "team-1", provider.Stripe)
+	require.NoError(t, err)
+	sub, err := client.CreateSubscription(ctx, customer.ID, time.Time{})
+	require.NoError(t, err)
+	server.SetSubscriptionStart(sub.ID, start)
+	server.RejectUsageRecords(sub.Item("price_nodes").ID)
+
+	u := mock_users.NewMockUsersClient(ctrl)
+	u.EXPECT().
+		GetBillableOrganizations(gomock.Any(), gomock.Any()).
+		Return(&users.GetBillableOrganizationsResponse{
+			Organizations: []users.Organization{{ID: "300", ExternalID: "stripe-team", TeamID: "team-1"}},
+		}, nil)
+	require.NoError(t, d.InsertAggregates(ctx, []db.Aggregate{
+		{BucketStart: start, InstanceID: "300", AmountType: "node-seconds", AmountValue: 3600},
+	}))
+
+	// Nothing could be sent, so the whole upload is retried next time.
+	j := job.NewUsageUpload(d, u, usage.NewStripe(client, d), instrument.NewJobCollector("foo"))
+	require.Error(t, j.Do(now))
+	aggs, err := d.GetAggregatesToUpload(ctx, "300", start, now)
+	require.NoError(t, err)
+	assert.Len(t, aggs, 1)
+	discrepancies, err := d.GetDiscrepancies(ctx)
+	require.NoError(t, err)
+	assert.Len(t, discrepancies, 0)
+}
+
+func TestGCP_Add_products(t *testing.T) {
+	cl := &stubControlClient{}
+	gcp := usage.NewGCP(cl)
+	require.NoError(t, gcp.Reset(context.Background()))
+	org := users.Organization{ID: "200", GCP: &users.GoogleCloudPlatform{SubscriptionLevel: "standard"}}
+	_, err := gcp.Add(context.Background(), org, start, now, []db.Aggregate{
+		{ID: 1, BucketStart: start, AmountType: "node-seconds", AmountValue: 10},
+		{ID: 2, BucketStart: start, AmountType: "samples", AmountValue: 20},         // not billed through GCP
+		{ID: 3, BucketStart: start, AmountType: "flux-autorelease", AmountValue: 2}, // not billed through GCP
+		{ID: 4, BucketStart: start, AmountType: "flux-lock", AmountValue: 1},        // not billed
+	})
+	require.NoError(t, err)
+	_, err = gcp.Upload(context.Background(), "foo")
+	require.NoError(t, err)
+
+	metrics := map[string]int64{}
+	for _, op := range cl.operations {
+		metrics[op.MetricValueSets[0].MetricName] = *op.MetricValueSets[0].MetricValues[0].Int64Value
+	}
+	assert.Equal(t, map[string]int64{
+		"google.weave.works/standard_nodes": 10,
+	}, metrics)
+}
+
 func TestJobUpload_Do_zuoraError(t *testing.T) {
 	d := dbtest.Setup(t)
 	defer dbtest.Cleanup(t, d)
@@ -341,7 +530,7 @@ func TestJobUpload_Do_zuoraError(t *testing.T) {
 	err := d.InsertAggregates(ctx, aggregates)
 	assert.NoError(t, err)
 
-	j := job.NewUsageUpload(d, u, usage.NewZuora(z), instrument.NewJobCollector("foo"))
+	j := job.NewUsageUpload(d, u, usage.NewZuora(z, d), instrument.NewJobCollector("foo"))
 	err = j.Do(now)
 	assert.Error(t, err)
 
@@ -411,7 +600,7 @@ func TestJobUpload_Do_outOfOrder(t *testing.T) {
 	err := d.InsertAggregates(ctx, aggregates)
 	assert.NoError(t, err)
 
-	j := job.NewUsageUpload(d, u, usage.NewZuora(z), instrument.NewJobCollector("foo"))
+	j := job.NewUsageUpload(d, u, usage.NewZuora(z, d), instrument.NewJobCollector("foo"))
 	err = j.Do(secondDayStart.Add(10 * time.Minute))
 	assert.NoError(t, err)
 
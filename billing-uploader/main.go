@@ -18,6 +18,7 @@ import (
 	"github.com/weaveworks/service/billing-uploader/job/usage"
 	"github.com/weaveworks/service/common/dbconfig"
 	"github.com/weaveworks/service/common/gcp/control"
+	"github.com/weaveworks/service/common/stripe"
 	"github.com/weaveworks/service/common/users"
 	"github.com/weaveworks/service/common/zuora"
 )
@@ -42,6 +43,12 @@ const index = `
 				<button type="submit">Zuora</button>
 				</form>
 			</li>
+			<li>
+				<form action="uploader/upload/stripe" method="post">
+				<input type="hidden" name="csrf_token" value="$__CSRF_TOKEN_PLACEHOLDER__">
+				<button type="submit">Stripe</button>
+				</form>
+			</li>
 		</ul>
 	</body>
 </html>
@@ -62,6 +69,10 @@ func main() {
 			// It is scheduled to go hourly at :15 because the aggregation of usage is scheduled at :10
 			"0 15 * * * *", // Seconds, Minutes, Hours, Day of month, Month, Day of week
 			"Cron spec for periodic execution of the GCP uploader job. Should be scheduled to run once an hour")
+		uploadStripeCronSpec = flag.String(
+			"upload-stripe-cron-spec",
+			"0 20 * * * *", // Seconds, Minutes, Hours, Day of month, Month, Day of week
+			"Cron spec for periodic execution of the Stripe uploader job. Should be scheduled to run once an hour")
 		invoiceCronSpec = flag.String(
 			"invoice-cron-spec",
 			"0 * * * * *", // Every minute
@@ -71,12 +82,14 @@ func main() {
 		usersConfig  users.Config
 		zuoraConfig  zuora.Config
 		gcpConfig    control.Config
+		stripeConfig stripe.Config
 	)
 	serverConfig.RegisterFlags(flag.CommandLine)
 	dbConfig.RegisterFlags(flag.CommandLine, "postgres://postgres@billing-db/billing?sslmode=disable", "Database to use.", "/migrations", "Migrations directory.")
 	usersConfig.RegisterFlags(flag.CommandLine)
 	zuoraConfig.RegisterFlags(flag.CommandLine)
 	gcpConfig.RegisterFlags(flag.CommandLine)
+	stripeConfig.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 	serverConfig.MetricsNamespace = "billing"
 
@@ -107,7 +120,7 @@ func main() {
 
 	// Zuora upload cron
 	zuora := zuora.New(zuoraConfig, nil)
-	zuoraCron, zuoraUpload := startCron(*uploadZuoraCronSpec, db, users, usage.NewZuora(zuora), jobCollector)
+	zuoraCron, zuoraUpload := startCron(*uploadZuoraCronSpec, db, users, usage.NewZuora(zuora, db), jobCollector)
 	defer zuoraCron.Stop()
 
 	// GCP upload cron
@@ -124,6 +137,16 @@ func main() {
 		log.Infof("GCP usage uploader is disabled")
 	}
 
+	// Stripe upload cron
+	var stripeUpload *job.UsageUpload
+	if stripeConfig.Enabled() {
+		var stripeCron *cron.Cron
+		stripeCron, stripeUpload = startCron(*uploadStripeCronSpec, db, users, usage.NewStripe(stripe.New(stripeConfig, nil), db), jobCollector)
+		defer stripeCron.Stop()
+	} else {
+		log.Infof("Stripe usage uploader is disabled")
+	}
+
 	invoiceCron := cron.New()
 	invoice := job.NewInvoiceUpload(db, users, zuora, jobCollector)
 	invoiceCron.AddJob(*invoiceCronSpec, invoice)
@@ -134,7 +157,7 @@ func main() {
 		w.Write([]byte(index))
 	})
 	server.HTTP.Path("/upload").Methods("POST").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		http.Error(w, "use /upload/gcp or /upload/zuora", http.StatusSeeOther)
+		http.Error(w, "use /upload/gcp, /upload/zuora or /upload/stripe", http.StatusSeeOther)
 	})
 	server.HTTP.Path("/upload/gcp").Methods("POST").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if err := gcpUpload.Do(time.Now()); err != nil {
@@ -150,6 +173,17 @@ func main() {
 			w.Write([]byte("Success"))
 		}
 	})
+	server.HTTP.Path("/upload/stripe").Methods("POST").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if stripeUpload == nil {
+			http.Error(w, "Stripe usage uploader is disabled", http.StatusNotFound)
+			return
+		}
+		if err := stripeUpload.Do(time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		} else {
+			w.Write([]byte("Success"))
+		}
+	})
 	// healthCheck handles a very simple health check
 	server.HTTP.Path("/healthcheck").Methods("GET").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,81 @@
+package catalog
+
+import (
+	"github.com/weaveworks/service/common/billing/provider"
+	"github.com/weaveworks/service/common/constants/billing"
+)
+
+// Product is something we bill for, metered in one or more amount types.
+type Product struct {
+	// Name identifies the product, e.g. "nodes".
+	Name string
+	// Unit is what usage of the product is counted in.
+	Unit string
+	// AmountTypes are the amount types of the aggregates metering the
+	// product. Their amounts add up.
+	AmountTypes []string
+	// Charges are what the product is billed as, per billing provider: a unit
+	// of measure in Zuora, a metric name in GCP, formatted with the
+	// subscription level, or the amount type of the usage records of a Stripe
+	// subscription. Products without a charge for a provider aren't billed
+	// through it.
+	Charges map[string]string
+}
+
+// Catalog lists the products we bill for.
+type Catalog []Product
+
+// Default is the catalog of Weave Cloud.
+// Only nodes are billed through GCP, as our marketplace service only defines
+// their metrics, and Service Control rejects reports with any unknown metric.
+var Default = Catalog{
+	{
+		Name:        "nodes",
+		Unit:        billing.UsageNodeSeconds,
+		AmountTypes: []string{billing.UsageNodeSeconds},
+		Charges: map[string]string{
+			provider.Zuora:  billing.UsageNodeSeconds,
+			provider.GCP:    "google.weave.works/%s_nodes",
+			provider.Stripe: billing.UsageNodeSeconds,
+		},
+	},
+	{
+		Name:        "samples",
+		Unit:        billing.UsageSamples,
+		AmountTypes: []string{billing.UsageSamples},
+		Charges: map[string]string{
+			provider.Zuora: billing.UsageSamples,
+		},
+	},
+	{
+		Name:        "deploys",
+		Unit:        "deploys",
+		AmountTypes: []string{billing.UsageFluxRelease, billing.UsageFluxAutoRelease},
+		Charges: map[string]string{
+			provider.Zuora: "deploys",
+		},
+	},
+}
+
+// Product returns the product metered in the amount type.
+func (c Catalog) Product(amountType string) (Product, bool) {
+	for _, p := range c {
+		for _, t := range p.AmountTypes {
+			if t == amountType {
+				return p, true
+			}
+		}
+	}
+	return Product{}, false
+}
+
+// Charge returns what usage of the amount type is billed as through the
+// provider, or false if it isn't billed through it.
+func (c Catalog) Charge(providerName, amountType string) (string, bool) {
+	p, ok := c.Product(amountType)
+	if !ok {
+		return "", false
+	}
+	charge, ok := p.Charges[providerName]
+	return charge, ok
+}
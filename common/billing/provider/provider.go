@@ -2,3 +2,13 @@ package provider
 
 // External is the type for a "billed externally" billing provider.
 const External = "external"
+
+// Stripe is the type for teams billed through Stripe, rather than per
+// instance through Zuora or GCP.
+const Stripe = "stripe"
+
+// Zuora is the provider of instances billed through Zuora.
+const Zuora = "zuora"
+
+// GCP is the provider of instances billed through the GCP marketplace.
+const GCP = "gcp"
@@ -2,3 +2,13 @@ package billing
 
 // UsageNodeSeconds denotes the billing usage type.
 const UsageNodeSeconds = "node-seconds"
+
+// UsageSamples denotes the Cortex samples usage type, as emitted by metrics-usage.
+const UsageSamples = "samples"
+
+// UsageFluxRelease and UsageFluxAutoRelease denote the flux deploys usage
+// types, as emitted by flux-api for manual and automated releases.
+const (
+	UsageFluxRelease     = "flux-release"
+	UsageFluxAutoRelease = "flux-autorelease"
+)
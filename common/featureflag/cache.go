@@ -0,0 +1,47 @@
+package featureflag
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Cache keeps flags for a while after loading them, so that they aren't
+// loaded for every evaluation.
+type Cache struct {
+	load func(context.Context) ([]Flag, error)
+	ttl  time.Duration
+	now  func() time.Time
+
+	mtx      sync.Mutex
+	flags    []Flag
+	loadedAt time.Time
+}
+
+// NewCache creates a cache of the flags returned by load, reloading them
+// once they are older than ttl. A ttl of 0 loads them every time.
+func NewCache(load func(context.Context) ([]Flag, error), ttl time.Duration) *Cache {
+	return &Cache{load: load, ttl: ttl, now: time.Now}
+}
+
+// Flags returns the cached flags, reloading them if they have expired. If
+// reloading fails, the flags loaded last are returned until it succeeds.
+func (c *Cache) Flags(ctx context.Context) ([]Flag, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	now := c.now()
+	if c.loadedAt.IsZero() || now.Sub(c.loadedAt) >= c.ttl {
+		flags, err := c.load(ctx)
+		if err != nil {
+			if c.loadedAt.IsZero() {
+				return nil, err
+			}
+			log.Warnf("Failed to reload feature flags, using those loaded at %v: %v", c.loadedAt, err)
+			return c.flags, nil
+		}
+		c.flags, c.loadedAt = flags, now
+	}
+	return c.flags, nil
+}
@@ -0,0 +1,37 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	loads := 0
+	var loadErr error
+	c := NewCache(func(context.Context) ([]Flag, error) {
+		loads++
+		return []Flag{{Name: "flag"}}, loadErr
+	}, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	flags, err := c.Flags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Flag{{Name: "flag"}}, flags)
+	c.Flags(ctx)
+	assert.Equal(t, 1, loads)
+
+	// Flags loaded before are used if reloading fails
+	now = now.Add(time.Minute)
+	loadErr = errors.New("db is down")
+	flags, err = c.Flags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Flag{{Name: "flag"}}, flags)
+	assert.Equal(t, 2, loads)
+}
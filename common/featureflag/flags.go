@@ -17,3 +17,10 @@ const WeaveworksInternal = "weaveworks-internal"
 
 // WeeklyReportable feature flag enables weekly reports to be sent to the members of an organization
 const WeeklyReportable = "weekly-reportable"
+
+// RateLimit feature flag overrides the rate at which authfe accepts probe requests from an organization.
+// Its value has the form "rate-limit:<requests per second>[:<burst>]".
+const RateLimit = "rate-limit"
+
+// NoRateLimit feature flag exempts an organization from authfe's probe request rate limits.
+const NoRateLimit = "no-rate-limit"
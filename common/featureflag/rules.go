@@ -0,0 +1,158 @@
+package featureflag
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	flagNameRegexp  = regexp.MustCompile(`\A[a-zA-Z0-9_.-]+\z`)
+	flagValueRegexp = regexp.MustCompile(`\A\S*\z`)
+)
+
+// Flag is a feature flag whose value is decided by rules, rather than being set
+// on organizations one at a time.
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Rules are tried in order, and the first one which matches an
+	// organization decides the flag's value for it.
+	Rules []Rule `json:"rules,omitempty"`
+	// Enabled and Value are the default, for organizations no rule matches.
+	Enabled bool   `json:"enabled,omitempty"`
+	Value   string `json:"value,omitempty"`
+}
+
+// Rule matches organizations by their attributes. Empty conditions match all
+// organizations.
+type Rule struct {
+	Platforms        []string   `json:"platforms,omitempty"`
+	Environments     []string   `json:"environments,omitempty"`
+	Teams            []string   `json:"teams,omitempty"`
+	BillingProviders []string   `json:"billingProviders,omitempty"`
+	CreatedAfter     *time.Time `json:"createdAfter,omitempty"`
+	CreatedBefore    *time.Time `json:"createdBefore,omitempty"`
+	// Percentage of the organizations matching the other conditions which
+	// the rule applies to. Organizations are picked by hashing their ID, so
+	// the same ones are picked every time, and raising the percentage only
+	// adds more.
+	Percentage *uint `json:"percentage,omitempty"`
+
+	// Enabled and Value are those of the flag for matching organizations.
+	Enabled bool   `json:"enabled"`
+	Value   string `json:"value,omitempty"`
+}
+
+// Subject is an organization, as far as rules are concerned.
+type Subject struct {
+	ID              string
+	Platform        string
+	Environment     string
+	Team            string
+	BillingProvider string
+	CreatedAt       time.Time
+	// FeatureFlags are those set on the organization itself, which take
+	// precedence over rules.
+	FeatureFlags []string
+}
+
+// Validate checks a flag can be evaluated, and that its values can be passed
+// on in a space-separated list of flags.
+func (f *Flag) Validate() error {
+	if !flagNameRegexp.MatchString(f.Name) {
+		return fmt.Errorf("invalid flag name %q: expected letters, digits, '_', '.' or '-'", f.Name)
+	}
+	if !flagValueRegexp.MatchString(f.Value) {
+		return fmt.Errorf("invalid default value %q: values cannot contain spaces", f.Value)
+	}
+	for i, rule := range f.Rules {
+		if !flagValueRegexp.MatchString(rule.Value) {
+			return fmt.Errorf("rule %d: invalid value %q: values cannot contain spaces", i+1, rule.Value)
+		}
+		if rule.Percentage != nil && *rule.Percentage > uint(max) {
+			return fmt.Errorf("rule %d: invalid percentage %d: expected a value in the [0, 100] interval", i+1, *rule.Percentage)
+		}
+		if rule.CreatedAfter != nil && rule.CreatedBefore != nil && !rule.CreatedAfter.Before(*rule.CreatedBefore) {
+			return fmt.Errorf("rule %d: createdAfter must be before createdBefore", i+1)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns whether the flag is enabled for the subject, and its value
+// if so. Flags set on the subject itself are returned as they are.
+func (f *Flag) Evaluate(s Subject) (string, bool) {
+	if value, ok := GetFeatureFlagValue(f.Name, s.FeatureFlags); ok {
+		return value, true
+	}
+	for _, rule := range f.Rules {
+		if rule.matches(f.Name, s) {
+			return rule.Value, rule.Enabled
+		}
+	}
+	return f.Value, f.Enabled
+}
+
+// Resolve returns the feature flags of the subject: those set on it, and those
+// the flags enable for it, as "name" or "name:value".
+func Resolve(flags []Flag, s Subject) []string {
+	resolved := append([]string{}, s.FeatureFlags...)
+	for i := range flags {
+		if _, ok := GetFeatureFlagValue(flags[i].Name, s.FeatureFlags); ok {
+			continue
+		}
+		if value, ok := flags[i].Evaluate(s); ok {
+			resolved = append(resolved, format(flags[i].Name, value))
+		}
+	}
+	return resolved
+}
+
+func format(name, value string) string {
+	if value == "" {
+		return name
+	}
+	return name + ":" + value
+}
+
+func (r *Rule) matches(flagName string, s Subject) bool {
+	if !matchesAny(r.Platforms, s.Platform) ||
+		!matchesAny(r.Environments, s.Environment) ||
+		!matchesAny(r.Teams, s.Team) ||
+		!matchesAny(r.BillingProviders, s.BillingProvider) {
+		return false
+	}
+	if r.CreatedAfter != nil && !s.CreatedAt.After(*r.CreatedAfter) {
+		return false
+	}
+	if r.CreatedBefore != nil && !s.CreatedAt.Before(*r.CreatedBefore) {
+		return false
+	}
+	return r.Percentage == nil || bucket(flagName, s.ID) < *r.Percentage
+}
+
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// bucket places an organization in one of 100 buckets for a flag. Hashing the
+// flag's name too means different flags are rolled out to different
+// organizations first.
+func bucket(flagName, id string) uint {
+	h := fnv.New32a()
+	h.Write([]byte(flagName))
+	h.Write([]byte{0})
+	h.Write([]byte(id))
+	return uint(h.Sum32() % uint32(max))
+}
@@ -0,0 +1,114 @@
+package featureflag_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/weaveworks/service/common/featureflag"
+)
+
+func percentage(p uint) *uint {
+	return &p
+}
+
+func TestFlagEvaluate(t *testing.T) {
+	launch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	flag := featureflag.Flag{
+		Name: "new-ui",
+		Rules: []featureflag.Rule{
+			{Teams: []string{"excluded-team"}, Enabled: false},
+			{Platforms: []string{"kubernetes"}, Environments: []string{"gke", "eks"}, Enabled: true, Value: "kube"},
+			{BillingProviders: []string{"gcp"}, Enabled: true, Value: "gcp"},
+			{CreatedAfter: &launch, Enabled: true, Value: "new"},
+		},
+		Enabled: true,
+		Value:   "default",
+	}
+	for _, tc := range []struct {
+		subject featureflag.Subject
+		value   string
+		enabled bool
+	}{
+		{featureflag.Subject{Team: "excluded-team", Platform: "kubernetes", Environment: "gke"}, "", false},
+		{featureflag.Subject{Platform: "kubernetes", Environment: "GKE"}, "kube", true},
+		{featureflag.Subject{Platform: "kubernetes", Environment: "minikube"}, "default", true},
+		{featureflag.Subject{Platform: "docker", BillingProvider: "gcp"}, "gcp", true},
+		{featureflag.Subject{CreatedAt: launch.Add(time.Hour)}, "new", true},
+		{featureflag.Subject{CreatedAt: launch}, "default", true},
+		// Flags set on organizations take precedence
+		{featureflag.Subject{Team: "excluded-team", FeatureFlags: []string{"new-ui:mine"}}, "mine", true},
+	} {
+		value, enabled := flag.Evaluate(tc.subject)
+		assert.Equal(t, tc.enabled, enabled, "%+v", tc.subject)
+		assert.Equal(t, tc.value, value, "%+v", tc.subject)
+	}
+}
+
+func TestFlagEvaluate_Percentage(t *testing.T) {
+	flag := featureflag.Flag{
+		Name:  "rollout",
+		Rules: []featureflag.Rule{{Percentage: percentage(20), Enabled: true}},
+	}
+	wider := featureflag.Flag{
+		Name:  "rollout",
+		Rules: []featureflag.Rule{{Percentage: percentage(50), Enabled: true}},
+	}
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		s := featureflag.Subject{ID: fmt.Sprint(i)}
+		_, on := flag.Evaluate(s)
+		// Evaluation is stable
+		_, again := flag.Evaluate(s)
+		assert.Equal(t, on, again)
+		// Raising the percentage only adds organizations
+		if _, onWider := wider.Evaluate(s); on {
+			assert.True(t, onWider)
+		}
+		if on {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 200, enabled, 50)
+
+	none := featureflag.Flag{Name: "none", Rules: []featureflag.Rule{{Percentage: percentage(0), Enabled: true}}}
+	_, on := none.Evaluate(featureflag.Subject{ID: "1"})
+	assert.False(t, on)
+}
+
+func TestResolve(t *testing.T) {
+	flags := []featureflag.Flag{
+		{Name: "on", Enabled: true},
+		{Name: "off"},
+		{Name: "valued", Enabled: true, Value: "42"},
+		{Name: "explicit", Enabled: true, Value: "rule"},
+	}
+	assert.Equal(t,
+		[]string{"billing", "explicit:set", "on", "valued:42"},
+		featureflag.Resolve(flags, featureflag.Subject{FeatureFlags: []string{"billing", "explicit:set"}}))
+	assert.Equal(t,
+		[]string{"on", "valued:42", "explicit:rule"},
+		featureflag.Resolve(flags, featureflag.Subject{}))
+}
+
+func TestFlagValidate(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		flag  featureflag.Flag
+		valid bool
+	}{
+		{featureflag.Flag{Name: "rate-limit", Enabled: true, Value: "10:20"}, true},
+		{featureflag.Flag{Name: ""}, false},
+		{featureflag.Flag{Name: "has space"}, false},
+		{featureflag.Flag{Name: "has:colon"}, false},
+		{featureflag.Flag{Name: "ok", Value: "has space"}, false},
+		{featureflag.Flag{Name: "ok", Rules: []featureflag.Rule{{Percentage: percentage(100)}}}, true},
+		{featureflag.Flag{Name: "ok", Rules: []featureflag.Rule{{Percentage: percentage(101)}}}, false},
+		{featureflag.Flag{Name: "ok", Rules: []featureflag.Rule{{Value: "a b"}}}, false},
+		{featureflag.Flag{Name: "ok", Rules: []featureflag.Rule{{CreatedAfter: &now, CreatedBefore: &now}}}, false},
+	} {
+		err := tc.flag.Validate()
+		assert.Equal(t, tc.valid, err == nil, "%+v: %v", tc.flag, err)
+	}
+}
@@ -50,6 +50,7 @@ type UserItem struct {
 type User struct {
 	Username string `yaml:"username,omitempty"`
 	Password string `yaml:"password,omitempty"`
+	Token    string `yaml:"token,omitempty"`
 }
 
 // Unmarshal unmarshals the provided YAML string into this instance of KubeConfig.
@@ -97,3 +98,10 @@ func NewKubeConfig(clusterName, endpoint, username, password, clusterCaCertifica
 		},
 	}
 }
+
+// NewTokenKubeConfig helps constructing a new KubeConfig, authenticating with a bearer token rather than a password.
+func NewTokenKubeConfig(clusterName, endpoint, token, clusterCaCertificate string) *KubeConfig {
+	kubeCfg := NewKubeConfig(clusterName, endpoint, clusterName, "", clusterCaCertificate)
+	kubeCfg.Users[0].User = User{Token: token}
+	return kubeCfg
+}
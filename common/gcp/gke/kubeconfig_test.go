@@ -103,3 +103,27 @@ func TestCreateManuallyAndMarshalKubeConfig(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, KubeConfigYAML, string(bytes))
 }
+
+func TestCreateWithTokenAndMarshalKubeConfig(t *testing.T) {
+	kubeCfg := gke.NewTokenKubeConfig("dev", "192.168.0.1", "k8s-aws-v1.abc", "foo")
+	bytes, err := kubeCfg.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Config
+current-context: dev
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+clusters:
+- name: dev
+  cluster:
+    certificate-authority-data: foo
+    server: https://192.168.0.1
+users:
+- name: dev
+  user:
+    token: k8s-aws-v1.abc
+`, string(bytes))
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"net"
 	"net/http"
+	"strings"
 
 	"github.com/weaveworks/common/logging"
 	"github.com/weaveworks/common/user"
@@ -16,3 +17,17 @@ func HostFromRequest(r *http.Request) string {
 	}
 	return host
 }
+
+// ClientIP returns the address of the client, which is usually behind authfe.
+// authfe gets the client's address from the load balancer, and appends it to
+// X-Forwarded-For when proxying, so only the last entry can be trusted: the
+// others are whatever the client sent.
+func ClientIP(r *http.Request) string {
+	if forwarded := r.Header["X-Forwarded-For"]; len(forwarded) > 0 {
+		entries := strings.Split(forwarded[len(forwarded)-1], ",")
+		if last := strings.TrimSpace(entries[len(entries)-1]); last != "" {
+			return last
+		}
+	}
+	return HostFromRequest(r)
+}
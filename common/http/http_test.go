@@ -0,0 +1,33 @@
+package http_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	httpUtil "github.com/weaveworks/service/common/http"
+)
+
+func TestClientIP(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		forwarded []string
+		expected  string
+	}{
+		{name: "direct", expected: "192.0.2.1"},
+		{name: "proxied", forwarded: []string{"198.51.100.7"}, expected: "198.51.100.7"},
+		{name: "spoofed", forwarded: []string{"203.0.113.9, 198.51.100.7"}, expected: "198.51.100.7"},
+		{name: "several headers", forwarded: []string{"203.0.113.9", "198.51.100.7"}, expected: "198.51.100.7"},
+		{name: "empty", forwarded: []string{""}, expected: "192.0.2.1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+			for _, f := range tc.forwarded {
+				r.Header.Add("X-Forwarded-For", f)
+			}
+			assert.Equal(t, tc.expected, httpUtil.ClientIP(r))
+		})
+	}
+}
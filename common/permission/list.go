@@ -77,3 +77,49 @@ const CreateWebhook = "instance.webhook.create"
 
 // DeleteWebhook permission allows user to delete webhooks
 const DeleteWebhook = "instance.webhook.delete"
+
+// CreateToken permission allows creating scoped API tokens for instances
+const CreateToken = "instance.token.create"
+
+// RevokeToken permission allows revoking API tokens of instances
+const RevokeToken = "instance.token.revoke"
+
+// ViewAuditLog permission allows viewing the audit log of an instance
+const ViewAuditLog = "instance.audit.view"
+
+// ManageTeamRoles permission allows creating, updating and deleting the custom roles of a team
+const ManageTeamRoles = "team.roles.manage"
+
+// ManageTeamSSO permission allows configuring single sign-on through the team's own identity provider
+const ManageTeamSSO = "team.sso.manage"
+
+// ManageTeamSCIM permission allows managing the token the team's identity provider provisions members through SCIM with
+const ManageTeamSCIM = "team.scim.manage"
+
+// ManageTeamWebhooks permission allows managing the webhooks the team's events are sent to
+const ManageTeamWebhooks = "team.webhooks.manage"
+
+// RequiringTwoFactor lists the sensitive permissions teams can require
+// members to have enabled two-factor authentication to use
+var RequiringTwoFactor = []string{
+	OpenHostShell,
+	OpenContainerShell,
+	UpdateBilling,
+	DeleteInstance,
+	TransferInstance,
+	CreateToken,
+	ManageTeamRoles,
+	ManageTeamSSO,
+	ManageTeamSCIM,
+}
+
+// RequiresTwoFactor tells whether teams can require two-factor
+// authentication to use a permission
+func RequiresTwoFactor(permissionID string) bool {
+	for _, id := range RequiringTwoFactor {
+		if id == permissionID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package stripe
+
+import (
+	"flag"
+	"time"
+)
+
+// Config provides the values necessary to create a new Stripe client.
+type Config struct {
+	Endpoint  string
+	SecretKey string
+	Timeout   time.Duration
+
+	// PriceID is the metered price node-seconds are billed at.
+	PriceID string
+}
+
+// RegisterFlags registers flags to configure a Stripe client.
+func (c *Config) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&c.Endpoint, "stripe.endpoint", "https://api.stripe.com/v1/", "Endpoint for Stripe's API")
+	f.StringVar(&c.SecretKey, "stripe.secret-key", "", "Secret key for Stripe's API. Billing through Stripe is disabled if empty.")
+	f.DurationVar(&c.Timeout, "stripe.timeout", 10*time.Second, "Timeout for requests to Stripe's API")
+	f.StringVar(&c.PriceID, "stripe.price-id", "", "ID of the metered Stripe price node-seconds are billed at")
+}
+
+// Enabled returns whether billing through Stripe is configured.
+func (c *Config) Enabled() bool {
+	return c.SecretKey != ""
+}
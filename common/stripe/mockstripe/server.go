@@ -0,0 +1,375 @@
+package mockstripe
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/service/common/stripe"
+)
+
+// Server is a stub of Stripe's API, keeping its state in memory. It only
+// implements what stripe.Client uses.
+type Server struct {
+	*httptest.Server
+
+	mtx            sync.Mutex
+	nextID         int
+	customers      map[string]*stripe.Customer
+	paymentMethods map[string]*stripe.PaymentMethod
+	subscriptions  []*stripe.Subscription
+	invoices       []stripe.Invoice
+	usageRecords   map[string][]stripe.UsageRecord
+	rejectedItems  map[string]bool
+	// replies are those of requests with an idempotency key, which are
+	// replayed if the key is sent again.
+	replies map[string][]byte
+}
+
+// New starts a stub Stripe server. Close it once done.
+func New() *Server {
+	s := &Server{
+		customers:      map[string]*stripe.Customer{},
+		paymentMethods: map[string]*stripe.PaymentMethod{},
+		usageRecords:   map[string][]stripe.UsageRecord{},
+		rejectedItems:  map[string]bool{},
+		replies:        map[string][]byte{},
+	}
+	r := mux.NewRouter().PathPrefix("/v1").Subrouter()
+	r.HandleFunc("/customers", s.createCustomer).Methods("POST")
+	r.HandleFunc("/customers/{id}", s.getCustomer).Methods("GET")
+	r.HandleFunc("/customers/{id}", s.updateCustomer).Methods("POST")
+	r.HandleFunc("/payment_methods", s.listPaymentMethods).Methods("GET")
+	r.HandleFunc("/payment_methods/{id}/attach", s.attachPaymentMethod).Methods("POST")
+	r.HandleFunc("/subscriptions", s.createSubscription).Methods("POST")
+	r.HandleFunc("/subscriptions", s.listSubscriptions).Methods("GET")
+	r.HandleFunc("/invoices", s.listInvoices).Methods("GET")
+	r.HandleFunc("/subscription_items/{id}/usage_records", s.createUsageRecord).Methods("POST")
+	s.Server = httptest.NewServer(s.idempotent(r))
+	return s
+}
+
+// Config returns the configuration of a client of this server, billing
+// usage at priceID.
+func (s *Server) Config(priceID string) stripe.Config {
+	return stripe.Config{
+		Endpoint:  s.URL + "/v1/",
+		SecretKey: "sk_test_mockstripe",
+		Timeout:   5 * time.Second,
+		PriceID:   priceID,
+	}
+}
+
+// AddPaymentMethod adds a card which can then be attached to a customer, as
+// Stripe.js would, and returns its ID.
+func (s *Server) AddPaymentMethod(card stripe.Card) string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	pm := &stripe.PaymentMethod{ID: s.newID("pm"), Type: "card", Card: &card}
+	s.paymentMethods[pm.ID] = pm
+	return pm.ID
+}
+
+// AddInvoice adds an invoice, as Stripe would at the end of a billing period.
+func (s *Server) AddInvoice(invoice stripe.Invoice) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if invoice.ID == "" {
+		invoice.ID = s.newID("in")
+	}
+	s.invoices = append(s.invoices, invoice)
+}
+
+// UsageRecords returns the usage records created for a subscription item.
+func (s *Server) UsageRecords(subscriptionItemID string) []stripe.UsageRecord {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]stripe.UsageRecord{}, s.usageRecords[subscriptionItemID]...)
+}
+
+// SetSubscriptionStart backdates a subscription, as if it started at start.
+func (s *Server) SetSubscriptionStart(subscriptionID string, start time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, sub := range s.subscriptions {
+		if sub.ID == subscriptionID {
+			sub.StartDate = start.Unix()
+			sub.CurrentPeriodStart = start.Unix()
+		}
+	}
+}
+
+// RejectUsageRecords makes creating usage records of a subscription item
+// fail from now on.
+func (s *Server) RejectUsageRecords(subscriptionItemID string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.rejectedItems[subscriptionItemID] = true
+}
+
+// newID returns a new ID with the same prefix Stripe would use. The caller
+// must hold the lock.
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s_%d", prefix, s.nextID)
+}
+
+func (s *Server) createCustomer(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	c := &stripe.Customer{ID: s.newID("cus"), Metadata: map[string]string{}}
+	updateCustomer(c, r)
+	s.customers[c.ID] = c
+	reply(w, http.StatusOK, c)
+}
+
+func (s *Server) getCustomer(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	c, ok := s.customers[mux.Vars(r)["id"]]
+	if !ok {
+		notFound(w, "customer")
+		return
+	}
+	reply(w, http.StatusOK, c)
+}
+
+func (s *Server) updateCustomer(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	c, ok := s.customers[mux.Vars(r)["id"]]
+	if !ok {
+		notFound(w, "customer")
+		return
+	}
+	if id := r.FormValue("invoice_settings[default_payment_method]"); id != "" {
+		if pm, ok := s.paymentMethods[id]; !ok || pm.Customer != c.ID {
+			notFound(w, "payment_method")
+			return
+		}
+	}
+	updateCustomer(c, r)
+	reply(w, http.StatusOK, c)
+}
+
+func updateCustomer(c *stripe.Customer, r *http.Request) {
+	set := func(dest *string, key string) {
+		if value := r.FormValue(key); value != "" {
+			*dest = value
+		}
+	}
+	set(&c.Name, "name")
+	set(&c.Email, "email")
+	set(&c.Phone, "phone")
+	set(&c.Address.Line1, "address[line1]")
+	set(&c.Address.Line2, "address[line2]")
+	set(&c.Address.City, "address[city]")
+	set(&c.Address.PostalCode, "address[postal_code]")
+	set(&c.Address.State, "address[state]")
+	set(&c.Address.Country, "address[country]")
+	set(&c.InvoiceSettings.DefaultPaymentMethod, "invoice_settings[default_payment_method]")
+	for key, values := range r.PostForm {
+		if len(key) > len("metadata[]") && key[:len("metadata[")] == "metadata[" {
+			c.Metadata[key[len("metadata["):len(key)-1]] = values[0]
+		}
+	}
+}
+
+func (s *Server) listPaymentMethods(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	customerID := r.FormValue("customer")
+	if _, ok := s.customers[customerID]; !ok {
+		notFound(w, "customer")
+		return
+	}
+	pms := []*stripe.PaymentMethod{}
+	for _, pm := range s.paymentMethods {
+		if pm.Customer == customerID {
+			pms = append(pms, pm)
+		}
+	}
+	sort.Slice(pms, func(i, j int) bool { return pms[i].ID > pms[j].ID })
+	reply(w, http.StatusOK, list(pms))
+}
+
+func (s *Server) attachPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	pm, ok := s.paymentMethods[mux.Vars(r)["id"]]
+	if !ok {
+		notFound(w, "payment_method")
+		return
+	}
+	customerID := r.FormValue("customer")
+	if _, ok := s.customers[customerID]; !ok {
+		notFound(w, "customer")
+		return
+	}
+	// Like Stripe's test card 4000000000000002, cards ending in 0002 are declined.
+	if pm.Card != nil && pm.Card.Last4 == "0002" {
+		reply(w, http.StatusPaymentRequired, map[string]*stripe.Error{"error": {
+			Type: "card_error", Code: "card_declined", DeclineCode: "generic_decline", Message: "Your card was declined.",
+		}})
+		return
+	}
+	pm.Customer = customerID
+	reply(w, http.StatusOK, pm)
+}
+
+func (s *Server) createSubscription(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	customerID := r.FormValue("customer")
+	if _, ok := s.customers[customerID]; !ok {
+		notFound(w, "customer")
+		return
+	}
+	now := time.Now().Unix()
+	sub := &stripe.Subscription{
+		ID:                 s.newID("sub"),
+		Customer:           customerID,
+		Status:             stripe.SubscriptionActive,
+		StartDate:          now,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   time.Now().AddDate(0, 1, 0).Unix(),
+	}
+	if trialEnd, err := strconv.ParseInt(r.FormValue("trial_end"), 10, 64); err == nil {
+		sub.Status = stripe.SubscriptionTrialing
+		sub.TrialEnd = trialEnd
+	}
+	for i := 0; ; i++ {
+		priceID := r.FormValue(fmt.Sprintf("items[%d][price]", i))
+		if priceID == "" {
+			break
+		}
+		item := stripe.SubscriptionItem{ID: s.newID("si"), Price: stripe.Price{ID: priceID, Currency: "usd"}}
+		item.Price.Recurring.Interval = "month"
+		item.Price.Recurring.UsageType = "metered"
+		sub.Items.Data = append(sub.Items.Data, item)
+	}
+	s.subscriptions = append(s.subscriptions, sub)
+	reply(w, http.StatusOK, sub)
+}
+
+func (s *Server) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	subs := []*stripe.Subscription{}
+	for _, sub := range s.subscriptions {
+		if sub.Customer == r.FormValue("customer") {
+			subs = append(subs, sub)
+		}
+	}
+	reply(w, http.StatusOK, list(subs))
+}
+
+func (s *Server) listInvoices(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 10
+	}
+	invoices := []stripe.Invoice{}
+	for i := len(s.invoices) - 1; i >= 0 && len(invoices) < limit; i-- {
+		if s.invoices[i].Customer == r.FormValue("customer") {
+			invoices = append(invoices, s.invoices[i])
+		}
+	}
+	reply(w, http.StatusOK, list(invoices))
+}
+
+func (s *Server) createUsageRecord(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	itemID := mux.Vars(r)["id"]
+	found := false
+	for _, sub := range s.subscriptions {
+		for _, item := range sub.Items.Data {
+			found = found || item.ID == itemID
+		}
+	}
+	if !found {
+		notFound(w, "subscription_item")
+		return
+	}
+	if s.rejectedItems[itemID] {
+		invalid(w, "subscription_item")
+		return
+	}
+	quantity, err := strconv.ParseInt(r.FormValue("quantity"), 10, 64)
+	if err != nil {
+		invalid(w, "quantity")
+		return
+	}
+	timestamp, err := strconv.ParseInt(r.FormValue("timestamp"), 10, 64)
+	if err != nil {
+		invalid(w, "timestamp")
+		return
+	}
+	record := stripe.UsageRecord{ID: s.newID("mbur"), SubscriptionItem: itemID, Quantity: quantity, Timestamp: timestamp}
+	s.usageRecords[itemID] = append(s.usageRecords[itemID], record)
+	reply(w, http.StatusOK, record)
+}
+
+// idempotent replays the reply to earlier requests with the same
+// idempotency key, like Stripe does.
+func (s *Server) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || r.Method != "POST" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		s.mtx.Lock()
+		body, ok := s.replies[key]
+		s.mtx.Unlock()
+		if ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(body)
+			return
+		}
+		rec := httptest.NewRecorder()
+		next.ServeHTTP(rec, r)
+		if rec.Code == http.StatusOK {
+			s.mtx.Lock()
+			s.replies[key] = rec.Body.Bytes()
+			s.mtx.Unlock()
+		}
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	})
+}
+
+func list(data interface{}) map[string]interface{} {
+	return map[string]interface{}{"object": "list", "data": data}
+}
+
+func notFound(w http.ResponseWriter, resource string) {
+	reply(w, http.StatusNotFound, map[string]*stripe.Error{"error": {
+		Type: "invalid_request_error", Code: "resource_missing", Message: "No such " + resource,
+	}})
+}
+
+func invalid(w http.ResponseWriter, param string) {
+	reply(w, http.StatusBadRequest, map[string]*stripe.Error{"error": {
+		Type: "invalid_request_error", Code: "parameter_invalid", Message: "Invalid " + param,
+	}})
+}
+
+func reply(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,282 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/weaveworks/common/http/client"
+	"github.com/weaveworks/common/instrument"
+	"github.com/weaveworks/service/common"
+)
+
+var clientRequestCollector = instrument.NewHistogramCollectorFromOpts(prometheus.HistogramOpts{
+	Namespace: common.PrometheusNamespace,
+	Subsystem: "stripe_client",
+	Name:      "request_duration_seconds",
+	Help:      "Response time of Stripe requests.",
+})
+
+func init() {
+	clientRequestCollector.Register()
+}
+
+// ErrNotFound means Stripe couldn't find what we were looking for.
+var ErrNotFound = errors.New("not found")
+
+// Error is an error returned by Stripe's API.
+// See also: https://stripe.com/docs/api/errors
+type Error struct {
+	StatusCode  int    `json:"-"`
+	Type        string `json:"type"`
+	Code        string `json:"code"`
+	DeclineCode string `json:"decline_code"`
+	Message     string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("stripe: %s (%d %s)", e.Message, e.StatusCode, e.Type)
+}
+
+// IsCardError returns whether the error is about the customer's card, e.g.
+// because it was declined, rather than about the request.
+func (e *Error) IsCardError() bool {
+	return e.Type == "card_error"
+}
+
+// Client defines an interface to access the Stripe API.
+type Client interface {
+	GetConfig() Config
+
+	GetCustomer(ctx context.Context, customerID string) (*Customer, error)
+	CreateCustomer(ctx context.Context, params CustomerParams) (*Customer, error)
+	UpdateCustomer(ctx context.Context, customerID string, params CustomerParams) (*Customer, error)
+
+	// AttachPaymentMethod attaches the payment method to the customer, and
+	// makes it the default one for their invoices.
+	AttachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error
+	GetPaymentMethods(ctx context.Context, customerID string) ([]PaymentMethod, error)
+
+	// CreateSubscription subscribes the customer to the configured price,
+	// starting at the end of their trial if it isn't over.
+	CreateSubscription(ctx context.Context, customerID string, trialEnd time.Time) (*Subscription, error)
+	GetSubscriptions(ctx context.Context, customerID string) ([]Subscription, error)
+
+	GetInvoices(ctx context.Context, customerID string, limit int) ([]Invoice, error)
+
+	// CreateUsageRecord adds usage to a metered subscription item. Records
+	// with the same idempotency key are only counted once.
+	CreateUsageRecord(ctx context.Context, subscriptionItemID string, quantity int64, timestamp time.Time, idempotencyKey string) (*UsageRecord, error)
+}
+
+// Stripe implements Client.
+type Stripe struct {
+	*common.JSONClient
+	cfg Config
+}
+
+// New returns a Stripe client. If httpClient is nil, http.Client is instantiated.
+func New(cfg Config, httpClient client.Requester) *Stripe {
+	if !strings.HasSuffix(cfg.Endpoint, "/") {
+		cfg.Endpoint += "/"
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &Stripe{
+		JSONClient: common.NewJSONClient(client.NewTimedClient(httpClient, clientRequestCollector)),
+		cfg:        cfg,
+	}
+}
+
+// GetConfig returns the underlying Config.
+func (s *Stripe) GetConfig() Config {
+	return s.cfg
+}
+
+// GetCustomer gets a customer.
+func (s *Stripe) GetCustomer(ctx context.Context, customerID string) (*Customer, error) {
+	if customerID == "" {
+		return nil, ErrNotFound
+	}
+	customer := &Customer{}
+	if err := s.do(ctx, "GET", "customers/{id}", "customers/"+url.PathEscape(customerID), nil, "", customer); err != nil {
+		return nil, err
+	}
+	if customer.Deleted {
+		return nil, ErrNotFound
+	}
+	return customer, nil
+}
+
+// CreateCustomer creates a customer.
+func (s *Stripe) CreateCustomer(ctx context.Context, params CustomerParams) (*Customer, error) {
+	customer := &Customer{}
+	if err := s.do(ctx, "POST", "customers", "customers", params.values(), "", customer); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// UpdateCustomer updates a customer's details.
+func (s *Stripe) UpdateCustomer(ctx context.Context, customerID string, params CustomerParams) (*Customer, error) {
+	if customerID == "" {
+		return nil, ErrNotFound
+	}
+	customer := &Customer{}
+	if err := s.do(ctx, "POST", "customers/{id}", "customers/"+url.PathEscape(customerID), params.values(), "", customer); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// AttachPaymentMethod attaches a payment method to a customer, and makes it
+// the default one for their invoices.
+func (s *Stripe) AttachPaymentMethod(ctx context.Context, customerID, paymentMethodID string) error {
+	if customerID == "" || paymentMethodID == "" {
+		return ErrNotFound
+	}
+	if err := s.do(ctx, "POST", "payment_methods/{id}/attach", "payment_methods/"+url.PathEscape(paymentMethodID)+"/attach",
+		url.Values{"customer": {customerID}}, "", &PaymentMethod{}); err != nil {
+		return err
+	}
+	_, err := s.UpdateCustomer(ctx, customerID, CustomerParams{DefaultPaymentMethod: paymentMethodID})
+	return err
+}
+
+// GetPaymentMethods lists the cards of a customer.
+func (s *Stripe) GetPaymentMethods(ctx context.Context, customerID string) ([]PaymentMethod, error) {
+	if customerID == "" {
+		return nil, ErrNotFound
+	}
+	var list struct {
+		Data []PaymentMethod `json:"data"`
+	}
+	if err := s.do(ctx, "GET", "payment_methods", "payment_methods",
+		url.Values{"customer": {customerID}, "type": {"card"}}, "", &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// CreateSubscription subscribes a customer to the configured price. The
+// subscription starts at the end of the trial if it isn't over yet.
+func (s *Stripe) CreateSubscription(ctx context.Context, customerID string, trialEnd time.Time) (*Subscription, error) {
+	if customerID == "" {
+		return nil, ErrNotFound
+	}
+	values := url.Values{
+		"customer":        {customerID},
+		"items[0][price]": {s.cfg.PriceID},
+	}
+	if trialEnd.After(time.Now()) {
+		values.Set("trial_end", strconv.FormatInt(trialEnd.Unix(), 10))
+	}
+	subscription := &Subscription{}
+	// Retrying creating the same subscription mustn't subscribe the customer twice.
+	idempotencyKey := fmt.Sprintf("subscription-%s-%s", customerID, s.cfg.PriceID)
+	if err := s.do(ctx, "POST", "subscriptions", "subscriptions", values, idempotencyKey, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// GetSubscriptions lists the subscriptions of a customer which haven't been canceled.
+func (s *Stripe) GetSubscriptions(ctx context.Context, customerID string) ([]Subscription, error) {
+	if customerID == "" {
+		return nil, ErrNotFound
+	}
+	var list struct {
+		Data []Subscription `json:"data"`
+	}
+	if err := s.do(ctx, "GET", "subscriptions", "subscriptions", url.Values{"customer": {customerID}}, "", &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// GetInvoices lists the latest invoices of a customer, most recent first.
+func (s *Stripe) GetInvoices(ctx context.Context, customerID string, limit int) ([]Invoice, error) {
+	if customerID == "" {
+		return nil, ErrNotFound
+	}
+	values := url.Values{"customer": {customerID}}
+	if limit > 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	var list struct {
+		Data []Invoice `json:"data"`
+	}
+	if err := s.do(ctx, "GET", "invoices", "invoices", values, "", &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// CreateUsageRecord adds usage to a metered subscription item, at the provided time.
+func (s *Stripe) CreateUsageRecord(ctx context.Context, subscriptionItemID string, quantity int64, timestamp time.Time, idempotencyKey string) (*UsageRecord, error) {
+	if subscriptionItemID == "" {
+		return nil, ErrNotFound
+	}
+	record := &UsageRecord{}
+	if err := s.do(ctx, "POST", "subscription_items/{id}/usage_records", "subscription_items/"+url.PathEscape(subscriptionItemID)+"/usage_records", url.Values{
+		"quantity":  {strconv.FormatInt(quantity, 10)},
+		"timestamp": {strconv.FormatInt(timestamp.Unix(), 10)},
+		"action":    {"increment"},
+	}, idempotencyKey, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// do sends a form-encoded request to Stripe's API, and decodes its JSON reply into dest.
+func (s *Stripe) do(ctx context.Context, method, operation, path string, values url.Values, idempotencyKey string, dest interface{}) error {
+	u := s.cfg.Endpoint + path
+	var body io.Reader
+	if method == "GET" {
+		if len(values) > 0 {
+			u += "?" + values.Encode()
+		}
+	} else {
+		body = strings.NewReader(values.Encode())
+	}
+	r, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return err
+	}
+	r.SetBasicAuth(s.cfg.SecretKey, "")
+	if body != nil {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if idempotencyKey != "" {
+		r.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	resp, err := s.Do(ctx, method+" "+operation, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var reply struct {
+			Error *Error `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil || reply.Error == nil {
+			return &common.HTTPStatusError{Code: resp.StatusCode}
+		}
+		reply.Error.StatusCode = resp.StatusCode
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrNotFound
+		}
+		return reply.Error
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
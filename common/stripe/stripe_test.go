@@ -0,0 +1,115 @@
+package stripe_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/common/stripe"
+	"github.com/weaveworks/service/common/stripe/mockstripe"
+)
+
+func TestCustomerAndPaymentMethods(t *testing.T) {
+	server := mockstripe.New()
+	defer server.Close()
+	client := stripe.New(server.Config("price_nodes"), nil)
+	ctx := context.Background()
+
+	customer, err := client.CreateCustomer(ctx, stripe.CustomerParams{
+		Name:     "Foo Bar",
+		Email:    "foo@weave.works",
+		Address:  &stripe.Address{City: "London", Country: "GB"},
+		Metadata: map[string]string{"team_id": "team-1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "team-1", customer.Metadata["team_id"])
+
+	customer, err = client.UpdateCustomer(ctx, customer.ID, stripe.CustomerParams{Phone: "+44 20"})
+	require.NoError(t, err)
+	assert.Equal(t, "+44 20", customer.Phone)
+	assert.Equal(t, "Foo Bar", customer.Name)
+
+	pmID := server.AddPaymentMethod(stripe.Card{Brand: "visa", Last4: "4242", ExpMonth: 12, ExpYear: 2030})
+	require.NoError(t, client.AttachPaymentMethod(ctx, customer.ID, pmID))
+	customer, err = client.GetCustomer(ctx, customer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pmID, customer.InvoiceSettings.DefaultPaymentMethod)
+
+	pms, err := client.GetPaymentMethods(ctx, customer.ID)
+	require.NoError(t, err)
+	require.Len(t, pms, 1)
+	assert.Equal(t, "4242", pms[0].Card.Last4)
+
+	declined := server.AddPaymentMethod(stripe.Card{Brand: "visa", Last4: "0002"})
+	err = client.AttachPaymentMethod(ctx, customer.ID, declined)
+	require.IsType(t, &stripe.Error{}, err)
+	assert.True(t, err.(*stripe.Error).IsCardError())
+	assert.Equal(t, "generic_decline", err.(*stripe.Error).DeclineCode)
+
+	_, err = client.GetCustomer(ctx, "cus_missing")
+	assert.Equal(t, stripe.ErrNotFound, err)
+}
+
+func TestSubscriptionUsageRecords(t *testing.T) {
+	server := mockstripe.New()
+	defer server.Close()
+	client := stripe.New(server.Config("price_nodes"), nil)
+	ctx := context.Background()
+
+	customer, err := client.CreateCustomer(ctx, stripe.CustomerParams{Name: "Foo Bar"})
+	require.NoError(t, err)
+	sub, err := client.CreateSubscription(ctx, customer.ID, time.Now().Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, stripe.SubscriptionTrialing, sub.Status)
+	assert.True(t, sub.IsActive())
+
+	// Retrying doesn't subscribe the customer twice.
+	_, err = client.CreateSubscription(ctx, customer.ID, time.Now().Add(24*time.Hour))
+	require.NoError(t, err)
+	subs, err := client.GetSubscriptions(ctx, customer.ID)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	item := subs[0].Item("price_nodes")
+	require.NotNil(t, item)
+
+	at := time.Date(2018, 4, 1, 10, 0, 0, 0, time.UTC)
+	_, err = client.CreateUsageRecord(ctx, item.ID, 3600, at, "aggregate-1")
+	require.NoError(t, err)
+	_, err = client.CreateUsageRecord(ctx, item.ID, 3600, at, "aggregate-1")
+	require.NoError(t, err)
+	_, err = client.CreateUsageRecord(ctx, item.ID, 7200, at, "aggregate-2")
+	require.NoError(t, err)
+
+	records := server.UsageRecords(item.ID)
+	require.Len(t, records, 2)
+	assert.Equal(t, int64(3600), records[0].Quantity)
+	assert.Equal(t, at.Unix(), records[0].Timestamp)
+	assert.Equal(t, int64(7200), records[1].Quantity)
+
+	_, err = client.CreateUsageRecord(ctx, "si_missing", 1, at, "aggregate-3")
+	assert.Equal(t, stripe.ErrNotFound, err)
+}
+
+func TestGetInvoices(t *testing.T) {
+	server := mockstripe.New()
+	defer server.Close()
+	client := stripe.New(server.Config("price_nodes"), nil)
+	ctx := context.Background()
+
+	server.AddInvoice(stripe.Invoice{Number: "INV-1", Customer: "cus_1", Total: 1000})
+	server.AddInvoice(stripe.Invoice{Number: "INV-2", Customer: "cus_2", Total: 2000})
+	server.AddInvoice(stripe.Invoice{Number: "INV-3", Customer: "cus_1", Total: 3000})
+
+	invoices, err := client.GetInvoices(ctx, "cus_1", 10)
+	require.NoError(t, err)
+	require.Len(t, invoices, 2)
+	assert.Equal(t, "INV-3", invoices[0].Number)
+	assert.Equal(t, "INV-1", invoices[1].Number)
+
+	invoices, err = client.GetInvoices(ctx, "cus_1", 1)
+	require.NoError(t, err)
+	require.Len(t, invoices, 1)
+}
@@ -0,0 +1,192 @@
+package stripe
+
+import "net/url"
+
+// Subscription statuses which still bill the customer.
+const (
+	SubscriptionActive   = "active"
+	SubscriptionTrialing = "trialing"
+	SubscriptionPastDue  = "past_due"
+)
+
+// Address is a customer's billing address.
+type Address struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+	State      string `json:"state"`
+	Country    string `json:"country"`
+}
+
+// Customer is a Stripe customer.
+type Customer struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Email    string            `json:"email"`
+	Phone    string            `json:"phone"`
+	Address  Address           `json:"address"`
+	Currency string            `json:"currency"`
+	Metadata map[string]string `json:"metadata"`
+	Deleted  bool              `json:"deleted"`
+
+	InvoiceSettings struct {
+		DefaultPaymentMethod string `json:"default_payment_method"`
+	} `json:"invoice_settings"`
+}
+
+// CustomerParams are the details of a customer to create or update. Empty
+// values are left as they are.
+type CustomerParams struct {
+	Name     string
+	Email    string
+	Phone    string
+	Address  *Address
+	Metadata map[string]string
+
+	// DefaultPaymentMethod is the payment method the customer's invoices are
+	// paid with.
+	DefaultPaymentMethod string
+}
+
+func (p CustomerParams) values() url.Values {
+	v := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	set("name", p.Name)
+	set("email", p.Email)
+	set("phone", p.Phone)
+	if p.Address != nil {
+		set("address[line1]", p.Address.Line1)
+		set("address[line2]", p.Address.Line2)
+		set("address[city]", p.Address.City)
+		set("address[postal_code]", p.Address.PostalCode)
+		set("address[state]", p.Address.State)
+		set("address[country]", p.Address.Country)
+	}
+	for key, value := range p.Metadata {
+		set("metadata["+key+"]", value)
+	}
+	set("invoice_settings[default_payment_method]", p.DefaultPaymentMethod)
+	return v
+}
+
+// Price is what a subscription item is billed at.
+type Price struct {
+	ID         string `json:"id"`
+	Currency   string `json:"currency"`
+	UnitAmount int64  `json:"unit_amount"`
+	// UnitAmountDecimal is the price per unit in cents, for prices below a cent.
+	UnitAmountDecimal string `json:"unit_amount_decimal"`
+	Recurring         struct {
+		Interval  string `json:"interval"`
+		UsageType string `json:"usage_type"`
+	} `json:"recurring"`
+}
+
+// SubscriptionItem is a price a customer is subscribed to.
+type SubscriptionItem struct {
+	ID    string `json:"id"`
+	Price Price  `json:"price"`
+}
+
+// Subscription is a customer's subscription to some prices.
+type Subscription struct {
+	ID                 string `json:"id"`
+	Customer           string `json:"customer"`
+	Status             string `json:"status"`
+	StartDate          int64  `json:"start_date"`
+	TrialEnd           int64  `json:"trial_end"`
+	CurrentPeriodStart int64  `json:"current_period_start"`
+	CurrentPeriodEnd   int64  `json:"current_period_end"`
+	Items              struct {
+		Data []SubscriptionItem `json:"data"`
+	} `json:"items"`
+}
+
+// IsActive returns whether the subscription still bills the customer.
+func (s *Subscription) IsActive() bool {
+	switch s.Status {
+	case SubscriptionActive, SubscriptionTrialing, SubscriptionPastDue:
+		return true
+	}
+	return false
+}
+
+// Item returns the subscription's item for the price, or nil if it has none.
+func (s *Subscription) Item(priceID string) *SubscriptionItem {
+	for i := range s.Items.Data {
+		if s.Items.Data[i].Price.ID == priceID {
+			return &s.Items.Data[i]
+		}
+	}
+	return nil
+}
+
+// Card describes a credit card, without its number.
+type Card struct {
+	Brand    string `json:"brand"`
+	Last4    string `json:"last4"`
+	ExpMonth int    `json:"exp_month"`
+	ExpYear  int    `json:"exp_year"`
+}
+
+// BillingDetails are those of the owner of a payment method.
+type BillingDetails struct {
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Phone   string  `json:"phone"`
+	Address Address `json:"address"`
+}
+
+// PaymentMethod is a way for a customer to pay, e.g. a card.
+type PaymentMethod struct {
+	ID             string         `json:"id"`
+	Type           string         `json:"type"`
+	Customer       string         `json:"customer"`
+	Card           *Card          `json:"card"`
+	BillingDetails BillingDetails `json:"billing_details"`
+}
+
+// InvoiceLine is a line of an invoice.
+type InvoiceLine struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Amount      int64  `json:"amount"`
+	Quantity    int64  `json:"quantity"`
+	Period      struct {
+		Start int64 `json:"start"`
+		End   int64 `json:"end"`
+	} `json:"period"`
+}
+
+// Invoice is a Stripe invoice. Amounts are in cents.
+type Invoice struct {
+	ID               string `json:"id"`
+	Number           string `json:"number"`
+	Customer         string `json:"customer"`
+	Status           string `json:"status"`
+	Currency         string `json:"currency"`
+	Created          int64  `json:"created"`
+	DueDate          int64  `json:"due_date"`
+	PeriodStart      int64  `json:"period_start"`
+	PeriodEnd        int64  `json:"period_end"`
+	Total            int64  `json:"total"`
+	AmountRemaining  int64  `json:"amount_remaining"`
+	InvoicePDF       string `json:"invoice_pdf"`
+	HostedInvoiceURL string `json:"hosted_invoice_url"`
+	Lines            struct {
+		Data []InvoiceLine `json:"data"`
+	} `json:"lines"`
+}
+
+// UsageRecord is usage reported for a metered subscription item.
+type UsageRecord struct {
+	ID               string `json:"id"`
+	SubscriptionItem string `json:"subscription_item"`
+	Quantity         int64  `json:"quantity"`
+	Timestamp        int64  `json:"timestamp"`
+}
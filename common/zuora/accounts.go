@@ -66,6 +66,23 @@ type AccountSubscription struct {
 	RatePlans             []SubscriptionRatePlan `json:"ratePlans"`
 }
 
+// ChargeNumbers returns the numbers of the subscription's charges, by unit of measure.
+func (s *AccountSubscription) ChargeNumbers() map[string]string {
+	charges := map[string]string{}
+	for _, plan := range s.RatePlans {
+		for _, charge := range plan.RatePlanCharges {
+			if charge.Uom != "" {
+				charges[charge.Uom] = charge.ChargeNumber
+			}
+		}
+	}
+	// The node-seconds charge may have a prefixed unit of measure, see extractNodeSecondsSubscription.
+	if s.ChargeNumber != "" {
+		charges[billing.UsageNodeSeconds] = s.ChargeNumber
+	}
+	return charges
+}
+
 // ToZuoraAccountNumber converts a weave organization ID to a Zuora Account Number.
 //
 // It takes the sha256, prefixes with `W`, and truncates it to 32 characters.
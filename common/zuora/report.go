@@ -12,6 +12,8 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/weaveworks/service/billing-api/db"
+	"github.com/weaveworks/service/common/billing/catalog"
+	"github.com/weaveworks/service/common/billing/provider"
 	timeutil "github.com/weaveworks/service/common/time"
 )
 
@@ -133,22 +135,29 @@ func (r *Report) Size() int {
 }
 
 type groupKey struct {
-	amountType string
+	unitType   string
 	bucketTime time.Time
 }
 
 // ReportFromAggregates groups usage by 'billing period' (monthly in our case) for Zuora to generate correct invoices.
-func ReportFromAggregates(config Config, aggs []db.Aggregate, paymentProviderID string, from, through time.Time, subscriptionNumber string, chargeNumber string, cycleDay int) (*Report, error) {
+// Aggregates are billed as the units of measure of their product in the catalog, to the subscription's charge
+// for that unit of measure, as in charges.
+func ReportFromAggregates(config Config, aggs []db.Aggregate, paymentProviderID string, from, through time.Time, subscriptionNumber string, charges map[string]string, cycleDay int) (*Report, error) {
 	r := NewReport(config)
 
-	// Sum them by (type,month).
+	// Sum them by (unit,month).
 	// This is because zuora requires usage to be grouped by month to issue invoices with charges corresponding to the correct period.
 	// `bucketTime` is used for grouping and is therefore part a common key to all report lines which belong in the same month.
 	groupedSums := map[groupKey]int64{}
 	for _, agg := range aggs {
+		unitType, ok := catalog.Default.Charge(provider.Zuora, agg.AmountType)
+		if !ok {
+			log.Warnf("Not reporting %v usage, which isn't billed through Zuora", agg.AmountType)
+			continue
+		}
 		// `bucketTime` is  bounded by `through`, but must be lower than `through` because intervals are [inclusive, exclusive)
 		bucketTime := timeutil.MinTime(timeutil.EndOfCycle(agg.BucketStart, cycleDay), timeutil.JustBefore(through))
-		key := groupKey{amountType: agg.AmountType, bucketTime: bucketTime}
+		key := groupKey{unitType: unitType, bucketTime: bucketTime}
 		groupedSums[key] += agg.AmountValue
 	}
 
@@ -160,10 +169,15 @@ func ReportFromAggregates(config Config, aggs []db.Aggregate, paymentProviderID
 	// Add this instance's sums to the report.
 	// O(n^2)
 	for key, amountValue := range groupedSums {
+		chargeNumber, ok := charges[key.unitType]
+		if !ok {
+			log.Errorf("Report line entry not added, subscription %v has no %v charge: %+v %v", subscriptionNumber, key.unitType, key, amountValue)
+			continue
+		}
 		added := false
 		for _, interval := range intervals {
 			if timeutil.InTimeRange(interval.From, interval.To, key.bucketTime) {
-				r.AddLineEntry(paymentProviderID, key.amountType, amountValue, interval.From, interval.To, subscriptionNumber, chargeNumber)
+				r.AddLineEntry(paymentProviderID, key.unitType, amountValue, interval.From, interval.To, subscriptionNumber, chargeNumber)
 				added = true
 				break
 			}
@@ -178,7 +192,8 @@ func ReportFromAggregates(config Config, aggs []db.Aggregate, paymentProviderID
 	return r, nil
 }
 
-// FilterAggregatesForSubscription removes aggregates are not billable for this subscription
+// FilterAggregatesForSubscription removes aggregates are not billable for this subscription, i.e. those whose
+// unit of measure in the catalog isn't charged by any of the subscription's products.
 func FilterAggregatesForSubscription(ctx context.Context, z Client, aggs []db.Aggregate, account *Account) ([]db.Aggregate, error) {
 	productIDs := []string{}
 	for _, ratePlan := range account.Subscription.RatePlans {
@@ -191,8 +206,11 @@ func FilterAggregatesForSubscription(ctx context.Context, z Client, aggs []db.Ag
 	}
 	results := []db.Aggregate{}
 	for _, a := range aggs {
-		_, supported := units[a.AmountType]
-		if supported {
+		unitType, ok := catalog.Default.Charge(provider.Zuora, a.AmountType)
+		if !ok {
+			continue
+		}
+		if _, supported := units[unitType]; supported {
 			results = append(results, a)
 		}
 	}
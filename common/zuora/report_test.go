@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/service/billing-api/db"
 )
 
 var (
@@ -111,3 +113,29 @@ func TestConcat(t *testing.T) {
 		t.Fatal("Entries missing")
 	}
 }
+
+func TestReportFromAggregates_Products(t *testing.T) {
+	start := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)
+	aggs := []db.Aggregate{
+		{BucketStart: start, AmountType: "node-seconds", AmountValue: 10},
+		{BucketStart: start.Add(time.Hour), AmountType: "node-seconds", AmountValue: 5},
+		{BucketStart: start, AmountType: "flux-release", AmountValue: 2},
+		{BucketStart: start, AmountType: "flux-autorelease", AmountValue: 3},
+		{BucketStart: start, AmountType: "samples", AmountValue: 100},
+		{BucketStart: start, AmountType: "flux-lock", AmountValue: 1},
+	}
+	// The subscription doesn't charge for samples.
+	charges := map[string]string{"node-seconds": "C-nodes", "deploys": "C-deploys"}
+	r, err := ReportFromAggregates(Config{}, aggs, "P1", start, start.Add(24*time.Hour), "S1", charges, 1)
+	assert.NoError(t, err)
+
+	lines := map[string]lineEntry{}
+	for _, e := range r.entries {
+		lines[e.unitType] = e
+	}
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "15", lines["node-seconds"].quantity)
+	assert.Equal(t, "C-nodes", lines["node-seconds"].chargeID)
+	assert.Equal(t, "5", lines["deploys"].quantity)
+	assert.Equal(t, "C-deploys", lines["deploys"].chargeID)
+}
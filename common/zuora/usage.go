@@ -30,6 +30,8 @@ type Usage struct {
 	UnitType  string  `json:"unitOfMeasure"`
 	Quantity  float64 `json:"quantity"`
 	Status    string  `json:"status"` // 'Importing'|'Pending'|'Processed'
+	// SourceName is the name of the file the usage was imported from.
+	SourceName string `json:"sourceName"`
 }
 
 type postUsageResponse struct {
@@ -59,6 +61,12 @@ var usageImportHistogram = prometheus.NewHistogramVec(
 	[]string{"status"},
 )
 
+// UsageFileName returns the name of the file usage uploaded with the id is
+// imported from.
+func UsageFileName(id string) string {
+	return fmt.Sprintf("u-%.44s.csv", id)
+}
+
 // UploadUsage uploads usage information to Zuora.
 func (z *Zuora) UploadUsage(ctx context.Context, r io.Reader, id string) (UsageUploadID, error) {
 	usage := bytes.Buffer{}
@@ -67,7 +75,7 @@ func (z *Zuora) UploadUsage(ctx context.Context, r io.Reader, id string) (UsageU
 	writer := multipart.NewWriter(body)
 	// This creates a new "part". I.e. a section in the multi-part upload.
 	// The word "file" is the name of the upload, and this is specified by zuora. The filename doesn't matter, but must not be null, and is limited to 50 chars!!
-	part, err := writer.CreateFormFile("file", fmt.Sprintf("u-%.44s.csv", id))
+	part, err := writer.CreateFormFile("file", UsageFileName(id))
 	if err != nil {
 		return "", err
 	}
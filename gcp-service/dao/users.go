@@ -1,10 +1,13 @@
 package dao
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -37,6 +40,12 @@ const (
 type UsersClient interface {
 	// GoogleOAuthToken returns the Google OAuth token for the specified user.
 	GoogleOAuthToken(userID string) (*oauth2.Token, error)
+	// ClusterProviderAccounts returns the accounts of the specified cluster
+	// provider which the user granted us access to.
+	ClusterProviderAccounts(userID, provider string) ([]string, error)
+	// AddClusterProviderAccount records that the user granted us access to an
+	// account of the specified cluster provider.
+	AddClusterProviderAccount(userID, provider, account string) error
 }
 
 // UsersHTTPClient is a HTTP implementation of UsersClient.
@@ -77,6 +86,65 @@ func (c UsersHTTPClient) GoogleOAuthToken(userID string) (*oauth2.Token, error)
 	return &oauth2.Token{AccessToken: token.AccessToken}, nil
 }
 
+// ClusterProviderAccounts returns the accounts of the specified cluster
+// provider which the user granted us access to.
+func (c UsersHTTPClient) ClusterProviderAccounts(userID, provider string) ([]string, error) {
+	logger := log.WithField("user_id", userID)
+	method := "GET /admin/users/users/{user}/cluster-accounts/{provider}"
+	start := time.Now()
+	clientRequestCollector.Before(method, start)
+
+	resp, err := http.Get(c.clusterProviderAccountsURL(userID, provider))
+	if err != nil {
+		clientRequestCollector.After(method, internalServerError, start)
+		logger.Errorf("Failed to get %s accounts from users service: %v", provider, err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	clientRequestCollector.After(method, strconv.Itoa(resp.StatusCode), start)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("users service returned %s", resp.Status)
+	}
+	var accounts struct {
+		Accounts []string `json:"accounts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&accounts); err != nil {
+		logger.Errorf("Failed to deserialise %s accounts from users service: %v", provider, err)
+		return nil, err
+	}
+	return accounts.Accounts, nil
+}
+
+// AddClusterProviderAccount records that the user granted us access to an
+// account of the specified cluster provider.
+func (c UsersHTTPClient) AddClusterProviderAccount(userID, provider, account string) error {
+	logger := log.WithField("user_id", userID)
+	method := "POST /admin/users/users/{user}/cluster-accounts/{provider}"
+	start := time.Now()
+	clientRequestCollector.Before(method, start)
+
+	body, err := json.Marshal(map[string]string{"account": account})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(c.clusterProviderAccountsURL(userID, provider), "application/json", bytes.NewReader(body))
+	if err != nil {
+		clientRequestCollector.After(method, internalServerError, start)
+		logger.Errorf("Failed to add %s account to users service: %v", provider, err)
+		return err
+	}
+	defer resp.Body.Close()
+	clientRequestCollector.After(method, strconv.Itoa(resp.StatusCode), start)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("users service returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (c UsersHTTPClient) clusterProviderAccountsURL(userID, provider string) string {
+	return fmt.Sprintf("%s/admin/users/users/%s/cluster-accounts/%s", c.UsersHostPort, url.PathEscape(userID), url.PathEscape(provider))
+}
+
 // UsersNoOpClient is a no-op implementation of UsersClient.
 // This implementation is mostly useful for testing.
 type UsersNoOpClient struct {
@@ -86,3 +154,13 @@ type UsersNoOpClient struct {
 func (c UsersNoOpClient) GoogleOAuthToken(userID string) (*oauth2.Token, error) {
 	return &oauth2.Token{AccessToken: "<token>"}, nil
 }
+
+// ClusterProviderAccounts returns no accounts and a nil error.
+func (c UsersNoOpClient) ClusterProviderAccounts(userID, provider string) ([]string, error) {
+	return []string{}, nil
+}
+
+// AddClusterProviderAccount does nothing and returns a nil error.
+func (c UsersNoOpClient) AddClusterProviderAccount(userID, provider, account string) error {
+	return nil
+}
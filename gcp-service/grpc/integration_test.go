@@ -29,6 +29,10 @@ var expectedCluster = &grpc.Cluster{
 	ClusterID:         "gke-integration",
 	Zone:              "us-central1-a",
 	KubernetesVersion: "1.8.5-gke.0",
+	Provider:          "gke",
+	Account:           "gke-integration",
+	Region:            "us-central1-a",
+	Endpoint:          "35.184.163.242",
 }
 
 func TestGetClusters(t *testing.T) {
@@ -56,6 +60,26 @@ func TestGetClustersForProject(t *testing.T) {
 	assert.Equal(t, expectedCluster, reply.Clusters[0])
 }
 
+func TestGetClustersForProvider(t *testing.T) {
+	client := newClient(t)
+	defer client.Close()
+	reply, err := client.GetClusters(context.Background(), &grpc.ClustersRequest{
+		UserID:   "123456",
+		Provider: "eks",
+		Account:  "arn:aws:iam::123456789012:role/weave-cloud",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(reply.Clusters))
+	assert.Equal(t, "eks", reply.Clusters[0].Provider)
+	assert.Equal(t, "eks-integration", reply.Clusters[0].ClusterID)
+
+	_, err = client.GetClusters(context.Background(), &grpc.ClustersRequest{
+		UserID:   "123456",
+		Provider: "openshift",
+	})
+	assert.Error(t, err)
+}
+
 func TestRunKubectlCmd(t *testing.T) {
 	client := newClient(t)
 	defer client.Close()
@@ -83,6 +107,17 @@ func TestInstallWeaveCloud(t *testing.T) {
 	})
 	assert.NoError(t, err)
 	assert.NotNil(t, reply)
+
+	reply, err = client.InstallWeaveCloud(context.Background(), &grpc.InstallWeaveCloudRequest{
+		UserID:          "123456",
+		Provider:        "aks",
+		Account:         "tenant/subscription",
+		Region:          "westeurope",
+		ClusterID:       "weave/aks-integration",
+		WeaveCloudToken: "abc123",
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, reply)
 }
 
 func newClient(t *testing.T) *grpc.Client {
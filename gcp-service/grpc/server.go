@@ -3,6 +3,7 @@ package grpc
 import (
 	"golang.org/x/net/context"
 
+	"github.com/weaveworks/service/gcp-service/provider"
 	"github.com/weaveworks/service/gcp-service/service"
 )
 
@@ -11,26 +12,45 @@ type Server struct {
 	Service *service.Service
 }
 
-// GetClusters returns all the GKE clusters belonging to the provided user.
+// GetClusters returns all the clusters belonging to the provided user, with the requested provider.
 func (s Server) GetClusters(ctx context.Context, req *ClustersRequest) (*ClustersReply, error) {
-	allClusters, err := s.Service.GetClusters(ctx, req.UserID)
+	allClusters, err := s.Service.ListClusters(ctx, req.Provider, req.UserID, req.Account)
 	if err != nil {
 		return nil, err
 	}
+	return toClustersReply(allClusters), nil
+}
+
+func toClustersReply(allClusters []*provider.Cluster) *ClustersReply {
 	var clusters []*Cluster
 	for _, cluster := range allClusters {
 		clusters = append(clusters, toProtobuf(cluster))
 	}
-	return &ClustersReply{Clusters: clusters}, nil
+	return &ClustersReply{Clusters: clusters}
 }
 
-func toProtobuf(cluster *service.Cluster) *Cluster {
+func toProtobuf(cluster *provider.Cluster) *Cluster {
 	return &Cluster{
 		ProjectID:         cluster.ProjectID,
 		Zone:              cluster.Zone,
 		ClusterID:         cluster.ClusterID,
 		KubernetesVersion: cluster.KubernetesVersion,
+		Provider:          cluster.Provider,
+		Account:           cluster.Account,
+		Region:            cluster.Region,
+		Endpoint:          cluster.Endpoint,
+	}
+}
+
+// clusterRef identifies a cluster from a request, which may use GKE's terms for its account and region.
+func clusterRef(account, projectID, region, zone, clusterID string) provider.ClusterRef {
+	if account == "" {
+		account = projectID
+	}
+	if region == "" {
+		region = zone
 	}
+	return provider.ClusterRef{Account: account, Region: region, ClusterID: clusterID}
 }
 
 // GetProjects returns all the GCP projects belonging to the provided user.
@@ -42,22 +62,23 @@ func (s Server) GetProjects(ctx context.Context, req *ProjectsRequest) (*Project
 	return &ProjectsReply{ProjectIDs: projectIDs}, nil
 }
 
-// GetClustersForProject returns all the GKE clusters belonging to the provided user in the specified project.
+// GetClustersForProject returns all the clusters belonging to the provided user in the specified project, or account.
 func (s Server) GetClustersForProject(ctx context.Context, req *ClustersRequest) (*ClustersReply, error) {
-	allClusters, err := s.Service.GetClustersForProject(ctx, req.UserID, req.ProjectID)
+	account := req.Account
+	if account == "" {
+		account = req.ProjectID
+	}
+	allClusters, err := s.Service.ListClusters(ctx, req.Provider, req.UserID, account)
 	if err != nil {
 		return nil, err
 	}
-	var clusters []*Cluster
-	for _, cluster := range allClusters {
-		clusters = append(clusters, toProtobuf(cluster))
-	}
-	return &ClustersReply{Clusters: clusters}, nil
+	return toClustersReply(allClusters), nil
 }
 
 // RunKubectlCmd executes the provided kubectl command against the specified cluster.
 func (s Server) RunKubectlCmd(ctx context.Context, req *KubectlCmdRequest) (*KubectlCmdReply, error) {
-	out, err := s.Service.RunKubectlCmd(ctx, req.UserID, req.ProjectID, req.Zone, req.ClusterID, req.Args)
+	ref := clusterRef(req.Account, req.ProjectID, req.Region, req.Zone, req.ClusterID)
+	out, err := s.Service.RunKubectlCmd(ctx, req.Provider, req.UserID, ref, req.Args)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +87,8 @@ func (s Server) RunKubectlCmd(ctx context.Context, req *KubectlCmdRequest) (*Kub
 
 // InstallWeaveCloud installs Weave Cloud against the specified cluster.
 func (s Server) InstallWeaveCloud(ctx context.Context, req *InstallWeaveCloudRequest) (*Empty, error) {
-	err := s.Service.InstallWeaveCloud(ctx, req.UserID, req.ProjectID, req.Zone, req.ClusterID, req.WeaveCloudToken)
+	ref := clusterRef(req.Account, req.ProjectID, req.Region, req.Zone, req.ClusterID)
+	err := s.Service.InstallWeaveCloud(ctx, req.Provider, req.UserID, ref, req.WeaveCloudToken)
 	if err != nil {
 		return nil, err
 	}
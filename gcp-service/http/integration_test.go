@@ -11,7 +11,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/weaveworks/service/gcp-service/service"
+	"github.com/weaveworks/service/gcp-service/provider"
 )
 
 func TestGetProjects(t *testing.T) {
@@ -41,6 +41,33 @@ func TestGetClustersForProject(t *testing.T) {
 	assert.Equal(t, "1.8.5-gke.0", cluster.KubernetesVersion)
 }
 
+func TestGetProviderClusters(t *testing.T) {
+	resp := get(t, "/api/gcp/users/123456/providers/eks/clusters?account=arn:aws:iam::123456789012:role/weave-cloud")
+	clusters := deserializeJSON(t, resp)
+	assert.Equal(t, 1, len(clusters))
+	cluster := clusters[0]
+	assert.Equal(t, "eks", cluster.Provider)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/weave-cloud", cluster.Account)
+	assert.Equal(t, "eks-integration", cluster.ClusterID)
+	assert.Equal(t, "", cluster.ProjectID)
+
+	resp = get(t, "/api/gcp/users/123456/providers/gke/clusters")
+	clusters = deserializeJSON(t, resp)
+	assert.Equal(t, 1, len(clusters))
+	assert.Equal(t, "gke", clusters[0].Provider)
+	assert.Equal(t, "us-central1-a", clusters[0].Region)
+}
+
+func TestRunProviderKubectlCmd(t *testing.T) {
+	resp := post(t, "/api/gcp/users/123456/providers/aks/kubectl", map[string]interface{}{
+		"account":   "tenant/subscription",
+		"region":    "westeurope",
+		"clusterId": "weave/aks-integration",
+		"args":      []string{"get", "pods", "--all-namespaces"},
+	})
+	assert.Equal(t, "\"Dry run: kubectl get pods --all-namespaces (1.17.5)\"\n", resp)
+}
+
 func TestRunKubectlCmd(t *testing.T) {
 	resp := post(t, "/api/gcp/users/123456/projects/gke-integration/clusters/gke-integration/zones/us-central1-a/kubectl", []string{"get", "pods", "--all-namespaces"})
 	assert.Equal(t, "\"Dry run: kubectl get pods --all-namespaces (1.8.5-gke.0)\"\n", resp)
@@ -74,8 +101,8 @@ func read(t *testing.T, resp *http.Response, err error) string {
 	return string(body)
 }
 
-func deserializeJSON(t *testing.T, jsonStr string) []*service.Cluster {
-	var clusters []*service.Cluster
+func deserializeJSON(t *testing.T, jsonStr string) []*provider.Cluster {
+	var clusters []*provider.Cluster
 	err := json.Unmarshal([]byte(jsonStr), &clusters)
 	assert.NoError(t, err)
 	return clusters
@@ -6,8 +6,11 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
+	"github.com/weaveworks/common/user"
 	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/gcp-service/provider"
 	gcprender "github.com/weaveworks/service/gcp-service/render"
 	"github.com/weaveworks/service/gcp-service/service"
 
@@ -25,6 +28,7 @@ const (
 	ProjectID = "projectID"
 	Zone      = "zone"
 	ClusterID = "clusterID"
+	Provider  = "provider"
 )
 
 // RegisterRoutes registers the users API HTTP routes to the provided Router.
@@ -47,6 +51,21 @@ func (s Server) RegisterRoutes(r *mux.Router) {
 
 		// Install Weave Cloud to the specified GKE cluster (belonging to the specified user, and existing in the specified zone):
 		{"users_projects_clusters_zones_install", "POST", fmt.Sprintf("/api/gcp/users/{%v}/projects/{%v}/clusters/{%v}/zones/{%v}/install", UserID, ProjectID, ClusterID, Zone), s.InstallWeaveCloud},
+
+		// Get the specified user's clusters with the specified provider (gke, eks or aks), within the account given as a query parameter:
+		{"users_providers_clusters", "GET", fmt.Sprintf("/api/gcp/users/{%v}/providers/{%v}/clusters", UserID, Provider), s.ListProviderClusters},
+
+		// Run the provided kubectl command against the cluster specified in the request's body, with the specified provider:
+		{"users_providers_kubectl", "POST", fmt.Sprintf("/api/gcp/users/{%v}/providers/{%v}/kubectl", UserID, Provider), s.RunProviderKubectlCmd},
+
+		// Install Weave Cloud to the cluster specified in the request's body, with the specified provider:
+		{"users_providers_install", "POST", fmt.Sprintf("/api/gcp/users/{%v}/providers/{%v}/install", UserID, Provider), s.InstallProviderWeaveCloud},
+
+		// Send the authenticated user to grant us access to the account given as a query parameter, with the specified provider:
+		{"providers_consent", "GET", fmt.Sprintf("/api/gcp/providers/{%v}/consent", Provider), s.StartProviderConsent},
+
+		// Record the account the authenticated user granted us access to, as the specified provider sends them back to us:
+		{"providers_consent_callback", "GET", fmt.Sprintf("/api/gcp/providers/{%v}/consent/callback", Provider), s.FinishProviderConsent},
 	} {
 		r.Handle(route.path, route.handler).Methods(route.method).Name(route.name)
 	}
@@ -55,7 +74,7 @@ func (s Server) RegisterRoutes(r *mux.Router) {
 // GetClusters returns all the GKE clusters belonging to the provided user in the specified project.
 func (s Server) GetClusters(w http.ResponseWriter, r *http.Request) {
 	userID := mux.Vars(r)[UserID]
-	clusters, err := s.Service.GetClusters(r.Context(), userID)
+	clusters, err := s.Service.ListClusters(r.Context(), provider.GKE, userID, "")
 	if err != nil {
 		render.Error(w, r, err, gcprender.ErrorStatusCode)
 	} else {
@@ -78,7 +97,7 @@ func (s Server) GetProjects(w http.ResponseWriter, r *http.Request) {
 func (s Server) GetClustersForProject(w http.ResponseWriter, r *http.Request) {
 	userID := mux.Vars(r)[UserID]
 	projectID := mux.Vars(r)[ProjectID]
-	clusters, err := s.Service.GetClustersForProject(r.Context(), userID, projectID)
+	clusters, err := s.Service.ListClusters(r.Context(), provider.GKE, userID, projectID)
 	if err != nil {
 		render.Error(w, r, err, gcprender.ErrorStatusCode)
 	} else {
@@ -96,7 +115,8 @@ func (s Server) RunKubectlCmd(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		render.Error(w, r, err, gcprender.ErrorStatusCode)
 	}
-	out, err := s.Service.RunKubectlCmd(r.Context(), userID, projectID, zone, clusterID, args)
+	ref := provider.ClusterRef{Account: projectID, Region: zone, ClusterID: clusterID}
+	out, err := s.Service.RunKubectlCmd(r.Context(), provider.GKE, userID, ref, args)
 	if err != nil {
 		render.Error(w, r, err, gcprender.ErrorStatusCode)
 	} else {
@@ -131,10 +151,9 @@ func (s Server) InstallWeaveCloud(w http.ResponseWriter, r *http.Request) {
 
 	err := s.Service.InstallWeaveCloud(
 		r.Context(),
+		provider.GKE,
 		userID,
-		projectID,
-		zone,
-		clusterID,
+		provider.ClusterRef{Account: projectID, Region: zone, ClusterID: clusterID},
 		payload.WeaveCloudToken,
 	)
 	if err != nil {
@@ -142,3 +161,116 @@ func (s Server) InstallWeaveCloud(w http.ResponseWriter, r *http.Request) {
 	}
 	render.JSON(w, http.StatusOK, nil)
 }
+
+// ListProviderClusters returns the clusters belonging to the provided user with the specified provider.
+func (s Server) ListProviderClusters(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)[UserID]
+	providerName := mux.Vars(r)[Provider]
+	account := r.URL.Query().Get("account")
+	clusters, err := s.Service.ListClusters(r.Context(), providerName, userID, account)
+	if err != nil {
+		render.Error(w, r, err, gcprender.ErrorStatusCode)
+	} else {
+		render.JSON(w, http.StatusOK, clusters)
+	}
+}
+
+// clusterPayload identifies a cluster in the body of requests, as accounts
+// and clusters' IDs may contain slashes.
+type clusterPayload struct {
+	Account   string `json:"account"`
+	Region    string `json:"region"`
+	ClusterID string `json:"clusterId"`
+}
+
+func (p clusterPayload) ref() provider.ClusterRef {
+	return provider.ClusterRef{Account: p.Account, Region: p.Region, ClusterID: p.ClusterID}
+}
+
+// RunProviderKubectlCmd executes the provided kubectl command against the specified cluster.
+func (s Server) RunProviderKubectlCmd(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)[UserID]
+	providerName := mux.Vars(r)[Provider]
+	var payload struct {
+		clusterPayload
+		Args []string `json:"args"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		render.Error(w, r, err, gcprender.ErrorStatusCode)
+		return
+	}
+	out, err := s.Service.RunKubectlCmd(r.Context(), providerName, userID, payload.ref(), payload.Args)
+	if err != nil {
+		render.Error(w, r, err, gcprender.ErrorStatusCode)
+	} else {
+		render.JSON(w, http.StatusOK, out)
+	}
+}
+
+// InstallProviderWeaveCloud installs Weave Cloud to the specified cluster.
+func (s Server) InstallProviderWeaveCloud(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)[UserID]
+	providerName := mux.Vars(r)[Provider]
+	var payload struct {
+		clusterPayload
+		WeaveCloudToken string `json:"weaveCloudToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		render.Error(w, r, err, gcprender.ErrorStatusCode)
+		return
+	}
+	if err := s.Service.InstallWeaveCloud(r.Context(), providerName, userID, payload.ref(), payload.WeaveCloudToken); err != nil {
+		render.Error(w, r, err, gcprender.ErrorStatusCode)
+	} else {
+		render.JSON(w, http.StatusOK, nil)
+	}
+}
+
+// StartProviderConsent redirects the user to grant us access to an account with the specified provider.
+// Users are identified by authfe, as the provider sends them back to a fixed URL.
+func (s Server) StartProviderConsent(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get(user.UserIDHeaderName)
+	if userID == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	providerName := mux.Vars(r)[Provider]
+	query := r.URL.Query()
+	consentURL, err := s.Service.ConsentURL(providerName, userID, query.Get("account"), localPath(query.Get("next")))
+	if err != nil {
+		render.Error(w, r, err, gcprender.ErrorStatusCode)
+		return
+	}
+	http.Redirect(w, r, consentURL, http.StatusFound)
+}
+
+// FinishProviderConsent records the account the user granted us access to with the specified provider, and redirects them to where they started from.
+func (s Server) FinishProviderConsent(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get(user.UserIDHeaderName)
+	if userID == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	providerName := mux.Vars(r)[Provider]
+	query := r.URL.Query()
+	if query.Get("code") == "" {
+		// Users declined, or the provider failed
+		render.Error(w, r, provider.ErrInvalidConsent, gcprender.ErrorStatusCode)
+		return
+	}
+	next, err := s.Service.Consent(r.Context(), providerName, userID, query.Get("code"), query.Get("state"))
+	if err != nil {
+		render.Error(w, r, err, gcprender.ErrorStatusCode)
+		return
+	}
+	http.Redirect(w, r, next, http.StatusFound)
+}
+
+// localPath returns next if it's a path on our own host, so that consent
+// can't redirect users elsewhere, and "/" otherwise.
+func localPath(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.Contains(next, "\\") {
+		return "/"
+	}
+	return next
+}
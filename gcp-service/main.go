@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 
 	log "github.com/sirupsen/logrus"
@@ -15,6 +16,7 @@ import (
 	"github.com/weaveworks/service/gcp-service/dao"
 	"github.com/weaveworks/service/gcp-service/grpc"
 	"github.com/weaveworks/service/gcp-service/http"
+	"github.com/weaveworks/service/gcp-service/provider"
 	"github.com/weaveworks/service/gcp-service/render"
 	"github.com/weaveworks/service/gcp-service/service"
 	kubectl "github.com/weaveworks/service/kubectl-service/grpc"
@@ -33,9 +35,13 @@ func main() {
 		dryRun        = flag.Bool("dry-run", false, "Do NOT actually run DAO calls, but mock them and return arbitrary values.")
 		usersConfig   users.Config
 		kubectlConfig kubectl.Config
+		eksConfig     provider.EKSConfig
+		aksConfig     provider.AKSConfig
 	)
 	usersConfig.RegisterFlags(flag.CommandLine)
 	kubectlConfig.RegisterFlags(flag.CommandLine)
+	eksConfig.RegisterFlags(flag.CommandLine)
+	aksConfig.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
 	if err := logging.Setup(serverConfig.LogLevel.String()); err != nil {
@@ -57,10 +63,14 @@ func main() {
 	}
 	defer kubectlClient.Close()
 
+	providers, err := newProviders(*dryRun, usersConfig, eksConfig, aksConfig)
+	if err != nil {
+		log.Fatalf("Failed to create cluster providers: %v", err)
+	}
+
 	svc := &service.Service{
-		UsersClient:      newUsersClient(*dryRun, usersConfig),
-		KubectlClient:    kubectlClient,
-		GKEClientFactory: newGKEClientFactory(*dryRun),
+		KubectlClient: kubectlClient,
+		Providers:     providers,
 	}
 
 	hserv := &http.Server{Service: svc}
@@ -81,6 +91,35 @@ func newKubectlClient(dryRun bool, kubectlConfig kubectl.Config) (kubectl.Closea
 	return kubectl.NewClient(kubectlConfig)
 }
 
+func newProviders(dryRun bool, usersConfig users.Config, eksConfig provider.EKSConfig, aksConfig provider.AKSConfig) (provider.Providers, error) {
+	usersClient := newUsersClient(dryRun, usersConfig)
+	providers := provider.Providers{
+		provider.GKE: provider.GKEProvider{
+			UsersClient:   usersClient,
+			ClientFactory: newGKEClientFactory(dryRun),
+		},
+	}
+	if dryRun {
+		providers[provider.EKS] = provider.NoOpProvider{Name: provider.EKS}
+		providers[provider.AKS] = provider.NoOpProvider{Name: provider.AKS}
+		return providers, nil
+	}
+	if eksConfig.Enabled {
+		eks, err := provider.NewEKSProvider(eksConfig)
+		if err != nil {
+			return nil, err
+		}
+		providers[provider.EKS] = eks
+	}
+	if aksConfig.ClientID != "" {
+		if aksConfig.StateSecret == "" {
+			return nil, errors.New("-aks.state-secret is required with -aks.client-id")
+		}
+		providers[provider.AKS] = provider.NewAKSProvider(aksConfig, usersClient)
+	}
+	return providers, nil
+}
+
 func newUsersClient(dryRun bool, usersConfig users.Config) dao.UsersClient {
 	if dryRun {
 		log.Infof("Dry run mode activated: no call will actually be made to the users service.")
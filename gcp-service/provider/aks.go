@@ -0,0 +1,370 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/weaveworks/common/instrument"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/weaveworks/service/gcp-service/dao"
+)
+
+// Prometheus metrics for AKS API client.
+var aksRequestCollector = instrument.NewHistogramCollectorFromOpts(prometheus.HistogramOpts{
+	Namespace: "azure",
+	Subsystem: "aks_client",
+	Name:      "request_duration_seconds",
+	Help:      "Response time of Azure Kubernetes Service API requests.",
+	Buckets:   prometheus.DefBuckets,
+})
+
+func init() {
+	aksRequestCollector.Register()
+}
+
+const (
+	aksAPIVersion              = "2020-03-01"
+	aksSubscriptionsAPIVersion = "2020-01-01"
+	aksConsentTimeout          = 10 * time.Minute
+)
+
+// AKSConfig configures access to Azure Kubernetes Service.
+type AKSConfig struct {
+	// ClientID and ClientSecret are those of our multi-tenant Azure AD application.
+	ClientID      string
+	ClientSecret  string
+	LoginURL      string
+	ManagementURL string
+	// RedirectURL is our consent callback, as registered with the application.
+	RedirectURL string
+	StateSecret string
+}
+
+// RegisterFlags registers configuration variables.
+func (c *AKSConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&c.ClientID, "aks.client-id", "", "ID of the Azure AD application users grant access to their AKS clusters. AKS clusters aren't found if empty.")
+	f.StringVar(&c.ClientSecret, "aks.client-secret", "", "Secret of the Azure AD application.")
+	f.StringVar(&c.LoginURL, "aks.login-url", "https://login.microsoftonline.com", "URL of Azure AD.")
+	f.StringVar(&c.ManagementURL, "aks.management-url", "https://management.azure.com", "URL of the Azure Resource Manager API.")
+	f.StringVar(&c.RedirectURL, "aks.redirect-url", "", "URL Azure AD sends users back to once they've granted access to a subscription, e.g. https://cloud.weave.works/api/gcp/providers/aks/consent/callback")
+	f.StringVar(&c.StateSecret, "aks.state-secret", "", "Secret with which to sign the state of consent requests.")
+}
+
+// AKSProvider finds Azure Kubernetes Service clusters, as our Azure AD
+// application, once users have granted it access to their subscriptions.
+// Accounts are "<tenant ID>/<subscription ID>", regions are locations, and
+// clusters are identified by "<resource group>/<name>".
+// Users only find clusters in the subscriptions they consented for, as our
+// application may have been granted access to others in the same tenant.
+type AKSProvider struct {
+	cfg   AKSConfig
+	users dao.UsersClient
+}
+
+// NewAKSProvider creates a provider for AKS clusters, which records the
+// subscriptions users grant access to with the users service.
+func NewAKSProvider(cfg AKSConfig, users dao.UsersClient) *AKSProvider {
+	return &AKSProvider{cfg: cfg, users: users}
+}
+
+type aksCluster struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Location   string `json:"location"`
+	Properties struct {
+		KubernetesVersion string `json:"kubernetesVersion"`
+		FQDN              string `json:"fqdn"`
+	} `json:"properties"`
+}
+
+// ListClusters returns the AKS clusters in the specified subscription.
+func (p AKSProvider) ListClusters(ctx context.Context, userID, account string) ([]*Cluster, error) {
+	tenantID, subscriptionID, err := parseAKSAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.authorize(userID, account); err != nil {
+		return nil, err
+	}
+	client := p.client(ctx, tenantID)
+	clusters := []*Cluster{}
+	next := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.ContainerService/managedClusters?api-version=%s", p.cfg.ManagementURL, url.PathEscape(subscriptionID), aksAPIVersion)
+	for next != "" {
+		var page struct {
+			Value    []aksCluster `json:"value"`
+			NextLink string       `json:"nextLink"`
+		}
+		if err := p.do(ctx, client, "GET", "/managedClusters", next, &page); err != nil {
+			return nil, err
+		}
+		for i := range page.Value {
+			clusters = append(clusters, summarizeAKS(account, &page.Value[i]))
+		}
+		next = page.NextLink
+	}
+	return clusters, nil
+}
+
+// GetCluster returns the specified AKS cluster, and its admin kubeconfig.
+func (p AKSProvider) GetCluster(ctx context.Context, userID string, ref ClusterRef) (*Cluster, []byte, error) {
+	tenantID, subscriptionID, err := parseAKSAccount(ref.Account)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := p.authorize(userID, ref.Account); err != nil {
+		return nil, nil, err
+	}
+	parts := strings.Split(ref.ClusterID, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, nil, ErrClusterNotFound
+	}
+	client := p.client(ctx, tenantID)
+	clusterURL := fmt.Sprintf("%s/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/%s",
+		p.cfg.ManagementURL, url.PathEscape(subscriptionID), url.PathEscape(parts[0]), url.PathEscape(parts[1]))
+
+	var cluster aksCluster
+	if err := p.do(ctx, client, "GET", "/managedClusters/{name}", clusterURL+"?api-version="+aksAPIVersion, &cluster); err != nil {
+		return nil, nil, err
+	}
+	var creds struct {
+		Kubeconfigs []struct {
+			Value string `json:"value"`
+		} `json:"kubeconfigs"`
+	}
+	if err := p.do(ctx, client, "POST", "/managedClusters/{name}/listClusterAdminCredential", clusterURL+"/listClusterAdminCredential?api-version="+aksAPIVersion, &creds); err != nil {
+		return nil, nil, err
+	}
+	if len(creds.Kubeconfigs) == 0 {
+		return nil, nil, &APIError{Provider: AKS, StatusCode: http.StatusOK, Message: "no kubeconfig returned"}
+	}
+	kubeCfg, err := base64.StdEncoding.DecodeString(creds.Kubeconfigs[0].Value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return summarizeAKS(ref.Account, &cluster), kubeCfg, nil
+}
+
+// ConsentURL returns where to send the user to grant our application access
+// to the specified subscription.
+func (p AKSProvider) ConsentURL(userID, subscriptionID, next string) (string, error) {
+	if subscriptionID == "" {
+		return "", ErrAccountRequired
+	}
+	if strings.Contains(subscriptionID, "/") {
+		return "", &InvalidAccountError{Account: subscriptionID, Expected: "<subscription ID>"}
+	}
+	state, err := p.signState(aksConsentState{
+		UserID:         userID,
+		SubscriptionID: subscriptionID,
+		Next:           next,
+		Expires:        time.Now().Add(aksConsentTimeout).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return p.oauth2Config().AuthCodeURL(state), nil
+}
+
+// Consent checks the user has access to the subscription they consented for,
+// with the token Azure AD issues them for our application, and records it.
+func (p AKSProvider) Consent(ctx context.Context, userID, code, state string) (string, error) {
+	s, err := p.verifyState(state)
+	if err != nil || s.UserID != userID {
+		return "", ErrInvalidConsent
+	}
+	token, err := p.oauth2Config().Exchange(ctx, code)
+	if err != nil {
+		if _, ok := err.(*oauth2.RetrieveError); ok {
+			return "", ErrInvalidConsent
+		}
+		return "", err
+	}
+	client := oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
+	client.Timeout = 30 * time.Second
+	var subscription struct {
+		SubscriptionID string `json:"subscriptionId"`
+		TenantID       string `json:"tenantId"`
+	}
+	subscriptionURL := fmt.Sprintf("%s/subscriptions/%s?api-version=%s", p.cfg.ManagementURL, url.PathEscape(s.SubscriptionID), aksSubscriptionsAPIVersion)
+	if err := p.do(ctx, client, "GET", "/subscriptions/{id}", subscriptionURL, &subscription); err != nil {
+		if err == ErrClusterNotFound {
+			// Users can't see subscriptions they don't have access to
+			return "", ErrAccountNotGranted
+		}
+		return "", err
+	}
+	if !strings.EqualFold(subscription.SubscriptionID, s.SubscriptionID) || subscription.TenantID == "" {
+		return "", ErrAccountNotGranted
+	}
+	if err := p.users.AddClusterProviderAccount(userID, AKS, subscription.TenantID+"/"+subscription.SubscriptionID); err != nil {
+		return "", err
+	}
+	return s.Next, nil
+}
+
+// authorize checks the user granted us access to the account.
+func (p AKSProvider) authorize(userID, account string) error {
+	accounts, err := p.users.ClusterProviderAccounts(userID, AKS)
+	if err != nil {
+		return err
+	}
+	for _, granted := range accounts {
+		if strings.EqualFold(granted, account) {
+			return nil
+		}
+	}
+	return ErrAccountNotGranted
+}
+
+// oauth2Config returns the configuration of our application, to issue tokens
+// to users of any organisation's Azure AD.
+func (p AKSProvider) oauth2Config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   p.cfg.LoginURL + "/organizations/oauth2/v2.0/authorize",
+			TokenURL:  p.cfg.LoginURL + "/organizations/oauth2/v2.0/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		RedirectURL: p.cfg.RedirectURL,
+		Scopes:      []string{p.cfg.ManagementURL + "/user_impersonation"},
+	}
+}
+
+// aksConsentState is what we need to know about users when Azure AD sends
+// them back to us.
+type aksConsentState struct {
+	UserID         string `json:"userId"`
+	SubscriptionID string `json:"subscriptionId"`
+	Next           string `json:"next,omitempty"`
+	Expires        int64  `json:"expires"`
+}
+
+func (p AKSProvider) signState(s aksConsentState) (string, error) {
+	j, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(j)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(p.stateMAC(payload)), nil
+}
+
+func (p AKSProvider) verifyState(state string) (*aksConsentState, error) {
+	parts := strings.Split(state, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidConsent
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(mac, p.stateMAC(parts[0])) {
+		return nil, ErrInvalidConsent
+	}
+	j, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidConsent
+	}
+	var s aksConsentState
+	if err := json.Unmarshal(j, &s); err != nil || time.Now().Unix() > s.Expires {
+		return nil, ErrInvalidConsent
+	}
+	return &s, nil
+}
+
+func (p AKSProvider) stateMAC(payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(p.cfg.StateSecret))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// client returns an HTTP client authenticated as our application in the user's tenant.
+func (p AKSProvider) client(ctx context.Context, tenantID string) *http.Client {
+	cfg := clientcredentials.Config{
+		ClientID:       p.cfg.ClientID,
+		ClientSecret:   p.cfg.ClientSecret,
+		TokenURL:       fmt.Sprintf("%s/%s/oauth2/token", p.cfg.LoginURL, url.PathEscape(tenantID)),
+		EndpointParams: url.Values{"resource": {p.cfg.ManagementURL + "/"}},
+		AuthStyle:      oauth2.AuthStyleInParams,
+	}
+	client := cfg.Client(ctx)
+	client.Timeout = 30 * time.Second
+	return client
+}
+
+// do sends a request to the Azure Resource Manager API, and decodes its reply into result.
+func (p AKSProvider) do(ctx context.Context, client *http.Client, verb, route, u string, result interface{}) error {
+	method := verb + " " + route
+	start := time.Now()
+	aksRequestCollector.Before(method, start)
+
+	req, err := http.NewRequest(verb, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		aksRequestCollector.After(method, "500", start)
+		return err
+	}
+	defer resp.Body.Close()
+	aksRequestCollector.After(method, strconv.Itoa(resp.StatusCode), start)
+	if resp.StatusCode/100 != 2 {
+		var reply struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&reply)
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrClusterNotFound
+		}
+		return &APIError{Provider: AKS, StatusCode: resp.StatusCode, Message: reply.Error.Message}
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func parseAKSAccount(account string) (string, string, error) {
+	if account == "" {
+		return "", "", ErrAccountRequired
+	}
+	parts := strings.Split(account, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", &InvalidAccountError{Account: account, Expected: "<tenant ID>/<subscription ID>"}
+	}
+	return parts[0], parts[1], nil
+}
+
+// aksResourceGroup returns the resource group of a cluster, from its ID, e.g.
+// /subscriptions/<subscription ID>/resourceGroups/<resource group>/providers/Microsoft.ContainerService/managedClusters/<name>
+func aksResourceGroup(id string) string {
+	parts := strings.Split(id, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "resourceGroups") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+func summarizeAKS(account string, cluster *aksCluster) *Cluster {
+	return &Cluster{
+		Provider:          AKS,
+		Account:           account,
+		Region:            cluster.Location,
+		ClusterID:         aksResourceGroup(cluster.ID) + "/" + cluster.Name,
+		KubernetesVersion: cluster.Properties.KubernetesVersion,
+		Endpoint:          cluster.Properties.FQDN,
+	}
+}
@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/weaveworks/service/gcp-service/dao"
+)
+
+const (
+	aksAccount   = "tenant1/sub1"
+	aksClusterID = "/subscriptions/sub1/resourcegroups/weave/providers/Microsoft.ContainerService/managedClusters/prod"
+)
+
+// stubAzure serves Azure AD's tokens for tenant1, and one AKS cluster in
+// sub1, to requests with them. It also issues a token to a user who can see
+// sub1, for code1.
+func stubAzure() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/organizations/oauth2/v2.0/token" {
+			r.ParseForm()
+			if r.Form.Get("client_id") != "app" || r.Form.Get("client_secret") != "secret" || r.Form.Get("grant_type") != "authorization_code" || r.Form.Get("code") != "code1" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"usertoken1","token_type":"Bearer","expires_in":3600}`))
+			return
+		}
+		if r.Header.Get("Authorization") == "Bearer usertoken1" {
+			if r.URL.Path == "/subscriptions/sub1" {
+				w.Write([]byte(`{"id":"/subscriptions/sub1","subscriptionId":"sub1","tenantId":"tenant1","displayName":"Production"}`))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"SubscriptionNotFound","message":"Not found"}}`))
+			return
+		}
+		if r.URL.Path == "/tenant1/oauth2/token" {
+			r.ParseForm()
+			if r.Form.Get("client_id") != "app" || r.Form.Get("client_secret") != "secret" || r.Form.Get("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"token1","token_type":"Bearer","expires_in":"3600"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		cluster := `{"id":"` + aksClusterID + `","name":"prod","location":"westeurope","properties":{"kubernetesVersion":"1.17.3","fqdn":"prod-dns.hcp.westeurope.azmk8s.io"}}`
+		switch r.Method + " " + r.URL.Path {
+		case "GET /subscriptions/sub1/providers/Microsoft.ContainerService/managedClusters":
+			w.Write([]byte(`{"value":[` + cluster + `]}`))
+		case "GET /subscriptions/sub1/resourceGroups/weave/providers/Microsoft.ContainerService/managedClusters/prod":
+			w.Write([]byte(cluster))
+		case "POST /subscriptions/sub1/resourceGroups/weave/providers/Microsoft.ContainerService/managedClusters/prod/listClusterAdminCredential":
+			w.Write([]byte(`{"kubeconfigs":[{"name":"clusterAdmin","value":"` + base64.StdEncoding.EncodeToString([]byte("apiVersion: v1\n")) + `"}]}`))
+		case "GET /subscriptions/sub2/providers/Microsoft.ContainerService/managedClusters":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":{"code":"AuthorizationFailed","message":"The client does not have authorization"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"ResourceNotFound","message":"Not found"}}`))
+		}
+	}))
+}
+
+// stubUsers records the accounts users granted us access to.
+type stubUsers struct {
+	dao.UsersNoOpClient
+	accounts map[string][]string
+}
+
+func (u *stubUsers) ClusterProviderAccounts(userID, provider string) ([]string, error) {
+	return u.accounts[userID+"/"+provider], nil
+}
+
+func (u *stubUsers) AddClusterProviderAccount(userID, provider, account string) error {
+	u.accounts[userID+"/"+provider] = append(u.accounts[userID+"/"+provider], account)
+	return nil
+}
+
+func newTestAKSProvider(server *httptest.Server, users *stubUsers) *AKSProvider {
+	return NewAKSProvider(AKSConfig{
+		ClientID:      "app",
+		ClientSecret:  "secret",
+		LoginURL:      server.URL,
+		ManagementURL: server.URL,
+		RedirectURL:   "https://weave.test/api/gcp/providers/aks/consent/callback",
+		StateSecret:   "state-secret",
+	}, users)
+}
+
+func TestAKSListClusters(t *testing.T) {
+	server := stubAzure()
+	defer server.Close()
+	p := newTestAKSProvider(server, &stubUsers{accounts: map[string][]string{"user1/aks": {aksAccount, "tenant1/sub2"}}})
+
+	clusters, err := p.ListClusters(context.Background(), "user1", aksAccount)
+	require.NoError(t, err)
+	assert.Equal(t, []*Cluster{{
+		Provider:          AKS,
+		Account:           aksAccount,
+		Region:            "westeurope",
+		ClusterID:         "weave/prod",
+		KubernetesVersion: "1.17.3",
+		Endpoint:          "prod-dns.hcp.westeurope.azmk8s.io",
+	}}, clusters)
+
+	_, err = p.ListClusters(context.Background(), "user1", "")
+	assert.Equal(t, ErrAccountRequired, err)
+	_, err = p.ListClusters(context.Background(), "user1", "sub1")
+	assert.IsType(t, &InvalidAccountError{}, err)
+
+	// Subscriptions which weren't shared with us are reported as such
+	_, err = p.ListClusters(context.Background(), "user1", "tenant1/sub2")
+	require.IsType(t, &APIError{}, err)
+	assert.Equal(t, http.StatusForbidden, err.(*APIError).StatusCode)
+	assert.Equal(t, "The client does not have authorization", err.(*APIError).Message)
+
+	// Users only find clusters in the subscriptions they granted us access to
+	_, err = p.ListClusters(context.Background(), "user2", aksAccount)
+	assert.Equal(t, ErrAccountNotGranted, err)
+}
+
+func TestAKSGetCluster(t *testing.T) {
+	server := stubAzure()
+	defer server.Close()
+	p := newTestAKSProvider(server, &stubUsers{accounts: map[string][]string{"user1/aks": {aksAccount}}})
+
+	cluster, kubeCfg, err := p.GetCluster(context.Background(), "user1", ClusterRef{Account: aksAccount, Region: "westeurope", ClusterID: "weave/prod"})
+	require.NoError(t, err)
+	assert.Equal(t, "weave/prod", cluster.ClusterID)
+	assert.Equal(t, "1.17.3", cluster.KubernetesVersion)
+	assert.Equal(t, "apiVersion: v1\n", string(kubeCfg))
+
+	_, _, err = p.GetCluster(context.Background(), "user1", ClusterRef{Account: aksAccount, ClusterID: "weave/dev"})
+	assert.Equal(t, ErrClusterNotFound, err)
+	_, _, err = p.GetCluster(context.Background(), "user1", ClusterRef{Account: aksAccount, ClusterID: "prod"})
+	assert.Equal(t, ErrClusterNotFound, err)
+	_, _, err = p.GetCluster(context.Background(), "user2", ClusterRef{Account: aksAccount, ClusterID: "weave/prod"})
+	assert.Equal(t, ErrAccountNotGranted, err)
+}
+
+func TestAKSConsent(t *testing.T) {
+	server := stubAzure()
+	defer server.Close()
+	users := &stubUsers{accounts: map[string][]string{}}
+	p := newTestAKSProvider(server, users)
+
+	_, err := p.ConsentURL("user1", "", "/")
+	assert.Equal(t, ErrAccountRequired, err)
+
+	consentURL, err := p.ConsentURL("user1", "sub1", "/org1/clusters")
+	require.NoError(t, err)
+	u, err := url.Parse(consentURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/organizations/oauth2/v2.0/authorize", u.Path)
+	assert.Equal(t, "app", u.Query().Get("client_id"))
+	assert.Equal(t, "https://weave.test/api/gcp/providers/aks/consent/callback", u.Query().Get("redirect_uri"))
+	state := u.Query().Get("state")
+
+	// Consent is only recorded for the user who asked for it, with a valid code
+	_, err = p.Consent(context.Background(), "user2", "code1", state)
+	assert.Equal(t, ErrInvalidConsent, err)
+	_, err = p.Consent(context.Background(), "user1", "code1", state+"x")
+	assert.Equal(t, ErrInvalidConsent, err)
+	_, err = p.Consent(context.Background(), "user1", "code2", state)
+	assert.Equal(t, ErrInvalidConsent, err)
+	assert.Empty(t, users.accounts)
+
+	next, err := p.Consent(context.Background(), "user1", "code1", state)
+	require.NoError(t, err)
+	assert.Equal(t, "/org1/clusters", next)
+	assert.Equal(t, []string{aksAccount}, users.accounts["user1/aks"])
+	_, err = p.ListClusters(context.Background(), "user1", aksAccount)
+	assert.NoError(t, err)
+
+	// Users can't grant access to subscriptions they can't see
+	consentURL, err = p.ConsentURL("user1", "sub2", "/")
+	require.NoError(t, err)
+	u, err = url.Parse(consentURL)
+	require.NoError(t, err)
+	_, err = p.Consent(context.Background(), "user1", "code1", u.Query().Get("state"))
+	assert.Equal(t, ErrAccountNotGranted, err)
+	assert.Equal(t, []string{aksAccount}, users.accounts["user1/aks"])
+}
@@ -0,0 +1,255 @@
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/weaveworks/common/instrument"
+	"golang.org/x/net/context"
+
+	"github.com/weaveworks/service/common/gcp/gke"
+)
+
+// Prometheus metrics for EKS API client.
+var eksRequestCollector = instrument.NewHistogramCollectorFromOpts(prometheus.HistogramOpts{
+	Namespace: "aws",
+	Subsystem: "eks_client",
+	Name:      "request_duration_seconds",
+	Help:      "Response time of Amazon Elastic Kubernetes Service API requests.",
+	Buckets:   prometheus.DefBuckets,
+})
+
+func init() {
+	eksRequestCollector.Register()
+}
+
+const (
+	// Tokens for EKS clusters are pre-signed requests to this STS endpoint, as
+	// expected by aws-iam-authenticator.
+	stsURL          = "https://sts.amazonaws.com/?Action=GetCallerIdentity&Version=2011-06-15"
+	eksTokenPrefix  = "k8s-aws-v1."
+	eksClusterIDKey = "x-k8s-aws-id"
+)
+
+// EKSConfig configures access to Amazon Elastic Kubernetes Service.
+type EKSConfig struct {
+	Enabled bool
+	// Regions are looked for clusters in, as EKS has no global listing.
+	Regions string
+	// Endpoint is the EKS API's URL, with %s for the region.
+	Endpoint string
+}
+
+// RegisterFlags registers configuration variables.
+func (c *EKSConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&c.Enabled, "eks.enabled", false, "Find EKS clusters, assuming the IAM roles users grant us, with credentials from the environment.")
+	f.StringVar(&c.Regions, "eks.regions", "us-east-1,us-east-2,us-west-2,eu-west-1,eu-central-1,ap-southeast-1,ap-northeast-1", "Comma-separated AWS regions to look for EKS clusters in.")
+	f.StringVar(&c.Endpoint, "eks.endpoint", "https://eks.%s.amazonaws.com", "URL of the EKS API, with %s for the region.")
+}
+
+// EKSProvider finds Amazon Elastic Kubernetes Service clusters, by assuming
+// IAM roles users grant us. Accounts are the ARNs of these roles, and the
+// user's ID is the external ID they must require to be assumed.
+type EKSProvider struct {
+	regions  []string
+	endpoint string
+	client   *http.Client
+	// credentials returns those of the role assumed for a user.
+	credentials func(roleARN, userID string) *credentials.Credentials
+}
+
+// NewEKSProvider creates a provider for EKS clusters.
+func NewEKSProvider(cfg EKSConfig) (*EKSProvider, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	return newEKSProvider(cfg, func(roleARN, userID string) *credentials.Credentials {
+		return stscreds.NewCredentials(sess, roleARN, func(p *stscreds.AssumeRoleProvider) {
+			p.ExternalID = aws.String(userID)
+		})
+	}), nil
+}
+
+func newEKSProvider(cfg EKSConfig, creds func(roleARN, userID string) *credentials.Credentials) *EKSProvider {
+	var regions []string
+	for _, region := range strings.Split(cfg.Regions, ",") {
+		if region = strings.TrimSpace(region); region != "" {
+			regions = append(regions, region)
+		}
+	}
+	return &EKSProvider{
+		regions:     regions,
+		endpoint:    cfg.Endpoint,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		credentials: creds,
+	}
+}
+
+type eksCluster struct {
+	Name                 string `json:"name"`
+	Version              string `json:"version"`
+	Endpoint             string `json:"endpoint"`
+	Status               string `json:"status"`
+	CertificateAuthority struct {
+		Data string `json:"data"`
+	} `json:"certificateAuthority"`
+}
+
+// ListClusters returns the EKS clusters which the specified role can see, in all configured regions.
+func (p EKSProvider) ListClusters(ctx context.Context, userID, roleARN string) ([]*Cluster, error) {
+	if roleARN == "" {
+		return nil, ErrAccountRequired
+	}
+	creds := p.credentials(roleARN, userID)
+	clusters := []*Cluster{}
+	for _, region := range p.regions {
+		query := url.Values{}
+		for {
+			var page struct {
+				Clusters  []string `json:"clusters"`
+				NextToken string   `json:"nextToken"`
+			}
+			if err := p.get(ctx, creds, region, "/clusters", "/clusters", query, &page); err != nil {
+				return nil, err
+			}
+			for _, name := range page.Clusters {
+				cluster, err := p.describe(ctx, creds, region, name)
+				if err != nil {
+					return nil, err
+				}
+				clusters = append(clusters, summarizeEKS(roleARN, region, cluster))
+			}
+			if page.NextToken == "" {
+				break
+			}
+			query.Set("nextToken", page.NextToken)
+		}
+	}
+	return clusters, nil
+}
+
+// GetCluster returns the specified EKS cluster, and a kubeconfig using a token for the assumed role.
+func (p EKSProvider) GetCluster(ctx context.Context, userID string, ref ClusterRef) (*Cluster, []byte, error) {
+	if ref.Account == "" {
+		return nil, nil, ErrAccountRequired
+	}
+	creds := p.credentials(ref.Account, userID)
+	cluster, err := p.describe(ctx, creds, ref.Region, ref.ClusterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	token, err := eksToken(creds, cluster.Name, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	kubeCfg := gke.NewTokenKubeConfig(
+		cluster.Name,
+		strings.TrimPrefix(cluster.Endpoint, "https://"),
+		token,
+		cluster.CertificateAuthority.Data)
+	kubeCfgBytes, err := kubeCfg.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	return summarizeEKS(ref.Account, ref.Region, cluster), kubeCfgBytes, nil
+}
+
+func (p EKSProvider) describe(ctx context.Context, creds *credentials.Credentials, region, name string) (*eksCluster, error) {
+	var reply struct {
+		Cluster eksCluster `json:"cluster"`
+	}
+	if err := p.get(ctx, creds, region, "/clusters/{name}", "/clusters/"+url.PathEscape(name), nil, &reply); err != nil {
+		return nil, err
+	}
+	return &reply.Cluster, nil
+}
+
+// get sends a signed request to the EKS API of a region, and decodes its reply into result.
+// Only configured regions are accepted, as they're part of the API's hostname.
+func (p EKSProvider) get(ctx context.Context, creds *credentials.Credentials, region, route, path string, query url.Values, result interface{}) error {
+	if !p.isConfiguredRegion(region) {
+		return &InvalidRegionError{Region: region, Available: p.regions}
+	}
+	method := "GET " + route
+	start := time.Now()
+	eksRequestCollector.Before(method, start)
+
+	u := fmt.Sprintf(p.endpoint, region) + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := v4.NewSigner(creds).Sign(req, nil, "eks", region, start); err != nil {
+		eksRequestCollector.After(method, "500", start)
+		return err
+	}
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		eksRequestCollector.After(method, "500", start)
+		return err
+	}
+	defer resp.Body.Close()
+	eksRequestCollector.After(method, strconv.Itoa(resp.StatusCode), start)
+	if resp.StatusCode/100 != 2 {
+		var reply struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&reply)
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrClusterNotFound
+		}
+		return &APIError{Provider: EKS, StatusCode: resp.StatusCode, Message: reply.Message}
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (p EKSProvider) isConfiguredRegion(region string) bool {
+	for _, r := range p.regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// eksToken returns a bearer token for an EKS cluster: a pre-signed STS
+// request, which the cluster makes to find out whose credentials signed it.
+func eksToken(creds *credentials.Credentials, clusterName string, now time.Time) (string, error) {
+	req, err := http.NewRequest("GET", stsURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(eksClusterIDKey, clusterName)
+	if _, err := v4.NewSigner(creds).Presign(req, nil, "sts", "us-east-1", time.Minute, now); err != nil {
+		return "", err
+	}
+	return eksTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(req.URL.String())), nil
+}
+
+func summarizeEKS(roleARN, region string, cluster *eksCluster) *Cluster {
+	return &Cluster{
+		Provider:          EKS,
+		Account:           roleARN,
+		Region:            region,
+		ClusterID:         cluster.Name,
+		KubernetesVersion: cluster.Version,
+		Endpoint:          cluster.Endpoint,
+	}
+}
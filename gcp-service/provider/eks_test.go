@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/weaveworks/service/common/gcp/gke"
+)
+
+const roleARN = "arn:aws:iam::123456789012:role/weave-cloud"
+
+// stubEKS serves two clusters in eu-west-1, over two pages, to signed requests.
+func stubEKS() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Authorization"), "Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"The security token included in the request is invalid."}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/eu-west-1/clusters":
+			if r.URL.Query().Get("nextToken") == "" {
+				w.Write([]byte(`{"clusters":["prod"],"nextToken":"page2"}`))
+			} else {
+				w.Write([]byte(`{"clusters":["staging"],"nextToken":null}`))
+			}
+		case "/eu-west-1/clusters/prod", "/eu-west-1/clusters/staging":
+			name := strings.TrimPrefix(r.URL.Path, "/eu-west-1/clusters/")
+			json.NewEncoder(w).Encode(map[string]interface{}{"cluster": map[string]interface{}{
+				"name":                 name,
+				"version":              "1.16",
+				"endpoint":             "https://" + name + ".eks.amazonaws.com",
+				"status":               "ACTIVE",
+				"certificateAuthority": map[string]string{"data": "Y2E="},
+			}})
+		case "/us-east-1/clusters":
+			w.Write([]byte(`{"clusters":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No cluster found"}`))
+		}
+	}))
+}
+
+// newTestEKSProvider returns a provider for the stub, recording which roles it assumes.
+func newTestEKSProvider(server *httptest.Server, accessKeyID string, assumed *[]string) *EKSProvider {
+	cfg := EKSConfig{Regions: "eu-west-1, us-east-1", Endpoint: server.URL + "/%s"}
+	return newEKSProvider(cfg, func(roleARN, userID string) *credentials.Credentials {
+		*assumed = append(*assumed, roleARN+" "+userID)
+		return credentials.NewStaticCredentials(accessKeyID, "secret", "session")
+	})
+}
+
+func TestEKSListClusters(t *testing.T) {
+	server := stubEKS()
+	defer server.Close()
+	var assumed []string
+	p := newTestEKSProvider(server, "AKID", &assumed)
+
+	clusters, err := p.ListClusters(context.Background(), "user1", roleARN)
+	require.NoError(t, err)
+	assert.Equal(t, []*Cluster{
+		{Provider: EKS, Account: roleARN, Region: "eu-west-1", ClusterID: "prod", KubernetesVersion: "1.16", Endpoint: "https://prod.eks.amazonaws.com"},
+		{Provider: EKS, Account: roleARN, Region: "eu-west-1", ClusterID: "staging", KubernetesVersion: "1.16", Endpoint: "https://staging.eks.amazonaws.com"},
+	}, clusters)
+	// The user's ID is the external ID of the role
+	assert.Equal(t, []string{roleARN + " user1"}, assumed)
+
+	_, err = p.ListClusters(context.Background(), "user1", "")
+	assert.Equal(t, ErrAccountRequired, err)
+
+	// Roles we can't use are reported as such
+	_, err = newTestEKSProvider(server, "OTHER", &assumed).ListClusters(context.Background(), "user1", roleARN)
+	require.IsType(t, &APIError{}, err)
+	assert.Equal(t, http.StatusForbidden, err.(*APIError).StatusCode)
+}
+
+func TestEKSGetCluster(t *testing.T) {
+	server := stubEKS()
+	defer server.Close()
+	var assumed []string
+	p := newTestEKSProvider(server, "AKID", &assumed)
+
+	cluster, kubeCfgBytes, err := p.GetCluster(context.Background(), "user1", ClusterRef{Account: roleARN, Region: "eu-west-1", ClusterID: "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, "prod", cluster.ClusterID)
+	assert.Equal(t, "1.16", cluster.KubernetesVersion)
+
+	var kubeCfg gke.KubeConfig
+	require.NoError(t, kubeCfg.Unmarshal(string(kubeCfgBytes)))
+	assert.Equal(t, "https://prod.eks.amazonaws.com", kubeCfg.Clusters[0].Cluster.Server)
+	assert.Equal(t, "Y2E=", kubeCfg.Clusters[0].Cluster.CertificateAuthorityData)
+	token := kubeCfg.Users[0].User.Token
+	require.True(t, strings.HasPrefix(token, eksTokenPrefix))
+
+	// The token is a pre-signed request for the caller's identity, naming the cluster
+	presigned, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, eksTokenPrefix))
+	require.NoError(t, err)
+	u, err := url.Parse(string(presigned))
+	require.NoError(t, err)
+	assert.Equal(t, "sts.amazonaws.com", u.Host)
+	assert.Equal(t, "GetCallerIdentity", u.Query().Get("Action"))
+	assert.Contains(t, u.Query().Get("X-Amz-Credential"), "AKID/")
+	assert.Contains(t, u.Query().Get("X-Amz-SignedHeaders"), eksClusterIDKey)
+	assert.Equal(t, "session", u.Query().Get("X-Amz-Security-Token"))
+
+	_, _, err = p.GetCluster(context.Background(), "user1", ClusterRef{Account: roleARN, Region: "eu-west-1", ClusterID: "dev"})
+	assert.Equal(t, ErrClusterNotFound, err)
+
+	// Requests are only signed for the configured regions' endpoints
+	for _, region := range []string{"", "ap-south-1", "eu-west-1.attacker.example/"} {
+		_, _, err = p.GetCluster(context.Background(), "user1", ClusterRef{Account: roleARN, Region: region, ClusterID: "prod"})
+		assert.IsType(t, &InvalidRegionError{}, err)
+	}
+}
+
+func TestEKSToken(t *testing.T) {
+	creds := credentials.NewStaticCredentials("AKID", "secret", "")
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	a, err := eksToken(creds, "prod", now)
+	require.NoError(t, err)
+	b, err := eksToken(creds, "staging", now)
+	require.NoError(t, err)
+	// Tokens are only valid for the cluster they were made for
+	assert.NotEqual(t, a, b)
+}
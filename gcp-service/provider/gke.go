@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+
+	"github.com/weaveworks/service/common/gcp/gke"
+	"github.com/weaveworks/service/gcp-service/dao"
+)
+
+// GKEProvider finds Google Kubernetes Engine clusters, using users' Google OAuth tokens.
+// Accounts are GCP projects, and regions are zones.
+type GKEProvider struct {
+	UsersClient   dao.UsersClient
+	ClientFactory func(*oauth2.Token) (gke.Client, error)
+}
+
+// ListAccounts returns all the GCP projects belonging to the provided user.
+func (p GKEProvider) ListAccounts(ctx context.Context, userID string) ([]string, error) {
+	client, err := p.clientFor(userID)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := client.ListProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var projectIDs []string
+	for _, project := range projects {
+		projectIDs = append(projectIDs, project.Name)
+	}
+	return projectIDs, nil
+}
+
+// ListClusters returns the GKE clusters belonging to the provided user in the specified project, or in all their projects.
+func (p GKEProvider) ListClusters(ctx context.Context, userID, projectID string) ([]*Cluster, error) {
+	client, err := p.clientFor(userID)
+	if err != nil {
+		return nil, err
+	}
+	var clusters []*gke.Cluster
+	if projectID == "" {
+		clusters, err = client.ListClusters(ctx)
+	} else {
+		clusters, err = client.ListClustersForProject(ctx, projectID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	summaries := []*Cluster{}
+	for _, cluster := range clusters {
+		summaries = append(summaries, summarizeGKE(cluster))
+	}
+	return summaries, nil
+}
+
+// GetCluster returns the specified GKE cluster, and a kubeconfig using its basic authentication.
+func (p GKEProvider) GetCluster(ctx context.Context, userID string, ref ClusterRef) (*Cluster, []byte, error) {
+	client, err := p.clientFor(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	cluster, err := client.GetCluster(ctx, ref.Account, ref.Region, ref.ClusterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	kubeCfg := gke.NewKubeConfig(
+		cluster.Cluster.Name,
+		cluster.Cluster.Endpoint,
+		cluster.Cluster.MasterAuth.Username,
+		cluster.Cluster.MasterAuth.Password,
+		cluster.Cluster.MasterAuth.ClusterCaCertificate)
+	kubeCfgBytes, err := kubeCfg.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	return summarizeGKE(cluster), kubeCfgBytes, nil
+}
+
+func (p GKEProvider) clientFor(userID string) (gke.Client, error) {
+	token, err := p.UsersClient.GoogleOAuthToken(userID)
+	if err != nil {
+		return nil, err
+	}
+	return p.ClientFactory(token)
+}
+
+func summarizeGKE(cluster *gke.Cluster) *Cluster {
+	return &Cluster{
+		Provider:          GKE,
+		Account:           cluster.ProjectID,
+		Region:            cluster.Zone,
+		ClusterID:         cluster.Cluster.Name,
+		KubernetesVersion: cluster.Cluster.CurrentMasterVersion,
+		Endpoint:          cluster.Cluster.Endpoint,
+		ProjectID:         cluster.ProjectID,
+		Zone:              cluster.Zone,
+	}
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/weaveworks/service/common/gcp/gke"
+)
+
+// NoOpProvider is a no-op implementation of ClusterProvider, which finds one
+// arbitrary cluster in any account.
+// This implementation is mostly useful for testing.
+type NoOpProvider struct {
+	Name string
+}
+
+// ListClusters returns an arbitrary list of one cluster, and a nil error.
+func (p NoOpProvider) ListClusters(ctx context.Context, userID, account string) ([]*Cluster, error) {
+	return []*Cluster{p.sampleCluster(ClusterRef{Account: account, Region: "us-east-1", ClusterID: p.Name + "-integration"})}, nil
+}
+
+// GetCluster returns an arbitrary cluster and kubeconfig, and a nil error.
+func (p NoOpProvider) GetCluster(ctx context.Context, userID string, ref ClusterRef) (*Cluster, []byte, error) {
+	cluster := p.sampleCluster(ref)
+	kubeCfg, err := gke.NewTokenKubeConfig(ref.ClusterID, "192.168.0.1", "<token>", "<cluster_ca_certificate>").Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cluster, kubeCfg, nil
+}
+
+func (p NoOpProvider) sampleCluster(ref ClusterRef) *Cluster {
+	return &Cluster{
+		Provider:          p.Name,
+		Account:           ref.Account,
+		Region:            ref.Region,
+		ClusterID:         ref.ClusterID,
+		KubernetesVersion: "1.17.5",
+		Endpoint:          "https://192.168.0.1",
+	}
+}
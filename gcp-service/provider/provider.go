@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// Names of the supported providers of managed Kubernetes clusters.
+const (
+	GKE = "gke"
+	EKS = "eks"
+	AKS = "aks"
+)
+
+// Errors returned by providers.
+var (
+	ErrAccountRequired   = errors.New("an account is required to find clusters with this provider")
+	ErrClusterNotFound   = errors.New("cluster not found")
+	ErrAccountNotGranted = errors.New("access to this account hasn't been granted")
+	ErrInvalidConsent    = errors.New("invalid or expired consent")
+)
+
+// Cluster groups identifiers for a managed Kubernetes cluster, its version, and where to reach it, to avoid leaking sensitive information about the cluster itself.
+type Cluster struct {
+	Provider string `json:"provider"`
+	// Account is what the cluster was found in: a GCP project, the ARN of an
+	// AWS IAM role, or an Azure tenant and subscription.
+	Account           string `json:"account,omitempty"`
+	Region            string `json:"region,omitempty"`
+	ClusterID         string `json:"clusterId,omitempty"`
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	Endpoint          string `json:"endpoint,omitempty"`
+
+	// ProjectID and Zone are only set for GKE clusters, for older clients.
+	ProjectID string `json:"projectId,omitempty"`
+	Zone      string `json:"zone,omitempty"`
+}
+
+// ClusterRef identifies a cluster, as listed by a provider.
+type ClusterRef struct {
+	Account   string
+	Region    string
+	ClusterID string
+}
+
+// ClusterProvider finds the managed Kubernetes clusters of a cloud provider,
+// and gives kubectl access to them.
+type ClusterProvider interface {
+	// ListClusters lists the user's clusters in the provided account.
+	// Providers which can find a user's accounts themselves list the clusters
+	// of all of them if account is empty; others return ErrAccountRequired.
+	ListClusters(ctx context.Context, userID, account string) ([]*Cluster, error)
+	// GetCluster returns the specified cluster, and a kubeconfig with which
+	// kubectl can administer it.
+	GetCluster(ctx context.Context, userID string, ref ClusterRef) (*Cluster, []byte, error)
+}
+
+// AccountLister is implemented by providers which can find a user's
+// accounts, e.g. GCP projects.
+type AccountLister interface {
+	ListAccounts(ctx context.Context, userID string) ([]string, error)
+}
+
+// ConsentProvider is implemented by providers which users grant access to
+// their accounts by consenting to our OAuth application, e.g. Azure AD's.
+type ConsentProvider interface {
+	// ConsentURL returns where to send the user to grant us access to the
+	// account, and to be sent back to next afterwards.
+	ConsentURL(userID, account, next string) (string, error)
+	// Consent records the account the user granted us access to, given the
+	// code and state the provider sent them back with, and returns next.
+	Consent(ctx context.Context, userID, code, state string) (string, error)
+}
+
+// UnknownProviderError is returned for requests to providers which aren't
+// configured.
+type UnknownProviderError struct {
+	Name      string
+	Available []string
+}
+
+func (e *UnknownProviderError) Error() string {
+	return fmt.Sprintf("unknown cluster provider %q, expected one of: %s", e.Name, strings.Join(e.Available, ", "))
+}
+
+// InvalidAccountError is returned for accounts which a provider can't make sense of.
+type InvalidAccountError struct {
+	Account  string
+	Expected string
+}
+
+func (e *InvalidAccountError) Error() string {
+	return fmt.Sprintf("invalid account %q, expected %s", e.Account, e.Expected)
+}
+
+// InvalidRegionError is returned for regions which a provider doesn't look for clusters in.
+type InvalidRegionError struct {
+	Region    string
+	Available []string
+}
+
+func (e *InvalidRegionError) Error() string {
+	return fmt.Sprintf("invalid region %q, expected one of: %s", e.Region, strings.Join(e.Available, ", "))
+}
+
+// APIError is returned when a provider's API fails a request.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API returned %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// Providers are the configured cluster providers, by name.
+type Providers map[string]ClusterProvider
+
+// Get returns the named provider. Requests which don't name one are for GKE,
+// as they were before other providers were supported.
+func (p Providers) Get(name string) (ClusterProvider, error) {
+	if name == "" {
+		name = GKE
+	}
+	if provider, ok := p[name]; ok {
+		return provider, nil
+	}
+	available := []string{}
+	for name := range p {
+		available = append(available, name)
+	}
+	sort.Strings(available)
+	return nil, &UnknownProviderError{Name: name, Available: available}
+}
+
+// GetConsentProvider returns the named provider, if users grant it access to
+// their accounts through consent.
+func (p Providers) GetConsentProvider(name string) (ConsentProvider, error) {
+	if provider, ok := p[name].(ConsentProvider); ok {
+		return provider, nil
+	}
+	available := []string{}
+	for name, provider := range p {
+		if _, ok := provider.(ConsentProvider); ok {
+			available = append(available, name)
+		}
+	}
+	sort.Strings(available)
+	return nil, &UnknownProviderError{Name: name, Available: available}
+}
@@ -7,14 +7,28 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/service/gcp-service/provider"
 )
 
 // ErrorStatusCode translates error into HTTP status code.
 func ErrorStatusCode(err error) int {
-	//switch err.(type) {
-	//case *..., *...:
-	//		return http.StatusBadRequest
-	//	}
+	switch err := err.(type) {
+	case *provider.UnknownProviderError, *provider.InvalidAccountError, *provider.InvalidRegionError:
+		return http.StatusBadRequest
+	case *provider.APIError:
+		// Users haven't granted us access, or have revoked it
+		if err.StatusCode == http.StatusUnauthorized || err.StatusCode == http.StatusForbidden {
+			return http.StatusForbidden
+		}
+	}
+	switch err {
+	case provider.ErrAccountRequired, provider.ErrInvalidConsent:
+		return http.StatusBadRequest
+	case provider.ErrAccountNotGranted:
+		return http.StatusForbidden
+	case provider.ErrClusterNotFound:
+		return http.StatusNotFound
+	}
 
 	// Just incase there's something sensitive in the error
 	return http.StatusInternalServerError
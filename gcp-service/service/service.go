@@ -1,120 +1,76 @@
 package service
 
 import (
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 
 	"golang.org/x/net/context"
-	"golang.org/x/oauth2"
 
 	"github.com/weaveworks/launcher/pkg/kubectl"
-	"github.com/weaveworks/service/common/gcp/gke"
-	"github.com/weaveworks/service/gcp-service/dao"
+	"github.com/weaveworks/service/gcp-service/provider"
 	pb "github.com/weaveworks/service/kubectl-service/grpc"
 )
 
 // Service is our service.
 type Service struct {
-	UsersClient      dao.UsersClient
-	KubectlClient    pb.CloseableKubectlClient
-	GKEClientFactory func(*oauth2.Token) (gke.Client, error)
-}
-
-// Cluster groups identifiers for a Google Kubernetes Engine cluster and its version, to avoid leaking sensitive information about the cluster itself.
-type Cluster struct {
-	ProjectID         string `json:"projectId,omitempty"`
-	Zone              string `json:"zone,omitempty"`
-	ClusterID         string `json:"clusterId,omitempty"`
-	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
-}
-
-// GetClusters returns all the GKE clusters belonging to the provided user.
-func (s Service) GetClusters(ctx context.Context, userID string) ([]*Cluster, error) {
-	logger := log.WithField("user_id", userID)
-	client, err := s.gkeClientFor(userID)
-	if err != nil {
-		return nil, err
-	}
-	clusters, err := client.ListClusters(ctx)
-	if err != nil {
-		return nil, err
-	}
-	logger.Infof("%v cluster(s) retrieved", len(clusters))
-	return summarize(clusters), nil
-}
-
-func summarize(clusters []*gke.Cluster) []*Cluster {
-	ids := []*Cluster{}
-	for _, cluster := range clusters {
-		ids = append(ids, &Cluster{
-			ProjectID:         cluster.ProjectID,
-			Zone:              cluster.Zone,
-			ClusterID:         cluster.Cluster.Name,
-			KubernetesVersion: cluster.Cluster.CurrentMasterVersion,
-		})
-	}
-	return ids
+	KubectlClient pb.CloseableKubectlClient
+	Providers     provider.Providers
 }
 
 // GetProjects returns all the GCP projects belonging to the provided user.
 func (s Service) GetProjects(ctx context.Context, userID string) ([]string, error) {
 	logger := log.WithField("user_id", userID)
-	client, err := s.gkeClientFor(userID)
+	p, err := s.Providers.Get(provider.GKE)
 	if err != nil {
 		return nil, err
 	}
-	projects, err := client.ListProjects(context.Background())
+	lister, ok := p.(provider.AccountLister)
+	if !ok {
+		return nil, fmt.Errorf("%v provider can't list projects", provider.GKE)
+	}
+	projectIDs, err := lister.ListAccounts(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	var projectIDs []string
-	for _, project := range projects {
-		projectIDs = append(projectIDs, project.Name)
-	}
 	logger.Infof("%v project(s) retrieved", len(projectIDs))
 	return projectIDs, nil
 }
 
-// GetClustersForProject returns all the GKE clusters belonging to the provided user in the specified project.
-func (s Service) GetClustersForProject(ctx context.Context, userID, projectID string) ([]*Cluster, error) {
-	logger := log.WithFields(log.Fields{"user_id": userID, "project_id": projectID})
-	client, err := s.gkeClientFor(userID)
+// ListClusters returns the clusters belonging to the provided user in the specified account of the specified provider.
+// GKE clusters are listed across all the user's projects if account is empty.
+func (s Service) ListClusters(ctx context.Context, providerName, userID, account string) ([]*provider.Cluster, error) {
+	logger := log.WithFields(log.Fields{"user_id": userID, "provider": providerName, "account": account})
+	p, err := s.Providers.Get(providerName)
 	if err != nil {
 		return nil, err
 	}
-	clusters, err := client.ListClustersForProject(ctx, projectID)
+	clusters, err := p.ListClusters(ctx, userID, account)
 	if err != nil {
 		return nil, err
 	}
 	logger.Infof("%v cluster(s) retrieved", len(clusters))
-	return summarize(clusters), nil
+	return clusters, nil
 }
 
 // RunKubectlCmd executes the provided kubectl command against the specified cluster.
-func (s Service) RunKubectlCmd(ctx context.Context, userID, projectID, zone, clusterID string, args []string) (string, error) {
-	logger := log.WithFields(log.Fields{"user_id": userID, "project_id": projectID, "zone": zone, "cluster_id": clusterID})
-	client, err := s.gkeClientFor(userID)
+func (s Service) RunKubectlCmd(ctx context.Context, providerName, userID string, ref provider.ClusterRef, args []string) (string, error) {
+	logger := log.WithFields(log.Fields{"user_id": userID, "provider": providerName, "account": ref.Account, "region": ref.Region, "cluster_id": ref.ClusterID})
+	p, err := s.Providers.Get(providerName)
 	if err != nil {
 		return "", err
 	}
-	cluster, err := client.GetCluster(ctx, projectID, zone, clusterID)
-	if err != nil {
-		return "", err
-	}
-	kubeCfg := gke.NewKubeConfig(
-		cluster.Cluster.Name,
-		cluster.Cluster.Endpoint,
-		cluster.Cluster.MasterAuth.Username,
-		cluster.Cluster.MasterAuth.Password,
-		cluster.Cluster.MasterAuth.ClusterCaCertificate)
-	kubeCfgBytes, err := kubeCfg.Marshal()
+	cluster, kubeCfg, err := p.GetCluster(ctx, userID, ref)
 	if err != nil {
 		return "", err
 	}
 	logger.Infof("Running kubectl command %v", args)
 	reply, err := s.KubectlClient.RunKubectlCmd(ctx, &pb.KubectlRequest{
-		Version:    cluster.Cluster.CurrentMasterVersion,
-		Kubeconfig: kubeCfgBytes,
-		Args:       args,
+		Version: cluster.KubernetesVersion,
+		// Ask clusters whose providers don't tell us their version
+		DiscoverVersion: cluster.KubernetesVersion == "",
+		Kubeconfig:      kubeCfg,
+		Args:            args,
 	})
 	if err != nil {
 		return "", err
@@ -122,39 +78,29 @@ func (s Service) RunKubectlCmd(ctx context.Context, userID, projectID, zone, clu
 	return reply.Output, nil
 }
 
-func (s Service) gkeClientFor(userID string) (gke.Client, error) {
-	token, err := s.UsersClient.GoogleOAuthToken(userID)
-	if err != nil {
-		return nil, err
-	}
-	return s.GKEClientFactory(token)
-}
-
 // KubectlServiceClient implements github.com/weaveworks/launcher/pkg/kubectl.Client
 type KubectlServiceClient struct {
-	Context   context.Context
-	Service   Service
-	UserID    string
-	ProjectID string
-	Zone      string
-	ClusterID string
+	Context  context.Context
+	Service  Service
+	Provider string
+	UserID   string
+	Cluster  provider.ClusterRef
 }
 
 // Execute implements github.com/weaveworks/launcher/pkg/kubectl.Client
 func (k KubectlServiceClient) Execute(args ...string) (string, error) {
-	return k.Service.RunKubectlCmd(k.Context, k.UserID, k.ProjectID, k.Zone, k.ClusterID, args)
+	return k.Service.RunKubectlCmd(k.Context, k.Provider, k.UserID, k.Cluster, args)
 }
 
 // InstallWeaveCloud executes the provided kubectl command against the specified cluster.
-func (s Service) InstallWeaveCloud(ctx context.Context, userID, projectID, zone, clusterID, weaveCloudToken string) error {
+func (s Service) InstallWeaveCloud(ctx context.Context, providerName, userID string, ref provider.ClusterRef, weaveCloudToken string) error {
 	// Create client which implements kubectl.Client so we can use our kubectl pkg helpers
 	client := KubectlServiceClient{
-		Context:   ctx,
-		Service:   s,
-		UserID:    userID,
-		ProjectID: projectID,
-		Zone:      zone,
-		ClusterID: clusterID,
+		Context:  ctx,
+		Service:  s,
+		Provider: providerName,
+		UserID:   userID,
+		Cluster:  ref,
 	}
 
 	// 1. Create weave namespace
@@ -177,3 +123,27 @@ func (s Service) InstallWeaveCloud(ctx context.Context, userID, projectID, zone,
 
 	return nil
 }
+
+// ConsentURL returns where to send the user to grant us access to the specified account of the specified provider.
+func (s Service) ConsentURL(providerName, userID, account, next string) (string, error) {
+	p, err := s.Providers.GetConsentProvider(providerName)
+	if err != nil {
+		return "", err
+	}
+	return p.ConsentURL(userID, account, next)
+}
+
+// Consent records the account of the specified provider the user granted us access to, and returns where to send them next.
+func (s Service) Consent(ctx context.Context, providerName, userID, code, state string) (string, error) {
+	logger := log.WithFields(log.Fields{"user_id": userID, "provider": providerName})
+	p, err := s.Providers.GetConsentProvider(providerName)
+	if err != nil {
+		return "", err
+	}
+	next, err := p.Consent(ctx, userID, code, state)
+	if err != nil {
+		return "", err
+	}
+	logger.Infof("Access to account granted")
+	return next, nil
+}
@@ -3,6 +3,7 @@ package grpc
 import (
 	"flag"
 	"fmt"
+	"io"
 	strings "strings"
 
 	"github.com/weaveworks/common/instrument"
@@ -63,6 +64,9 @@ func NewClient(cfg Config) (*Client, error) {
 			common_grpc.NewErrorInterceptor("kubectl-error-code"),
 			common_grpc.NewMetricsInterceptor(clientRequestCollector),
 		)),
+		google_grpc.WithStreamInterceptor(
+			otgrpc.OpenTracingStreamClientInterceptor(opentracing.GlobalTracer()),
+		),
 	}
 	conn, err := google_grpc.Dial(cfg.HostPort, dialOptions...)
 	if err != nil {
@@ -79,6 +83,11 @@ func (c Client) RunKubectlCmd(ctx context.Context, in *KubectlRequest, opts ...g
 	return c.client.RunKubectlCmd(ctx, in, opts...)
 }
 
+// StreamKubectlCmd executes the provided kubectl command against the specified cluster, streaming its output.
+func (c Client) StreamKubectlCmd(ctx context.Context, in *KubectlRequest, opts ...google_grpc.CallOption) (Kubectl_StreamKubectlCmdClient, error) {
+	return c.client.StreamKubectlCmd(ctx, in, opts...)
+}
+
 // Close closes the underlying TCP connection for to the remote gRPC server.
 func (c *Client) Close() {
 	c.conn.Close()
@@ -95,6 +104,31 @@ func (c NoOpClient) RunKubectlCmd(ctx context.Context, in *KubectlRequest, opts
 	return &KubectlReply{Output: fmt.Sprintf("Dry run: kubectl %v (%v)", strings.Join(in.Args, " "), in.Version)}, nil
 }
 
+// StreamKubectlCmd does nothing, but streams what it would have done.
+func (c NoOpClient) StreamKubectlCmd(ctx context.Context, in *KubectlRequest, opts ...google_grpc.CallOption) (Kubectl_StreamKubectlCmdClient, error) {
+	log.Infof("NoOpClient#StreamKubectlCmd(ctx, {%v, %v, %v,}, opts) called.", in.Version, string(in.Kubeconfig), in.Args)
+	return &noOpStream{outputs: []*KubectlOutput{
+		{Stream: STDOUT, Data: []byte(fmt.Sprintf("Dry run: kubectl %v (%v)", strings.Join(in.Args, " "), in.Version))},
+		{Exited: true},
+	}}, nil
+}
+
+// noOpStream replays outputs. Its other gRPC stream methods aren't
+// implemented.
+type noOpStream struct {
+	google_grpc.ClientStream
+	outputs []*KubectlOutput
+}
+
+func (s *noOpStream) Recv() (*KubectlOutput, error) {
+	if len(s.outputs) == 0 {
+		return nil, io.EOF
+	}
+	output := s.outputs[0]
+	s.outputs = s.outputs[1:]
+	return output, nil
+}
+
 // Close does nothing.
 func (c NoOpClient) Close() {
 	log.Info("NoOpClient#Close() called.")
@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+var errOutputLimitExceeded = errors.New("kubectl output limit exceeded")
+
+// outputLimit caps the output of a command, across its streams. Writes are
+// serialised, so the streams can share an underlying writer.
+type outputLimit struct {
+	sync.Mutex
+	remaining int
+	// exceeded is called once the limit is reached, to kill the command.
+	exceeded func()
+	reached  bool
+}
+
+func (l *outputLimit) writer(w io.Writer) io.Writer {
+	return limitedWriter{limit: l, w: w}
+}
+
+func (l *outputLimit) wasExceeded() bool {
+	l.Lock()
+	defer l.Unlock()
+	return l.reached
+}
+
+type limitedWriter struct {
+	limit *outputLimit
+	w     io.Writer
+}
+
+func (w limitedWriter) Write(p []byte) (int, error) {
+	l := w.limit
+	l.Lock()
+	defer l.Unlock()
+	if l.reached {
+		return 0, errOutputLimitExceeded
+	}
+	if len(p) <= l.remaining {
+		l.remaining -= len(p)
+		return w.w.Write(p)
+	}
+	// Keep what fits, and stop the command
+	l.reached = true
+	l.exceeded()
+	n, err := w.w.Write(p[:l.remaining])
+	l.remaining = 0
+	if err != nil {
+		return n, err
+	}
+	return n, errOutputLimitExceeded
+}
+
+// streamWriter sends what is written to it as a chunk of a command's output.
+type streamWriter struct {
+	stream Kubectl_StreamKubectlCmdServer
+	kind   KubectlOutput_Stream
+}
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// The buffer may be reused once we return
+	data := append([]byte{}, p...)
+	if err := w.stream.Send(&KubectlOutput{Stream: w.kind, Data: data}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
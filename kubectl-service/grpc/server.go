@@ -1,7 +1,11 @@
 package grpc
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -10,10 +14,12 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/weaveworks/common/instrument"
-	"github.com/weaveworks/service/common/collections"
+	"github.com/weaveworks/service/kubectl-service/policy"
 )
 
 // Prometheus metrics for kubectl commands.
@@ -25,8 +31,16 @@ var kubectlCollector = instrument.NewHistogramCollectorFromOpts(prometheus.Histo
 	Buckets:   prometheus.DefBuckets,
 })
 
+var kubectlRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: "kubectl_service",
+	Subsystem: "kubectl",
+	Name:      "running_commands",
+	Help:      "Number of kubectl commands running.",
+})
+
 func init() {
 	kubectlCollector.Register()
+	prometheus.MustRegister(kubectlRunning)
 }
 
 const (
@@ -34,10 +48,34 @@ const (
 	internalServerError = "500"
 )
 
+// ErrTimeout is returned for kubectl commands which were killed for running
+// for too long.
+var ErrTimeout = errors.New("kubectl command timed out")
+
+// ServerConfig holds the server's limits on kubectl commands.
+type ServerConfig struct {
+	MaxDuration    time.Duration
+	MaxOutputBytes int
+	MaxConcurrent  int
+	Policy         policy.Config
+}
+
+// RegisterFlags registers configuration variables.
+func (c *ServerConfig) RegisterFlags(f *flag.FlagSet) {
+	f.DurationVar(&c.MaxDuration, "kubectl.max-duration", 5*time.Minute, "Longest a kubectl command may run for before it is killed. Callers' deadlines are honoured if sooner.")
+	f.IntVar(&c.MaxOutputBytes, "kubectl.max-output-bytes", 1024*1024, "Most output a kubectl command may write before it is killed.")
+	f.IntVar(&c.MaxConcurrent, "kubectl.max-concurrent", 16, "Most kubectl commands run at once. Others wait for one to finish.")
+	c.Policy.RegisterFlags(f)
+}
+
 // Server implements KubectlServer.
 type Server struct {
-	versions *collections.Trie
+	binaries binaries
 	runner   KubectlRunner
+	cfg      ServerConfig
+	policy   *policy.Policy
+	// Holds a value for each command running.
+	slots chan struct{}
 }
 
 const (
@@ -46,16 +84,27 @@ const (
 )
 
 // NewServer creates... a new server.
-func NewServer(runner KubectlRunner) (*Server, error) {
+func NewServer(runner KubectlRunner, cfg ServerConfig) (*Server, error) {
+	// With no slots, every command would wait forever.
+	if cfg.MaxConcurrent < 1 {
+		return nil, fmt.Errorf("-kubectl.max-concurrent must be at least 1, got %d", cfg.MaxConcurrent)
+	}
 	versions, err := listSupportedVersions()
 	if err != nil {
 		return nil, err
 	}
-	log.Infof("Supported Kubernetes versions: {%v}", strings.Join(versions, ", "))
+	log.Infof("Available kubectl versions: {%v}", strings.Join(versions, ", "))
+	return newServer(runner, cfg, versions), nil
+}
+
+func newServer(runner KubectlRunner, cfg ServerConfig, versions []string) *Server {
 	return &Server{
-		versions: versionsTrie(versions),
+		binaries: newBinaries(versions),
 		runner:   runner,
-	}, nil
+		cfg:      cfg,
+		policy:   policy.New(cfg.Policy),
+		slots:    make(chan struct{}, cfg.MaxConcurrent),
+	}
 }
 
 func listSupportedVersions() ([]string, error) {
@@ -72,32 +121,138 @@ func listSupportedVersions() ([]string, error) {
 	return supportedVersions, nil
 }
 
-func versionsTrie(versions []string) *collections.Trie {
-	trie := collections.NewTrie()
-	for _, version := range versions {
-		trie.Add(version)
-	}
-	return trie
-}
-
 // RunKubectlCmd executes the provided kubectl command against the specified cluster.
 func (s Server) RunKubectlCmd(ctx context.Context, req *KubectlRequest) (*KubectlReply, error) {
-	kubeCfgFile, err := writeToFile(req.Kubeconfig)
+	var out bytes.Buffer
+	result, err := s.run(ctx, req, &out, &out)
+	log.Infof("Out: \n%v\nErr: \n%v\n", out.String(), err)
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(kubeCfgFile.Name())
-	version := s.findCompatibleVersionOrDefaultToLatest(req.Version)
-	out, err := s.runner.RunCmd(kubeCfgFile.Name(), version, req.Args)
-	log.Infof("Out: \n%v\nErr: \n%v\n", string(out), err)
-	if err != nil {
-		return nil, err
+	if result.exitCode != 0 && !result.truncated {
+		return nil, fmt.Errorf("exit status %d", result.exitCode)
 	}
 	return &KubectlReply{
-		Output: string(out),
+		Output:         out.String(),
+		Truncated:      result.truncated,
+		KubectlVersion: result.kubectlVersion,
 	}, nil
 }
 
+// StreamKubectlCmd executes the provided kubectl command against the specified cluster, streaming its output.
+func (s Server) StreamKubectlCmd(req *KubectlRequest, stream Kubectl_StreamKubectlCmdServer) error {
+	stdout := streamWriter{stream: stream, kind: STDOUT}
+	stderr := streamWriter{stream: stream, kind: STDERR}
+	result, err := s.run(stream.Context(), req, stdout, stderr)
+	if err != nil {
+		return streamError(err)
+	}
+	return stream.Send(&KubectlOutput{
+		Exited:         true,
+		ExitCode:       int32(result.exitCode),
+		Truncated:      result.truncated,
+		KubectlVersion: result.kubectlVersion,
+	})
+}
+
+// streamError turns errors running commands into gRPC errors, as streams
+// don't go through render.GRPCErrorInterceptor.
+func streamError(err error) error {
+	switch err.(type) {
+	case *policy.Violation:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case *IncompatibleVersionError:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	switch err {
+	case ErrTimeout:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case context.Canceled:
+		return status.Error(codes.Canceled, err.Error())
+	}
+	log.Errorf("Error running kubectl: %v", err)
+	return err
+}
+
+// result is how a command went.
+type result struct {
+	exitCode int
+	// Set if the command was killed for writing too much output.
+	truncated      bool
+	kubectlVersion string
+}
+
+// run runs a kubectl command within the server's limits, writing its output
+// to stdout and stderr. Commands which write too much output are killed, and
+// are reported as truncated rather than failed. Commands the policy doesn't
+// allow are rejected with a *policy.Violation, and those which are only
+// validated are not run.
+func (s Server) run(ctx context.Context, req *KubectlRequest, stdout, stderr io.Writer) (result, error) {
+	if err := s.policy.Check(req.Args); err != nil {
+		// Only the violation is logged: arguments may hold credentials.
+		log.Warnf("Rejected kubectl command: %v", err)
+		return result{}, err
+	}
+	if req.ValidateOnly {
+		return result{}, nil
+	}
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return result{}, contextError(ctx.Err())
+	}
+	kubectlRunning.Inc()
+	defer kubectlRunning.Dec()
+
+	kubeCfgFile, err := writeToFile(req.Kubeconfig)
+	if err != nil {
+		return result{}, err
+	}
+	defer os.Remove(kubeCfgFile.Name())
+
+	cmdCtx, cancel := context.WithTimeout(ctx, s.cfg.MaxDuration)
+	defer cancel()
+	version, err := s.kubectlVersion(cmdCtx, req, kubeCfgFile.Name())
+	if cmdCtx.Err() != nil {
+		return result{}, contextError(cmdCtx.Err())
+	} else if err != nil {
+		return result{}, err
+	}
+	limit := &outputLimit{remaining: s.cfg.MaxOutputBytes, exceeded: cancel}
+	exitCode, err := s.runner.RunCmd(cmdCtx, kubeCfgFile.Name(), version, req.Args, limit.writer(stdout), limit.writer(stderr))
+	if limit.wasExceeded() {
+		return result{exitCode: exitCode, truncated: true, kubectlVersion: version}, nil
+	}
+	if cmdCtx.Err() != nil {
+		return result{}, contextError(cmdCtx.Err())
+	}
+	return result{exitCode: exitCode, kubectlVersion: version}, err
+}
+
+// kubectlVersion picks the kubectl to run a command with, from the cluster's
+// version, which is either given or discovered.
+func (s Server) kubectlVersion(ctx context.Context, req *KubectlRequest, kubeCfgFileName string) (string, error) {
+	serverVersion := req.Version
+	if req.DiscoverVersion {
+		discovered, err := s.discoverVersion(ctx, kubeCfgFileName)
+		if err != nil {
+			return "", err
+		}
+		serverVersion = discovered
+	}
+	return s.binaries.compatible(serverVersion)
+}
+
+// contextError is the error returned for commands which ran out of time, or
+// whose callers went away.
+func contextError(err error) error {
+	if err == context.DeadlineExceeded {
+		return ErrTimeout
+	}
+	return err
+}
+
 func writeToFile(kubeconfig []byte) (*os.File, error) {
 	tmpfile, err := ioutil.TempFile("/tmp", "kubeconfig")
 	if err != nil {
@@ -112,17 +267,12 @@ func writeToFile(kubeconfig []byte) (*os.File, error) {
 	return tmpfile, nil
 }
 
-func (s Server) findCompatibleVersionOrDefaultToLatest(version string) string {
-	if compatVersion, ok := s.versions.BestMatch(version); ok {
-		return compatVersion
-	}
-	return latest
-}
-
 // KubectlRunner is the interface for kubectl commands' runners.
 // This abstraction is mainly to be able to do DI for testing.
 type KubectlRunner interface {
-	RunCmd(kubeCfgFileName, version string, args []string) ([]byte, error)
+	// RunCmd runs a command, writing its output to stdout and stderr as it
+	// goes, and returns its exit code. The command is killed once ctx is done.
+	RunCmd(ctx context.Context, kubeCfgFileName, version string, args []string, stdout, stderr io.Writer) (int, error)
 }
 
 // DefaultKubectlRunner is the canonical implementation of KubectlRunner.
@@ -130,19 +280,26 @@ type DefaultKubectlRunner struct {
 }
 
 // RunCmd runs the provided command on the Kubernetes cluster targeted by the provided kubeconfig using the provided version of kubectl.
-func (r DefaultKubectlRunner) RunCmd(kubeCfgFileName, version string, rawArgs []string) ([]byte, error) {
+func (r DefaultKubectlRunner) RunCmd(ctx context.Context, kubeCfgFileName, version string, rawArgs []string, stdout, stderr io.Writer) (int, error) {
 	cmd, args := cmdAndArgs(kubeCfgFileName, version, rawArgs)
 	log.Infof("Running: %v %v", cmd, args)
 	start := time.Now()
 	kubectlCollector.Before(cmd, start)
-	command := exec.Command(cmd, args...)
-	out, err := command.CombinedOutput()
-	if err != nil {
+	command := exec.CommandContext(ctx, cmd, args...)
+	command.Stdout = stdout
+	command.Stderr = stderr
+	err := command.Run()
+	exitCode := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		// Failing is up to the caller: they get the exit code
+		exitCode, err = exitErr.ExitCode(), nil
+	}
+	if err != nil || exitCode != 0 {
 		kubectlCollector.After(cmd, internalServerError, start)
 	} else {
 		kubectlCollector.After(cmd, ok, start)
 	}
-	return out, err
+	return exitCode, err
 }
 
 func cmdAndArgs(kubeCfgFileName, version string, rawArgs []string) (string, []string) {
@@ -153,15 +310,53 @@ func cmdAndArgs(kubeCfgFileName, version string, rawArgs []string) (string, []st
 	return cmd, args
 }
 
+// NoOpOutput is a chunk of the output of a NoOpKubectlRunner's commands.
+type NoOpOutput struct {
+	Stderr bool
+	Data   string
+}
+
 // NoOpKubectlRunner is a no-op implementation of KubectlRunner.
 // This implementation is mostly useful for testing.
 type NoOpKubectlRunner struct {
+	// Output is written by commands, one chunk at a time, to emulate
+	// kubectl's streams. Commands write the dry run message if there is none.
+	Output []NoOpOutput
+	// Delay is waited for before writing each chunk, to emulate slow
+	// commands.
+	Delay    time.Duration
+	ExitCode int
+	// ServerVersion is reported by `kubectl version` commands, so that
+	// clusters' versions can be discovered.
+	ServerVersion string
 }
 
 // RunCmd actually does NOT run anything for this implementation. It just logs the command it could have run with a different runner.
-func (r NoOpKubectlRunner) RunCmd(kubeCfgFileName, version string, rawArgs []string) ([]byte, error) {
+func (r NoOpKubectlRunner) RunCmd(ctx context.Context, kubeCfgFileName, version string, rawArgs []string, stdout, stderr io.Writer) (int, error) {
 	cmd, args := cmdAndArgs(kubeCfgFileName, version, rawArgs)
 	msg := fmt.Sprintf("Dry run: %v %v", cmd, args)
 	log.Infof(msg)
-	return []byte(msg), nil
+	output := r.Output
+	if r.ServerVersion != "" && len(rawArgs) > 0 && rawArgs[0] == "version" {
+		output = []NoOpOutput{{Data: fmt.Sprintf(`{"serverVersion":{"gitVersion":%q}}`, r.ServerVersion)}}
+	}
+	if len(output) == 0 {
+		output = []NoOpOutput{{Data: msg}}
+	}
+	for _, o := range output {
+		select {
+		case <-time.After(r.Delay):
+		case <-ctx.Done():
+			// Like a killed process
+			return -1, ctx.Err()
+		}
+		w := stdout
+		if o.Stderr {
+			w = stderr
+		}
+		if _, err := io.WriteString(w, o.Data); err != nil {
+			return -1, err
+		}
+	}
+	return r.ExitCode, nil
 }
@@ -0,0 +1,159 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	google_grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/weaveworks/service/kubectl-service/policy"
+)
+
+var testConfig = ServerConfig{
+	MaxDuration:    time.Second,
+	MaxOutputBytes: 1024,
+	MaxConcurrent:  2,
+	Policy:         policy.Config{AllowedCommands: []string{"get"}},
+}
+
+var testVersions = []string{"1.15.11", "1.16.9", "1.17.5"}
+
+var getPods = &KubectlRequest{Version: "1.17.5", Args: []string{"get", "pods"}}
+
+// fakeStream collects what is sent to a client.
+type fakeStream struct {
+	google_grpc.ServerStream
+	ctx     context.Context
+	outputs []*KubectlOutput
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStream) Send(output *KubectlOutput) error {
+	s.outputs = append(s.outputs, output)
+	return nil
+}
+
+func stream(t *testing.T, s *Server, ctx context.Context) ([]*KubectlOutput, error) {
+	return streamRequest(t, s, ctx, getPods)
+}
+
+func streamRequest(t *testing.T, s *Server, ctx context.Context, req *KubectlRequest) ([]*KubectlOutput, error) {
+	fake := &fakeStream{ctx: ctx}
+	err := s.StreamKubectlCmd(req, fake)
+	return fake.outputs, err
+}
+
+func TestStreamKubectlCmd(t *testing.T) {
+	s := newServer(NoOpKubectlRunner{
+		Output: []NoOpOutput{
+			{Data: "pod/a created\n"},
+			{Stderr: true, Data: "error: b is invalid\n"},
+		},
+		ExitCode: 1,
+	}, testConfig, testVersions)
+
+	outputs, err := stream(t, s, context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*KubectlOutput{
+		{Stream: STDOUT, Data: []byte("pod/a created\n")},
+		{Stream: STDERR, Data: []byte("error: b is invalid\n")},
+		{Exited: true, ExitCode: 1, KubectlVersion: "1.17.5"},
+	}, outputs)
+
+	// Non-streaming calls combine the streams, and fail
+	_, err = s.RunKubectlCmd(context.Background(), getPods)
+	assert.EqualError(t, err, "exit status 1")
+}
+
+func TestStreamKubectlCmd_Limits(t *testing.T) {
+	slow := NoOpKubectlRunner{Output: []NoOpOutput{{Data: "done"}}, Delay: time.Second}
+	cfg := testConfig
+	cfg.MaxDuration = 10 * time.Millisecond
+	_, err := stream(t, newServer(slow, cfg, testVersions), context.Background())
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	_, err = newServer(slow, cfg, testVersions).RunKubectlCmd(context.Background(), getPods)
+	assert.Equal(t, ErrTimeout, err)
+
+	// Callers' deadlines are honoured, as is them going away
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = stream(t, newServer(slow, testConfig, testVersions), ctx)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = stream(t, newServer(slow, testConfig, testVersions), ctx)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	// Commands writing too much are stopped
+	chatty := NoOpKubectlRunner{Output: []NoOpOutput{{Data: "hello"}, {Stderr: true, Data: " world"}, {Data: "!"}}}
+	cfg = testConfig
+	cfg.MaxOutputBytes = 8
+	outputs, err := stream(t, newServer(chatty, cfg, testVersions), context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*KubectlOutput{
+		{Stream: STDOUT, Data: []byte("hello")},
+		{Stream: STDERR, Data: []byte(" wo")},
+		{Exited: true, ExitCode: -1, Truncated: true, KubectlVersion: "1.17.5"},
+	}, outputs)
+	reply, err := newServer(chatty, cfg, testVersions).RunKubectlCmd(context.Background(), getPods)
+	require.NoError(t, err)
+	assert.Equal(t, &KubectlReply{Output: "hello wo", Truncated: true, KubectlVersion: "1.17.5"}, reply)
+}
+
+func TestStreamKubectlCmd_Concurrency(t *testing.T) {
+	cfg := testConfig
+	cfg.MaxConcurrent = 1
+	s := newServer(NoOpKubectlRunner{Delay: 100 * time.Millisecond}, cfg, testVersions)
+
+	done := make(chan error)
+	go func() {
+		_, err := stream(t, s, context.Background())
+		done <- err
+	}()
+	// Wait for the first command to start
+	for len(s.slots) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// Others wait for it to finish
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := stream(t, s, ctx)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	outputs, err := stream(t, s, context.Background())
+	require.NoError(t, err)
+	assert.Len(t, outputs, 2)
+	assert.NoError(t, <-done)
+}
+
+func TestNewServer_MaxConcurrent(t *testing.T) {
+	cfg := testConfig
+	cfg.MaxConcurrent = 0
+	_, err := NewServer(nil, cfg)
+	assert.Error(t, err)
+}
+
+func TestStreamKubectlCmd_Policy(t *testing.T) {
+	runner := NoOpKubectlRunner{Output: []NoOpOutput{{Data: "ran"}}}
+	s := newServer(runner, testConfig, testVersions)
+
+	exec := &KubectlRequest{Args: []string{"exec", "pod", "--", "sh"}}
+	_, err := streamRequest(t, s, context.Background(), exec)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.RunKubectlCmd(context.Background(), exec)
+	assert.IsType(t, &policy.Violation{}, err)
+
+	// Commands can be checked without being run
+	outputs, err := streamRequest(t, s, context.Background(), &KubectlRequest{Args: []string{"get", "pods"}, ValidateOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, []*KubectlOutput{{Exited: true}}, outputs)
+	_, err = s.RunKubectlCmd(context.Background(), &KubectlRequest{Args: exec.Args, ValidateOnly: true})
+	assert.IsType(t, &policy.Violation{}, err)
+}
@@ -0,0 +1,123 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// IncompatibleVersionError is returned when none of the kubectl binaries
+// supports the cluster's version of Kubernetes.
+type IncompatibleVersionError struct {
+	ServerVersion string
+	Supported     []string
+}
+
+func (e *IncompatibleVersionError) Error() string {
+	return fmt.Sprintf("no kubectl is compatible with Kubernetes %q: kubectl %s are available, each of which supports clusters up to one minor version older or newer", e.ServerVersion, strings.Join(e.Supported, ", "))
+}
+
+// kubeVersion is a Kubernetes or kubectl version.
+type kubeVersion struct {
+	major, minor, patch int
+}
+
+// Matches e.g. v1.17.3-gke.0, 1.17.5 or 1.17+, as reported by clusters.
+var versionRegexp = regexp.MustCompile(`^v?(\d+)\.(\d+)\+?(?:\.(\d+))?`)
+
+func parseVersion(version string) (kubeVersion, bool) {
+	m := versionRegexp.FindStringSubmatch(strings.TrimSpace(version))
+	if m == nil {
+		return kubeVersion{}, false
+	}
+	var v kubeVersion
+	v.major, _ = strconv.Atoi(m[1])
+	v.minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.patch, _ = strconv.Atoi(m[3])
+	}
+	return v, true
+}
+
+// binary is a kubectl binary, named after its version.
+type binary struct {
+	name    string
+	version kubeVersion
+}
+
+// binaries are the kubectl binaries available, newest first.
+type binaries []binary
+
+func newBinaries(names []string) binaries {
+	var bs binaries
+	for _, name := range names {
+		if v, ok := parseVersion(name); ok {
+			bs = append(bs, binary{name: name, version: v})
+		}
+	}
+	sort.Slice(bs, func(i, j int) bool {
+		a, b := bs[i].version, bs[j].version
+		if a.major != b.major {
+			return a.major > b.major
+		}
+		if a.minor != b.minor {
+			return a.minor > b.minor
+		}
+		return a.patch > b.patch
+	})
+	return bs
+}
+
+func (bs binaries) names() []string {
+	names := []string{}
+	for _, b := range bs {
+		names = append(names, b.name)
+	}
+	return names
+}
+
+// compatible returns the kubectl binary to use with a version of Kubernetes.
+// kubectl supports clusters one minor version older or newer than itself, so
+// the newest binary of the same minor version is preferred, then the newer
+// minor version, then the older one.
+func (bs binaries) compatible(serverVersion string) (string, error) {
+	v, ok := parseVersion(serverVersion)
+	if ok {
+		for _, skew := range []int{0, 1, -1} {
+			for _, b := range bs {
+				if b.version.major == v.major && b.version.minor == v.minor+skew {
+					return b.name, nil
+				}
+			}
+		}
+	}
+	return "", &IncompatibleVersionError{ServerVersion: serverVersion, Supported: bs.names()}
+}
+
+// discoverVersion asks a cluster for its version, using the latest kubectl.
+func (s Server) discoverVersion(ctx context.Context, kubeCfgFileName string) (string, error) {
+	var out bytes.Buffer
+	exitCode, err := s.runner.RunCmd(ctx, kubeCfgFileName, latest, []string{"version", "--output=json"}, &out, ioutil.Discard)
+	if err != nil {
+		return "", err
+	}
+	if exitCode != 0 {
+		return "", fmt.Errorf("failed to discover the cluster's version: exit status %d", exitCode)
+	}
+	var reply struct {
+		ServerVersion struct {
+			GitVersion string `json:"gitVersion"`
+		} `json:"serverVersion"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &reply); err != nil || reply.ServerVersion.GitVersion == "" {
+		return "", fmt.Errorf("failed to discover the cluster's version from %q", out.String())
+	}
+	return reply.ServerVersion.GitVersion, nil
+}
@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCompatible(t *testing.T) {
+	bs := newBinaries(testVersions)
+	for _, tc := range []struct {
+		serverVersion string
+		kubectl       string // Empty if none is compatible
+	}{
+		{"1.17.5", "1.17.5"},
+		{"v1.17.3-gke.0", "1.17.5"},
+		{"1.16", "1.16.9"},
+		{"1.18.2", "1.17.5"},
+		{"1.14.10-gke.27", "1.15.11"},
+		{"1.17+", "1.17.5"},
+		{"1.13.12", ""},
+		{"1.19.0", ""},
+		{"2.0.0-gke.0", ""},
+		{"", ""},
+		{"latest", ""},
+	} {
+		t.Run(tc.serverVersion, func(t *testing.T) {
+			kubectl, err := bs.compatible(tc.serverVersion)
+			if tc.kubectl == "" {
+				require.IsType(t, &IncompatibleVersionError{}, err)
+				assert.Equal(t, []string{"1.17.5", "1.16.9", "1.15.11"}, err.(*IncompatibleVersionError).Supported)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.kubectl, kubectl)
+		})
+	}
+}
+
+func TestDiscoverVersion(t *testing.T) {
+	s := newServer(NoOpKubectlRunner{ServerVersion: "v1.16.8-eks-e16311"}, testConfig, testVersions)
+
+	// The cluster's version is used rather than the one given
+	reply, err := s.RunKubectlCmd(context.Background(), &KubectlRequest{Version: "1.17.5", DiscoverVersion: true, Args: []string{"get", "pods"}})
+	require.NoError(t, err)
+	assert.Equal(t, "1.16.9", reply.KubectlVersion)
+	assert.Contains(t, reply.Output, "Dry run: /kubectl/1.16.9")
+
+	s = newServer(NoOpKubectlRunner{ServerVersion: "v1.12.10"}, testConfig, testVersions)
+	_, err = s.RunKubectlCmd(context.Background(), &KubectlRequest{DiscoverVersion: true, Args: []string{"get", "pods"}})
+	assert.IsType(t, &IncompatibleVersionError{}, err)
+	_, err = streamRequest(t, s, context.Background(), &KubectlRequest{DiscoverVersion: true, Args: []string{"get", "pods"}})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Clusters which can't be asked fail the command
+	s = newServer(NoOpKubectlRunner{ExitCode: 1}, testConfig, testVersions)
+	_, err = s.RunKubectlCmd(context.Background(), &KubectlRequest{DiscoverVersion: true, Args: []string{"get", "pods"}})
+	assert.EqualError(t, err, "failed to discover the cluster's version: exit status 1")
+}
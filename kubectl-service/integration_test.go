@@ -5,6 +5,7 @@ package main_test
 
 import (
 	"context"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,15 +36,42 @@ func TestGetPods(t *testing.T) {
 	assert.NoError(t, err)
 	// "1.17.3-gke.0"'s closest match is 1.17.5 which is packaged with the current version of kubectl-service, hence it was selected to run the provided command:
 	assert.Regexp(t, "Dry run: /kubectl/1\\.17\\.5 \\[--kubeconfig=/tmp/kubeconfig[0-9]+ get pods --all-namespaces\\]", resp.Output)
+	assert.Equal(t, "1.17.5", resp.KubectlVersion)
 
-	resp, err = client.RunKubectlCmd(context.Background(), &grpc.KubectlRequest{
+	_, err = client.RunKubectlCmd(context.Background(), &grpc.KubectlRequest{
 		Version:    "2.0.0-gke.0",
 		Kubeconfig: kubeCfgYAML,
 		Args:       []string{"get", "pods", "--all-namespaces"},
 	})
+	// "2.0.0-gke.0" has no compatible version packaged in the current version of kubectl-service, hence the command was refused:
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no kubectl is compatible with Kubernetes \"2.0.0-gke.0\"")
+}
+
+func TestStreamGetPods(t *testing.T) {
+	client := newClient(t)
+	defer client.Close()
+
+	stream, err := client.StreamKubectlCmd(context.Background(), &grpc.KubectlRequest{
+		Version:    "1.17.5",
+		Kubeconfig: newKubeConfigYAML(t),
+		Args:       []string{"get", "pods", "--all-namespaces"},
+	})
 	assert.NoError(t, err)
-	// "2.0.0-gke.0" has no compatible version packaged in the current version of kubectl-service, hence it defaulted to "latest" to run the provided command:
-	assert.Regexp(t, "Dry run: /kubectl/latest \\[--kubeconfig=/tmp/kubeconfig[0-9]+ get pods --all-namespaces\\]", resp.Output)
+	var outputs []*grpc.KubectlOutput
+	for {
+		output, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		outputs = append(outputs, output)
+	}
+	assert.Len(t, outputs, 2)
+	assert.Equal(t, grpc.STDOUT, outputs[0].Stream)
+	assert.Regexp(t, "Dry run: /kubectl/1\\.17\\.5 \\[--kubeconfig=/tmp/kubeconfig[0-9]+ get pods --all-namespaces\\]", string(outputs[0].Data))
+	assert.True(t, outputs[1].Exited)
+	assert.Zero(t, outputs[1].ExitCode)
 }
 
 func newClient(t *testing.T) *grpc.Client {
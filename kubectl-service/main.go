@@ -23,7 +23,10 @@ func main() {
 	flag.CommandLine.IntVar(&serverConfig.GRPCListenPort, "grpc-port", 4772, "gRPC port to listen on")
 	var (
 		dryRun = flag.Bool("dry-run", false, "Do NOT actually run kubectl, but simply log the command.")
+
+		kubectlConfig grpc.ServerConfig
 	)
+	kubectlConfig.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
 	log.Infof("kubectl-service configured to listen on ports %d (HTTP) and %d (gRPC)", serverConfig.HTTPListenPort, serverConfig.GRPCListenPort)
@@ -33,7 +36,7 @@ func main() {
 	}
 	defer serv.Shutdown()
 
-	gserv, err := grpc.NewServer(newRunner(*dryRun))
+	gserv, err := grpc.NewServer(newRunner(*dryRun), kubectlConfig)
 	if err != nil {
 		log.Fatalf("Failed to create kubectl-service's gRPC server: %v", err)
 	}
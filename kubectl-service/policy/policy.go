@@ -0,0 +1,331 @@
+package policy
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reasons commands are rejected for, as reported in metrics.
+const (
+	ReasonCommand   = "command"
+	ReasonFlag      = "flag"
+	ReasonNamespace = "namespace"
+)
+
+var rejectedCommands = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "kubectl_service",
+	Subsystem: "policy",
+	Name:      "rejected_commands",
+	Help:      "Number of kubectl commands rejected by the policy.",
+}, []string{"reason"})
+
+func init() {
+	prometheus.MustRegister(rejectedCommands)
+}
+
+var (
+	// DefaultAllowedCommands are the commands gcp-service needs to install
+	// agents, and to find out about clusters.
+	DefaultAllowedCommands = []string{
+		"apply",
+		"cluster-info",
+		"config current-context",
+		"config view",
+		"create clusterrolebinding",
+		"create namespace",
+		"create secret",
+		"delete",
+		"describe",
+		"get",
+		"rollout status",
+		"version",
+	}
+	// DefaultForbiddenFlags are those which would let commands use other
+	// credentials, or another cluster, than the kubeconfig they are run with.
+	DefaultForbiddenFlags = []string{
+		"--as",
+		"--as-group",
+		"--certificate-authority",
+		"--client-certificate",
+		"--client-key",
+		"--cluster",
+		"--context",
+		"--insecure-skip-tls-verify",
+		"--kubeconfig",
+		"--password",
+		"--server",
+		"-s",
+		"--token",
+		"--username",
+	}
+)
+
+// Flags which take a value, which may be given as the next argument. Along
+// with boolFlags, these are the only flags commands may use: others, e.g.
+// --cache-dir, --kustomize or --from-file, would let commands read or write
+// the service's own files.
+var valueFlags = map[string]struct{}{
+	"--as": {}, "--as-group": {}, "--certificate-authority": {},
+	"--client-certificate": {}, "--client-key": {}, "--cluster": {}, "--clusterrole": {},
+	"--container": {}, "--context": {}, "--field-selector": {}, "--filename": {},
+	"--from-literal": {}, "--group": {}, "--image": {},
+	"--kubeconfig": {}, "--namespace": {}, "--output": {},
+	"--password": {}, "--replicas": {}, "--request-timeout": {}, "--role": {},
+	"--selector": {}, "--server": {}, "--serviceaccount": {}, "--since": {},
+	"--tail": {}, "--timeout": {}, "--token": {}, "--type": {},
+	"--user": {}, "--username": {}, "--v": {},
+	"-c": {}, "-f": {}, "-l": {}, "-n": {}, "-o": {}, "-s": {}, "-v": {},
+}
+
+// Flags which never take the next argument as their value.
+var boolFlags = map[string]struct{}{
+	"--all": {}, "--all-namespaces": {}, "--cascade": {}, "--client": {},
+	"--dry-run": {}, "--export": {}, "--flatten": {}, "--follow": {}, "--force": {},
+	"--ignore-not-found": {}, "--insecure-skip-tls-verify": {}, "--minify": {},
+	"--no-headers": {}, "--now": {}, "--overwrite": {}, "--previous": {},
+	"--prune": {}, "--quiet": {}, "--raw": {}, "--record": {}, "--recursive": {},
+	"--save-config": {}, "--server-side": {}, "--short": {}, "--show-kind": {},
+	"--show-labels": {}, "--stdin": {}, "--timestamps": {}, "--tty": {},
+	"--validate": {}, "--wait": {}, "--watch": {}, "--watch-only": {},
+	"-A": {}, "-R": {}, "-i": {}, "-q": {}, "-t": {}, "-w": {},
+}
+
+// Config configures which kubectl commands are allowed.
+type Config struct {
+	// AllowedCommands are verbs, optionally followed by subcommands, e.g.
+	// "get" or "create namespace".
+	AllowedCommands []string
+	// ForbiddenFlags are long, or short, flags, e.g. "--token" or "-s".
+	ForbiddenFlags []string
+	// AllowedNamespaces restricts which namespaces commands may use, and
+	// create. Any namespace may be used when empty.
+	AllowedNamespaces []string
+}
+
+// RegisterFlags registers configuration variables.
+func (c *Config) RegisterFlags(f *flag.FlagSet) {
+	c.AllowedCommands = DefaultAllowedCommands
+	c.ForbiddenFlags = DefaultForbiddenFlags
+	f.Var((*list)(&c.AllowedCommands), "policy.allowed-commands", "Comma-separated kubectl commands which may be run, e.g. \"get,create namespace\".")
+	f.Var((*list)(&c.ForbiddenFlags), "policy.forbidden-flags", "Comma-separated kubectl flags which may not be used, e.g. \"--token,-s\".")
+	f.Var((*list)(&c.AllowedNamespaces), "policy.allowed-namespaces", "Comma-separated namespaces which kubectl commands may use. All are allowed if empty.")
+}
+
+// list is a comma-separated flag, which replaces its default when set.
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(value string) error {
+	*l = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// Violation is the error for commands which the policy doesn't allow.
+type Violation struct {
+	Reason  string
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("kubectl command not allowed: %s", v.Message)
+}
+
+// Policy decides which kubectl commands may be run.
+type Policy struct {
+	commands       [][]string
+	forbiddenFlags map[string]struct{}
+	namespaces     map[string]struct{}
+}
+
+// New creates a policy.
+func New(cfg Config) *Policy {
+	p := &Policy{
+		forbiddenFlags: toSet(cfg.ForbiddenFlags),
+		namespaces:     toSet(cfg.AllowedNamespaces),
+	}
+	for _, command := range cfg.AllowedCommands {
+		p.commands = append(p.commands, strings.Fields(command))
+	}
+	return p
+}
+
+func toSet(items []string) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, item := range items {
+		set[item] = struct{}{}
+	}
+	return set
+}
+
+// Check returns a *Violation if a command, given by its arguments, isn't
+// allowed.
+func (p *Policy) Check(args []string) error {
+	cmd, err := parse(args)
+	if err == nil {
+		err = p.check(cmd)
+	}
+	if v, ok := err.(*Violation); ok {
+		rejectedCommands.WithLabelValues(v.Reason).Inc()
+	}
+	return err
+}
+
+func (p *Policy) check(cmd command) error {
+	for _, f := range cmd.flags {
+		if _, ok := p.forbiddenFlags[f.name]; ok {
+			return &Violation{Reason: ReasonFlag, Message: fmt.Sprintf("flag %s is forbidden", f.name)}
+		}
+		switch f.name {
+		case "--filename", "-f":
+			// Manifests may only be fetched, not read from the service's files
+			if u, err := url.Parse(f.value); err != nil || u.Scheme != "https" || u.Host == "" {
+				return &Violation{Reason: ReasonFlag, Message: fmt.Sprintf("flag %s only accepts https URLs", f.name)}
+			}
+		case "--output", "-o":
+			// e.g. -o jsonpath-file=/etc/passwd
+			if strings.Contains(f.value, "file") {
+				return &Violation{Reason: ReasonFlag, Message: fmt.Sprintf("flag %s may not read templates from files", f.name)}
+			}
+		}
+	}
+	if !p.allowsCommand(cmd.positionals) {
+		if len(cmd.positionals) == 0 {
+			return &Violation{Reason: ReasonCommand, Message: "no command given"}
+		}
+		verb := cmd.positionals
+		if len(verb) > 2 {
+			verb = verb[:2]
+		}
+		return &Violation{Reason: ReasonCommand, Message: fmt.Sprintf("command %q is not allowed", strings.Join(verb, " "))}
+	}
+	if len(p.namespaces) == 0 {
+		return nil
+	}
+	namespaces := []string{}
+	for _, f := range cmd.flags {
+		switch f.name {
+		case "--all-namespaces", "-A":
+			if f.value != "false" {
+				return &Violation{Reason: ReasonNamespace, Message: "commands may not use all namespaces"}
+			}
+		case "--namespace", "-n":
+			namespaces = append(namespaces, f.value)
+		}
+	}
+	if len(namespaces) == 0 {
+		namespaces = append(namespaces, "default")
+	}
+	if len(cmd.positionals) >= 3 && cmd.positionals[0] == "create" && (cmd.positionals[1] == "namespace" || cmd.positionals[1] == "ns") {
+		namespaces = append(namespaces, cmd.positionals[2])
+	}
+	for _, namespace := range namespaces {
+		if _, ok := p.namespaces[namespace]; !ok {
+			return &Violation{Reason: ReasonNamespace, Message: fmt.Sprintf("namespace %q is not allowed", namespace)}
+		}
+	}
+	return nil
+}
+
+func (p *Policy) allowsCommand(positionals []string) bool {
+	for _, allowed := range p.commands {
+		if len(allowed) == 0 || len(positionals) < len(allowed) {
+			continue
+		}
+		matches := true
+		for i, word := range allowed {
+			if positionals[i] != word {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// command is a kubectl command, split into its flags and positional
+// arguments, the first of which are its verb and subcommand.
+type command struct {
+	positionals []string
+	flags       []flagArg
+}
+
+type flagArg struct {
+	name  string // Including its dashes, e.g. "--namespace" or "-n"
+	value string
+}
+
+// parse splits a command's arguments. Flags other than those in valueFlags
+// and boolFlags are rejected, even with their value attached, e.g.
+// --log-dir=/tmp: which arguments they take is unknown, so they could hide
+// the command from the policy, and they could use the service's files.
+func parse(args []string) (command, error) {
+	var cmd command
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			// What follows is passed on, e.g. to the containers of exec
+			cmd.positionals = append(cmd.positionals, args[i+1:]...)
+			return cmd, nil
+		case strings.HasPrefix(arg, "--"):
+			f := flagArg{name: arg}
+			eq := strings.Index(arg, "=")
+			if eq >= 0 {
+				f.name, f.value = arg[:eq], arg[eq+1:]
+			}
+			_, isValue := valueFlags[f.name]
+			_, isBool := boolFlags[f.name]
+			switch {
+			case !isValue && !isBool:
+				return cmd, &Violation{Reason: ReasonFlag, Message: fmt.Sprintf("flag %s is unknown", f.name)}
+			case isValue && eq < 0 && i+1 < len(args):
+				f.value = args[i+1]
+				i++
+			}
+			cmd.flags = append(cmd.flags, f)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// Short flags may be combined, e.g. -it, and the last may have
+			// its value attached, e.g. -nkube-system
+			for j := 1; j < len(arg); j++ {
+				f := flagArg{name: "-" + arg[j:j+1]}
+				rest := arg[j+1:]
+				if _, ok := valueFlags[f.name]; ok {
+					f.value = strings.TrimPrefix(rest, "=")
+					if rest == "" && i+1 < len(args) {
+						f.value = args[i+1]
+						i++
+					}
+					cmd.flags = append(cmd.flags, f)
+					break
+				}
+				if _, ok := boolFlags[f.name]; !ok {
+					return cmd, &Violation{Reason: ReasonFlag, Message: fmt.Sprintf("flag %s is unknown", f.name)}
+				}
+				if strings.HasPrefix(rest, "=") {
+					f.value = rest[1:]
+					cmd.flags = append(cmd.flags, f)
+					break
+				}
+				cmd.flags = append(cmd.flags, f)
+			}
+		default:
+			cmd.positionals = append(cmd.positionals, arg)
+		}
+	}
+	return cmd, nil
+}
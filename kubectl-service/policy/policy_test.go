@@ -0,0 +1,89 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/kubectl-service/policy"
+)
+
+func TestCheck(t *testing.T) {
+	defaults := policy.New(policy.Config{
+		AllowedCommands: policy.DefaultAllowedCommands,
+		ForbiddenFlags:  policy.DefaultForbiddenFlags,
+	})
+	restricted := policy.New(policy.Config{
+		AllowedCommands:   []string{"get", "create namespace", "apply"},
+		ForbiddenFlags:    policy.DefaultForbiddenFlags,
+		AllowedNamespaces: []string{"weave", "default"},
+	})
+
+	for _, tc := range []struct {
+		name   string
+		policy *policy.Policy
+		args   []string
+		reason string // Empty if allowed
+	}{
+		{"get", defaults, []string{"get", "pods", "--all-namespaces"}, ""},
+		{"apply", defaults, []string{"apply", "-f", "https://get.weave.works/k8s/agent.yaml"}, ""},
+		{"create namespace", defaults, []string{"create", "namespace", "weave"}, ""},
+		{"create secret, with flags first", defaults, []string{"--namespace=weave", "create", "secret", "generic", "weave-cloud", "--from-literal=token=abc"}, ""},
+		{"config", defaults, []string{"config", "current-context"}, ""},
+		{"no command", defaults, []string{}, policy.ReasonCommand},
+		{"only flags", defaults, []string{"-n", "weave"}, policy.ReasonCommand},
+		{"exec", defaults, []string{"exec", "-it", "pod", "--", "sh"}, policy.ReasonCommand},
+		{"port-forward", defaults, []string{"port-forward", "pod", "8080"}, policy.ReasonCommand},
+		{"create other", defaults, []string{"create", "deployment", "nginx", "--image=nginx"}, policy.ReasonCommand},
+		{"subcommand in flag value", defaults, []string{"create", "-n", "namespace", "x"}, policy.ReasonCommand},
+		{"config set", defaults, []string{"config", "set-credentials", "admin"}, policy.ReasonCommand},
+		{"kubeconfig override", defaults, []string{"--kubeconfig=/etc/kubernetes/admin.conf", "get", "pods"}, policy.ReasonFlag},
+		{"kubeconfig override after", defaults, []string{"get", "pods", "--kubeconfig", "/etc/kubernetes/admin.conf"}, policy.ReasonFlag},
+		{"token", defaults, []string{"get", "secrets", "--token=abc"}, policy.ReasonFlag},
+		{"server", defaults, []string{"-s", "https://10.0.0.1", "get", "pods"}, policy.ReasonFlag},
+		{"server attached", defaults, []string{"-shttps://10.0.0.1", "get", "pods"}, policy.ReasonFlag},
+		{"impersonation", defaults, []string{"get", "pods", "--as=system:admin"}, policy.ReasonFlag},
+		{"boolean flags", defaults, []string{"get", "pods", "--watch", "-w", "--ignore-not-found"}, ""},
+		{"unknown flag with value attached", defaults, []string{"--log-dir=/tmp", "get", "pods"}, policy.ReasonFlag},
+		{"cache dir", defaults, []string{"get", "pods", "--cache-dir=/tmp"}, policy.ReasonFlag},
+		{"local manifest", defaults, []string{"apply", "-f", "/etc/kubernetes/admin.conf"}, policy.ReasonFlag},
+		{"local manifest attached", defaults, []string{"delete", "--filename=manifest.yaml"}, policy.ReasonFlag},
+		{"http manifest", defaults, []string{"apply", "-f", "http://get.weave.works/k8s/agent.yaml"}, policy.ReasonFlag},
+		{"kustomize", defaults, []string{"apply", "-k", "/etc"}, policy.ReasonFlag},
+		{"secret from file", defaults, []string{"create", "secret", "generic", "s", "--from-file=/etc/passwd"}, policy.ReasonFlag},
+		{"template file", defaults, []string{"get", "pods", "-o", "jsonpath-file=/etc/passwd"}, policy.ReasonFlag},
+		{"output", defaults, []string{"get", "pods", "-ojsonpath={.items}"}, ""},
+		{"unknown flag hiding command", defaults, []string{"--log-dir", "get", "exec", "pod", "--", "sh"}, policy.ReasonFlag},
+		{"unknown flag hiding command 2", defaults, []string{"--tls-server-name", "get", "exec", "pod", "--", "sh"}, policy.ReasonFlag},
+		{"unknown flag hiding command 3", defaults, []string{"--profile-output", "get", "exec", "pod", "--", "sh"}, policy.ReasonFlag},
+		{"unknown flag hiding command 4", defaults, []string{"--vmodule", "get", "exec", "pod", "--", "sh"}, policy.ReasonFlag},
+		{"unknown flag hiding command 5", defaults, []string{"--log-file", "get", "exec", "pod", "--", "sh"}, policy.ReasonFlag},
+		{"unknown flag after command", defaults, []string{"get", "pods", "--log-dir", "/tmp"}, policy.ReasonFlag},
+		{"unknown short flag", defaults, []string{"-x", "get", "pods"}, policy.ReasonFlag},
+		{"combined short flags", defaults, []string{"get", "pods", "-wA"}, ""},
+		{"combined short flags hiding server", defaults, []string{"-As", "https://10.0.0.1", "get", "pods"}, policy.ReasonFlag},
+		{"allowed namespace", restricted, []string{"get", "pods", "-n", "weave"}, ""},
+		{"allowed namespace attached", restricted, []string{"get", "pods", "-nweave"}, ""},
+		{"default namespace", restricted, []string{"apply", "-f", "https://get.weave.works/k8s/agent.yaml"}, ""},
+		{"create allowed namespace", restricted, []string{"create", "namespace", "weave"}, ""},
+		{"other namespace", restricted, []string{"get", "pods", "--namespace", "kube-system"}, policy.ReasonNamespace},
+		{"other namespace after allowed", restricted, []string{"get", "pods", "-n", "weave", "-n=kube-system"}, policy.ReasonNamespace},
+		{"create other namespace", restricted, []string{"create", "namespace", "mine"}, policy.ReasonNamespace},
+		{"all namespaces", restricted, []string{"get", "pods", "--all-namespaces"}, policy.ReasonNamespace},
+		{"all namespaces short", restricted, []string{"get", "pods", "-A"}, policy.ReasonNamespace},
+		{"all namespaces combined", restricted, []string{"get", "pods", "-wA"}, policy.ReasonNamespace},
+		{"namespace combined", restricted, []string{"get", "pods", "-wn", "kube-system"}, policy.ReasonNamespace},
+		{"not all namespaces", restricted, []string{"get", "pods", "--all-namespaces=false"}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Check(tc.args)
+			if tc.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.IsType(t, &policy.Violation{}, err)
+			assert.Equal(t, tc.reason, err.(*policy.Violation).Reason)
+		})
+	}
+}
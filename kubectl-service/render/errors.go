@@ -7,16 +7,23 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/weaveworks/common/httpgrpc"
+	kubectl "github.com/weaveworks/service/kubectl-service/grpc"
+	"github.com/weaveworks/service/kubectl-service/policy"
 
 	log "github.com/sirupsen/logrus"
 )
 
 // ErrorStatusCode translates error into HTTP status code.
 func ErrorStatusCode(err error) int {
-	//switch err.(type) {
-	//case *..., *...:
-	//		return http.StatusBadRequest
-	//	}
+	if err == kubectl.ErrTimeout {
+		return http.StatusGatewayTimeout
+	}
+	if _, ok := err.(*policy.Violation); ok {
+		return http.StatusForbidden
+	}
+	if _, ok := err.(*kubectl.IncompatibleVersionError); ok {
+		return http.StatusBadRequest
+	}
 
 	// Just incase there's something sensitive in the error
 	return http.StatusInternalServerError
@@ -33,6 +33,8 @@ const (
 	alertLinkText          = "View firing alerts"
 	deployPage             = "/deploy/services"
 	deployLinkText         = "Weave Cloud Deploy"
+	billingPage            = "/org/billing"
+	billingLinkText        = "Weave Cloud billing"
 )
 
 // handleCreateEvent handles event post requests and log them in DB and queue
@@ -83,7 +85,7 @@ func (em *EventManager) handleCreateEvent(r *http.Request, instanceID string) (i
 	}
 
 	var eventURL, eventURLText string
-	// and link to Deploy page for Flux events
+	// and link to Deploy page for Flux events, and to the billing page for budget events
 	switch e.Type {
 	case types.SyncType,
 		types.PolicyType,
@@ -93,6 +95,9 @@ func (em *EventManager) handleCreateEvent(r *http.Request, instanceID string) (i
 		types.AutoDeployCommitType:
 		eventURLText = deployLinkText
 		eventURL = deployPage
+	case types.BillingBudgetType:
+		eventURLText = billingLinkText
+		eventURL = billingPage
 	}
 
 	link, err := em.getInstanceLink(instanceData.Organization.ExternalID, eventURL)
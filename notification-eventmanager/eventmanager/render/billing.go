@@ -0,0 +1,21 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/weaveworks/service/notification-eventmanager/types"
+)
+
+func parseBudgetData(data types.BudgetData) *parsedData {
+	color := "warning"
+	if data.Threshold >= 100 {
+		color = "danger"
+	}
+	currency := escapeHTML(data.Currency)
+	return &parsedData{
+		Title: fmt.Sprintf("Weave Cloud cost reached %d%% of budget", data.Threshold),
+		Text: fmt.Sprintf("Cost for %s so far is %.2f %s, over %d%% of the monthly budget of %.2f %s. Projected cost for the whole month is %.2f %s.",
+			escapeHTML(data.Month), data.MonthToDate, currency, data.Threshold, data.Budget, currency, data.Projected, currency),
+		Color: color,
+	}
+}
@@ -112,6 +112,14 @@ func (r *Render) Data(ev *types.Event, eventURL, eventURLText, settingsURL strin
 			Text:  commitAutoDeployText(data),
 		}
 
+	case types.BillingBudgetType:
+		var data types.BudgetData
+		if err := json.Unmarshal(ev.Data, &data); err != nil {
+			return errors.Wrap(err, "unmarshaling billing budget data error")
+		}
+
+		pd = parseBudgetData(data)
+
 	default:
 		return errors.New("Unsupported event type")
 	}
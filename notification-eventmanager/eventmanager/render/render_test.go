@@ -47,3 +47,24 @@ func TestRender_Data_escapesHTML(t *testing.T) {
 	assert.NotContains(t, string(ev.Messages[types.EmailReceiver]), xss)
 	assert.NotContains(t, string(ev.Messages[types.StackdriverReceiver]), xss)
 }
+
+func TestRender_Data_billingBudget(t *testing.T) {
+	r := render.NewRender(templates.MustNewEngine("../../templates"))
+	dataraw, err := json.Marshal(types.BudgetData{
+		Month:       "2018-06",
+		Threshold:   80,
+		Budget:      100,
+		Currency:    "USD",
+		MonthToDate: 81.5,
+		Projected:   120.25,
+	})
+	assert.NoError(t, err)
+	ev := types.Event{Type: types.BillingBudgetType, Data: dataraw}
+
+	err = r.Data(&ev, "https://cloud.weave.works/foo/org/billing", "Weave Cloud billing", "")
+	assert.NoError(t, err)
+	expected := "Cost for 2018-06 so far is 81.50 USD, over 80% of the monthly budget of 100.00 USD. Projected cost for the whole month is 120.25 USD."
+	assert.JSONEq(t, `{"type": "billing_budget", "text": "`+expected+`", "attachments":[{"text":"[Weave Cloud billing](https://cloud.weave.works/foo/org/billing)"}], "timestamp":"0001-01-01T00:00:00Z"}`,
+		string(ev.Messages[types.BrowserReceiver]))
+	assert.Contains(t, string(ev.Messages[types.SlackReceiver]), expected)
+}
@@ -39,6 +39,8 @@ const (
 	OnboardingStartedType = "onboarding_started"
 	// OnboardingFailedType event type
 	OnboardingFailedType = "onboarding_failed"
+	// BillingBudgetType event type
+	BillingBudgetType = "billing_budget"
 )
 
 // SyncData is data for sync event, contains metadata and services
@@ -52,6 +54,19 @@ func (sd SyncData) Type() string {
 	return SyncType
 }
 
+// BudgetData is data for billing budget event, sent when the cost of an
+// instance so far this month crosses a threshold of its monthly budget
+type BudgetData struct {
+	// Month is the month the cost is for, formatted as 2006-01.
+	Month string `json:"month"`
+	// Threshold is the percentage of the budget crossed.
+	Threshold   int     `json:"threshold"`
+	Budget      float64 `json:"budget"`
+	Currency    string  `json:"currency"`
+	MonthToDate float64 `json:"monthToDate"`
+	Projected   float64 `json:"projected"`
+}
+
 // WebhookAlert is alertmanager JSON payload with alerts
 type WebhookAlert struct {
 	Version           string            `json:"version,omitempty"`
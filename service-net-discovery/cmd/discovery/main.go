@@ -5,32 +5,67 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/weaveworks/common/server"
+	"github.com/weaveworks/service/common/dbconfig"
+	discovery "github.com/weaveworks/service/service-net-discovery"
+	"github.com/weaveworks/service/service-net-discovery/db"
 	"github.com/weaveworks/service/service-net-discovery/peers"
 )
 
 func main() {
 	var (
 		serverConfig = server.Config{
-			MetricsNamespace: peers.MetricsNamespace,
+			MetricsNamespace: discovery.MetricsNamespace,
 		}
-		peersConfig peers.PeerDiscoveryConfig
+		dbConfig    dbconfig.Config
+		peersConfig peers.Config
+		dynamodbURL string
 	)
 	serverConfig.RegisterFlags(flag.CommandLine)
-	flag.StringVar(&peersConfig.DynamodbURL, "app.dynamodb.url", "", "dynamodb url")
+	peersConfig.RegisterFlags(flag.CommandLine)
+	dbConfig.RegisterFlags(flag.CommandLine,
+		"",
+		"URI where peers are stored (required): dynamodb://<region>/<table>, postgres://... or memory:// for development",
+		"",
+		"Path where the database migration files can be found")
+	flag.StringVar(&dynamodbURL, "app.dynamodb.url", "", "Deprecated; use --database.uri")
 	flag.Parse()
 
+	if serverConfig.HTTPServerWriteTimeout > 0 && peersConfig.WatchTimeout >= serverConfig.HTTPServerWriteTimeout {
+		log.Fatalf("-peers.watch-timeout (%v) must be shorter than -server.http-write-timeout (%v)", peersConfig.WatchTimeout, serverConfig.HTTPServerWriteTimeout)
+	}
+
+	if dynamodbURL != "" {
+		dbConfig = dbconfig.New(dynamodbURL, "", "")
+	}
+
 	s, err := server.New(serverConfig)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	discovery, err := peers.New(peersConfig)
+	scheme, _, _, err := dbConfig.Parameters()
 	if err != nil {
 		log.Fatal(err)
 	}
-	s.HTTP.Methods("GET").Path("/api/net/peer").HandlerFunc(discovery.ListPeers)
-	s.HTTP.Methods("POST").Path("/api/net/peer").HandlerFunc(discovery.UpdatePeer)
-	s.HTTP.Methods("DELETE").Path("/api/net/peer").HandlerFunc(discovery.DeletePeer)
+	switch scheme {
+	case "":
+		log.Fatal("-database.uri is required")
+	case "memory":
+		log.Warn("Storing peers in memory: they are lost on restart, and not shared between replicas. Only use this for development.")
+	}
+	store, err := db.New(dbConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	peerDiscovery := peers.New(store, peersConfig)
+	defer peerDiscovery.Stop()
+	s.HTTP.Methods("GET").Path("/api/net/peer").HandlerFunc(peerDiscovery.ListPeers)
+	s.HTTP.Methods("POST").Path("/api/net/peer").HandlerFunc(peerDiscovery.UpdatePeer)
+	s.HTTP.Methods("DELETE").Path("/api/net/peer").HandlerFunc(peerDiscovery.DeletePeer)
+	s.HTTP.Methods("GET").Path("/api/net/peer/watch").HandlerFunc(peerDiscovery.WatchPeers)
+	s.HTTP.Methods("DELETE").Path("/api/net/peer/{peername}").HandlerFunc(peerDiscovery.DeletePeerByName)
 
 	defer log.Info("app exiting")
 
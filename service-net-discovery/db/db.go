@@ -0,0 +1,53 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/weaveworks/service/common/dbconfig"
+	discovery "github.com/weaveworks/service/service-net-discovery"
+	"github.com/weaveworks/service/service-net-discovery/db/dynamo"
+	"github.com/weaveworks/service/service-net-discovery/db/memory"
+	"github.com/weaveworks/service/service-net-discovery/db/postgres"
+)
+
+// PeerStore is the interface for storing instances' peers.
+type PeerStore interface {
+	// UpdatePeer creates, or replaces, a peer of an instance.
+	UpdatePeer(ctx context.Context, instanceID string, peer discovery.PeerInfo) error
+	// ListPeers returns all the peers of an instance.
+	ListPeers(ctx context.Context, instanceID string) ([]discovery.PeerInfo, error)
+	// DeletePeer deletes a peer of an instance, if it exists.
+	DeletePeer(ctx context.Context, instanceID, peerName string) error
+	// DeletePeersNotSeenSince deletes the peers, of all instances, last seen
+	// before the cutoff, returning how many were deleted for each instance.
+	DeletePeersNotSeenSince(ctx context.Context, cutoff time.Time) (map[string]int, error)
+
+	Close() error
+}
+
+// New creates a new peer store, of the type given by the URI's scheme.
+func New(cfg dbconfig.Config) (PeerStore, error) {
+	scheme, dataSourceName, migrationsDir, err := cfg.Parameters()
+	if err != nil {
+		return nil, err
+	}
+	var d PeerStore
+	switch scheme {
+	case "memory":
+		d, err = memory.New(dataSourceName, migrationsDir)
+	case "postgres":
+		d, err = postgres.New(dataSourceName, migrationsDir)
+	// DynamoDB URLs name a region, or the endpoint of an emulator
+	case "dynamodb", "http", "https":
+		d, err = dynamo.New(dataSourceName)
+	default:
+		return nil, fmt.Errorf("Unknown database type: %s", scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
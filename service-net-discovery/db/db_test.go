@@ -0,0 +1,108 @@
+package db_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/weaveworks/service/common/dbconfig"
+	discovery "github.com/weaveworks/service/service-net-discovery"
+	"github.com/weaveworks/service/service-net-discovery/db"
+	"github.com/weaveworks/service/service-net-discovery/db/dbtest"
+)
+
+func TestNew_UnknownScheme(t *testing.T) {
+	_, err := db.New(dbconfig.New("mysql://localhost/peers", "", ""))
+	assert.EqualError(t, err, "Unknown database type: mysql")
+}
+
+func TestPeerStore(t *testing.T) {
+	store := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, store)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	a := discovery.PeerInfo{PeerName: "a", NickName: "host-a", Addresses: []net.IP{net.ParseIP("10.0.0.1")}, LastSeen: now}
+	b := discovery.PeerInfo{PeerName: "b", Addresses: []net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.3")}, LastSeen: now}
+	require.NoError(t, store.UpdatePeer(ctx, "instance1", a))
+	require.NoError(t, store.UpdatePeer(ctx, "instance1", b))
+	require.NoError(t, store.UpdatePeer(ctx, "instance2", a))
+
+	peers, err := store.ListPeers(ctx, "instance1")
+	require.NoError(t, err)
+	assertPeers(t, []discovery.PeerInfo{a, b}, peers)
+
+	// Updates replace peers
+	a.Addresses = []net.IP{net.ParseIP("10.0.0.4")}
+	a.LastSeen = now.Add(time.Minute)
+	require.NoError(t, store.UpdatePeer(ctx, "instance1", a))
+	peers, err = store.ListPeers(ctx, "instance1")
+	require.NoError(t, err)
+	assertPeers(t, []discovery.PeerInfo{a, b}, peers)
+
+	require.NoError(t, store.DeletePeer(ctx, "instance1", "b"))
+	require.NoError(t, store.DeletePeer(ctx, "instance1", "missing"))
+	peers, err = store.ListPeers(ctx, "instance1")
+	require.NoError(t, err)
+	assertPeers(t, []discovery.PeerInfo{a}, peers)
+
+	// Other instances' peers are left alone
+	peers, err = store.ListPeers(ctx, "instance2")
+	require.NoError(t, err)
+	assert.Len(t, peers, 1)
+	peers, err = store.ListPeers(ctx, "instance3")
+	require.NoError(t, err)
+	assert.Empty(t, peers)
+}
+
+// assertPeers compares peers' addresses as strings, as IPs may be stored in
+// either of their forms.
+func assertPeers(t *testing.T, expected, actual []discovery.PeerInfo) {
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		assert.Equal(t, expected[i].PeerName, actual[i].PeerName)
+		assert.Equal(t, expected[i].NickName, actual[i].NickName)
+		assert.True(t, expected[i].LastSeen.Equal(actual[i].LastSeen))
+		assert.Equal(t, addresses(expected[i]), addresses(actual[i]))
+	}
+}
+
+func addresses(peer discovery.PeerInfo) []string {
+	var addrs []string
+	for _, addr := range peer.Addresses {
+		addrs = append(addrs, addr.String())
+	}
+	return addrs
+}
+
+func TestPeerStore_DeletePeersNotSeenSince(t *testing.T) {
+	store := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, store)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	require.NoError(t, store.UpdatePeer(ctx, "instance1", discovery.PeerInfo{PeerName: "old", LastSeen: now.Add(-time.Hour)}))
+	require.NoError(t, store.UpdatePeer(ctx, "instance1", discovery.PeerInfo{PeerName: "new", LastSeen: now}))
+	require.NoError(t, store.UpdatePeer(ctx, "instance2", discovery.PeerInfo{PeerName: "old", LastSeen: now.Add(-2 * time.Hour)}))
+	require.NoError(t, store.UpdatePeer(ctx, "instance2", discovery.PeerInfo{PeerName: "older", LastSeen: now.Add(-3 * time.Hour)}))
+
+	deleted, err := store.DeletePeersNotSeenSince(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"instance1": 1, "instance2": 2}, deleted)
+
+	peers, err := store.ListPeers(ctx, "instance1")
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, "new", peers[0].PeerName)
+	peers, err = store.ListPeers(ctx, "instance2")
+	require.NoError(t, err)
+	assert.Empty(t, peers)
+
+	deleted, err = store.DeletePeersNotSeenSince(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+}
@@ -0,0 +1,292 @@
+package dynamo
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"github.com/weaveworks/common/instrument"
+	"golang.org/x/net/context"
+
+	discovery "github.com/weaveworks/service/service-net-discovery"
+)
+
+const (
+	instanceField = "i"
+	peerNameField = "p"
+	peerDataField = "d"
+)
+
+var (
+	dynamoRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: discovery.MetricsNamespace,
+		Name:      "dynamo_request_duration_seconds",
+		Help:      "Time in seconds spent doing DynamoDB requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "status_code"})
+	dynamoConsumedCapacity = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: discovery.MetricsNamespace,
+		Name:      "dynamo_consumed_capacity_total",
+		Help:      "The capacity units consumed by operation.",
+	}, []string{"operation"})
+)
+
+func init() {
+	prometheus.MustRegister(dynamoRequestDuration)
+	prometheus.MustRegister(dynamoConsumedCapacity)
+}
+
+// DB stores peers in a DynamoDB table, as gob-encoded blobs keyed by
+// instance and peer name.
+type DB struct {
+	tableName string
+	db        *dynamodb.DynamoDB
+}
+
+// New creates a new DynamoDB peer store, creating its table if needed.
+func New(url string) (*DB, error) {
+	dynamoDBConfig, tableName, err := awsConfigFromURLString(url)
+	if err != nil {
+		return nil, err
+	}
+	d := &DB{
+		tableName: tableName,
+		db:        dynamodb.New(session.New(dynamoDBConfig)),
+	}
+	err = d.CreateTables()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// CreateTables will create the dynamoDB tables for peers if they do not already exist
+func (d *DB) CreateTables() error {
+	// see if tableName exists
+	tableFound := false
+	lpi := dynamodb.ListTablesInput{Limit: aws.Int64(50)}
+	err := d.db.ListTablesPages(&lpi, func(resp *dynamodb.ListTablesOutput, lastPage bool) bool {
+		for _, s := range resp.TableNames {
+			if *s == d.tableName {
+				tableFound = true
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if tableFound {
+		return nil
+	}
+
+	params := &dynamodb.CreateTableInput{
+		TableName: aws.String(d.tableName),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(instanceField),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String(peerNameField),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(instanceField),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String(peerNameField),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(1), // Table should be pre-created in dev and prod,
+			WriteCapacityUnits: aws.Int64(1), // so these values are never used in anger
+		},
+	}
+	log.Infof("Creating table %s", d.tableName)
+	_, err = d.db.CreateTable(params)
+	return err
+}
+
+// UpdatePeer creates, or replaces, a peer of an instance.
+func (d *DB) UpdatePeer(ctx context.Context, instanceID string, peer discovery.PeerInfo) (err error) {
+	buf := new(bytes.Buffer)
+	enc := gob.NewEncoder(buf)
+	if err := enc.Encode(peer); err != nil {
+		return err
+	}
+
+	var resp *dynamodb.PutItemOutput
+	err = instrument.TimeRequestHistogram(ctx, "DynamoDB.PutItem", dynamoRequestDuration, func(_ context.Context) error {
+		resp, err = d.db.PutItem(&dynamodb.PutItemInput{
+			TableName: aws.String(d.tableName),
+			Item: map[string]*dynamodb.AttributeValue{
+				instanceField: {S: aws.String(instanceID)},
+				peerNameField: {S: aws.String(peer.PeerName)},
+				peerDataField: {B: buf.Bytes()},
+			},
+			ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if resp.ConsumedCapacity != nil && resp.ConsumedCapacity.CapacityUnits != nil {
+		dynamoConsumedCapacity.WithLabelValues("DynamoDB.PutItem").Add(*resp.ConsumedCapacity.CapacityUnits)
+	}
+	return nil
+}
+
+// ListPeers returns all the peers of an instance.
+func (d *DB) ListPeers(ctx context.Context, instanceID string) (peers []discovery.PeerInfo, err error) {
+	var resp *dynamodb.QueryOutput
+	err = instrument.TimeRequestHistogram(ctx, "DynamoDB.Query", dynamoRequestDuration, func(_ context.Context) error {
+		var err error
+		resp, err = d.db.Query(&dynamodb.QueryInput{
+			TableName:              aws.String(d.tableName),
+			ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+			KeyConditionExpression: aws.String(instanceField + " = :id"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":id": {S: aws.String(instanceID)},
+			}})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.ConsumedCapacity != nil && resp.ConsumedCapacity.CapacityUnits != nil {
+		dynamoConsumedCapacity.WithLabelValues("DynamoDB.Query").Add(*resp.ConsumedCapacity.CapacityUnits)
+	}
+
+	log.Debugf("Query returned: %v", resp)
+
+	for i, item := range resp.Items {
+		peerData, found := item[peerDataField]
+		if !found {
+			log.Errorf("Row %d has no data", i)
+			continue
+		}
+		var peer discovery.PeerInfo
+		reader := bytes.NewReader(peerData.B)
+		decoder := gob.NewDecoder(reader)
+		err := decoder.Decode(&peer)
+		if err != nil {
+			return nil, fmt.Errorf("error while decoding peer data: %s", err)
+		}
+		peers = append(peers, peer)
+	}
+	log.Debugf("peerList [%s] returning %v", instanceID, peers)
+	return
+}
+
+// DeletePeer deletes a peer of an instance, if it exists.
+func (d *DB) DeletePeer(ctx context.Context, instanceID, peerName string) (err error) {
+	var resp *dynamodb.DeleteItemOutput
+	err = instrument.TimeRequestHistogram(ctx, "DynamoDB.DeleteItem", dynamoRequestDuration, func(_ context.Context) error {
+		resp, err = d.db.DeleteItem(&dynamodb.DeleteItemInput{
+			TableName: aws.String(d.tableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				instanceField: {S: aws.String(instanceID)},
+				peerNameField: {S: aws.String(peerName)},
+			},
+			ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if resp.ConsumedCapacity != nil && resp.ConsumedCapacity.CapacityUnits != nil {
+		dynamoConsumedCapacity.WithLabelValues("DynamoDB.DeleteItem").Add(*resp.ConsumedCapacity.CapacityUnits)
+	}
+	return nil
+}
+
+// DeletePeersNotSeenSince deletes the peers last seen before the cutoff. As
+// when peers were last seen is only in their encoded data, the whole table is
+// scanned. Peers are only deleted if they haven't been updated since.
+func (d *DB) DeletePeersNotSeenSince(ctx context.Context, cutoff time.Time) (map[string]int, error) {
+	var expired []map[string]*dynamodb.AttributeValue
+	var decodeErr error
+	err := instrument.TimeRequestHistogram(ctx, "DynamoDB.Scan", dynamoRequestDuration, func(_ context.Context) error {
+		return d.db.ScanPages(&dynamodb.ScanInput{
+			TableName:              aws.String(d.tableName),
+			ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+		}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			if page.ConsumedCapacity != nil && page.ConsumedCapacity.CapacityUnits != nil {
+				dynamoConsumedCapacity.WithLabelValues("DynamoDB.Scan").Add(*page.ConsumedCapacity.CapacityUnits)
+			}
+			for _, item := range page.Items {
+				peerData, found := item[peerDataField]
+				if !found {
+					continue
+				}
+				var peer discovery.PeerInfo
+				if err := gob.NewDecoder(bytes.NewReader(peerData.B)).Decode(&peer); err != nil {
+					decodeErr = fmt.Errorf("error while decoding peer data: %s", err)
+					return false
+				}
+				if peer.LastSeen.Before(cutoff) {
+					expired = append(expired, item)
+				}
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	deleted := map[string]int{}
+	for _, item := range expired {
+		var resp *dynamodb.DeleteItemOutput
+		err := instrument.TimeRequestHistogram(ctx, "DynamoDB.DeleteItem", dynamoRequestDuration, func(_ context.Context) error {
+			var err error
+			resp, err = d.db.DeleteItem(&dynamodb.DeleteItemInput{
+				TableName: aws.String(d.tableName),
+				Key: map[string]*dynamodb.AttributeValue{
+					instanceField: item[instanceField],
+					peerNameField: item[peerNameField],
+				},
+				ConditionExpression: aws.String(peerDataField + " = :data"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":data": item[peerDataField],
+				},
+				ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+			})
+			return err
+		})
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			// Seen again since we looked
+			continue
+		} else if err != nil {
+			return deleted, err
+		}
+		if resp.ConsumedCapacity != nil && resp.ConsumedCapacity.CapacityUnits != nil {
+			dynamoConsumedCapacity.WithLabelValues("DynamoDB.DeleteItem").Add(*resp.ConsumedCapacity.CapacityUnits)
+		}
+		deleted[*item[instanceField].S]++
+	}
+	return deleted, nil
+}
+
+// Close does nothing, as there are no connections to close.
+func (d *DB) Close() error {
+	return nil
+}
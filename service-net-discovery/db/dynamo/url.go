@@ -1,4 +1,4 @@
-package peers
+package dynamo
 
 import (
 	"net/url"
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	discovery "github.com/weaveworks/service/service-net-discovery"
+)
+
+// DB is an in-memory peer store for testing, and local development
+type DB struct {
+	mtx   sync.Mutex
+	peers map[string]map[string]discovery.PeerInfo // by instance, then name
+}
+
+// New creates a new in-memory peer store
+func New(_, _ string) (*DB, error) {
+	return &DB{
+		peers: map[string]map[string]discovery.PeerInfo{},
+	}, nil
+}
+
+// UpdatePeer creates, or replaces, a peer of an instance.
+func (d *DB) UpdatePeer(_ context.Context, instanceID string, peer discovery.PeerInfo) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.peers[instanceID]; !ok {
+		d.peers[instanceID] = map[string]discovery.PeerInfo{}
+	}
+	d.peers[instanceID][peer.PeerName] = peer
+	return nil
+}
+
+// ListPeers returns all the peers of an instance, by name.
+func (d *DB) ListPeers(_ context.Context, instanceID string) ([]discovery.PeerInfo, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	var peers []discovery.PeerInfo
+	for _, peer := range d.peers[instanceID] {
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].PeerName < peers[j].PeerName })
+	return peers, nil
+}
+
+// DeletePeer deletes a peer of an instance, if it exists.
+func (d *DB) DeletePeer(_ context.Context, instanceID, peerName string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	delete(d.peers[instanceID], peerName)
+	return nil
+}
+
+// DeletePeersNotSeenSince deletes the peers last seen before the cutoff.
+func (d *DB) DeletePeersNotSeenSince(_ context.Context, cutoff time.Time) (map[string]int, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	deleted := map[string]int{}
+	for instanceID, peers := range d.peers {
+		for name, peer := range peers {
+			if peer.LastSeen.Before(cutoff) {
+				delete(peers, name)
+				deleted[instanceID]++
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// Close finishes using the db. Noop.
+func (d *DB) Close() error {
+	return nil
+}
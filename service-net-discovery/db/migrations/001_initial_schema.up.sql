@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS peers (
+  instance_id text NOT NULL,
+  peer_name   text NOT NULL,
+  nickname    text NOT NULL DEFAULT '',
+  addresses   text[] NOT NULL DEFAULT '{}',
+  last_seen   timestamp with time zone NOT NULL,
+  PRIMARY KEY (instance_id, peer_name)
+);
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"database/sql"
+	"net"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"github.com/weaveworks/common/instrument"
+	"golang.org/x/net/context"
+	"gopkg.in/mattes/migrate.v1/migrate"
+
+	"github.com/weaveworks/service/common/dbwait"
+	discovery "github.com/weaveworks/service/service-net-discovery"
+
+	_ "gopkg.in/mattes/migrate.v1/driver/postgres" // Import the postgres migrations driver
+)
+
+var postgresRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: discovery.MetricsNamespace,
+	Name:      "postgres_request_duration_seconds",
+	Help:      "Time in seconds spent doing Postgres requests.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"method", "status_code"})
+
+func init() {
+	prometheus.MustRegister(postgresRequestDuration)
+}
+
+// DB is a postgres peer store, for dev and production
+type DB struct {
+	dbProxy
+	squirrel.StatementBuilderType
+}
+
+type dbProxy interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// New creates a new postgres peer store
+func New(uri, migrationsDir string) (DB, error) {
+	db, err := sql.Open("postgres", uri)
+	if err != nil {
+		return DB{}, err
+	}
+
+	if err := dbwait.Wait(db); err != nil {
+		return DB{}, errors.Wrap(err, "cannot establish db connection")
+	}
+
+	if migrationsDir != "" {
+		log.Infof("Running Database Migrations...")
+		if errs, ok := migrate.UpSync(uri, migrationsDir); !ok {
+			for _, err := range errs {
+				log.Error(err)
+			}
+			return DB{}, errors.New("Database migrations failed")
+		}
+	}
+
+	return DB{
+		dbProxy:              db,
+		StatementBuilderType: statementBuilder(db),
+	}, err
+}
+
+var statementBuilder = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).RunWith
+
+// UpdatePeer creates, or replaces, a peer of an instance.
+func (d DB) UpdatePeer(ctx context.Context, instanceID string, peer discovery.PeerInfo) error {
+	addresses := []string{}
+	for _, addr := range peer.Addresses {
+		addresses = append(addresses, addr.String())
+	}
+	return instrument.TimeRequestHistogram(ctx, "Postgres.UpdatePeer", postgresRequestDuration, func(_ context.Context) error {
+		_, err := d.Insert("peers").
+			Columns("instance_id", "peer_name", "nickname", "addresses", "last_seen").
+			Values(instanceID, peer.PeerName, peer.NickName, pq.Array(addresses), peer.LastSeen).
+			Suffix("ON CONFLICT (instance_id, peer_name) DO UPDATE SET nickname = excluded.nickname, addresses = excluded.addresses, last_seen = excluded.last_seen").
+			Exec()
+		return err
+	})
+}
+
+// ListPeers returns all the peers of an instance, by name.
+func (d DB) ListPeers(ctx context.Context, instanceID string) ([]discovery.PeerInfo, error) {
+	var peers []discovery.PeerInfo
+	err := instrument.TimeRequestHistogram(ctx, "Postgres.ListPeers", postgresRequestDuration, func(_ context.Context) error {
+		rows, err := d.Select("peer_name", "nickname", "addresses", "last_seen").
+			From("peers").
+			Where(squirrel.Eq{"instance_id": instanceID}).
+			OrderBy("peer_name").
+			Query()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var peer discovery.PeerInfo
+			var addresses []string
+			if err := rows.Scan(&peer.PeerName, &peer.NickName, pq.Array(&addresses), &peer.LastSeen); err != nil {
+				return err
+			}
+			for _, addr := range addresses {
+				peer.Addresses = append(peer.Addresses, net.ParseIP(addr))
+			}
+			peer.LastSeen = peer.LastSeen.UTC()
+			peers = append(peers, peer)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+// DeletePeer deletes a peer of an instance, if it exists.
+func (d DB) DeletePeer(ctx context.Context, instanceID, peerName string) error {
+	return instrument.TimeRequestHistogram(ctx, "Postgres.DeletePeer", postgresRequestDuration, func(_ context.Context) error {
+		_, err := d.Delete("peers").
+			Where(squirrel.Eq{"instance_id": instanceID, "peer_name": peerName}).
+			Exec()
+		return err
+	})
+}
+
+// DeletePeersNotSeenSince deletes the peers last seen before the cutoff.
+func (d DB) DeletePeersNotSeenSince(ctx context.Context, cutoff time.Time) (map[string]int, error) {
+	deleted := map[string]int{}
+	err := instrument.TimeRequestHistogram(ctx, "Postgres.DeletePeersNotSeenSince", postgresRequestDuration, func(_ context.Context) error {
+		query, args, err := d.Delete("peers").
+			Where(squirrel.Lt{"last_seen": cutoff}).
+			Suffix("RETURNING instance_id").
+			ToSql()
+		if err != nil {
+			return err
+		}
+		rows, err := d.Query(query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var instanceID string
+			if err := rows.Scan(&instanceID); err != nil {
+				return err
+			}
+			deleted[instanceID]++
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// Transaction runs the given function in a postgres transaction. If fn returns
+// an error the txn will be rolled back.
+func (d DB) Transaction(f func(DB) error) error {
+	if _, ok := d.dbProxy.(*sql.Tx); ok {
+		// Already in a nested transaction
+		return f(d)
+	}
+
+	tx, err := d.dbProxy.(*sql.DB).Begin()
+	if err != nil {
+		return err
+	}
+	err = f(DB{
+		dbProxy:              tx,
+		StatementBuilderType: statementBuilder(tx),
+	})
+	if err != nil {
+		// Rollback error is ignored as we already have one in progress
+		if err2 := tx.Rollback(); err2 != nil {
+			log.Warnf("transaction rollback: %v (ignored)", err2)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// Close the database
+func (d DB) Close() error {
+	if db, ok := d.dbProxy.(interface {
+		Close() error
+	}); ok {
+		return db.Close()
+	}
+	return nil
+}
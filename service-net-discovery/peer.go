@@ -0,0 +1,17 @@
+package discovery
+
+import (
+	"net"
+	"time"
+)
+
+// PeerInfo represents a peer in a serializable way
+type PeerInfo struct {
+	PeerName  string
+	NickName  string
+	Addresses []net.IP
+	LastSeen  time.Time
+}
+
+// MetricsNamespace for the service
+const MetricsNamespace = "service_net"
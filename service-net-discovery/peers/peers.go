@@ -1,175 +1,172 @@
 package peers
 
 import (
-	"bytes"
-	"encoding/gob"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
 	"fmt"
 	"net"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
-	"github.com/weaveworks/common/instrument"
 	"golang.org/x/net/context"
-)
-
-const (
-	instanceField = "i"
-	peerNameField = "p"
-	peerDataField = "d"
 
-	// MetricsNamespace for the service
-	MetricsNamespace = "service_net"
+	discovery "github.com/weaveworks/service/service-net-discovery"
+	"github.com/weaveworks/service/service-net-discovery/db"
 )
 
-var (
-	dynamoRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: MetricsNamespace,
-		Name:      "dynamo_request_duration_seconds",
-		Help:      "Time in seconds spent doing DynamoDB requests.",
-		Buckets:   prometheus.DefBuckets,
-	}, []string{"method", "status_code"})
-	dynamoConsumedCapacity = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: MetricsNamespace,
-		Name:      "dynamo_consumed_capacity_total",
-		Help:      "The capacity units consumed by operation.",
-	}, []string{"operation"})
-)
+var expiredPeers = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: discovery.MetricsNamespace,
+	Name:      "expired_peers_total",
+	Help:      "Number of peers deleted for not having been seen within their TTL.",
+})
 
 func init() {
-	prometheus.MustRegister(dynamoRequestDuration)
-	prometheus.MustRegister(dynamoConsumedCapacity)
+	prometheus.MustRegister(expiredPeers)
 }
 
-// PeerInfo represents a peer in a serializable way
-type PeerInfo struct {
-	PeerName  string
-	NickName  string
-	Addresses []net.IP
-	LastSeen  time.Time
+// Config configures how long peers are kept, and watched for.
+type Config struct {
+	// PeerTTL is how long peers are kept after they were last seen. Peers
+	// are kept forever if it is zero.
+	PeerTTL    time.Duration
+	GCInterval time.Duration
+	// WatchTimeout must be shorter than the server's write timeout, which
+	// closes the connections of longer watches. authfe doesn't time out the
+	// requests it proxies, so the server's is the only one that applies.
+	WatchTimeout time.Duration
+	// WatchPollInterval is how often watches look for changes made by other
+	// replicas, which they aren't told about.
+	WatchPollInterval time.Duration
 }
 
-// PeerDiscovery refers to the peer info in the DynamoDb instance
+// RegisterFlags registers configuration variables with a flag set
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.DurationVar(&cfg.PeerTTL, "peers.ttl", 15*time.Minute, "How long peers are advertised, and kept, after they were last seen. 0 keeps them forever.")
+	f.DurationVar(&cfg.GCInterval, "peers.gc-interval", time.Minute, "How often to delete peers not seen within their TTL.")
+	f.DurationVar(&cfg.WatchTimeout, "peers.watch-timeout", 25*time.Second, "Longest time watches wait for peers to change. Must be shorter than -server.http-write-timeout.")
+	f.DurationVar(&cfg.WatchPollInterval, "peers.watch-poll-interval", 5*time.Second, "How often watches look for peers changed via other replicas.")
+}
+
+// PeerDiscovery keeps track of the peers of instances' Weave Nets
 type PeerDiscovery struct {
-	tableName string
-	db        *dynamodb.DynamoDB
+	store    db.PeerStore
+	cfg      Config
+	now      func() time.Time
+	watchers *watchers
+	quit     chan struct{}
+	done     chan struct{}
 }
 
-// PeerDiscoveryConfig has everything we need to make a PeerDiscovery
-type PeerDiscoveryConfig struct {
-	DynamodbURL string
+// New will create a new PeerDiscovery, storing peers in the provided store,
+// and start deleting expired peers.
+func New(store db.PeerStore, cfg Config) *PeerDiscovery {
+	d := &PeerDiscovery{
+		store:    store,
+		cfg:      cfg,
+		now:      func() time.Time { return time.Now().UTC() },
+		watchers: newWatchers(),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go d.loop()
+	return d
 }
 
-// New will create a new PeerDiscovery
-func New(config PeerDiscoveryConfig) (*PeerDiscovery, error) {
-	dynamoDBConfig, tableName, err := awsConfigFromURLString(config.DynamodbURL)
-	if err != nil {
-		return nil, err
+// Stop deleting expired peers.
+func (d *PeerDiscovery) Stop() {
+	close(d.quit)
+	<-d.done
+}
+
+func (d *PeerDiscovery) loop() {
+	defer close(d.done)
+	if d.cfg.PeerTTL <= 0 || d.cfg.GCInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(d.cfg.GCInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.quit:
+			return
+		case <-ticker.C:
+			if err := d.expirePeers(context.Background()); err != nil {
+				log.Errorf("Error deleting expired peers: %v", err)
+			}
+		}
 	}
-	d := &PeerDiscovery{
-		tableName: tableName,
-		db:        dynamodb.New(session.New(dynamoDBConfig)),
+}
+
+// expirePeers deletes the peers not seen within their TTL, telling watchers
+// of the instances they were deleted from.
+func (d *PeerDiscovery) expirePeers(ctx context.Context) error {
+	deleted, err := d.store.DeletePeersNotSeenSince(ctx, d.now().Add(-d.cfg.PeerTTL))
+	for instanceID, count := range deleted {
+		log.Infof("expired %d peer(s) of %s", count, instanceID)
+		expiredPeers.Add(float64(count))
+		d.watchers.notify(instanceID)
 	}
-	err = d.CreateTables()
+	return err
+}
+
+// livePeers returns the peers of an instance seen within their TTL, by name.
+// Expired peers may not have been deleted yet.
+func (d *PeerDiscovery) livePeers(ctx context.Context, instanceID string) ([]discovery.PeerInfo, error) {
+	peers, err := d.store.ListPeers(ctx, instanceID)
 	if err != nil {
 		return nil, err
 	}
-	return d, nil
+	live := []discovery.PeerInfo{}
+	cutoff := d.now().Add(-d.cfg.PeerTTL)
+	for _, peer := range peers {
+		if d.cfg.PeerTTL <= 0 || !peer.LastSeen.Before(cutoff) {
+			live = append(live, peer)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].PeerName < live[j].PeerName })
+	return live, nil
 }
 
-// CreateTables will create the dynamoDB tables for peers if they do not already exist
-func (d *PeerDiscovery) CreateTables() error {
-	// see if tableName exists
-	tableFound := false
-	lpi := dynamodb.ListTablesInput{Limit: aws.Int64(50)}
-	err := d.db.ListTablesPages(&lpi, func(resp *dynamodb.ListTablesOutput, lastPage bool) bool {
-		for _, s := range resp.TableNames {
-			if *s == d.tableName {
-				tableFound = true
-				return false
-			}
+// version identifies a set of peers, ignoring when they were last seen, so
+// that watchers can tell when it changes.
+func version(peers []discovery.PeerInfo) string {
+	h := sha256.New()
+	for _, peer := range peers {
+		addrs := []string{}
+		for _, addr := range peer.Addresses {
+			addrs = append(addrs, addr.String())
 		}
-		return true
-	})
-	if err != nil {
-		return err
+		sort.Strings(addrs)
+		fmt.Fprintf(h, "%q %q %s\n", peer.PeerName, peer.NickName, strings.Join(addrs, ","))
 	}
-	if tableFound {
-		return nil
-	}
-
-	params := &dynamodb.CreateTableInput{
-		TableName: aws.String(d.tableName),
-		AttributeDefinitions: []*dynamodb.AttributeDefinition{
-			{
-				AttributeName: aws.String(instanceField),
-				AttributeType: aws.String("S"),
-			},
-			{
-				AttributeName: aws.String(peerNameField),
-				AttributeType: aws.String("S"),
-			},
-		},
-		KeySchema: []*dynamodb.KeySchemaElement{
-			{
-				AttributeName: aws.String(instanceField),
-				KeyType:       aws.String("HASH"),
-			},
-			{
-				AttributeName: aws.String(peerNameField),
-				KeyType:       aws.String("RANGE"),
-			},
-		},
-		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
-			ReadCapacityUnits:  aws.Int64(1), // Table should be pre-created in dev and prod,
-			WriteCapacityUnits: aws.Int64(1), // so these values are never used in anger
-		},
-	}
-	log.Infof("Creating table %s", d.tableName)
-	_, err = d.db.CreateTable(params)
-	return err
+	return hex.EncodeToString(h.Sum(nil))[:16]
 }
 
 func (d *PeerDiscovery) updatePeer(ctx context.Context, instanceID, fromPeerName, nickname string, timestamp time.Time, addresses []net.IP) (peerAddresses []string, peerCount int, err error) {
-	fromPeer := PeerInfo{
+	before, err := d.livePeers(ctx, instanceID)
+	if err != nil {
+		return nil, 0, err
+	}
+	fromPeer := discovery.PeerInfo{
 		PeerName:  fromPeerName,
 		NickName:  nickname,
 		Addresses: addresses,
 		LastSeen:  timestamp, // TODO: should we code for the possibility time went backwards?
 	}
-
-	buf := new(bytes.Buffer)
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(fromPeer); err != nil {
+	if err := d.store.UpdatePeer(ctx, instanceID, fromPeer); err != nil {
 		return nil, 0, err
 	}
 
-	var resp *dynamodb.PutItemOutput
-	err = instrument.TimeRequestHistogram(ctx, "DynamoDB.PutItem", dynamoRequestDuration, func(_ context.Context) error {
-		resp, err = d.db.PutItem(&dynamodb.PutItemInput{
-			TableName: aws.String(d.tableName),
-			Item: map[string]*dynamodb.AttributeValue{
-				instanceField: {S: aws.String(instanceID)},
-				peerNameField: {S: aws.String(fromPeerName)},
-				peerDataField: {B: buf.Bytes()},
-			},
-			ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
-		})
-		return err
-	})
+	peers, err := d.livePeers(ctx, instanceID)
 	if err != nil {
 		return nil, 0, err
 	}
-	if resp.ConsumedCapacity != nil && resp.ConsumedCapacity.CapacityUnits != nil {
-		dynamoConsumedCapacity.WithLabelValues("DynamoDB.PutItem").Add(*resp.ConsumedCapacity.CapacityUnits)
-	}
-
-	peers, err := d.peerList(ctx, instanceID)
-	if err != nil {
-		return nil, 0, err
+	if version(peers) != version(before) {
+		d.watchers.notify(instanceID)
 	}
 	peerCount = len(peers)               // note count includes the calling peer
 	addrMap := make(map[string]struct{}) // map to de-dupe
@@ -186,65 +183,39 @@ func (d *PeerDiscovery) updatePeer(ctx context.Context, instanceID, fromPeerName
 	return
 }
 
-func (d *PeerDiscovery) peerList(ctx context.Context, instanceID string) (peers []PeerInfo, err error) {
-	var resp *dynamodb.QueryOutput
-	err = instrument.TimeRequestHistogram(ctx, "DynamoDB.Query", dynamoRequestDuration, func(_ context.Context) error {
-		var err error
-		resp, err = d.db.Query(&dynamodb.QueryInput{
-			TableName:              aws.String(d.tableName),
-			ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
-			KeyConditionExpression: aws.String(instanceField + " = :id"),
-			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-				":id": {S: aws.String(instanceID)},
-			}})
+func (d *PeerDiscovery) deletePeer(ctx context.Context, instanceID, peerName string) error {
+	if err := d.store.DeletePeer(ctx, instanceID, peerName); err != nil {
 		return err
-	})
-	if err != nil {
-		return nil, err
 	}
-	if resp.ConsumedCapacity != nil && resp.ConsumedCapacity.CapacityUnits != nil {
-		dynamoConsumedCapacity.WithLabelValues("DynamoDB.Query").Add(*resp.ConsumedCapacity.CapacityUnits)
-	}
-
-	log.Debugf("Query returned: %v", resp)
+	d.watchers.notify(instanceID)
+	return nil
+}
 
-	for i, item := range resp.Items {
-		peerData, found := item[peerDataField]
-		if !found {
-			log.Errorf("Row %d has no data", i)
-			continue
-		}
-		var peer PeerInfo
-		reader := bytes.NewReader(peerData.B)
-		decoder := gob.NewDecoder(reader)
-		err := decoder.Decode(&peer)
+// watch waits for the peers of an instance to differ from the version given,
+// for up to timeout, returning them and their version.
+func (d *PeerDiscovery) watch(ctx context.Context, instanceID, since string, timeout time.Duration) ([]discovery.PeerInfo, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	changed, stop := d.watchers.watch(instanceID)
+	defer func() { stop() }()
+	poll := time.NewTicker(d.cfg.WatchPollInterval)
+	defer poll.Stop()
+	for {
+		peers, err := d.livePeers(ctx, instanceID)
 		if err != nil {
-			return nil, fmt.Errorf("error while decoding peer data: %s", err)
+			return nil, "", err
+		}
+		if v := version(peers); v != since {
+			return peers, v, nil
+		}
+		select {
+		case <-changed:
+			// Watch for the next change before looking at this one
+			stop()
+			changed, stop = d.watchers.watch(instanceID)
+		case <-poll.C:
+		case <-ctx.Done():
+			return peers, since, nil
 		}
-		peers = append(peers, peer)
-	}
-	log.Debugf("peerList [%s] returning %v", instanceID, peers)
-	return
-}
-
-func (d *PeerDiscovery) deletePeer(ctx context.Context, instanceID, peerName string) (err error) {
-	var resp *dynamodb.DeleteItemOutput
-	err = instrument.TimeRequestHistogram(ctx, "DynamoDB.DeleteItem", dynamoRequestDuration, func(_ context.Context) error {
-		resp, err = d.db.DeleteItem(&dynamodb.DeleteItemInput{
-			TableName: aws.String(d.tableName),
-			Key: map[string]*dynamodb.AttributeValue{
-				instanceField: {S: aws.String(instanceID)},
-				peerNameField: {S: aws.String(peerName)},
-			},
-			ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
-		})
-		return err
-	})
-	if err != nil {
-		return err
-	}
-	if resp.ConsumedCapacity != nil && resp.ConsumedCapacity.CapacityUnits != nil {
-		dynamoConsumedCapacity.WithLabelValues("DynamoDB.DeleteItem").Add(*resp.ConsumedCapacity.CapacityUnits)
 	}
-	return nil
 }
@@ -0,0 +1,179 @@
+package peers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"golang.org/x/net/context"
+
+	discovery "github.com/weaveworks/service/service-net-discovery"
+	"github.com/weaveworks/service/service-net-discovery/db/memory"
+)
+
+const instanceID = "instance1"
+
+type fixture struct {
+	discovery *PeerDiscovery
+	router    *mux.Router
+	now       time.Time
+}
+
+func setup(t *testing.T) *fixture {
+	store, err := memory.New("", "")
+	require.NoError(t, err)
+	f := &fixture{now: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}
+	// GC runs explicitly in tests, via expirePeers
+	f.discovery = New(store, Config{PeerTTL: 15 * time.Minute, WatchTimeout: time.Minute, WatchPollInterval: time.Minute})
+	f.discovery.now = func() time.Time { return f.now }
+	f.router = mux.NewRouter()
+	f.router.Methods("GET").Path("/api/net/peer").HandlerFunc(f.discovery.ListPeers)
+	f.router.Methods("POST").Path("/api/net/peer").HandlerFunc(f.discovery.UpdatePeer)
+	f.router.Methods("GET").Path("/api/net/peer/watch").HandlerFunc(f.discovery.WatchPeers)
+	f.router.Methods("DELETE").Path("/api/net/peer/{peername}").HandlerFunc(f.discovery.DeletePeerByName)
+	return f
+}
+
+func (f *fixture) request(t *testing.T, method, url, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, user.InjectOrgIDIntoHTTPRequest(user.InjectOrgID(context.Background(), instanceID), r))
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, r)
+	return w
+}
+
+func (f *fixture) update(t *testing.T, name, address string) PeerUpdateResponse {
+	w := f.request(t, "POST", "/api/net/peer", fmt.Sprintf(`{"peername": %q, "addresses": [%q]}`, name, address))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp PeerUpdateResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	return resp
+}
+
+func (f *fixture) list(t *testing.T) []string {
+	w := f.request(t, "GET", "/api/net/peer", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var peers []PeerInfoResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&peers))
+	names := []string{}
+	for _, peer := range peers {
+		names = append(names, peer.Name)
+	}
+	return names
+}
+
+func (f *fixture) watch(t *testing.T, version, timeout string) *httptest.ResponseRecorder {
+	return f.request(t, "GET", fmt.Sprintf("/api/net/peer/watch?version=%s&timeout=%s", version, timeout), "")
+}
+
+func TestExpiredPeersAreNotAdvertised(t *testing.T) {
+	f := setup(t)
+	defer f.discovery.Stop()
+
+	f.update(t, "a", "10.0.0.1")
+	f.now = f.now.Add(10 * time.Minute)
+	f.update(t, "b", "10.0.0.2")
+	assert.Equal(t, []string{"a", "b"}, f.list(t))
+
+	// a expires before it is deleted
+	f.now = f.now.Add(10 * time.Minute)
+	assert.Equal(t, []string{"b"}, f.list(t))
+	resp := f.update(t, "c", "10.0.0.3")
+	assert.Equal(t, PeerUpdateResponse{Addresses: []string{"10.0.0.2"}, PeerCount: 2}, resp)
+}
+
+func TestExpirePeers(t *testing.T) {
+	f := setup(t)
+	defer f.discovery.Stop()
+	ctx := context.Background()
+
+	f.update(t, "a", "10.0.0.1")
+	f.now = f.now.Add(10 * time.Minute)
+	f.update(t, "b", "10.0.0.2")
+	changed, stop := f.discovery.watchers.watch(instanceID)
+	defer stop()
+
+	f.now = f.now.Add(10 * time.Minute)
+	require.NoError(t, f.discovery.expirePeers(ctx))
+	peers, err := f.discovery.store.ListPeers(ctx, instanceID)
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, "b", peers[0].PeerName)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("watchers weren't told about expired peers")
+	}
+}
+
+func TestDeletePeerByName(t *testing.T) {
+	f := setup(t)
+	defer f.discovery.Stop()
+
+	f.update(t, "a", "10.0.0.1")
+	f.update(t, "b", "10.0.0.2")
+	w := f.request(t, "DELETE", "/api/net/peer/a", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []string{"b"}, f.list(t))
+}
+
+func TestWatchPeers(t *testing.T) {
+	f := setup(t)
+	defer f.discovery.Stop()
+
+	f.update(t, "a", "10.0.0.1")
+
+	// Without a version, the current peers are returned straight away
+	w := f.watch(t, "", "10s")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var current PeerWatchResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&current))
+	require.Len(t, current.Peers, 1)
+	assert.Equal(t, "a", current.Peers[0].Name)
+
+	// Peers being seen again isn't a change
+	f.now = f.now.Add(time.Minute)
+	f.update(t, "a", "10.0.0.1")
+	w = f.watch(t, current.Version, "10ms")
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// Watches return when peers change
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- f.watch(t, current.Version, "10s")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	f.discovery.updatePeer(context.Background(), instanceID, "b", "", f.now, []net.IP{net.ParseIP("10.0.0.2")})
+	select {
+	case w = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch didn't return when peers changed")
+	}
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var changed PeerWatchResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&changed))
+	assert.NotEqual(t, current.Version, changed.Version)
+	assert.Len(t, changed.Peers, 2)
+
+	w = f.watch(t, current.Version, "forever")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVersionIgnoresLastSeen(t *testing.T) {
+	a := discovery.PeerInfo{PeerName: "a", Addresses: []net.IP{net.ParseIP("10.0.0.1")}, LastSeen: time.Now()}
+	seenAgain := a
+	seenAgain.LastSeen = a.LastSeen.Add(time.Minute)
+	moved := a
+	moved.Addresses = []net.IP{net.ParseIP("10.0.0.2")}
+	assert.Equal(t, version([]discovery.PeerInfo{a}), version([]discovery.PeerInfo{seenAgain}))
+	assert.NotEqual(t, version([]discovery.PeerInfo{a}), version([]discovery.PeerInfo{moved}))
+}
@@ -8,8 +8,11 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
 	"github.com/weaveworks/common/user"
+
+	discovery "github.com/weaveworks/service/service-net-discovery"
 )
 
 const (
@@ -78,11 +81,15 @@ func (d *PeerDiscovery) ListPeers(w http.ResponseWriter, r *http.Request) {
 		badRequest(w, err)
 		return
 	}
-	peers, err := d.peerList(ctx, id)
+	peers, err := d.livePeers(ctx, id)
 	if err != nil {
 		badRequest(w, err)
 		return
 	}
+	json.NewEncoder(w).Encode(peerInfoResponses(peers))
+}
+
+func peerInfoResponses(peers []discovery.PeerInfo) []PeerInfoResponse {
 	response := []PeerInfoResponse{}
 	for _, peer := range peers {
 		peerInfo := PeerInfoResponse{
@@ -95,12 +102,12 @@ func (d *PeerDiscovery) ListPeers(w http.ResponseWriter, r *http.Request) {
 		}
 		response = append(response, peerInfo)
 	}
-	json.NewEncoder(w).Encode(response)
+	return response
 }
 
 // UpdatePeer is the peer update endpoint for service-net
 func (d *PeerDiscovery) UpdatePeer(w http.ResponseWriter, r *http.Request) {
-	timestamp := time.Now().UTC()
+	timestamp := d.now()
 	update, err := getParams(r)
 	if err != nil {
 		badRequest(w, err)
@@ -139,3 +146,62 @@ func (d *PeerDiscovery) DeletePeer(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Infof("peer delete %s/%s", update.instanceID, update.Name)
 }
+
+// DeletePeerByName deletes the peer named in the path (/api/net/peer/{peername})
+func (d *PeerDiscovery) DeletePeerByName(w http.ResponseWriter, r *http.Request) {
+	id, ctx, err := user.ExtractOrgIDFromHTTPRequest(r)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+	name := mux.Vars(r)[httpPeerNameField]
+	if err := d.deletePeer(ctx, id, name); err != nil {
+		badRequest(w, err)
+		return
+	}
+	log.Infof("peer delete %s/%s", id, name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PeerWatchResponse represents the peers of an instance, as returned by a watch
+type PeerWatchResponse struct {
+	Version string             `json:"version"`
+	Peers   []PeerInfoResponse `json:"peers"`
+}
+
+/* Example call, returning once peers differ from those of version 3f1c...:
+   curl -H X-Scope-OrgID:123 '127.0.0.1:8080/api/net/peer/watch?version=3f1c...&timeout=60s'
+*/
+
+// WatchPeers is the long-poll endpoint for peer changes (/api/net/peer/watch).
+// It returns the peers as soon as they differ from the version given, or
+// Not Modified if they don't change within the timeout.
+func (d *PeerDiscovery) WatchPeers(w http.ResponseWriter, r *http.Request) {
+	id, ctx, err := user.ExtractOrgIDFromHTTPRequest(r)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+	timeout := d.cfg.WatchTimeout
+	if t := r.URL.Query().Get("timeout"); t != "" {
+		requested, err := time.ParseDuration(t)
+		if err != nil || requested <= 0 {
+			badRequest(w, fmt.Errorf("Invalid timeout: %s", t))
+			return
+		}
+		if requested < timeout {
+			timeout = requested
+		}
+	}
+	since := r.URL.Query().Get("version")
+	peers, v, err := d.watch(ctx, id, since, timeout)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+	if since != "" && v == since {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	json.NewEncoder(w).Encode(PeerWatchResponse{Version: v, Peers: peerInfoResponses(peers)})
+}
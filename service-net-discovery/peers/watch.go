@@ -0,0 +1,45 @@
+package peers
+
+import "sync"
+
+// watchers are told when the peers of instances change via this replica.
+type watchers struct {
+	sync.Mutex
+	byInstance map[string]map[chan struct{}]struct{}
+}
+
+func newWatchers() *watchers {
+	return &watchers{byInstance: map[string]map[chan struct{}]struct{}{}}
+}
+
+// watch returns a channel which is closed when the instance's peers next
+// change, and a function to stop watching.
+func (w *watchers) watch(instanceID string) (<-chan struct{}, func()) {
+	w.Lock()
+	defer w.Unlock()
+	c := make(chan struct{})
+	if _, ok := w.byInstance[instanceID]; !ok {
+		w.byInstance[instanceID] = map[chan struct{}]struct{}{}
+	}
+	w.byInstance[instanceID][c] = struct{}{}
+	return c, func() {
+		w.Lock()
+		defer w.Unlock()
+		if _, ok := w.byInstance[instanceID][c]; ok {
+			delete(w.byInstance[instanceID], c)
+			if len(w.byInstance[instanceID]) == 0 {
+				delete(w.byInstance, instanceID)
+			}
+		}
+	}
+}
+
+// notify tells the watchers of an instance that its peers changed.
+func (w *watchers) notify(instanceID string) {
+	w.Lock()
+	defer w.Unlock()
+	for c := range w.byInstance[instanceID] {
+		close(c)
+	}
+	delete(w.byInstance, instanceID)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -45,6 +46,8 @@ func (a *API) admin(w http.ResponseWriter, r *http.Request) {
 			<li><a href="/admin/users/users">Users</a></li>
 			<li><a href="/admin/users/organizations">Organizations</a></li>
 			<li><a href="/admin/users/teams">Teams</a></li>
+			<li><a href="/admin/users/audit">Audit Log</a></li>
+			<li><a href="/admin/users/featureflags">Feature Flags</a></li>
 			<li><a href="/admin/users/weeklyreports">Weekly Reports</a></li>
 		</ul>
 	</body>
@@ -620,13 +623,52 @@ func (a *API) adminBecomeUser(w http.ResponseWriter, r *http.Request) {
 	// If we are already impersonating we will get the impersonating id
 	// here which we then keep as impersonator for the new user.
 	userID := session.GetActingUserID()
+	// Admins must step up with their own second factor to impersonate
+	if err := a.verifySecondFactor(r.Context(), userID, r.FormValue("code")); err == users.ErrInvalidAuthenticationData {
+		renderError(w, r, users.ValidationErrorf("Enable two-factor authentication to become other users"))
+		return
+	} else if err != nil {
+		renderError(w, r, err)
+		return
+	}
 	if err := a.sessions.Set(w, r, session.Provider, session.LoginID, u.ID, userID); err != nil {
 		renderError(w, r, users.ErrInvalidAuthenticationData)
 		return
 	}
+	a.recordImpersonation(r.Context(), userID, u.ID)
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
+// recordImpersonation records in the audit log of each of the user's teams
+// that they are being impersonated.
+func (a *API) recordImpersonation(ctx context.Context, impersonatingUserID, userID string) {
+	logger := commonuser.LogWith(ctx, logging.Global())
+	teams, err := a.db.ListTeamsForUserID(ctx, userID)
+	if err != nil {
+		logger.Errorf("Failed to record impersonation of %s in the audit log: %v", userID, err)
+		return
+	}
+	teamIDs := []string{}
+	for _, t := range teams {
+		teamIDs = append(teamIDs, t.ID)
+	}
+	if len(teamIDs) == 0 {
+		// Still record it, for the admin view
+		teamIDs = append(teamIDs, "")
+	}
+	for _, teamID := range teamIDs {
+		if err := a.db.InsertAuditEntry(ctx, &users.AuditEntry{
+			TeamID:     teamID,
+			ActorID:    impersonatingUserID,
+			Action:     users.AuditUserImpersonate,
+			TargetType: users.AuditTargetUser,
+			TargetID:   userID,
+		}); err != nil {
+			logger.Errorf("Failed to record impersonation of %s in the audit log: %v", userID, err)
+		}
+	}
+}
+
 func (a *API) adminDeleteUser(w http.ResponseWriter, r *http.Request) {
 	userID := mux.Vars(r)["userID"]
 	if userID == "" {
@@ -741,6 +783,43 @@ func getSpecificLogin(login string, logins []*login.Login) (*string, error) {
 	return nil, users.ErrLoginNotFound
 }
 
+// clusterProviderAccounts are the accounts of a cluster provider, e.g. Azure
+// subscriptions, a user granted us access to. gcp-service records them when
+// users grant access, and only finds clusters in them.
+type clusterProviderAccounts struct {
+	Accounts []string `json:"accounts"`
+}
+
+func (a *API) adminListClusterProviderAccounts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	accounts, err := a.db.ListClusterProviderAccounts(r.Context(), vars["userID"], vars["provider"])
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, clusterProviderAccounts{Accounts: accounts})
+}
+
+func (a *API) adminAddClusterProviderAccount(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var body struct {
+		Account string `json:"account"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		renderError(w, r, users.NewMalformedInputError(err))
+		return
+	}
+	if body.Account == "" {
+		renderError(w, r, users.ValidationErrorf("account is required"))
+		return
+	}
+	if err := a.db.AddClusterProviderAccount(r.Context(), vars["userID"], vars["provider"], body.Account); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func redirectWithMessage(w http.ResponseWriter, r *http.Request, msg string) {
 	var u *url.URL
 	query := url.Values{}
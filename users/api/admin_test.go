@@ -158,6 +158,26 @@ func TestAPI_adminGetUserToken_NoToken(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestAPI_adminClusterProviderAccounts(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	usr := getUser(t)
+	path := fmt.Sprintf("/admin/users/users/%s/cluster-accounts/aks", usr.ID)
+	do := func(method, body string, expectedCode int) string {
+		w := httptest.NewRecorder()
+		r := requestAs(t, usr, method, path, strings.NewReader(body))
+		app.ServeHTTP(w, r)
+		assert.Equal(t, expectedCode, w.Code, w.Body.String())
+		return w.Body.String()
+	}
+
+	assert.JSONEq(t, `{"accounts":[]}`, do("GET", "", http.StatusOK))
+	do("POST", `{"account":"tenant/sub1"}`, http.StatusNoContent)
+	do("POST", `{}`, http.StatusBadRequest)
+	assert.JSONEq(t, `{"accounts":["tenant/sub1"]}`, do("GET", "", http.StatusOK))
+}
+
 func TestAPI_adminDeleteUser(t *testing.T) {
 	setup(t)
 	defer cleanup(t)
@@ -12,6 +12,7 @@ import (
 	users_sync "github.com/weaveworks/service/users-sync/api"
 	"github.com/weaveworks/service/users/db"
 	"github.com/weaveworks/service/users/emailer"
+	"github.com/weaveworks/service/users/events"
 	"github.com/weaveworks/service/users/login"
 	"github.com/weaveworks/service/users/marketing"
 	"github.com/weaveworks/service/users/sessions"
@@ -41,6 +42,11 @@ type API struct {
 	billingEnabler           featureflag.Enabler
 	notificationReceiversURL string
 	usersSyncClient          users_sync.UsersSyncClient
+	domain                   string         // Where users reach us, e.g. to return to from teams' identity providers.
+	ssoStateSecret           string         // Protects state passed through teams' identity providers.
+	ssoClient                *http.Client   // Talks to teams' OIDC identity providers.
+	ssoAllowHTTP             bool           // Whether teams' OIDC identity providers may be reached without TLS. Development only!
+	eventSender              *events.Sender // Sends test events to teams' event webhooks.
 	http.Handler
 }
 
@@ -67,6 +73,10 @@ func New(
 	billingEnabler featureflag.Enabler,
 	notificationReceiversURL string,
 	usersSyncClient users_sync.UsersSyncClient,
+	domain string,
+	ssoStateSecret string,
+	ssoAllowPrivateNetworks bool,
+	eventWebhooksAllowPrivateNetworks bool,
 ) *API {
 	a := &API{
 		createAdminUsers:         createAdminUsers,
@@ -90,6 +100,11 @@ func New(
 		billingEnabler:           billingEnabler,
 		notificationReceiversURL: notificationReceiversURL,
 		usersSyncClient:          usersSyncClient,
+		domain:                   domain,
+		ssoStateSecret:           ssoStateSecret,
+		ssoClient:                newSSOClient(ssoAllowPrivateNetworks),
+		ssoAllowHTTP:             ssoAllowPrivateNetworks,
+		eventSender:              events.NewSender(db, eventWebhookTestTimeout, eventWebhooksAllowPrivateNetworks),
 	}
 
 	r := mux.NewRouter()
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/service/common/permission"
+	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/users"
+)
+
+func (a *API) listAPITokens(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgExternalID := mux.Vars(r)["orgExternalID"]
+	if err := a.userCanAccessOrg(ctx, currentUser, orgExternalID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	if err := RequireOrgMemberPermissionTo(ctx, a.db, currentUser.ID, orgExternalID, permission.ViewToken); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	apiTokens, err := a.db.ListAPITokens(ctx, orgExternalID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if apiTokens == nil {
+		apiTokens = []*users.APIToken{}
+	}
+	render.JSON(w, http.StatusOK, apiTokens)
+}
+
+type createAPITokenPayload struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func (a *API) createAPIToken(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgExternalID := mux.Vars(r)["orgExternalID"]
+	if err := a.userCanAccessOrg(ctx, currentUser, orgExternalID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	if err := RequireOrgMemberPermissionTo(ctx, a.db, currentUser.ID, orgExternalID, permission.CreateToken); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	defer r.Body.Close()
+	var payload createAPITokenPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		renderError(w, r, users.NewMalformedInputError(err))
+		return
+	}
+
+	if payload.Name == "" {
+		renderError(w, r, users.ValidationErrorf("name cannot be blank"))
+		return
+	}
+	if len(payload.Scopes) == 0 {
+		renderError(w, r, users.ValidationErrorf("at least one scope is required"))
+		return
+	}
+	for _, scope := range payload.Scopes {
+		if !users.ValidAPITokenScope(scope) {
+			renderError(w, r, users.NewMalformedInputError(fmt.Errorf("invalid scope %q", scope)))
+			return
+		}
+	}
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+		renderError(w, r, users.ValidationErrorf("expiry must be in the future"))
+		return
+	}
+
+	apiToken, err := a.db.CreateAPIToken(ctx, orgExternalID, currentUser.ID, payload.Name, payload.Scopes, payload.ExpiresAt)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusCreated, apiToken)
+}
+
+// revokeAPIToken revokes an API token. Services which cache token lookups
+// keep accepting it until their cache entry expires; see
+// client.CachingClientConfig.
+func (a *API) revokeAPIToken(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgExternalID := mux.Vars(r)["orgExternalID"]
+	if err := a.userCanAccessOrg(ctx, currentUser, orgExternalID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	if err := RequireOrgMemberPermissionTo(ctx, a.db, currentUser.ID, orgExternalID, permission.RevokeToken); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	tokenID := mux.Vars(r)["tokenID"]
+	if err := a.db.RevokeAPIToken(ctx, orgExternalID, tokenID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,95 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/db/dbtest"
+)
+
+func TestAPI_APITokens(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	user, org, _ := dbtest.GetOrgAndTeam(t, database)
+	tokensURL := "/api/users/org/" + org.ExternalID + "/tokens"
+
+	// Create
+	w := httptest.NewRecorder()
+	r := requestAs(t, user, "POST", tokensURL, jsonBody{
+		"name":   "prometheus",
+		"scopes": []string{users.APITokenScopeProm},
+	}.Reader(t))
+	app.ServeHTTP(w, r)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "prometheus", created["name"])
+	assert.NotEmpty(t, created["token"])
+	assert.NotContains(t, created, "OrganizationID")
+
+	found, err := database.FindAPIToken(r.Context(), created["token"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, []string{users.APITokenScopeProm}, found.Scopes)
+
+	// List, without the token itself
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, requestAs(t, user, "GET", tokensURL, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, created["id"], listed[0]["id"])
+	assert.NotContains(t, listed[0], "token")
+
+	// Revoke
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, requestAs(t, user, "DELETE", tokensURL+"/"+created["id"].(string), nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, requestAs(t, user, "DELETE", tokensURL+"/"+created["id"].(string), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, requestAs(t, user, "GET", tokensURL, nil))
+	assert.Equal(t, "[]\n", w.Body.String())
+}
+
+func TestAPI_createAPIToken_Invalid(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	user, org, _ := dbtest.GetOrgAndTeam(t, database)
+	for _, payload := range []jsonBody{
+		{"scopes": []string{users.APITokenScopeRead}},
+		{"name": "no scopes"},
+		{"name": "bad scope", "scopes": []string{"admin"}},
+		{"name": "expired", "scopes": []string{users.APITokenScopeRead}, "expiresAt": "2000-01-01T00:00:00Z"},
+	} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, requestAs(t, user, "POST", "/api/users/org/"+org.ExternalID+"/tokens", payload.Reader(t)))
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%v", payload)
+	}
+}
+
+func TestAPI_createAPIToken_Forbidden(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	_, org, team := dbtest.GetOrgAndTeam(t, database)
+	viewer := getUser(t)
+	require.NoError(t, database.AddUserToTeam(context.TODO(), viewer.ID, team.ID, users.ViewerRoleID))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, requestAs(t, viewer, "POST", "/api/users/org/"+org.ExternalID+"/tokens", jsonBody{
+		"name":   "prometheus",
+		"scopes": []string{users.APITokenScopeProm},
+	}.Reader(t)))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/weaveworks/common/logging"
+	commonuser "github.com/weaveworks/common/user"
+
+	"github.com/weaveworks/service/common/permission"
+	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/db/filter"
+)
+
+type auditLogView struct {
+	Entries []auditEntryView `json:"entries"`
+	// NextPage is set if there may be more entries
+	NextPage uint64 `json:"nextPage,omitempty"`
+}
+
+type auditEntryView struct {
+	*users.AuditEntry
+	ActorEmail string `json:"actorEmail,omitempty"`
+}
+
+func (a *API) listAuditEntries(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgExternalID := mux.Vars(r)["orgExternalID"]
+	if err := a.userCanAccessOrg(ctx, currentUser, orgExternalID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	if err := RequireOrgMemberPermissionTo(ctx, a.db, currentUser.ID, orgExternalID, permission.ViewAuditLog); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	org, err := a.db.FindOrganizationByID(ctx, orgExternalID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	page := filter.ParsePageValue(r.FormValue("page"))
+	entries, err := a.db.ListAuditEntries(ctx, filter.AuditOrganization{ID: org.ID, TeamID: org.TeamID}, page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	view := auditLogView{Entries: a.auditEntryViews(ctx, entries)}
+	if len(entries) == filter.ResultsPerPage {
+		view.NextPage = page + 1
+	}
+	render.JSON(w, http.StatusOK, view)
+}
+
+func (a *API) auditEntryViews(ctx context.Context, entries []*users.AuditEntry) []auditEntryView {
+	views := []auditEntryView{}
+	emails := map[string]string{}
+	for _, e := range entries {
+		email, ok := emails[e.ActorID]
+		if !ok && e.ActorID != "" {
+			// Users may have been deleted since, so leave out their email.
+			if u, err := a.db.FindUserByID(ctx, e.ActorID); err == nil {
+				email = u.Email
+			}
+			emails[e.ActorID] = email
+		}
+		views = append(views, auditEntryView{AuditEntry: e, ActorEmail: email})
+	}
+	return views
+}
+
+func (a *API) adminListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	page := filter.ParsePageValue(r.FormValue("page"))
+	query := r.FormValue("query")
+
+	entries, err := a.db.ListAuditEntries(ctx, filter.ParseAuditQuery(query), page)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	b, err := a.templates.Bytes("list_audit_entries.html", map[string]interface{}{
+		"Entries":      a.auditEntryViews(ctx, entries),
+		"Query":        query,
+		"Page":         page,
+		"NextPageLink": getNextPageLink(*r.URL),
+	})
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if _, err := w.Write(b); err != nil {
+		commonuser.LogWith(ctx, logging.Global()).Warnf("list audit entries: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/common/constants/webhooks"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/db/dbtest"
+	"github.com/weaveworks/service/users/db/filter"
+)
+
+type auditLog struct {
+	Entries []struct {
+		users.AuditEntry
+		ActorEmail string `json:"actorEmail"`
+	} `json:"entries"`
+	NextPage uint64 `json:"nextPage"`
+}
+
+func getAuditLog(t *testing.T, u *users.User, org *users.Organization) auditLog {
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, requestAs(t, u, "GET", "/api/users/org/"+org.ExternalID+"/audit", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var log auditLog
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &log))
+	return log
+}
+
+func TestAPI_AuditLog(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	user, org, _ := dbtest.GetOrgAndTeam(t, database)
+	assert.Empty(t, getAuditLog(t, user, org).Entries)
+
+	w := httptest.NewRecorder()
+	r := requestAs(t, user, "POST", "/api/users/org/"+org.ExternalID+"/webhooks", jsonBody{
+		"integrationType": webhooks.GithubPushIntegrationType,
+	}.Reader(t))
+	// The first entry is whatever the client sent; authfe appends the last.
+	r.Header.Set("X-Forwarded-For", "10.0.0.1, 1.2.3.4")
+	r.Header.Set("User-Agent", "test")
+	app.ServeHTTP(w, r)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	log := getAuditLog(t, user, org)
+	require.Len(t, log.Entries, 1)
+	entry := log.Entries[0]
+	assert.Equal(t, users.AuditWebhookCreate, entry.Action)
+	assert.Equal(t, user.ID, entry.ActorID)
+	assert.Equal(t, user.Email, entry.ActorEmail)
+	assert.JSONEq(t, `{"integrationType": "github.push"}`, string(entry.After))
+	assert.Equal(t, users.AuditMetadata{
+		IPAddress: "1.2.3.4",
+		UserAgent: "test",
+		Method:    "POST",
+		Path:      "/api/users/org/" + org.ExternalID + "/webhooks",
+	}, entry.Metadata)
+	assert.Zero(t, log.NextPage)
+}
+
+func TestAPI_AuditLog_Impersonation(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin := getUser(t)
+	user, org, _ := dbtest.GetOrgAndTeam(t, database)
+	become := func(code string) int {
+		w := httptest.NewRecorder()
+		r := requestAs(t, admin, "POST", "/admin/users/users/"+user.ID+"/become", strings.NewReader(url.Values{"code": {code}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		app.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Admins must step up with their second factor
+	require.Equal(t, http.StatusBadRequest, become(""))
+	secret, _ := enrollTOTP(t, admin)
+	require.Equal(t, http.StatusBadRequest, become("000000"))
+	require.Equal(t, http.StatusFound, become(totpCode(t, secret, 0)))
+
+	r, err := http.NewRequest("PUT", "/api/users/org/"+org.ExternalID, jsonBody{"name": "renamed"}.Reader(t))
+	require.NoError(t, err)
+	cookie, err := sessionStore.Cookie(r, "mock", admin.ID, user.ID, admin.ID)
+	require.NoError(t, err)
+	r.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	entries, err := database.ListAuditEntries(context.Background(), filter.AuditOrganization{ID: org.ID, TeamID: org.TeamID}, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, users.AuditOrganizationUpdate, entries[0].Action)
+	assert.JSONEq(t, `{"name": "renamed"}`, string(entries[0].After))
+	assert.Equal(t, user.ID, entries[0].ActorID)
+	assert.Equal(t, admin.ID, entries[0].ImpersonatingUserID)
+	assert.Equal(t, users.AuditUserImpersonate, entries[1].Action)
+	assert.Equal(t, admin.ID, entries[1].ActorID)
+	assert.Equal(t, user.ID, entries[1].TargetID)
+}
+
+func TestAPI_AuditLog_Forbidden(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	_, org, team := dbtest.GetOrgAndTeam(t, database)
+	viewer := getUser(t)
+	require.NoError(t, database.AddUserToTeam(context.TODO(), viewer.ID, team.ID, users.ViewerRoleID))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, requestAs(t, viewer, "GET", "/api/users/org/"+org.ExternalID+"/audit", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"net/http"
 
+	httpUtil "github.com/weaveworks/service/common/http"
 	"github.com/weaveworks/service/users"
 	"github.com/weaveworks/service/users/tokens"
 )
@@ -42,6 +43,26 @@ func (a *API) authenticateWebhook(handler http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// withAuditActor records who is making the request, so that changes made
+// while handling it are attributed to them in the audit log.
+func (a *API) withAuditActor(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := users.AuditActor{
+			Metadata: users.AuditMetadata{
+				IPAddress: httpUtil.ClientIP(r),
+				UserAgent: r.UserAgent(),
+				Method:    r.Method,
+				Path:      r.URL.Path,
+			},
+		}
+		if session, err := a.sessions.Get(r); err == nil {
+			actor.UserID = session.UserID
+			actor.ImpersonatingUserID = session.ImpersonatingUserID
+		}
+		handler.ServeHTTP(w, r.WithContext(users.WithAuditActor(r.Context(), actor)))
+	})
+}
+
 // UserAuthenticator can authenticate user requests
 type UserAuthenticator func(w http.ResponseWriter, r *http.Request) (*users.User, error)
 
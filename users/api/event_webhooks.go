@@ -0,0 +1,254 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/service/common/permission"
+	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/db"
+	"github.com/weaveworks/service/users/tokens"
+)
+
+const (
+	// How many of a webhook's deliveries are listed.
+	eventDeliveriesLimit = 100
+	// How long to wait for webhooks to respond to test events.
+	eventWebhookTestTimeout = 10 * time.Second
+)
+
+// EventWebhookView describes a team's event webhook.
+type EventWebhookView struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	// Secret signs the events sent to the webhook. It is only shown when the
+	// webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// EventDeliveryView describes an event sent, or to be sent, to a webhook.
+type EventDeliveryView struct {
+	ID             string          `json:"id"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	State          string          `json:"state"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt,omitempty"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+func newEventWebhookView(webhook *users.EventWebhook) EventWebhookView {
+	return EventWebhookView{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		EventTypes: append([]string{}, webhook.EventTypes...),
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
+
+func newEventDeliveryView(delivery *users.EventDelivery) EventDeliveryView {
+	view := EventDeliveryView{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		State:          delivery.State,
+		Attempts:       delivery.Attempts,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+	if delivery.State == users.EventDeliveryPending {
+		next := delivery.NextAttemptAt
+		view.NextAttemptAt = &next
+	}
+	return view
+}
+
+func (a *API) teamForWebhookManagement(currentUser *users.User, r *http.Request) (*users.Team, error) {
+	ctx := r.Context()
+	teamExternalID := mux.Vars(r)["teamExternalID"]
+	team, err := a.userCanAccessTeam(ctx, currentUser, teamExternalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireTeamMemberPermissionTo(ctx, a.db, currentUser.ID, teamExternalID, permission.ManageTeamWebhooks); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// teamEventWebhook finds one of a team's webhooks. Other teams' webhooks
+// are not found.
+func (a *API) teamEventWebhook(currentUser *users.User, r *http.Request) (*users.Team, *users.EventWebhook, error) {
+	team, err := a.teamForWebhookManagement(currentUser, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	webhook, err := a.db.FindEventWebhook(r.Context(), mux.Vars(r)["webhookID"])
+	if err != nil {
+		return nil, nil, err
+	}
+	if webhook.TeamID != team.ID {
+		return nil, nil, users.ErrNotFound
+	}
+	return team, webhook, nil
+}
+
+func (a *API) listEventWebhooks(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	team, err := a.teamForWebhookManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	webhooks, err := a.db.ListEventWebhooks(r.Context(), team.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	views := []EventWebhookView{}
+	for _, webhook := range webhooks {
+		views = append(views, newEventWebhookView(webhook))
+	}
+	render.JSON(w, http.StatusOK, views)
+}
+
+func validateEventWebhook(view EventWebhookView) error {
+	u, err := url.Parse(view.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return users.ValidationErrorf("Invalid webhook URL: %q", view.URL)
+	}
+	for _, eventType := range view.EventTypes {
+		known := false
+		for _, t := range users.EventTypes {
+			if t == eventType {
+				known = true
+			}
+		}
+		if !known {
+			return users.ValidationErrorf("Invalid event type: %q", eventType)
+		}
+	}
+	return nil
+}
+
+// createEventWebhook subscribes a URL to the team's events, generating the
+// secret they are signed with.
+func (a *API) createEventWebhook(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	team, err := a.teamForWebhookManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	var view EventWebhookView
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		renderError(w, r, users.NewMalformedInputError(err))
+		return
+	}
+	if err := validateEventWebhook(view); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	secret, err := tokens.Generate()
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	webhook := &users.EventWebhook{
+		TeamID:     team.ID,
+		URL:        view.URL,
+		Secret:     secret,
+		EventTypes: view.EventTypes,
+		CreatedBy:  currentUser.ID,
+	}
+	if err := a.db.CreateEventWebhook(r.Context(), webhook); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	created := newEventWebhookView(webhook)
+	created.Secret = webhook.Secret
+	render.JSON(w, http.StatusCreated, created)
+}
+
+func (a *API) deleteEventWebhook(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	team, err := a.teamForWebhookManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.db.DeleteEventWebhook(r.Context(), team.ID, mux.Vars(r)["webhookID"]); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) listEventDeliveries(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	_, webhook, err := a.teamEventWebhook(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	deliveries, err := a.db.ListEventDeliveries(r.Context(), webhook.ID, eventDeliveriesLimit)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	views := []EventDeliveryView{}
+	for _, delivery := range deliveries {
+		views = append(views, newEventDeliveryView(delivery))
+	}
+	render.JSON(w, http.StatusOK, views)
+}
+
+// testEventWebhook sends a ping event to a webhook straight away, returning
+// how that went. If it fails, it is retried like any other event.
+func (a *API) testEventWebhook(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	team, webhook, err := a.teamEventWebhook(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	event, err := db.NewEvent(team.ExternalID, users.EventPing, map[string]string{"webhookId": webhook.ID})
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	delivery := &users.EventDelivery{
+		WebhookID: webhook.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   payload,
+		// Keep the dispatcher from sending it too
+		NextAttemptAt: time.Now().UTC().Add(time.Minute),
+	}
+	if err := a.db.CreateEventDelivery(ctx, delivery); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.eventSender.Deliver(ctx, delivery); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, newEventDeliveryView(delivery))
+}
@@ -0,0 +1,89 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/api"
+	"github.com/weaveworks/service/users/db/dbtest"
+	"github.com/weaveworks/service/users/events"
+)
+
+func TestAPI_EventWebhooks(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	viewer, err := dbtest.GetUserInTeam(t, database, team, users.ViewerRoleID)
+	require.NoError(t, err)
+	path := "/api/users/teams/" + team.ExternalID + "/event_webhooks"
+
+	var secret string
+	received := []users.Event{}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		if _, err := events.Verify(secret, r.Header.Get(events.SignatureHeader), body); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event users.Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		received = append(received, event)
+	}))
+	defer receiver.Close()
+
+	doRequest(t, viewer, "GET", path, nil, http.StatusForbidden)
+	doRequest(t, admin, "POST", path, jsonBody{"url": "ftp://example.com"}.Reader(t), http.StatusBadRequest)
+	doRequest(t, admin, "POST", path, jsonBody{"url": receiver.URL, "eventTypes": []string{"nope"}}.Reader(t), http.StatusBadRequest)
+
+	// The secret is only shown when the webhook is created
+	var webhook api.EventWebhookView
+	data := doRequest(t, admin, "POST", path, jsonBody{"url": receiver.URL}.Reader(t), http.StatusCreated)
+	require.NoError(t, json.Unmarshal(data, &webhook))
+	require.NotEmpty(t, webhook.Secret)
+	secret = webhook.Secret
+	var list []api.EventWebhookView
+	require.NoError(t, json.Unmarshal(doRequest(t, admin, "GET", path, nil, http.StatusOK), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, webhook.ID, list[0].ID)
+	assert.Empty(t, list[0].Secret)
+
+	// Test events are sent straight away
+	var delivery api.EventDeliveryView
+	data = doRequest(t, admin, "POST", path+"/"+webhook.ID+"/test", nil, http.StatusOK)
+	require.NoError(t, json.Unmarshal(data, &delivery))
+	assert.Equal(t, users.EventDeliveryDelivered, delivery.State)
+	assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	require.Len(t, received, 1)
+	assert.Equal(t, users.EventPing, received[0].Type)
+	assert.Equal(t, team.ExternalID, received[0].TeamID)
+
+	// Changes to the team are queued, and logged
+	newcomer := getUser(t)
+	require.NoError(t, database.AddUserToTeam(context.Background(), newcomer.ID, team.ID, users.ViewerRoleID))
+	var deliveries []api.EventDeliveryView
+	require.NoError(t, json.Unmarshal(doRequest(t, admin, "GET", path+"/"+webhook.ID+"/deliveries", nil, http.StatusOK), &deliveries))
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, users.EventTeamMemberJoined, deliveries[0].EventType)
+	assert.Equal(t, users.EventDeliveryPending, deliveries[0].State)
+	assert.NotNil(t, deliveries[0].NextAttemptAt)
+	assert.Equal(t, delivery.ID, deliveries[1].ID)
+
+	// Other teams' webhooks aren't found
+	otherAdmin, _, otherTeam := dbtest.GetOrgAndTeam(t, database)
+	otherPath := "/api/users/teams/" + otherTeam.ExternalID + "/event_webhooks/" + webhook.ID
+	doRequest(t, otherAdmin, "POST", otherPath+"/test", nil, http.StatusNotFound)
+	doRequest(t, otherAdmin, "DELETE", otherPath, nil, http.StatusNotFound)
+
+	doRequest(t, admin, "DELETE", path+"/"+webhook.ID, nil, http.StatusNoContent)
+	doRequest(t, admin, "GET", path+"/"+webhook.ID+"/deliveries", nil, http.StatusNotFound)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/weaveworks/common/logging"
+	commonuser "github.com/weaveworks/common/user"
+
+	"github.com/weaveworks/service/common/featureflag"
+	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/db/filter"
+)
+
+type featureFlagView struct {
+	featureflag.Flag
+	// Definition is the flag as JSON, for editing.
+	Definition    string
+	Organizations []featureFlagOrganizationView
+}
+
+type featureFlagOrganizationView struct {
+	ExternalID string `json:"externalID"`
+	Name       string `json:"name"`
+	Value      string `json:"value,omitempty"`
+	// Explicit is set if the flag is set on the organization itself, rather
+	// than enabled by rules.
+	Explicit bool `json:"explicit,omitempty"`
+}
+
+// featureFlagViews evaluates the rule-based flags for every organization.
+func (a *API) featureFlagViews(ctx context.Context) ([]featureFlagView, error) {
+	flags, err := a.db.ListFeatureFlagDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orgs, err := a.db.ListOrganizations(ctx, filter.And(), 0)
+	if err != nil {
+		return nil, err
+	}
+	views := []featureFlagView{}
+	for _, flag := range flags {
+		definition, err := json.MarshalIndent(flag, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		view := featureFlagView{Flag: flag, Definition: string(definition), Organizations: []featureFlagOrganizationView{}}
+		for _, org := range orgs {
+			value, ok := flag.Evaluate(org.FeatureFlagSubject())
+			if !ok {
+				continue
+			}
+			_, explicit := featureflag.GetFeatureFlagValue(flag.Name, org.FeatureFlags)
+			view.Organizations = append(view.Organizations, featureFlagOrganizationView{
+				ExternalID: org.ExternalID,
+				Name:       org.Name,
+				Value:      value,
+				Explicit:   explicit,
+			})
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+func (a *API) adminListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	flags, err := a.featureFlagViews(ctx)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	b, err := a.templates.Bytes("list_feature_flags.html", map[string]interface{}{
+		"FeatureFlags": flags,
+		"Message":      r.FormValue("msg"),
+	})
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if _, err := w.Write(b); err != nil {
+		commonuser.LogWith(ctx, logging.Global()).Warnf("list feature flags: %v", err)
+	}
+}
+
+// adminListFeatureFlagOrganizations lists the organizations a flag is
+// enabled for, whether by rules or set on them.
+func (a *API) adminListFeatureFlagOrganizations(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	flags, err := a.featureFlagViews(r.Context())
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	for _, flag := range flags {
+		if flag.Name == name {
+			render.JSON(w, http.StatusOK, flag.Organizations)
+			return
+		}
+	}
+	renderError(w, r, users.ErrNotFound)
+}
+
+// adminSetFeatureFlag creates or replaces a flag from its JSON definition.
+func (a *API) adminSetFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	var flag featureflag.Flag
+	decoder := json.NewDecoder(strings.NewReader(r.FormValue("definition")))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&flag); err != nil {
+		renderError(w, r, users.NewMalformedInputError(err))
+		return
+	}
+	if err := flag.Validate(); err != nil {
+		renderError(w, r, users.NewMalformedInputError(err))
+		return
+	}
+	if err := a.db.SetFeatureFlagDefinition(r.Context(), flag); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	redirectWithMessage(w, r, fmt.Sprintf("Saved feature flag %q", flag.Name))
+}
+
+func (a *API) adminDeleteFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if err := a.db.DeleteFeatureFlagDefinition(r.Context(), name); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	redirectWithMessage(w, r, fmt.Sprintf("Deleted feature flag %q", name))
+}
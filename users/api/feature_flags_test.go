@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/users"
+)
+
+func setFeatureFlag(t *testing.T, definition string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("POST", "/admin/users/featureflags", strings.NewReader(url.Values{"definition": {definition}}.Encode()))
+	require.NoError(t, err)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	app.ServeHTTP(w, r)
+	return w
+}
+
+func featureFlagOrganizations(t *testing.T, name string) []string {
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/admin/users/featureflags/"+name+"/organizations", nil)
+	require.NoError(t, err)
+	app.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var orgs []struct {
+		ExternalID string `json:"externalID"`
+		Value      string `json:"value"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &orgs))
+	ids := []string{}
+	for _, org := range orgs {
+		ids = append(ids, org.ExternalID+":"+org.Value)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestAPI_adminFeatureFlags(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	_, kube := getOrg(t)
+	_, docker := getOrg(t)
+	require.NoError(t, database.SetFeatureFlags(ctx, docker.ExternalID, []string{"beta:set"}))
+	platform, environment := "kubernetes", "gke"
+	_, err := database.UpdateOrganization(ctx, kube.ExternalID, users.OrgWriteView{Platform: &platform, Environment: &environment})
+	require.NoError(t, err)
+
+	w := setFeatureFlag(t, `{"name": "beta", "rules": [{"platforms": ["kubernetes"], "enabled": true, "value": "rule"}]}`)
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	expected := []string{kube.ExternalID + ":rule", docker.ExternalID + ":set"}
+	sort.Strings(expected)
+	assert.Equal(t, expected, featureFlagOrganizations(t, "beta"))
+
+	w = httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/admin/users/featureflags", nil)
+	require.NoError(t, err)
+	app.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "beta")
+
+	// Invalid definitions are refused
+	assert.Equal(t, http.StatusBadRequest, setFeatureFlag(t, `{"name": "has space"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, setFeatureFlag(t, `{"name": "beta", "rules": [{"percentage": 101}]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, setFeatureFlag(t, `{"name": "beta", "rulez": []}`).Code)
+
+	w = httptest.NewRecorder()
+	r, err = http.NewRequest("POST", "/admin/users/featureflags/beta/remove", nil)
+	require.NoError(t, err)
+	app.ServeHTTP(w, r)
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	flags, err := database.ListFeatureFlagDefinitions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+}
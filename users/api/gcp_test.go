@@ -208,7 +208,7 @@ func testGCPSubscribe(t *testing.T, database db.DB, a *api.API, client *mock_pro
 }
 
 func createAPI(client procurement.API) *api.API {
-	sessionStore = sessions.MustNewStore("Test-Session-Secret-Which-Is-64-Bytes-Long-aa1a166556cb719f531cd", false, "")
+	sessionStore = sessions.MustNewStore("Test-Session-Secret-Which-Is-64-Bytes-Long-aa1a166556cb719f531cd", false, "", database)
 	return api.New(
 		false,
 		nil,
@@ -231,6 +231,10 @@ func createAPI(client procurement.API) *api.API {
 		nil,
 		"",
 		nil,
+		"",
+		"",
+		false,
+		false,
 	)
 }
 func makeEntitlement(state procurement.EntitlementState) procurement.Entitlement {
@@ -50,7 +50,7 @@ func setupWithMockServices(t *testing.T, fluxAPI, scopeAPI, cortexAPI, netAPI st
 	var directLogin = false
 
 	database = dbtest.Setup(t)
-	sessionStore = sessions.MustNewStore("Test-Session-Secret-Which-Is-64-Bytes-Long-aa1a166556cb719f531cd", false, "")
+	sessionStore = sessions.MustNewStore("Test-Session-Secret-Which-Is-64-Bytes-Long-aa1a166556cb719f531cd", false, "", database)
 	templates := templates.MustNewEngine("../templates", "../../common/templates")
 	logins = MockLoginProvider{Users: make(map[string]login.Claims)}
 
@@ -61,7 +61,7 @@ func setupWithMockServices(t *testing.T, fluxAPI, scopeAPI, cortexAPI, netAPI st
 		Domain:       domain,
 		FromAddress:  "test@test.com",
 	}
-	grpcServer := grpc.New(sessionStore, database, nil, []*marketing.Queue{}, []string{})
+	grpcServer := grpc.New(sessionStore, database, nil, []*marketing.Queue{}, []string{}, featureflag.NewCache(database.ListFeatureFlagDefinitions, 0))
 
 	ctrl = gomock.NewController(t)
 	billingClient = billing_grpc.NewMockBillingClient(ctrl)
@@ -94,6 +94,10 @@ func setupWithMockServices(t *testing.T, fluxAPI, scopeAPI, cortexAPI, netAPI st
 		billingEnabler,
 		"",
 		nil,
+		domain,
+		"Test-SSO-State-Secret",
+		true,
+		true,
 	)
 }
 
@@ -109,13 +113,13 @@ func testEmailSendDirectly(_ context.Context, e *email.Email) error {
 
 // RequestAs makes a request as the given user.
 func requestAs(t *testing.T, u *users.User, method, endpoint string, body io.Reader) *http.Request {
-	impersonatingUserID := "" // this test doesn't involve impersonation
-	cookie, err := sessionStore.Cookie("mock", u.ID, u.ID, impersonatingUserID)
-	assert.NoError(t, err)
-
 	r, err := http.NewRequest(method, endpoint, body)
 	require.NoError(t, err)
 
+	impersonatingUserID := "" // this test doesn't involve impersonation
+	cookie, err := sessionStore.Cookie(r, "mock", u.ID, u.ID, impersonatingUserID)
+	assert.NoError(t, err)
+
 	r.AddCookie(cookie)
 	return r
 }
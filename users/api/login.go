@@ -58,6 +58,9 @@ type attachLoginProviderView struct {
 	Email        string            `json:"email"`
 	MunchkinHash string            `json:"munchkinHash"`
 	QueryParams  map[string]string `json:"queryParams,omitempty"`
+	// TwoFactorRequired is set when the user must give their second factor
+	// to /api/users/login/verify before they are logged in.
+	TwoFactorRequired bool `json:"twoFactorRequired,omitempty"`
 }
 
 // attachLoginProvider is used for oauth login or signup
@@ -140,6 +143,13 @@ func (a *API) attachLoginProvider(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if u != nil {
+		if err := a.requireSSOLogin(ctx, u, providerID); err != nil {
+			renderError(w, r, err)
+			return
+		}
+	}
+
 	if u == nil {
 		// No matching user found, this must be a first-time-login with this
 		// provider, so we'll create an account for them.
@@ -195,8 +205,14 @@ func (a *API) attachLoginProvider(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	impersonatingUserID := "" // Logging in via provider credentials => cannot be impersonating
-	if err := a.sessions.Set(w, r, providerID, claims.ID, u.ID, impersonatingUserID); err != nil {
+	if view.Attach {
+		// Already logged in, so any second factor was already given
+		impersonatingUserID := "" // Logging in via provider credentials => cannot be impersonating
+		err = a.sessions.Set(w, r, providerID, claims.ID, u.ID, impersonatingUserID)
+	} else {
+		view.TwoFactorRequired, err = a.startSession(w, r, providerID, claims.ID, u.ID)
+	}
+	if err != nil {
 		renderError(w, r, users.ErrInvalidAuthenticationData)
 		return
 	}
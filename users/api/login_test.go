@@ -165,6 +165,7 @@ func Test_Verify(t *testing.T) {
 		r, _ := http.NewRequest("GET", testCase.requestURL, nil)
 		if testCase.sessionParameters != nil {
 			s, _ := sessionStore.Cookie(
+				r,
 				testCase.sessionParameters[0],
 				testCase.sessionParameters[1],
 				testCase.sessionParameters[2],
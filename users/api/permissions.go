@@ -4,6 +4,7 @@ import (
 	"context"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/weaveworks/service/common/permission"
 	"github.com/weaveworks/service/users"
 	"github.com/weaveworks/service/users/db"
 )
@@ -25,7 +26,7 @@ func requirePermission(ctx context.Context, d db.DB, userID, teamID, permissionI
 	// Check if the given permission is in the list
 	for _, permission := range permissions {
 		if permission.ID == permissionID {
-			return nil
+			return requireTwoFactorFor(ctx, d, userID, teamID, permissionID)
 		}
 	}
 
@@ -53,3 +54,27 @@ func RequireOrgMemberPermissionTo(ctx context.Context, d db.DB, userID, orgExter
 	}
 	return requirePermission(ctx, d, userID, org.TeamID, permissionID)
 }
+
+// requireTwoFactorFor denies sensitive permissions to members of teams which
+// require two-factor authentication for them, until they have enabled it.
+func requireTwoFactorFor(ctx context.Context, d db.DB, userID, teamID, permissionID string) error {
+	if !permission.RequiresTwoFactor(permissionID) {
+		return nil
+	}
+	required, err := d.TeamRequiresTwoFactor(ctx, teamID)
+	if err != nil || !required {
+		return err
+	}
+	totp, err := d.GetUserTOTP(ctx, userID)
+	if err != nil && err != users.ErrNotFound {
+		return err
+	}
+	if totp.Confirmed() {
+		return nil
+	}
+	team, err := d.FindTeamByInternalID(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	return &users.TwoFactorRequiredError{TeamExternalID: team.ExternalID, TeamName: team.Name, PermissionID: permissionID}
+}
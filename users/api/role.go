@@ -0,0 +1,366 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/service/common/permission"
+	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/users"
+)
+
+// adminPermissions are those a role needs for its members to count as team
+// admins. Every team must keep at least one admin, so that someone can
+// always manage its members and roles.
+var adminPermissions = []string{
+	permission.InviteTeamMember,
+	permission.UpdateTeamMemberRole,
+	permission.RemoveTeamMember,
+	permission.ManageTeamRoles,
+}
+
+// RoleWriteView describes a custom role being created or updated
+type RoleWriteView struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	PermissionIDs []string `json:"permissions"`
+}
+
+func isAdminLevel(permissionIDs []string) bool {
+	has := map[string]bool{}
+	for _, id := range permissionIDs {
+		has[id] = true
+	}
+	for _, id := range adminPermissions {
+		if !has[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func permissionIDs(permissions []*users.Permission) []string {
+	ids := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+// requireTeamKeepsAdmin checks a team will still have an admin-level member
+// after a change. memberRoles maps the IDs of members whose role changes to
+// their new role ID, or to "" if they are being removed. rolePermissions maps
+// the IDs of roles whose permissions change to their new permission IDs.
+func (a *API) requireTeamKeepsAdmin(ctx context.Context, teamID string, memberRoles map[string]string, rolePermissions map[string][]string) error {
+	members, err := a.db.ListTeamUsersWithRoles(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	permissionsOf := map[string][]string{}
+	for roleID, ids := range rolePermissions {
+		permissionsOf[roleID] = ids
+	}
+	for _, m := range members {
+		roleID := m.Role.ID
+		if newRoleID, ok := memberRoles[m.User.ID]; ok {
+			roleID = newRoleID
+		}
+		if roleID == "" {
+			continue
+		}
+		ids, ok := permissionsOf[roleID]
+		if !ok {
+			permissions, err := a.db.ListPermissionsForRoleID(ctx, roleID)
+			if err != nil {
+				return err
+			}
+			ids = permissionIDs(permissions)
+			permissionsOf[roleID] = ids
+		}
+		if isAdminLevel(ids) {
+			return nil
+		}
+	}
+	return users.ErrForbidden
+}
+
+// requireCanGrant checks a member only gives a role permissions they hold
+// themselves, so that managing roles can't be used to escalate their own.
+// kept are the permission IDs the role already has, which it may keep.
+func (a *API) requireCanGrant(ctx context.Context, userID, teamID string, permissionIDs, kept []string) error {
+	role, err := a.db.GetUserRoleInTeam(ctx, userID, teamID)
+	if err == users.ErrNotFound {
+		return users.ErrForbidden
+	} else if err != nil {
+		return err
+	}
+	held, err := a.db.ListPermissionsForRoleID(ctx, role.ID)
+	if err != nil {
+		return err
+	}
+	may := map[string]bool{}
+	for _, p := range held {
+		may[p.ID] = true
+	}
+	for _, id := range kept {
+		may[id] = true
+	}
+	for _, id := range permissionIDs {
+		if !may[id] {
+			return users.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// findTeamRole finds a role which members of the team may be given: either a
+// built-in role, or one of the team's custom roles.
+func (a *API) findTeamRole(ctx context.Context, teamID, roleID string) (*users.Role, error) {
+	role, err := a.db.FindRoleByID(ctx, roleID)
+	if err == users.ErrNotFound || (err == nil && role.TeamID != "" && role.TeamID != teamID) {
+		return nil, users.ValidationErrorf("Invalid role: %q", roleID)
+	} else if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// findCustomRole finds one of a team's custom roles. Built-in roles can't be
+// changed, so aren't found.
+func (a *API) findCustomRole(ctx context.Context, teamID, roleID string) (*users.Role, error) {
+	role, err := a.db.FindRoleByID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role.TeamID == "" || role.TeamID != teamID {
+		return nil, users.ErrNotFound
+	}
+	return role, nil
+}
+
+// validateRole checks the name and permissions of a custom role, returning
+// its deduplicated permission IDs.
+func (a *API) validateRole(ctx context.Context, view *RoleWriteView) ([]string, error) {
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" {
+		return nil, users.ValidationErrorf("Role name cannot be blank")
+	}
+	all, err := a.db.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	for _, p := range all {
+		known[p.ID] = true
+	}
+	seen := map[string]bool{}
+	var ids []string
+	for _, id := range view.PermissionIDs {
+		if !known[id] {
+			return nil, users.ValidationErrorf("Invalid permission: %q", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (a *API) renderRole(ctx context.Context, role *users.Role) (RoleView, error) {
+	permissions, err := a.db.ListPermissionsForRoleID(ctx, role.ID)
+	if err != nil {
+		return RoleView{}, err
+	}
+	return RoleView{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Custom:      role.TeamID != "",
+		Permissions: renderPermissions(permissions),
+	}, nil
+}
+
+func (a *API) listPermissions(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	permissions, err := a.db.ListPermissions(r.Context())
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, PermissionsView{Permissions: renderPermissions(permissions)})
+}
+
+func (a *API) listTeamRoles(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	team, err := a.userCanAccessTeam(ctx, currentUser, mux.Vars(r)["teamExternalID"])
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	roles, err := a.db.ListTeamRoles(ctx, team.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	view := RolesView{Roles: make([]RoleView, 0, len(roles))}
+	for _, role := range roles {
+		roleView, err := a.renderRole(ctx, role)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+		view.Roles = append(view.Roles, roleView)
+	}
+	render.JSON(w, http.StatusOK, view)
+}
+
+func (a *API) createTeamRole(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	teamExternalID := mux.Vars(r)["teamExternalID"]
+	team, err := a.userCanAccessTeam(ctx, currentUser, teamExternalID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := RequireTeamMemberPermissionTo(ctx, a.db, currentUser.ID, teamExternalID, permission.ManageTeamRoles); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	defer r.Body.Close()
+	var view RoleWriteView
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		renderError(w, r, users.NewMalformedInputError(err))
+		return
+	}
+	ids, err := a.validateRole(ctx, &view)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.requireCanGrant(ctx, currentUser.ID, team.ID, ids, nil); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	role, err := a.db.CreateTeamRole(ctx, team.ID, view.Name, view.Description, ids)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	roleView, err := a.renderRole(ctx, role)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusCreated, roleView)
+}
+
+func (a *API) updateTeamRole(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	teamExternalID := mux.Vars(r)["teamExternalID"]
+	roleID := mux.Vars(r)["roleID"]
+	team, err := a.userCanAccessTeam(ctx, currentUser, teamExternalID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := RequireTeamMemberPermissionTo(ctx, a.db, currentUser.ID, teamExternalID, permission.ManageTeamRoles); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if _, err := a.findCustomRole(ctx, team.ID, roleID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	current, err := a.db.ListPermissionsForRoleID(ctx, roleID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	defer r.Body.Close()
+	var view RoleWriteView
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		renderError(w, r, users.NewMalformedInputError(err))
+		return
+	}
+	ids, err := a.validateRole(ctx, &view)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	if err := a.requireCanGrant(ctx, currentUser.ID, team.ID, ids, permissionIDs(current)); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	// Taking permissions away from a role mustn't leave the team without
+	// an admin.
+	if err := a.requireTeamKeepsAdmin(ctx, team.ID, nil, map[string][]string{roleID: ids}); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	role, err := a.db.UpdateTeamRole(ctx, team.ID, roleID, view.Name, view.Description, ids)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	roleView, err := a.renderRole(ctx, role)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, roleView)
+}
+
+func (a *API) deleteTeamRole(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	teamExternalID := mux.Vars(r)["teamExternalID"]
+	roleID := mux.Vars(r)["roleID"]
+	team, err := a.userCanAccessTeam(ctx, currentUser, teamExternalID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := RequireTeamMemberPermissionTo(ctx, a.db, currentUser.ID, teamExternalID, permission.ManageTeamRoles); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if _, err := a.findCustomRole(ctx, team.ID, roleID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	members, err := a.db.ListTeamUsersWithRoles(ctx, team.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	for _, m := range members {
+		if m.Role.ID == roleID {
+			renderError(w, r, users.ValidationErrorf("Role is still assigned to %s", m.User.Email))
+			return
+		}
+	}
+
+	if config, err := a.db.GetTeamSSOConfig(ctx, team.ID); err == nil && config.DefaultRoleID == roleID {
+		renderError(w, r, users.ValidationErrorf("Role is the default role of single sign-on"))
+		return
+	} else if err != nil && err != users.ErrNotFound {
+		renderError(w, r, err)
+		return
+	}
+
+	if err := a.db.DeleteTeamRole(ctx, team.ID, roleID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,158 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/common/permission"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/api"
+	"github.com/weaveworks/service/users/db/dbtest"
+)
+
+func createRole(t *testing.T, u *users.User, team *users.Team, body map[string]interface{}) api.RoleView {
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, requestAs(t, u, "POST", "/api/users/teams/"+team.ExternalID+"/roles", jsonBody(body).Reader(t)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var role api.RoleView
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &role))
+	return role
+}
+
+func TestAPI_TeamRoles(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	viewer, err := dbtest.GetUserInTeam(t, database, team, users.ViewerRoleID)
+	require.NoError(t, err)
+	path := "/api/users/teams/" + team.ExternalID + "/roles"
+
+	deployer := createRole(t, admin, team, map[string]interface{}{
+		"name":        "Deployer",
+		"description": "Deploys images",
+		"permissions": []string{permission.DeployImage, permission.ViewTeamMembers, permission.DeployImage},
+	})
+	assert.Equal(t, "Deployer", deployer.Name)
+	assert.True(t, deployer.Custom)
+	assert.Len(t, deployer.Permissions, 2)
+
+	// Only members holding team.roles.manage can change roles
+	doRequest(t, viewer, "POST", path, jsonBody{"name": "Nope"}.Reader(t), http.StatusForbidden)
+	doRequest(t, viewer, "DELETE", path+"/"+deployer.ID, nil, http.StatusForbidden)
+
+	// Members can't grant permissions they don't hold, even to their own role
+	rolesManager := createRole(t, admin, team, map[string]interface{}{
+		"name":        "Roles manager",
+		"permissions": []string{permission.ManageTeamRoles, permission.ViewTeamMembers},
+	})
+	manager, err := dbtest.GetUserInTeam(t, database, team, rolesManager.ID)
+	require.NoError(t, err)
+	doRequest(t, manager, "POST", path, jsonBody{"name": "Shell", "permissions": []string{permission.OpenHostShell}}.Reader(t), http.StatusForbidden)
+	doRequest(t, manager, "PUT", path+"/"+rolesManager.ID, jsonBody{
+		"name":        "Roles manager",
+		"permissions": []string{permission.ManageTeamRoles, permission.ViewTeamMembers, permission.OpenHostShell},
+	}.Reader(t), http.StatusForbidden)
+	doRequest(t, manager, "PUT", path+"/"+deployer.ID, jsonBody{
+		"name":        "Deployer",
+		"permissions": []string{permission.DeployImage, permission.ViewTeamMembers},
+	}.Reader(t), http.StatusOK)
+	viewers := createRole(t, manager, team, map[string]interface{}{
+		"name":        "Viewers",
+		"permissions": []string{permission.ViewTeamMembers},
+	})
+	doRequest(t, manager, "DELETE", path+"/"+viewers.ID, nil, http.StatusNoContent)
+
+	// Unknown permissions and blank names are rejected
+	doRequest(t, admin, "POST", path, jsonBody{"name": "Bad", "permissions": []string{"nope"}}.Reader(t), http.StatusBadRequest)
+	doRequest(t, admin, "POST", path, jsonBody{"name": " "}.Reader(t), http.StatusBadRequest)
+
+	// Built-in roles can't be changed
+	doRequest(t, admin, "PUT", path+"/"+users.ViewerRoleID, jsonBody{"name": "Viewer"}.Reader(t), http.StatusNotFound)
+
+	body := doRequest(t, viewer, "GET", path, nil, http.StatusOK)
+	var roles api.RolesView
+	require.NoError(t, json.Unmarshal(body, &roles))
+	assert.Len(t, roles.Roles, 5)
+
+	// Custom roles of one team can't be given to members of another
+	otherAdmin, _, other := dbtest.GetOrgAndTeam(t, database)
+	doRequest(t, otherAdmin, "POST", "/api/users/teams/"+other.ExternalID+"/users",
+		jsonBody{"email": "someone@example.com", "roleId": deployer.ID}.Reader(t), http.StatusBadRequest)
+	doRequest(t, otherAdmin, "GET", path, nil, http.StatusForbidden)
+
+	// The role grants its permissions, and no others
+	doRequest(t, admin, "PUT", fmt.Sprintf("/api/users/teams/%s/users/%s", team.ExternalID, viewer.Email),
+		jsonBody{"roleId": deployer.ID}.Reader(t), http.StatusNoContent)
+	ctx := context.Background()
+	assert.NoError(t, api.RequireTeamMemberPermissionTo(ctx, database, viewer.ID, team.ExternalID, permission.DeployImage))
+	assert.Equal(t, users.ErrForbidden, api.RequireTeamMemberPermissionTo(ctx, database, viewer.ID, team.ExternalID, permission.OpenHostShell))
+
+	doRequest(t, admin, "PUT", path+"/"+deployer.ID, jsonBody{
+		"name":        "Deployer",
+		"permissions": []string{permission.OpenHostShell},
+	}.Reader(t), http.StatusOK)
+	assert.NoError(t, api.RequireTeamMemberPermissionTo(ctx, database, viewer.ID, team.ExternalID, permission.OpenHostShell))
+	assert.Equal(t, users.ErrForbidden, api.RequireTeamMemberPermissionTo(ctx, database, viewer.ID, team.ExternalID, permission.DeployImage))
+
+	// Roles still assigned to members can't be deleted
+	doRequest(t, admin, "DELETE", path+"/"+deployer.ID, nil, http.StatusBadRequest)
+	doRequest(t, admin, "PUT", fmt.Sprintf("/api/users/teams/%s/users/%s", team.ExternalID, viewer.Email),
+		jsonBody{"roleId": users.ViewerRoleID}.Reader(t), http.StatusNoContent)
+	doRequest(t, admin, "DELETE", path+"/"+deployer.ID, nil, http.StatusNoContent)
+}
+
+func TestAPI_TeamRolesKeepAdmin(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	path := "/api/users/teams/" + team.ExternalID + "/roles"
+	owner := createRole(t, admin, team, map[string]interface{}{
+		"name": "Owner",
+		"permissions": []string{
+			permission.InviteTeamMember,
+			permission.UpdateTeamMemberRole,
+			permission.RemoveTeamMember,
+			permission.ManageTeamRoles,
+		},
+	})
+
+	// Make someone with the custom admin-level role the only admin
+	other, err := dbtest.GetUserInTeam(t, database, team, users.ViewerRoleID)
+	require.NoError(t, err)
+	doRequest(t, admin, "PUT", fmt.Sprintf("/api/users/teams/%s/users/%s", team.ExternalID, other.Email),
+		jsonBody{"roleId": owner.ID}.Reader(t), http.StatusNoContent)
+	doRequest(t, other, "PUT", fmt.Sprintf("/api/users/teams/%s/users/%s", team.ExternalID, admin.Email),
+		jsonBody{"roleId": users.EditorRoleID}.Reader(t), http.StatusNoContent)
+
+	// The team would be left without an admin
+	doRequest(t, other, "PUT", path+"/"+owner.ID, jsonBody{
+		"name":        "Owner",
+		"permissions": []string{permission.InviteTeamMember},
+	}.Reader(t), http.StatusForbidden)
+	doRequest(t, other, "DELETE", fmt.Sprintf("/api/users/teams/%s/users/%s", team.ExternalID, other.Email), nil, http.StatusForbidden)
+
+	// Promoting someone else lets them go
+	doRequest(t, other, "PUT", fmt.Sprintf("/api/users/teams/%s/users/%s", team.ExternalID, admin.Email),
+		jsonBody{"roleId": users.AdminRoleID}.Reader(t), http.StatusNoContent)
+	doRequest(t, admin, "PUT", fmt.Sprintf("/api/users/teams/%s/users/%s", team.ExternalID, other.Email),
+		jsonBody{"roleId": users.ViewerRoleID}.Reader(t), http.StatusNoContent)
+
+	// Members who can change roles still can't demote the last admin
+	manager := createRole(t, admin, team, map[string]interface{}{
+		"name":        "Manager",
+		"permissions": []string{permission.UpdateTeamMemberRole},
+	})
+	doRequest(t, admin, "PUT", fmt.Sprintf("/api/users/teams/%s/users/%s", team.ExternalID, other.Email),
+		jsonBody{"roleId": manager.ID}.Reader(t), http.StatusNoContent)
+	doRequest(t, other, "PUT", fmt.Sprintf("/api/users/teams/%s/users/%s", team.ExternalID, admin.Email),
+		jsonBody{"roleId": users.ViewerRoleID}.Reader(t), http.StatusForbidden)
+}
@@ -31,9 +31,41 @@ func (a *API) RegisterRoutes(r *mux.Router) {
 		{"api_users_signup", "POST", "/api/users/signup", a.emailLogin},
 		{"api_users_update", "PUT", "/api/users/user", a.authenticateUser(a.updateUser)},
 		{"api_users_user", "GET", "/api/users/user", a.authenticateUser(a.getCurrentUser)},
+		{"api_users_totp", "GET", "/api/users/user/totp", a.authenticateUser(a.getTOTP)},
+		{"api_users_totp_enroll", "POST", "/api/users/user/totp", a.authenticateUser(a.enrollTOTP)},
+		{"api_users_totp_confirm", "POST", "/api/users/user/totp/confirm", a.authenticateUser(a.confirmTOTP)},
+		{"api_users_totp_recovery_codes", "POST", "/api/users/user/totp/recovery_codes", a.authenticateUser(a.regenerateRecoveryCodes)},
+		{"api_users_totp_disable", "DELETE", "/api/users/user/totp", a.authenticateUser(a.disableTOTP)},
+		// Finishes logging in users who have enabled two-factor
+		// authentication, given their code.
+		{"api_users_login_verify", "POST", "/api/users/login/verify", a.verifyLogin},
+		{"api_users_sessions", "GET", "/api/users/sessions", a.authenticateUser(a.listSessions)},
+		{"api_users_sessions_revoke", "DELETE", "/api/users/sessions/{sessionID}", a.authenticateUser(a.revokeSession)},
 		{"api_users_gcp_subscribe", "POST", "/api/users/gcp/subscribe", a.authenticateUser(a.gcpSubscribe)},
 		{"api_users_gcp_sso_login", "GET", "/api/users/gcp/sso/login", a.authenticateUser(a.gcpSSOLogin)},
 
+		// Single sign-on through a team's own identity provider. The login
+		// endpoint redirects to the identity provider, which sends users back
+		// to the callback (SAML identity providers POST their responses).
+		{"api_users_sso_teamExternalID_login", "GET", "/api/users/sso/{teamExternalID}/login", a.ssoLogin},
+		{"api_users_sso_teamExternalID_callback", "GET", "/api/users/sso/{teamExternalID}/callback", a.ssoCallback},
+		{"api_users_sso_teamExternalID_callback", "POST", "/api/users/sso/{teamExternalID}/callback", a.ssoCallback},
+		{"api_users_sso_teamExternalID_metadata", "GET", "/api/users/sso/{teamExternalID}/metadata", a.ssoMetadata},
+
+		// SCIM provisioning of a team's members by its identity provider,
+		// authenticated by the team's SCIM token.
+		{"api_users_scim_teamExternalID_service_provider_config", "GET", "/api/users/scim/v2/{teamExternalID}/ServiceProviderConfig", a.authenticateSCIM(a.scimServiceProviderConfig)},
+		{"api_users_scim_teamExternalID_users", "GET", "/api/users/scim/v2/{teamExternalID}/Users", a.authenticateSCIM(a.scimListUsers)},
+		{"api_users_scim_teamExternalID_create_user", "POST", "/api/users/scim/v2/{teamExternalID}/Users", a.authenticateSCIM(a.scimCreateUser)},
+		{"api_users_scim_teamExternalID_user", "GET", "/api/users/scim/v2/{teamExternalID}/Users/{userID}", a.authenticateSCIM(a.scimGetUser)},
+		{"api_users_scim_teamExternalID_replace_user", "PUT", "/api/users/scim/v2/{teamExternalID}/Users/{userID}", a.authenticateSCIM(a.scimReplaceUser)},
+		{"api_users_scim_teamExternalID_patch_user", "PATCH", "/api/users/scim/v2/{teamExternalID}/Users/{userID}", a.authenticateSCIM(a.scimPatchUser)},
+		{"api_users_scim_teamExternalID_delete_user", "DELETE", "/api/users/scim/v2/{teamExternalID}/Users/{userID}", a.authenticateSCIM(a.scimDeleteUser)},
+		{"api_users_scim_teamExternalID_groups", "GET", "/api/users/scim/v2/{teamExternalID}/Groups", a.authenticateSCIM(a.scimListGroups)},
+		{"api_users_scim_teamExternalID_group", "GET", "/api/users/scim/v2/{teamExternalID}/Groups/{roleID}", a.authenticateSCIM(a.scimGetGroup)},
+		{"api_users_scim_teamExternalID_replace_group", "PUT", "/api/users/scim/v2/{teamExternalID}/Groups/{roleID}", a.authenticateSCIM(a.scimReplaceGroup)},
+		{"api_users_scim_teamExternalID_patch_group", "PATCH", "/api/users/scim/v2/{teamExternalID}/Groups/{roleID}", a.authenticateSCIM(a.scimPatchGroup)},
+
 		// Logs the current user out (just deletes the session cookie)
 		{"api_users_logout", "POST", "/api/users/logout", a.logout},
 
@@ -54,6 +86,24 @@ func (a *API) RegisterRoutes(r *mux.Router) {
 		{"api_users_team_teamExternalID_remove_user_from_team", "DELETE", "/api/users/teams/{teamExternalID}/users/{userEmail}", a.authenticateUser(a.removeUserFromTeam)},
 		{"api_users_team_teamExternalID_invite_user_to_team", "POST", "/api/users/teams/{teamExternalID}/users", a.authenticateUser(a.inviteUserToTeam)},
 		{"api_users_teams_teamExternalID_update_user_role", "PUT", "/api/users/teams/{teamExternalID}/users/{userEmail}", a.authenticateUser(a.updateUserRoleInTeam)},
+		{"api_users_permissions", "GET", "/api/users/permissions", a.authenticateUser(a.listPermissions)},
+		{"api_users_teams_teamExternalID_roles", "GET", "/api/users/teams/{teamExternalID}/roles", a.authenticateUser(a.listTeamRoles)},
+		{"api_users_teams_teamExternalID_create_role", "POST", "/api/users/teams/{teamExternalID}/roles", a.authenticateUser(a.createTeamRole)},
+		{"api_users_teams_teamExternalID_update_role", "PUT", "/api/users/teams/{teamExternalID}/roles/{roleID}", a.authenticateUser(a.updateTeamRole)},
+		{"api_users_teams_teamExternalID_delete_role", "DELETE", "/api/users/teams/{teamExternalID}/roles/{roleID}", a.authenticateUser(a.deleteTeamRole)},
+		{"api_users_teams_teamExternalID_sso", "GET", "/api/users/teams/{teamExternalID}/sso", a.authenticateUser(a.getTeamSSO)},
+		{"api_users_teams_teamExternalID_update_sso", "PUT", "/api/users/teams/{teamExternalID}/sso", a.authenticateUser(a.setTeamSSO)},
+		{"api_users_teams_teamExternalID_delete_sso", "DELETE", "/api/users/teams/{teamExternalID}/sso", a.authenticateUser(a.deleteTeamSSO)},
+		{"api_users_teams_teamExternalID_two_factor", "GET", "/api/users/teams/{teamExternalID}/two_factor", a.authenticateUser(a.getTeamTwoFactor)},
+		{"api_users_teams_teamExternalID_update_two_factor", "PUT", "/api/users/teams/{teamExternalID}/two_factor", a.authenticateUser(a.setTeamTwoFactor)},
+		{"api_users_teams_teamExternalID_scim", "GET", "/api/users/teams/{teamExternalID}/scim", a.authenticateUser(a.getTeamSCIM)},
+		{"api_users_teams_teamExternalID_create_scim_token", "POST", "/api/users/teams/{teamExternalID}/scim", a.authenticateUser(a.createTeamSCIMToken)},
+		{"api_users_teams_teamExternalID_delete_scim_token", "DELETE", "/api/users/teams/{teamExternalID}/scim", a.authenticateUser(a.deleteTeamSCIMToken)},
+		{"api_users_teams_teamExternalID_event_webhooks", "GET", "/api/users/teams/{teamExternalID}/event_webhooks", a.authenticateUser(a.listEventWebhooks)},
+		{"api_users_teams_teamExternalID_create_event_webhook", "POST", "/api/users/teams/{teamExternalID}/event_webhooks", a.authenticateUser(a.createEventWebhook)},
+		{"api_users_teams_teamExternalID_delete_event_webhook", "DELETE", "/api/users/teams/{teamExternalID}/event_webhooks/{webhookID}", a.authenticateUser(a.deleteEventWebhook)},
+		{"api_users_teams_teamExternalID_event_webhook_deliveries", "GET", "/api/users/teams/{teamExternalID}/event_webhooks/{webhookID}/deliveries", a.authenticateUser(a.listEventDeliveries)},
+		{"api_users_teams_teamExternalID_test_event_webhook", "POST", "/api/users/teams/{teamExternalID}/event_webhooks/{webhookID}/test", a.authenticateUser(a.testEventWebhook)},
 		{"api_users_teams_teamExternalID_permissions", "GET", "/api/users/teams/{teamExternalID}/users/{userEmail}/permissions", a.authenticateUser(a.listTeamPermissions)},
 
 		// Used by the launcher agent to get the external instance ID using a token
@@ -80,6 +130,14 @@ func (a *API) RegisterRoutes(r *mux.Router) {
 		{"api_users_webhooks_create", "POST", "/api/users/org/{orgExternalID}/webhooks", a.authenticateUser(a.createOrganizationWebhook)},
 		{"api_users_webhooks_delete", "DELETE", "/api/users/org/{orgExternalID}/webhooks/{secretID}", a.authenticateUser(a.deleteOrganizationWebhook)},
 
+		// Organization API tokens
+		{"api_users_tokens_list", "GET", "/api/users/org/{orgExternalID}/tokens", a.authenticateUser(a.listAPITokens)},
+		{"api_users_tokens_create", "POST", "/api/users/org/{orgExternalID}/tokens", a.authenticateUser(a.createAPIToken)},
+		{"api_users_tokens_revoke", "DELETE", "/api/users/org/{orgExternalID}/tokens/{tokenID}", a.authenticateUser(a.revokeAPIToken)},
+
+		// Organization audit log
+		{"api_users_org_orgExternalID_audit", "GET", "/api/users/org/{orgExternalID}/audit", a.authenticateUser(a.listAuditEntries)},
+
 		// Internal stuff for our internal usage, internally.
 		{"root", "GET", "/admin/users", a.admin},
 		{"admin_users_weekly_reports", "GET", "/admin/users/weeklyreports", a.adminWeeklyReportsControlPanel},
@@ -98,13 +156,20 @@ func (a *API) RegisterRoutes(r *mux.Router) {
 		{"admin_users_users_userID_become", "POST", "/admin/users/users/{userID}/become", a.adminBecomeUser},
 		{"admin_users_users_userID_delete", "POST", "/admin/users/users/{userID}/remove", a.adminDeleteUser},
 		{"admin_users_users_userID_logins_provider_token", "GET", "/admin/users/users/{userID}/logins/{provider}/token", a.adminGetUserToken},
+		{"admin_users_users_userID_cluster_accounts_provider", "GET", "/admin/users/users/{userID}/cluster-accounts/{provider}", a.adminListClusterProviderAccounts},
+		{"admin_users_users_userID_cluster_accounts_provider_add", "POST", "/admin/users/users/{userID}/cluster-accounts/{provider}", a.adminAddClusterProviderAccount},
 		{"admin_users_users_userID_organizations", "GET", "/admin/users/users/{userID}/organizations", a.adminListOrganizationsForUser},
 		{"admin_users_teams", "GET", "/admin/users/teams", a.adminListTeams},
+		{"admin_users_audit", "GET", "/admin/users/audit", a.adminListAuditEntries},
+		{"admin_users_featureflags", "GET", "/admin/users/featureflags", a.adminListFeatureFlags},
+		{"admin_users_featureflags_set", "POST", "/admin/users/featureflags", a.adminSetFeatureFlag},
+		{"admin_users_featureflags_name_organizations", "GET", "/admin/users/featureflags/{name}/organizations", a.adminListFeatureFlagOrganizations},
+		{"admin_users_featureflags_name_delete", "POST", "/admin/users/featureflags/{name}/remove", a.adminDeleteFeatureFlag},
 		{"admin_users_teams_teamID_billing", "POST", "/admin/users/teams/{teamID}/billing", a.adminChangeTeamBilling},
 
 		// HealthCheck
 		{"healthcheck", "GET", "/api/users/healthcheck", a.healthcheck},
 	} {
-		r.Handle(route.path, route.handler).Methods(route.method).Name(route.name)
+		r.Handle(route.path, a.withAuditActor(route.handler)).Methods(route.method).Name(route.name)
 	}
 }
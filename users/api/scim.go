@@ -0,0 +1,910 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/common/logging"
+	commonuser "github.com/weaveworks/common/user"
+	"github.com/weaveworks/service/common/permission"
+	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/common/validation"
+	"github.com/weaveworks/service/users"
+	usersRender "github.com/weaveworks/service/users/render"
+)
+
+// Teams' identity providers provision their members through SCIM 2.0
+// (RFC 7643 and RFC 7644). SCIM users are the users who are members of the
+// team, and SCIM groups are the roles members have. Other users are only
+// found if the team's identity provider provisioned or deprovisioned them,
+// so that a team's token can't find, or add, anyone else by their ID. Roles themselves are
+// managed here, so groups can't be created, renamed or deleted through SCIM,
+// only their members changed.
+
+const (
+	scimUserSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimServiceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimListResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	scimContentType = "application/scim+json"
+)
+
+// TeamSCIMView describes a team's SCIM provisioning. The token is only
+// returned when it is created.
+type TeamSCIMView struct {
+	// BaseURL is what to configure the identity provider with.
+	BaseURL   string    `json:"baseUrl"`
+	Token     string    `json:"token,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SCIMMeta is the metadata of a SCIM resource.
+type SCIMMeta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	Location     string     `json:"location"`
+}
+
+// SCIMName is the name of a SCIM user.
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMEmail is one of a SCIM user's emails.
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMMember references a user in a group, or a group a user is in.
+type SCIMMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMUser is a member of a team, as a SCIM resource.
+type SCIMUser struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *SCIMName    `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []SCIMEmail  `json:"emails,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Groups      []SCIMMember `json:"groups,omitempty"`
+	Meta        *SCIMMeta    `json:"meta,omitempty"`
+}
+
+// SCIMGroup is a role members of a team may have, as a SCIM resource.
+type SCIMGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id"`
+	DisplayName string       `json:"displayName"`
+	Members     []SCIMMember `json:"members"`
+	Meta        *SCIMMeta    `json:"meta,omitempty"`
+}
+
+// SCIMListResponse is a page of SCIM resources.
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// SCIMPatchOp is a request to modify a SCIM resource.
+type SCIMPatchOp struct {
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMPatchOperation is one of the modifications of a SCIMPatchOp.
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// scimError is an error with the status, and SCIM error type, to report it
+// to identity providers with.
+type scimError struct {
+	status   int
+	scimType string
+	detail   string
+}
+
+func (err *scimError) Error() string {
+	return err.detail
+}
+
+func scimErrorf(status int, scimType, format string, args ...interface{}) error {
+	return &scimError{status: status, scimType: scimType, detail: fmt.Sprintf(format, args...)}
+}
+
+func renderSCIM(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logging.Global().Errorf("Error encoding SCIM response: %v", err)
+	}
+}
+
+func renderSCIMError(w http.ResponseWriter, r *http.Request, err error) {
+	commonuser.LogWith(r.Context(), logging.Global()).Errorf("%s %s: %v", r.Method, r.URL.Path, err)
+
+	body := map[string]interface{}{"schemas": []string{scimErrorSchema}}
+	status := usersRender.ErrorStatusCode(err)
+	detail := err.Error()
+	if e, ok := err.(*scimError); ok {
+		status = e.status
+		if e.scimType != "" {
+			body["scimType"] = e.scimType
+		}
+	} else if status == http.StatusInternalServerError {
+		detail = "An internal server error occurred"
+	}
+	body["status"] = strconv.Itoa(status)
+	body["detail"] = detail
+	renderSCIM(w, status, body)
+}
+
+func (a *API) scimBaseURL(teamExternalID string) string {
+	return fmt.Sprintf("%s/api/users/scim/v2/%s", a.domain, url.PathEscape(teamExternalID))
+}
+
+// teamForSCIMManagement finds a team whose SCIM provisioning the current
+// user may manage.
+func (a *API) teamForSCIMManagement(currentUser *users.User, r *http.Request) (*users.Team, error) {
+	ctx := r.Context()
+	teamExternalID := mux.Vars(r)["teamExternalID"]
+	team, err := a.userCanAccessTeam(ctx, currentUser, teamExternalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireTeamMemberPermissionTo(ctx, a.db, currentUser.ID, teamExternalID, permission.ManageTeamSCIM); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (a *API) getTeamSCIM(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	team, err := a.teamForSCIMManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	token, err := a.db.GetTeamSCIMToken(r.Context(), team.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, TeamSCIMView{
+		BaseURL:   a.scimBaseURL(team.ExternalID),
+		CreatedAt: token.CreatedAt,
+	})
+}
+
+// createTeamSCIMToken creates a token for the team's identity provider,
+// replacing any the team had.
+func (a *API) createTeamSCIMToken(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	team, err := a.teamForSCIMManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	token, err := a.db.CreateTeamSCIMToken(r.Context(), team.ID, currentUser.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusCreated, TeamSCIMView{
+		BaseURL:   a.scimBaseURL(team.ExternalID),
+		Token:     token.Token,
+		CreatedAt: token.CreatedAt,
+	})
+}
+
+func (a *API) deleteTeamSCIMToken(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	team, err := a.teamForSCIMManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.db.DeleteTeamSCIMToken(r.Context(), team.ID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authenticateSCIM authenticates a team's identity provider by the team's
+// SCIM bearer token. Tokens only give access to their own team.
+func (a *API) authenticateSCIM(handler func(*users.Team, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		header := r.Header.Get("Authorization")
+		if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+			renderSCIMError(w, r, users.ErrInvalidAuthenticationData)
+			return
+		}
+		token, err := a.db.FindTeamSCIMToken(ctx, strings.TrimSpace(header[len("Bearer "):]))
+		if err == users.ErrNotFound {
+			err = users.ErrInvalidAuthenticationData
+		}
+		if err != nil {
+			renderSCIMError(w, r, err)
+			return
+		}
+		team, err := a.db.FindTeamByInternalID(ctx, token.TeamID)
+		if err != nil {
+			renderSCIMError(w, r, err)
+			return
+		}
+		if !strings.EqualFold(team.ExternalID, mux.Vars(r)["teamExternalID"]) {
+			renderSCIMError(w, r, users.ErrInvalidAuthenticationData)
+			return
+		}
+		handler(team, w, r)
+	}
+}
+
+func (a *API) scimServiceProviderConfig(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	supported := func(ok bool) map[string]interface{} {
+		return map[string]interface{}{"supported": ok}
+	}
+	renderSCIM(w, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{scimServiceProviderConfigSchema},
+		"patch":          supported(true),
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": scimMaxResults},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "The team's SCIM token, sent as a bearer token",
+			"primary":     true,
+		}},
+		"meta": SCIMMeta{ResourceType: "ServiceProviderConfig", Location: a.scimBaseURL(team.ExternalID) + "/ServiceProviderConfig"},
+	})
+}
+
+// scimMaxResults is the most resources returned in one page.
+const scimMaxResults = 200
+
+var scimFilterRegexp = regexp.MustCompile(`^\s*([A-Za-z.]+)\s+(?i:eq)\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// scimFilter parses the simple filters identity providers use to look
+// resources up, e.g. `userName eq "alice@example.com"`: equality of one of
+// attributes.
+func scimFilter(r *http.Request, attributes ...string) (attribute, value string, err error) {
+	filter := r.URL.Query().Get("filter")
+	if filter == "" {
+		return "", "", nil
+	}
+	match := scimFilterRegexp.FindStringSubmatch(filter)
+	if match == nil {
+		return "", "", scimErrorf(http.StatusBadRequest, "invalidFilter", "Unsupported filter: %q", filter)
+	}
+	if err := json.Unmarshal([]byte(`"`+match[2]+`"`), &value); err != nil {
+		return "", "", scimErrorf(http.StatusBadRequest, "invalidFilter", "Invalid filter: %q", filter)
+	}
+	for _, a := range attributes {
+		if strings.EqualFold(match[1], a) {
+			return a, value, nil
+		}
+	}
+	return "", "", scimErrorf(http.StatusBadRequest, "invalidFilter", "Unsupported filter attribute: %q", match[1])
+}
+
+// scimPage returns a page of n resources, as requested by SCIM's 1-based
+// startIndex and count.
+func scimPage(r *http.Request, n int) (start, end int) {
+	startIndex, err := strconv.Atoi(r.URL.Query().Get("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 0 || count > scimMaxResults {
+		count = scimMaxResults
+	}
+	start = startIndex - 1
+	if start > n {
+		start = n
+	}
+	end = start + count
+	if end > n {
+		end = n
+	}
+	return start, end
+}
+
+func scimList(r *http.Request, resources []interface{}) SCIMListResponse {
+	start, end := scimPage(r, len(resources))
+	return SCIMListResponse{
+		Schemas:      []string{scimListResponseSchema},
+		TotalResults: len(resources),
+		StartIndex:   start + 1,
+		ItemsPerPage: end - start,
+		Resources:    resources[start:end],
+	}
+}
+
+func (a *API) renderSCIMUser(team *users.Team, user *users.User, role *users.Role) SCIMUser {
+	active := role != nil
+	created := user.CreatedAt
+	view := SCIMUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       user.ID,
+		UserName: user.Email,
+		Emails:   []SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:   &active,
+		Meta: &SCIMMeta{
+			ResourceType: "User",
+			Created:      &created,
+			Location:     a.scimBaseURL(team.ExternalID) + "/Users/" + user.ID,
+		},
+	}
+	if user.Name != "" || user.FirstName != "" || user.LastName != "" {
+		view.Name = &SCIMName{Formatted: user.Name, GivenName: user.FirstName, FamilyName: user.LastName}
+		view.DisplayName = user.Name
+	}
+	if role != nil {
+		view.Groups = []SCIMMember{{
+			Value:   role.ID,
+			Display: role.Name,
+			Ref:     a.scimBaseURL(team.ExternalID) + "/Groups/" + role.ID,
+		}}
+	}
+	return view
+}
+
+// scimDefaultRoleID is the role of members provisioned by the team's
+// identity provider: the same as those who join by logging in through it.
+func (a *API) scimDefaultRoleID(ctx context.Context, team *users.Team) (string, error) {
+	config, err := a.db.GetTeamSSOConfig(ctx, team.ID)
+	if err == users.ErrNotFound {
+		return users.DefaultRoleID, nil
+	} else if err != nil {
+		return "", err
+	}
+	return config.DefaultRoleID, nil
+}
+
+func (a *API) scimListUsers(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	attribute, value, err := scimFilter(r, "userName", "emails.value")
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	members, err := a.db.ListTeamUsersWithRoles(ctx, team.ID)
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	resources := []interface{}{}
+	for _, m := range members {
+		if attribute != "" && !strings.EqualFold(m.User.Email, value) {
+			continue
+		}
+		resources = append(resources, a.renderSCIMUser(team, &m.User, &m.Role))
+	}
+	renderSCIM(w, http.StatusOK, scimList(r, resources))
+}
+
+// findSCIMUser finds a user, and their role in the team: nil if they aren't
+// a member. Users who aren't members are only found if the team's identity
+// provider manages them.
+func (a *API) findSCIMUser(ctx context.Context, team *users.Team, userID string) (*users.User, *users.Role, error) {
+	user, err := a.db.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	role, err := a.db.GetUserRoleInTeam(ctx, user.ID, team.ID)
+	if err == users.ErrNotFound {
+		managed, err := a.db.IsTeamSCIMUser(ctx, team.ID, user.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !managed {
+			return nil, nil, users.ErrNotFound
+		}
+		return user, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	return user, role, nil
+}
+
+// scimAddUserToTeam adds a user the team's identity provider manages to the
+// team. Users who already had an account are told, as when they're invited.
+func (a *API) scimAddUserToTeam(ctx context.Context, team *users.Team, user *users.User, roleID string, created bool) error {
+	if err := a.db.AddTeamSCIMUser(ctx, team.ID, user.ID); err != nil {
+		return err
+	}
+	if err := a.db.AddUserToTeam(ctx, user.ID, team.ID, roleID); err != nil {
+		return err
+	}
+	if !created {
+		inviter := &users.User{Email: fmt.Sprintf("The identity provider of %s", team.Name)}
+		if err := a.emailer.GrantAccessToTeamEmail(ctx, inviter, user, team.ExternalID, team.Name); err != nil {
+			commonuser.LogWith(ctx, logging.Global()).Errorf("Error telling user %s they were provisioned in team %s: %v", user.ID, team.ID, err)
+		}
+	}
+	return nil
+}
+
+func (a *API) scimGetUser(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	user, role, err := a.findSCIMUser(r.Context(), team, mux.Vars(r)["userID"])
+	if err == nil && role == nil {
+		err = users.ErrNotFound
+	}
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	renderSCIM(w, http.StatusOK, a.renderSCIMUser(team, user, role))
+}
+
+// scimEmail is the email of a SCIM user: their primary email, or else their
+// user name.
+func scimEmail(view SCIMUser) string {
+	for _, e := range view.Emails {
+		if e.Primary {
+			return strings.TrimSpace(e.Value)
+		}
+	}
+	if len(view.Emails) > 0 && !strings.Contains(view.UserName, "@") {
+		return strings.TrimSpace(view.Emails[0].Value)
+	}
+	return strings.TrimSpace(view.UserName)
+}
+
+// scimCreateUser provisions a member of the team. Users who don't have an
+// account yet get one, which they log in to through the team's single
+// sign-on. Users who do are invited, i.e. added to the team and told so.
+func (a *API) scimCreateUser(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+	var view SCIMUser
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		renderSCIMError(w, r, scimErrorf(http.StatusBadRequest, "invalidSyntax", "%v", err))
+		return
+	}
+	email := scimEmail(view)
+	if !validation.ValidateEmail(email) {
+		renderSCIMError(w, r, scimErrorf(http.StatusBadRequest, "invalidValue", "Invalid email: %q", email))
+		return
+	}
+
+	roleID, err := a.scimDefaultRoleID(ctx, team)
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	user, err := a.db.FindUserByEmail(ctx, email)
+	created := false
+	if err == users.ErrNotFound {
+		user, err = a.db.CreateUser(ctx, email, nil)
+		created = true
+	} else if err == nil {
+		if _, err := a.db.GetUserRoleInTeam(ctx, user.ID, team.ID); err == nil {
+			renderSCIMError(w, r, scimErrorf(http.StatusConflict, "uniqueness", "%s is already a member of the team", email))
+			return
+		}
+	}
+	if err == nil {
+		err = a.scimAddUserToTeam(ctx, team, user, roleID, created)
+	}
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	// Only new accounts are named after the identity provider's user: users
+	// otherwise manage their own profiles.
+	if created && view.Name != nil {
+		update := &users.UserUpdate{
+			Name:      stripHTML(view.Name.Formatted),
+			FirstName: stripHTML(view.Name.GivenName),
+			LastName:  stripHTML(view.Name.FamilyName),
+		}
+		if update.Name == "" {
+			update.Name = strings.TrimSpace(update.FirstName + " " + update.LastName)
+		}
+		if validateNames(update.Name, update.FirstName, update.LastName, "") == nil {
+			if user, err = a.db.UpdateUser(ctx, user.ID, update); err != nil {
+				renderSCIMError(w, r, err)
+				return
+			}
+		}
+	}
+
+	_, role, err := a.findSCIMUser(ctx, team, user.ID)
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	resource := a.renderSCIMUser(team, user, role)
+	w.Header().Set("Location", resource.Meta.Location)
+	renderSCIM(w, http.StatusCreated, resource)
+}
+
+// scimSetUserActive adds a user to the team, or removes them from it. Users
+// removed from the team are logged out of their sessions, and still managed
+// by the team's identity provider, which may add them back.
+func (a *API) scimSetUserActive(ctx context.Context, team *users.Team, user *users.User, role *users.Role, active bool) (*users.Role, error) {
+	switch {
+	case active && role == nil:
+		roleID, err := a.scimDefaultRoleID(ctx, team)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.scimAddUserToTeam(ctx, team, user, roleID, false); err != nil {
+			return nil, err
+		}
+		return a.db.GetUserRoleInTeam(ctx, user.ID, team.ID)
+	case !active && role != nil:
+		if err := a.requireTeamKeepsAdmin(ctx, team.ID, map[string]string{user.ID: ""}, nil); err != nil {
+			return nil, scimErrorf(http.StatusForbidden, "", "The team's last admin can't be removed")
+		}
+		if err := a.db.AddTeamSCIMUser(ctx, team.ID, user.ID); err != nil {
+			return nil, err
+		}
+		return nil, a.db.RemoveUserFromTeam(ctx, user.ID, team.ID)
+	}
+	return role, nil
+}
+
+// scimReplaceUser replaces a user. Only whether they are active, i.e. a
+// member of the team, is changed.
+func (a *API) scimReplaceUser(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	var view SCIMUser
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		renderSCIMError(w, r, scimErrorf(http.StatusBadRequest, "invalidSyntax", "%v", err))
+		return
+	}
+	active := view.Active == nil || *view.Active
+	a.scimUpdateUser(team, &active, w, r)
+}
+
+// scimPatchUser modifies a user. Only whether they are active, i.e. a
+// member of the team, is changed: identity providers deactivate users
+// they deprovision.
+func (a *API) scimPatchUser(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	var patch SCIMPatchOp
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		renderSCIMError(w, r, scimErrorf(http.StatusBadRequest, "invalidSyntax", "%v", err))
+		return
+	}
+	var active *bool
+	for _, op := range patch.Operations {
+		if !strings.EqualFold(op.Op, "replace") && !strings.EqualFold(op.Op, "add") {
+			continue
+		}
+		value := op.Value
+		if op.Path == "" {
+			// The value holds the attributes being replaced
+			var attributes map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &attributes); err != nil {
+				renderSCIMError(w, r, scimErrorf(http.StatusBadRequest, "invalidSyntax", "%v", err))
+				return
+			}
+			var ok bool
+			if value, ok = attributes["active"]; !ok {
+				continue
+			}
+		} else if !strings.EqualFold(op.Path, "active") {
+			continue
+		}
+		b, err := scimBool(value)
+		if err != nil {
+			renderSCIMError(w, r, err)
+			return
+		}
+		active = &b
+	}
+	a.scimUpdateUser(team, active, w, r)
+}
+
+// scimBool parses a boolean, which some identity providers send as a
+// string.
+func scimBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, scimErrorf(http.StatusBadRequest, "invalidValue", "Invalid boolean: %s", value)
+}
+
+// scimUpdateUser makes a user active, i.e. a member of the team, or not. A
+// nil active leaves them as they are. Users who aren't members are only
+// found when they are being made active.
+func (a *API) scimUpdateUser(team *users.Team, active *bool, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, role, err := a.findSCIMUser(ctx, team, mux.Vars(r)["userID"])
+	if err == nil && role == nil && (active == nil || !*active) {
+		err = users.ErrNotFound
+	}
+	if err == nil && active != nil {
+		role, err = a.scimSetUserActive(ctx, team, user, role, *active)
+	}
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	renderSCIM(w, http.StatusOK, a.renderSCIMUser(team, user, role))
+}
+
+func (a *API) scimDeleteUser(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, role, err := a.findSCIMUser(ctx, team, mux.Vars(r)["userID"])
+	if err == nil && role == nil {
+		err = users.ErrNotFound
+	}
+	if err == nil {
+		_, err = a.scimSetUserActive(ctx, team, user, role, false)
+	}
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// scimGroups renders the team's roles as groups, with their members.
+func (a *API) scimGroups(ctx context.Context, team *users.Team) ([]SCIMGroup, error) {
+	roles, err := a.db.ListTeamRoles(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := a.db.ListTeamUsersWithRoles(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]SCIMGroup, 0, len(roles))
+	for _, role := range roles {
+		group := SCIMGroup{
+			Schemas:     []string{scimGroupSchema},
+			ID:          role.ID,
+			DisplayName: role.Name,
+			Members:     []SCIMMember{},
+			Meta: &SCIMMeta{
+				ResourceType: "Group",
+				Location:     a.scimBaseURL(team.ExternalID) + "/Groups/" + role.ID,
+			},
+		}
+		for _, m := range members {
+			if m.Role.ID == role.ID {
+				group.Members = append(group.Members, SCIMMember{
+					Value:   m.User.ID,
+					Display: m.User.Email,
+					Ref:     a.scimBaseURL(team.ExternalID) + "/Users/" + m.User.ID,
+				})
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// findSCIMGroup finds one of the roles of a team, as a group.
+func (a *API) findSCIMGroup(ctx context.Context, team *users.Team, roleID string) (*SCIMGroup, error) {
+	groups, err := a.scimGroups(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.ID == roleID {
+			return &g, nil
+		}
+	}
+	return nil, users.ErrNotFound
+}
+
+func (a *API) scimListGroups(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	attribute, value, err := scimFilter(r, "displayName")
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	groups, err := a.scimGroups(r.Context(), team)
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	resources := []interface{}{}
+	for _, g := range groups {
+		if attribute != "" && !strings.EqualFold(g.DisplayName, value) {
+			continue
+		}
+		resources = append(resources, g)
+	}
+	renderSCIM(w, http.StatusOK, scimList(r, resources))
+}
+
+func (a *API) scimGetGroup(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	group, err := a.findSCIMGroup(r.Context(), team, mux.Vars(r)["roleID"])
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	renderSCIM(w, http.StatusOK, group)
+}
+
+// scimReplaceGroup replaces the members of a group. Members who are no
+// longer in it get the team's default role; users who weren't members of
+// the team join it.
+func (a *API) scimReplaceGroup(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	var view SCIMGroup
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		renderSCIMError(w, r, scimErrorf(http.StatusBadRequest, "invalidSyntax", "%v", err))
+		return
+	}
+	a.scimUpdateGroup(team, func(members map[string]bool) error {
+		for id := range members {
+			delete(members, id)
+		}
+		for _, m := range view.Members {
+			members[m.Value] = true
+		}
+		return nil
+	}, w, r)
+}
+
+// scimMemberPathRegexp matches the paths of particular members, e.g.
+// `members[value eq "42"]`.
+var scimMemberPathRegexp = regexp.MustCompile(`^(?i:members)\[\s*(?i:value)\s+(?i:eq)\s+"([^"]*)"\s*\]$`)
+
+// scimPatchGroup adds members to, and removes them from, a group.
+func (a *API) scimPatchGroup(team *users.Team, w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	var patch SCIMPatchOp
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		renderSCIMError(w, r, scimErrorf(http.StatusBadRequest, "invalidSyntax", "%v", err))
+		return
+	}
+	a.scimUpdateGroup(team, func(members map[string]bool) error {
+		for _, op := range patch.Operations {
+			var values []SCIMMember
+			if len(op.Value) > 0 {
+				if err := json.Unmarshal(op.Value, &values); err != nil {
+					if op.Path != "" {
+						return scimErrorf(http.StatusBadRequest, "invalidValue", "Invalid members: %s", op.Value)
+					}
+					// Only members can be changed
+					continue
+				}
+			}
+			if match := scimMemberPathRegexp.FindStringSubmatch(op.Path); match != nil {
+				values = append(values, SCIMMember{Value: match[1]})
+			} else if op.Path != "" && !strings.EqualFold(op.Path, "members") {
+				// Only members can be changed
+				continue
+			}
+			switch strings.ToLower(op.Op) {
+			case "add":
+				for _, m := range values {
+					members[m.Value] = true
+				}
+			case "remove":
+				if len(values) == 0 {
+					for id := range members {
+						delete(members, id)
+					}
+				}
+				for _, m := range values {
+					delete(members, m.Value)
+				}
+			case "replace":
+				for id := range members {
+					delete(members, id)
+				}
+				for _, m := range values {
+					members[m.Value] = true
+				}
+			default:
+				return scimErrorf(http.StatusBadRequest, "invalidSyntax", "Invalid operation: %q", op.Op)
+			}
+		}
+		return nil
+	}, w, r)
+}
+
+// scimUpdateGroup changes the members of a group, i.e. which members of the
+// team have a role. update is passed the IDs of the group's members, to
+// change.
+func (a *API) scimUpdateGroup(team *users.Team, update func(members map[string]bool) error, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	group, err := a.findSCIMGroup(ctx, team, mux.Vars(r)["roleID"])
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	members := map[string]bool{}
+	for _, m := range group.Members {
+		members[m.Value] = true
+	}
+	if err := update(members); err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+
+	defaultRoleID, err := a.scimDefaultRoleID(ctx, team)
+	if err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	changes := map[string]string{}
+	for _, m := range group.Members {
+		// Members of the default role can't be removed from it: they'd get
+		// the same role.
+		if !members[m.Value] && group.ID != defaultRoleID {
+			changes[m.Value] = defaultRoleID
+		}
+	}
+	var joining []*users.User
+	for userID := range members {
+		user, role, err := a.findSCIMUser(ctx, team, userID)
+		if err == users.ErrNotFound {
+			err = scimErrorf(http.StatusBadRequest, "invalidValue", "Unknown user: %q", userID)
+		}
+		if err != nil {
+			renderSCIMError(w, r, err)
+			return
+		}
+		if role == nil {
+			joining = append(joining, user)
+		} else if role.ID != group.ID {
+			changes[userID] = group.ID
+		}
+	}
+	if err := a.requireTeamKeepsAdmin(ctx, team.ID, changes, nil); err != nil {
+		renderSCIMError(w, r, scimErrorf(http.StatusForbidden, "", "The team must keep an admin"))
+		return
+	}
+
+	for userID, roleID := range changes {
+		if err := a.db.UpdateUserRoleInTeam(ctx, userID, team.ID, roleID); err != nil {
+			renderSCIMError(w, r, err)
+			return
+		}
+	}
+	for _, user := range joining {
+		if err := a.scimAddUserToTeam(ctx, team, user, group.ID, false); err != nil {
+			renderSCIMError(w, r, err)
+			return
+		}
+	}
+
+	if group, err = a.findSCIMGroup(ctx, team, group.ID); err != nil {
+		renderSCIMError(w, r, err)
+		return
+	}
+	renderSCIM(w, http.StatusOK, group)
+}
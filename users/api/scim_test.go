@@ -0,0 +1,266 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/api"
+	"github.com/weaveworks/service/users/db/dbtest"
+)
+
+// scimClient is a team's identity provider.
+type scimClient struct {
+	t       *testing.T
+	baseURL string
+	token   string
+}
+
+func newSCIMClient(t *testing.T, admin *users.User, team *users.Team) scimClient {
+	data := doRequest(t, admin, "POST", "/api/users/teams/"+team.ExternalID+"/scim", nil, http.StatusCreated)
+	var view api.TeamSCIMView
+	require.NoError(t, json.Unmarshal(data, &view))
+	require.NotEmpty(t, view.Token)
+	return scimClient{t: t, baseURL: "/api/users/scim/v2/" + team.ExternalID, token: view.Token}
+}
+
+func (c scimClient) do(method, path string, body jsonBody, expectedStatus int, into interface{}) {
+	var r *http.Request
+	if body != nil {
+		r = httptest.NewRequest(method, c.baseURL+path, body.Reader(c.t))
+	} else {
+		r = httptest.NewRequest(method, c.baseURL+path, nil)
+	}
+	r.Header.Set("Authorization", "Bearer "+c.token)
+	r.Header.Set("Content-Type", "application/scim+json")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	require.Equal(c.t, expectedStatus, w.Code, w.Body.String())
+	if into != nil {
+		require.NoError(c.t, json.Unmarshal(w.Body.Bytes(), into))
+	}
+}
+
+func TestAPI_TeamSCIMToken(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	viewer, err := dbtest.GetUserInTeam(t, database, team, users.ViewerRoleID)
+	require.NoError(t, err)
+	path := "/api/users/teams/" + team.ExternalID + "/scim"
+
+	doRequest(t, admin, "GET", path, nil, http.StatusNotFound)
+	doRequest(t, viewer, "POST", path, nil, http.StatusForbidden)
+
+	client := newSCIMClient(t, admin, team)
+	client.do("GET", "/ServiceProviderConfig", nil, http.StatusOK, nil)
+	var view api.TeamSCIMView
+	require.NoError(t, json.Unmarshal(doRequest(t, admin, "GET", path, nil, http.StatusOK), &view))
+	assert.Equal(t, domain+"/api/users/scim/v2/"+team.ExternalID, view.BaseURL)
+	assert.Empty(t, view.Token)
+
+	// Tokens only give access to their own team
+	_, _, other := dbtest.GetOrgAndTeam(t, database)
+	scimClient{t: t, baseURL: "/api/users/scim/v2/" + other.ExternalID, token: client.token}.do("GET", "/Users", nil, http.StatusUnauthorized, nil)
+	scimClient{t: t, baseURL: client.baseURL, token: "nope"}.do("GET", "/Users", nil, http.StatusUnauthorized, nil)
+
+	// Creating another token replaces the first
+	replacement := newSCIMClient(t, admin, team)
+	client.do("GET", "/Users", nil, http.StatusUnauthorized, nil)
+	replacement.do("GET", "/Users", nil, http.StatusOK, nil)
+
+	doRequest(t, admin, "DELETE", path, nil, http.StatusNoContent)
+	replacement.do("GET", "/Users", nil, http.StatusUnauthorized, nil)
+}
+
+func TestAPI_SCIMUsers(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	client := newSCIMClient(t, admin, team)
+	ctx := context.Background()
+
+	// Provisioning creates accounts for new users
+	var alice api.SCIMUser
+	client.do("POST", "/Users", jsonBody{
+		"schemas":  []string{"urn:ietf:params:scim:schemas:core:2.0:User"},
+		"userName": "alice@example.com",
+		"name":     map[string]string{"givenName": "Alice", "familyName": "Smith"},
+		"emails":   []map[string]interface{}{{"value": "alice@example.com", "primary": true}},
+		"active":   true,
+	}, http.StatusCreated, &alice)
+	assert.Equal(t, "alice@example.com", alice.UserName)
+	require.NotNil(t, alice.Active)
+	assert.True(t, *alice.Active)
+	require.Len(t, alice.Groups, 1)
+	assert.Equal(t, users.DefaultRoleID, alice.Groups[0].Value)
+	u, err := database.FindUserByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, u.ID)
+	assert.Equal(t, "Alice", u.FirstName)
+	assert.Equal(t, "Alice Smith", u.Name)
+
+	assert.Len(t, sentEmails, 0)
+
+	// Existing users are invited: they join the team, and are told so
+	bob := getUser(t)
+	client.do("POST", "/Users", jsonBody{"userName": bob.Email}, http.StatusCreated, nil)
+	role, err := database.GetUserRoleInTeam(ctx, bob.ID, team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, users.DefaultRoleID, role.ID)
+	require.Len(t, sentEmails, 1)
+	assert.Equal(t, []string{bob.Email}, sentEmails[0].To)
+	client.do("POST", "/Users", jsonBody{"userName": bob.Email}, http.StatusConflict, nil)
+	client.do("POST", "/Users", jsonBody{"userName": "not an email"}, http.StatusBadRequest, nil)
+
+	var list api.SCIMListResponse
+	client.do("GET", "/Users", nil, http.StatusOK, &list)
+	assert.Equal(t, 3, list.TotalResults)
+	var found struct {
+		TotalResults int
+		Resources    []api.SCIMUser
+	}
+	client.do("GET", `/Users?filter=userName+eq+%22ALICE@example.com%22`, nil, http.StatusOK, &found)
+	require.Equal(t, 1, found.TotalResults)
+	assert.Equal(t, alice.ID, found.Resources[0].ID)
+	client.do("GET", "/Users?startIndex=2&count=1", nil, http.StatusOK, &found)
+	assert.Equal(t, 3, found.TotalResults)
+	assert.Len(t, found.Resources, 1)
+	client.do("GET", `/Users?filter=title+eq+%22x%22`, nil, http.StatusBadRequest, nil)
+
+	// Users who aren't members of the team aren't found, nor added to it
+	outsider := getUser(t)
+	client.do("GET", "/Users/"+outsider.ID, nil, http.StatusNotFound, nil)
+	client.do("PUT", "/Users/"+outsider.ID, jsonBody{"userName": outsider.Email, "active": true}, http.StatusNotFound, nil)
+	client.do("PATCH", "/Users/"+outsider.ID, jsonBody{
+		"Operations": []map[string]interface{}{{"op": "replace", "path": "active", "value": true}},
+	}, http.StatusNotFound, nil)
+	_, err = database.GetUserRoleInTeam(ctx, outsider.ID, team.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+	client.do("GET", "/Users/"+alice.ID, nil, http.StatusOK, nil)
+
+	// Deactivating users removes them from the team, and logs them out
+	r := httptest.NewRequest("GET", "/", nil)
+	cookie, err := sessionStore.Cookie(r, "email", "alice@example.com", alice.ID, "")
+	require.NoError(t, err)
+	var deactivated api.SCIMUser
+	client.do("PATCH", "/Users/"+alice.ID, jsonBody{
+		"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+		"Operations": []map[string]interface{}{{"op": "Replace", "path": "active", "value": "False"}},
+	}, http.StatusOK, &deactivated)
+	require.NotNil(t, deactivated.Active)
+	assert.False(t, *deactivated.Active)
+	_, err = database.GetUserRoleInTeam(ctx, alice.ID, team.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+	w := httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/api/users/user", nil)
+	r.AddCookie(cookie)
+	app.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	client.do("GET", "/Users/"+alice.ID, nil, http.StatusNotFound, nil)
+
+	// ...and reactivating them adds them back
+	client.do("PUT", "/Users/"+alice.ID, jsonBody{"userName": "alice@example.com", "active": true}, http.StatusOK, nil)
+	_, err = database.GetUserRoleInTeam(ctx, alice.ID, team.ID)
+	assert.NoError(t, err)
+
+	client.do("DELETE", "/Users/"+bob.ID, nil, http.StatusNoContent, nil)
+	_, err = database.GetUserRoleInTeam(ctx, bob.ID, team.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+	client.do("DELETE", "/Users/"+bob.ID, nil, http.StatusNotFound, nil)
+
+	// The team's last admin can't be removed
+	client.do("DELETE", "/Users/"+admin.ID, nil, http.StatusForbidden, nil)
+}
+
+func TestAPI_SCIMGroups(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	client := newSCIMClient(t, admin, team)
+	ctx := context.Background()
+	custom := createRole(t, admin, team, map[string]interface{}{"name": "Deployers"})
+	member, err := dbtest.GetUserInTeam(t, database, team, users.ViewerRoleID)
+	require.NoError(t, err)
+	outsider := getUser(t)
+
+	// Groups are the team's roles
+	var list struct {
+		TotalResults int
+		Resources    []api.SCIMGroup
+	}
+	client.do("GET", "/Groups", nil, http.StatusOK, &list)
+	groups := map[string]api.SCIMGroup{}
+	for _, g := range list.Resources {
+		groups[g.ID] = g
+	}
+	require.Contains(t, groups, custom.ID)
+	assert.Equal(t, "Deployers", groups[custom.ID].DisplayName)
+	require.Len(t, groups[users.AdminRoleID].Members, 1)
+	assert.Equal(t, admin.ID, groups[users.AdminRoleID].Members[0].Value)
+	client.do("GET", `/Groups?filter=displayName+eq+%22deployers%22`, nil, http.StatusOK, &list)
+	require.Equal(t, 1, list.TotalResults)
+
+	roleOf := func(u *users.User) string {
+		role, err := database.GetUserRoleInTeam(ctx, u.ID, team.ID)
+		if err != nil {
+			return ""
+		}
+		return role.ID
+	}
+
+	// Users the identity provider doesn't manage can't be added to groups
+	client.do("PATCH", "/Groups/"+custom.ID, jsonBody{
+		"Operations": []map[string]interface{}{{"op": "add", "path": "members", "value": []map[string]string{{"value": outsider.ID}}}},
+	}, http.StatusBadRequest, nil)
+	assert.Equal(t, "", roleOf(outsider))
+
+	// Adding members to a group gives them its role, and adds users the
+	// identity provider deprovisioned back to the team
+	var deprovisioned api.SCIMUser
+	client.do("POST", "/Users", jsonBody{"userName": "carol@example.com"}, http.StatusCreated, &deprovisioned)
+	client.do("DELETE", "/Users/"+deprovisioned.ID, nil, http.StatusNoContent, nil)
+	var group api.SCIMGroup
+	client.do("PATCH", "/Groups/"+custom.ID, jsonBody{
+		"Operations": []map[string]interface{}{{
+			"op":    "add",
+			"path":  "members",
+			"value": []map[string]string{{"value": member.ID}, {"value": deprovisioned.ID}},
+		}},
+	}, http.StatusOK, &group)
+	assert.Len(t, group.Members, 2)
+	assert.Equal(t, custom.ID, roleOf(member))
+	assert.Equal(t, custom.ID, roleOf(&users.User{ID: deprovisioned.ID}))
+
+	// Removing them gives them the default role
+	client.do("PATCH", "/Groups/"+custom.ID, jsonBody{
+		"Operations": []map[string]interface{}{{"op": "remove", "path": `members[value eq "` + member.ID + `"]`}},
+	}, http.StatusOK, &group)
+	assert.Len(t, group.Members, 1)
+	assert.Equal(t, users.DefaultRoleID, roleOf(member))
+
+	client.do("PUT", "/Groups/"+users.AdminRoleID, jsonBody{
+		"displayName": "Admin",
+		"members":     []map[string]string{{"value": admin.ID}, {"value": member.ID}},
+	}, http.StatusOK, &group)
+	assert.Len(t, group.Members, 2)
+	assert.Equal(t, users.AdminRoleID, roleOf(member))
+
+	// Teams must keep an admin
+	client.do("PUT", "/Groups/"+users.AdminRoleID, jsonBody{"members": []map[string]string{}}, http.StatusForbidden, nil)
+	assert.Equal(t, users.AdminRoleID, roleOf(admin))
+
+	client.do("PATCH", "/Groups/"+custom.ID, jsonBody{
+		"Operations": []map[string]interface{}{{"op": "add", "path": "members", "value": []map[string]string{{"value": "nope"}}}},
+	}, http.StatusBadRequest, nil)
+	client.do("GET", "/Groups/nope", nil, http.StatusNotFound, nil)
+}
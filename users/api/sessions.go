@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/sessions"
+)
+
+// SessionView describes one of a user's sessions, i.e. a device they are
+// logged in on.
+type SessionView struct {
+	ID         string    `json:"id"`
+	Provider   string    `json:"provider"`
+	UserAgent  string    `json:"userAgent"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	// Current is set for the session making the request.
+	Current bool `json:"current"`
+}
+
+func (a *API) listSessions(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	persisted, err := a.db.ListSessions(ctx, currentUser.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	current, _ := a.sessions.Get(r)
+	now := time.Now().UTC()
+	views := []SessionView{}
+	for _, s := range persisted {
+		// Admins' impersonations aren't the user's own sessions
+		if s.ImpersonatingUserID != "" || s.Expired(now, sessions.SessionDuration) {
+			continue
+		}
+		views = append(views, SessionView{
+			ID:         s.ID,
+			Provider:   s.Provider,
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			Current:    s.ID == current.ID,
+		})
+	}
+	render.JSON(w, http.StatusOK, views)
+}
+
+func (a *API) revokeSession(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["sessionID"]
+	if err := a.db.RevokeSession(r.Context(), currentUser.ID, sessionID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
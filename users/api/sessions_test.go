@@ -1,13 +1,17 @@
 package api_test
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/api"
 )
 
 func Test_Sessions_EncodeDecode(t *testing.T) {
@@ -16,11 +20,12 @@ func Test_Sessions_EncodeDecode(t *testing.T) {
 
 	user := getUser(t)
 
+	r, _ := http.NewRequest("GET", "/", nil)
 	impersonatingUserID := "" // this test doesn't involve impersonation
-	encoded, err := sessionStore.Encode("google", "1234", user.ID, impersonatingUserID)
+	encoded, err := sessionStore.Encode(r, "google", "1234", user.ID, impersonatingUserID)
 	require.NoError(t, err)
 
-	foundSession, err := sessionStore.Decode(encoded)
+	foundSession, err := sessionStore.Decode(context.Background(), encoded)
 	require.NoError(t, err)
 
 	assert.Equal(t, user.ID, foundSession.UserID)
@@ -37,3 +42,56 @@ func Test_Sessions_Get_NoCookie(t *testing.T) {
 	assert.Equal(t, users.ErrInvalidAuthenticationData, err)
 	assert.Equal(t, "", session.UserID)
 }
+
+func Test_Sessions_ListAndRevoke(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	user := getUser(t)
+	login := func(userAgent string) *http.Cookie {
+		r, _ := http.NewRequest("GET", "/", nil)
+		r.Header.Set("User-Agent", userAgent)
+		r.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2")
+		cookie, err := sessionStore.Cookie(r, "email", user.Email, user.ID, "")
+		require.NoError(t, err)
+		return cookie
+	}
+	request := func(cookie *http.Cookie, method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(method, path, nil)
+		r.AddCookie(cookie)
+		app.ServeHTTP(w, r)
+		return w
+	}
+	laptop, phone := login("laptop"), login("phone")
+
+	w := request(laptop, "GET", "/api/users/sessions")
+	require.Equal(t, http.StatusOK, w.Code)
+	var views []api.SessionView
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &views))
+	require.Len(t, views, 2)
+	byAgent := map[string]api.SessionView{}
+	for _, v := range views {
+		byAgent[v.UserAgent] = v
+		assert.Equal(t, "10.0.0.2", v.IPAddress)
+	}
+	assert.True(t, byAgent["laptop"].Current)
+	assert.False(t, byAgent["phone"].Current)
+
+	// Other users' sessions can't be revoked
+	assert.Equal(t, http.StatusNotFound, request(requestCookie(t, getUser(t)), "DELETE", "/api/users/sessions/"+byAgent["phone"].ID).Code)
+	assert.Equal(t, http.StatusNotFound, request(laptop, "DELETE", "/api/users/sessions/nope").Code)
+
+	// Revoked sessions can no longer be used
+	assert.Equal(t, http.StatusNoContent, request(laptop, "DELETE", "/api/users/sessions/"+byAgent["phone"].ID).Code)
+	assert.Equal(t, http.StatusUnauthorized, request(phone, "GET", "/api/users/user").Code)
+	assert.Equal(t, http.StatusOK, request(laptop, "GET", "/api/users/user").Code)
+
+	// Nor can sessions which have logged out
+	request(laptop, "GET", "/api/users/logout")
+	assert.Equal(t, http.StatusUnauthorized, request(laptop, "GET", "/api/users/user").Code)
+}
+
+func requestCookie(t *testing.T, u *users.User) *http.Cookie {
+	return requestAs(t, u, "GET", "/", nil).Cookies()[0]
+}
@@ -0,0 +1,445 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/common/logging"
+	commonuser "github.com/weaveworks/common/user"
+	"github.com/weaveworks/service/common/permission"
+	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/common/validation"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/events"
+	"github.com/weaveworks/service/users/login"
+	"github.com/weaveworks/service/users/login/saml"
+)
+
+// How long to wait for teams' OIDC identity providers to respond.
+const ssoClientTimeout = 10 * time.Second
+
+// TeamSSOView describes a team's single sign-on. The OIDC client secret is
+// never returned.
+type TeamSSOView struct {
+	Protocol      string `json:"protocol"`
+	OIDCIssuerURL string `json:"oidcIssuerUrl,omitempty"`
+	OIDCClientID  string `json:"oidcClientId,omitempty"`
+	SAMLMetadata  string `json:"samlMetadata,omitempty"`
+	DefaultRoleID string `json:"defaultRoleId"`
+	Enforce       bool   `json:"enforce"`
+
+	// What to configure the identity provider with, and where users go to
+	// log in.
+	LoginURL    string `json:"loginUrl"`
+	CallbackURL string `json:"callbackUrl"`
+	EntityID    string `json:"entityId,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TeamSSOWriteView describes a team's single sign-on being configured
+type TeamSSOWriteView struct {
+	Protocol      string `json:"protocol"`
+	OIDCIssuerURL string `json:"oidcIssuerUrl"`
+	OIDCClientID  string `json:"oidcClientId"`
+	// OIDCClientSecret is kept as it is when left blank.
+	OIDCClientSecret string `json:"oidcClientSecret"`
+	SAMLMetadata     string `json:"samlMetadata"`
+	DefaultRoleID    string `json:"defaultRoleId"`
+	Enforce          bool   `json:"enforce"`
+}
+
+func ssoProviderID(teamExternalID string) string {
+	return users.SSOProviderPrefix + teamExternalID
+}
+
+func (a *API) ssoURL(teamExternalID, endpoint string) string {
+	return fmt.Sprintf("%s/api/users/sso/%s/%s", a.domain, url.PathEscape(teamExternalID), endpoint)
+}
+
+func (a *API) renderTeamSSO(team *users.Team, config *users.TeamSSOConfig) TeamSSOView {
+	view := TeamSSOView{
+		Protocol:      config.Protocol,
+		OIDCIssuerURL: config.OIDCIssuerURL,
+		OIDCClientID:  config.OIDCClientID,
+		SAMLMetadata:  config.SAMLMetadata,
+		DefaultRoleID: config.DefaultRoleID,
+		Enforce:       config.Enforce,
+		LoginURL:      a.ssoURL(team.ExternalID, "login"),
+		CallbackURL:   a.ssoURL(team.ExternalID, "callback"),
+		CreatedAt:     config.CreatedAt,
+		UpdatedAt:     config.UpdatedAt,
+	}
+	if config.Protocol == users.SSOProtocolSAML {
+		view.EntityID = a.ssoURL(team.ExternalID, "metadata")
+	}
+	return view
+}
+
+// newSSOClient returns the client to talk to teams' OIDC identity providers
+// with. Their URLs are configured by teams, so unless allowPrivateNetworks is
+// set, they may only be on public addresses.
+func newSSOClient(allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivateNetworks {
+		dialer.Control = events.PublicOnly
+	}
+	return &http.Client{
+		Timeout: ssoClientTimeout,
+		// No proxy, which would be what the dialer checks.
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	}
+}
+
+// ssoLoginProvider returns the login provider of a team's single sign-on.
+func (a *API) ssoLoginProvider(ctx context.Context, team *users.Team, config *users.TeamSSOConfig) (login.Provider, error) {
+	switch config.Protocol {
+	case users.SSOProtocolOIDC:
+		return login.NewOIDCProvider(ctx, a.ssoClient, config.OIDCIssuerURL, config.OIDCClientID, config.OIDCClientSecret, a.ssoURL(team.ExternalID, "callback"), a.ssoStateSecret)
+	case users.SSOProtocolSAML:
+		return a.samlLoginProvider(team, config)
+	}
+	return nil, fmt.Errorf("unknown single sign-on protocol: %q", config.Protocol)
+}
+
+func (a *API) samlLoginProvider(team *users.Team, config *users.TeamSSOConfig) (*login.SAMLProvider, error) {
+	return login.NewSAMLProvider([]byte(config.SAMLMetadata), a.ssoURL(team.ExternalID, "metadata"), a.ssoURL(team.ExternalID, "callback"), a.ssoStateSecret)
+}
+
+// validateTeamSSO turns what a team's single sign-on is being configured
+// with into its configuration. existing is the current configuration, if
+// any.
+func (a *API) validateTeamSSO(ctx context.Context, team *users.Team, view TeamSSOWriteView, existing *users.TeamSSOConfig) (*users.TeamSSOConfig, error) {
+	config := &users.TeamSSOConfig{
+		TeamID:        team.ID,
+		Protocol:      view.Protocol,
+		DefaultRoleID: view.DefaultRoleID,
+		Enforce:       view.Enforce,
+	}
+	switch view.Protocol {
+	case users.SSOProtocolOIDC:
+		config.OIDCIssuerURL = strings.TrimSpace(view.OIDCIssuerURL)
+		config.OIDCClientID = strings.TrimSpace(view.OIDCClientID)
+		config.OIDCClientSecret = view.OIDCClientSecret
+		if u, err := url.Parse(config.OIDCIssuerURL); err != nil || (u.Scheme != "https" && !(a.ssoAllowHTTP && u.Scheme == "http")) || u.Host == "" {
+			return nil, users.ValidationErrorf("Invalid OIDC issuer URL: %q, it must be https", view.OIDCIssuerURL)
+		}
+		if config.OIDCClientID == "" {
+			return nil, users.ValidationErrorf("OIDC client ID cannot be blank")
+		}
+		if config.OIDCClientSecret == "" && existing != nil && existing.Protocol == users.SSOProtocolOIDC {
+			config.OIDCClientSecret = existing.OIDCClientSecret
+		}
+		if config.OIDCClientSecret == "" {
+			return nil, users.ValidationErrorf("OIDC client secret cannot be blank")
+		}
+	case users.SSOProtocolSAML:
+		config.SAMLMetadata = strings.TrimSpace(view.SAMLMetadata)
+		if _, err := saml.ParseMetadata([]byte(config.SAMLMetadata)); err != nil {
+			return nil, users.ValidationErrorf("Invalid SAML metadata: %v", err)
+		}
+	default:
+		return nil, users.ValidationErrorf("Invalid protocol: %q", view.Protocol)
+	}
+	if config.DefaultRoleID == "" {
+		config.DefaultRoleID = users.DefaultRoleID
+	}
+	if _, err := a.findTeamRole(ctx, team.ID, config.DefaultRoleID); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// teamForSSOManagement finds a team whose single sign-on the current user
+// may manage.
+func (a *API) teamForSSOManagement(currentUser *users.User, r *http.Request) (*users.Team, error) {
+	ctx := r.Context()
+	teamExternalID := mux.Vars(r)["teamExternalID"]
+	team, err := a.userCanAccessTeam(ctx, currentUser, teamExternalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireTeamMemberPermissionTo(ctx, a.db, currentUser.ID, teamExternalID, permission.ManageTeamSSO); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (a *API) getTeamSSO(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	team, err := a.teamForSSOManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	config, err := a.db.GetTeamSSOConfig(r.Context(), team.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, a.renderTeamSSO(team, config))
+}
+
+func (a *API) setTeamSSO(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	team, err := a.teamForSSOManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	defer r.Body.Close()
+	var view TeamSSOWriteView
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		renderError(w, r, users.NewMalformedInputError(err))
+		return
+	}
+	existing, err := a.db.GetTeamSSOConfig(ctx, team.ID)
+	if err == users.ErrNotFound {
+		existing = nil
+	} else if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	config, err := a.validateTeamSSO(ctx, team, view, existing)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	if err := a.db.SetTeamSSOConfig(ctx, config); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	config, err = a.db.GetTeamSSOConfig(ctx, team.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, a.renderTeamSSO(team, config))
+}
+
+func (a *API) deleteTeamSSO(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	team, err := a.teamForSSOManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.db.DeleteTeamSSOConfig(r.Context(), team.ID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findTeamSSO finds a team by its external ID, and its single sign-on.
+func (a *API) findTeamSSO(ctx context.Context, teamExternalID string) (*users.Team, *users.TeamSSOConfig, error) {
+	team, err := a.db.FindTeamByExternalID(ctx, teamExternalID)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := a.db.GetTeamSSOConfig(ctx, team.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return team, config, nil
+}
+
+// ssoMetadata describes us to a team's SAML identity provider.
+func (a *API) ssoMetadata(w http.ResponseWriter, r *http.Request) {
+	team, config, err := a.findTeamSSO(r.Context(), mux.Vars(r)["teamExternalID"])
+	if err == nil && config.Protocol != users.SSOProtocolSAML {
+		err = users.ErrNotFound
+	}
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	provider, err := a.samlLoginProvider(team, config)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.Write(provider.Metadata())
+}
+
+// ssoLogin sends users to their team's identity provider to log in.
+func (a *API) ssoLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	team, config, err := a.findTeamSSO(ctx, mux.Vars(r)["teamExternalID"])
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	provider, err := a.ssoLoginProvider(ctx, team, config)
+	if err != nil {
+		commonuser.LogWith(ctx, logging.Global()).Errorf("Single sign-on of team %s is broken: %v", team.ExternalID, err)
+		renderError(w, r, users.ErrInvalidAuthenticationData)
+		return
+	}
+	http.Redirect(w, r, provider.LoginURL(r, ""), http.StatusFound)
+}
+
+// ssoCallback is where a team's identity provider sends users back to once
+// they have logged in. Users who aren't members of the team yet join it
+// with its default role.
+func (a *API) ssoCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := commonuser.LogWith(ctx, logging.Global())
+	team, config, err := a.findTeamSSO(ctx, mux.Vars(r)["teamExternalID"])
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	provider, err := a.ssoLoginProvider(ctx, team, config)
+	if err != nil {
+		logger.Errorf("Single sign-on of team %s is broken: %v", team.ExternalID, err)
+		renderError(w, r, users.ErrInvalidAuthenticationData)
+		return
+	}
+	claims, authSession, extraState, err := provider.Login(r)
+	if err != nil {
+		logger.Infof("Single sign-on to team %s failed: %v", team.ExternalID, err)
+		renderError(w, r, users.ErrInvalidAuthenticationData)
+		return
+	}
+	if claims.ID == "" || !validation.ValidateEmail(claims.Email) {
+		logger.Errorf("Identity provider of team %s returned an invalid user: %q, %q", team.ExternalID, claims.ID, claims.Email)
+		renderError(w, r, users.ErrInvalidAuthenticationData)
+		return
+	}
+
+	providerID := ssoProviderID(team.ExternalID)
+	u, err := a.findSSOUser(r, team, providerID, claims)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.db.AddLoginToUser(ctx, u.ID, providerID, claims.ID, authSession); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	if _, err := a.db.GetUserRoleInTeam(ctx, u.ID, team.ID); err == users.ErrNotFound {
+		if err := a.db.AddUserToTeam(ctx, u.ID, team.ID, config.DefaultRoleID); err != nil {
+			renderError(w, r, err)
+			return
+		}
+	} else if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	if err := a.UpdateUserAtLogin(ctx, u); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	pending, err := a.startSession(w, r, providerID, claims.ID, u.ID)
+	if err != nil {
+		renderError(w, r, users.ErrInvalidAuthenticationData)
+		return
+	}
+
+	next := extraState["next"]
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		next = "/"
+	}
+	if pending {
+		next = "/login/verify?" + url.Values{"next": {next}}.Encode()
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// findSSOUser finds, or creates, the user who logged in through a team's
+// identity provider. Identity providers can assert any email, so existing
+// users are only matched by email if they prove they own the account by
+// being logged in, which links the identity provider to it.
+func (a *API) findSSOUser(r *http.Request, team *users.Team, providerID string, claims *login.Claims) (*users.User, error) {
+	ctx := r.Context()
+	u, err := a.db.FindUserByLogin(ctx, providerID, claims.ID)
+	if err != users.ErrNotFound {
+		return u, err
+	}
+
+	if session, err := a.sessions.Get(r); err == nil {
+		u, err := a.db.FindUserByID(ctx, session.UserID)
+		if err != nil && err != users.ErrNotFound {
+			return nil, err
+		}
+		if err == nil && strings.EqualFold(u.Email, claims.Email) {
+			return u, nil
+		}
+	}
+
+	u, err = a.db.FindUserByEmail(ctx, claims.Email)
+	switch err {
+	case nil:
+		return nil, &users.SSOLinkRequiredError{TeamExternalID: team.ExternalID, TeamName: team.Name, Email: u.Email}
+	case users.ErrNotFound:
+		givenName, familyName := getNameFromClaims(claims)
+		u, err = a.db.CreateUser(ctx, claims.Email, &users.UserUpdate{
+			Name:      claims.Name,
+			FirstName: givenName,
+			LastName:  familyName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		a.marketingQueues.UserCreated(u.Email, u.FirstName, u.LastName, u.Company, u.CreatedAt, nil)
+		return u, nil
+	default:
+		return nil, err
+	}
+}
+
+// requireSSOLogin checks a user may log in with a provider: members of teams
+// which enforce single sign-on may only log in through their identity
+// provider, once they have linked it to their account. Until then, they may
+// log in some other way, to link it.
+func (a *API) requireSSOLogin(ctx context.Context, u *users.User, providerID string) error {
+	if strings.HasPrefix(providerID, users.SSOProviderPrefix) {
+		return nil
+	}
+	logins, err := a.db.ListLoginsForUserIDs(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	linked := map[string]bool{}
+	for _, l := range logins {
+		linked[l.Provider] = true
+	}
+	teams, err := a.db.ListTeamsForUserID(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	for _, team := range teams {
+		if !linked[ssoProviderID(team.ExternalID)] {
+			continue
+		}
+		config, err := a.db.GetTeamSSOConfig(ctx, team.ID)
+		if err == users.ErrNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if config.Enforce {
+			return &users.SSORequiredError{TeamExternalID: team.ExternalID, TeamName: team.Name}
+		}
+	}
+	return nil
+}
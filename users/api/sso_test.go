@@ -0,0 +1,293 @@
+package api_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/api"
+	"github.com/weaveworks/service/users/client"
+	"github.com/weaveworks/service/users/db/dbtest"
+	"github.com/weaveworks/service/users/login"
+	"github.com/weaveworks/service/users/login/stubidp"
+)
+
+// csrfCookie is the anti-CSRF cookie authfe gives browsers.
+func csrfCookie() *http.Cookie {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return &http.Cookie{Name: "csrf_token", Value: base64.StdEncoding.EncodeToString(b)}
+}
+
+// startStubIdP runs a stub identity provider, which logs users straight in.
+func startStubIdP(t *testing.T, user login.Claims) (*stubidp.IdP, *httptest.Server) {
+	server := httptest.NewUnstartedServer(nil)
+	idp, err := stubidp.New("http://"+server.Listener.Addr().String(), user)
+	require.NoError(t, err)
+	server.Config.Handler = idp.Handler()
+	server.Start()
+	return idp, server
+}
+
+func setTeamSSO(t *testing.T, u *users.User, team *users.Team, body jsonBody) api.TeamSSOView {
+	data := doRequest(t, u, "PUT", "/api/users/teams/"+team.ExternalID+"/sso", body.Reader(t), http.StatusOK)
+	var view api.TeamSSOView
+	require.NoError(t, json.Unmarshal(data, &view))
+	return view
+}
+
+func TestAPI_TeamSSOConfig(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	viewer, err := dbtest.GetUserInTeam(t, database, team, users.ViewerRoleID)
+	require.NoError(t, err)
+	path := "/api/users/teams/" + team.ExternalID + "/sso"
+	idp, err := stubidp.New("https://idp.test", login.Claims{})
+	require.NoError(t, err)
+
+	doRequest(t, admin, "GET", path, nil, http.StatusNotFound)
+
+	// Only members holding team.sso.manage can configure single sign-on
+	doRequest(t, viewer, "PUT", path, jsonBody{"protocol": "saml", "samlMetadata": string(idp.SAMLMetadata())}.Reader(t), http.StatusForbidden)
+
+	// Invalid configurations are rejected
+	for _, body := range []jsonBody{
+		{"protocol": "ldap"},
+		{"protocol": "oidc", "oidcIssuerUrl": "idp.test", "oidcClientId": "id", "oidcClientSecret": "secret"},
+		{"protocol": "oidc", "oidcIssuerUrl": "https://idp.test", "oidcClientSecret": "secret"},
+		{"protocol": "oidc", "oidcIssuerUrl": "https://idp.test", "oidcClientId": "id"},
+		{"protocol": "saml", "samlMetadata": "<EntityDescriptor/>"},
+		{"protocol": "saml", "samlMetadata": string(idp.SAMLMetadata()), "defaultRoleId": "nope"},
+	} {
+		doRequest(t, admin, "PUT", path, body.Reader(t), http.StatusBadRequest)
+	}
+
+	view := setTeamSSO(t, admin, team, jsonBody{
+		"protocol":         "oidc",
+		"oidcIssuerUrl":    "https://idp.test",
+		"oidcClientId":     "id",
+		"oidcClientSecret": "secret",
+	})
+	assert.Equal(t, users.DefaultRoleID, view.DefaultRoleID)
+	assert.Equal(t, domain+"/api/users/sso/"+team.ExternalID+"/callback", view.CallbackURL)
+
+	// The secret is never returned, and is kept when left out
+	body := doRequest(t, admin, "GET", path, nil, http.StatusOK)
+	assert.NotContains(t, string(body), "secret")
+	setTeamSSO(t, admin, team, jsonBody{
+		"protocol":      "oidc",
+		"oidcIssuerUrl": "https://idp.test",
+		"oidcClientId":  "id",
+		"enforce":       true,
+	})
+	config, err := database.GetTeamSSOConfig(context.Background(), team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", config.OIDCClientSecret)
+	assert.True(t, config.Enforce)
+
+	// The default role can't be deleted
+	role := createRole(t, admin, team, map[string]interface{}{"name": "SSO"})
+	setTeamSSO(t, admin, team, jsonBody{"protocol": "saml", "samlMetadata": string(idp.SAMLMetadata()), "defaultRoleId": role.ID})
+	doRequest(t, admin, "DELETE", "/api/users/teams/"+team.ExternalID+"/roles/"+role.ID, nil, http.StatusBadRequest)
+
+	doRequest(t, admin, "DELETE", path, nil, http.StatusNoContent)
+	doRequest(t, admin, "GET", path, nil, http.StatusNotFound)
+}
+
+func TestAPI_TeamSSOLoginOIDC(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	_, server := startStubIdP(t, login.Claims{ID: "alice", Email: "alice@example.com", Name: "Alice Smith"})
+	defer server.Close()
+	setTeamSSO(t, admin, team, jsonBody{
+		"protocol":         "oidc",
+		"oidcIssuerUrl":    server.URL,
+		"oidcClientId":     stubidp.ClientID,
+		"oidcClientSecret": stubidp.ClientSecret,
+		"defaultRoleId":    users.EditorRoleID,
+	})
+	csrf := csrfCookie()
+
+	// We send users to the identity provider...
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/users/sso/"+team.ExternalID+"/login?next=/instances", nil)
+	r.AddCookie(csrf)
+	app.ServeHTTP(w, r)
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	require.True(t, strings.HasPrefix(w.Header().Get("Location"), server.URL))
+
+	// ...which logs them straight in, and sends them back.
+	noRedirects := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noRedirects.Get(w.Header().Get("Location"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/api/users/sso/"+team.ExternalID+"/callback", callback.Path)
+
+	// The callback needs the same csrf cookie
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", callback.RequestURI(), nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", callback.RequestURI(), nil)
+	r.AddCookie(csrf)
+	app.ServeHTTP(w, r)
+	require.Equal(t, http.StatusSeeOther, w.Code, w.Body.String())
+	assert.Equal(t, "/instances", w.Header().Get("Location"))
+	assert.True(t, hasCookie(w, client.AuthCookieName))
+
+	// The user was created, and joined the team with its default role
+	u, err := database.FindUserByLogin(context.Background(), "sso-"+team.ExternalID, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", u.Email)
+	assert.Equal(t, "Alice", u.FirstName)
+	role, err := database.GetUserRoleInTeam(context.Background(), u.ID, team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, users.EditorRoleID, role.ID)
+}
+
+func TestAPI_TeamSSOLoginSAML(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	idp, err := stubidp.New("https://idp.test", login.Claims{ID: "bob@example.com", Email: "bob@example.com"})
+	require.NoError(t, err)
+	view := setTeamSSO(t, admin, team, jsonBody{"protocol": "saml", "samlMetadata": string(idp.SAMLMetadata())})
+
+	// We describe ourselves to the identity provider
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/users/sso/"+team.ExternalID+"/metadata", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), view.CallbackURL)
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/users/sso/"+team.ExternalID+"/login?next=/instances", nil))
+	require.Equal(t, http.StatusFound, w.Code, w.Body.String())
+	location, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://idp.test/saml/sso", location.Scheme+"://"+location.Host+location.Path)
+
+	post := func(response, relayState string) *httptest.ResponseRecorder {
+		return postSAMLResponse(t, nil, team, response, relayState)
+	}
+
+	// Responses for anyone else are refused
+	response, err := idp.SAMLResponse(view.CallbackURL, "https://elsewhere.test", "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, post(response, "").Code)
+
+	response, err = idp.SAMLResponse(view.CallbackURL, view.EntityID, "")
+	require.NoError(t, err)
+	w = post(response, location.Query().Get("RelayState"))
+	require.Equal(t, http.StatusSeeOther, w.Code, w.Body.String())
+	assert.Equal(t, "/instances", w.Header().Get("Location"))
+	assert.True(t, hasCookie(w, client.AuthCookieName))
+
+	u, err := database.FindUserByLogin(context.Background(), "sso-"+team.ExternalID, "bob@example.com")
+	require.NoError(t, err)
+	role, err := database.GetUserRoleInTeam(context.Background(), u.ID, team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, users.DefaultRoleID, role.ID)
+
+	// Identity providers can't log in existing users, be they of other teams...
+	other := getUser(t)
+	idp.SetUser(login.Claims{ID: other.Email, Email: other.Email})
+	response, err = idp.SAMLResponse(view.CallbackURL, view.EntityID, "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, post(response, "").Code)
+
+	// ...or of this one...
+	idp.SetUser(login.Claims{ID: admin.Email, Email: admin.Email})
+	response, err = idp.SAMLResponse(view.CallbackURL, view.EntityID, "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, post(response, "").Code)
+	_, err = database.FindUserByLogin(context.Background(), "sso-"+team.ExternalID, admin.Email)
+	assert.Equal(t, users.ErrNotFound, err)
+
+	// ...unless they are logged in, which links the identity provider to
+	// their account.
+	w = postSAMLResponse(t, admin, team, response, "")
+	require.Equal(t, http.StatusSeeOther, w.Code, w.Body.String())
+	u, err = database.FindUserByLogin(context.Background(), "sso-"+team.ExternalID, admin.Email)
+	require.NoError(t, err)
+	assert.Equal(t, admin.ID, u.ID)
+	assert.Equal(t, http.StatusSeeOther, post(response, "").Code)
+}
+
+// postSAMLResponse sends a SAML response to a team's single sign-on
+// callback, as u if they aren't nil.
+func postSAMLResponse(t *testing.T, u *users.User, team *users.Team, response, relayState string) *httptest.ResponseRecorder {
+	form := url.Values{"SAMLResponse": {response}, "RelayState": {relayState}}
+	var r *http.Request
+	if u != nil {
+		r = requestAs(t, u, "POST", "/api/users/sso/"+team.ExternalID+"/callback", strings.NewReader(form.Encode()))
+	} else {
+		r = httptest.NewRequest("POST", "/api/users/sso/"+team.ExternalID+"/callback", strings.NewReader(form.Encode()))
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
+
+func TestAPI_TeamSSOEnforce(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	idp, err := stubidp.New("https://idp.test", login.Claims{ID: admin.Email, Email: admin.Email})
+	require.NoError(t, err)
+	view := setTeamSSO(t, admin, team, jsonBody{"protocol": "saml", "samlMetadata": string(idp.SAMLMetadata()), "enforce": true})
+
+	// Members of the team who haven't linked its identity provider can log
+	// in some other way, to link it...
+	logins.SetUsers(map[string]login.Claims{
+		"admin": {ID: "admin", Email: admin.Email},
+	})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/users/logins/mock/attach?code=admin&state=state", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	response, err := idp.SAMLResponse(view.CallbackURL, view.EntityID, "")
+	require.NoError(t, err)
+	w = postSAMLResponse(t, admin, team, response, "")
+	require.Equal(t, http.StatusSeeOther, w.Code, w.Body.String())
+
+	// ...after which they can't.
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/users/logins/mock/attach?code=admin&state=state", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.False(t, hasCookie(w, client.AuthCookieName))
+	var body struct {
+		Errors []map[string]interface{} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Errors, 1)
+	assert.Equal(t, team.ExternalID, body.Errors[0]["teamId"])
+
+	// Others can log in some other way.
+	other := getUser(t)
+	logins.SetUsers(map[string]login.Claims{
+		"other": {ID: "other", Email: other.Email},
+	})
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/users/logins/mock/attach?code=other&state=state", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -37,6 +37,7 @@ type RoleView struct {
 	ID          string           `json:"id"`
 	Name        string           `json:"name"`
 	Description string           `json:"description"`
+	Custom      bool             `json:"custom,omitempty"`
 	Permissions []PermissionView `json:"permissions,omitempty"`
 }
 
@@ -162,10 +163,15 @@ func (a *API) updateUserRoleInTeam(currentUser *users.User, w http.ResponseWrite
 		renderError(w, r, err)
 		return
 	}
-	// This query might fail for a couple of reasons:
-	//   1. The user is not part of the team
-	//   2. Role ID is not valid (`admin`, `editor`, `viewer`)
-	//      - this check is done implicitly on the DB level
+	if _, err := a.findTeamRole(ctx, team.ID, update.RoleID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.requireTeamKeepsAdmin(ctx, team.ID, map[string]string{user.ID: update.RoleID}, nil); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	// This query fails if the user is not part of the team
 	err = a.db.UpdateUserRoleInTeam(ctx, user.ID, team.ID, update.RoleID)
 	if err != nil {
 		renderError(w, r, err)
@@ -246,25 +252,16 @@ func (a *API) removeUserFromTeam(currentUser *users.User, w http.ResponseWriter,
 		return
 	}
 
-	role, err := a.db.GetUserRoleInTeam(ctx, user.ID, team.ID)
-	if err != nil {
+	if _, err := a.db.GetUserRoleInTeam(ctx, user.ID, team.ID); err != nil {
 		renderError(w, r, err)
 		return
 	}
 
 	// A team always has to have at least one admin present,
 	// so if the user to be removed is the only admin, deny it.
-	if role.ID == users.AdminRoleID {
-		adminCount := 0
-		for _, m := range members {
-			if m.Role.ID == users.AdminRoleID {
-				adminCount++
-			}
-		}
-		if adminCount == 1 {
-			renderError(w, r, users.ErrForbidden)
-			return
-		}
+	if err := a.requireTeamKeepsAdmin(ctx, team.ID, map[string]string{user.ID: ""}, nil); err != nil {
+		renderError(w, r, err)
+		return
 	}
 
 	// All users should be able to remove themselves from the team regardless of their role,
@@ -325,6 +322,11 @@ func (a *API) inviteUserToTeam(currentUser *users.User, w http.ResponseWriter, r
 		return
 	}
 
+	if _, err := a.findTeamRole(ctx, team.ID, roleID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+
 	invitee, created, err := a.db.InviteUserToTeam(ctx, email, teamExternalID, roleID)
 	if err != nil {
 		renderError(w, r, err)
@@ -0,0 +1,457 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/weaveworks/service/common/permission"
+	"github.com/weaveworks/service/common/render"
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/tokens"
+	"github.com/weaveworks/service/users/totp"
+)
+
+const (
+	// totpIssuer is how authenticator apps label our codes.
+	totpIssuer = "Weave Cloud"
+
+	// Users are locked out for a while after too many wrong codes, so
+	// codes can't be guessed.
+	maxTwoFactorFailures = 5
+	twoFactorLockout     = 15 * time.Minute
+
+	recoveryCodeCount = 10
+	recoveryCodeChars = 10
+)
+
+// recoveryCodeAlphabet leaves out characters which are easily mistaken
+// for each other. It has 32 characters, so random bytes map onto it evenly.
+const recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// TOTPView describes a user's two-factor authentication.
+type TOTPView struct {
+	// Enabled is set once the user has confirmed their enrollment.
+	Enabled bool `json:"enabled"`
+	// The secret, and a URI to show as a QR code, are only returned when
+	// starting to enroll.
+	Secret            string `json:"secret,omitempty"`
+	ProvisioningURI   string `json:"provisioningUri,omitempty"`
+	RecoveryCodesLeft int    `json:"recoveryCodesLeft"`
+}
+
+// RecoveryCodesView holds new recovery codes, which are only ever shown
+// once.
+type RecoveryCodesView struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TwoFactorCodeView is a code from a user's authenticator, or one of their
+// recovery codes.
+type TwoFactorCodeView struct {
+	Code string `json:"code"`
+}
+
+// TeamTwoFactorView describes a team's two-factor authentication policy.
+type TeamTwoFactorView struct {
+	RequireTwoFactor bool `json:"requireTwoFactor"`
+	// Permissions are the sensitive permissions the policy applies to.
+	Permissions []string `json:"permissions"`
+	// MembersWithoutTwoFactor are the emails of members holding any of
+	// Permissions who haven't enabled two-factor authentication.
+	MembersWithoutTwoFactor []string `json:"membersWithoutTwoFactor"`
+}
+
+// startSession logs a user in, unless they have enabled two-factor
+// authentication, in which case the login waits for their code. It
+// returns whether it is waiting.
+func (a *API) startSession(w http.ResponseWriter, r *http.Request, provider, loginID, userID string) (bool, error) {
+	enrollment, err := a.db.GetUserTOTP(r.Context(), userID)
+	if err != nil && err != users.ErrNotFound {
+		return false, err
+	}
+	if enrollment.Confirmed() {
+		return true, a.sessions.SetPending(w, provider, loginID, userID)
+	}
+	return false, a.sessions.Set(w, r, provider, loginID, userID, "")
+}
+
+// verifyLogin finishes a login waiting for the user's second factor.
+func (a *API) verifyLogin(w http.ResponseWriter, r *http.Request) {
+	pending, err := a.sessions.GetPending(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	code, err := decodeTwoFactorCode(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.verifySecondFactor(r.Context(), pending.UserID, code); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.sessions.Set(w, r, pending.Provider, pending.LoginID, pending.UserID, ""); err != nil {
+		renderError(w, r, users.ErrInvalidAuthenticationData)
+		return
+	}
+	a.sessions.ClearPending(w, r)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// verifySecondFactor checks a code from a user's authenticator, or one of
+// their recovery codes. Each code only works once.
+func (a *API) verifySecondFactor(ctx context.Context, userID, code string) error {
+	enrollment, err := a.db.GetUserTOTP(ctx, userID)
+	if err == users.ErrNotFound || (err == nil && !enrollment.Confirmed()) {
+		return users.ErrInvalidAuthenticationData
+	} else if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if enrollment.FailedAttempts >= maxTwoFactorFailures &&
+		enrollment.LastFailedAt != nil && now.Sub(*enrollment.LastFailedAt) < twoFactorLockout {
+		return users.ErrTooManyTwoFactorAttempts
+	}
+
+	ok := false
+	if step, valid := totp.Validate(enrollment.Secret, code, now); valid {
+		ok, err = a.db.UseUserTOTPStep(ctx, userID, step)
+	} else if recovery := normalizeRecoveryCode(code); len(recovery) == recoveryCodeChars {
+		ok, err = a.db.UseUserTOTPRecoveryCode(ctx, userID, tokens.Hash(recovery))
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := a.db.AddUserTOTPFailure(ctx, userID, now.Add(-twoFactorLockout)); err != nil {
+			return err
+		}
+		return users.ValidationErrorf("Invalid two-factor code")
+	}
+	return nil
+}
+
+func decodeTwoFactorCode(r *http.Request) (string, error) {
+	defer r.Body.Close()
+	var view TwoFactorCodeView
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		return "", users.NewMalformedInputError(err)
+	}
+	if strings.TrimSpace(view.Code) == "" {
+		return "", users.ValidationErrorf("Code cannot be blank")
+	}
+	return view.Code, nil
+}
+
+// generateRecoveryCodes returns new recovery codes, formatted like
+// "abcde-fghjk", and their hashes.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeChars)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		for j := range b {
+			b[j] = recoveryCodeAlphabet[int(b[j])%len(recoveryCodeAlphabet)]
+		}
+		code := string(b)
+		codes[i] = code[:recoveryCodeChars/2] + "-" + code[recoveryCodeChars/2:]
+		hashes[i] = tokens.Hash(code)
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
+
+// requireOwnSession forbids admins impersonating users from changing their
+// second factor.
+func (a *API) requireOwnSession(r *http.Request) error {
+	session, err := a.sessions.Get(r)
+	if err != nil {
+		return err
+	}
+	if session.ImpersonatingUserID != "" {
+		return users.ErrForbidden
+	}
+	return nil
+}
+
+func renderTOTP(enrollment *users.UserTOTP) TOTPView {
+	return TOTPView{
+		Enabled:           enrollment.Confirmed(),
+		RecoveryCodesLeft: len(enrollment.RecoveryCodeHashes),
+	}
+}
+
+func (a *API) getTOTP(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	enrollment, err := a.db.GetUserTOTP(r.Context(), currentUser.ID)
+	if err == users.ErrNotFound {
+		render.JSON(w, http.StatusOK, TOTPView{})
+		return
+	} else if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, renderTOTP(enrollment))
+}
+
+// enrollTOTP starts enrolling the current user, with a new secret for
+// their authenticator. It has no effect until they confirm it.
+func (a *API) enrollTOTP(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := a.requireOwnSession(r); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	existing, err := a.db.GetUserTOTP(ctx, currentUser.ID)
+	if err != nil && err != users.ErrNotFound {
+		renderError(w, r, err)
+		return
+	}
+	if existing.Confirmed() {
+		renderError(w, r, users.ValidationErrorf("Two-factor authentication is already enabled"))
+		return
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.db.SetUserTOTP(ctx, currentUser.ID, secret); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, TOTPView{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, totpIssuer, currentUser.Email),
+	})
+}
+
+// confirmTOTP finishes enrolling the current user, given a code from their
+// authenticator. Their other sessions are logged out, as they were logged
+// in without a second factor.
+func (a *API) confirmTOTP(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := a.requireOwnSession(r); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	code, err := decodeTwoFactorCode(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	enrollment, err := a.db.GetUserTOTP(ctx, currentUser.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if enrollment.Confirmed() {
+		renderError(w, r, users.ValidationErrorf("Two-factor authentication is already enabled"))
+		return
+	}
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now().UTC())
+	if !ok {
+		renderError(w, r, users.ValidationErrorf("Invalid two-factor code"))
+		return
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.db.ConfirmUserTOTP(ctx, currentUser.ID, step, hashes); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.revokeOtherSessions(r, currentUser.ID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, RecoveryCodesView{RecoveryCodes: codes})
+}
+
+func (a *API) revokeOtherSessions(r *http.Request, userID string) error {
+	ctx := r.Context()
+	current, err := a.sessions.Get(r)
+	if err != nil {
+		return err
+	}
+	persisted, err := a.db.ListSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, s := range persisted {
+		if s.ID == current.ID || s.ImpersonatingUserID != "" {
+			continue
+		}
+		if err := a.db.RevokeSession(ctx, userID, s.ID); err != nil && err != users.ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// regenerateRecoveryCodes replaces the current user's recovery codes, given
+// a code from their authenticator.
+func (a *API) regenerateRecoveryCodes(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := a.requireOwnSession(r); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	code, err := decodeTwoFactorCode(r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.verifySecondFactor(ctx, currentUser.ID, code); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if err := a.db.SetUserTOTPRecoveryCodes(ctx, currentUser.ID, hashes); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, RecoveryCodesView{RecoveryCodes: codes})
+}
+
+// disableTOTP turns off the current user's two-factor authentication,
+// given a code from their authenticator or a recovery code. Enrollments
+// which were never confirmed are simply discarded.
+func (a *API) disableTOTP(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := a.requireOwnSession(r); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	enrollment, err := a.db.GetUserTOTP(ctx, currentUser.ID)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	if enrollment.Confirmed() {
+		code, err := decodeTwoFactorCode(r)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+		if err := a.verifySecondFactor(ctx, currentUser.ID, code); err != nil {
+			renderError(w, r, err)
+			return
+		}
+	}
+	if err := a.db.DeleteUserTOTP(ctx, currentUser.ID); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) renderTeamTwoFactor(ctx context.Context, team *users.Team) (TeamTwoFactorView, error) {
+	required, err := a.db.TeamRequiresTwoFactor(ctx, team.ID)
+	if err != nil {
+		return TeamTwoFactorView{}, err
+	}
+	view := TeamTwoFactorView{
+		RequireTwoFactor:        required,
+		Permissions:             permission.RequiringTwoFactor,
+		MembersWithoutTwoFactor: []string{},
+	}
+	members, err := a.db.ListTeamUsersWithRoles(ctx, team.ID)
+	if err != nil {
+		return TeamTwoFactorView{}, err
+	}
+	sensitiveRoles := map[string]bool{}
+	for _, m := range members {
+		sensitive, checked := sensitiveRoles[m.Role.ID]
+		if !checked {
+			permissions, err := a.db.ListPermissionsForRoleID(ctx, m.Role.ID)
+			if err != nil {
+				return TeamTwoFactorView{}, err
+			}
+			for _, p := range permissions {
+				sensitive = sensitive || permission.RequiresTwoFactor(p.ID)
+			}
+			sensitiveRoles[m.Role.ID] = sensitive
+		}
+		if !sensitive {
+			continue
+		}
+		enrollment, err := a.db.GetUserTOTP(ctx, m.User.ID)
+		if err != nil && err != users.ErrNotFound {
+			return TeamTwoFactorView{}, err
+		}
+		if !enrollment.Confirmed() {
+			view.MembersWithoutTwoFactor = append(view.MembersWithoutTwoFactor, m.User.Email)
+		}
+	}
+	return view, nil
+}
+
+func (a *API) getTeamTwoFactor(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	team, err := a.teamForSSOManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	view, err := a.renderTeamTwoFactor(r.Context(), team)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, view)
+}
+
+// setTeamTwoFactor sets whether the team requires members to enable
+// two-factor authentication to use sensitive permissions. Whoever turns it
+// on must have enabled it themselves, so they don't lock themselves out.
+func (a *API) setTeamTwoFactor(currentUser *users.User, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	team, err := a.teamForSSOManagement(currentUser, r)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	defer r.Body.Close()
+	var input TeamTwoFactorView
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		renderError(w, r, users.NewMalformedInputError(err))
+		return
+	}
+	if input.RequireTwoFactor {
+		enrollment, err := a.db.GetUserTOTP(ctx, currentUser.ID)
+		if err != nil && err != users.ErrNotFound {
+			renderError(w, r, err)
+			return
+		}
+		if !enrollment.Confirmed() {
+			renderError(w, r, users.ValidationErrorf("Enable two-factor authentication yourself before requiring it"))
+			return
+		}
+	}
+	if err := a.db.SetTeamRequiresTwoFactor(ctx, team.ID, input.RequireTwoFactor); err != nil {
+		renderError(w, r, err)
+		return
+	}
+	view, err := a.renderTeamTwoFactor(ctx, team)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+	render.JSON(w, http.StatusOK, view)
+}
@@ -0,0 +1,197 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/api"
+	"github.com/weaveworks/service/users/client"
+	"github.com/weaveworks/service/users/db/dbtest"
+	"github.com/weaveworks/service/users/login"
+	"github.com/weaveworks/service/users/sessions"
+	"github.com/weaveworks/service/users/totp"
+)
+
+// totpCode returns a code from an authenticator whose clock is off by skew
+// periods. Codes can only be used once, so tests use codes from different
+// periods, each of which is accepted.
+func totpCode(t *testing.T, secret string, skew int) string {
+	code, err := totp.Code(secret, time.Now().Add(time.Duration(skew)*totp.Period))
+	require.NoError(t, err)
+	return code
+}
+
+// enrollTOTP enables two-factor authentication for a user, confirming it
+// with the code of the previous period. It returns their secret and
+// recovery codes.
+func enrollTOTP(t *testing.T, u *users.User) (string, []string) {
+	var view api.TOTPView
+	require.NoError(t, json.Unmarshal(doRequest(t, u, "POST", "/api/users/user/totp", nil, http.StatusOK), &view))
+	require.NotEmpty(t, view.Secret)
+	var codes api.RecoveryCodesView
+	data := doRequest(t, u, "POST", "/api/users/user/totp/confirm", jsonBody{"code": totpCode(t, view.Secret, -1)}.Reader(t), http.StatusOK)
+	require.NoError(t, json.Unmarshal(data, &codes))
+	return view.Secret, codes.RecoveryCodes
+}
+
+func TestAPI_TOTPEnrollment(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	user := getUser(t)
+	path := "/api/users/user/totp"
+	var view api.TOTPView
+	require.NoError(t, json.Unmarshal(doRequest(t, user, "GET", path, nil, http.StatusOK), &view))
+	assert.False(t, view.Enabled)
+
+	// Enrolling gives the secret to add to an authenticator
+	require.NoError(t, json.Unmarshal(doRequest(t, user, "POST", path, nil, http.StatusOK), &view))
+	assert.False(t, view.Enabled)
+	assert.True(t, strings.HasPrefix(view.ProvisioningURI, "otpauth://totp/"))
+	assert.Contains(t, view.ProvisioningURI, "secret="+view.Secret)
+
+	// It only takes effect once confirmed with a code from it
+	doRequest(t, user, "POST", path+"/confirm", jsonBody{"code": "000000"}.Reader(t), http.StatusBadRequest)
+	other := requestCookie(t, user)
+	var codes api.RecoveryCodesView
+	data := doRequest(t, user, "POST", path+"/confirm", jsonBody{"code": totpCode(t, view.Secret, 0)}.Reader(t), http.StatusOK)
+	require.NoError(t, json.Unmarshal(data, &codes))
+	assert.Len(t, codes.RecoveryCodes, 10)
+	var enabled api.TOTPView
+	require.NoError(t, json.Unmarshal(doRequest(t, user, "GET", path, nil, http.StatusOK), &enabled))
+	assert.True(t, enabled.Enabled)
+	assert.Equal(t, 10, enabled.RecoveryCodesLeft)
+	assert.Empty(t, enabled.Secret)
+	doRequest(t, user, "POST", path, nil, http.StatusBadRequest)
+
+	// Sessions from before are logged out
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/users/user", nil)
+	r.AddCookie(other)
+	app.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Recovery codes can be regenerated, which replaces them
+	var regenerated api.RecoveryCodesView
+	data = doRequest(t, user, "POST", path+"/recovery_codes", jsonBody{"code": totpCode(t, view.Secret, 1)}.Reader(t), http.StatusOK)
+	require.NoError(t, json.Unmarshal(data, &regenerated))
+	assert.Len(t, regenerated.RecoveryCodes, 10)
+	doRequest(t, user, "DELETE", path, jsonBody{"code": codes.RecoveryCodes[0]}.Reader(t), http.StatusBadRequest)
+
+	// Disabling needs a code too
+	doRequest(t, user, "DELETE", path, jsonBody{"code": "000000"}.Reader(t), http.StatusBadRequest)
+	doRequest(t, user, "DELETE", path, jsonBody{"code": strings.ToUpper(regenerated.RecoveryCodes[0])}.Reader(t), http.StatusNoContent)
+	require.NoError(t, json.Unmarshal(doRequest(t, user, "GET", path, nil, http.StatusOK), &view))
+	assert.False(t, view.Enabled)
+}
+
+func TestAPI_TOTPLogin(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	user := getUser(t)
+	secret, recoveryCodes := enrollTOTP(t, user)
+	logins.SetUsers(map[string]login.Claims{
+		"user": {ID: "user", Email: user.Email},
+	})
+	attach := func() *http.Cookie {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/api/users/logins/mock/attach?code=user&state=state", nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"twoFactorRequired":true`)
+		assert.False(t, hasCookie(w, client.AuthCookieName))
+		for _, c := range w.Result().Cookies() {
+			if c.Name == sessions.PendingLoginCookieName {
+				return c
+			}
+		}
+		t.Fatal("no pending login cookie")
+		return nil
+	}
+	verify := func(pending *http.Cookie, code string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/users/login/verify", jsonBody{"code": code}.Reader(t))
+		if pending != nil {
+			r.AddCookie(pending)
+		}
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	// Logging in waits for the second factor
+	pending := attach()
+	code := totpCode(t, secret, 0)
+	assert.Equal(t, http.StatusUnauthorized, verify(nil, code).Code)
+	assert.Equal(t, http.StatusBadRequest, verify(pending, "000000").Code)
+	w := verify(pending, code)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.True(t, hasCookie(w, client.AuthCookieName))
+
+	// Codes can't be replayed
+	assert.Equal(t, http.StatusBadRequest, verify(attach(), code).Code)
+
+	// Recovery codes work, once
+	pending = attach()
+	assert.Equal(t, http.StatusNoContent, verify(pending, recoveryCodes[0]).Code)
+	assert.Equal(t, http.StatusBadRequest, verify(attach(), recoveryCodes[0]).Code)
+
+	// Too many wrong codes lock the user out for a while
+	pending = attach()
+	for i := 0; i < 5; i++ {
+		verify(pending, "000000")
+	}
+	assert.Equal(t, http.StatusTooManyRequests, verify(pending, totpCode(t, secret, 1)).Code)
+}
+
+func TestAPI_TeamTwoFactor(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+
+	admin, _, team := dbtest.GetOrgAndTeam(t, database)
+	otherAdmin, err := dbtest.GetUserInTeam(t, database, team, users.AdminRoleID)
+	require.NoError(t, err)
+	viewer, err := dbtest.GetUserInTeam(t, database, team, users.ViewerRoleID)
+	require.NoError(t, err)
+	path := "/api/users/teams/" + team.ExternalID + "/two_factor"
+
+	var view api.TeamTwoFactorView
+	require.NoError(t, json.Unmarshal(doRequest(t, admin, "GET", path, nil, http.StatusOK), &view))
+	assert.False(t, view.RequireTwoFactor)
+	assert.Contains(t, view.Permissions, "scope.host.exec")
+	assert.Contains(t, view.Permissions, "instance.billing.update")
+	// Viewers hold no sensitive permissions
+	assert.Len(t, view.MembersWithoutTwoFactor, 2)
+	assert.Contains(t, view.MembersWithoutTwoFactor, admin.Email)
+	assert.Contains(t, view.MembersWithoutTwoFactor, otherAdmin.Email)
+
+	doRequest(t, viewer, "PUT", path, jsonBody{"requireTwoFactor": true}.Reader(t), http.StatusForbidden)
+	// Admins can't lock themselves out
+	doRequest(t, admin, "PUT", path, jsonBody{"requireTwoFactor": true}.Reader(t), http.StatusBadRequest)
+
+	enrollTOTP(t, admin)
+	require.NoError(t, json.Unmarshal(doRequest(t, admin, "PUT", path, jsonBody{"requireTwoFactor": true}.Reader(t), http.StatusOK), &view))
+	assert.True(t, view.RequireTwoFactor)
+	assert.Equal(t, []string{otherAdmin.Email}, view.MembersWithoutTwoFactor)
+
+	// Members who haven't enabled it are denied sensitive permissions...
+	ctx := context.Background()
+	err = api.RequireTeamMemberPermissionTo(ctx, database, otherAdmin.ID, team.ExternalID, "scope.host.exec")
+	require.IsType(t, &users.TwoFactorRequiredError{}, err)
+	data := doRequest(t, otherAdmin, "GET", path, nil, http.StatusForbidden)
+	assert.Contains(t, string(data), `"permissionId":"team.sso.manage"`)
+	// ...but not others
+	assert.NoError(t, api.RequireTeamMemberPermissionTo(ctx, database, otherAdmin.ID, team.ExternalID, "team.members.view"))
+	assert.NoError(t, api.RequireTeamMemberPermissionTo(ctx, database, admin.ID, team.ExternalID, "scope.host.exec"))
+
+	enrollTOTP(t, otherAdmin)
+	assert.NoError(t, api.RequireTeamMemberPermissionTo(ctx, database, otherAdmin.ID, team.ExternalID, "scope.host.exec"))
+}
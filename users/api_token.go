@@ -0,0 +1,60 @@
+package users
+
+// This file amends the generated struct by protobuf in users.pb.go
+
+import (
+	"time"
+)
+
+// Scopes an API token can be granted.
+const (
+	// APITokenScopeUpload allows uploading data to any service.
+	APITokenScopeUpload = "upload"
+	// APITokenScopeRead allows reading data from any service.
+	APITokenScopeRead = "read"
+	// APITokenScopeFlux allows uploading and reading Flux data only.
+	APITokenScopeFlux = "flux"
+	// APITokenScopeProm allows uploading and reading Prometheus data only.
+	APITokenScopeProm = "prom"
+)
+
+// ValidAPITokenScope returns true if scope is a known API token scope.
+func ValidAPITokenScope(scope string) bool {
+	switch scope {
+	case APITokenScopeUpload, APITokenScopeRead, APITokenScopeFlux, APITokenScopeProm:
+		return true
+	}
+	return false
+}
+
+// Allows returns true if any of the token's scopes permit the action on the
+// given service.
+func (t *APIToken) Allows(action AuthorizedAction, service string) bool {
+	for _, scope := range t.Scopes {
+		switch scope {
+		case APITokenScopeUpload:
+			if action == INSTANCE_DATA_UPLOAD {
+				return true
+			}
+		case APITokenScopeRead:
+			if action == INSTANCE_DATA_ACCESS {
+				return true
+			}
+		case APITokenScopeFlux, APITokenScopeProm:
+			if service == scope && action != OTHER {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Expired returns true if the token has an expiry which has passed.
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// Revoked returns true if the token has been revoked.
+func (t *APIToken) Revoked() bool {
+	return t.RevokedAt != nil
+}
@@ -0,0 +1,89 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditOrganizationDelete             = "organization.delete"
+	AuditOrganizationUpdate             = "organization.update"
+	AuditOrganizationMove               = "organization.move"
+	AuditOrganizationFeatureFlagsUpdate = "organization.feature_flags.update"
+	AuditTeamMemberRoleUpdate           = "team.member.role.update"
+	AuditTeamMemberRemove               = "team.member.remove"
+	AuditTeamRoleCreate                 = "team.role.create"
+	AuditTeamRoleUpdate                 = "team.role.update"
+	AuditTeamRoleDelete                 = "team.role.delete"
+	AuditTeamSSOUpdate                  = "team.sso.update"
+	AuditTeamSSODelete                  = "team.sso.delete"
+	AuditTeamSCIMTokenCreate            = "team.scim_token.create"
+	AuditTeamSCIMTokenDelete            = "team.scim_token.delete"
+	AuditTeamTwoFactorUpdate            = "team.two_factor.update"
+	AuditWebhookCreate                  = "webhook.create"
+	AuditWebhookDelete                  = "webhook.delete"
+	AuditAPITokenCreate                 = "api_token.create"
+	AuditAPITokenRevoke                 = "api_token.revoke"
+	AuditUserImpersonate                = "user.impersonate"
+)
+
+// Types of the targets of audited actions.
+const (
+	AuditTargetOrganization = "organization"
+	AuditTargetUser         = "user"
+	AuditTargetTeam         = "team"
+	AuditTargetRole         = "role"
+	AuditTargetWebhook      = "webhook"
+	AuditTargetAPIToken     = "api_token"
+)
+
+// AuditEntry records an action taken against an organization or a team.
+// Entries are append-only.
+type AuditEntry struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Team-wide actions, such as changing a member's role, only have TeamID,
+	// and are part of the audit log of each organization in the team.
+	OrganizationID string `json:"-"`
+	TeamID         string `json:"-"`
+	// ActorID is the user the action was taken as. When an admin is
+	// impersonating that user, ImpersonatingUserID is the admin.
+	ActorID             string          `json:"actorId,omitempty"`
+	ImpersonatingUserID string          `json:"impersonatingUserId,omitempty"`
+	Action              string          `json:"action"`
+	TargetType          string          `json:"targetType"`
+	TargetID            string          `json:"targetId"`
+	Before              json.RawMessage `json:"before,omitempty"`
+	After               json.RawMessage `json:"after,omitempty"`
+	Metadata            AuditMetadata   `json:"metadata"`
+}
+
+// AuditMetadata describes the request an audited action was made in.
+type AuditMetadata struct {
+	IPAddress string `json:"ipAddress,omitempty"`
+	UserAgent string `json:"userAgent,omitempty"`
+	Method    string `json:"method,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+// AuditActor is who is making a request, for the audit log.
+type AuditActor struct {
+	UserID              string
+	ImpersonatingUserID string
+	Metadata            AuditMetadata
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context recording actions as taken by actor.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFromContext returns the actor set with WithAuditActor, if any.
+func AuditActorFromContext(ctx context.Context) (AuditActor, bool) {
+	actor, ok := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor, ok
+}
@@ -27,6 +27,12 @@ func init() {
 }
 
 // CachingClientConfig control behaviour of the authenticator client.
+//
+// Cookie lookups are cached for OrgCredCacheExpiration, so sessions which
+// have been revoked keep working for up to that long. Likewise, token lookups
+// are cached for ProbeCredCacheExpiration, so revoked API tokens keep working
+// for up to that long: the users service can't evict them from its clients'
+// caches.
 type CachingClientConfig struct {
 	CacheEnabled             bool
 	ProbeCredCacheSize       int
@@ -39,17 +45,21 @@ type CachingClientConfig struct {
 
 type cachingClient struct {
 	users.UsersClient
-	probeCredCache gcache.Cache
-	orgCredCache   gcache.Cache
-	userCache      gcache.Cache
+	probeCredCache           gcache.Cache
+	probeCredCacheExpiration time.Duration
+	orgCredCache             gcache.Cache
+	userCredCache            gcache.Cache
+	userCache                gcache.Cache
 }
 
 func newCachingClient(cfg CachingClientConfig, client users.UsersClient) *cachingClient {
 	return &cachingClient{
-		UsersClient:    client,
-		probeCredCache: gcache.New(cfg.ProbeCredCacheSize).LRU().Expiration(cfg.ProbeCredCacheExpiration).Build(),
-		orgCredCache:   gcache.New(cfg.OrgCredCacheSize).LRU().Expiration(cfg.OrgCredCacheExpiration).Build(),
-		userCache:      gcache.New(cfg.UserCacheSize).LRU().Expiration(cfg.UserCacheExpiration).Build(),
+		UsersClient:              client,
+		probeCredCache:           gcache.New(cfg.ProbeCredCacheSize).LRU().Expiration(cfg.ProbeCredCacheExpiration).Build(),
+		probeCredCacheExpiration: cfg.ProbeCredCacheExpiration,
+		orgCredCache:             gcache.New(cfg.OrgCredCacheSize).LRU().Expiration(cfg.OrgCredCacheExpiration).Build(),
+		userCredCache:            gcache.New(cfg.OrgCredCacheSize).LRU().Expiration(cfg.OrgCredCacheExpiration).Build(),
+		userCache:                gcache.New(cfg.UserCacheSize).LRU().Expiration(cfg.UserCacheExpiration).Build(),
 	}
 }
 
@@ -77,6 +87,25 @@ func (c *cachingClient) LookupOrg(ctx context.Context, in *users.LookupOrgReques
 	return out, err
 }
 
+// LookupUser authenticates a cookie.
+func (c *cachingClient) LookupUser(ctx context.Context, in *users.LookupUserRequest, opts ...grpc.CallOption) (*users.LookupUserResponse, error) {
+	if c.userCredCache == nil {
+		return c.UsersClient.LookupUser(ctx, in, opts...)
+	}
+
+	user, err := c.userCredCache.Get(*in)
+	authCacheCounter.WithLabelValues("user_cred_cache", hitOrMiss(err)).Inc()
+	if err == nil {
+		return user.(cacheValue).out.(*users.LookupUserResponse), user.(cacheValue).err
+	}
+
+	out, err := c.UsersClient.LookupUser(ctx, in, opts...)
+	if err == nil || isErrorCachable(err) {
+		c.userCredCache.Set(*in, cacheValue{out, err})
+	}
+	return out, err
+}
+
 // LookupUsingToken authenticates a token for access to an org.
 func (c *cachingClient) LookupUsingToken(ctx context.Context, in *users.LookupUsingTokenRequest, opts ...grpc.CallOption) (*users.LookupUsingTokenResponse, error) {
 	if c.probeCredCache == nil {
@@ -90,6 +119,15 @@ func (c *cachingClient) LookupUsingToken(ctx context.Context, in *users.LookupUs
 	}
 
 	out, err := c.UsersClient.LookupUsingToken(ctx, in, opts...)
+	if err == nil && out.TokenExpiresAt != nil {
+		// Don't keep accepting API tokens from the cache once they have expired.
+		if expiresIn := time.Until(*out.TokenExpiresAt); expiresIn < c.probeCredCacheExpiration {
+			if expiresIn > 0 {
+				c.probeCredCache.SetWithExpire(*in, cacheValue{out, err}, expiresIn)
+			}
+			return out, err
+		}
+	}
 	if err == nil || isErrorCachable(err) {
 		c.probeCredCache.Set(*in, cacheValue{out, err})
 	}
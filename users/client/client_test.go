@@ -27,6 +27,7 @@ const (
 	orgID         = "somePersistentInternalID"
 	orgToken      = "Scope-Probe token=token123"
 	orgCookie     = "cookie123"
+	expiringToken = "expiring123"
 	userID        = "user12346"
 	userEmail     = "user@example.org"
 )
@@ -40,10 +41,11 @@ type dummyServer struct {
 	users.UsersServer
 	grpcServer *grpc.Server
 
-	failRequests int32
-	probeLookups int32
-	orgLookups   int32
-	userLookups  int32
+	failRequests  int32
+	probeLookups  int32
+	orgLookups    int32
+	userLookups   int32
+	cookieLookups int32
 }
 
 // LookupOrg authenticates a cookie for access to an org by extenal ID.
@@ -64,6 +66,22 @@ func (d *dummyServer) LookupOrg(ctx context.Context, req *users.LookupOrgRequest
 	}, nil
 }
 
+// LookupUser authenticates a cookie.
+func (d *dummyServer) LookupUser(ctx context.Context, req *users.LookupUserRequest) (*users.LookupUserResponse, error) {
+	atomic.AddInt32(&d.cookieLookups, 1)
+	if atomic.LoadInt32(&d.failRequests) != 0 {
+		return nil, errors.New("fake error")
+	}
+
+	if orgCookie != req.Cookie {
+		return nil, users.ErrInvalidAuthenticationData
+	}
+
+	return &users.LookupUserResponse{
+		UserID: userID,
+	}, nil
+}
+
 // LookupUsingToken authenticates a token for access to an org.
 func (d *dummyServer) LookupUsingToken(ctx context.Context, req *users.LookupUsingTokenRequest) (*users.LookupUsingTokenResponse, error) {
 	atomic.AddInt32(&d.probeLookups, 1)
@@ -71,6 +89,14 @@ func (d *dummyServer) LookupUsingToken(ctx context.Context, req *users.LookupUsi
 		return nil, errors.New("fake error")
 	}
 
+	if req.Token == expiringToken {
+		expiresAt := time.Now().Add(100 * time.Millisecond)
+		return &users.LookupUsingTokenResponse{
+			OrganizationID: orgID,
+			TokenExpiresAt: &expiresAt,
+		}, nil
+	}
+
 	if orgToken != req.Token {
 		return nil, users.ErrInvalidAuthenticationData
 	}
@@ -238,6 +264,86 @@ func TestAuthCache(t *testing.T) {
 	}
 }
 
+func TestAuthCacheCookies(t *testing.T) {
+	server, err := newDummyServer()
+	require.NoError(t, err)
+	defer server.Close()
+	auth, err := New("grpc", server.URL, CachingClientConfig{
+		CacheEnabled:           true,
+		ProbeCredCacheSize:     1,
+		OrgCredCacheSize:       2,
+		UserCacheSize:          1,
+		OrgCredCacheExpiration: 100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	lookup := func(cookie string) (*users.LookupUserResponse, error) {
+		return auth.LookupUser(context.Background(), &users.LookupUserRequest{
+			Cookie: cookie,
+		})
+	}
+
+	response, err := lookup(orgCookie)
+	require.NoError(t, err)
+	assert.Equal(t, userID, response.UserID)
+	_, err = lookup("Not the right cookie")
+	assert.Error(t, err)
+	_, err = lookup(orgCookie)
+	require.NoError(t, err)
+	_, err = lookup("Not the right cookie")
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.cookieLookups), "Unexpected number of cookie lookups")
+
+	// Once the cache expires, revoked sessions are noticed
+	time.Sleep(150 * time.Millisecond)
+	_, err = lookup(orgCookie)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&server.cookieLookups), "Unexpected number of cookie lookups")
+}
+
+func TestAuthCacheTokenExpiry(t *testing.T) {
+	server, err := newDummyServer()
+	require.NoError(t, err)
+	defer server.Close()
+	auth, err := New("grpc", server.URL, CachingClientConfig{
+		CacheEnabled:             true,
+		ProbeCredCacheSize:       1,
+		OrgCredCacheSize:         1,
+		UserCacheSize:            1,
+		ProbeCredCacheExpiration: time.Minute,
+	})
+	require.NoError(t, err)
+
+	lookup := func() {
+		response, err := auth.LookupUsingToken(context.Background(), &users.LookupUsingTokenRequest{
+			Token: expiringToken,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, orgID, response.OrganizationID)
+	}
+
+	// Cached until the token expires, rather than for the usual expiration
+	lookup()
+	lookup()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.probeLookups), "Unexpected number of probe lookups")
+	time.Sleep(150 * time.Millisecond)
+	lookup()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.probeLookups), "Unexpected number of probe lookups")
+}
+
+func TestTokenService(t *testing.T) {
+	for path, service := range map[string]string{
+		"/api/prom/push":         "prom",
+		"/api/flux/v6/whoami":    "flux",
+		"/api/report":            "report",
+		"/api/net/peer/foo":      "net",
+		"/api":                   "",
+		"/prom/alertmanager/api": "prom",
+	} {
+		assert.Equal(t, service, tokenService(path), path)
+	}
+}
+
 func TestMiddleware(t *testing.T) {
 	server, err := newDummyServer()
 	require.NoError(t, err)
@@ -129,6 +129,7 @@ func (a AuthProbeMiddleware) Wrap(next http.Handler) http.Handler {
 		response, err := a.UsersClient.LookupUsingToken(ctx, &users.LookupUsingTokenRequest{
 			Token:        token,
 			AuthorizeFor: a.AuthorizeFor,
+			Service:      tokenService(r.URL.Path),
 		})
 		if err != nil {
 			handleError(err, w, r)
@@ -152,6 +153,16 @@ func (a AuthProbeMiddleware) Wrap(next http.Handler) http.Handler {
 	})
 }
 
+// tokenService returns the service a probe request is for, which is the
+// first path element after /api, e.g. "prom" for /api/prom/push.
+func tokenService(path string) string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "/api"), "/")
+	if i := strings.Index(path, "/"); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
+
 // AuthAdminMiddleware is a middleware.Interface for authentication probes based on the headers
 type AuthAdminMiddleware struct {
 	UsersClient users.UsersClient
@@ -0,0 +1,536 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/service/users"
+)
+
+// audited records changes to organizations and teams in the audit log of
+// another database implementation. Failing to write to the audit log is
+// logged, but doesn't fail the change itself.
+type audited struct {
+	DB
+}
+
+// Audited wraps a database so that changes made through it are recorded in
+// its audit log.
+func Audited(d DB) DB {
+	return audited{d}
+}
+
+// The parts of an organization which are recorded in the audit log.
+type auditOrganization struct {
+	Name           string    `json:"name,omitempty"`
+	Platform       string    `json:"platform,omitempty"`
+	Environment    string    `json:"environment,omitempty"`
+	TrialExpiresAt time.Time `json:"trialExpiresAt,omitempty"`
+	TeamID         string    `json:"teamId,omitempty"`
+}
+
+func newAuditOrganization(org *users.Organization) auditOrganization {
+	return auditOrganization{
+		Name:           org.Name,
+		Platform:       org.Platform,
+		Environment:    org.Environment,
+		TrialExpiresAt: org.TrialExpiresAt,
+		TeamID:         org.TeamExternalID,
+	}
+}
+
+type auditFeatureFlags struct {
+	FeatureFlags []string `json:"featureFlags"`
+}
+
+type auditTeamMember struct {
+	RoleID string `json:"roleId,omitempty"`
+}
+
+type auditRole struct {
+	Name          string   `json:"name,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	PermissionIDs []string `json:"permissionIds,omitempty"`
+}
+
+func (a audited) snapshotRole(ctx context.Context, roleID string) (auditRole, error) {
+	role, err := a.DB.FindRoleByID(ctx, roleID)
+	if err != nil {
+		return auditRole{}, err
+	}
+	permissions, err := a.DB.ListPermissionsForRoleID(ctx, roleID)
+	if err != nil {
+		return auditRole{}, err
+	}
+	snapshot := auditRole{Name: role.Name, Description: role.Description}
+	for _, p := range permissions {
+		snapshot.PermissionIDs = append(snapshot.PermissionIDs, p.ID)
+	}
+	sort.Strings(snapshot.PermissionIDs)
+	return snapshot, nil
+}
+
+// The parts of a team's single sign-on which are recorded in the audit log:
+// never its client secret.
+type auditTeamSSO struct {
+	Protocol      string `json:"protocol,omitempty"`
+	OIDCIssuerURL string `json:"oidcIssuerUrl,omitempty"`
+	OIDCClientID  string `json:"oidcClientId,omitempty"`
+	SAMLMetadata  string `json:"samlMetadata,omitempty"`
+	DefaultRoleID string `json:"defaultRoleId,omitempty"`
+	Enforce       bool   `json:"enforce"`
+}
+
+func newAuditTeamSSO(c *users.TeamSSOConfig) auditTeamSSO {
+	return auditTeamSSO{
+		Protocol:      c.Protocol,
+		OIDCIssuerURL: c.OIDCIssuerURL,
+		OIDCClientID:  c.OIDCClientID,
+		SAMLMetadata:  c.SAMLMetadata,
+		DefaultRoleID: c.DefaultRoleID,
+		Enforce:       c.Enforce,
+	}
+}
+
+type auditWebhook struct {
+	IntegrationType string `json:"integrationType"`
+}
+
+type auditAPIToken struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func newAuditAPIToken(t *users.APIToken) auditAPIToken {
+	return auditAPIToken{Name: t.Name, Scopes: t.Scopes, ExpiresAt: t.ExpiresAt}
+}
+
+// diff returns only the fields of before and after which differ. Either of
+// them may be nil, e.g. when something is created or deleted.
+func diff(before, after interface{}) (json.RawMessage, json.RawMessage) {
+	b, a := toFields(before), toFields(after)
+	for k, v := range b {
+		if reflect.DeepEqual(v, a[k]) {
+			delete(b, k)
+			delete(a, k)
+		}
+	}
+	return fromFields(b), fromFields(a)
+}
+
+func toFields(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	fields := map[string]interface{}{}
+	if bs, err := json.Marshal(v); err == nil {
+		json.Unmarshal(bs, &fields)
+	}
+	return fields
+}
+
+func fromFields(fields map[string]interface{}) json.RawMessage {
+	if len(fields) == 0 {
+		return nil
+	}
+	bs, _ := json.Marshal(fields)
+	return bs
+}
+
+// InsertAuditEntry attributes the entry to the actor in the context, unless
+// it already has one.
+func (a audited) InsertAuditEntry(ctx context.Context, entry *users.AuditEntry) error {
+	if actor, ok := users.AuditActorFromContext(ctx); ok {
+		if entry.ActorID == "" {
+			entry.ActorID = actor.UserID
+			entry.ImpersonatingUserID = actor.ImpersonatingUserID
+		}
+		entry.Metadata = actor.Metadata
+	}
+	return a.DB.InsertAuditEntry(ctx, entry)
+}
+
+// record writes an entry to the audit log, attributed to actingID if there is
+// no actor in the context.
+func (a audited) record(ctx context.Context, actingID string, entry users.AuditEntry, before, after interface{}) {
+	entry.Before, entry.After = diff(before, after)
+	if before != nil && after != nil && entry.Before == nil && entry.After == nil {
+		// Nothing changed
+		return
+	}
+	if _, ok := users.AuditActorFromContext(ctx); !ok {
+		entry.ActorID = actingID
+	}
+	if err := a.InsertAuditEntry(ctx, &entry); err != nil {
+		log.Errorf("Failed to record %s of %s %s in the audit log: %v", entry.Action, entry.TargetType, entry.TargetID, err)
+	}
+}
+
+func (a audited) DeleteOrganization(ctx context.Context, externalID string, actingID string) error {
+	org, findErr := a.DB.FindOrganizationByID(ctx, externalID)
+	if err := a.DB.DeleteOrganization(ctx, externalID, actingID); err != nil || findErr != nil {
+		return err
+	}
+	a.record(ctx, actingID, users.AuditEntry{
+		OrganizationID: org.ID,
+		TeamID:         org.TeamID,
+		Action:         users.AuditOrganizationDelete,
+		TargetType:     users.AuditTargetOrganization,
+		TargetID:       org.ExternalID,
+	}, newAuditOrganization(org), nil)
+	return nil
+}
+
+// snapshotOrganization returns an organization as it is before a change. The
+// organization can't be kept instead, as some implementations change it in
+// place.
+func (a audited) snapshotOrganization(ctx context.Context, externalID string) (auditOrganization, error) {
+	org, err := a.DB.FindOrganizationByID(ctx, externalID)
+	if err != nil {
+		return auditOrganization{}, err
+	}
+	return newAuditOrganization(org), nil
+}
+
+func (a audited) UpdateOrganization(ctx context.Context, externalID string, update users.OrgWriteView) (*users.Organization, error) {
+	before, findErr := a.snapshotOrganization(ctx, externalID)
+	org, err := a.DB.UpdateOrganization(ctx, externalID, update)
+	if err != nil || findErr != nil {
+		return org, err
+	}
+	a.record(ctx, "", users.AuditEntry{
+		OrganizationID: org.ID,
+		TeamID:         org.TeamID,
+		Action:         users.AuditOrganizationUpdate,
+		TargetType:     users.AuditTargetOrganization,
+		TargetID:       org.ExternalID,
+	}, before, newAuditOrganization(org))
+	return org, nil
+}
+
+func (a audited) MoveOrganizationToTeam(ctx context.Context, externalID, teamExternalID, teamName, userID string) error {
+	before, findErr := a.snapshotOrganization(ctx, externalID)
+	if err := a.DB.MoveOrganizationToTeam(ctx, externalID, teamExternalID, teamName, userID); err != nil || findErr != nil {
+		return err
+	}
+	after, err := a.DB.FindOrganizationByID(ctx, externalID)
+	if err != nil {
+		log.Errorf("Failed to record move of organization %s in the audit log: %v", externalID, err)
+		return nil
+	}
+	a.record(ctx, userID, users.AuditEntry{
+		OrganizationID: after.ID,
+		TeamID:         after.TeamID,
+		Action:         users.AuditOrganizationMove,
+		TargetType:     users.AuditTargetOrganization,
+		TargetID:       after.ExternalID,
+	}, before, newAuditOrganization(after))
+	return nil
+}
+
+func (a audited) recordFeatureFlags(ctx context.Context, org *users.Organization, before, after []string) {
+	a.record(ctx, "", users.AuditEntry{
+		OrganizationID: org.ID,
+		TeamID:         org.TeamID,
+		Action:         users.AuditOrganizationFeatureFlagsUpdate,
+		TargetType:     users.AuditTargetOrganization,
+		TargetID:       org.ExternalID,
+	}, auditFeatureFlags{before}, auditFeatureFlags{after})
+}
+
+func (a audited) AddFeatureFlag(ctx context.Context, externalID string, featureFlag string) error {
+	org, findErr := a.DB.FindOrganizationByID(ctx, externalID)
+	var before []string
+	if findErr == nil {
+		before = append([]string{}, org.FeatureFlags...)
+	}
+	if err := a.DB.AddFeatureFlag(ctx, externalID, featureFlag); err != nil || findErr != nil {
+		return err
+	}
+	a.recordFeatureFlags(ctx, org, before, append(append([]string{}, before...), featureFlag))
+	return nil
+}
+
+func (a audited) SetFeatureFlags(ctx context.Context, externalID string, featureFlags []string) error {
+	org, findErr := a.DB.FindOrganizationByID(ctx, externalID)
+	var before []string
+	if findErr == nil {
+		before = append([]string{}, org.FeatureFlags...)
+	}
+	if err := a.DB.SetFeatureFlags(ctx, externalID, featureFlags); err != nil || findErr != nil {
+		return err
+	}
+	a.recordFeatureFlags(ctx, org, before, append([]string{}, featureFlags...))
+	return nil
+}
+
+func (a audited) UpdateUserRoleInTeam(ctx context.Context, userID, teamID, roleID string) error {
+	before := auditTeamMember{}
+	if role, err := a.DB.GetUserRoleInTeam(ctx, userID, teamID); err == nil {
+		before.RoleID = role.ID
+	}
+	if err := a.DB.UpdateUserRoleInTeam(ctx, userID, teamID, roleID); err != nil {
+		return err
+	}
+	a.record(ctx, "", users.AuditEntry{
+		TeamID:     teamID,
+		Action:     users.AuditTeamMemberRoleUpdate,
+		TargetType: users.AuditTargetUser,
+		TargetID:   userID,
+	}, before, auditTeamMember{RoleID: roleID})
+	return nil
+}
+
+func (a audited) RemoveUserFromTeam(ctx context.Context, userID, teamID string) error {
+	role, roleErr := a.DB.GetUserRoleInTeam(ctx, userID, teamID)
+	if err := a.DB.RemoveUserFromTeam(ctx, userID, teamID); err != nil || roleErr != nil {
+		// Removing someone who isn't a member is a noop, so isn't recorded.
+		return err
+	}
+	a.record(ctx, "", users.AuditEntry{
+		TeamID:     teamID,
+		Action:     users.AuditTeamMemberRemove,
+		TargetType: users.AuditTargetUser,
+		TargetID:   userID,
+	}, auditTeamMember{RoleID: role.ID}, nil)
+	return nil
+}
+
+func (a audited) CreateTeamRole(ctx context.Context, teamID, name, description string, permissionIDs []string) (*users.Role, error) {
+	role, err := a.DB.CreateTeamRole(ctx, teamID, name, description, permissionIDs)
+	if err != nil {
+		return nil, err
+	}
+	after, _ := a.snapshotRole(ctx, role.ID)
+	a.record(ctx, "", users.AuditEntry{
+		TeamID:     teamID,
+		Action:     users.AuditTeamRoleCreate,
+		TargetType: users.AuditTargetRole,
+		TargetID:   role.ID,
+	}, nil, after)
+	return role, nil
+}
+
+func (a audited) UpdateTeamRole(ctx context.Context, teamID, roleID, name, description string, permissionIDs []string) (*users.Role, error) {
+	before, findErr := a.snapshotRole(ctx, roleID)
+	role, err := a.DB.UpdateTeamRole(ctx, teamID, roleID, name, description, permissionIDs)
+	if err != nil || findErr != nil {
+		return role, err
+	}
+	after, _ := a.snapshotRole(ctx, roleID)
+	a.record(ctx, "", users.AuditEntry{
+		TeamID:     teamID,
+		Action:     users.AuditTeamRoleUpdate,
+		TargetType: users.AuditTargetRole,
+		TargetID:   roleID,
+	}, before, after)
+	return role, nil
+}
+
+func (a audited) DeleteTeamRole(ctx context.Context, teamID, roleID string) error {
+	before, findErr := a.snapshotRole(ctx, roleID)
+	if err := a.DB.DeleteTeamRole(ctx, teamID, roleID); err != nil || findErr != nil {
+		return err
+	}
+	a.record(ctx, "", users.AuditEntry{
+		TeamID:     teamID,
+		Action:     users.AuditTeamRoleDelete,
+		TargetType: users.AuditTargetRole,
+		TargetID:   roleID,
+	}, before, nil)
+	return nil
+}
+
+func (a audited) SetTeamSSOConfig(ctx context.Context, config *users.TeamSSOConfig) error {
+	var before interface{}
+	if existing, err := a.DB.GetTeamSSOConfig(ctx, config.TeamID); err == nil {
+		before = newAuditTeamSSO(existing)
+	} else if err != users.ErrNotFound {
+		return err
+	}
+	if err := a.DB.SetTeamSSOConfig(ctx, config); err != nil {
+		return err
+	}
+	a.record(ctx, "", users.AuditEntry{
+		TeamID:     config.TeamID,
+		Action:     users.AuditTeamSSOUpdate,
+		TargetType: users.AuditTargetTeam,
+		TargetID:   config.TeamID,
+	}, before, newAuditTeamSSO(config))
+	return nil
+}
+
+func (a audited) DeleteTeamSSOConfig(ctx context.Context, teamID string) error {
+	before, findErr := a.DB.GetTeamSSOConfig(ctx, teamID)
+	if err := a.DB.DeleteTeamSSOConfig(ctx, teamID); err != nil || findErr != nil {
+		return err
+	}
+	a.record(ctx, "", users.AuditEntry{
+		TeamID:     teamID,
+		Action:     users.AuditTeamSSODelete,
+		TargetType: users.AuditTargetTeam,
+		TargetID:   teamID,
+	}, newAuditTeamSSO(before), nil)
+	return nil
+}
+
+func (a audited) CreateTeamSCIMToken(ctx context.Context, teamID, createdBy string) (*users.TeamSCIMToken, error) {
+	token, err := a.DB.CreateTeamSCIMToken(ctx, teamID, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	a.record(ctx, createdBy, users.AuditEntry{
+		TeamID:     teamID,
+		Action:     users.AuditTeamSCIMTokenCreate,
+		TargetType: users.AuditTargetTeam,
+		TargetID:   teamID,
+	}, nil, nil)
+	return token, nil
+}
+
+func (a audited) DeleteTeamSCIMToken(ctx context.Context, teamID string) error {
+	if err := a.DB.DeleteTeamSCIMToken(ctx, teamID); err != nil {
+		return err
+	}
+	a.record(ctx, "", users.AuditEntry{
+		TeamID:     teamID,
+		Action:     users.AuditTeamSCIMTokenDelete,
+		TargetType: users.AuditTargetTeam,
+		TargetID:   teamID,
+	}, nil, nil)
+	return nil
+}
+
+type auditTeamTwoFactor struct {
+	RequireTwoFactor bool `json:"requireTwoFactor"`
+}
+
+func (a audited) SetTeamRequiresTwoFactor(ctx context.Context, teamID string, require bool) error {
+	before, findErr := a.DB.TeamRequiresTwoFactor(ctx, teamID)
+	if err := a.DB.SetTeamRequiresTwoFactor(ctx, teamID, require); err != nil || findErr != nil {
+		return err
+	}
+	a.record(ctx, "", users.AuditEntry{
+		TeamID:     teamID,
+		Action:     users.AuditTeamTwoFactorUpdate,
+		TargetType: users.AuditTargetTeam,
+		TargetID:   teamID,
+	}, auditTeamTwoFactor{before}, auditTeamTwoFactor{require})
+	return nil
+}
+
+func (a audited) RemoveUserFromOrganization(ctx context.Context, orgExternalID, email string) error {
+	var (
+		user *users.User
+		role *users.Role
+	)
+	org, findErr := a.DB.FindOrganizationByID(ctx, orgExternalID)
+	if findErr == nil {
+		user, findErr = a.DB.FindUserByEmail(ctx, email)
+	}
+	if findErr == nil {
+		role, findErr = a.DB.GetUserRoleInTeam(ctx, user.ID, org.TeamID)
+	}
+	if err := a.DB.RemoveUserFromOrganization(ctx, orgExternalID, email); err != nil || findErr != nil {
+		return err
+	}
+	a.record(ctx, "", users.AuditEntry{
+		TeamID:     org.TeamID,
+		Action:     users.AuditTeamMemberRemove,
+		TargetType: users.AuditTargetUser,
+		TargetID:   user.ID,
+	}, auditTeamMember{RoleID: role.ID}, nil)
+	return nil
+}
+
+func (a audited) CreateOrganizationWebhook(ctx context.Context, orgExternalID, integrationType string) (*users.Webhook, error) {
+	w, err := a.DB.CreateOrganizationWebhook(ctx, orgExternalID, integrationType)
+	if err != nil {
+		return w, err
+	}
+	org, err := a.DB.FindOrganizationByID(ctx, orgExternalID)
+	if err != nil {
+		log.Errorf("Failed to record creation of webhook %s in the audit log: %v", w.ID, err)
+		return w, nil
+	}
+	a.record(ctx, "", users.AuditEntry{
+		OrganizationID: org.ID,
+		TeamID:         org.TeamID,
+		Action:         users.AuditWebhookCreate,
+		TargetType:     users.AuditTargetWebhook,
+		TargetID:       w.ID,
+	}, nil, auditWebhook{IntegrationType: w.IntegrationType})
+	return w, nil
+}
+
+func (a audited) DeleteOrganizationWebhook(ctx context.Context, orgExternalID, secretID string) error {
+	w, findErr := a.DB.FindOrganizationWebhookBySecretID(ctx, secretID)
+	if err := a.DB.DeleteOrganizationWebhook(ctx, orgExternalID, secretID); err != nil || findErr != nil || w.DeletedAt != nil {
+		return err
+	}
+	org, err := a.DB.FindOrganizationByID(ctx, orgExternalID)
+	if err != nil || org.ID != w.OrganizationID {
+		// The webhook belonged to another organization, so nothing was deleted.
+		return nil
+	}
+	a.record(ctx, "", users.AuditEntry{
+		OrganizationID: org.ID,
+		TeamID:         org.TeamID,
+		Action:         users.AuditWebhookDelete,
+		TargetType:     users.AuditTargetWebhook,
+		TargetID:       w.ID,
+	}, auditWebhook{IntegrationType: w.IntegrationType}, nil)
+	return nil
+}
+
+func (a audited) CreateAPIToken(ctx context.Context, orgExternalID, createdBy, name string, scopes []string, expiresAt *time.Time) (*users.APIToken, error) {
+	t, err := a.DB.CreateAPIToken(ctx, orgExternalID, createdBy, name, scopes, expiresAt)
+	if err != nil {
+		return t, err
+	}
+	org, err := a.DB.FindOrganizationByID(ctx, orgExternalID)
+	if err != nil {
+		log.Errorf("Failed to record creation of API token %s in the audit log: %v", t.ID, err)
+		return t, nil
+	}
+	a.record(ctx, createdBy, users.AuditEntry{
+		OrganizationID: org.ID,
+		TeamID:         org.TeamID,
+		Action:         users.AuditAPITokenCreate,
+		TargetType:     users.AuditTargetAPIToken,
+		TargetID:       t.ID,
+	}, nil, newAuditAPIToken(t))
+	return t, nil
+}
+
+func (a audited) RevokeAPIToken(ctx context.Context, orgExternalID, tokenID string) error {
+	var before *users.APIToken
+	ts, findErr := a.DB.ListAPITokens(ctx, orgExternalID)
+	for _, t := range ts {
+		if t.ID == tokenID {
+			before = t
+		}
+	}
+	if err := a.DB.RevokeAPIToken(ctx, orgExternalID, tokenID); err != nil || findErr != nil || before == nil {
+		return err
+	}
+	org, err := a.DB.FindOrganizationByID(ctx, orgExternalID)
+	if err != nil {
+		log.Errorf("Failed to record revocation of API token %s in the audit log: %v", tokenID, err)
+		return nil
+	}
+	a.record(ctx, "", users.AuditEntry{
+		OrganizationID: org.ID,
+		TeamID:         org.TeamID,
+		Action:         users.AuditAPITokenRevoke,
+		TargetType:     users.AuditTargetAPIToken,
+		TargetID:       tokenID,
+	}, newAuditAPIToken(before), nil)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/weaveworks/service/common"
 	"github.com/weaveworks/service/common/dbconfig"
+	"github.com/weaveworks/service/common/featureflag"
 	"github.com/weaveworks/service/users"
 	"github.com/weaveworks/service/users/db/filter"
 	"github.com/weaveworks/service/users/db/memory"
@@ -129,7 +130,17 @@ type DB interface {
 	ListTeamsForUserID(ctx context.Context, userID string) ([]*users.Team, error)
 	ListTeamUsersWithRoles(ctx context.Context, teamID string) ([]*users.UserWithRole, error)
 	ListTeamUsers(ctx context.Context, teamID string) ([]*users.User, error)
+	// ListRoles lists the built-in roles, which every team has.
 	ListRoles(ctx context.Context) ([]*users.Role, error)
+	// ListTeamRoles lists the built-in roles, and the custom roles of a team.
+	ListTeamRoles(ctx context.Context, teamID string) ([]*users.Role, error)
+	FindRoleByID(ctx context.Context, roleID string) (*users.Role, error)
+	CreateTeamRole(ctx context.Context, teamID, name, description string, permissionIDs []string) (*users.Role, error)
+	// UpdateTeamRole updates a custom role, replacing its permissions. Built-in
+	// roles, and those of other teams, are not found.
+	UpdateTeamRole(ctx context.Context, teamID, roleID, name, description string, permissionIDs []string) (*users.Role, error)
+	DeleteTeamRole(ctx context.Context, teamID, roleID string) error
+	ListPermissions(ctx context.Context) ([]*users.Permission, error)
 	ListPermissionsForRoleID(ctx context.Context, roleID string) ([]*users.Permission, error)
 	GetUserRoleInTeam(ctx context.Context, userID, teamID string) (*users.Role, error)
 	UpdateUserRoleInTeam(ctx context.Context, userID, teamID, roleID string) error
@@ -147,6 +158,110 @@ type DB interface {
 	FindOrganizationWebhookBySecretID(ctx context.Context, secretID string) (*users.Webhook, error)
 	SetOrganizationWebhookFirstSeenAt(ctx context.Context, secretID string) (*time.Time, error)
 
+	// API tokens
+	ListAPITokens(ctx context.Context, orgExternalID string) ([]*users.APIToken, error)
+	// CreateAPIToken creates a new token. The token itself is only set on
+	// the returned APIToken, as only a hash of it is stored.
+	CreateAPIToken(ctx context.Context, orgExternalID, createdBy, name string, scopes []string, expiresAt *time.Time) (*users.APIToken, error)
+	RevokeAPIToken(ctx context.Context, orgExternalID, tokenID string) error
+	// FindAPIToken finds an unrevoked API token, which may have expired.
+	FindAPIToken(ctx context.Context, token string) (*users.APIToken, error)
+	SetAPITokenLastUsedAt(ctx context.Context, tokenID string, lastUsedAt time.Time) error
+
+	// Sessions
+	// CreateSession persists a new session, setting its CreatedAt and LastSeenAt.
+	CreateSession(ctx context.Context, session *users.Session) error
+	// FindSession finds an unrevoked session, which may have expired.
+	FindSession(ctx context.Context, sessionID string) (*users.Session, error)
+	// ListSessions lists the unrevoked sessions of a user, most recently seen first.
+	ListSessions(ctx context.Context, userID string) ([]*users.Session, error)
+	SetSessionLastSeenAt(ctx context.Context, sessionID string, lastSeenAt time.Time) error
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeUserSessions(ctx context.Context, userID string) error
+
+	// Single sign-on
+	// GetTeamSSOConfig returns ErrNotFound if the team has no single sign-on.
+	GetTeamSSOConfig(ctx context.Context, teamID string) (*users.TeamSSOConfig, error)
+	// SetTeamSSOConfig creates or replaces the single sign-on configuration of a team.
+	SetTeamSSOConfig(ctx context.Context, config *users.TeamSSOConfig) error
+	DeleteTeamSSOConfig(ctx context.Context, teamID string) error
+
+	// SCIM provisioning
+	// CreateTeamSCIMToken creates a token for a team's identity provider,
+	// replacing any the team had. The token itself is only set on the
+	// returned TeamSCIMToken, as only a hash of it is stored.
+	CreateTeamSCIMToken(ctx context.Context, teamID, createdBy string) (*users.TeamSCIMToken, error)
+	GetTeamSCIMToken(ctx context.Context, teamID string) (*users.TeamSCIMToken, error)
+	FindTeamSCIMToken(ctx context.Context, token string) (*users.TeamSCIMToken, error)
+	DeleteTeamSCIMToken(ctx context.Context, teamID string) error
+	// AddTeamSCIMUser records that the team's identity provider manages the
+	// user, so that it can find them once they are no longer a member.
+	AddTeamSCIMUser(ctx context.Context, teamID, userID string) error
+	IsTeamSCIMUser(ctx context.Context, teamID, userID string) (bool, error)
+
+	// Two-factor authentication
+	// GetUserTOTP returns ErrNotFound if the user hasn't started enrolling.
+	GetUserTOTP(ctx context.Context, userID string) (*users.UserTOTP, error)
+	// SetUserTOTP starts enrolling a user with a new secret, replacing any
+	// enrollment they had.
+	SetUserTOTP(ctx context.Context, userID, secret string) error
+	// ConfirmUserTOTP finishes enrolling a user, who proved it with the code
+	// of step.
+	ConfirmUserTOTP(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error
+	SetUserTOTPRecoveryCodes(ctx context.Context, userID string, recoveryCodeHashes []string) error
+	// UseUserTOTPStep records a use of the code of step, returning false if
+	// a code of that or a later step was already used.
+	UseUserTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+	// UseUserTOTPRecoveryCode consumes a recovery code, returning false if
+	// the user has no such code.
+	UseUserTOTPRecoveryCode(ctx context.Context, userID, recoveryCodeHash string) (bool, error)
+	// AddUserTOTPFailure counts a failed attempt, restarting the count if
+	// the last failure was before since.
+	AddUserTOTPFailure(ctx context.Context, userID string, since time.Time) error
+	DeleteUserTOTP(ctx context.Context, userID string) error
+	TeamRequiresTwoFactor(ctx context.Context, teamID string) (bool, error)
+	SetTeamRequiresTwoFactor(ctx context.Context, teamID string, require bool) error
+
+	// Event webhooks
+	ListEventWebhooks(ctx context.Context, teamID string) ([]*users.EventWebhook, error)
+	// CreateEventWebhook creates a webhook, setting its ID and CreatedAt.
+	CreateEventWebhook(ctx context.Context, webhook *users.EventWebhook) error
+	FindEventWebhook(ctx context.Context, webhookID string) (*users.EventWebhook, error)
+	// DeleteEventWebhook deletes a team's webhook, and its deliveries.
+	DeleteEventWebhook(ctx context.Context, teamID, webhookID string) error
+	// CreateEventDelivery queues an event for delivery, setting its ID,
+	// State and CreatedAt. It is due straight away unless NextAttemptAt is set.
+	CreateEventDelivery(ctx context.Context, delivery *users.EventDelivery) error
+	// ClaimEventDeliveries returns up to limit pending deliveries which are
+	// due, and leases them so that they aren't claimed again until lease has
+	// passed.
+	ClaimEventDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*users.EventDelivery, error)
+	// UpdateEventDelivery records the outcome of an attempt at a delivery.
+	UpdateEventDelivery(ctx context.Context, delivery *users.EventDelivery) error
+	// ListEventDeliveries lists a webhook's deliveries, newest first.
+	ListEventDeliveries(ctx context.Context, webhookID string, limit int) ([]*users.EventDelivery, error)
+
+	// Feature flag definitions
+	// ListFeatureFlagDefinitions lists the flags decided by rules, by name.
+	ListFeatureFlagDefinitions(ctx context.Context) ([]featureflag.Flag, error)
+	// SetFeatureFlagDefinition creates or replaces the definition of a flag.
+	SetFeatureFlagDefinition(ctx context.Context, flag featureflag.Flag) error
+	DeleteFeatureFlagDefinition(ctx context.Context, name string) error
+
+	// Cluster provider accounts
+	// AddClusterProviderAccount records that a user granted us access to an
+	// account of a cluster provider, e.g. an Azure subscription.
+	AddClusterProviderAccount(ctx context.Context, userID, provider, account string) error
+	// ListClusterProviderAccounts lists the accounts of a provider a user
+	// granted us access to.
+	ListClusterProviderAccounts(ctx context.Context, userID, provider string) ([]string, error)
+
+	// Audit log
+	InsertAuditEntry(ctx context.Context, entry *users.AuditEntry) error
+	// ListAuditEntries lists audit log entries, newest first.
+	// NB: page 0 will return all matches. Use page >= 1 for paginated responses
+	ListAuditEntries(ctx context.Context, f filter.AuditEntry, page uint64) ([]*users.AuditEntry, error)
+
 	// GetSummary exports a summary of the DB.
 	// WARNING: this is a relatively expensive query, and basically exports the entire DB.
 	GetSummary(ctx context.Context) ([]*users.SummaryEntry, error)
@@ -175,5 +290,5 @@ func MustNew(cfg dbconfig.Config) DB {
 	if err != nil {
 		log.Fatal(err)
 	}
-	return traced{timed{d, common.DatabaseRequestDuration}}
+	return EmitEvents(Audited(traced{timed{d, common.DatabaseRequestDuration}}))
 }
@@ -2,19 +2,23 @@ package db_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/weaveworks/service/common/constants/webhooks"
+	"github.com/weaveworks/service/common/featureflag"
 	"github.com/weaveworks/service/common/gcp/procurement"
 	"github.com/weaveworks/service/users"
 	"github.com/weaveworks/service/users/db/dbtest"
 	"github.com/weaveworks/service/users/db/filter"
+	"github.com/weaveworks/service/users/tokens"
 )
 
 func TestDB_RemoveOtherUsersAccess(t *testing.T) {
@@ -428,6 +432,67 @@ func TestDB_SetOrganizationWebhookFirstSeenAt(t *testing.T) {
 	assert.Equal(t, ti, w.FirstSeenAt)
 }
 
+func TestDB_APITokens(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+
+	ctx := context.Background()
+
+	u, err := db.CreateUser(ctx, "joe@email.com", nil)
+	require.NoError(t, err)
+	o, err := db.CreateOrganizationWithTeam(ctx, u.ID, "happy-place-67", "My cool Org", "1234", "", "Some Team", u.TrialExpiresAt())
+	require.NoError(t, err)
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	t1, err := db.CreateAPIToken(ctx, o.ExternalID, u.ID, "prometheus", []string{users.APITokenScopeProm}, &expiresAt)
+	require.NoError(t, err)
+	assert.NotEmpty(t, t1.Token)
+	assert.Equal(t, o.ID, t1.OrganizationID)
+	assert.Equal(t, u.ID, t1.CreatedBy)
+	assert.Equal(t, []string{users.APITokenScopeProm}, t1.Scopes)
+	assert.True(t, expiresAt.Equal(*t1.ExpiresAt))
+	assert.NotZero(t, t1.CreatedAt)
+	assert.Nil(t, t1.LastUsedAt)
+	t2, err := db.CreateAPIToken(ctx, o.ExternalID, u.ID, "uploader", []string{users.APITokenScopeUpload}, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, t1.Token, t2.Token)
+
+	// The token itself is not stored
+	ts, err := db.ListAPITokens(ctx, o.ExternalID)
+	require.NoError(t, err)
+	require.Len(t, ts, 2)
+	assert.Equal(t, []string{t1.ID, t2.ID}, []string{ts[0].ID, ts[1].ID})
+	assert.Empty(t, ts[0].Token)
+	assert.Empty(t, ts[1].Token)
+
+	found, err := db.FindAPIToken(ctx, t1.Token)
+	require.NoError(t, err)
+	assert.Equal(t, t1.ID, found.ID)
+	assert.Empty(t, found.Token)
+	_, err = db.FindAPIToken(ctx, t1.Token+"a")
+	assert.Equal(t, users.ErrNotFound, err)
+
+	lastUsedAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, db.SetAPITokenLastUsedAt(ctx, t1.ID, lastUsedAt))
+	found, err = db.FindAPIToken(ctx, t1.Token)
+	require.NoError(t, err)
+	assert.True(t, lastUsedAt.Equal(*found.LastUsedAt))
+
+	// Revoked tokens can no longer be found
+	require.NoError(t, db.RevokeAPIToken(ctx, o.ExternalID, t1.ID))
+	_, err = db.FindAPIToken(ctx, t1.Token)
+	assert.Equal(t, users.ErrNotFound, err)
+	ts, err = db.ListAPITokens(ctx, o.ExternalID)
+	require.NoError(t, err)
+	require.Len(t, ts, 1)
+	assert.Equal(t, t2.ID, ts[0].ID)
+	assert.Equal(t, users.ErrNotFound, db.RevokeAPIToken(ctx, o.ExternalID, t1.ID))
+
+	// Tokens can only be revoked through their own organization
+	_, other := dbtest.GetOrg(t, db)
+	assert.Equal(t, users.ErrNotFound, db.RevokeAPIToken(ctx, other.ExternalID, t2.ID))
+}
+
 func TestDB_UpdateUser(t *testing.T) {
 	db := dbtest.Setup(t)
 	defer dbtest.Cleanup(t, db)
@@ -457,3 +522,528 @@ func TestDB_UpdateUser(t *testing.T) {
 		assert.Equal(t, "Bane Enterprises", u.Company)
 	}
 }
+
+func TestDB_AuditLog(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+
+	admin := dbtest.GetUser(t, db)
+	user, org, team := dbtest.GetOrgAndTeam(t, db)
+	member, err := dbtest.GetUserInTeam(t, db, team, users.ViewerRoleID)
+	require.NoError(t, err)
+	ctx := users.WithAuditActor(context.Background(), users.AuditActor{
+		UserID:              user.ID,
+		ImpersonatingUserID: admin.ID,
+		Metadata:            users.AuditMetadata{IPAddress: "1.2.3.4", Method: "PUT"},
+	})
+
+	require.NoError(t, db.SetFeatureFlags(ctx, org.ExternalID, []string{"foo"}))
+	require.NoError(t, db.UpdateUserRoleInTeam(ctx, member.ID, team.ID, users.EditorRoleID))
+	// Nothing changes, so nothing is recorded
+	_, err = db.UpdateOrganization(ctx, org.ExternalID, users.OrgWriteView{Name: &org.Name})
+	require.NoError(t, err)
+	// Entries of other organizations aren't listed
+	_, other := dbtest.GetOrg(t, db)
+	require.NoError(t, db.SetFeatureFlags(ctx, other.ExternalID, []string{"bar"}))
+	// Without an actor in the context, the acting user is recorded
+	require.NoError(t, db.DeleteOrganization(context.Background(), org.ExternalID, user.ID))
+
+	entries, err := db.ListAuditEntries(ctx, filter.AuditOrganization{ID: org.ID, TeamID: org.TeamID}, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	deleted := entries[0]
+	assert.Equal(t, users.AuditOrganizationDelete, deleted.Action)
+	assert.Equal(t, user.ID, deleted.ActorID)
+	assert.Empty(t, deleted.ImpersonatingUserID)
+	assert.Equal(t, org.ExternalID, deleted.TargetID)
+	var before map[string]interface{}
+	require.NoError(t, json.Unmarshal(deleted.Before, &before))
+	assert.Equal(t, org.Name, before["name"])
+	assert.Nil(t, deleted.After)
+
+	roleUpdated := entries[1]
+	assert.Equal(t, users.AuditTeamMemberRoleUpdate, roleUpdated.Action)
+	assert.Empty(t, roleUpdated.OrganizationID)
+	assert.Equal(t, team.ID, roleUpdated.TeamID)
+	assert.Equal(t, member.ID, roleUpdated.TargetID)
+	assert.JSONEq(t, `{"roleId": "viewer"}`, string(roleUpdated.Before))
+	assert.JSONEq(t, `{"roleId": "editor"}`, string(roleUpdated.After))
+
+	flagsUpdated := entries[2]
+	assert.Equal(t, users.AuditOrganizationFeatureFlagsUpdate, flagsUpdated.Action)
+	assert.Equal(t, org.ID, flagsUpdated.OrganizationID)
+	assert.Equal(t, user.ID, flagsUpdated.ActorID)
+	assert.Equal(t, admin.ID, flagsUpdated.ImpersonatingUserID)
+	assert.Equal(t, "1.2.3.4", flagsUpdated.Metadata.IPAddress)
+	assert.JSONEq(t, `{"featureFlags": []}`, string(flagsUpdated.Before))
+	assert.JSONEq(t, `{"featureFlags": ["foo"]}`, string(flagsUpdated.After))
+
+	// Filtering and pagination
+	entries, err = db.ListAuditEntries(ctx, filter.ParseAuditQuery("action:team. actor:"+admin.ID), 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, roleUpdated.ID, entries[0].ID)
+	entries, err = db.ListAuditEntries(ctx, filter.All, 2)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDB_TeamRoles(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+	ctx := context.Background()
+
+	_, _, team := dbtest.GetOrgAndTeam(t, db)
+	_, _, other := dbtest.GetOrgAndTeam(t, db)
+
+	role, err := db.CreateTeamRole(ctx, team.ID, "Deployer", "Deploys images", []string{"flux.image.deploy", "team.members.view"})
+	require.NoError(t, err)
+	assert.Equal(t, team.ID, role.TeamID)
+
+	// Custom roles are only listed for their team
+	builtin, err := db.ListRoles(ctx)
+	require.NoError(t, err)
+	assert.Len(t, builtin, 3)
+	roles, err := db.ListTeamRoles(ctx, team.ID)
+	require.NoError(t, err)
+	assert.Len(t, roles, 4)
+	roles, err = db.ListTeamRoles(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Len(t, roles, 3)
+
+	permissions, err := db.ListPermissionsForRoleID(ctx, role.ID)
+	require.NoError(t, err)
+	assert.Len(t, permissions, 2)
+
+	// Roles can only be updated by their team
+	_, err = db.UpdateTeamRole(ctx, other.ID, role.ID, "Hijacked", "", nil)
+	assert.Equal(t, users.ErrNotFound, err)
+	_, err = db.UpdateTeamRole(ctx, team.ID, users.AdminRoleID, "Hijacked", "", nil)
+	assert.Equal(t, users.ErrNotFound, err)
+
+	updated, err := db.UpdateTeamRole(ctx, team.ID, role.ID, "Releaser", "Releases images", []string{"flux.image.deploy"})
+	require.NoError(t, err)
+	assert.Equal(t, "Releaser", updated.Name)
+	found, err := db.FindRoleByID(ctx, role.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Releases images", found.Description)
+	permissions, err = db.ListPermissionsForRoleID(ctx, role.ID)
+	require.NoError(t, err)
+	require.Len(t, permissions, 1)
+	assert.Equal(t, "flux.image.deploy", permissions[0].ID)
+
+	member, err := dbtest.GetUserInTeam(t, db, team, role.ID)
+	require.NoError(t, err)
+	memberRole, err := db.GetUserRoleInTeam(ctx, member.ID, team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, role.ID, memberRole.ID)
+	require.NoError(t, db.UpdateUserRoleInTeam(ctx, member.ID, team.ID, users.ViewerRoleID))
+
+	assert.Equal(t, users.ErrNotFound, db.DeleteTeamRole(ctx, other.ID, role.ID))
+	require.NoError(t, db.DeleteTeamRole(ctx, team.ID, role.ID))
+	_, err = db.FindRoleByID(ctx, role.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+	roles, err = db.ListTeamRoles(ctx, team.ID)
+	require.NoError(t, err)
+	assert.Len(t, roles, 3)
+}
+
+func TestDB_TeamSSOConfig(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+	ctx := context.Background()
+
+	_, _, team := dbtest.GetOrgAndTeam(t, db)
+
+	_, err := db.GetTeamSSOConfig(ctx, team.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+
+	require.NoError(t, db.SetTeamSSOConfig(ctx, &users.TeamSSOConfig{
+		TeamID:           team.ID,
+		Protocol:         users.SSOProtocolOIDC,
+		OIDCIssuerURL:    "https://idp.example.com",
+		OIDCClientID:     "client",
+		OIDCClientSecret: "secret",
+		DefaultRoleID:    users.ViewerRoleID,
+	}))
+	config, err := db.GetTeamSSOConfig(ctx, team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", config.OIDCClientSecret)
+	assert.Equal(t, users.ViewerRoleID, config.DefaultRoleID)
+	assert.False(t, config.Enforce)
+	assert.False(t, config.CreatedAt.IsZero())
+
+	// Setting it again replaces it
+	require.NoError(t, db.SetTeamSSOConfig(ctx, &users.TeamSSOConfig{
+		TeamID:        team.ID,
+		Protocol:      users.SSOProtocolSAML,
+		SAMLMetadata:  "<EntityDescriptor/>",
+		DefaultRoleID: users.EditorRoleID,
+		Enforce:       true,
+	}))
+	config, err = db.GetTeamSSOConfig(ctx, team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, users.SSOProtocolSAML, config.Protocol)
+	assert.Equal(t, "", config.OIDCClientSecret)
+	assert.Equal(t, "<EntityDescriptor/>", config.SAMLMetadata)
+	assert.True(t, config.Enforce)
+
+	// Changes are audited, without the client secret
+	entries, err := db.ListAuditEntries(ctx, filter.And(filter.AuditTeamID(team.ID), filter.AuditAction(users.AuditTeamSSOUpdate)), 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, e := range entries {
+		assert.NotContains(t, string(e.Before)+string(e.After), "secret")
+	}
+
+	require.NoError(t, db.DeleteTeamSSOConfig(ctx, team.ID))
+	_, err = db.GetTeamSSOConfig(ctx, team.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+	assert.Equal(t, users.ErrNotFound, db.DeleteTeamSSOConfig(ctx, team.ID))
+}
+
+func TestDB_Sessions(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+	ctx := context.Background()
+
+	user, _, team := dbtest.GetOrgAndTeam(t, db)
+	create := func(userID string) *users.Session {
+		id, err := tokens.Generate()
+		require.NoError(t, err)
+		session := &users.Session{ID: id, UserID: userID, Provider: "email", LoginID: "login", UserAgent: "curl", IPAddress: "10.0.0.1"}
+		require.NoError(t, db.CreateSession(ctx, session))
+		return session
+	}
+
+	first := create(user.ID)
+	assert.False(t, first.CreatedAt.IsZero())
+	found, err := db.FindSession(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "curl", found.UserAgent)
+	assert.Equal(t, "10.0.0.1", found.IPAddress)
+
+	// Sessions are listed most recently seen first
+	second := create(user.ID)
+	require.NoError(t, db.SetSessionLastSeenAt(ctx, second.ID, time.Now().Add(time.Minute)))
+	sessions, err := db.ListSessions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, second.ID, sessions[0].ID)
+	assert.Equal(t, first.ID, sessions[1].ID)
+
+	// Users can only revoke their own sessions
+	other := dbtest.GetUser(t, db)
+	assert.Equal(t, users.ErrNotFound, db.RevokeSession(ctx, other.ID, first.ID))
+	require.NoError(t, db.RevokeSession(ctx, user.ID, first.ID))
+	assert.Equal(t, users.ErrNotFound, db.RevokeSession(ctx, user.ID, first.ID))
+	_, err = db.FindSession(ctx, first.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+	sessions, err = db.ListSessions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	// Removing users from teams, or deleting them, logs them out everywhere
+	require.NoError(t, db.RemoveUserFromTeam(ctx, user.ID, team.ID))
+	_, err = db.FindSession(ctx, second.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+
+	session := create(other.ID)
+	require.NoError(t, db.DeleteUser(ctx, other.ID, ""))
+	_, err = db.FindSession(ctx, session.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+}
+
+func TestDB_TeamSCIMToken(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+	ctx := context.Background()
+
+	user, _, team := dbtest.GetOrgAndTeam(t, db)
+
+	_, err := db.GetTeamSCIMToken(ctx, team.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+
+	first, err := db.CreateTeamSCIMToken(ctx, team.ID, user.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, first.Token)
+	found, err := db.FindTeamSCIMToken(ctx, first.Token)
+	require.NoError(t, err)
+	assert.Equal(t, team.ID, found.TeamID)
+	assert.Equal(t, user.ID, found.CreatedBy)
+	assert.Empty(t, found.Token)
+
+	// Creating another replaces it
+	second, err := db.CreateTeamSCIMToken(ctx, team.ID, user.ID)
+	require.NoError(t, err)
+	_, err = db.FindTeamSCIMToken(ctx, first.Token)
+	assert.Equal(t, users.ErrNotFound, err)
+	_, err = db.FindTeamSCIMToken(ctx, second.Token)
+	assert.NoError(t, err)
+	_, err = db.GetTeamSCIMToken(ctx, team.ID)
+	assert.NoError(t, err)
+
+	require.NoError(t, db.DeleteTeamSCIMToken(ctx, team.ID))
+	_, err = db.FindTeamSCIMToken(ctx, second.Token)
+	assert.Equal(t, users.ErrNotFound, err)
+	assert.Equal(t, users.ErrNotFound, db.DeleteTeamSCIMToken(ctx, team.ID))
+}
+
+func TestDB_TeamSCIMUsers(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+	ctx := context.Background()
+
+	_, _, team := dbtest.GetOrgAndTeam(t, db)
+	_, _, other := dbtest.GetOrgAndTeam(t, db)
+	user := dbtest.GetUser(t, db)
+
+	managed, err := db.IsTeamSCIMUser(ctx, team.ID, user.ID)
+	require.NoError(t, err)
+	assert.False(t, managed)
+
+	require.NoError(t, db.AddTeamSCIMUser(ctx, team.ID, user.ID))
+	// Adding a user again is a no-op
+	require.NoError(t, db.AddTeamSCIMUser(ctx, team.ID, user.ID))
+	assert.Equal(t, users.ErrNotFound, db.AddTeamSCIMUser(ctx, team.ID, "unknown"))
+
+	managed, err = db.IsTeamSCIMUser(ctx, team.ID, user.ID)
+	require.NoError(t, err)
+	assert.True(t, managed)
+	managed, err = db.IsTeamSCIMUser(ctx, other.ID, user.ID)
+	require.NoError(t, err)
+	assert.False(t, managed)
+}
+
+func TestDB_UserTOTP(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+	ctx := context.Background()
+
+	user := dbtest.GetUser(t, db)
+	_, err := db.GetUserTOTP(ctx, user.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+
+	require.NoError(t, db.SetUserTOTP(ctx, user.ID, "SECRET"))
+	enrollment, err := db.GetUserTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "SECRET", enrollment.Secret)
+	assert.False(t, enrollment.Confirmed())
+
+	require.NoError(t, db.ConfirmUserTOTP(ctx, user.ID, 10, []string{"a", "b"}))
+	enrollment, err = db.GetUserTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, enrollment.Confirmed())
+	assert.Equal(t, []string{"a", "b"}, enrollment.RecoveryCodeHashes)
+
+	// Codes can't be reused
+	ok, err := db.UseUserTOTPStep(ctx, user.ID, 10)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = db.UseUserTOTPStep(ctx, user.ID, 11)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = db.UseUserTOTPRecoveryCode(ctx, user.ID, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = db.UseUserTOTPRecoveryCode(ctx, user.ID, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Failures are counted until they are old enough to be forgotten
+	require.NoError(t, db.AddUserTOTPFailure(ctx, user.ID, time.Now().Add(-time.Hour)))
+	require.NoError(t, db.AddUserTOTPFailure(ctx, user.ID, time.Now().Add(-time.Hour)))
+	enrollment, err = db.GetUserTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, enrollment.FailedAttempts)
+	assert.Equal(t, []string{"b"}, enrollment.RecoveryCodeHashes)
+	require.NoError(t, db.AddUserTOTPFailure(ctx, user.ID, time.Now().Add(time.Hour)))
+	enrollment, err = db.GetUserTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, enrollment.FailedAttempts)
+
+	require.NoError(t, db.SetUserTOTPRecoveryCodes(ctx, user.ID, []string{"c"}))
+	enrollment, err = db.GetUserTOTP(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, enrollment.RecoveryCodeHashes)
+
+	require.NoError(t, db.DeleteUserTOTP(ctx, user.ID))
+	_, err = db.GetUserTOTP(ctx, user.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+	assert.Equal(t, users.ErrNotFound, db.DeleteUserTOTP(ctx, user.ID))
+	_, err = db.UseUserTOTPStep(ctx, user.ID, 12)
+	assert.Equal(t, users.ErrNotFound, err)
+}
+
+func TestDB_TeamRequiresTwoFactor(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+	ctx := context.Background()
+
+	_, _, team := dbtest.GetOrgAndTeam(t, db)
+	required, err := db.TeamRequiresTwoFactor(ctx, team.ID)
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	require.NoError(t, db.SetTeamRequiresTwoFactor(ctx, team.ID, true))
+	required, err = db.TeamRequiresTwoFactor(ctx, team.ID)
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	_, err = db.TeamRequiresTwoFactor(ctx, "nope")
+	assert.Equal(t, users.ErrNotFound, err)
+}
+
+func TestDB_EventWebhooks(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+	ctx := context.Background()
+
+	_, _, team := dbtest.GetOrgAndTeam(t, db)
+	_, _, otherTeam := dbtest.GetOrgAndTeam(t, db)
+	all := &users.EventWebhook{TeamID: team.ID, URL: "https://example.com/all", Secret: "secret"}
+	require.NoError(t, db.CreateEventWebhook(ctx, all))
+	assert.NotEmpty(t, all.ID)
+	members := &users.EventWebhook{TeamID: team.ID, URL: "https://example.com/members", Secret: "secret", EventTypes: []string{users.EventTeamMemberJoined}}
+	require.NoError(t, db.CreateEventWebhook(ctx, members))
+
+	webhooks, err := db.ListEventWebhooks(ctx, team.ID)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+	assert.Equal(t, []string{users.EventTeamMemberJoined}, webhooks[1].EventTypes)
+	webhooks, err = db.ListEventWebhooks(ctx, otherTeam.ID)
+	require.NoError(t, err)
+	assert.Empty(t, webhooks)
+
+	// Changes are queued for the webhooks subscribed to them
+	user := dbtest.GetUser(t, db)
+	require.NoError(t, db.AddUserToTeam(ctx, user.ID, team.ID, users.ViewerRoleID))
+	_, err = dbtest.GetUserInTeam(t, db, otherTeam, users.ViewerRoleID)
+	require.NoError(t, err)
+	for _, webhook := range []*users.EventWebhook{all, members} {
+		deliveries, err := db.ListEventDeliveries(ctx, webhook.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, users.EventTeamMemberJoined, deliveries[0].EventType)
+		assert.Equal(t, users.EventDeliveryPending, deliveries[0].State)
+		var event users.Event
+		require.NoError(t, json.Unmarshal(deliveries[0].Payload, &event))
+		assert.Equal(t, team.ExternalID, event.TeamID)
+		assert.Contains(t, string(event.Data), user.Email)
+	}
+	require.NoError(t, db.RemoveUserFromTeam(ctx, user.ID, team.ID))
+	deliveries, err := db.ListEventDeliveries(ctx, all.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, users.EventTeamMemberLeft, deliveries[0].EventType)
+
+	// Due deliveries are claimed once until their lease expires
+	now := time.Now().UTC()
+	claimed, err := db.ClaimEventDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Len(t, claimed, 3)
+	claimed, err = db.ClaimEventDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+	claimed, err = db.ClaimEventDeliveries(ctx, now.Add(2*time.Minute), time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	delivery := claimed[0]
+	delivery.State = users.EventDeliveryDelivered
+	delivery.Attempts = 1
+	delivery.ResponseStatus = 200
+	delivery.LastAttemptAt = &now
+	delivery.DeliveredAt = &now
+	require.NoError(t, db.UpdateEventDelivery(ctx, delivery))
+	claimed, err = db.ClaimEventDeliveries(ctx, now.Add(4*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Len(t, claimed, 2)
+	for _, c := range claimed {
+		assert.NotEqual(t, delivery.ID, c.ID)
+	}
+
+	// Webhooks can only be deleted by their team, along with their deliveries
+	assert.Equal(t, users.ErrNotFound, db.DeleteEventWebhook(ctx, otherTeam.ID, all.ID))
+	require.NoError(t, db.DeleteEventWebhook(ctx, team.ID, all.ID))
+	_, err = db.FindEventWebhook(ctx, all.ID)
+	assert.Equal(t, users.ErrNotFound, err)
+	deliveries, err = db.ListEventDeliveries(ctx, all.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestDB_FeatureFlagDefinitions(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+	ctx := context.Background()
+
+	flags, err := db.ListFeatureFlagDefinitions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+
+	percentage := uint(25)
+	createdAfter := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	beta := featureflag.Flag{
+		Name: "beta",
+		Rules: []featureflag.Rule{{
+			Platforms:    []string{"kubernetes"},
+			CreatedAfter: &createdAfter,
+			Percentage:   &percentage,
+			Enabled:      true,
+			Value:        "2",
+		}},
+	}
+	require.NoError(t, db.SetFeatureFlagDefinition(ctx, featureflag.Flag{Name: "alpha", Enabled: true}))
+	require.NoError(t, db.SetFeatureFlagDefinition(ctx, beta))
+	flags, err = db.ListFeatureFlagDefinitions(ctx)
+	require.NoError(t, err)
+	require.Len(t, flags, 2)
+	assert.Equal(t, "alpha", flags[0].Name)
+	assert.Equal(t, beta.Rules[0].Platforms, flags[1].Rules[0].Platforms)
+	assert.True(t, createdAfter.Equal(*flags[1].Rules[0].CreatedAfter))
+	assert.Equal(t, percentage, *flags[1].Rules[0].Percentage)
+
+	// Setting a flag again replaces it
+	require.NoError(t, db.SetFeatureFlagDefinition(ctx, featureflag.Flag{Name: "beta", Description: "replaced"}))
+	flags, err = db.ListFeatureFlagDefinitions(ctx)
+	require.NoError(t, err)
+	require.Len(t, flags, 2)
+	assert.Equal(t, "replaced", flags[1].Description)
+	assert.Empty(t, flags[1].Rules)
+
+	require.NoError(t, db.DeleteFeatureFlagDefinition(ctx, "alpha"))
+	assert.Equal(t, users.ErrNotFound, db.DeleteFeatureFlagDefinition(ctx, "alpha"))
+	flags, err = db.ListFeatureFlagDefinitions(ctx)
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, "beta", flags[0].Name)
+}
+
+func TestDB_ClusterProviderAccounts(t *testing.T) {
+	db := dbtest.Setup(t)
+	defer dbtest.Cleanup(t, db)
+	ctx := context.Background()
+	user := dbtest.GetUser(t, db)
+	other := dbtest.GetUser(t, db)
+
+	accounts, err := db.ListClusterProviderAccounts(ctx, user.ID, "aks")
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+
+	require.NoError(t, db.AddClusterProviderAccount(ctx, user.ID, "aks", "tenant/sub2"))
+	require.NoError(t, db.AddClusterProviderAccount(ctx, user.ID, "aks", "tenant/sub1"))
+	// Adding an account again is a no-op
+	require.NoError(t, db.AddClusterProviderAccount(ctx, user.ID, "aks", "tenant/sub1"))
+	require.NoError(t, db.AddClusterProviderAccount(ctx, other.ID, "aks", "tenant/sub3"))
+	assert.Equal(t, users.ErrNotFound, db.AddClusterProviderAccount(ctx, "unknown", "aks", "tenant/sub1"))
+
+	accounts, err = db.ListClusterProviderAccounts(ctx, user.ID, "aks")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tenant/sub1", "tenant/sub2"}, accounts)
+	accounts, err = db.ListClusterProviderAccounts(ctx, user.ID, "eks")
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+}
@@ -36,7 +36,7 @@ func Setup(t *testing.T) db.DB {
 	go func() {
 		done <- pg.Transaction(func(tx postgres.DB) error {
 			// Pass out the tx so we can run the test
-			newDB <- tx
+			newDB <- db.EmitEvents(db.Audited(tx))
 			// Wait for the test to finish
 			return <-done
 		})
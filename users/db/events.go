@@ -0,0 +1,236 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/tokens"
+)
+
+// emitting queues events for teams' event webhooks when instances and team
+// memberships change in another database implementation. Like the audit log,
+// failing to queue an event is logged, but doesn't fail the change itself.
+type emitting struct {
+	DB
+}
+
+// EmitEvents wraps a database so that changes made through it are sent to
+// the event webhooks of the teams they affect.
+func EmitEvents(d DB) DB {
+	return emitting{d}
+}
+
+// The data of instance events.
+type eventInstance struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type eventInstanceMoved struct {
+	eventInstance
+	FromTeamID string `json:"fromTeamId"`
+	ToTeamID   string `json:"toTeamId"`
+}
+
+type eventInstanceRefuseDataUpload struct {
+	eventInstance
+	RefuseDataUpload bool `json:"refuseDataUpload"`
+}
+
+// The data of team membership events.
+type eventTeamMember struct {
+	UserID string `json:"userId"`
+	Email  string `json:"email"`
+	RoleID string `json:"roleId,omitempty"`
+}
+
+func newEventInstance(org *users.Organization) eventInstance {
+	return eventInstance{ID: org.ExternalID, Name: org.Name}
+}
+
+// emit queues an event for each of a team's webhooks subscribed to it.
+func (e emitting) emit(ctx context.Context, teamID, eventType string, data interface{}) {
+	if err := e.queue(ctx, teamID, eventType, data); err != nil {
+		log.Errorf("Failed to queue %s event for team %s: %v", eventType, teamID, err)
+	}
+}
+
+func (e emitting) queue(ctx context.Context, teamID, eventType string, data interface{}) error {
+	webhooks, err := e.DB.ListEventWebhooks(ctx, teamID)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+	team, err := e.DB.FindTeamByInternalID(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	event, err := NewEvent(team.ExternalID, eventType, data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(eventType) {
+			continue
+		}
+		if err := e.DB.CreateEventDelivery(ctx, &users.EventDelivery{
+			WebhookID: webhook.ID,
+			EventID:   event.ID,
+			EventType: eventType,
+			Payload:   payload,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewEvent creates an event for a team, given by its external ID.
+func NewEvent(teamExternalID, eventType string, data interface{}) (*users.Event, error) {
+	id, err := tokens.Generate()
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &users.Event{
+		ID:        id,
+		Type:      eventType,
+		TeamID:    teamExternalID,
+		CreatedAt: time.Now().UTC(),
+		Data:      encoded,
+	}, nil
+}
+
+func (e emitting) CreateOrganizationWithTeam(ctx context.Context, ownerID, externalID, name, token, teamExternalID, teamName string, trialExpiresAt time.Time) (*users.Organization, error) {
+	org, err := e.DB.CreateOrganizationWithTeam(ctx, ownerID, externalID, name, token, teamExternalID, teamName, trialExpiresAt)
+	if err != nil {
+		return org, err
+	}
+	e.emit(ctx, org.TeamID, users.EventInstanceCreated, newEventInstance(org))
+	return org, nil
+}
+
+func (e emitting) CreateOrganizationWithGCP(ctx context.Context, ownerID, externalAccountID string, trialExpiresAt time.Time) (*users.Organization, error) {
+	org, err := e.DB.CreateOrganizationWithGCP(ctx, ownerID, externalAccountID, trialExpiresAt)
+	if err != nil {
+		return org, err
+	}
+	e.emit(ctx, org.TeamID, users.EventInstanceCreated, newEventInstance(org))
+	return org, nil
+}
+
+func (e emitting) DeleteOrganization(ctx context.Context, externalID string, actingID string) error {
+	org, findErr := e.DB.FindOrganizationByID(ctx, externalID)
+	if err := e.DB.DeleteOrganization(ctx, externalID, actingID); err != nil || findErr != nil {
+		return err
+	}
+	e.emit(ctx, org.TeamID, users.EventInstanceDeleted, newEventInstance(org))
+	return nil
+}
+
+func (e emitting) MoveOrganizationToTeam(ctx context.Context, externalID, teamExternalID, teamName, userID string) error {
+	before, findErr := e.DB.FindOrganizationByID(ctx, externalID)
+	if err := e.DB.MoveOrganizationToTeam(ctx, externalID, teamExternalID, teamName, userID); err != nil || findErr != nil {
+		return err
+	}
+	after, err := e.DB.FindOrganizationByID(ctx, externalID)
+	if err != nil {
+		log.Errorf("Failed to queue %s event for organization %s: %v", users.EventInstanceMoved, externalID, err)
+		return nil
+	}
+	if before.TeamID == after.TeamID {
+		return nil
+	}
+	// Both teams are told, as the instance leaves one and joins the other
+	data := eventInstanceMoved{
+		eventInstance: newEventInstance(after),
+		FromTeamID:    before.TeamExternalID,
+		ToTeamID:      after.TeamExternalID,
+	}
+	e.emit(ctx, before.TeamID, users.EventInstanceMoved, data)
+	e.emit(ctx, after.TeamID, users.EventInstanceMoved, data)
+	return nil
+}
+
+func (e emitting) SetOrganizationRefuseDataUpload(ctx context.Context, externalID string, value bool) error {
+	before, findErr := e.DB.FindOrganizationByID(ctx, externalID)
+	if err := e.DB.SetOrganizationRefuseDataUpload(ctx, externalID, value); err != nil || findErr != nil {
+		return err
+	}
+	if before.RefuseDataUpload == value {
+		return nil
+	}
+	e.emit(ctx, before.TeamID, users.EventInstanceRefuseDataUploadUpdated, eventInstanceRefuseDataUpload{
+		eventInstance:    newEventInstance(before),
+		RefuseDataUpload: value,
+	})
+	return nil
+}
+
+// isTeamMember tells whether a user is a member of a team, for sending events
+// only when they join.
+func (e emitting) isTeamMember(ctx context.Context, userID, teamID string) bool {
+	_, err := e.DB.GetUserRoleInTeam(ctx, userID, teamID)
+	return err == nil
+}
+
+func (e emitting) emitMemberJoined(ctx context.Context, u *users.User, teamID, roleID string) {
+	e.emit(ctx, teamID, users.EventTeamMemberJoined, eventTeamMember{UserID: u.ID, Email: u.Email, RoleID: roleID})
+}
+
+func (e emitting) AddUserToTeam(ctx context.Context, userID, teamID, roleID string) error {
+	member := e.isTeamMember(ctx, userID, teamID)
+	if err := e.DB.AddUserToTeam(ctx, userID, teamID, roleID); err != nil || member {
+		return err
+	}
+	u, err := e.DB.FindUserByID(ctx, userID)
+	if err != nil {
+		log.Errorf("Failed to queue %s event for team %s: %v", users.EventTeamMemberJoined, teamID, err)
+		return nil
+	}
+	e.emitMemberJoined(ctx, u, teamID, roleID)
+	return nil
+}
+
+func (e emitting) InviteUserToTeam(ctx context.Context, email, teamExternalID, roleID string) (*users.User, bool, error) {
+	member := false
+	if u, err := e.DB.FindUserByEmail(ctx, email); err == nil {
+		if team, err := e.DB.FindTeamByExternalID(ctx, teamExternalID); err == nil {
+			member = e.isTeamMember(ctx, u.ID, team.ID)
+		}
+	}
+	u, created, err := e.DB.InviteUserToTeam(ctx, email, teamExternalID, roleID)
+	if err != nil || member {
+		return u, created, err
+	}
+	team, err := e.DB.FindTeamByExternalID(ctx, teamExternalID)
+	if err != nil {
+		log.Errorf("Failed to queue %s event for team %s: %v", users.EventTeamMemberJoined, teamExternalID, err)
+		return u, created, nil
+	}
+	e.emitMemberJoined(ctx, u, team.ID, roleID)
+	return u, created, nil
+}
+
+func (e emitting) RemoveUserFromTeam(ctx context.Context, userID, teamID string) error {
+	member := e.isTeamMember(ctx, userID, teamID)
+	if err := e.DB.RemoveUserFromTeam(ctx, userID, teamID); err != nil || !member {
+		return err
+	}
+	data := eventTeamMember{UserID: userID}
+	if u, err := e.DB.FindUserByID(ctx, userID); err == nil {
+		data.Email = u.Email
+	}
+	e.emit(ctx, teamID, users.EventTeamMemberLeft, data)
+	return nil
+}
@@ -0,0 +1,105 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/weaveworks/service/users"
+)
+
+// AuditEntry filters audit log entries.
+type AuditEntry interface {
+	Filter
+	// MatchesAuditEntry checks whether an audit log entry matches this filter.
+	MatchesAuditEntry(users.AuditEntry) bool
+}
+
+// AuditOrganization finds the entries of an organization, as well as the
+// team-wide entries of the team it belongs to.
+type AuditOrganization struct {
+	ID     string
+	TeamID string
+}
+
+// Where implements AuditEntry.
+func (o AuditOrganization) Where() squirrel.Sqlizer {
+	return squirrel.Or{
+		squirrel.Eq{"audit_log.organization_id": o.ID},
+		squirrel.And{
+			squirrel.Eq{"audit_log.organization_id": nil},
+			squirrel.Eq{"audit_log.team_id": o.TeamID},
+		},
+	}
+}
+
+// MatchesAuditEntry implements AuditEntry.
+func (o AuditOrganization) MatchesAuditEntry(e users.AuditEntry) bool {
+	if e.OrganizationID != "" {
+		return e.OrganizationID == o.ID
+	}
+	return o.TeamID != "" && e.TeamID == o.TeamID
+}
+
+// AuditOrganizationID finds the entries of exactly this organization.
+type AuditOrganizationID string
+
+// Where implements AuditEntry.
+func (o AuditOrganizationID) Where() squirrel.Sqlizer {
+	return squirrel.Eq{"audit_log.organization_id": string(o)}
+}
+
+// MatchesAuditEntry implements AuditEntry.
+func (o AuditOrganizationID) MatchesAuditEntry(e users.AuditEntry) bool {
+	return e.OrganizationID == string(o)
+}
+
+// AuditTeamID finds the entries of exactly this team.
+type AuditTeamID string
+
+// Where implements AuditEntry.
+func (t AuditTeamID) Where() squirrel.Sqlizer {
+	return squirrel.Eq{"audit_log.team_id": string(t)}
+}
+
+// MatchesAuditEntry implements AuditEntry.
+func (t AuditTeamID) MatchesAuditEntry(e users.AuditEntry) bool {
+	return e.TeamID == string(t)
+}
+
+// AuditActorID finds the entries of actions taken by, or while
+// impersonating, a user.
+type AuditActorID string
+
+// Where implements AuditEntry.
+func (a AuditActorID) Where() squirrel.Sqlizer {
+	return squirrel.Or{
+		squirrel.Eq{"audit_log.actor_id": string(a)},
+		squirrel.Eq{"audit_log.impersonating_user_id": string(a)},
+	}
+}
+
+// MatchesAuditEntry implements AuditEntry.
+func (a AuditActorID) MatchesAuditEntry(e users.AuditEntry) bool {
+	return e.ActorID == string(a) || e.ImpersonatingUserID == string(a)
+}
+
+// AuditAction finds entries of an action, e.g. `organization.delete`, or
+// of all the actions starting with a prefix ending in `.`, e.g. `team.`.
+type AuditAction string
+
+// Where implements AuditEntry.
+func (a AuditAction) Where() squirrel.Sqlizer {
+	if strings.HasSuffix(string(a), ".") {
+		return squirrel.Expr("audit_log.action LIKE ?", string(a)+"%")
+	}
+	return squirrel.Eq{"audit_log.action": string(a)}
+}
+
+// MatchesAuditEntry implements AuditEntry.
+func (a AuditAction) MatchesAuditEntry(e users.AuditEntry) bool {
+	if strings.HasSuffix(string(a), ".") {
+		return strings.HasPrefix(e.Action, string(a))
+	}
+	return e.Action == string(a)
+}
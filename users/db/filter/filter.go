@@ -73,6 +73,17 @@ func (a AndFilter) MatchesTeam(t users.Team) bool {
 	return true
 }
 
+// MatchesAuditEntry matches all the filters in this AndFilter.
+func (a AndFilter) MatchesAuditEntry(e users.AuditEntry) bool {
+	for _, f := range a {
+		matcher := f.(AuditEntry)
+		if !matcher.MatchesAuditEntry(e) {
+			return false
+		}
+	}
+	return true
+}
+
 // OrFilter requires at least one filter to pass.
 type OrFilter []Filter
 
@@ -123,6 +134,17 @@ func (o OrFilter) MatchesTeam(t users.Team) bool {
 	return false
 }
 
+// MatchesAuditEntry matches at least one of the filters in this OrFilter.
+func (o OrFilter) MatchesAuditEntry(e users.AuditEntry) bool {
+	for _, f := range o {
+		matcher := f.(AuditEntry)
+		if matcher.MatchesAuditEntry(e) {
+			return true
+		}
+	}
+	return false
+}
+
 // ParseOrgQuery extracts filters and search from the `query` form
 // value. It supports `<key>:<value>` for exact matches as well as `is:<key>`
 // for boolean toggles, and `feature:<feature>` for feature flags.
@@ -222,3 +244,26 @@ func ParseTeamQuery(qs string) Team {
 	}
 	return And(filters...)
 }
+
+// ParseAuditQuery extracts filters from the 'query' form value. It supports
+// `org:<id>`, `team:<id>`, `actor:<user id>` and `action:<action>`.
+func ParseAuditQuery(qs string) AuditEntry {
+	filters := []Filter{}
+	for _, p := range strings.Fields(qs) {
+		kv := strings.SplitN(p, queryFilterDelim, 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "org":
+			filters = append(filters, AuditOrganizationID(kv[1]))
+		case "team":
+			filters = append(filters, AuditTeamID(kv[1]))
+		case "actor":
+			filters = append(filters, AuditActorID(kv[1]))
+		case "action":
+			filters = append(filters, AuditAction(kv[1]))
+		}
+	}
+	return And(filters...)
+}
@@ -3,6 +3,7 @@ package filter_test
 import (
 	"github.com/Masterminds/squirrel"
 	"github.com/stretchr/testify/assert"
+	"github.com/weaveworks/service/users"
 	"github.com/weaveworks/service/users/db/filter"
 	"testing"
 )
@@ -44,3 +45,15 @@ func TestAndOr(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "((a = ? OR b = ?) AND b = ?)", sql)
 }
+
+func TestAuditOrganization(t *testing.T) {
+	f := filter.AuditOrganization{ID: "1", TeamID: "2"}
+	sql, args, err := f.Where().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(audit_log.organization_id = ? OR (audit_log.organization_id IS NULL AND audit_log.team_id = ?))", sql)
+	assert.Equal(t, []interface{}{"1", "2"}, args)
+
+	assert.True(t, f.MatchesAuditEntry(users.AuditEntry{OrganizationID: "1", TeamID: "3"}))
+	assert.True(t, f.MatchesAuditEntry(users.AuditEntry{TeamID: "2"}))
+	assert.False(t, f.MatchesAuditEntry(users.AuditEntry{OrganizationID: "4", TeamID: "2"}))
+}
@@ -166,6 +166,24 @@ func (i ID) MatchesOrg(o users.Organization) bool {
 	return o.ID == string(i)
 }
 
+// TeamIDs filters for organizations belonging to any of these teams.
+type TeamIDs []string
+
+// Where returns the query to filter by team ID.
+func (t TeamIDs) Where() squirrel.Sqlizer {
+	return squirrel.Eq{"organizations.team_id": []string(t)}
+}
+
+// MatchesOrg checks whether an organization matches this filter.
+func (t TeamIDs) MatchesOrg(o users.Organization) bool {
+	for _, teamID := range t {
+		if o.TeamID == teamID {
+			return true
+		}
+	}
+	return false
+}
+
 // ExternalID filters for organizations with exactly this external ID.
 type ExternalID string
 
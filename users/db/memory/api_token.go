@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/tokens"
+)
+
+// ListAPITokens lists the unrevoked API tokens of an organization
+func (d *DB) ListAPITokens(ctx context.Context, orgExternalID string) ([]*users.APIToken, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	o, err := d.findOrganizationByExternalID(orgExternalID)
+	if err != nil {
+		return nil, err
+	}
+	var ts []*users.APIToken
+	for _, t := range d.apiTokens {
+		if t.OrganizationID == o.ID && !t.Revoked() {
+			ts = append(ts, t)
+		}
+	}
+	sort.Slice(ts, func(i, j int) bool {
+		if ts[i].CreatedAt.Equal(ts[j].CreatedAt) {
+			return len(ts[i].ID) < len(ts[j].ID) || (len(ts[i].ID) == len(ts[j].ID) && ts[i].ID < ts[j].ID)
+		}
+		return ts[i].CreatedAt.Before(ts[j].CreatedAt)
+	})
+	return ts, nil
+}
+
+// CreateAPIToken creates a new API token for an organization
+func (d *DB) CreateAPIToken(ctx context.Context, orgExternalID, createdBy, name string, scopes []string, expiresAt *time.Time) (*users.APIToken, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	o, err := d.findOrganizationByExternalID(orgExternalID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := tokens.Generate()
+	if err != nil {
+		return nil, err
+	}
+	t := &users.APIToken{
+		ID:             fmt.Sprint(len(d.apiTokens) + 1),
+		OrganizationID: o.ID,
+		Name:           name,
+		Scopes:         scopes,
+		CreatedBy:      createdBy,
+		CreatedAt:      time.Now().UTC(),
+		ExpiresAt:      expiresAt,
+	}
+	d.apiTokens[tokens.Hash(token)] = t
+
+	created := *t
+	created.Token = token
+	return &created, nil
+}
+
+// RevokeAPIToken revokes an organization's API token
+func (d *DB) RevokeAPIToken(ctx context.Context, orgExternalID, tokenID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	o, err := d.findOrganizationByExternalID(orgExternalID)
+	if err != nil {
+		return err
+	}
+	for _, t := range d.apiTokens {
+		if t.OrganizationID == o.ID && t.ID == tokenID && !t.Revoked() {
+			now := time.Now().UTC()
+			t.RevokedAt = &now
+			return nil
+		}
+	}
+	return users.ErrNotFound
+}
+
+// FindAPIToken finds an unrevoked API token
+func (d *DB) FindAPIToken(ctx context.Context, token string) (*users.APIToken, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	t, ok := d.apiTokens[tokens.Hash(token)]
+	if !ok || t.Revoked() {
+		return nil, users.ErrNotFound
+	}
+	return t, nil
+}
+
+// SetAPITokenLastUsedAt records when an API token was last used
+func (d *DB) SetAPITokenLastUsedAt(ctx context.Context, tokenID string, lastUsedAt time.Time) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, t := range d.apiTokens {
+		if t.ID == tokenID {
+			t.LastUsedAt = &lastUsedAt
+			return nil
+		}
+	}
+	return users.ErrNotFound
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/db/filter"
+)
+
+// InsertAuditEntry appends an entry to the audit log
+func (d *DB) InsertAuditEntry(_ context.Context, entry *users.AuditEntry) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	e := *entry
+	e.ID = fmt.Sprint(len(d.auditLog) + 1)
+	e.CreatedAt = time.Now().UTC()
+	d.auditLog = append(d.auditLog, &e)
+	entry.ID, entry.CreatedAt = e.ID, e.CreatedAt
+	return nil
+}
+
+// ListAuditEntries lists audit log entries matching the filter, newest first
+func (d *DB) ListAuditEntries(_ context.Context, f filter.AuditEntry, page uint64) ([]*users.AuditEntry, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var entries []*users.AuditEntry
+	for i := len(d.auditLog) - 1; i >= 0; i-- {
+		if f.MatchesAuditEntry(*d.auditLog[i]) {
+			entries = append(entries, d.auditLog[i])
+		}
+	}
+	if page > 0 {
+		start := (page - 1) * filter.ResultsPerPage
+		if start >= uint64(len(entries)) {
+			return nil, nil
+		}
+		end := start + filter.ResultsPerPage
+		if end > uint64(len(entries)) {
+			end = uint64(len(entries))
+		}
+		entries = entries[start:end]
+	}
+	return entries, nil
+}
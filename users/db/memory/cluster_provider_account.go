@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/weaveworks/service/users"
+)
+
+// AddClusterProviderAccount records an account a user granted access to
+func (d *DB) AddClusterProviderAccount(ctx context.Context, userID, provider, account string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.users[userID]; !ok {
+		return users.ErrNotFound
+	}
+	key := userID + "-" + provider
+	for _, a := range d.clusterAccounts[key] {
+		if a == account {
+			return nil
+		}
+	}
+	d.clusterAccounts[key] = append(d.clusterAccounts[key], account)
+	return nil
+}
+
+// ListClusterProviderAccounts lists the accounts a user granted access to
+func (d *DB) ListClusterProviderAccounts(ctx context.Context, userID, provider string) ([]string, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	accounts := append([]string{}, d.clusterAccounts[userID+"-"+provider]...)
+	sort.Strings(accounts)
+	return accounts, nil
+}
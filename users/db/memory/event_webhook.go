@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/weaveworks/service/users"
+)
+
+// ListEventWebhooks lists the event webhooks of a team
+func (d *DB) ListEventWebhooks(ctx context.Context, teamID string) ([]*users.EventWebhook, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	webhooks := []*users.EventWebhook{}
+	for _, w := range d.eventWebhooks {
+		if w.TeamID == teamID {
+			webhooks = append(webhooks, copyEventWebhook(w))
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks, nil
+}
+
+// CreateEventWebhook creates an event webhook for a team
+func (d *DB) CreateEventWebhook(ctx context.Context, webhook *users.EventWebhook) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.teams[webhook.TeamID]; !ok {
+		return users.ErrNotFound
+	}
+	d.nextEventWebhookID++
+	webhook.ID = fmt.Sprint(d.nextEventWebhookID)
+	webhook.CreatedAt = time.Now().UTC()
+	d.eventWebhooks[webhook.ID] = copyEventWebhook(webhook)
+	return nil
+}
+
+// FindEventWebhook finds an event webhook
+func (d *DB) FindEventWebhook(ctx context.Context, webhookID string) (*users.EventWebhook, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	w, ok := d.eventWebhooks[webhookID]
+	if !ok {
+		return nil, users.ErrNotFound
+	}
+	return copyEventWebhook(w), nil
+}
+
+// DeleteEventWebhook deletes an event webhook of a team, and its deliveries
+func (d *DB) DeleteEventWebhook(ctx context.Context, teamID, webhookID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	w, ok := d.eventWebhooks[webhookID]
+	if !ok || w.TeamID != teamID {
+		return users.ErrNotFound
+	}
+	delete(d.eventWebhooks, webhookID)
+	deliveries := d.eventDeliveries[:0]
+	for _, delivery := range d.eventDeliveries {
+		if delivery.WebhookID != webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	d.eventDeliveries = deliveries
+	return nil
+}
+
+// CreateEventDelivery queues an event for delivery to a webhook
+func (d *DB) CreateEventDelivery(ctx context.Context, delivery *users.EventDelivery) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.eventWebhooks[delivery.WebhookID]; !ok {
+		return users.ErrNotFound
+	}
+	d.nextEventDeliveryID++
+	delivery.ID = fmt.Sprint(d.nextEventDeliveryID)
+	delivery.State = users.EventDeliveryPending
+	delivery.CreatedAt = time.Now().UTC()
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = delivery.CreatedAt
+	}
+	created := *delivery
+	d.eventDeliveries = append(d.eventDeliveries, &created)
+	return nil
+}
+
+// ClaimEventDeliveries leases pending deliveries which are due
+func (d *DB) ClaimEventDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*users.EventDelivery, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	claimed := []*users.EventDelivery{}
+	for _, delivery := range d.eventDeliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if delivery.State != users.EventDeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.NextAttemptAt = now.Add(lease)
+		result := *delivery
+		claimed = append(claimed, &result)
+	}
+	return claimed, nil
+}
+
+// UpdateEventDelivery records the outcome of an attempt at a delivery
+func (d *DB) UpdateEventDelivery(ctx context.Context, delivery *users.EventDelivery) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for i, existing := range d.eventDeliveries {
+		if existing.ID == delivery.ID {
+			updated := *delivery
+			d.eventDeliveries[i] = &updated
+			return nil
+		}
+	}
+	return users.ErrNotFound
+}
+
+// ListEventDeliveries lists the most recent deliveries to a webhook
+func (d *DB) ListEventDeliveries(ctx context.Context, webhookID string, limit int) ([]*users.EventDelivery, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	deliveries := []*users.EventDelivery{}
+	for i := len(d.eventDeliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if d.eventDeliveries[i].WebhookID == webhookID {
+			result := *d.eventDeliveries[i]
+			deliveries = append(deliveries, &result)
+		}
+	}
+	return deliveries, nil
+}
+
+func copyEventWebhook(w *users.EventWebhook) *users.EventWebhook {
+	result := *w
+	result.EventTypes = append([]string{}, w.EventTypes...)
+	return &result
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/weaveworks/service/common/featureflag"
+	"github.com/weaveworks/service/users"
+)
+
+// ListFeatureFlagDefinitions lists the flags decided by rules, by name
+func (d *DB) ListFeatureFlagDefinitions(ctx context.Context) ([]featureflag.Flag, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	flags := []featureflag.Flag{}
+	for _, flag := range d.featureFlags {
+		flag.Rules = append([]featureflag.Rule{}, flag.Rules...)
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags, nil
+}
+
+// SetFeatureFlagDefinition creates or replaces the definition of a flag
+func (d *DB) SetFeatureFlagDefinition(ctx context.Context, flag featureflag.Flag) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	flag.Rules = append([]featureflag.Rule{}, flag.Rules...)
+	d.featureFlags[flag.Name] = flag
+	return nil
+}
+
+// DeleteFeatureFlagDefinition deletes the definition of a flag
+func (d *DB) DeleteFeatureFlagDefinition(ctx context.Context, name string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.featureFlags[name]; !ok {
+		return users.ErrNotFound
+	}
+	delete(d.featureFlags, name)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/weaveworks/service/common/featureflag"
 	"github.com/weaveworks/service/users"
 	"github.com/weaveworks/service/users/login"
 )
@@ -21,6 +22,20 @@ type DB struct {
 	permissions          map[string]*users.Permission          // map[id]permission
 	rolesPermissions     map[string][]string                   // map[roleID][]permissionID
 	webhooks             map[string][]*users.Webhook           // map[externalOrgID]webhook
+	apiTokens            map[string]*users.APIToken            // map[tokenHash]APIToken
+	auditLog             []*users.AuditEntry                   // oldest first
+	teamSSOConfigs       map[string]*users.TeamSSOConfig       // map[teamID]config
+	teamSCIMTokens       map[string]*users.TeamSCIMToken       // map[tokenHash]token
+	teamSCIMUsers        map[string]map[string]bool            // map[teamID]set[userID]
+	sessions             map[string]*users.Session             // map[id]session
+	userTOTP             map[string]*users.UserTOTP            // map[userID]enrollment
+	teamsTwoFactor       map[string]bool                       // map[teamID]requireTwoFactor
+	eventWebhooks        map[string]*users.EventWebhook        // map[id]webhook
+	eventDeliveries      []*users.EventDelivery                // oldest first
+	featureFlags         map[string]featureflag.Flag           // map[name]flag
+	clusterAccounts      map[string][]string                   // map['userID-provider']accounts
+	nextEventWebhookID   int
+	nextEventDeliveryID  int
 	passwordHashingCost  int
 	mtx                  sync.Mutex
 }
@@ -53,6 +68,13 @@ var permissions = map[string]*users.Permission{
 	"scope.container.pause":        {ID: "scope.container.pause", Name: "Scope.container.pause", Description: "derp"},
 	"scope.container.restart":      {ID: "scope.container.restart", Name: "Scope.container.restart", Description: "derp"},
 	"scope.container.stop":         {ID: "scope.container.stop", Name: "Scope.container.stop", Description: "derp"},
+	"instance.token.create":        {ID: "instance.token.create", Name: "Instance.token.create", Description: "derp"},
+	"instance.token.revoke":        {ID: "instance.token.revoke", Name: "Instance.token.revoke", Description: "derp"},
+	"instance.audit.view":          {ID: "instance.audit.view", Name: "Instance.audit.view", Description: "derp"},
+	"team.roles.manage":            {ID: "team.roles.manage", Name: "Team.roles.manage", Description: "derp"},
+	"team.sso.manage":              {ID: "team.sso.manage", Name: "Team.sso.manage", Description: "derp"},
+	"team.scim.manage":             {ID: "team.scim.manage", Name: "Team.scim.manage", Description: "derp"},
+	"team.webhooks.manage":         {ID: "team.webhooks.manage", Name: "Team.webhooks.manage", Description: "derp"},
 }
 
 // New creates a new in-memory database
@@ -86,6 +108,13 @@ func New(_, _ string, passwordHashingCost int) (*DB, error) {
 			"scope.container.pause",
 			"scope.container.restart",
 			"scope.container.stop",
+			"instance.token.create",
+			"instance.token.revoke",
+			"instance.audit.view",
+			"team.roles.manage",
+			"team.sso.manage",
+			"team.scim.manage",
+			"team.webhooks.manage",
 		},
 		"editor": {
 			"alert.settings.update",
@@ -127,6 +156,16 @@ func New(_, _ string, passwordHashingCost int) (*DB, error) {
 		permissions:         permissions,
 		rolesPermissions:    rolesPermissions,
 		webhooks:            make(map[string][]*users.Webhook),
+		apiTokens:           make(map[string]*users.APIToken),
+		teamSSOConfigs:      make(map[string]*users.TeamSSOConfig),
+		teamSCIMTokens:      make(map[string]*users.TeamSCIMToken),
+		teamSCIMUsers:       make(map[string]map[string]bool),
+		sessions:            make(map[string]*users.Session),
+		userTOTP:            make(map[string]*users.UserTOTP),
+		teamsTwoFactor:      make(map[string]bool),
+		eventWebhooks:       make(map[string]*users.EventWebhook),
+		featureFlags:        make(map[string]featureflag.Flag),
+		clusterAccounts:     make(map[string][]string),
 		passwordHashingCost: passwordHashingCost,
 	}, nil
 }
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"context"
+	"sort"
 
 	"github.com/weaveworks/service/users"
 )
@@ -24,3 +25,16 @@ func (d *DB) ListPermissionsForRoleID(_ context.Context, roleID string) ([]*user
 
 	return permissions, nil
 }
+
+// ListPermissions lists all permissions
+func (d *DB) ListPermissions(_ context.Context) ([]*users.Permission, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	permissions := []*users.Permission{}
+	for _, permission := range d.permissions {
+		permissions = append(permissions, permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].ID < permissions[j].ID })
+	return permissions, nil
+}
@@ -2,19 +2,97 @@ package memory
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
 	"github.com/weaveworks/service/users"
 )
 
-// ListRoles lists all user roles
+// ListRoles lists all built-in user roles
 func (d *DB) ListRoles(_ context.Context) ([]*users.Role, error) {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
 
 	var roles []*users.Role
 	for _, role := range d.roles {
-		roles = append(roles, role)
+		if role.TeamID == "" {
+			roles = append(roles, role)
+		}
 	}
 
 	return roles, nil
 }
+
+// ListTeamRoles lists the built-in roles and the custom roles of a team
+func (d *DB) ListTeamRoles(_ context.Context, teamID string) ([]*users.Role, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var roles []*users.Role
+	for _, role := range d.roles {
+		if role.TeamID == "" || role.TeamID == teamID {
+			roles = append(roles, role)
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].ID < roles[j].ID })
+	return roles, nil
+}
+
+// FindRoleByID finds a built-in or custom role
+func (d *DB) FindRoleByID(_ context.Context, roleID string) (*users.Role, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	role, ok := d.roles[roleID]
+	if !ok {
+		return nil, users.ErrNotFound
+	}
+	return role, nil
+}
+
+// CreateTeamRole creates a custom role for a team
+func (d *DB) CreateTeamRole(_ context.Context, teamID, name, description string, permissionIDs []string) (*users.Role, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var id string
+	for n := len(d.roles) + 1; ; n++ {
+		id = fmt.Sprintf("custom.%d", n)
+		if _, exists := d.roles[id]; !exists {
+			break
+		}
+	}
+	role := &users.Role{ID: id, Name: name, Description: description, TeamID: teamID}
+	d.roles[id] = role
+	d.rolesPermissions[id] = append([]string{}, permissionIDs...)
+	return role, nil
+}
+
+// UpdateTeamRole updates a custom role of a team, replacing its permissions
+func (d *DB) UpdateTeamRole(_ context.Context, teamID, roleID, name, description string, permissionIDs []string) (*users.Role, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	role, ok := d.roles[roleID]
+	if !ok || role.TeamID == "" || role.TeamID != teamID {
+		return nil, users.ErrNotFound
+	}
+	role.Name = name
+	role.Description = description
+	d.rolesPermissions[roleID] = append([]string{}, permissionIDs...)
+	return role, nil
+}
+
+// DeleteTeamRole deletes a custom role of a team
+func (d *DB) DeleteTeamRole(_ context.Context, teamID, roleID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	role, ok := d.roles[roleID]
+	if !ok || role.TeamID == "" || role.TeamID != teamID {
+		return users.ErrNotFound
+	}
+	delete(d.roles, roleID)
+	delete(d.rolesPermissions, roleID)
+	return nil
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/tokens"
+)
+
+// CreateTeamSCIMToken creates a SCIM token for a team, replacing any it had
+func (d *DB) CreateTeamSCIMToken(ctx context.Context, teamID, createdBy string) (*users.TeamSCIMToken, error) {
+	token, err := tokens.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.teams[teamID]; !ok {
+		return nil, users.ErrNotFound
+	}
+	d.deleteTeamSCIMToken(teamID)
+	t := &users.TeamSCIMToken{
+		TeamID:    teamID,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().UTC(),
+	}
+	d.teamSCIMTokens[tokens.Hash(token)] = t
+	result := *t
+	result.Token = token
+	return &result, nil
+}
+
+// GetTeamSCIMToken returns the SCIM token of a team, without the token itself
+func (d *DB) GetTeamSCIMToken(ctx context.Context, teamID string) (*users.TeamSCIMToken, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, t := range d.teamSCIMTokens {
+		if t.TeamID == teamID {
+			result := *t
+			return &result, nil
+		}
+	}
+	return nil, users.ErrNotFound
+}
+
+// FindTeamSCIMToken finds a team's SCIM token
+func (d *DB) FindTeamSCIMToken(ctx context.Context, token string) (*users.TeamSCIMToken, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	t, ok := d.teamSCIMTokens[tokens.Hash(token)]
+	if !ok {
+		return nil, users.ErrNotFound
+	}
+	result := *t
+	return &result, nil
+}
+
+// DeleteTeamSCIMToken deletes the SCIM token of a team
+func (d *DB) DeleteTeamSCIMToken(ctx context.Context, teamID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if !d.deleteTeamSCIMToken(teamID) {
+		return users.ErrNotFound
+	}
+	return nil
+}
+
+func (d *DB) deleteTeamSCIMToken(teamID string) bool {
+	for hash, t := range d.teamSCIMTokens {
+		if t.TeamID == teamID {
+			delete(d.teamSCIMTokens, hash)
+			return true
+		}
+	}
+	return false
+}
+
+// AddTeamSCIMUser records that the team's identity provider manages the user
+func (d *DB) AddTeamSCIMUser(ctx context.Context, teamID, userID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.teams[teamID]; !ok {
+		return users.ErrNotFound
+	}
+	if _, ok := d.users[userID]; !ok {
+		return users.ErrNotFound
+	}
+	if d.teamSCIMUsers[teamID] == nil {
+		d.teamSCIMUsers[teamID] = map[string]bool{}
+	}
+	d.teamSCIMUsers[teamID][userID] = true
+	return nil
+}
+
+// IsTeamSCIMUser returns whether the team's identity provider manages the user
+func (d *DB) IsTeamSCIMUser(ctx context.Context, teamID, userID string) (bool, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	return d.teamSCIMUsers[teamID][userID], nil
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/weaveworks/service/users"
+)
+
+// CreateSession persists a new session
+func (d *DB) CreateSession(ctx context.Context, session *users.Session) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	now := time.Now().UTC()
+	session.CreatedAt, session.LastSeenAt = now, now
+	s := *session
+	d.sessions[s.ID] = &s
+	return nil
+}
+
+// FindSession finds an unrevoked session
+func (d *DB) FindSession(ctx context.Context, sessionID string) (*users.Session, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	s, ok := d.sessions[sessionID]
+	if !ok || s.RevokedAt != nil {
+		return nil, users.ErrNotFound
+	}
+	session := *s
+	return &session, nil
+}
+
+// ListSessions lists the unrevoked sessions of a user
+func (d *DB) ListSessions(ctx context.Context, userID string) ([]*users.Session, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var ss []*users.Session
+	for _, s := range d.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			session := *s
+			ss = append(ss, &session)
+		}
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].LastSeenAt.After(ss[j].LastSeenAt)
+	})
+	return ss, nil
+}
+
+// SetSessionLastSeenAt records when a session was last used
+func (d *DB) SetSessionLastSeenAt(ctx context.Context, sessionID string, lastSeenAt time.Time) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	s, ok := d.sessions[sessionID]
+	if !ok {
+		return users.ErrNotFound
+	}
+	s.LastSeenAt = lastSeenAt
+	return nil
+}
+
+// RevokeSession revokes one of a user's sessions
+func (d *DB) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	s, ok := d.sessions[sessionID]
+	if !ok || s.UserID != userID || s.RevokedAt != nil {
+		return users.ErrNotFound
+	}
+	now := time.Now().UTC()
+	s.RevokedAt = &now
+	return nil
+}
+
+// RevokeUserSessions revokes all of a user's sessions
+func (d *DB) RevokeUserSessions(ctx context.Context, userID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.revokeUserSessions(userID)
+	return nil
+}
+
+func (d *DB) revokeUserSessions(userID string) {
+	now := time.Now().UTC()
+	for _, s := range d.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/weaveworks/service/users"
+)
+
+// GetTeamSSOConfig returns the single sign-on configuration of a team
+func (d *DB) GetTeamSSOConfig(ctx context.Context, teamID string) (*users.TeamSSOConfig, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	c, ok := d.teamSSOConfigs[teamID]
+	if !ok {
+		return nil, users.ErrNotFound
+	}
+	config := *c
+	return &config, nil
+}
+
+// SetTeamSSOConfig creates or replaces the single sign-on configuration of a team
+func (d *DB) SetTeamSSOConfig(ctx context.Context, config *users.TeamSSOConfig) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.teams[config.TeamID]; !ok {
+		return users.ErrNotFound
+	}
+	c := *config
+	now := time.Now().UTC()
+	c.CreatedAt, c.UpdatedAt = now, now
+	if existing, ok := d.teamSSOConfigs[config.TeamID]; ok {
+		c.CreatedAt = existing.CreatedAt
+	}
+	d.teamSSOConfigs[config.TeamID] = &c
+	return nil
+}
+
+// DeleteTeamSSOConfig removes the single sign-on configuration of a team
+func (d *DB) DeleteTeamSSOConfig(ctx context.Context, teamID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.teamSSOConfigs[teamID]; !ok {
+		return users.ErrNotFound
+	}
+	delete(d.teamSSOConfigs, teamID)
+	return nil
+}
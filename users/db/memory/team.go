@@ -310,10 +310,15 @@ func (d *DB) ensureUserIsPartOfTeamByName(ctx context.Context, userID, teamName
 	return team, nil
 }
 
-// RemoveUserFromTeam removes a user from a team
+// RemoveUserFromTeam removes a user from a team, and revokes their sessions
 func (d *DB) RemoveUserFromTeam(ctx context.Context, userID, teamID string) error {
-
+	if _, ok := d.teamMemberships[userID][teamID]; !ok {
+		return nil
+	}
 	delete(d.teamMemberships[userID], teamID)
 
+	// Removed members mustn't keep access through sessions they already have
+	d.revokeUserSessions(userID)
+
 	return nil
 }
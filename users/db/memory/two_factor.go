@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/weaveworks/service/users"
+)
+
+// GetUserTOTP returns the two-factor enrollment of a user
+func (d *DB) GetUserTOTP(ctx context.Context, userID string) (*users.UserTOTP, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	t, ok := d.userTOTP[userID]
+	if !ok {
+		return nil, users.ErrNotFound
+	}
+	result := *t
+	result.RecoveryCodeHashes = append([]string{}, t.RecoveryCodeHashes...)
+	return &result, nil
+}
+
+// SetUserTOTP starts enrolling a user in two-factor authentication
+func (d *DB) SetUserTOTP(ctx context.Context, userID, secret string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.users[userID]; !ok {
+		return users.ErrNotFound
+	}
+	d.userTOTP[userID] = &users.UserTOTP{
+		UserID:             userID,
+		Secret:             secret,
+		RecoveryCodeHashes: []string{},
+		CreatedAt:          time.Now().UTC(),
+	}
+	return nil
+}
+
+// ConfirmUserTOTP finishes enrolling a user in two-factor authentication
+func (d *DB) ConfirmUserTOTP(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	t, ok := d.userTOTP[userID]
+	if !ok {
+		return users.ErrNotFound
+	}
+	now := time.Now().UTC()
+	t.ConfirmedAt = &now
+	t.LastUsedStep = step
+	t.RecoveryCodeHashes = append([]string{}, recoveryCodeHashes...)
+	return nil
+}
+
+// SetUserTOTPRecoveryCodes replaces a user's recovery codes
+func (d *DB) SetUserTOTPRecoveryCodes(ctx context.Context, userID string, recoveryCodeHashes []string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	t, ok := d.userTOTP[userID]
+	if !ok {
+		return users.ErrNotFound
+	}
+	t.RecoveryCodeHashes = append([]string{}, recoveryCodeHashes...)
+	return nil
+}
+
+// UseUserTOTPStep records a successful use of the code of step
+func (d *DB) UseUserTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	t, ok := d.userTOTP[userID]
+	if !ok {
+		return false, users.ErrNotFound
+	}
+	if step <= t.LastUsedStep {
+		return false, nil
+	}
+	t.LastUsedStep = step
+	t.FailedAttempts = 0
+	return true, nil
+}
+
+// UseUserTOTPRecoveryCode consumes one of a user's recovery codes
+func (d *DB) UseUserTOTPRecoveryCode(ctx context.Context, userID, recoveryCodeHash string) (bool, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	t, ok := d.userTOTP[userID]
+	if !ok {
+		return false, users.ErrNotFound
+	}
+	for i, hash := range t.RecoveryCodeHashes {
+		if hash == recoveryCodeHash {
+			t.RecoveryCodeHashes = append(t.RecoveryCodeHashes[:i:i], t.RecoveryCodeHashes[i+1:]...)
+			t.FailedAttempts = 0
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AddUserTOTPFailure counts a failed attempt at a user's second factor
+func (d *DB) AddUserTOTPFailure(ctx context.Context, userID string, since time.Time) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	t, ok := d.userTOTP[userID]
+	if !ok {
+		return users.ErrNotFound
+	}
+	if t.LastFailedAt == nil || t.LastFailedAt.Before(since) {
+		t.FailedAttempts = 0
+	}
+	now := time.Now().UTC()
+	t.FailedAttempts++
+	t.LastFailedAt = &now
+	return nil
+}
+
+// DeleteUserTOTP removes a user's two-factor enrollment
+func (d *DB) DeleteUserTOTP(ctx context.Context, userID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.userTOTP[userID]; !ok {
+		return users.ErrNotFound
+	}
+	delete(d.userTOTP, userID)
+	return nil
+}
+
+// TeamRequiresTwoFactor tells whether a team requires two-factor
+// authentication for sensitive permissions
+func (d *DB) TeamRequiresTwoFactor(ctx context.Context, teamID string) (bool, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.teams[teamID]; !ok {
+		return false, users.ErrNotFound
+	}
+	return d.teamsTwoFactor[teamID], nil
+}
+
+// SetTeamRequiresTwoFactor sets whether a team requires two-factor
+// authentication for sensitive permissions
+func (d *DB) SetTeamRequiresTwoFactor(ctx context.Context, teamID string, require bool) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.teams[teamID]; !ok {
+		return users.ErrNotFound
+	}
+	d.teamsTwoFactor[teamID] = require
+	return nil
+}
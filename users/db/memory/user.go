@@ -76,6 +76,9 @@ func (d *DB) DeleteUser(ctx context.Context, userID, actingID string) error {
 	// Delete team memberships
 	delete(d.teamMemberships, userID)
 
+	// Log the user out everywhere
+	d.revokeUserSessions(userID)
+
 	// Delete user
 	delete(d.users, userID)
 
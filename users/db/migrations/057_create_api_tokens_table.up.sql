@@ -0,0 +1,27 @@
+CREATE SEQUENCE api_tokens_id_seq;
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id              text PRIMARY KEY NOT NULL DEFAULT nextval('api_tokens_id_seq'::regclass),
+    organization_id text NOT NULL REFERENCES organizations(id),
+    name            text NOT NULL,
+    token_hash      text NOT NULL,
+    scopes          text[] NOT NULL,
+    created_by      text REFERENCES users(id),
+
+    created_at      timestamp with time zone NOT NULL DEFAULT now(),
+    expires_at      timestamp with time zone,
+    last_used_at    timestamp with time zone,
+    revoked_at      timestamp with time zone
+);
+
+CREATE UNIQUE INDEX api_tokens_token_hash ON api_tokens (token_hash);
+CREATE INDEX api_tokens_organization_id ON api_tokens (organization_id) WHERE revoked_at IS NULL;
+
+-- instance.token.create
+INSERT INTO permissions(id, name, description) VALUES ('instance.token.create', 'Create instance API token', 'Users with this permission are allowed to create scoped API tokens for instances.') ON CONFLICT DO NOTHING;
+-- only admins can create API tokens
+INSERT INTO roles_permissions(permission_id, role_id) VALUES ('instance.token.create', 'admin') ON CONFLICT DO NOTHING;
+
+-- instance.token.revoke
+INSERT INTO permissions(id, name, description) VALUES ('instance.token.revoke', 'Revoke instance API token', 'Users with this permission are allowed to revoke API tokens of instances.') ON CONFLICT DO NOTHING;
+-- only admins can revoke API tokens
+INSERT INTO roles_permissions(permission_id, role_id) VALUES ('instance.token.revoke', 'admin') ON CONFLICT DO NOTHING;
@@ -0,0 +1,27 @@
+-- No foreign keys, as the log must outlive whatever it refers to.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id                    bigserial PRIMARY KEY,
+    created_at            timestamp with time zone NOT NULL DEFAULT now(),
+    organization_id       text,
+    team_id               text,
+    actor_id              text,
+    impersonating_user_id text,
+    action                text NOT NULL,
+    target_type           text NOT NULL,
+    target_id             text NOT NULL,
+    before                jsonb,
+    after                 jsonb,
+    metadata              jsonb NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX audit_log_organization_id ON audit_log (organization_id, created_at DESC);
+CREATE INDEX audit_log_team_id ON audit_log (team_id, created_at DESC) WHERE organization_id IS NULL;
+
+-- The audit log is append-only.
+CREATE RULE audit_log_no_update AS ON UPDATE TO audit_log DO INSTEAD NOTHING;
+CREATE RULE audit_log_no_delete AS ON DELETE TO audit_log DO INSTEAD NOTHING;
+
+-- instance.audit.view
+INSERT INTO permissions(id, name, description) VALUES ('instance.audit.view', 'View instance audit log', 'Users with this permission are allowed to view the audit log of the instance.') ON CONFLICT DO NOTHING;
+-- only admins can view the audit log
+INSERT INTO roles_permissions(permission_id, role_id) VALUES ('instance.audit.view', 'admin') ON CONFLICT DO NOTHING;
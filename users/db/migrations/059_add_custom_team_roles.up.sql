@@ -0,0 +1,12 @@
+-- Custom roles belong to a team, built-in roles to none.
+ALTER TABLE roles ADD COLUMN team_id text REFERENCES teams(id);
+CREATE INDEX roles_team_id ON roles (team_id) WHERE deleted_at IS NULL;
+
+-- Custom roles can't be given descriptive IDs like built-in roles, as
+-- teams choose their names.
+CREATE SEQUENCE custom_roles_id_seq;
+
+-- team.roles.manage
+INSERT INTO permissions(id, name, description) VALUES ('team.roles.manage', 'Manage team roles', 'Users with this permission are allowed to create, update and delete the custom roles of the team.') ON CONFLICT DO NOTHING;
+-- only admins can manage custom roles
+INSERT INTO roles_permissions(permission_id, role_id) VALUES ('team.roles.manage', 'admin') ON CONFLICT DO NOTHING;
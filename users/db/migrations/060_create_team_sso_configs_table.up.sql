@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS team_sso_configs (
+    team_id            text PRIMARY KEY NOT NULL REFERENCES teams(id),
+    protocol           text NOT NULL,
+
+    oidc_issuer_url    text,
+    oidc_client_id     text,
+    oidc_client_secret text,
+
+    saml_metadata      text,
+
+    default_role_id    text NOT NULL REFERENCES roles(id),
+    enforce            boolean NOT NULL DEFAULT false,
+
+    created_at         timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at         timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- team.sso.manage
+INSERT INTO permissions(id, name, description) VALUES ('team.sso.manage', 'Manage team single sign-on', 'Users with this permission are allowed to configure logging in to the team through its own identity provider.') ON CONFLICT DO NOTHING;
+-- only admins can manage single sign-on
+INSERT INTO roles_permissions(permission_id, role_id) VALUES ('team.sso.manage', 'admin') ON CONFLICT DO NOTHING;
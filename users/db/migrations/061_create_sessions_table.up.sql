@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id                    text PRIMARY KEY NOT NULL,
+    user_id               text NOT NULL REFERENCES users(id),
+    provider              text NOT NULL,
+    login_id              text NOT NULL,
+    impersonating_user_id text REFERENCES users(id),
+    user_agent            text NOT NULL DEFAULT '',
+    ip_address            text NOT NULL DEFAULT '',
+
+    created_at            timestamp with time zone NOT NULL DEFAULT now(),
+    last_seen_at          timestamp with time zone NOT NULL DEFAULT now(),
+    revoked_at            timestamp with time zone
+);
+
+CREATE INDEX sessions_user_id ON sessions (user_id) WHERE revoked_at IS NULL;
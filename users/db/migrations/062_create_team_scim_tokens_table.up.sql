@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS team_scim_tokens (
+    team_id    text PRIMARY KEY NOT NULL REFERENCES teams(id),
+    token_hash text NOT NULL,
+    created_by text REFERENCES users(id),
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX team_scim_tokens_token_hash ON team_scim_tokens (token_hash);
+
+-- team.scim.manage
+INSERT INTO permissions(id, name, description) VALUES ('team.scim.manage', 'Manage team SCIM provisioning', 'Users with this permission are allowed to create and revoke the token the team''s identity provider provisions members with.') ON CONFLICT DO NOTHING;
+-- only admins can manage SCIM provisioning
+INSERT INTO roles_permissions(permission_id, role_id) VALUES ('team.scim.manage', 'admin') ON CONFLICT DO NOTHING;
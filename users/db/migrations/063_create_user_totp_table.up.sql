@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id              text PRIMARY KEY NOT NULL REFERENCES users(id),
+    secret               text NOT NULL,
+    recovery_code_hashes text[] NOT NULL DEFAULT '{}',
+    last_used_step       bigint NOT NULL DEFAULT 0,
+    failed_attempts      integer NOT NULL DEFAULT 0,
+    last_failed_at       timestamp with time zone,
+    created_at           timestamp with time zone NOT NULL DEFAULT now(),
+    confirmed_at         timestamp with time zone
+);
+
+ALTER TABLE teams ADD COLUMN require_two_factor boolean NOT NULL DEFAULT false;
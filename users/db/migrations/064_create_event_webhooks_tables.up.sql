@@ -0,0 +1,37 @@
+CREATE SEQUENCE event_webhooks_id_seq;
+CREATE TABLE IF NOT EXISTS event_webhooks (
+    id          text PRIMARY KEY NOT NULL DEFAULT nextval('event_webhooks_id_seq'::regclass),
+    team_id     text NOT NULL REFERENCES teams(id),
+    url         text NOT NULL,
+    secret      text NOT NULL,
+    event_types text[] NOT NULL DEFAULT '{}',
+    created_by  text REFERENCES users(id),
+    created_at  timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX event_webhooks_team_id ON event_webhooks (team_id);
+
+CREATE SEQUENCE event_deliveries_id_seq;
+CREATE TABLE IF NOT EXISTS event_deliveries (
+    id              text PRIMARY KEY NOT NULL DEFAULT nextval('event_deliveries_id_seq'::regclass),
+    webhook_id      text NOT NULL REFERENCES event_webhooks(id) ON DELETE CASCADE,
+    event_id        text NOT NULL,
+    event_type      text NOT NULL,
+    payload         jsonb NOT NULL,
+    state           text NOT NULL DEFAULT 'pending',
+    attempts        integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp with time zone NOT NULL DEFAULT now(),
+    last_attempt_at timestamp with time zone,
+    response_status integer NOT NULL DEFAULT 0,
+    last_error      text NOT NULL DEFAULT '',
+    created_at      timestamp with time zone NOT NULL DEFAULT now(),
+    delivered_at    timestamp with time zone
+);
+
+CREATE INDEX event_deliveries_webhook_id ON event_deliveries (webhook_id, created_at);
+CREATE INDEX event_deliveries_due ON event_deliveries (next_attempt_at) WHERE state = 'pending';
+
+-- team.webhooks.manage
+INSERT INTO permissions(id, name, description) VALUES ('team.webhooks.manage', 'Manage team event webhooks', 'Users with this permission are allowed to create, test and delete the webhooks the team''s events are sent to, and view their deliveries.') ON CONFLICT DO NOTHING;
+-- only admins can manage event webhooks
+INSERT INTO roles_permissions(permission_id, role_id) VALUES ('team.webhooks.manage', 'admin') ON CONFLICT DO NOTHING;
@@ -0,0 +1,8 @@
+-- Feature flags decided by rules, rather than set on organizations one at a time.
+CREATE TABLE IF NOT EXISTS feature_flag_definitions (
+    name       text PRIMARY KEY NOT NULL,
+    definition jsonb NOT NULL,
+
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS cluster_provider_accounts (
+    user_id    text NOT NULL REFERENCES users(id),
+    provider   text NOT NULL,
+    account    text NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, provider, account)
+);
@@ -0,0 +1,8 @@
+-- Users a team's identity provider provisioned, or deprovisioned, through SCIM.
+-- Only these and the team's members can be found by the team's SCIM token.
+CREATE TABLE IF NOT EXISTS team_scim_users (
+    team_id    text NOT NULL REFERENCES teams(id),
+    user_id    text NOT NULL REFERENCES users(id),
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, user_id)
+);
@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/tokens"
+)
+
+// ListAPITokens lists the unrevoked API tokens of an organization
+func (d DB) ListAPITokens(ctx context.Context, orgExternalID string) ([]*users.APIToken, error) {
+	rows, err := d.apiTokensQuery().
+		Join("organizations ON (api_tokens.organization_id = organizations.id)").
+		Where("organizations.external_id = ?", orgExternalID).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.scanAPITokens(rows)
+}
+
+// CreateAPIToken creates a new API token for an organization
+func (d DB) CreateAPIToken(ctx context.Context, orgExternalID, createdBy, name string, scopes []string, expiresAt *time.Time) (*users.APIToken, error) {
+	token, err := tokens.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := d.FindOrganizationByID(ctx, orgExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &users.APIToken{
+		OrganizationID: org.ID,
+		Name:           name,
+		Token:          token,
+		Scopes:         scopes,
+		CreatedBy:      createdBy,
+		ExpiresAt:      expiresAt,
+	}
+	err = d.Insert("api_tokens").
+		Columns("organization_id", "name", "token_hash", "scopes", "created_by", "expires_at").
+		Values(t.OrganizationID, t.Name, tokens.Hash(token), pq.Array(t.Scopes), t.CreatedBy, t.ExpiresAt).
+		Suffix("RETURNING id, created_at").
+		QueryRowContext(ctx).
+		Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// RevokeAPIToken revokes an organization's API token
+func (d DB) RevokeAPIToken(ctx context.Context, orgExternalID, tokenID string) error {
+	org, err := d.FindOrganizationByID(ctx, orgExternalID)
+	if err != nil {
+		return err
+	}
+
+	result, err := d.Update("api_tokens").
+		Set("revoked_at", d.Now()).
+		Where("api_tokens.organization_id = ?", org.ID).
+		Where("api_tokens.id = ?", tokenID).
+		Where("api_tokens.revoked_at is null").
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return users.ErrNotFound
+	}
+	return nil
+}
+
+// FindAPIToken finds an unrevoked API token
+func (d DB) FindAPIToken(ctx context.Context, token string) (*users.APIToken, error) {
+	t, err := d.scanAPIToken(
+		d.apiTokensQuery().Where(squirrel.Eq{"api_tokens.token_hash": tokens.Hash(token)}).QueryRowContext(ctx),
+	)
+	if err == sql.ErrNoRows {
+		return nil, users.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SetAPITokenLastUsedAt records when an API token was last used
+func (d DB) SetAPITokenLastUsedAt(ctx context.Context, tokenID string, lastUsedAt time.Time) error {
+	_, err := d.Update("api_tokens").
+		Set("last_used_at", lastUsedAt).
+		Where("id = ?", tokenID).
+		ExecContext(ctx)
+	return err
+}
+
+func (d DB) scanAPITokens(rows *sql.Rows) ([]*users.APIToken, error) {
+	defer rows.Close()
+	var ts []*users.APIToken
+	for rows.Next() {
+		t, err := d.scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return ts, nil
+}
+
+func (d DB) scanAPIToken(row squirrel.RowScanner) (*users.APIToken, error) {
+	t := &users.APIToken{}
+	var createdBy sql.NullString
+	if err := row.Scan(
+		&t.ID, &t.OrganizationID, &t.Name, pq.Array(&t.Scopes), &createdBy,
+		&t.CreatedAt, &t.ExpiresAt, &t.LastUsedAt, &t.RevokedAt,
+	); err != nil {
+		return nil, err
+	}
+	t.CreatedBy = createdBy.String
+	return t, nil
+}
+
+func (d DB) apiTokensQuery() squirrel.SelectBuilder {
+	return d.Select(
+		"api_tokens.id",
+		"api_tokens.organization_id",
+		"api_tokens.name",
+		"api_tokens.scopes",
+		"api_tokens.created_by",
+		"api_tokens.created_at",
+		"api_tokens.expires_at",
+		"api_tokens.last_used_at",
+		"api_tokens.revoked_at",
+	).
+		From("api_tokens").
+		Where("api_tokens.revoked_at is null").
+		OrderBy("api_tokens.created_at ASC, api_tokens.id ASC")
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/db/filter"
+)
+
+// InsertAuditEntry appends an entry to the audit log
+func (d DB) InsertAuditEntry(ctx context.Context, entry *users.AuditEntry) error {
+	metadata, err := json.Marshal(entry.Metadata)
+	if err != nil {
+		return err
+	}
+	return d.Insert("audit_log").
+		Columns(
+			"organization_id", "team_id", "actor_id", "impersonating_user_id",
+			"action", "target_type", "target_id", "before", "after", "metadata",
+		).
+		Values(
+			nullString(entry.OrganizationID), nullString(entry.TeamID),
+			nullString(entry.ActorID), nullString(entry.ImpersonatingUserID),
+			entry.Action, entry.TargetType, entry.TargetID,
+			nullJSON(entry.Before), nullJSON(entry.After), metadata,
+		).
+		Suffix("RETURNING id, created_at").
+		QueryRowContext(ctx).
+		Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// ListAuditEntries lists audit log entries matching the filter, newest first
+func (d DB) ListAuditEntries(ctx context.Context, f filter.AuditEntry, page uint64) ([]*users.AuditEntry, error) {
+	q := d.Select(
+		"audit_log.id",
+		"audit_log.created_at",
+		"audit_log.organization_id",
+		"audit_log.team_id",
+		"audit_log.actor_id",
+		"audit_log.impersonating_user_id",
+		"audit_log.action",
+		"audit_log.target_type",
+		"audit_log.target_id",
+		"audit_log.before",
+		"audit_log.after",
+		"audit_log.metadata",
+	).
+		From("audit_log").
+		Where(f.Where()).
+		OrderBy("audit_log.created_at DESC, audit_log.id DESC")
+	if page > 0 {
+		q = q.Limit(filter.ResultsPerPage).Offset((page - 1) * filter.ResultsPerPage)
+	}
+	rows, err := q.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*users.AuditEntry
+	for rows.Next() {
+		e, err := d.scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return entries, nil
+}
+
+func (d DB) scanAuditEntry(row squirrel.RowScanner) (*users.AuditEntry, error) {
+	e := &users.AuditEntry{}
+	var (
+		orgID, teamID, actorID, impersonatingUserID sql.NullString
+		before, after, metadata                     []byte
+	)
+	if err := row.Scan(
+		&e.ID, &e.CreatedAt, &orgID, &teamID, &actorID, &impersonatingUserID,
+		&e.Action, &e.TargetType, &e.TargetID, &before, &after, &metadata,
+	); err != nil {
+		return nil, err
+	}
+	e.OrganizationID = orgID.String
+	e.TeamID = teamID.String
+	e.ActorID = actorID.String
+	e.ImpersonatingUserID = impersonatingUserID.String
+	e.Before = before
+	e.After = after
+	if err := json.Unmarshal(metadata, &e.Metadata); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullJSON(b json.RawMessage) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return []byte(b)
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+
+	"github.com/weaveworks/service/users"
+)
+
+// AddClusterProviderAccount records an account a user granted access to
+func (d DB) AddClusterProviderAccount(ctx context.Context, userID, provider, account string) error {
+	_, err := d.Insert("cluster_provider_accounts").
+		Columns("user_id", "provider", "account").
+		Values(userID, provider, account).
+		Suffix("ON CONFLICT DO NOTHING").
+		ExecContext(ctx)
+	if e, ok := err.(*pq.Error); ok && e.Code.Name() == "foreign_key_violation" {
+		return users.ErrNotFound
+	}
+	return err
+}
+
+// ListClusterProviderAccounts lists the accounts a user granted access to
+func (d DB) ListClusterProviderAccounts(ctx context.Context, userID, provider string) ([]string, error) {
+	rows, err := d.Select("account").
+		From("cluster_provider_accounts").
+		Where("user_id = ? AND provider = ?", userID, provider).
+		OrderBy("account").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	accounts := []string{}
+	for rows.Next() {
+		var account string
+		if err := rows.Scan(&account); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
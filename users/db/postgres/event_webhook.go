@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+
+	"github.com/weaveworks/service/users"
+)
+
+// ListEventWebhooks lists the event webhooks of a team
+func (d DB) ListEventWebhooks(ctx context.Context, teamID string) ([]*users.EventWebhook, error) {
+	rows, err := d.eventWebhooksQuery().
+		Where(squirrel.Eq{"team_id": teamID}).
+		OrderBy("created_at").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	webhooks := []*users.EventWebhook{}
+	for rows.Next() {
+		w, err := d.scanEventWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// CreateEventWebhook creates an event webhook for a team
+func (d DB) CreateEventWebhook(ctx context.Context, webhook *users.EventWebhook) error {
+	return d.Insert("event_webhooks").
+		Columns("team_id", "url", "secret", "event_types", "created_by").
+		Values(webhook.TeamID, webhook.URL, webhook.Secret, pq.Array(webhook.EventTypes), nullString(webhook.CreatedBy)).
+		Suffix("RETURNING id, created_at").
+		QueryRowContext(ctx).
+		Scan(&webhook.ID, &webhook.CreatedAt)
+}
+
+// FindEventWebhook finds an event webhook
+func (d DB) FindEventWebhook(ctx context.Context, webhookID string) (*users.EventWebhook, error) {
+	w, err := d.scanEventWebhook(d.eventWebhooksQuery().Where(squirrel.Eq{"id": webhookID}).QueryRowContext(ctx))
+	if err == sql.ErrNoRows {
+		return nil, users.ErrNotFound
+	}
+	return w, err
+}
+
+// DeleteEventWebhook deletes an event webhook of a team, and its deliveries
+func (d DB) DeleteEventWebhook(ctx context.Context, teamID, webhookID string) error {
+	result, err := d.Delete("event_webhooks").
+		Where(squirrel.Eq{"id": webhookID, "team_id": teamID}).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	return requireRowsAffected(result)
+}
+
+// CreateEventDelivery queues an event for delivery to a webhook
+func (d DB) CreateEventDelivery(ctx context.Context, delivery *users.EventDelivery) error {
+	nextAttemptAt := delivery.NextAttemptAt
+	if nextAttemptAt.IsZero() {
+		nextAttemptAt = d.Now()
+	}
+	delivery.State = users.EventDeliveryPending
+	return d.Insert("event_deliveries").
+		Columns("webhook_id", "event_id", "event_type", "payload", "next_attempt_at").
+		Values(delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Payload, nextAttemptAt).
+		Suffix("RETURNING id, next_attempt_at, created_at").
+		QueryRowContext(ctx).
+		Scan(&delivery.ID, &delivery.NextAttemptAt, &delivery.CreatedAt)
+}
+
+// ClaimEventDeliveries leases pending deliveries which are due
+func (d DB) ClaimEventDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*users.EventDelivery, error) {
+	// Skip deliveries other replicas are claiming, rather than waiting
+	rows, err := d.QueryContext(ctx, `
+		UPDATE event_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM event_deliveries
+			WHERE state = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+eventDeliveryColumns,
+		now, now.Add(lease), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return d.scanEventDeliveries(rows)
+}
+
+// UpdateEventDelivery records the outcome of an attempt at a delivery
+func (d DB) UpdateEventDelivery(ctx context.Context, delivery *users.EventDelivery) error {
+	result, err := d.Update("event_deliveries").
+		SetMap(map[string]interface{}{
+			"state":           delivery.State,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_attempt_at": delivery.LastAttemptAt,
+			"response_status": delivery.ResponseStatus,
+			"last_error":      delivery.LastError,
+			"delivered_at":    delivery.DeliveredAt,
+		}).
+		Where(squirrel.Eq{"id": delivery.ID}).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	return requireRowsAffected(result)
+}
+
+// ListEventDeliveries lists the most recent deliveries to a webhook
+func (d DB) ListEventDeliveries(ctx context.Context, webhookID string, limit int) ([]*users.EventDelivery, error) {
+	rows, err := d.Select(eventDeliveryColumns).
+		From("event_deliveries").
+		Where(squirrel.Eq{"webhook_id": webhookID}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.scanEventDeliveries(rows)
+}
+
+func (d DB) eventWebhooksQuery() squirrel.SelectBuilder {
+	return d.Select("id", "team_id", "url", "secret", "event_types", "created_by", "created_at").
+		From("event_webhooks")
+}
+
+func (d DB) scanEventWebhook(row squirrel.RowScanner) (*users.EventWebhook, error) {
+	w := &users.EventWebhook{}
+	var createdBy sql.NullString
+	if err := row.Scan(&w.ID, &w.TeamID, &w.URL, &w.Secret, pq.Array(&w.EventTypes), &createdBy, &w.CreatedAt); err != nil {
+		return nil, err
+	}
+	w.CreatedBy = createdBy.String
+	return w, nil
+}
+
+const eventDeliveryColumns = `id, webhook_id, event_id, event_type, payload, state, attempts,
+	next_attempt_at, last_attempt_at, response_status, last_error, created_at, delivered_at`
+
+func (d DB) scanEventDeliveries(rows *sql.Rows) ([]*users.EventDelivery, error) {
+	defer rows.Close()
+	deliveries := []*users.EventDelivery{}
+	for rows.Next() {
+		delivery := &users.EventDelivery{}
+		var lastAttemptAt, deliveredAt pq.NullTime
+		if err := rows.Scan(
+			&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Payload,
+			&delivery.State, &delivery.Attempts, &delivery.NextAttemptAt, &lastAttemptAt,
+			&delivery.ResponseStatus, &delivery.LastError, &delivery.CreatedAt, &deliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		if lastAttemptAt.Valid {
+			delivery.LastAttemptAt = &lastAttemptAt.Time
+		}
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
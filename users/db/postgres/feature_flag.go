@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/weaveworks/service/common/featureflag"
+	"github.com/weaveworks/service/users"
+)
+
+// ListFeatureFlagDefinitions lists the flags decided by rules, by name
+func (d DB) ListFeatureFlagDefinitions(ctx context.Context) ([]featureflag.Flag, error) {
+	rows, err := d.Select("definition").
+		From("feature_flag_definitions").
+		OrderBy("name").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	flags := []featureflag.Flag{}
+	for rows.Next() {
+		var definition []byte
+		if err := rows.Scan(&definition); err != nil {
+			return nil, err
+		}
+		var flag featureflag.Flag
+		if err := json.Unmarshal(definition, &flag); err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+	return flags, rows.Err()
+}
+
+// SetFeatureFlagDefinition creates or replaces the definition of a flag
+func (d DB) SetFeatureFlagDefinition(ctx context.Context, flag featureflag.Flag) error {
+	definition, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	_, err = d.ExecContext(ctx, `
+		INSERT INTO feature_flag_definitions (name, definition)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET
+			definition = EXCLUDED.definition,
+			updated_at = now()`,
+		flag.Name, definition,
+	)
+	return err
+}
+
+// DeleteFeatureFlagDefinition deletes the definition of a flag
+func (d DB) DeleteFeatureFlagDefinition(ctx context.Context, name string) error {
+	result, err := d.Delete("feature_flag_definitions").
+		Where("name = ?", name).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return users.ErrNotFound
+	}
+	return nil
+}
@@ -58,3 +58,13 @@ func (d DB) scanPermission(row squirrel.RowScanner) (*users.Permission, error) {
 	}
 	return p, nil
 }
+
+// ListPermissions lists all permissions
+func (d DB) ListPermissions(ctx context.Context) ([]*users.Permission, error) {
+	rows, err := d.permissionsQuery().QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return d.scanPermissions(rows)
+}
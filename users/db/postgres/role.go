@@ -13,7 +13,8 @@ func (d DB) rolesQuery() squirrel.SelectBuilder {
 	return d.Select(`
 		roles.id,
 		roles.name,
-		roles.description
+		roles.description,
+		roles.team_id
 	`).
 		From("roles").
 		Where("roles.deleted_at is null").
@@ -37,15 +38,17 @@ func (d DB) scanRoles(rows *sql.Rows) ([]*users.Role, error) {
 
 func (d DB) scanRole(row squirrel.RowScanner) (*users.Role, error) {
 	r := &users.Role{}
-	if err := row.Scan(&r.ID, &r.Name, &r.Description); err != nil {
+	var teamID sql.NullString
+	if err := row.Scan(&r.ID, &r.Name, &r.Description, &teamID); err != nil {
 		return nil, err
 	}
+	r.TeamID = teamID.String
 	return r, nil
 }
 
-// ListRoles returns all user roles
+// ListRoles returns all built-in user roles
 func (d DB) ListRoles(ctx context.Context) ([]*users.Role, error) {
-	query := d.rolesQuery()
+	query := d.rolesQuery().Where("roles.team_id is null")
 	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		return nil, err
@@ -53,3 +56,118 @@ func (d DB) ListRoles(ctx context.Context) ([]*users.Role, error) {
 	defer rows.Close()
 	return d.scanRoles(rows)
 }
+
+// ListTeamRoles returns the built-in roles and the custom roles of a team
+func (d DB) ListTeamRoles(ctx context.Context, teamID string) ([]*users.Role, error) {
+	query := d.rolesQuery().Where(squirrel.Or{
+		squirrel.Eq{"roles.team_id": nil},
+		squirrel.Eq{"roles.team_id": teamID},
+	})
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return d.scanRoles(rows)
+}
+
+// FindRoleByID finds a built-in or custom role
+func (d DB) FindRoleByID(ctx context.Context, roleID string) (*users.Role, error) {
+	role, err := d.scanRole(d.rolesQuery().Where(squirrel.Eq{"roles.id": roleID}).QueryRowContext(ctx))
+	if err == sql.ErrNoRows {
+		return nil, users.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// CreateTeamRole creates a custom role for a team
+func (d DB) CreateTeamRole(ctx context.Context, teamID, name, description string, permissionIDs []string) (*users.Role, error) {
+	role := &users.Role{Name: name, Description: description, TeamID: teamID}
+	err := d.Transaction(func(tx DB) error {
+		err := tx.QueryRowContext(ctx, `
+			insert into roles (id, name, description, team_id)
+			values ('custom.' || nextval('custom_roles_id_seq'), $1, $2, $3)
+			returning id`,
+			name, description, teamID,
+		).Scan(&role.ID)
+		if err != nil {
+			return err
+		}
+		return tx.setRolePermissions(ctx, role.ID, permissionIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateTeamRole updates a custom role of a team, replacing its permissions
+func (d DB) UpdateTeamRole(ctx context.Context, teamID, roleID, name, description string, permissionIDs []string) (*users.Role, error) {
+	err := d.Transaction(func(tx DB) error {
+		result, err := tx.Update("roles").
+			Set("name", name).
+			Set("description", description).
+			Where(squirrel.Eq{"id": roleID, "team_id": teamID, "deleted_at": nil}).
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		if count, err := result.RowsAffected(); err != nil {
+			return err
+		} else if count == 0 {
+			return users.ErrNotFound
+		}
+		return tx.setRolePermissions(ctx, roleID, permissionIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &users.Role{ID: roleID, Name: name, Description: description, TeamID: teamID}, nil
+}
+
+// DeleteTeamRole deletes a custom role of a team
+func (d DB) DeleteTeamRole(ctx context.Context, teamID, roleID string) error {
+	return d.Transaction(func(tx DB) error {
+		now := tx.Now()
+		result, err := tx.Update("roles").
+			Set("deleted_at", now).
+			Where(squirrel.Eq{"id": roleID, "team_id": teamID, "deleted_at": nil}).
+			ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+		if count, err := result.RowsAffected(); err != nil {
+			return err
+		} else if count == 0 {
+			return users.ErrNotFound
+		}
+		_, err = tx.Update("roles_permissions").
+			Set("deleted_at", now).
+			Where(squirrel.Eq{"role_id": roleID, "deleted_at": nil}).
+			ExecContext(ctx)
+		return err
+	})
+}
+
+// setRolePermissions replaces the permissions of a role
+func (d DB) setRolePermissions(ctx context.Context, roleID string, permissionIDs []string) error {
+	_, err := d.Update("roles_permissions").
+		Set("deleted_at", d.Now()).
+		Where(squirrel.Eq{"role_id": roleID, "deleted_at": nil}).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, permissionID := range permissionIDs {
+		if _, err := d.Insert("roles_permissions").
+			Columns("role_id", "permission_id").
+			Values(roleID, permissionID).
+			ExecContext(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
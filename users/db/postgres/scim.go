@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/tokens"
+)
+
+// CreateTeamSCIMToken creates a SCIM token for a team, replacing any it had
+func (d DB) CreateTeamSCIMToken(ctx context.Context, teamID, createdBy string) (*users.TeamSCIMToken, error) {
+	token, err := tokens.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &users.TeamSCIMToken{
+		TeamID:    teamID,
+		Token:     token,
+		CreatedBy: createdBy,
+	}
+	err = d.QueryRowContext(ctx, `
+		INSERT INTO team_scim_tokens (team_id, token_hash, created_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (team_id) DO UPDATE SET
+			token_hash = EXCLUDED.token_hash,
+			created_by = EXCLUDED.created_by,
+			created_at = now()
+		RETURNING created_at`,
+		teamID, tokens.Hash(token), nullString(createdBy),
+	).Scan(&t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetTeamSCIMToken returns the SCIM token of a team, without the token itself
+func (d DB) GetTeamSCIMToken(ctx context.Context, teamID string) (*users.TeamSCIMToken, error) {
+	return d.scanTeamSCIMToken(
+		d.teamSCIMTokensQuery().Where(squirrel.Eq{"team_id": teamID}).QueryRowContext(ctx),
+	)
+}
+
+// FindTeamSCIMToken finds a team's SCIM token
+func (d DB) FindTeamSCIMToken(ctx context.Context, token string) (*users.TeamSCIMToken, error) {
+	return d.scanTeamSCIMToken(
+		d.teamSCIMTokensQuery().Where(squirrel.Eq{"token_hash": tokens.Hash(token)}).QueryRowContext(ctx),
+	)
+}
+
+// DeleteTeamSCIMToken deletes the SCIM token of a team
+func (d DB) DeleteTeamSCIMToken(ctx context.Context, teamID string) error {
+	result, err := d.Delete("team_scim_tokens").
+		Where("team_id = ?", teamID).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return users.ErrNotFound
+	}
+	return nil
+}
+
+func (d DB) teamSCIMTokensQuery() squirrel.SelectBuilder {
+	return d.Select("team_id", "created_by", "created_at").From("team_scim_tokens")
+}
+
+func (d DB) scanTeamSCIMToken(row squirrel.RowScanner) (*users.TeamSCIMToken, error) {
+	t := &users.TeamSCIMToken{}
+	var createdBy sql.NullString
+	err := row.Scan(&t.TeamID, &createdBy, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, users.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.CreatedBy = createdBy.String
+	return t, nil
+}
+
+// AddTeamSCIMUser records that the team's identity provider manages the user
+func (d DB) AddTeamSCIMUser(ctx context.Context, teamID, userID string) error {
+	_, err := d.Insert("team_scim_users").
+		Columns("team_id", "user_id").
+		Values(teamID, userID).
+		Suffix("ON CONFLICT DO NOTHING").
+		ExecContext(ctx)
+	if e, ok := err.(*pq.Error); ok && e.Code.Name() == "foreign_key_violation" {
+		return users.ErrNotFound
+	}
+	return err
+}
+
+// IsTeamSCIMUser returns whether the team's identity provider manages the user
+func (d DB) IsTeamSCIMUser(ctx context.Context, teamID, userID string) (bool, error) {
+	var ok bool
+	err := d.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM team_scim_users WHERE team_id = $1 AND user_id = $2)`,
+		teamID, userID,
+	).Scan(&ok)
+	return ok, err
+}
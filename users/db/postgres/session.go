@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/weaveworks/service/users"
+)
+
+func (d DB) sessionsQuery() squirrel.SelectBuilder {
+	return d.Select(
+		"sessions.id",
+		"sessions.user_id",
+		"sessions.provider",
+		"sessions.login_id",
+		"sessions.impersonating_user_id",
+		"sessions.user_agent",
+		"sessions.ip_address",
+		"sessions.created_at",
+		"sessions.last_seen_at",
+	).
+		From("sessions").
+		Where("sessions.revoked_at is null")
+}
+
+func (d DB) scanSession(row squirrel.RowScanner) (*users.Session, error) {
+	s := &users.Session{}
+	var impersonatingUserID sql.NullString
+	if err := row.Scan(
+		&s.ID, &s.UserID, &s.Provider, &s.LoginID, &impersonatingUserID,
+		&s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastSeenAt,
+	); err != nil {
+		return nil, err
+	}
+	s.ImpersonatingUserID = impersonatingUserID.String
+	return s, nil
+}
+
+// CreateSession persists a new session
+func (d DB) CreateSession(ctx context.Context, session *users.Session) error {
+	return d.Insert("sessions").
+		Columns("id", "user_id", "provider", "login_id", "impersonating_user_id", "user_agent", "ip_address").
+		Values(session.ID, session.UserID, session.Provider, session.LoginID, nullString(session.ImpersonatingUserID), session.UserAgent, session.IPAddress).
+		Suffix("RETURNING created_at, last_seen_at").
+		QueryRowContext(ctx).
+		Scan(&session.CreatedAt, &session.LastSeenAt)
+}
+
+// FindSession finds an unrevoked session
+func (d DB) FindSession(ctx context.Context, sessionID string) (*users.Session, error) {
+	s, err := d.scanSession(d.sessionsQuery().Where(squirrel.Eq{"sessions.id": sessionID}).QueryRowContext(ctx))
+	if err == sql.ErrNoRows {
+		return nil, users.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ListSessions lists the unrevoked sessions of a user
+func (d DB) ListSessions(ctx context.Context, userID string) ([]*users.Session, error) {
+	rows, err := d.sessionsQuery().
+		Where(squirrel.Eq{"sessions.user_id": userID}).
+		OrderBy("sessions.last_seen_at desc").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ss []*users.Session
+	for rows.Next() {
+		s, err := d.scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, s)
+	}
+	return ss, rows.Err()
+}
+
+// SetSessionLastSeenAt records when a session was last used
+func (d DB) SetSessionLastSeenAt(ctx context.Context, sessionID string, lastSeenAt time.Time) error {
+	_, err := d.Update("sessions").
+		Set("last_seen_at", lastSeenAt).
+		Where(squirrel.Eq{"id": sessionID}).
+		ExecContext(ctx)
+	return err
+}
+
+// RevokeSession revokes one of a user's sessions
+func (d DB) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	result, err := d.Update("sessions").
+		Set("revoked_at", d.Now()).
+		Where(squirrel.Eq{"id": sessionID, "user_id": userID}).
+		Where("revoked_at is null").
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return users.ErrNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes all of a user's sessions
+func (d DB) RevokeUserSessions(ctx context.Context, userID string) error {
+	_, err := d.Update("sessions").
+		Set("revoked_at", d.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where("revoked_at is null").
+		ExecContext(ctx)
+	return err
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/weaveworks/service/users"
+)
+
+// GetTeamSSOConfig returns the single sign-on configuration of a team
+func (d DB) GetTeamSSOConfig(ctx context.Context, teamID string) (*users.TeamSSOConfig, error) {
+	c := &users.TeamSSOConfig{}
+	var issuerURL, clientID, clientSecret, metadata sql.NullString
+	err := d.Select(
+		"team_id", "protocol",
+		"oidc_issuer_url", "oidc_client_id", "oidc_client_secret",
+		"saml_metadata",
+		"default_role_id", "enforce",
+		"created_at", "updated_at",
+	).
+		From("team_sso_configs").
+		Where("team_id = ?", teamID).
+		QueryRowContext(ctx).
+		Scan(
+			&c.TeamID, &c.Protocol,
+			&issuerURL, &clientID, &clientSecret,
+			&metadata,
+			&c.DefaultRoleID, &c.Enforce,
+			&c.CreatedAt, &c.UpdatedAt,
+		)
+	if err == sql.ErrNoRows {
+		return nil, users.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.OIDCIssuerURL = issuerURL.String
+	c.OIDCClientID = clientID.String
+	c.OIDCClientSecret = clientSecret.String
+	c.SAMLMetadata = metadata.String
+	return c, nil
+}
+
+// SetTeamSSOConfig creates or replaces the single sign-on configuration of a team
+func (d DB) SetTeamSSOConfig(ctx context.Context, c *users.TeamSSOConfig) error {
+	_, err := d.ExecContext(ctx, `
+		INSERT INTO team_sso_configs
+			(team_id, protocol, oidc_issuer_url, oidc_client_id, oidc_client_secret, saml_metadata, default_role_id, enforce)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (team_id) DO UPDATE SET
+			protocol = EXCLUDED.protocol,
+			oidc_issuer_url = EXCLUDED.oidc_issuer_url,
+			oidc_client_id = EXCLUDED.oidc_client_id,
+			oidc_client_secret = EXCLUDED.oidc_client_secret,
+			saml_metadata = EXCLUDED.saml_metadata,
+			default_role_id = EXCLUDED.default_role_id,
+			enforce = EXCLUDED.enforce,
+			updated_at = now()`,
+		c.TeamID, c.Protocol,
+		nullString(c.OIDCIssuerURL), nullString(c.OIDCClientID), nullString(c.OIDCClientSecret),
+		nullString(c.SAMLMetadata),
+		c.DefaultRoleID, c.Enforce,
+	)
+	return err
+}
+
+// DeleteTeamSSOConfig removes the single sign-on configuration of a team
+func (d DB) DeleteTeamSSOConfig(ctx context.Context, teamID string) error {
+	result, err := d.Delete("team_sso_configs").
+		Where("team_id = ?", teamID).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return users.ErrNotFound
+	}
+	return nil
+}
@@ -369,15 +369,24 @@ func (d DB) generateTeamExternalID(ctx context.Context) (string, error) {
 	return externalID, err
 }
 
-// RemoveUserFromTeam removes the user from the team.
+// RemoveUserFromTeam removes the user from the team, and revokes their
+// sessions so they don't keep access through them.
 // If they are not a team member, this is a noop.
 func (d DB) RemoveUserFromTeam(ctx context.Context, userID, teamID string) error {
-	_, err := d.ExecContext(ctx,
-		"update team_memberships set deleted_at = now() where user_id = $1 and team_id = $2",
-		userID,
-		teamID,
-	)
-	return err
+	return d.Transaction(func(tx DB) error {
+		result, err := tx.ExecContext(ctx,
+			"update team_memberships set deleted_at = now() where user_id = $1 and team_id = $2 and deleted_at is null",
+			userID,
+			teamID,
+		)
+		if err != nil {
+			return err
+		}
+		if count, err := result.RowsAffected(); err != nil || count == 0 {
+			return err
+		}
+		return tx.RevokeUserSessions(ctx, userID)
+	})
 }
 
 // FindTeamByExternalID finds team by its external ID
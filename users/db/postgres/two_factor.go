@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+
+	"github.com/weaveworks/service/users"
+)
+
+// GetUserTOTP returns the two-factor enrollment of a user
+func (d DB) GetUserTOTP(ctx context.Context, userID string) (*users.UserTOTP, error) {
+	t := &users.UserTOTP{}
+	var lastFailedAt, confirmedAt pq.NullTime
+	err := d.Select(
+		"user_id", "secret", "recovery_code_hashes", "last_used_step",
+		"failed_attempts", "last_failed_at", "created_at", "confirmed_at",
+	).
+		From("user_totp").
+		Where(squirrel.Eq{"user_id": userID}).
+		QueryRowContext(ctx).
+		Scan(
+			&t.UserID, &t.Secret, pq.Array(&t.RecoveryCodeHashes), &t.LastUsedStep,
+			&t.FailedAttempts, &lastFailedAt, &t.CreatedAt, &confirmedAt,
+		)
+	if err == sql.ErrNoRows {
+		return nil, users.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lastFailedAt.Valid {
+		t.LastFailedAt = &lastFailedAt.Time
+	}
+	if confirmedAt.Valid {
+		t.ConfirmedAt = &confirmedAt.Time
+	}
+	return t, nil
+}
+
+// SetUserTOTP starts enrolling a user in two-factor authentication
+func (d DB) SetUserTOTP(ctx context.Context, userID, secret string) error {
+	_, err := d.ExecContext(ctx, `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			recovery_code_hashes = '{}',
+			last_used_step = 0,
+			failed_attempts = 0,
+			last_failed_at = null,
+			created_at = now(),
+			confirmed_at = null`,
+		userID, secret,
+	)
+	return err
+}
+
+// ConfirmUserTOTP finishes enrolling a user in two-factor authentication
+func (d DB) ConfirmUserTOTP(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error {
+	return d.updateUserTOTP(ctx, userID, map[string]interface{}{
+		"confirmed_at":         d.Now(),
+		"last_used_step":       step,
+		"recovery_code_hashes": pq.Array(recoveryCodeHashes),
+	})
+}
+
+// SetUserTOTPRecoveryCodes replaces a user's recovery codes
+func (d DB) SetUserTOTPRecoveryCodes(ctx context.Context, userID string, recoveryCodeHashes []string) error {
+	return d.updateUserTOTP(ctx, userID, map[string]interface{}{
+		"recovery_code_hashes": pq.Array(recoveryCodeHashes),
+	})
+}
+
+// UseUserTOTPStep records a successful use of the code of step
+func (d DB) UseUserTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	// Only the first use of each code succeeds, even if concurrent
+	result, err := d.Update("user_totp").
+		Set("last_used_step", step).
+		Set("failed_attempts", 0).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Lt{"last_used_step": step}).
+		ExecContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	return d.usedUserTOTP(ctx, userID, result)
+}
+
+// UseUserTOTPRecoveryCode consumes one of a user's recovery codes
+func (d DB) UseUserTOTPRecoveryCode(ctx context.Context, userID, recoveryCodeHash string) (bool, error) {
+	result, err := d.ExecContext(ctx, `
+		UPDATE user_totp SET
+			recovery_code_hashes = array_remove(recovery_code_hashes, $2),
+			failed_attempts = 0
+		WHERE user_id = $1 AND $2 = ANY(recovery_code_hashes)`,
+		userID, recoveryCodeHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	return d.usedUserTOTP(ctx, userID, result)
+}
+
+// usedUserTOTP tells whether using a user's second factor updated their
+// enrollment, distinguishing codes which didn't work from users who
+// haven't enrolled.
+func (d DB) usedUserTOTP(ctx context.Context, userID string, result sql.Result) (bool, error) {
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	if _, err := d.GetUserTOTP(ctx, userID); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// AddUserTOTPFailure counts a failed attempt at a user's second factor
+func (d DB) AddUserTOTPFailure(ctx context.Context, userID string, since time.Time) error {
+	result, err := d.ExecContext(ctx, `
+		UPDATE user_totp SET
+			failed_attempts = CASE
+				WHEN last_failed_at IS NULL OR last_failed_at < $2 THEN 1
+				ELSE failed_attempts + 1
+			END,
+			last_failed_at = now()
+		WHERE user_id = $1`,
+		userID, since,
+	)
+	if err != nil {
+		return err
+	}
+	return requireRowsAffected(result)
+}
+
+// DeleteUserTOTP removes a user's two-factor enrollment
+func (d DB) DeleteUserTOTP(ctx context.Context, userID string) error {
+	result, err := d.Delete("user_totp").
+		Where(squirrel.Eq{"user_id": userID}).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	return requireRowsAffected(result)
+}
+
+// TeamRequiresTwoFactor tells whether a team requires two-factor
+// authentication for sensitive permissions
+func (d DB) TeamRequiresTwoFactor(ctx context.Context, teamID string) (bool, error) {
+	var require bool
+	err := d.Select("require_two_factor").
+		From("teams").
+		Where(squirrel.Eq{"id": teamID}).
+		Where("deleted_at is null").
+		QueryRowContext(ctx).
+		Scan(&require)
+	if err == sql.ErrNoRows {
+		return false, users.ErrNotFound
+	}
+	return require, err
+}
+
+// SetTeamRequiresTwoFactor sets whether a team requires two-factor
+// authentication for sensitive permissions
+func (d DB) SetTeamRequiresTwoFactor(ctx context.Context, teamID string, require bool) error {
+	result, err := d.Update("teams").
+		Set("require_two_factor", require).
+		Where(squirrel.Eq{"id": teamID}).
+		Where("deleted_at is null").
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	return requireRowsAffected(result)
+}
+
+func (d DB) updateUserTOTP(ctx context.Context, userID string, fields map[string]interface{}) error {
+	result, err := d.Update("user_totp").
+		SetMap(fields).
+		Where(squirrel.Eq{"user_id": userID}).
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	return requireRowsAffected(result)
+}
+
+// requireRowsAffected returns ErrNotFound if a statement changed nothing.
+func requireRowsAffected(result sql.Result) error {
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return users.ErrNotFound
+	}
+	return nil
+}
@@ -157,7 +157,8 @@ having count(m.id)=1;
 			return err
 		}
 
-		return nil
+		// Log the user out everywhere
+		return tx.RevokeUserSessions(ctx, userID)
 	})
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/weaveworks/common/instrument"
 
+	"github.com/weaveworks/service/common/featureflag"
 	"github.com/weaveworks/service/users"
 	"github.com/weaveworks/service/users/db/filter"
 	"github.com/weaveworks/service/users/login"
@@ -492,6 +493,54 @@ func (t timed) DeleteTeam(ctx context.Context, teamID string) (err error) {
 	return
 }
 
+func (t timed) ListTeamRoles(ctx context.Context, teamID string) (r []*users.Role, err error) {
+	t.timeRequest(ctx, "ListTeamRoles", func(ctx context.Context) error {
+		r, err = t.d.ListTeamRoles(ctx, teamID)
+		return err
+	})
+	return
+}
+
+func (t timed) FindRoleByID(ctx context.Context, roleID string) (r *users.Role, err error) {
+	t.timeRequest(ctx, "FindRoleByID", func(ctx context.Context) error {
+		r, err = t.d.FindRoleByID(ctx, roleID)
+		return err
+	})
+	return
+}
+
+func (t timed) CreateTeamRole(ctx context.Context, teamID, name, description string, permissionIDs []string) (r *users.Role, err error) {
+	t.timeRequest(ctx, "CreateTeamRole", func(ctx context.Context) error {
+		r, err = t.d.CreateTeamRole(ctx, teamID, name, description, permissionIDs)
+		return err
+	})
+	return
+}
+
+func (t timed) UpdateTeamRole(ctx context.Context, teamID, roleID, name, description string, permissionIDs []string) (r *users.Role, err error) {
+	t.timeRequest(ctx, "UpdateTeamRole", func(ctx context.Context) error {
+		r, err = t.d.UpdateTeamRole(ctx, teamID, roleID, name, description, permissionIDs)
+		return err
+	})
+	return
+}
+
+func (t timed) DeleteTeamRole(ctx context.Context, teamID, roleID string) (err error) {
+	t.timeRequest(ctx, "DeleteTeamRole", func(ctx context.Context) error {
+		err = t.d.DeleteTeamRole(ctx, teamID, roleID)
+		return err
+	})
+	return
+}
+
+func (t timed) ListPermissions(ctx context.Context) (p []*users.Permission, err error) {
+	t.timeRequest(ctx, "ListPermissions", func(ctx context.Context) error {
+		p, err = t.d.ListPermissions(ctx)
+		return err
+	})
+	return
+}
+
 func (t timed) ListPermissionsForRoleID(ctx context.Context, roleID string) (p []*users.Permission, err error) {
 	t.timeRequest(ctx, "ListPermissionsForRoleID", func(ctx context.Context) error {
 		p, err = t.d.ListPermissionsForRoleID(ctx, roleID)
@@ -588,6 +637,260 @@ func (t timed) SetOrganizationWebhookFirstSeenAt(ctx context.Context, secretID s
 	return
 }
 
+func (t timed) ListAPITokens(ctx context.Context, orgExternalID string) (ts []*users.APIToken, err error) {
+	t.timeRequest(ctx, "ListAPITokens", func(ctx context.Context) error {
+		ts, err = t.d.ListAPITokens(ctx, orgExternalID)
+		return err
+	})
+	return
+}
+
+func (t timed) CreateAPIToken(ctx context.Context, orgExternalID, createdBy, name string, scopes []string, expiresAt *time.Time) (tok *users.APIToken, err error) {
+	t.timeRequest(ctx, "CreateAPIToken", func(ctx context.Context) error {
+		tok, err = t.d.CreateAPIToken(ctx, orgExternalID, createdBy, name, scopes, expiresAt)
+		return err
+	})
+	return
+}
+
+func (t timed) RevokeAPIToken(ctx context.Context, orgExternalID, tokenID string) (err error) {
+	t.timeRequest(ctx, "RevokeAPIToken", func(ctx context.Context) error {
+		err = t.d.RevokeAPIToken(ctx, orgExternalID, tokenID)
+		return err
+	})
+	return
+}
+
+func (t timed) FindAPIToken(ctx context.Context, token string) (tok *users.APIToken, err error) {
+	t.timeRequest(ctx, "FindAPIToken", func(ctx context.Context) error {
+		tok, err = t.d.FindAPIToken(ctx, token)
+		return err
+	})
+	return
+}
+
+func (t timed) SetAPITokenLastUsedAt(ctx context.Context, tokenID string, lastUsedAt time.Time) (err error) {
+	t.timeRequest(ctx, "SetAPITokenLastUsedAt", func(ctx context.Context) error {
+		err = t.d.SetAPITokenLastUsedAt(ctx, tokenID, lastUsedAt)
+		return err
+	})
+	return
+}
+
+func (t timed) CreateSession(ctx context.Context, session *users.Session) (err error) {
+	t.timeRequest(ctx, "CreateSession", func(ctx context.Context) error {
+		err = t.d.CreateSession(ctx, session)
+		return err
+	})
+	return
+}
+
+func (t timed) FindSession(ctx context.Context, sessionID string) (s *users.Session, err error) {
+	t.timeRequest(ctx, "FindSession", func(ctx context.Context) error {
+		s, err = t.d.FindSession(ctx, sessionID)
+		return err
+	})
+	return
+}
+
+func (t timed) ListSessions(ctx context.Context, userID string) (ss []*users.Session, err error) {
+	t.timeRequest(ctx, "ListSessions", func(ctx context.Context) error {
+		ss, err = t.d.ListSessions(ctx, userID)
+		return err
+	})
+	return
+}
+
+func (t timed) SetSessionLastSeenAt(ctx context.Context, sessionID string, lastSeenAt time.Time) (err error) {
+	t.timeRequest(ctx, "SetSessionLastSeenAt", func(ctx context.Context) error {
+		err = t.d.SetSessionLastSeenAt(ctx, sessionID, lastSeenAt)
+		return err
+	})
+	return
+}
+
+func (t timed) RevokeSession(ctx context.Context, userID, sessionID string) (err error) {
+	t.timeRequest(ctx, "RevokeSession", func(ctx context.Context) error {
+		err = t.d.RevokeSession(ctx, userID, sessionID)
+		return err
+	})
+	return
+}
+
+func (t timed) RevokeUserSessions(ctx context.Context, userID string) (err error) {
+	t.timeRequest(ctx, "RevokeUserSessions", func(ctx context.Context) error {
+		err = t.d.RevokeUserSessions(ctx, userID)
+		return err
+	})
+	return
+}
+
+func (t timed) GetTeamSSOConfig(ctx context.Context, teamID string) (c *users.TeamSSOConfig, err error) {
+	t.timeRequest(ctx, "GetTeamSSOConfig", func(ctx context.Context) error {
+		c, err = t.d.GetTeamSSOConfig(ctx, teamID)
+		return err
+	})
+	return
+}
+
+func (t timed) SetTeamSSOConfig(ctx context.Context, config *users.TeamSSOConfig) (err error) {
+	t.timeRequest(ctx, "SetTeamSSOConfig", func(ctx context.Context) error {
+		err = t.d.SetTeamSSOConfig(ctx, config)
+		return err
+	})
+	return
+}
+
+func (t timed) DeleteTeamSSOConfig(ctx context.Context, teamID string) (err error) {
+	t.timeRequest(ctx, "DeleteTeamSSOConfig", func(ctx context.Context) error {
+		err = t.d.DeleteTeamSSOConfig(ctx, teamID)
+		return err
+	})
+	return
+}
+
+func (t timed) CreateTeamSCIMToken(ctx context.Context, teamID, createdBy string) (token *users.TeamSCIMToken, err error) {
+	t.timeRequest(ctx, "CreateTeamSCIMToken", func(ctx context.Context) error {
+		token, err = t.d.CreateTeamSCIMToken(ctx, teamID, createdBy)
+		return err
+	})
+	return
+}
+
+func (t timed) GetTeamSCIMToken(ctx context.Context, teamID string) (token *users.TeamSCIMToken, err error) {
+	t.timeRequest(ctx, "GetTeamSCIMToken", func(ctx context.Context) error {
+		token, err = t.d.GetTeamSCIMToken(ctx, teamID)
+		return err
+	})
+	return
+}
+
+func (t timed) FindTeamSCIMToken(ctx context.Context, token string) (scimToken *users.TeamSCIMToken, err error) {
+	t.timeRequest(ctx, "FindTeamSCIMToken", func(ctx context.Context) error {
+		scimToken, err = t.d.FindTeamSCIMToken(ctx, token)
+		return err
+	})
+	return
+}
+
+func (t timed) DeleteTeamSCIMToken(ctx context.Context, teamID string) (err error) {
+	t.timeRequest(ctx, "DeleteTeamSCIMToken", func(ctx context.Context) error {
+		err = t.d.DeleteTeamSCIMToken(ctx, teamID)
+		return err
+	})
+	return
+}
+
+func (t timed) AddTeamSCIMUser(ctx context.Context, teamID, userID string) (err error) {
+	t.timeRequest(ctx, "AddTeamSCIMUser", func(ctx context.Context) error {
+		err = t.d.AddTeamSCIMUser(ctx, teamID, userID)
+		return err
+	})
+	return
+}
+
+func (t timed) IsTeamSCIMUser(ctx context.Context, teamID, userID string) (ok bool, err error) {
+	t.timeRequest(ctx, "IsTeamSCIMUser", func(ctx context.Context) error {
+		ok, err = t.d.IsTeamSCIMUser(ctx, teamID, userID)
+		return err
+	})
+	return
+}
+
+func (t timed) GetUserTOTP(ctx context.Context, userID string) (totp *users.UserTOTP, err error) {
+	t.timeRequest(ctx, "GetUserTOTP", func(ctx context.Context) error {
+		totp, err = t.d.GetUserTOTP(ctx, userID)
+		return err
+	})
+	return
+}
+
+func (t timed) SetUserTOTP(ctx context.Context, userID, secret string) (err error) {
+	t.timeRequest(ctx, "SetUserTOTP", func(ctx context.Context) error {
+		err = t.d.SetUserTOTP(ctx, userID, secret)
+		return err
+	})
+	return
+}
+
+func (t timed) ConfirmUserTOTP(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) (err error) {
+	t.timeRequest(ctx, "ConfirmUserTOTP", func(ctx context.Context) error {
+		err = t.d.ConfirmUserTOTP(ctx, userID, step, recoveryCodeHashes)
+		return err
+	})
+	return
+}
+
+func (t timed) SetUserTOTPRecoveryCodes(ctx context.Context, userID string, recoveryCodeHashes []string) (err error) {
+	t.timeRequest(ctx, "SetUserTOTPRecoveryCodes", func(ctx context.Context) error {
+		err = t.d.SetUserTOTPRecoveryCodes(ctx, userID, recoveryCodeHashes)
+		return err
+	})
+	return
+}
+
+func (t timed) UseUserTOTPStep(ctx context.Context, userID string, step int64) (ok bool, err error) {
+	t.timeRequest(ctx, "UseUserTOTPStep", func(ctx context.Context) error {
+		ok, err = t.d.UseUserTOTPStep(ctx, userID, step)
+		return err
+	})
+	return
+}
+
+func (t timed) UseUserTOTPRecoveryCode(ctx context.Context, userID, recoveryCodeHash string) (ok bool, err error) {
+	t.timeRequest(ctx, "UseUserTOTPRecoveryCode", func(ctx context.Context) error {
+		ok, err = t.d.UseUserTOTPRecoveryCode(ctx, userID, recoveryCodeHash)
+		return err
+	})
+	return
+}
+
+func (t timed) AddUserTOTPFailure(ctx context.Context, userID string, since time.Time) (err error) {
+	t.timeRequest(ctx, "AddUserTOTPFailure", func(ctx context.Context) error {
+		err = t.d.AddUserTOTPFailure(ctx, userID, since)
+		return err
+	})
+	return
+}
+
+func (t timed) DeleteUserTOTP(ctx context.Context, userID string) (err error) {
+	t.timeRequest(ctx, "DeleteUserTOTP", func(ctx context.Context) error {
+		err = t.d.DeleteUserTOTP(ctx, userID)
+		return err
+	})
+	return
+}
+
+func (t timed) TeamRequiresTwoFactor(ctx context.Context, teamID string) (require bool, err error) {
+	t.timeRequest(ctx, "TeamRequiresTwoFactor", func(ctx context.Context) error {
+		require, err = t.d.TeamRequiresTwoFactor(ctx, teamID)
+		return err
+	})
+	return
+}
+
+func (t timed) SetTeamRequiresTwoFactor(ctx context.Context, teamID string, require bool) (err error) {
+	t.timeRequest(ctx, "SetTeamRequiresTwoFactor", func(ctx context.Context) error {
+		err = t.d.SetTeamRequiresTwoFactor(ctx, teamID, require)
+		return err
+	})
+	return
+}
+
+func (t timed) InsertAuditEntry(ctx context.Context, entry *users.AuditEntry) error {
+	return t.timeRequest(ctx, "InsertAuditEntry", func(ctx context.Context) error {
+		return t.d.InsertAuditEntry(ctx, entry)
+	})
+}
+
+func (t timed) ListAuditEntries(ctx context.Context, f filter.AuditEntry, page uint64) (es []*users.AuditEntry, err error) {
+	t.timeRequest(ctx, "ListAuditEntries", func(ctx context.Context) error {
+		es, err = t.d.ListAuditEntries(ctx, f, page)
+		return err
+	})
+	return
+}
+
 func (t timed) RemoveUserFromTeam(ctx context.Context, userID, teamID string) error {
 	return t.timeRequest(ctx, "RemoveUserFromTeam", func(ctx context.Context) error {
 		return t.d.RemoveUserFromTeam(ctx, userID, teamID)
@@ -599,3 +902,107 @@ func (t timed) Close(ctx context.Context) error {
 		return t.d.Close(ctx)
 	})
 }
+
+func (t timed) ListEventWebhooks(ctx context.Context, teamID string) (webhooks []*users.EventWebhook, err error) {
+	t.timeRequest(ctx, "ListEventWebhooks", func(ctx context.Context) error {
+		webhooks, err = t.d.ListEventWebhooks(ctx, teamID)
+		return err
+	})
+	return
+}
+
+func (t timed) CreateEventWebhook(ctx context.Context, webhook *users.EventWebhook) (err error) {
+	t.timeRequest(ctx, "CreateEventWebhook", func(ctx context.Context) error {
+		err = t.d.CreateEventWebhook(ctx, webhook)
+		return err
+	})
+	return
+}
+
+func (t timed) FindEventWebhook(ctx context.Context, webhookID string) (webhook *users.EventWebhook, err error) {
+	t.timeRequest(ctx, "FindEventWebhook", func(ctx context.Context) error {
+		webhook, err = t.d.FindEventWebhook(ctx, webhookID)
+		return err
+	})
+	return
+}
+
+func (t timed) DeleteEventWebhook(ctx context.Context, teamID, webhookID string) (err error) {
+	t.timeRequest(ctx, "DeleteEventWebhook", func(ctx context.Context) error {
+		err = t.d.DeleteEventWebhook(ctx, teamID, webhookID)
+		return err
+	})
+	return
+}
+
+func (t timed) CreateEventDelivery(ctx context.Context, delivery *users.EventDelivery) (err error) {
+	t.timeRequest(ctx, "CreateEventDelivery", func(ctx context.Context) error {
+		err = t.d.CreateEventDelivery(ctx, delivery)
+		return err
+	})
+	return
+}
+
+func (t timed) ClaimEventDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (deliveries []*users.EventDelivery, err error) {
+	t.timeRequest(ctx, "ClaimEventDeliveries", func(ctx context.Context) error {
+		deliveries, err = t.d.ClaimEventDeliveries(ctx, now, lease, limit)
+		return err
+	})
+	return
+}
+
+func (t timed) UpdateEventDelivery(ctx context.Context, delivery *users.EventDelivery) (err error) {
+	t.timeRequest(ctx, "UpdateEventDelivery", func(ctx context.Context) error {
+		err = t.d.UpdateEventDelivery(ctx, delivery)
+		return err
+	})
+	return
+}
+
+func (t timed) ListEventDeliveries(ctx context.Context, webhookID string, limit int) (deliveries []*users.EventDelivery, err error) {
+	t.timeRequest(ctx, "ListEventDeliveries", func(ctx context.Context) error {
+		deliveries, err = t.d.ListEventDeliveries(ctx, webhookID, limit)
+		return err
+	})
+	return
+}
+
+func (t timed) ListFeatureFlagDefinitions(ctx context.Context) (flags []featureflag.Flag, err error) {
+	t.timeRequest(ctx, "ListFeatureFlagDefinitions", func(ctx context.Context) error {
+		flags, err = t.d.ListFeatureFlagDefinitions(ctx)
+		return err
+	})
+	return
+}
+
+func (t timed) SetFeatureFlagDefinition(ctx context.Context, flag featureflag.Flag) (err error) {
+	t.timeRequest(ctx, "SetFeatureFlagDefinition", func(ctx context.Context) error {
+		err = t.d.SetFeatureFlagDefinition(ctx, flag)
+		return err
+	})
+	return
+}
+
+func (t timed) DeleteFeatureFlagDefinition(ctx context.Context, name string) (err error) {
+	t.timeRequest(ctx, "DeleteFeatureFlagDefinition", func(ctx context.Context) error {
+		err = t.d.DeleteFeatureFlagDefinition(ctx, name)
+		return err
+	})
+	return
+}
+
+func (t timed) AddClusterProviderAccount(ctx context.Context, userID, provider, account string) (err error) {
+	t.timeRequest(ctx, "AddClusterProviderAccount", func(ctx context.Context) error {
+		err = t.d.AddClusterProviderAccount(ctx, userID, provider, account)
+		return err
+	})
+	return
+}
+
+func (t timed) ListClusterProviderAccounts(ctx context.Context, userID, provider string) (accounts []string, err error) {
+	t.timeRequest(ctx, "ListClusterProviderAccounts", func(ctx context.Context) error {
+		accounts, err = t.d.ListClusterProviderAccounts(ctx, userID, provider)
+		return err
+	})
+	return
+}
@@ -7,6 +7,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/weaveworks/service/common/featureflag"
 	"github.com/weaveworks/service/users"
 	"github.com/weaveworks/service/users/db/filter"
 	"github.com/weaveworks/service/users/login"
@@ -348,6 +349,36 @@ func (t traced) DeleteTeam(ctx context.Context, teamID string) (err error) {
 	return t.d.DeleteTeam(ctx, teamID)
 }
 
+func (t traced) ListTeamRoles(ctx context.Context, teamID string) (r []*users.Role, err error) {
+	defer t.trace("ListTeamRoles", teamID, r, err)
+	return t.d.ListTeamRoles(ctx, teamID)
+}
+
+func (t traced) FindRoleByID(ctx context.Context, roleID string) (r *users.Role, err error) {
+	defer t.trace("FindRoleByID", roleID, r, err)
+	return t.d.FindRoleByID(ctx, roleID)
+}
+
+func (t traced) CreateTeamRole(ctx context.Context, teamID, name, description string, permissionIDs []string) (r *users.Role, err error) {
+	defer t.trace("CreateTeamRole", teamID, name, description, permissionIDs, r, err)
+	return t.d.CreateTeamRole(ctx, teamID, name, description, permissionIDs)
+}
+
+func (t traced) UpdateTeamRole(ctx context.Context, teamID, roleID, name, description string, permissionIDs []string) (r *users.Role, err error) {
+	defer t.trace("UpdateTeamRole", teamID, roleID, name, description, permissionIDs, r, err)
+	return t.d.UpdateTeamRole(ctx, teamID, roleID, name, description, permissionIDs)
+}
+
+func (t traced) DeleteTeamRole(ctx context.Context, teamID, roleID string) (err error) {
+	defer t.trace("DeleteTeamRole", teamID, roleID, err)
+	return t.d.DeleteTeamRole(ctx, teamID, roleID)
+}
+
+func (t traced) ListPermissions(ctx context.Context) (os []*users.Permission, err error) {
+	defer t.trace("ListPermissions", os, err)
+	return t.d.ListPermissions(ctx)
+}
+
 func (t traced) ListPermissionsForRoleID(ctx context.Context, roleID string) (os []*users.Permission, err error) {
 	defer t.trace("ListPermissionsForRoleID", roleID, os, err)
 	return t.d.ListPermissionsForRoleID(ctx, roleID)
@@ -410,7 +441,232 @@ func (t traced) SetOrganizationWebhookFirstSeenAt(ctx context.Context, secretID
 	return t.d.SetOrganizationWebhookFirstSeenAt(ctx, secretID)
 }
 
+func (t traced) ListAPITokens(ctx context.Context, orgExternalID string) (ts []*users.APIToken, err error) {
+	defer t.trace("ListAPITokens", orgExternalID, ts, err)
+	return t.d.ListAPITokens(ctx, orgExternalID)
+}
+
+func (t traced) CreateAPIToken(ctx context.Context, orgExternalID, createdBy, name string, scopes []string, expiresAt *time.Time) (tok *users.APIToken, err error) {
+	defer t.trace("CreateAPIToken", orgExternalID, createdBy, name, scopes, expiresAt, err)
+	return t.d.CreateAPIToken(ctx, orgExternalID, createdBy, name, scopes, expiresAt)
+}
+
+func (t traced) RevokeAPIToken(ctx context.Context, orgExternalID, tokenID string) (err error) {
+	defer t.trace("RevokeAPIToken", orgExternalID, tokenID, err)
+	return t.d.RevokeAPIToken(ctx, orgExternalID, tokenID)
+}
+
+func (t traced) FindAPIToken(ctx context.Context, token string) (tok *users.APIToken, err error) {
+	defer t.trace("FindAPIToken", err)
+	return t.d.FindAPIToken(ctx, token)
+}
+
+func (t traced) SetAPITokenLastUsedAt(ctx context.Context, tokenID string, lastUsedAt time.Time) (err error) {
+	defer t.trace("SetAPITokenLastUsedAt", tokenID, lastUsedAt, err)
+	return t.d.SetAPITokenLastUsedAt(ctx, tokenID, lastUsedAt)
+}
+
+func (t traced) CreateSession(ctx context.Context, session *users.Session) (err error) {
+	defer t.trace("CreateSession", session.UserID, session.Provider, err)
+	return t.d.CreateSession(ctx, session)
+}
+
+func (t traced) FindSession(ctx context.Context, sessionID string) (s *users.Session, err error) {
+	defer t.trace("FindSession", err)
+	return t.d.FindSession(ctx, sessionID)
+}
+
+func (t traced) ListSessions(ctx context.Context, userID string) (ss []*users.Session, err error) {
+	defer t.trace("ListSessions", userID, err)
+	return t.d.ListSessions(ctx, userID)
+}
+
+func (t traced) SetSessionLastSeenAt(ctx context.Context, sessionID string, lastSeenAt time.Time) (err error) {
+	defer t.trace("SetSessionLastSeenAt", lastSeenAt, err)
+	return t.d.SetSessionLastSeenAt(ctx, sessionID, lastSeenAt)
+}
+
+func (t traced) RevokeSession(ctx context.Context, userID, sessionID string) (err error) {
+	defer t.trace("RevokeSession", userID, err)
+	return t.d.RevokeSession(ctx, userID, sessionID)
+}
+
+func (t traced) RevokeUserSessions(ctx context.Context, userID string) (err error) {
+	defer t.trace("RevokeUserSessions", userID, err)
+	return t.d.RevokeUserSessions(ctx, userID)
+}
+
+func (t traced) GetTeamSSOConfig(ctx context.Context, teamID string) (c *users.TeamSSOConfig, err error) {
+	defer t.trace("GetTeamSSOConfig", teamID, err)
+	return t.d.GetTeamSSOConfig(ctx, teamID)
+}
+
+func (t traced) SetTeamSSOConfig(ctx context.Context, config *users.TeamSSOConfig) (err error) {
+	defer t.trace("SetTeamSSOConfig", config.TeamID, config.Protocol, err)
+	return t.d.SetTeamSSOConfig(ctx, config)
+}
+
+func (t traced) DeleteTeamSSOConfig(ctx context.Context, teamID string) (err error) {
+	defer t.trace("DeleteTeamSSOConfig", teamID, err)
+	return t.d.DeleteTeamSSOConfig(ctx, teamID)
+}
+
+func (t traced) CreateTeamSCIMToken(ctx context.Context, teamID, createdBy string) (token *users.TeamSCIMToken, err error) {
+	defer t.trace("CreateTeamSCIMToken", teamID, createdBy, err)
+	return t.d.CreateTeamSCIMToken(ctx, teamID, createdBy)
+}
+
+func (t traced) GetTeamSCIMToken(ctx context.Context, teamID string) (token *users.TeamSCIMToken, err error) {
+	defer t.trace("GetTeamSCIMToken", teamID, err)
+	return t.d.GetTeamSCIMToken(ctx, teamID)
+}
+
+func (t traced) FindTeamSCIMToken(ctx context.Context, token string) (scimToken *users.TeamSCIMToken, err error) {
+	defer t.trace("FindTeamSCIMToken", err)
+	return t.d.FindTeamSCIMToken(ctx, token)
+}
+
+func (t traced) DeleteTeamSCIMToken(ctx context.Context, teamID string) (err error) {
+	defer t.trace("DeleteTeamSCIMToken", teamID, err)
+	return t.d.DeleteTeamSCIMToken(ctx, teamID)
+}
+
+func (t traced) AddTeamSCIMUser(ctx context.Context, teamID, userID string) (err error) {
+	defer t.trace("AddTeamSCIMUser", teamID, userID, err)
+	return t.d.AddTeamSCIMUser(ctx, teamID, userID)
+}
+
+func (t traced) IsTeamSCIMUser(ctx context.Context, teamID, userID string) (ok bool, err error) {
+	defer t.trace("IsTeamSCIMUser", teamID, userID, ok, err)
+	return t.d.IsTeamSCIMUser(ctx, teamID, userID)
+}
+
+func (t traced) GetUserTOTP(ctx context.Context, userID string) (totp *users.UserTOTP, err error) {
+	defer t.trace("GetUserTOTP", userID, err)
+	return t.d.GetUserTOTP(ctx, userID)
+}
+
+func (t traced) SetUserTOTP(ctx context.Context, userID, secret string) (err error) {
+	defer t.trace("SetUserTOTP", userID, err)
+	return t.d.SetUserTOTP(ctx, userID, secret)
+}
+
+func (t traced) ConfirmUserTOTP(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) (err error) {
+	defer t.trace("ConfirmUserTOTP", userID, step, err)
+	return t.d.ConfirmUserTOTP(ctx, userID, step, recoveryCodeHashes)
+}
+
+func (t traced) SetUserTOTPRecoveryCodes(ctx context.Context, userID string, recoveryCodeHashes []string) (err error) {
+	defer t.trace("SetUserTOTPRecoveryCodes", userID, err)
+	return t.d.SetUserTOTPRecoveryCodes(ctx, userID, recoveryCodeHashes)
+}
+
+func (t traced) UseUserTOTPStep(ctx context.Context, userID string, step int64) (ok bool, err error) {
+	defer t.trace("UseUserTOTPStep", userID, step, ok, err)
+	return t.d.UseUserTOTPStep(ctx, userID, step)
+}
+
+func (t traced) UseUserTOTPRecoveryCode(ctx context.Context, userID, recoveryCodeHash string) (ok bool, err error) {
+	defer t.trace("UseUserTOTPRecoveryCode", userID, ok, err)
+	return t.d.UseUserTOTPRecoveryCode(ctx, userID, recoveryCodeHash)
+}
+
+func (t traced) AddUserTOTPFailure(ctx context.Context, userID string, since time.Time) (err error) {
+	defer t.trace("AddUserTOTPFailure", userID, since, err)
+	return t.d.AddUserTOTPFailure(ctx, userID, since)
+}
+
+func (t traced) DeleteUserTOTP(ctx context.Context, userID string) (err error) {
+	defer t.trace("DeleteUserTOTP", userID, err)
+	return t.d.DeleteUserTOTP(ctx, userID)
+}
+
+func (t traced) TeamRequiresTwoFactor(ctx context.Context, teamID string) (require bool, err error) {
+	defer t.trace("TeamRequiresTwoFactor", teamID, require, err)
+	return t.d.TeamRequiresTwoFactor(ctx, teamID)
+}
+
+func (t traced) SetTeamRequiresTwoFactor(ctx context.Context, teamID string, require bool) (err error) {
+	defer t.trace("SetTeamRequiresTwoFactor", teamID, require, err)
+	return t.d.SetTeamRequiresTwoFactor(ctx, teamID, require)
+}
+
+func (t traced) InsertAuditEntry(ctx context.Context, entry *users.AuditEntry) (err error) {
+	defer t.trace("InsertAuditEntry", entry, err)
+	return t.d.InsertAuditEntry(ctx, entry)
+}
+
+func (t traced) ListAuditEntries(ctx context.Context, f filter.AuditEntry, page uint64) (es []*users.AuditEntry, err error) {
+	defer t.trace("ListAuditEntries", f, page, es, err)
+	return t.d.ListAuditEntries(ctx, f, page)
+}
+
 func (t traced) Close(ctx context.Context) (err error) {
 	defer t.trace("Close", err)
 	return t.d.Close(ctx)
 }
+
+func (t traced) ListEventWebhooks(ctx context.Context, teamID string) (webhooks []*users.EventWebhook, err error) {
+	defer t.trace("ListEventWebhooks", teamID, err)
+	return t.d.ListEventWebhooks(ctx, teamID)
+}
+
+func (t traced) CreateEventWebhook(ctx context.Context, webhook *users.EventWebhook) (err error) {
+	defer t.trace("CreateEventWebhook", webhook.TeamID, webhook.URL, err)
+	return t.d.CreateEventWebhook(ctx, webhook)
+}
+
+func (t traced) FindEventWebhook(ctx context.Context, webhookID string) (webhook *users.EventWebhook, err error) {
+	defer t.trace("FindEventWebhook", webhookID, err)
+	return t.d.FindEventWebhook(ctx, webhookID)
+}
+
+func (t traced) DeleteEventWebhook(ctx context.Context, teamID, webhookID string) (err error) {
+	defer t.trace("DeleteEventWebhook", teamID, webhookID, err)
+	return t.d.DeleteEventWebhook(ctx, teamID, webhookID)
+}
+
+func (t traced) CreateEventDelivery(ctx context.Context, delivery *users.EventDelivery) (err error) {
+	defer t.trace("CreateEventDelivery", delivery.WebhookID, delivery.EventType, err)
+	return t.d.CreateEventDelivery(ctx, delivery)
+}
+
+func (t traced) ClaimEventDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (deliveries []*users.EventDelivery, err error) {
+	defer t.trace("ClaimEventDeliveries", now, lease, limit, err)
+	return t.d.ClaimEventDeliveries(ctx, now, lease, limit)
+}
+
+func (t traced) UpdateEventDelivery(ctx context.Context, delivery *users.EventDelivery) (err error) {
+	defer t.trace("UpdateEventDelivery", delivery.ID, delivery.State, err)
+	return t.d.UpdateEventDelivery(ctx, delivery)
+}
+
+func (t traced) ListEventDeliveries(ctx context.Context, webhookID string, limit int) (deliveries []*users.EventDelivery, err error) {
+	defer t.trace("ListEventDeliveries", webhookID, limit, err)
+	return t.d.ListEventDeliveries(ctx, webhookID, limit)
+}
+
+func (t traced) ListFeatureFlagDefinitions(ctx context.Context) (flags []featureflag.Flag, err error) {
+	defer t.trace("ListFeatureFlagDefinitions", flags, err)
+	return t.d.ListFeatureFlagDefinitions(ctx)
+}
+
+func (t traced) SetFeatureFlagDefinition(ctx context.Context, flag featureflag.Flag) (err error) {
+	defer t.trace("SetFeatureFlagDefinition", flag, err)
+	return t.d.SetFeatureFlagDefinition(ctx, flag)
+}
+
+func (t traced) DeleteFeatureFlagDefinition(ctx context.Context, name string) (err error) {
+	defer t.trace("DeleteFeatureFlagDefinition", name, err)
+	return t.d.DeleteFeatureFlagDefinition(ctx, name)
+}
+
+func (t traced) AddClusterProviderAccount(ctx context.Context, userID, provider, account string) (err error) {
+	defer t.trace("AddClusterProviderAccount", userID, provider, account, err)
+	return t.d.AddClusterProviderAccount(ctx, userID, provider, account)
+}
+
+func (t traced) ListClusterProviderAccounts(ctx context.Context, userID, provider string) (accounts []string, err error) {
+	defer t.trace("ListClusterProviderAccounts", userID, provider, accounts, err)
+	return t.d.ListClusterProviderAccounts(ctx, userID, provider)
+}
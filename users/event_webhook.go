@@ -0,0 +1,94 @@
+package users
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of the events sent to teams' event webhooks.
+const (
+	EventInstanceCreated                 = "instance.created"
+	EventInstanceDeleted                 = "instance.deleted"
+	EventInstanceMoved                   = "instance.moved"
+	EventInstanceRefuseDataUploadUpdated = "instance.refuse_data_upload.updated"
+	EventTeamMemberJoined                = "team.member.joined"
+	EventTeamMemberLeft                  = "team.member.left"
+	// EventPing is only sent when testing a webhook.
+	EventPing = "ping"
+)
+
+// EventTypes lists the events webhooks can subscribe to.
+var EventTypes = []string{
+	EventInstanceCreated,
+	EventInstanceDeleted,
+	EventInstanceMoved,
+	EventInstanceRefuseDataUploadUpdated,
+	EventTeamMemberJoined,
+	EventTeamMemberLeft,
+}
+
+// States of event deliveries.
+const (
+	EventDeliveryPending   = "pending"
+	EventDeliveryDelivered = "delivered"
+	EventDeliveryFailed    = "failed"
+)
+
+// EventWebhook is a team's subscription to events, which are POSTed to its
+// URL, signed with its secret.
+type EventWebhook struct {
+	ID     string
+	TeamID string
+	URL    string
+	Secret string
+	// EventTypes are the events sent to the webhook. It is sent all events
+	// when empty.
+	EventTypes []string
+	CreatedBy  string
+	CreatedAt  time.Time
+}
+
+// Subscribes tells whether the webhook is sent events of a type.
+func (w *EventWebhook) Subscribes(eventType string) bool {
+	if eventType == EventPing || len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the payload sent to event webhooks.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	TeamID    string          `json:"teamId"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// EventDelivery is an event queued for, or sent to, an event webhook. They
+// are kept as the webhook's delivery log.
+type EventDelivery struct {
+	ID        string
+	WebhookID string
+	EventID   string
+	EventType string
+	// Payload is the JSON encoded Event.
+	Payload []byte
+	State   string
+
+	Attempts      int
+	NextAttemptAt time.Time
+	LastAttemptAt *time.Time
+	// The outcome of the last attempt: the status the webhook responded
+	// with, or why it couldn't be reached.
+	ResponseStatus int
+	LastError      string
+
+	CreatedAt   time.Time
+	DeliveredAt *time.Time
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Config configures the sending of queued events.
+type Config struct {
+	PollInterval time.Duration
+	Timeout      time.Duration
+	BatchSize    int
+	Lease        time.Duration
+
+	AllowPrivateNetworks bool
+}
+
+// RegisterFlags registers configuration variables with a flag set
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.DurationVar(&cfg.PollInterval, "event-webhooks-poll-interval", 5*time.Second, "How often to look for events to send to event webhooks.")
+	f.DurationVar(&cfg.Timeout, "event-webhooks-timeout", 10*time.Second, "How long to wait for event webhooks to respond.")
+	f.IntVar(&cfg.BatchSize, "event-webhooks-batch-size", 20, "How many events to send at a time.")
+	f.DurationVar(&cfg.Lease, "event-webhooks-lease", 5*time.Minute, "How long events being sent are hidden from other replicas. Must be longer than a batch takes to send.")
+	f.BoolVar(&cfg.AllowPrivateNetworks, "event-webhooks-allow-private-networks", false, "Send events to webhooks on loopback, private and link-local addresses. Only for local development.")
+}
+
+// Dispatcher sends the events queued in the database, in the background.
+// Replicas each run one: deliveries are leased to one at a time.
+type Dispatcher struct {
+	cfg    Config
+	db     DB
+	sender *Sender
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+// NewDispatcher makes a dispatcher, and starts it.
+func NewDispatcher(cfg Config, db DB) *Dispatcher {
+	d := &Dispatcher{
+		cfg:    cfg,
+		db:     db,
+		sender: NewSender(db, cfg.Timeout, cfg.AllowPrivateNetworks),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go d.loop()
+	return d
+}
+
+// Stop the dispatcher, waiting for the events being sent.
+func (d *Dispatcher) Stop() {
+	close(d.quit)
+	<-d.done
+}
+
+func (d *Dispatcher) loop() {
+	defer close(d.done)
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Keep going while there's a backlog
+		for d.dispatch() == d.cfg.BatchSize {
+			select {
+			case <-d.quit:
+				return
+			default:
+			}
+		}
+		select {
+		case <-d.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch sends a batch of events which are due, returning how many there
+// were.
+func (d *Dispatcher) dispatch() int {
+	ctx := context.Background()
+	deliveries, err := d.db.ClaimEventDeliveries(ctx, time.Now().UTC(), d.cfg.Lease, d.cfg.BatchSize)
+	if err != nil {
+		log.Errorf("Error claiming event deliveries: %v", err)
+		return 0
+	}
+	for _, delivery := range deliveries {
+		if err := d.sender.Deliver(ctx, delivery); err != nil {
+			log.Errorf("Error recording delivery %s of %s event: %v", delivery.ID, delivery.EventType, err)
+		}
+	}
+	return len(deliveries)
+}
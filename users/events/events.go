@@ -0,0 +1,287 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/weaveworks/service/users"
+)
+
+// Headers sent with events.
+const (
+	// SignatureHeader signs the payload with the webhook's secret. See Sign.
+	SignatureHeader = "X-Weave-Cloud-Signature"
+	EventHeader     = "X-Weave-Cloud-Event"
+	DeliveryHeader  = "X-Weave-Cloud-Delivery"
+)
+
+const (
+	// MaxAttempts is how many times an event is sent before giving up on it.
+	MaxAttempts = 10
+
+	initialBackoff = 30 * time.Second
+	maxBackoff     = 6 * time.Hour
+	// Webhooks' responses are discarded, but are read so that connections
+	// can be reused.
+	maxResponseSize = 64 * 1024
+)
+
+var (
+	deliveriesSent = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "event_webhook_deliveries",
+			Help: "Attempts at sending events to event webhooks.",
+		},
+		[]string{"status"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(deliveriesSent)
+}
+
+// DB is the part of the users database used to send events.
+type DB interface {
+	FindEventWebhook(ctx context.Context, webhookID string) (*users.EventWebhook, error)
+	ClaimEventDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*users.EventDelivery, error)
+	UpdateEventDelivery(ctx context.Context, delivery *users.EventDelivery) error
+}
+
+// Sign returns the signature header of a payload sent at a time, of the form
+// t=<unix timestamp>,v1=<signature>. The signature is the hex encoded
+// HMAC-SHA256, keyed with the webhook's secret, of the timestamp and the
+// payload joined with a '.'. Including the timestamp lets receivers reject
+// replayed events.
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, signature(secret, t, payload))
+}
+
+func signature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	io.WriteString(mac, timestamp)
+	io.WriteString(mac, ".")
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ErrInvalidSignature is returned when a payload's signature doesn't match.
+var ErrInvalidSignature = errors.New("invalid event signature")
+
+// Verify checks the signature header of a payload, as a webhook would,
+// returning the time it was signed at.
+func Verify(secret, header string, payload []byte) (time.Time, error) {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return time.Time{}, ErrInvalidSignature
+		}
+		switch kv[0] {
+		case "t":
+			t = kv[1]
+		case "v1":
+			v1 = kv[1]
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || !hmac.Equal([]byte(v1), []byte(signature(secret, t, payload))) {
+		return time.Time{}, ErrInvalidSignature
+	}
+	return time.Unix(unix, 0).UTC(), nil
+}
+
+// Backoff returns how long to wait before sending an event again, after a
+// number of failed attempts: doubling from 30 seconds, up to 6 hours.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	backoff := float64(initialBackoff) * math.Pow(2, float64(attempts-1))
+	if backoff > float64(maxBackoff) {
+		return maxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// nonPublicNetworks are the networks webhooks can't be sent to, so that
+// teams can't use them to reach our own services: loopback, private,
+// link-local (including cloud metadata services), shared, multicast and
+// reserved addresses.
+var nonPublicNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// PublicOnly is a dialer Control function refusing connections to addresses
+// in nonPublicNetworks. Checking the address being connected to, rather than
+// the requested host, also covers names resolving to such addresses.
+func PublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid address %q", host)
+	}
+	for _, n := range nonPublicNetworks {
+		if n.Contains(ip) {
+			return fmt.Errorf("address %s isn't public", ip)
+		}
+	}
+	return nil
+}
+
+// Sender sends events to webhooks, recording the outcome in the database.
+type Sender struct {
+	db     DB
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender makes a sender, which gives up on webhooks which haven't
+// responded within timeout. Webhooks are only sent to public addresses,
+// unless allowPrivateNetworks is set, and redirects aren't followed.
+func NewSender(db DB, timeout time.Duration, allowPrivateNetworks bool) *Sender {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivateNetworks {
+		dialer.Control = PublicOnly
+	}
+	return &Sender{
+		db: db,
+		client: &http.Client{
+			Timeout: timeout,
+			// No proxy, which would be what the dialer checks.
+			Transport: &http.Transport{
+				DialContext:           dialer.DialContext,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: 1 * time.Second,
+			},
+			// Redirects could lead anywhere; they are reported as failures.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Deliver makes an attempt at sending an event to its webhook, and records
+// the outcome, updating delivery. Events which couldn't be sent are retried
+// with exponential backoff, until MaxAttempts is reached. An error is only
+// returned if the outcome couldn't be recorded.
+func (s *Sender) Deliver(ctx context.Context, delivery *users.EventDelivery) error {
+	webhook, err := s.db.FindEventWebhook(ctx, delivery.WebhookID)
+	if err == users.ErrNotFound {
+		// The webhook was deleted, and its deliveries along with it
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	now := s.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus, delivery.LastError = 0, ""
+	status, err := s.send(ctx, webhook, delivery, now)
+	if err != nil {
+		delivery.LastError = err.Error()
+	}
+	delivery.ResponseStatus = status
+
+	switch {
+	case err == nil:
+		delivery.State = users.EventDeliveryDelivered
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= MaxAttempts:
+		delivery.State = users.EventDeliveryFailed
+	default:
+		delivery.State = users.EventDeliveryPending
+		delivery.NextAttemptAt = now.Add(Backoff(delivery.Attempts))
+	}
+	deliveriesSent.WithLabelValues(outcome(delivery.State)).Inc()
+
+	if err := s.db.UpdateEventDelivery(ctx, delivery); err != nil && err != users.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// outcome names the state of a delivery after an attempt, for metrics.
+func outcome(state string) string {
+	if state == users.EventDeliveryPending {
+		return "retrying"
+	}
+	return state
+}
+
+// send POSTs an event to a webhook, returning the status it responded with,
+// and an error unless that was successful.
+func (s *Sender) send(ctx context.Context, webhook *users.EventWebhook, delivery *users.EventDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Weave-Cloud-Webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, now, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxResponseSize))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
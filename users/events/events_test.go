@@ -0,0 +1,206 @@
+package events_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/users"
+	"github.com/weaveworks/service/users/db"
+	"github.com/weaveworks/service/users/db/dbtest"
+	"github.com/weaveworks/service/users/events"
+)
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"type":"ping"}`)
+	at := time.Unix(1500000000, 0).UTC()
+	header := events.Sign("secret", at, payload)
+	assert.Equal(t, "t=1500000000,v1=8aa54731458d2ae876dcc4c9712d4bfeb7b0b3f6b70d6f8835005b58ead58d57", header)
+
+	signedAt, err := events.Verify("secret", header, payload)
+	require.NoError(t, err)
+	assert.Equal(t, at, signedAt)
+	_, err = events.Verify("other", header, payload)
+	assert.Equal(t, events.ErrInvalidSignature, err)
+	_, err = events.Verify("secret", header, []byte(`{"type":"pong"}`))
+	assert.Equal(t, events.ErrInvalidSignature, err)
+	_, err = events.Verify("secret", "t=1500000001,v1=8aa54731458d2ae876dcc4c9712d4bfeb7b0b3f6b70d6f8835005b58ead58d57", payload)
+	assert.Equal(t, events.ErrInvalidSignature, err)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, events.Backoff(1))
+	assert.Equal(t, time.Minute, events.Backoff(2))
+	assert.Equal(t, 4*time.Minute, events.Backoff(4))
+	assert.Equal(t, 6*time.Hour, events.Backoff(20))
+}
+
+// receiver is a webhook, which responds with status.
+type receiver struct {
+	t       *testing.T
+	server  *httptest.Server
+	status  int
+	secret  string
+	headers []http.Header
+}
+
+func newReceiver(t *testing.T, secret string) *receiver {
+	r := &receiver{t: t, status: http.StatusOK, secret: secret}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		_, err = events.Verify(r.secret, req.Header.Get(events.SignatureHeader), body)
+		assert.NoError(t, err)
+		r.headers = append(r.headers, req.Header)
+		w.WriteHeader(r.status)
+	}))
+	return r
+}
+
+func setup(t *testing.T) (db.DB, *users.EventWebhook, *receiver) {
+	database := dbtest.Setup(t)
+	_, _, team := dbtest.GetOrgAndTeam(t, database)
+	r := newReceiver(t, "secret")
+	webhook := &users.EventWebhook{TeamID: team.ID, URL: r.server.URL, Secret: r.secret}
+	require.NoError(t, database.CreateEventWebhook(context.Background(), webhook))
+	return database, webhook, r
+}
+
+func queue(t *testing.T, database db.DB, webhook *users.EventWebhook) *users.EventDelivery {
+	delivery := &users.EventDelivery{
+		WebhookID: webhook.ID,
+		EventID:   "event",
+		EventType: users.EventPing,
+		Payload:   []byte(`{"type":"ping"}`),
+	}
+	require.NoError(t, database.CreateEventDelivery(context.Background(), delivery))
+	return delivery
+}
+
+func TestSender_Deliver(t *testing.T) {
+	database, webhook, r := setup(t)
+	defer dbtest.Cleanup(t, database)
+	defer r.server.Close()
+	ctx := context.Background()
+	sender := events.NewSender(database, time.Second, true)
+
+	delivery := queue(t, database, webhook)
+	require.NoError(t, sender.Deliver(ctx, delivery))
+	assert.Equal(t, users.EventDeliveryDelivered, delivery.State)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	assert.NotNil(t, delivery.DeliveredAt)
+	require.Len(t, r.headers, 1)
+	assert.Equal(t, users.EventPing, r.headers[0].Get(events.EventHeader))
+	assert.Equal(t, delivery.ID, r.headers[0].Get(events.DeliveryHeader))
+
+	logged, err := database.ListEventDeliveries(ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, logged, 1)
+	assert.Equal(t, users.EventDeliveryDelivered, logged[0].State)
+}
+
+func TestSender_Retries(t *testing.T) {
+	database, webhook, r := setup(t)
+	defer dbtest.Cleanup(t, database)
+	defer r.server.Close()
+	ctx := context.Background()
+	sender := events.NewSender(database, time.Second, true)
+
+	// Failures are retried later, backing off
+	r.status = http.StatusInternalServerError
+	delivery := queue(t, database, webhook)
+	require.NoError(t, sender.Deliver(ctx, delivery))
+	assert.Equal(t, users.EventDeliveryPending, delivery.State)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+	assert.Contains(t, delivery.LastError, "500")
+	require.NotNil(t, delivery.LastAttemptAt)
+	assert.Equal(t, delivery.LastAttemptAt.Add(30*time.Second), delivery.NextAttemptAt)
+	claimed, err := database.ClaimEventDeliveries(ctx, time.Now().UTC(), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+	claimed, err = database.ClaimEventDeliveries(ctx, delivery.NextAttemptAt, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	require.NoError(t, sender.Deliver(ctx, claimed[0]))
+	assert.Equal(t, 2, claimed[0].Attempts)
+	assert.Equal(t, claimed[0].LastAttemptAt.Add(time.Minute), claimed[0].NextAttemptAt)
+
+	// Unreachable webhooks are retried too
+	r.server.Close()
+	for i := 2; i < events.MaxAttempts-1; i++ {
+		require.NoError(t, sender.Deliver(ctx, claimed[0]))
+		assert.Equal(t, users.EventDeliveryPending, claimed[0].State)
+	}
+	assert.Zero(t, claimed[0].ResponseStatus)
+	assert.NotEmpty(t, claimed[0].LastError)
+
+	// ...until giving up
+	require.NoError(t, sender.Deliver(ctx, claimed[0]))
+	assert.Equal(t, events.MaxAttempts, claimed[0].Attempts)
+	assert.Equal(t, users.EventDeliveryFailed, claimed[0].State)
+	claimed, err = database.ClaimEventDeliveries(ctx, time.Now().Add(24*time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestSender_PublicOnly(t *testing.T) {
+	database, webhook, r := setup(t)
+	defer dbtest.Cleanup(t, database)
+	defer r.server.Close()
+	ctx := context.Background()
+
+	// The receiver is on loopback
+	delivery := queue(t, database, webhook)
+	require.NoError(t, events.NewSender(database, time.Second, false).Deliver(ctx, delivery))
+	assert.Equal(t, users.EventDeliveryPending, delivery.State)
+	assert.Contains(t, delivery.LastError, "isn't public")
+	assert.Empty(t, r.headers)
+
+	// Redirects aren't followed
+	redirector := httptest.NewServer(http.RedirectHandler(r.server.URL, http.StatusTemporaryRedirect))
+	defer redirector.Close()
+	redirected := &users.EventWebhook{TeamID: webhook.TeamID, URL: redirector.URL, Secret: r.secret}
+	require.NoError(t, database.CreateEventWebhook(ctx, redirected))
+	delivery = queue(t, database, redirected)
+	require.NoError(t, events.NewSender(database, time.Second, true).Deliver(ctx, delivery))
+	assert.Equal(t, users.EventDeliveryPending, delivery.State)
+	assert.Equal(t, http.StatusTemporaryRedirect, delivery.ResponseStatus)
+	assert.Empty(t, r.headers)
+}
+
+func TestDispatcher(t *testing.T) {
+	database, webhook, r := setup(t)
+	defer dbtest.Cleanup(t, database)
+	defer r.server.Close()
+
+	queue(t, database, webhook)
+	queue(t, database, webhook)
+	dispatcher := events.NewDispatcher(events.Config{
+		PollInterval: 10 * time.Millisecond,
+		Timeout:      time.Second,
+		BatchSize:    1,
+		Lease:        time.Minute,
+
+		AllowPrivateNetworks: true,
+	}, database)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deliveries, err := database.ListEventDeliveries(context.Background(), webhook.ID, 10)
+		require.NoError(t, err)
+		if deliveries[0].State == users.EventDeliveryDelivered && deliveries[1].State == users.EventDeliveryDelivered {
+			break
+		}
+		require.True(t, time.Now().Before(deadline), "events weren't sent")
+		time.Sleep(10 * time.Millisecond)
+	}
+	dispatcher.Stop()
+	assert.Len(t, r.headers, 2)
+}
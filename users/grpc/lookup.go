@@ -21,6 +21,9 @@ import (
 	"github.com/weaveworks/service/users/weeklyreports"
 )
 
+// How precisely to record when API tokens were last used.
+const apiTokenLastUsedResolution = time.Minute
+
 // usersServer implements users.UsersServer
 type usersServer struct {
 	sessions          sessions.Store
@@ -28,19 +31,32 @@ type usersServer struct {
 	emailer           emailer.Emailer
 	marketingQueues   marketing.Queues
 	forceFeatureFlags []string
+	featureFlags      *featureflag.Cache
 }
 
-// New makes a new users.UsersServer
-func New(sessions sessions.Store, db db.DB, emailer emailer.Emailer, marketingQueues marketing.Queues, forceFeatureFlags []string) users.UsersServer {
+// New makes a new users.UsersServer. Lookups resolve organizations' feature
+// flags with the rule-based flags in featureFlags.
+func New(sessions sessions.Store, db db.DB, emailer emailer.Emailer, marketingQueues marketing.Queues, forceFeatureFlags []string, featureFlags *featureflag.Cache) users.UsersServer {
 	return &usersServer{
 		sessions:          sessions,
 		db:                db,
 		emailer:           emailer,
 		marketingQueues:   marketingQueues,
 		forceFeatureFlags: forceFeatureFlags,
+		featureFlags:      featureFlags,
 	}
 }
 
+// resolveFeatureFlags returns the flags set on an organization, and those
+// which rules enable for it.
+func (a *usersServer) resolveFeatureFlags(ctx context.Context, org *users.Organization) ([]string, error) {
+	flags, err := a.featureFlags.Flags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return featureflag.Resolve(flags, org.FeatureFlagSubject()), nil
+}
+
 func authorizeAction(action users.AuthorizedAction, org *users.Organization) error {
 	switch action {
 	case users.INSTANCE_DATA_ACCESS:
@@ -58,7 +74,7 @@ func authorizeAction(action users.AuthorizedAction, org *users.Organization) err
 
 // LookupOrg authenticates a cookie for access to an org by external ID.
 func (a *usersServer) LookupOrg(ctx context.Context, req *users.LookupOrgRequest) (*users.LookupOrgResponse, error) {
-	session, err := a.sessions.Decode(req.Cookie)
+	session, err := a.sessions.Decode(ctx, req.Cookie)
 	if err != nil {
 		return nil, err
 	}
@@ -76,11 +92,15 @@ func (a *usersServer) LookupOrg(ctx context.Context, req *users.LookupOrgRequest
 			if err != nil {
 				return nil, err
 			}
+			featureFlags, err := a.resolveFeatureFlags(ctx, org)
+			if err != nil {
+				return nil, err
+			}
 
 			return &users.LookupOrgResponse{
 				OrganizationID: org.ID,
 				UserID:         session.UserID,
-				FeatureFlags:   org.FeatureFlags,
+				FeatureFlags:   featureFlags,
 			}, nil
 		}
 	}
@@ -89,7 +109,7 @@ func (a *usersServer) LookupOrg(ctx context.Context, req *users.LookupOrgRequest
 
 // LookupAdmin authenticates a cookie for admin access.
 func (a *usersServer) LookupAdmin(ctx context.Context, req *users.LookupAdminRequest) (*users.LookupAdminResponse, error) {
-	session, err := a.sessions.Decode(req.Cookie)
+	session, err := a.sessions.Decode(ctx, req.Cookie)
 	if err != nil {
 		return nil, err
 	}
@@ -108,9 +128,14 @@ func (a *usersServer) LookupAdmin(ctx context.Context, req *users.LookupAdminReq
 	}, nil
 }
 
-// LookupUsingToken authenticates a token for access to an org.
+// LookupUsingToken authenticates a token for access to an org. The token
+// is either the org's probe token, or one of its API tokens.
 func (a *usersServer) LookupUsingToken(ctx context.Context, req *users.LookupUsingTokenRequest) (*users.LookupUsingTokenResponse, error) {
 	o, err := a.db.FindOrganizationByProbeToken(ctx, req.Token)
+	var apiToken *users.APIToken
+	if err == users.ErrNotFound {
+		apiToken, o, err = a.lookupAPIToken(ctx, req)
+	}
 	if err == users.ErrNotFound {
 		err = users.ErrInvalidAuthenticationData
 	}
@@ -121,15 +146,50 @@ func (a *usersServer) LookupUsingToken(ctx context.Context, req *users.LookupUsi
 	if err != nil {
 		return nil, err
 	}
-	return &users.LookupUsingTokenResponse{
+	featureFlags, err := a.resolveFeatureFlags(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	resp := &users.LookupUsingTokenResponse{
 		OrganizationID: o.ID,
-		FeatureFlags:   o.FeatureFlags,
-	}, nil
+		FeatureFlags:   featureFlags,
+	}
+	if apiToken != nil {
+		resp.TokenExpiresAt = apiToken.ExpiresAt
+	}
+	return resp, nil
+}
+
+// lookupAPIToken finds the API token and its org, checking the token
+// is still valid and scoped for the request.
+func (a *usersServer) lookupAPIToken(ctx context.Context, req *users.LookupUsingTokenRequest) (*users.APIToken, *users.Organization, error) {
+	t, err := a.db.FindAPIToken(ctx, req.Token)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if t.Expired(now) {
+		return nil, nil, users.ErrInvalidAuthenticationData
+	}
+	if !t.Allows(req.AuthorizeFor, req.Service) {
+		return nil, nil, users.ErrForbidden
+	}
+	o, err := a.db.FindOrganizationByInternalID(ctx, t.OrganizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Only record usage every so often, so busy tokens don't cause a write per request.
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) > apiTokenLastUsedResolution {
+		if err := a.db.SetAPITokenLastUsedAt(ctx, t.ID, now); err != nil {
+			log.Warnf("failed to set last used time of API token %s: %v", t.ID, err)
+		}
+	}
+	return t, o, nil
 }
 
 // LookupUser authenticates a cookie.
 func (a *usersServer) LookupUser(ctx context.Context, req *users.LookupUserRequest) (*users.LookupUserResponse, error) {
-	session, err := a.sessions.Decode(req.Cookie)
+	session, err := a.sessions.Decode(ctx, req.Cookie)
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +225,7 @@ func (a *usersServer) GetBillableOrganizations(ctx context.Context, req *users.G
 	organizations, err := a.db.ListOrganizations(
 		ctx,
 		filter.And(
-			filter.Or(filter.ZuoraAccount(true), filter.GCPSubscription(true)),
+			filter.Or(filter.ZuoraAccount(true), filter.GCPSubscription(true), filter.TeamIDs(req.TeamIDs)),
 			filter.TrialExpiredBy(req.Now),
 			// While billing is in development, only pick orgs with ff `billing`
 			filter.HasFeatureFlag(featureflag.Billing),
@@ -31,14 +31,14 @@ var (
 
 func setup(t *testing.T) {
 	database = dbtest.Setup(t)
-	sessionStore = sessions.MustNewStore("Test-Session-Secret-Which-Is-64-Bytes-Long-aa1a166556cb719f531cd", false, "")
+	sessionStore = sessions.MustNewStore("Test-Session-Secret-Which-Is-64-Bytes-Long-aa1a166556cb719f531cd", false, "", database)
 	templates := templates.MustNewEngine("../templates")
 	smtp = emailer.SMTPEmailer{
 		Templates:   templates,
 		Domain:      "https://weave.test",
 		FromAddress: "from@weave.test",
 	}
-	server = grpc.New(sessionStore, database, &smtp, []*marketing.Queue{}, []string{})
+	server = grpc.New(sessionStore, database, &smtp, []*marketing.Queue{}, []string{}, featureflag.NewCache(database.ListFeatureFlagDefinitions, 0))
 	ctx = context.Background()
 }
 
@@ -276,6 +276,26 @@ func Test_GetBillableOrganizations_GCP(t *testing.T) {
 	}
 }
 
+// Test_GetBillableOrganizations_Teams shows that we return organizations of
+// the requested teams, billed as a whole, without a Zuora account.
+func Test_GetBillableOrganizations_Teams(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+	org := makeBillingOrganization(t)
+	makeBillingOrganization(t)
+	now := org.TrialExpiresAt.Add(5 * 24 * time.Hour)
+
+	resp, err := server.GetBillableOrganizations(ctx, &users.GetBillableOrganizationsRequest{Now: now, TeamIDs: []string{org.TeamID}})
+	require.NoError(t, err)
+	assert.Equal(t, []users.Organization{*org}, resp.Organizations)
+
+	// Their trial still applies.
+	now = org.TrialExpiresAt.Add(-5 * 24 * time.Hour)
+	resp, err = server.GetBillableOrganizations(ctx, &users.GetBillableOrganizationsRequest{Now: now, TeamIDs: []string{org.TeamID}})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Organizations)
+}
+
 // Test_GetTrialOrganizations_NotExpired shows GetTrialOrganizations returns
 // organizations that have yet to reach the end of their trial period.
 func Test_GetTrialOrganizations_NotExpired(t *testing.T) {
@@ -520,3 +540,104 @@ func TestGetSummary(t *testing.T) {
 		assert.Equal(t, org.GCP.SubscriptionStatus, entry.GCPAccountSubscriptionStatus)
 	}
 }
+
+func Test_LookupUsingToken_APIToken(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+	user, org := dbtest.GetOrg(t, database)
+
+	promToken, err := database.CreateAPIToken(ctx, org.ExternalID, user.ID, "prom", []string{users.APITokenScopeProm}, nil)
+	require.NoError(t, err)
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	readToken, err := database.CreateAPIToken(ctx, org.ExternalID, user.ID, "read", []string{users.APITokenScopeRead}, &expiresAt)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		token   string
+		action  users.AuthorizedAction
+		service string
+		err     error
+	}{
+		// The probe token can still do everything
+		{org.ProbeToken, users.INSTANCE_DATA_UPLOAD, "report", nil},
+		{org.ProbeToken, users.INSTANCE_DATA_ACCESS, "flux", nil},
+		{promToken.Token, users.INSTANCE_DATA_UPLOAD, "prom", nil},
+		{promToken.Token, users.INSTANCE_DATA_ACCESS, "prom", nil},
+		{promToken.Token, users.INSTANCE_DATA_UPLOAD, "flux", users.ErrForbidden},
+		{readToken.Token, users.INSTANCE_DATA_ACCESS, "flux", nil},
+		{readToken.Token, users.INSTANCE_DATA_UPLOAD, "prom", users.ErrForbidden},
+		{readToken.Token, users.OTHER, "prom", users.ErrForbidden},
+		{"not a token", users.INSTANCE_DATA_ACCESS, "prom", users.ErrInvalidAuthenticationData},
+	} {
+		resp, err := server.LookupUsingToken(ctx, &users.LookupUsingTokenRequest{
+			Token:        tc.token,
+			AuthorizeFor: tc.action,
+			Service:      tc.service,
+		})
+		assert.Equal(t, tc.err, err, "%s %v %s", tc.token, tc.action, tc.service)
+		if tc.err == nil {
+			assert.Equal(t, org.ID, resp.OrganizationID)
+		}
+	}
+
+	// Expiry is reported, so it can be honoured by caches
+	resp, err := server.LookupUsingToken(ctx, &users.LookupUsingTokenRequest{
+		Token:        readToken.Token,
+		AuthorizeFor: users.INSTANCE_DATA_ACCESS,
+	})
+	require.NoError(t, err)
+	assert.True(t, expiresAt.Equal(*resp.TokenExpiresAt))
+
+	// Use is recorded
+	found, err := database.FindAPIToken(ctx, promToken.Token)
+	require.NoError(t, err)
+	assert.NotNil(t, found.LastUsedAt)
+
+	// Revoked tokens are refused
+	require.NoError(t, database.RevokeAPIToken(ctx, org.ExternalID, promToken.ID))
+	_, err = server.LookupUsingToken(ctx, &users.LookupUsingTokenRequest{
+		Token:        promToken.Token,
+		AuthorizeFor: users.INSTANCE_DATA_UPLOAD,
+		Service:      "prom",
+	})
+	assert.Equal(t, users.ErrInvalidAuthenticationData, err)
+}
+
+func Test_LookupUsingToken_ExpiredAPIToken(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+	user, org := dbtest.GetOrg(t, database)
+
+	expiresAt := time.Now().Add(-time.Minute)
+	token, err := database.CreateAPIToken(ctx, org.ExternalID, user.ID, "old", []string{users.APITokenScopeUpload}, &expiresAt)
+	require.NoError(t, err)
+	_, err = server.LookupUsingToken(ctx, &users.LookupUsingTokenRequest{
+		Token:        token.Token,
+		AuthorizeFor: users.INSTANCE_DATA_UPLOAD,
+	})
+	assert.Equal(t, users.ErrInvalidAuthenticationData, err)
+}
+
+func Test_LookupUsingToken_FeatureFlagRules(t *testing.T) {
+	setup(t)
+	defer cleanup(t)
+	_, org := dbtest.GetOrg(t, database)
+	require.NoError(t, database.SetFeatureFlags(ctx, org.ExternalID, []string{"explicit:org"}))
+
+	all := uint(100)
+	for _, flag := range []featureflag.Flag{
+		{Name: "rollout", Rules: []featureflag.Rule{{Percentage: &all, Enabled: true, Value: "on"}}},
+		{Name: "explicit", Enabled: true, Value: "default"},
+		{Name: "elsewhere", Rules: []featureflag.Rule{{Teams: []string{"another-team"}, Enabled: true}}},
+	} {
+		require.NoError(t, database.SetFeatureFlagDefinition(ctx, flag))
+	}
+
+	resp, err := server.LookupUsingToken(ctx, &users.LookupUsingTokenRequest{
+		Token:        org.ProbeToken,
+		AuthorizeFor: users.INSTANCE_DATA_UPLOAD,
+	})
+	require.NoError(t, err)
+	// Flags set on the organization take precedence over rules
+	assert.Equal(t, []string{"explicit:org", "rollout:on"}, resp.FeatureFlags)
+}
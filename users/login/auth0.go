@@ -2,24 +2,15 @@ package login
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"flag"
-	"fmt"
 	"net/http"
-	"net/http/httptest"
 	"net/url"
-	"strings"
 
 	"github.com/coreos/go-oidc"
-	"github.com/justinas/nosurf"
 	"golang.org/x/oauth2"
 	"gopkg.in/auth0.v5/management"
-
-	"github.com/weaveworks/service/common"
 )
 
 var errOAuthStateMismatch = errors.New("oauth state value did not match")
@@ -112,15 +103,11 @@ func (p *Auth0Provider) OAuthConfig(connection *string) oauth2.Config {
 // This specifies which connection to use, so they should go straight to
 // e.g. google/github
 func (p *Auth0Provider) LoginURL(r *http.Request, connection string) string {
-	token := csrfToken(r)
-
-	// pass on any query parameters (ignoring duplicate keys)
-	state := common.FlattenQueryParams(r.URL.Query())
-	state["token"] = token
+	state := newState(r)
 	urlParam := oauth2.SetAuthURLParam("connection", p.configs[connection].ConnectionName)
 
 	config := p.OAuthConfig(&connection)
-	return config.AuthCodeURL(p.encodeState(state), urlParam)
+	return config.AuthCodeURL(encodeState(p.clientSecret, state), urlParam)
 }
 
 // LogoutURL constructs the URL to use to log out
@@ -180,67 +167,7 @@ func (p *Auth0Provider) Login(r *http.Request) (*Claims, json.RawMessage, map[st
 	return claims, session, extraState, err
 }
 
-// State returns a tamper-proof (but not encrypted), hmac-ed state string, including:
-// * a csrf token to match with the user's cookie
-// * maybe a uri to redirect the user to once logged in
-func (p *Auth0Provider) encodeState(raw map[string]string) string {
-	// TODO: handle this error, for now we'll just generate an invalid state.
-	j, _ := json.Marshal(raw)
-	sum := hmac.New(sha256.New, []byte(p.clientSecret)).Sum(j)
-	return fmt.Sprintf(
-		"%s:%s",
-		base64.RawURLEncoding.EncodeToString(j),
-		base64.RawURLEncoding.EncodeToString(sum),
-	)
-}
-
-func (p *Auth0Provider) decodeState(raw string) (map[string]string, error) {
-	parts := strings.SplitN(raw, ":", 2)
-	if len(parts) != 2 {
-		return nil, errOAuthStateMismatch
-	}
-
-	j, err := base64.RawURLEncoding.DecodeString(parts[0])
-	if err != nil {
-		return nil, errOAuthStateMismatch
-	}
-	sum, err := base64.RawURLEncoding.DecodeString(parts[1])
-	if err != nil {
-		return nil, errOAuthStateMismatch
-	}
-
-	expected := hmac.New(sha256.New, []byte(p.clientSecret)).Sum(j)
-	if !hmac.Equal(expected, sum) {
-		return nil, errOAuthStateMismatch
-	}
-
-	var m map[string]string
-	return m, json.Unmarshal(j, &m)
-}
-
 // VerifyState validates the token found within the state query param.
 func (p *Auth0Provider) VerifyState(r *http.Request) (map[string]string, bool) {
-	state, err := p.decodeState(r.FormValue("state"))
-	if err != nil {
-		return nil, false
-	}
-	token := state["token"]
-	delete(state, "token")
-	return state, nosurf.VerifyToken(csrfToken(r), token)
-}
-
-// csrfToken extracts the anti-CSRF token from the cookie injected by authfe.
-func csrfToken(r *http.Request) string {
-	// We cannot simply use nosurf.Token() since it assumes that the CSRFHandler is being invoked
-	// and nosurf doesn't provide another way to extract the Token directly, so we hack
-	// our way around by invoking a phony handler.
-	var token string
-	handler := func(w http.ResponseWriter, r *http.Request) {
-		token = nosurf.Token(r)
-	}
-	phonyCSRFHandler := nosurf.New(http.HandlerFunc(handler))
-	phonyCSRFHandler.ExemptFunc(func(r *http.Request) bool { return true })
-	phonyResponseWriter := httptest.NewRecorder()
-	phonyCSRFHandler.ServeHTTP(phonyResponseWriter, r)
-	return token
+	return verifyState(p.clientSecret, r)
 }
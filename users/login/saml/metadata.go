@@ -0,0 +1,96 @@
+package saml
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+// SAML bindings and name ID formats.
+const (
+	redirectBinding   = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	postBinding       = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	emailNameIDFormat = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+)
+
+// IdentityProvider is a SAML identity provider, as described by its metadata.
+type IdentityProvider struct {
+	EntityID string
+	// SSOURL is where users are sent to log in, with the HTTP-Redirect binding.
+	SSOURL string
+	// Certificates are those the identity provider signs with.
+	Certificates []*x509.Certificate
+}
+
+type entityDescriptor struct {
+	XMLName           xml.Name `xml:"EntityDescriptor"`
+	EntityID          string   `xml:"entityID,attr"`
+	IDPSSODescriptors []struct {
+		KeyDescriptors []struct {
+			Use          string   `xml:"use,attr"`
+			Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+		} `xml:"KeyDescriptor"`
+		SingleSignOnServices []struct {
+			Binding  string `xml:"Binding,attr"`
+			Location string `xml:"Location,attr"`
+		} `xml:"SingleSignOnService"`
+	} `xml:"IDPSSODescriptor"`
+}
+
+// ParseMetadata parses the metadata of an identity provider.
+func ParseMetadata(data []byte) (*IdentityProvider, error) {
+	var metadata entityDescriptor
+	if err := xml.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("saml: invalid metadata: %v", err)
+	}
+	if metadata.EntityID == "" {
+		return nil, errors.New("saml: metadata has no entityID")
+	}
+	idp := &IdentityProvider{EntityID: metadata.EntityID}
+	for _, d := range metadata.IDPSSODescriptors {
+		for _, k := range d.KeyDescriptors {
+			if k.Use != "" && k.Use != "signing" {
+				continue
+			}
+			for _, c := range k.Certificates {
+				der, err := decodeBase64(c)
+				if err != nil {
+					return nil, fmt.Errorf("saml: invalid certificate in metadata: %v", err)
+				}
+				cert, err := x509.ParseCertificate(der)
+				if err != nil {
+					return nil, fmt.Errorf("saml: invalid certificate in metadata: %v", err)
+				}
+				idp.Certificates = append(idp.Certificates, cert)
+			}
+		}
+		for _, s := range d.SingleSignOnServices {
+			if s.Binding == redirectBinding && idp.SSOURL == "" {
+				idp.SSOURL = s.Location
+			}
+		}
+	}
+	if idp.SSOURL == "" {
+		return nil, errors.New("saml: metadata has no HTTP-Redirect single sign-on service")
+	}
+	if len(idp.Certificates) == 0 {
+		return nil, errors.New("saml: metadata has no signing certificates")
+	}
+	return idp, nil
+}
+
+// Metadata returns the metadata of the service provider, for configuring
+// identity providers.
+func (sp *ServiceProvider) Metadata() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="`)
+	xml.EscapeText(&buf, []byte(sp.EntityID))
+	buf.WriteString(`"><md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="` + protocolNamespace + `">`)
+	buf.WriteString(`<md:NameIDFormat>` + emailNameIDFormat + `</md:NameIDFormat>`)
+	buf.WriteString(`<md:AssertionConsumerService Binding="` + postBinding + `" Location="`)
+	xml.EscapeText(&buf, []byte(sp.ACSURL))
+	buf.WriteString(`" index="0" isDefault="true"/></md:SPSSODescriptor></md:EntityDescriptor>`)
+	return buf.Bytes()
+}
@@ -0,0 +1,341 @@
+// Package saml implements the parts of SAML 2.0 web browser single sign-on
+// needed to log users in through their identity provider: sending
+// authentication requests with the HTTP-Redirect binding, and checking
+// signed responses received with the HTTP-POST binding. Signatures are
+// checked with goxmldsig.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+const (
+	protocolNamespace  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	assertionNamespace = "urn:oasis:names:tc:SAML:2.0:assertion"
+	statusSuccess      = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	bearerMethod       = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+// clockSkew is how far our clock may differ from identity providers'.
+const clockSkew = time.Minute
+
+// ErrInvalidSignature is returned when a signature doesn't match the signed
+// content, or isn't by any trusted certificate.
+var ErrInvalidSignature = errors.New("saml: invalid signature")
+
+// ServiceProvider is us, as known to an identity provider.
+type ServiceProvider struct {
+	EntityID string
+	// ACSURL is the assertion consumer service URL, which identity providers
+	// POST responses to.
+	ACSURL string
+	IDP    *IdentityProvider
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (sp *ServiceProvider) now() time.Time {
+	if sp.Now != nil {
+		return sp.Now()
+	}
+	return time.Now()
+}
+
+// AuthnRequest is a request to an identity provider to log a user in.
+type AuthnRequest struct {
+	ID     string
+	Issuer string
+	ACSURL string
+}
+
+type authnRequest struct {
+	XMLName      xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID           string   `xml:"ID,attr"`
+	Version      string   `xml:"Version,attr"`
+	IssueInstant string   `xml:"IssueInstant,attr"`
+	Destination  string   `xml:"Destination,attr"`
+	ACSURL       string   `xml:"AssertionConsumerServiceURL,attr"`
+	Binding      string   `xml:"ProtocolBinding,attr"`
+	Issuer       string   `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+}
+
+// NewID returns a random ID for a SAML message.
+func NewID() string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	// IDs must not start with a digit
+	return "_" + hex.EncodeToString(b)
+}
+
+// AuthnRequestURL returns the URL to send a user to, to log in with the
+// identity provider. relayState is returned with the response.
+func (sp *ServiceProvider) AuthnRequestURL(relayState string) (string, error) {
+	req, err := xml.Marshal(authnRequest{
+		ID:           NewID(),
+		Version:      "2.0",
+		IssueInstant: sp.now().UTC().Format(time.RFC3339),
+		Destination:  sp.IDP.SSOURL,
+		ACSURL:       sp.ACSURL,
+		Binding:      postBinding,
+		Issuer:       sp.EntityID,
+	})
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+	w.Write(req)
+	w.Close()
+
+	u, err := url.Parse(sp.IDP.SSOURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// ParseAuthnRequest decodes a request sent with the HTTP-Redirect binding,
+// as an identity provider does.
+func ParseAuthnRequest(encoded string) (*AuthnRequest, error) {
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		return nil, err
+	}
+	var req authnRequest
+	if err := xml.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	return &AuthnRequest{ID: req.ID, Issuer: req.Issuer, ACSURL: req.ACSURL}, nil
+}
+
+// Assertion is what an identity provider asserts about a user who has
+// logged in.
+type Assertion struct {
+	NameID     string
+	Attributes map[string][]string
+}
+
+// ParseResponse checks a response POSTed by the identity provider, and
+// returns its assertion. The assertion, or the whole response, must be
+// signed by the identity provider, for us, and be current.
+//
+// Assertions aren't remembered, so one could be replayed until it expires.
+// Identity providers typically only make them valid for a few minutes.
+func (sp *ServiceProvider) ParseResponse(encoded string) (*Assertion, error) {
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("saml: invalid response: %v", err)
+	}
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, fmt.Errorf("saml: invalid response: %v", err)
+	}
+	for _, t := range doc.Child {
+		if _, ok := t.(*etree.Directive); ok {
+			return nil, errors.New("saml: invalid response: directives aren't allowed")
+		}
+	}
+	response := doc.Root()
+	if response == nil || !is(response, protocolNamespace, "Response") {
+		return nil, errors.New("saml: not a response")
+	}
+
+	// Either the response or its assertion must be signed. Only what was
+	// signed is read from here on.
+	responseSigned := false
+	if findChild(response, dsig.Namespace, dsig.SignatureTag) != nil {
+		if response, err = sp.verify(response); err != nil {
+			return nil, err
+		}
+		responseSigned = true
+	}
+	assertions := findChildren(response, assertionNamespace, "Assertion")
+	if len(assertions) != 1 {
+		return nil, errors.New("saml: response must have exactly one assertion")
+	}
+	assertion := assertions[0]
+	if findChild(assertion, dsig.Namespace, dsig.SignatureTag) != nil {
+		if assertion, err = sp.verify(assertion); err != nil {
+			return nil, err
+		}
+	} else if !responseSigned {
+		return nil, errors.New("saml: response isn't signed")
+	}
+
+	if destination := response.SelectAttrValue("Destination", ""); destination != "" && destination != sp.ACSURL {
+		return nil, fmt.Errorf("saml: response is for %q", destination)
+	}
+	status := findChild(response, protocolNamespace, "Status")
+	if status == nil {
+		return nil, errors.New("saml: response has no status")
+	}
+	if code := findChild(status, protocolNamespace, "StatusCode"); code == nil || code.SelectAttrValue("Value", "") != statusSuccess {
+		return nil, errors.New("saml: login failed")
+	}
+	return sp.checkAssertion(assertion)
+}
+
+// verify checks the enveloped signature of e was made by one of the identity
+// provider's certificates. It returns what was signed: e without its
+// signature. Only the returned element should be trusted, so that nothing
+// unsigned, e.g. wrapped around the signed content, can be read.
+func (sp *ServiceProvider) verify(e *etree.Element) (*etree.Element, error) {
+	// Namespaces declared by ancestors are in scope of what was signed.
+	ctx, err := etreeutils.NSBuildParentContext(e)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = ctx.SubContext(e)
+	if err != nil {
+		return nil, err
+	}
+	detached, err := etreeutils.NSDetatch(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	validation := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: sp.IDP.Certificates})
+	validation.Clock = dsig.NewFakeClockAt(sp.now())
+	signed, err := validation.Validate(detached)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return signed, nil
+}
+
+func (sp *ServiceProvider) checkAssertion(assertion *etree.Element) (*Assertion, error) {
+	now := sp.now()
+	if issuer := findChild(assertion, assertionNamespace, "Issuer"); issuer == nil || issuer.Text() != sp.IDP.EntityID {
+		return nil, errors.New("saml: assertion isn't from the identity provider")
+	}
+
+	conditions := findChild(assertion, assertionNamespace, "Conditions")
+	if conditions == nil {
+		return nil, errors.New("saml: assertion has no conditions")
+	}
+	if err := checkTimes(conditions, now); err != nil {
+		return nil, err
+	}
+	audienceOK := false
+	for _, restriction := range findChildren(conditions, assertionNamespace, "AudienceRestriction") {
+		for _, audience := range findChildren(restriction, assertionNamespace, "Audience") {
+			audienceOK = audienceOK || audience.Text() == sp.EntityID
+		}
+	}
+	if !audienceOK {
+		return nil, errors.New("saml: assertion isn't for us")
+	}
+
+	subject := findChild(assertion, assertionNamespace, "Subject")
+	if subject == nil {
+		return nil, errors.New("saml: assertion has no subject")
+	}
+	confirmed := false
+	for _, confirmation := range findChildren(subject, assertionNamespace, "SubjectConfirmation") {
+		data := findChild(confirmation, assertionNamespace, "SubjectConfirmationData")
+		if confirmation.SelectAttrValue("Method", "") != bearerMethod || data == nil {
+			continue
+		}
+		if data.SelectAttrValue("Recipient", "") != sp.ACSURL || data.SelectAttrValue("NotOnOrAfter", "") == "" || checkTimes(data, now) != nil {
+			continue
+		}
+		confirmed = true
+	}
+	if !confirmed {
+		return nil, errors.New("saml: subject isn't confirmed")
+	}
+	nameID := findChild(subject, assertionNamespace, "NameID")
+	if nameID == nil || nameID.Text() == "" {
+		return nil, errors.New("saml: subject has no NameID")
+	}
+
+	result := &Assertion{NameID: nameID.Text(), Attributes: map[string][]string{}}
+	for _, statement := range findChildren(assertion, assertionNamespace, "AttributeStatement") {
+		for _, attribute := range findChildren(statement, assertionNamespace, "Attribute") {
+			name := attribute.SelectAttrValue("Name", "")
+			for _, value := range findChildren(attribute, assertionNamespace, "AttributeValue") {
+				result.Attributes[name] = append(result.Attributes[name], value.Text())
+			}
+		}
+	}
+	return result, nil
+}
+
+// checkTimes checks now is within the NotBefore and NotOnOrAfter of e, if
+// it has them.
+func checkTimes(e *etree.Element, now time.Time) error {
+	if notBefore := e.SelectAttrValue("NotBefore", ""); notBefore != "" {
+		t, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			return fmt.Errorf("saml: invalid NotBefore: %v", err)
+		}
+		if now.Add(clockSkew).Before(t) {
+			return errors.New("saml: assertion isn't valid yet")
+		}
+	}
+	if notOnOrAfter := e.SelectAttrValue("NotOnOrAfter", ""); notOnOrAfter != "" {
+		t, err := time.Parse(time.RFC3339, notOnOrAfter)
+		if err != nil {
+			return fmt.Errorf("saml: invalid NotOnOrAfter: %v", err)
+		}
+		if !now.Add(-clockSkew).Before(t) {
+			return errors.New("saml: assertion has expired")
+		}
+	}
+	return nil
+}
+
+// is returns whether e is the element local in namespace.
+func is(e *etree.Element, namespace, local string) bool {
+	return e.Tag == local && e.NamespaceURI() == namespace
+}
+
+// findChild returns the first child element of e which is local in
+// namespace, or nil if there is none.
+func findChild(e *etree.Element, namespace, local string) *etree.Element {
+	if children := findChildren(e, namespace, local); len(children) > 0 {
+		return children[0]
+	}
+	return nil
+}
+
+// findChildren returns the child elements of e which are local in namespace.
+func findChildren(e *etree.Element, namespace, local string) []*etree.Element {
+	var children []*etree.Element
+	for _, c := range e.ChildElements() {
+		if is(c, namespace, local) {
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// decodeBase64 decodes base64 which may be wrapped over several lines.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
@@ -0,0 +1,125 @@
+package saml_test
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/weaveworks/service/users/login"
+	"github.com/weaveworks/service/users/login/saml"
+	"github.com/weaveworks/service/users/login/stubidp"
+)
+
+const (
+	acsURL   = "https://weave.test/api/users/sso/team/callback"
+	entityID = "https://weave.test/api/users/sso/team/metadata"
+)
+
+func setup(t *testing.T) (*stubidp.IdP, *saml.ServiceProvider) {
+	idp, err := stubidp.New("https://idp.test", login.Claims{ID: "alice@example.com", Email: "alice@example.com"})
+	require.NoError(t, err)
+	metadata, err := saml.ParseMetadata(idp.SAMLMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, "https://idp.test/saml/sso", metadata.SSOURL)
+	return idp, &saml.ServiceProvider{EntityID: entityID, ACSURL: acsURL, IDP: metadata}
+}
+
+func decode(t *testing.T, response string) string {
+	data, err := base64.StdEncoding.DecodeString(response)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func encode(response string) string {
+	return base64.StdEncoding.EncodeToString([]byte(response))
+}
+
+func TestParseResponse(t *testing.T) {
+	idp, sp := setup(t)
+
+	response, err := idp.SAMLResponse(acsURL, entityID, "")
+	require.NoError(t, err)
+	assertion, err := sp.ParseResponse(response)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", assertion.NameID)
+	assert.Equal(t, []string{"alice@example.com"}, assertion.Attributes["email"])
+
+	// Expired
+	sp.Now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, err = sp.ParseResponse(response)
+	assert.Error(t, err)
+	sp.Now = nil
+
+	// For someone else
+	other, err := idp.SAMLResponse(acsURL, "https://elsewhere.test", "")
+	require.NoError(t, err)
+	_, err = sp.ParseResponse(other)
+	assert.Error(t, err)
+	other, err = idp.SAMLResponse("https://elsewhere.test/acs", entityID, "")
+	require.NoError(t, err)
+	_, err = sp.ParseResponse(other)
+	assert.Error(t, err)
+
+	// From another identity provider
+	otherIdP, _ := setup(t)
+	other, err = otherIdP.SAMLResponse(acsURL, entityID, "")
+	require.NoError(t, err)
+	_, err = sp.ParseResponse(other)
+	assert.Equal(t, saml.ErrInvalidSignature, err)
+}
+
+func TestParseResponseTampering(t *testing.T) {
+	idp, sp := setup(t)
+	response, err := idp.SAMLResponse(acsURL, entityID, "")
+	require.NoError(t, err)
+	doc := decode(t, response)
+
+	// Changing what was signed
+	tampered := strings.Replace(doc, "<saml:AttributeValue>alice@example.com", "<saml:AttributeValue>mallory@example.com", 1)
+	require.NotEqual(t, doc, tampered)
+	_, err = sp.ParseResponse(encode(tampered))
+	assert.Equal(t, saml.ErrInvalidSignature, err)
+
+	// Removing the signature
+	start, end := strings.Index(doc, "<ds:Signature"), strings.Index(doc, "</ds:Signature>")+len("</ds:Signature>")
+	_, err = sp.ParseResponse(encode(doc[:start] + doc[end:]))
+	assert.Error(t, err)
+
+	// Adding an unsigned assertion alongside the signed one
+	assertionStart := strings.Index(doc, "<saml:Assertion")
+	unsigned := strings.Replace(doc[assertionStart:start]+doc[end:strings.Index(doc, "</samlp:Response>")], "alice@", "mallory@", -1)
+	wrapped := doc[:assertionStart] + unsigned + doc[assertionStart:]
+	_, err = sp.ParseResponse(encode(wrapped))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exactly one assertion")
+}
+
+func TestParseResponseMalformed(t *testing.T) {
+	_, sp := setup(t)
+	for _, response := range []string{
+		`<!DOCTYPE foo [<!ENTITY x "y">]><samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol">&x;</samlp:Response>`,
+		`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol"></samlp:Request>`,
+		`<foo/>`,
+	} {
+		_, err := sp.ParseResponse(encode(response))
+		assert.Error(t, err, response)
+	}
+}
+
+func TestAuthnRequest(t *testing.T) {
+	_, sp := setup(t)
+	u, err := sp.AuthnRequestURL("state")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(u, "https://idp.test/saml/sso?"))
+
+	var metadata struct {
+		EntityID string `xml:"entityID,attr"`
+	}
+	require.NoError(t, xml.Unmarshal(sp.Metadata(), &metadata))
+	assert.Equal(t, entityID, metadata.EntityID)
+}
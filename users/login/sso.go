@@ -0,0 +1,204 @@
+package login
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/weaveworks/service/common"
+	"github.com/weaveworks/service/users/login/saml"
+)
+
+var errNotSupportedBySSO = errors.New("not supported for single sign-on logins")
+
+// ssoProvider implements the parts of Provider which a team's own identity
+// provider doesn't support: it only logs its users in.
+type ssoProvider struct{}
+
+// LogoutURL returns to our login page. Users stay logged in to their
+// identity provider.
+func (ssoProvider) LogoutURL(r *http.Request) string {
+	return "/login"
+}
+
+// InviteUser isn't supported: users are provisioned when they first log in.
+func (ssoProvider) InviteUser(email string, inviter string, teamName string) error {
+	return errNotSupportedBySSO
+}
+
+// UpdateClaims isn't supported: claims are managed by the identity provider.
+func (ssoProvider) UpdateClaims(ctx context.Context, claims Claims, session json.RawMessage) error {
+	return errNotSupportedBySSO
+}
+
+// PasswordlessLogin isn't supported.
+func (ssoProvider) PasswordlessLogin(r *http.Request, email string) error {
+	return errNotSupportedBySSO
+}
+
+// GetAccessToken isn't supported.
+func (ssoProvider) GetAccessToken(string) (*string, error) {
+	return nil, errNotSupportedBySSO
+}
+
+// OIDCProvider logs users in through an OpenID Connect identity provider,
+// e.g. for a team's single sign-on.
+type OIDCProvider struct {
+	ssoProvider
+	config      oauth2.Config
+	verifier    *oidc.IDTokenVerifier
+	client      *http.Client
+	stateSecret string
+}
+
+// NewOIDCProvider discovers the identity provider at issuerURL, talking to
+// it with client. Users return from it to redirectURL. stateSecret protects
+// the state passed through the identity provider.
+func NewOIDCProvider(ctx context.Context, client *http.Client, issuerURL, clientID, clientSecret, redirectURL, stateSecret string) (*OIDCProvider, error) {
+	ctx = oidc.ClientContext(ctx, client)
+	provider, err := oidc.NewProvider(ctx, issuerURL)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCProvider{
+		config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  redirectURL,
+			Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+		},
+		verifier:    provider.Verifier(&oidc.Config{ClientID: clientID}),
+		client:      client,
+		stateSecret: stateSecret,
+	}, nil
+}
+
+// LoginURL constructs the URL to send users to, to log in with the identity
+// provider. There is only one connection, so it's ignored.
+func (p *OIDCProvider) LoginURL(r *http.Request, connection string) string {
+	return p.config.AuthCodeURL(encodeState(p.stateSecret, newState(r)))
+}
+
+// Login is called after the user has authenticated with the identity
+// provider. It turns that request into a set of claims.
+func (p *OIDCProvider) Login(r *http.Request) (*Claims, json.RawMessage, map[string]string, error) {
+	extraState, ok := verifyState(p.stateSecret, r)
+	if !ok {
+		return nil, nil, nil, errOAuthStateMismatch
+	}
+	ctx := oidc.ClientContext(r.Context(), p.client)
+	tok, err := p.config.Exchange(ctx, r.FormValue("code"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rawIDToken, ok := tok.Extra("id_token").(string)
+	if !ok {
+		return nil, nil, nil, errOidcNoIDToken
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var claims Claims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, nil, nil, err
+	}
+	session, err := json.Marshal(OIDCUserSession{Token: tok, IDToken: rawIDToken})
+	return &claims, session, extraState, err
+}
+
+// Attributes identity providers commonly assert users' details with.
+var (
+	samlEmailAttributes      = []string{"email", "mail", "urn:oid:0.9.2342.19200300.100.1.3", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress"}
+	samlGivenNameAttributes  = []string{"givenName", "firstName", "urn:oid:2.5.4.42", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname"}
+	samlFamilyNameAttributes = []string{"sn", "surname", "lastName", "urn:oid:2.5.4.4", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/surname"}
+	samlNameAttributes       = []string{"displayName", "name", "urn:oid:2.16.840.1.113730.3.1.241", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name"}
+)
+
+// SAMLProvider logs users in through a SAML 2.0 identity provider, e.g. for
+// a team's single sign-on.
+type SAMLProvider struct {
+	ssoProvider
+	sp          saml.ServiceProvider
+	stateSecret string
+}
+
+// NewSAMLProvider configures us as service provider entityID for the
+// identity provider described by metadata. Responses are POSTed to acsURL.
+// stateSecret protects the state passed through the identity provider.
+func NewSAMLProvider(metadata []byte, entityID, acsURL, stateSecret string) (*SAMLProvider, error) {
+	idp, err := saml.ParseMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &SAMLProvider{
+		sp:          saml.ServiceProvider{EntityID: entityID, ACSURL: acsURL, IDP: idp},
+		stateSecret: stateSecret,
+	}, nil
+}
+
+// Metadata describes us to the identity provider.
+func (p *SAMLProvider) Metadata() []byte {
+	return p.sp.Metadata()
+}
+
+// LoginURL constructs the URL to send users to, to log in with the identity
+// provider. There is only one connection, so it's ignored.
+func (p *SAMLProvider) LoginURL(r *http.Request, connection string) string {
+	// Identity providers POST responses back, so browsers won't send our
+	// csrf cookie with them; the relay state can't include a csrf token.
+	u, err := p.sp.AuthnRequestURL(encodeState(p.stateSecret, common.FlattenQueryParams(r.URL.Query())))
+	if err != nil {
+		return ""
+	}
+	return u
+}
+
+// Login is called with the response the identity provider POSTed once the
+// user authenticated. It turns that request into a set of claims. Logins
+// started at the identity provider have no relay state.
+func (p *SAMLProvider) Login(r *http.Request) (*Claims, json.RawMessage, map[string]string, error) {
+	assertion, err := p.sp.ParseResponse(r.FormValue("SAMLResponse"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	extraState := map[string]string{}
+	if relayState := r.FormValue("RelayState"); relayState != "" {
+		if extraState, err = decodeState(p.stateSecret, relayState); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	attribute := func(names []string) string {
+		for _, name := range names {
+			if values := assertion.Attributes[name]; len(values) > 0 {
+				return values[0]
+			}
+		}
+		return ""
+	}
+	claims := &Claims{
+		ID:         assertion.NameID,
+		Email:      attribute(samlEmailAttributes),
+		Name:       attribute(samlNameAttributes),
+		GivenName:  attribute(samlGivenNameAttributes),
+		FamilyName: attribute(samlFamilyNameAttributes),
+	}
+	if claims.Email == "" && strings.Contains(assertion.NameID, "@") {
+		claims.Email = assertion.NameID
+	}
+	// Identity providers vouch for their users' emails.
+	claims.Verified = claims.Email != ""
+	return claims, nil, extraState, nil
+}
+
+var (
+	_ Provider = &OIDCProvider{}
+	_ Provider = &SAMLProvider{}
+)
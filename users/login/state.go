@@ -0,0 +1,96 @@
+package login
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/justinas/nosurf"
+
+	"github.com/weaveworks/service/common"
+)
+
+// newState returns the state to round-trip through a login provider: the
+// request's query parameters, and a csrf token to match with the user's
+// cookie.
+func newState(r *http.Request) map[string]string {
+	// pass on any query parameters (ignoring duplicate keys)
+	state := common.FlattenQueryParams(r.URL.Query())
+	state["token"] = csrfToken(r)
+	return state
+}
+
+// encodeState returns a tamper-proof (but not encrypted), hmac-ed state string, including:
+// * a csrf token to match with the user's cookie
+// * maybe a uri to redirect the user to once logged in
+func encodeState(secret string, raw map[string]string) string {
+	// TODO: handle this error, for now we'll just generate an invalid state.
+	j, _ := json.Marshal(raw)
+	return fmt.Sprintf(
+		"%s:%s",
+		base64.RawURLEncoding.EncodeToString(j),
+		base64.RawURLEncoding.EncodeToString(stateMAC(secret, j)),
+	)
+}
+
+// stateMAC authenticates an encoded state with secret.
+func stateMAC(secret string, j []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(j)
+	return mac.Sum(nil)
+}
+
+func decodeState(secret, raw string) (map[string]string, error) {
+	parts := strings.SplitN(raw, ":", 2)
+	if len(parts) != 2 {
+		return nil, errOAuthStateMismatch
+	}
+
+	j, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errOAuthStateMismatch
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errOAuthStateMismatch
+	}
+
+	if !hmac.Equal(stateMAC(secret, j), sum) {
+		return nil, errOAuthStateMismatch
+	}
+
+	var m map[string]string
+	return m, json.Unmarshal(j, &m)
+}
+
+// verifyState validates the token found within the state query param.
+func verifyState(secret string, r *http.Request) (map[string]string, bool) {
+	state, err := decodeState(secret, r.FormValue("state"))
+	if err != nil {
+		return nil, false
+	}
+	token := state["token"]
+	delete(state, "token")
+	return state, nosurf.VerifyToken(csrfToken(r), token)
+}
+
+// csrfToken extracts the anti-CSRF token from the cookie injected by authfe.
+func csrfToken(r *http.Request) string {
+	// We cannot simply use nosurf.Token() since it assumes that the CSRFHandler is being invoked
+	// and nosurf doesn't provide another way to extract the Token directly, so we hack
+	// our way around by invoking a phony handler.
+	var token string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		token = nosurf.Token(r)
+	}
+	phonyCSRFHandler := nosurf.New(http.HandlerFunc(handler))
+	phonyCSRFHandler.ExemptFunc(func(r *http.Request) bool { return true })
+	phonyResponseWriter := httptest.NewRecorder()
+	phonyCSRFHandler.ServeHTTP(phonyResponseWriter, r)
+	return token
+}
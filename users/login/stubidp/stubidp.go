@@ -0,0 +1,371 @@
+// Package stubidp is an identity provider to test single sign-on against,
+// in tests or locally. It speaks both OpenID Connect and SAML, and logs
+// everyone straight in as its configured user, without asking who they are.
+package stubidp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	htmltemplate "html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/gorilla/mux"
+	dsig "github.com/russellhaering/goxmldsig"
+	jose "gopkg.in/square/go-jose.v2"
+
+	"github.com/weaveworks/service/users/login"
+	"github.com/weaveworks/service/users/login/saml"
+)
+
+// Credentials of the OpenID Connect client the stub identity provider knows.
+const (
+	ClientID     = "stub-client"
+	ClientSecret = "stub-secret"
+)
+
+const keyID = "stub"
+
+// IdP is a stub identity provider.
+type IdP struct {
+	// URL the identity provider is served at, which is its OpenID Connect
+	// issuer and SAML entity ID.
+	URL string
+
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+
+	mtx   sync.Mutex
+	user  login.Claims
+	codes map[string]string // map[code]nonce
+}
+
+// New creates a stub identity provider to be served at url, logging everyone
+// in as user.
+func New(url string, user login.Claims) (*IdP, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "stub identity provider"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &IdP{URL: url, key: key, cert: cert, user: user, codes: map[string]string{}}, nil
+}
+
+// SetUser changes who everyone is logged in as.
+func (p *IdP) SetUser(user login.Claims) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.user = user
+}
+
+func (p *IdP) currentUser() login.Claims {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.user
+}
+
+// Handler serves the identity provider.
+func (p *IdP) Handler() http.Handler {
+	r := mux.NewRouter()
+	r.Path("/.well-known/openid-configuration").Methods("GET").HandlerFunc(p.discovery)
+	r.Path("/oidc/authorize").Methods("GET").HandlerFunc(p.authorize)
+	r.Path("/oidc/token").Methods("POST").HandlerFunc(p.token)
+	r.Path("/oidc/keys").Methods("GET").HandlerFunc(p.keys)
+	r.Path("/saml/metadata").Methods("GET").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/samlmetadata+xml")
+		w.Write(p.SAMLMetadata())
+	})
+	r.Path("/saml/sso").Methods("GET").HandlerFunc(p.sso)
+	return r
+}
+
+func (p *IdP) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                p.URL,
+		"authorization_endpoint":                p.URL + "/oidc/authorize",
+		"token_endpoint":                        p.URL + "/oidc/token",
+		"jwks_uri":                              p.URL + "/oidc/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// authorize logs the user straight in, returning them to the client with a
+// code for their ID token.
+func (p *IdP) authorize(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("client_id") != ClientID || r.FormValue("response_type") != "code" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(r.FormValue("redirect_uri"))
+	if err != nil || !redirect.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	code := randomString()
+	p.mtx.Lock()
+	p.codes[code] = r.FormValue("nonce")
+	p.mtx.Unlock()
+
+	query := redirect.Query()
+	query.Set("code", code)
+	query.Set("state", r.FormValue("state"))
+	redirect.RawQuery = query.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *IdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.FormValue("client_id"), r.FormValue("client_secret")
+	}
+	if clientID != ClientID || clientSecret != ClientSecret {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	p.mtx.Lock()
+	nonce, ok := p.codes[r.FormValue("code")]
+	delete(p.codes, r.FormValue("code"))
+	p.mtx.Unlock()
+	if !ok {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	idToken, err := p.IDToken(nonce)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// IDToken returns a signed ID token for the current user.
+func (p *IdP) IDToken(nonce string) (string, error) {
+	user := p.currentUser()
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":            p.URL,
+		"sub":            user.ID,
+		"aud":            ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          user.Email,
+		"email_verified": true,
+		"name":           user.Name,
+		"given_name":     user.GivenName,
+		"family_name":    user.FamilyName,
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: jose.RS256,
+		Key:       jose.JSONWebKey{Key: p.key, KeyID: keyID},
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		return "", err
+	}
+	return signed.CompactSerialize()
+}
+
+func (p *IdP) keys(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &p.key.PublicKey, KeyID: keyID, Algorithm: string(jose.RS256), Use: "sig"},
+	}})
+}
+
+// SAMLMetadata describes the identity provider to SAML service providers.
+func (p *IdP) SAMLMetadata() []byte {
+	var buf bytes.Buffer
+	metadataTemplate.Execute(&buf, map[string]string{
+		"EntityID":    p.URL,
+		"SSOURL":      p.URL + "/saml/sso",
+		"Certificate": base64.StdEncoding.EncodeToString(p.cert.Raw),
+	})
+	return buf.Bytes()
+}
+
+// sso logs the user straight in, POSTing a response back to the service
+// provider from their browser.
+func (p *IdP) sso(w http.ResponseWriter, r *http.Request) {
+	req, err := saml.ParseAuthnRequest(r.FormValue("SAMLRequest"))
+	if err != nil || req.ACSURL == "" {
+		http.Error(w, "invalid SAMLRequest", http.StatusBadRequest)
+		return
+	}
+	response, err := p.SAMLResponse(req.ACSURL, req.Issuer, req.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	postTemplate.Execute(w, map[string]string{
+		"ACSURL":       req.ACSURL,
+		"SAMLResponse": response,
+		"RelayState":   r.FormValue("RelayState"),
+	})
+}
+
+// SAMLResponse returns a response, as POSTed to the service provider,
+// asserting the current user has logged in. Its assertion is signed.
+func (p *IdP) SAMLResponse(acsURL, audience, inResponseTo string) (string, error) {
+	user := p.currentUser()
+	now := time.Now().UTC()
+	var buf bytes.Buffer
+	if err := assertionTemplate.Execute(&buf, map[string]string{
+		"ID":           saml.NewID(),
+		"Issuer":       p.URL,
+		"IssueInstant": now.Format(time.RFC3339),
+		"NotBefore":    now.Add(-time.Minute).Format(time.RFC3339),
+		"NotOnOrAfter": now.Add(5 * time.Minute).Format(time.RFC3339),
+		"ACSURL":       acsURL,
+		"Audience":     audience,
+		"InResponseTo": inResponseTo,
+		"NameID":       user.ID,
+		"Email":        user.Email,
+		"GivenName":    user.GivenName,
+		"FamilyName":   user.FamilyName,
+	}); err != nil {
+		return "", err
+	}
+	assertion := etree.NewDocument()
+	if err := assertion.ReadFromBytes(buf.Bytes()); err != nil {
+		return "", err
+	}
+	signing := dsig.NewDefaultSigningContext(dsig.TLSCertKeyStore(tls.Certificate{
+		Certificate: [][]byte{p.cert.Raw},
+		PrivateKey:  p.key,
+	}))
+	// Exclusive canonicalization, so that the signature still holds once the
+	// assertion is in the response, which declares other namespaces.
+	signing.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	signed, err := signing.SignEnveloped(assertion.Root())
+	if err != nil {
+		return "", err
+	}
+
+	buf.Reset()
+	if err := responseTemplate.Execute(&buf, map[string]string{
+		"ID":           saml.NewID(),
+		"IssueInstant": now.Format(time.RFC3339),
+		"ACSURL":       acsURL,
+		"InResponseTo": inResponseTo,
+		"Issuer":       p.URL,
+	}); err != nil {
+		return "", err
+	}
+	response := etree.NewDocument()
+	if err := response.ReadFromBytes(buf.Bytes()); err != nil {
+		return "", err
+	}
+	response.Root().AddChild(signed)
+	encoded, err := response.WriteToBytes()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encoded), nil
+}
+
+// XML templates escape values with xml.
+var xmlFuncs = template.FuncMap{"xml": func(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}}
+
+var metadataTemplate = template.Must(template.New("metadata").Funcs(xmlFuncs).Parse(
+	`<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="{{.EntityID | xml}}">` +
+		`<md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">` +
+		`<md:KeyDescriptor use="signing"><ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:X509Data><ds:X509Certificate>{{.Certificate | xml}}</ds:X509Certificate></ds:X509Data></ds:KeyInfo></md:KeyDescriptor>` +
+		`<md:NameIDFormat>urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress</md:NameIDFormat>` +
+		`<md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="{{.SSOURL | xml}}"/>` +
+		`</md:IDPSSODescriptor>` +
+		`</md:EntityDescriptor>`))
+
+var assertionTemplate = template.Must(template.New("assertion").Funcs(xmlFuncs).Parse(
+	`<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="{{.ID | xml}}" Version="2.0" IssueInstant="{{.IssueInstant | xml}}">` +
+		`<saml:Issuer>{{.Issuer | xml}}</saml:Issuer>` +
+		`<saml:Subject>` +
+		`<saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">{{.NameID | xml}}</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+		`<saml:SubjectConfirmationData NotOnOrAfter="{{.NotOnOrAfter | xml}}" Recipient="{{.ACSURL | xml}}"{{if .InResponseTo}} InResponseTo="{{.InResponseTo | xml}}"{{end}}/>` +
+		`</saml:SubjectConfirmation>` +
+		`</saml:Subject>` +
+		`<saml:Conditions NotBefore="{{.NotBefore | xml}}" NotOnOrAfter="{{.NotOnOrAfter | xml}}">` +
+		`<saml:AudienceRestriction><saml:Audience>{{.Audience | xml}}</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AuthnStatement AuthnInstant="{{.IssueInstant | xml}}"><saml:AuthnContext><saml:AuthnContextClassRef>urn:oasis:names:tc:SAML:2.0:ac:classes:unspecified</saml:AuthnContextClassRef></saml:AuthnContext></saml:AuthnStatement>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute Name="email"><saml:AttributeValue>{{.Email | xml}}</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="givenName"><saml:AttributeValue>{{.GivenName | xml}}</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="sn"><saml:AttributeValue>{{.FamilyName | xml}}</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement>` +
+		`</saml:Assertion>`))
+
+var responseTemplate = template.Must(template.New("response").Funcs(xmlFuncs).Parse(
+	`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="{{.ID | xml}}" Version="2.0" IssueInstant="{{.IssueInstant | xml}}" Destination="{{.ACSURL | xml}}"{{if .InResponseTo}} InResponseTo="{{.InResponseTo | xml}}"{{end}}>` +
+		`<saml:Issuer>{{.Issuer | xml}}</saml:Issuer>` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` +
+		`</samlp:Response>`))
+
+var postTemplate = htmltemplate.Must(htmltemplate.New("post").Parse(`<!DOCTYPE html>
+<html>
+<body onload="document.forms[0].submit()">
+<form method="post" action="{{.ACSURL}}">
+<input type="hidden" name="SAMLResponse" value="{{.SAMLResponse}}">
+{{if .RelayState}}<input type="hidden" name="RelayState" value="{{.RelayState}}">{{end}}
+<noscript><input type="submit" value="Continue"></noscript>
+</form>
+</body>
+</html>
+`))
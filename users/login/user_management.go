@@ -10,8 +10,6 @@ import (
 
 	"gopkg.in/auth0.v5"
 	"gopkg.in/auth0.v5/management"
-
-	"github.com/weaveworks/service/common"
 )
 
 // NewAuth0Management initializes a auth0 management API client
@@ -55,10 +53,8 @@ func (p *Auth0Provider) PasswordlessLogin(r *http.Request, email string) error {
 		"response_type": "code",
 	}
 	if r != nil {
-		token := csrfToken(r)
-		state := common.FlattenQueryParams(r.URL.Query())
-		state["token"] = token
-		authParams["state"] = p.encodeState(state)
+		state := newState(r)
+		authParams["state"] = encodeState(p.clientSecret, state)
 	} else {
 		// Bouncing the user through the verify endpoint sends us back to auth0
 		// and makes them return with proper CSRF protection
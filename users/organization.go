@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/weaveworks/service/billing-api/trial"
+	"github.com/weaveworks/service/common/featureflag"
 	"github.com/weaveworks/service/users/tokens"
 )
 
@@ -165,6 +166,20 @@ func (o *Organization) BillingProvider() string {
 	return "zuora"
 }
 
+// FeatureFlagSubject returns the attributes of the organization which feature
+// flag rules match on.
+func (o *Organization) FeatureFlagSubject() featureflag.Subject {
+	return featureflag.Subject{
+		ID:              o.ID,
+		Platform:        o.Platform,
+		Environment:     o.Environment,
+		Team:            o.TeamExternalID,
+		BillingProvider: o.BillingProvider(),
+		CreatedAt:       o.CreatedAt,
+		FeatureFlags:    o.FeatureFlags,
+	}
+}
+
 // IsOnboarded returns whether the organization has onboarded
 func (o *Organization) IsOnboarded() bool {
 	return o.FirstSeenConnectedAt != nil
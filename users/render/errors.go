@@ -21,6 +21,8 @@ func ErrorStatusCode(err error) int {
 		return http.StatusUnauthorized
 	case users.ErrProviderParameters:
 		return http.StatusUnprocessableEntity
+	case users.ErrTooManyTwoFactorAttempts:
+		return http.StatusTooManyRequests
 	}
 
 	switch e := err.(type) {
@@ -28,6 +30,12 @@ func ErrorStatusCode(err error) int {
 		return http.StatusBadRequest
 	case *users.InstanceDeniedError:
 		return e.Status()
+	case *users.SSORequiredError:
+		return e.Status()
+	case *users.SSOLinkRequiredError:
+		return e.Status()
+	case *users.TwoFactorRequiredError:
+		return e.Status()
 	}
 
 	// Just incase there's something sensitive in the error
@@ -0,0 +1,16 @@
+package users
+
+import (
+	"time"
+)
+
+// TeamSCIMToken authenticates a team's identity provider, to provision the
+// team's members through SCIM. A team has at most one.
+type TeamSCIMToken struct {
+	TeamID string
+	// Token is only set when the token is created, as only a hash of it is
+	// stored.
+	Token     string
+	CreatedBy string
+	CreatedAt time.Time
+}
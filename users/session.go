@@ -0,0 +1,29 @@
+package users
+
+import (
+	"time"
+)
+
+// Session is a user's login, as persisted so that it can be listed and
+// revoked. Its ID is in the user's session cookie.
+type Session struct {
+	ID       string
+	UserID   string
+	Provider string
+	LoginID  string
+	// ImpersonatingUserID is the admin doing the impersonating, if any.
+	ImpersonatingUserID string
+
+	// The device and address the user logged in from.
+	UserAgent string
+	IPAddress string
+
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+}
+
+// Expired tells whether the session is older than maxAge.
+func (s *Session) Expired(now time.Time, maxAge time.Duration) bool {
+	return now.Sub(s.CreatedAt) > maxAge
+}
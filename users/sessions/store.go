@@ -1,22 +1,46 @@
 package sessions
 
 import (
-	"github.com/gorilla/securecookie"
-	log "github.com/sirupsen/logrus"
+	"context"
 	"net/http"
 	"time"
 
+	"github.com/gorilla/securecookie"
+	log "github.com/sirupsen/logrus"
+
+	httpUtil "github.com/weaveworks/service/common/http"
 	"github.com/weaveworks/service/users"
 	"github.com/weaveworks/service/users/client"
+	"github.com/weaveworks/service/users/tokens"
 )
 
 const (
 	// SessionDuration is the duration used to set expiration session cookies
 	SessionDuration = 1440 * time.Hour
+
+	// PendingLoginDuration is how long users have to enter their second
+	// factor after logging in.
+	PendingLoginDuration = 10 * time.Minute
+
+	// PendingLoginCookieName is the cookie holding logins which are waiting
+	// for the user's second factor.
+	PendingLoginCookieName = "_weave_cloud_pending_login"
+
+	// Only record when sessions were last seen every so often, so busy
+	// sessions don't cause a write per request.
+	lastSeenResolution = time.Minute
 )
 
+// DB persists sessions, so that they can be listed and revoked.
+type DB interface {
+	CreateSession(ctx context.Context, session *users.Session) error
+	FindSession(ctx context.Context, sessionID string) (*users.Session, error)
+	SetSessionLastSeenAt(ctx context.Context, sessionID string, lastSeenAt time.Time) error
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+}
+
 // MustNewStore creates a new session store, or panics.
-func MustNewStore(validationSecret string, secure bool, domain string) Store {
+func MustNewStore(validationSecret string, secure bool, domain string, db DB) Store {
 	secretBytes := []byte(validationSecret)
 	if len(secretBytes) != 64 {
 		log.Fatal("session-secret must be 64 bytes")
@@ -27,21 +51,30 @@ func MustNewStore(validationSecret string, secure bool, domain string) Store {
 		encoder: securecookie.New(secretBytes, nil).
 			SetSerializer(securecookie.JSONEncoder{}).
 			MaxAge(int(SessionDuration.Seconds())),
+		pendingEncoder: securecookie.New(secretBytes, nil).
+			SetSerializer(securecookie.JSONEncoder{}).
+			MaxAge(int(PendingLoginDuration.Seconds())),
 		secure: secure,
 		domain: domain,
+		db:     db,
 	}
 }
 
-// Store is a session store. It manages reading and writing from cookies.
+// Store is a session store. It manages reading and writing from cookies,
+// which hold the IDs of sessions persisted in the database.
 type Store struct {
-	secret  string
-	encoder *securecookie.SecureCookie
-	secure  bool
-	domain  string
+	secret         string
+	encoder        *securecookie.SecureCookie
+	pendingEncoder *securecookie.SecureCookie
+	secure         bool
+	domain         string
+	db             DB
 }
 
 // Session is the decoded representation of a session cookie
 type Session struct {
+	// The ID of the persisted session
+	ID string
 	// The provider used to authorize the session (e.g. "google", "email")
 	Provider string
 	// The provider's ID for our login
@@ -72,7 +105,7 @@ func (s Store) Get(r *http.Request) (Session, error) {
 	if err != nil {
 		return Session{}, err
 	}
-	return s.Decode(value)
+	return s.Decode(r.Context(), value)
 }
 
 // Extract the encoded session from a request.
@@ -87,9 +120,34 @@ func Extract(r *http.Request) (string, error) {
 	return cookie.Value, nil
 }
 
-// Decode converts an encoded session into a user ID.
-func (s Store) Decode(encoded string) (Session, error) {
-	// Parse and validate the encoded session
+// Decode converts an encoded session into a user ID. Sessions which have
+// been revoked are no longer valid.
+func (s Store) Decode(ctx context.Context, encoded string) (Session, error) {
+	session, err := s.decode(encoded)
+	if err != nil {
+		return Session{}, err
+	}
+	persisted, err := s.db.FindSession(ctx, session.ID)
+	if err == users.ErrNotFound {
+		return Session{}, users.ErrInvalidAuthenticationData
+	} else if err != nil {
+		return Session{}, err
+	}
+	now := time.Now().UTC()
+	if persisted.UserID != session.UserID || persisted.Expired(now, SessionDuration) {
+		return Session{}, users.ErrInvalidAuthenticationData
+	}
+	if now.Sub(persisted.LastSeenAt) > lastSeenResolution {
+		if err := s.db.SetSessionLastSeenAt(ctx, persisted.ID, now); err != nil {
+			log.Warnf("failed to set last seen time of session: %v", err)
+		}
+	}
+	return session, nil
+}
+
+// decode parses and validates an encoded session, without checking it is
+// still persisted.
+func (s Store) decode(encoded string) (Session, error) {
 	var session Session
 	if err := s.encoder.Decode(client.AuthCookieName, encoded, &session); err != nil {
 		return Session{}, users.ErrInvalidAuthenticationData
@@ -98,18 +156,21 @@ func (s Store) Decode(encoded string) (Session, error) {
 	if session.CreatedAt.IsZero() || time.Now().UTC().Sub(session.CreatedAt) > SessionDuration {
 		return Session{}, users.ErrInvalidAuthenticationData
 	}
-	// Lookup the user by encoded id
-	if session.UserID == "" {
+	// Cookies from before sessions were persisted have no ID, and can't be
+	// revoked, so are no longer accepted.
+	if session.ID == "" || session.UserID == "" {
 		return Session{}, users.ErrInvalidAuthenticationData
 	}
 	return session, nil
 }
 
-// Set stores the session with the given userID for the user.
+// Set stores the session with the given userID for the user, replacing
+// any session the request already had.
 func (s Store) Set(w http.ResponseWriter, r *http.Request, provider string, loginID string, userID string, impersonatingUserID string) error {
-	cookie, err := s.Cookie(provider, loginID, userID, impersonatingUserID)
+	cookie, err := s.Cookie(r, provider, loginID, userID, impersonatingUserID)
 	impersonationCookieShouldExist := false
 	if err == nil {
+		s.revoke(r)
 		http.SetCookie(w, cookie)
 		impersonationCookieShouldExist = (impersonatingUserID != "")
 	}
@@ -117,8 +178,10 @@ func (s Store) Set(w http.ResponseWriter, r *http.Request, provider string, logi
 	return err
 }
 
-// Clear deletes session data for the response
+// Clear revokes the request's session, and deletes session data for the
+// response.
 func (s Store) Clear(w http.ResponseWriter, r *http.Request) {
+	s.revoke(r)
 	http.SetCookie(w, &http.Cookie{
 		Name:     client.AuthCookieName,
 		Value:    "",
@@ -133,6 +196,22 @@ func (s Store) Clear(w http.ResponseWriter, r *http.Request) {
 	s.applyImpersonationCookie(w, r, false)
 }
 
+// revoke revokes the request's session, if it has one. Failures are only
+// logged: the cookie is replaced or deleted regardless.
+func (s Store) revoke(r *http.Request) {
+	encoded, err := Extract(r)
+	if err != nil {
+		return
+	}
+	session, err := s.decode(encoded)
+	if err != nil {
+		return
+	}
+	if err := s.db.RevokeSession(r.Context(), session.UserID, session.ID); err != nil && err != users.ErrNotFound {
+		log.Warnf("failed to revoke session: %v", err)
+	}
+}
+
 // applyImpersonationCookie arranges for impersonation cookie to be present / absent
 // - if cookieShouldExist is true, will request creation of impersonation cookie (no matter whether cookie exists)
 // - if cookieShouldExist is false and cookie present, will request its removal
@@ -160,9 +239,10 @@ func (s Store) applyImpersonationCookie(w http.ResponseWriter, r *http.Request,
 	http.SetCookie(w, &cookie)
 }
 
-// Cookie creates the http cookie to set for this user's session.
-func (s Store) Cookie(provider string, loginID string, userID string, impersonatingUserID string) (*http.Cookie, error) {
-	value, err := s.Encode(provider, loginID, userID, impersonatingUserID)
+// Cookie creates a session for this user, logging in from the request, and
+// the http cookie to set for it.
+func (s Store) Cookie(r *http.Request, provider string, loginID string, userID string, impersonatingUserID string) (*http.Cookie, error) {
+	value, err := s.Encode(r, provider, loginID, userID, impersonatingUserID)
 	return &http.Cookie{
 		Name:     client.AuthCookieName,
 		Value:    value,
@@ -175,17 +255,100 @@ func (s Store) Cookie(provider string, loginID string, userID string, impersonat
 	}, err
 }
 
-// Encode converts the session data into a session string
-func (s Store) Encode(provider string, loginID string, userID string, impersonatingUserID string) (string, error) {
+// Encode creates a session for this user, logging in from the request, and
+// converts it into a session string
+func (s Store) Encode(r *http.Request, provider string, loginID string, userID string, impersonatingUserID string) (string, error) {
+	id, err := tokens.Generate()
+	if err != nil {
+		return "", err
+	}
+	persisted := &users.Session{
+		ID:                  id,
+		UserID:              userID,
+		Provider:            provider,
+		LoginID:             loginID,
+		ImpersonatingUserID: impersonatingUserID,
+		UserAgent:           r.UserAgent(),
+		IPAddress:           httpUtil.ClientIP(r),
+	}
+	if err := s.db.CreateSession(r.Context(), persisted); err != nil {
+		return "", err
+	}
 	return s.encoder.Encode(client.AuthCookieName, Session{
+		ID:                  persisted.ID,
 		Provider:            provider,
 		LoginID:             loginID,
 		UserID:              userID,
-		CreatedAt:           time.Now().UTC(),
+		CreatedAt:           persisted.CreatedAt,
 		ImpersonatingUserID: impersonatingUserID,
 	})
 }
 
+// PendingLogin is a login waiting for the user to enter their second
+// factor, before their session is created.
+type PendingLogin struct {
+	Provider  string
+	LoginID   string
+	UserID    string
+	CreatedAt time.Time
+}
+
+// SetPending stores a login which is waiting for the user's second factor.
+func (s Store) SetPending(w http.ResponseWriter, provider, loginID, userID string) error {
+	value, err := s.pendingEncoder.Encode(PendingLoginCookieName, PendingLogin{
+		Provider:  provider,
+		LoginID:   loginID,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, s.pendingCookie(value, PendingLoginDuration))
+	return nil
+}
+
+// GetPending fetches the request's login waiting for a second factor.
+func (s Store) GetPending(r *http.Request) (PendingLogin, error) {
+	cookie, err := r.Cookie(PendingLoginCookieName)
+	if err != nil {
+		return PendingLogin{}, users.ErrInvalidAuthenticationData
+	}
+	var pending PendingLogin
+	if err := s.pendingEncoder.Decode(PendingLoginCookieName, cookie.Value, &pending); err != nil {
+		return PendingLogin{}, users.ErrInvalidAuthenticationData
+	}
+	if pending.UserID == "" || time.Now().UTC().Sub(pending.CreatedAt) > PendingLoginDuration {
+		return PendingLogin{}, users.ErrInvalidAuthenticationData
+	}
+	return pending, nil
+}
+
+// ClearPending deletes the login waiting for a second factor, if any.
+func (s Store) ClearPending(w http.ResponseWriter, r *http.Request) {
+	if findCookie(r, PendingLoginCookieName) == nil {
+		return
+	}
+	http.SetCookie(w, s.pendingCookie("", -1*time.Second))
+}
+
+func (s Store) pendingCookie(value string, maxAge time.Duration) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     PendingLoginCookieName,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Expires:  time.Now().UTC().Add(maxAge),
+		MaxAge:   int(maxAge / time.Second),
+		Secure:   s.secure,
+		Domain:   s.domain,
+	}
+	if maxAge < 0 {
+		cookie.MaxAge = -1
+	}
+	return cookie
+}
+
 // findCookie   Finds cookie by name in http.Request
 // If cookie exists, returns pointer to it, otherwise returns nil
 func findCookie(r *http.Request, cookieName string) *http.Cookie {
@@ -0,0 +1,93 @@
+package users
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Protocols teams can log in with through their own identity provider.
+const (
+	SSOProtocolOIDC = "oidc"
+	SSOProtocolSAML = "saml"
+)
+
+// SSOProviderPrefix prefixes the login provider IDs of teams' single
+// sign-on, which are "sso-<team external ID>".
+const SSOProviderPrefix = "sso-"
+
+// TeamSSOConfig configures a team's single sign-on through its own identity
+// provider.
+type TeamSSOConfig struct {
+	TeamID   string
+	Protocol string
+
+	OIDCIssuerURL    string
+	OIDCClientID     string
+	OIDCClientSecret string
+
+	// SAMLMetadata is the identity provider's metadata XML.
+	SAMLMetadata string
+
+	// DefaultRoleID is the role of users who join the team by logging in
+	// through its identity provider.
+	DefaultRoleID string
+	// Enforce forbids members of the team who have linked its identity
+	// provider to their account from logging in other than through it.
+	Enforce bool
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SSORequiredError is returned when a member of a team which enforces
+// single sign-on tries to log in some other way.
+type SSORequiredError struct {
+	TeamExternalID string
+	TeamName       string
+}
+
+func (err *SSORequiredError) Error() string {
+	return fmt.Sprintf("Members of %q must log in through its single sign-on", err.TeamName)
+}
+
+// Status returns the HTTP status code appropriate for this error.
+func (err *SSORequiredError) Status() int {
+	return http.StatusForbidden
+}
+
+// Metadata implements WithMetadata
+func (err *SSORequiredError) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"teamId":   err.TeamExternalID,
+		"loginUrl": fmt.Sprintf("/api/users/sso/%s/login", err.TeamExternalID),
+	}
+}
+
+// SSOLinkRequiredError is returned when a team's identity provider logs in
+// someone with the email of an existing user, who hasn't linked it to their
+// account. They must log in some other way first, then through the identity
+// provider again.
+type SSOLinkRequiredError struct {
+	TeamExternalID string
+	TeamName       string
+	Email          string
+}
+
+func (err *SSOLinkRequiredError) Error() string {
+	return fmt.Sprintf("%s already has an account: log in to it, then through the single sign-on of %q to link them", err.Email, err.TeamName)
+}
+
+// Status returns the HTTP status code appropriate for this error.
+func (err *SSOLinkRequiredError) Status() int {
+	return http.StatusForbidden
+}
+
+// Metadata implements WithMetadata
+func (err *SSOLinkRequiredError) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"teamId":   err.TeamExternalID,
+		"email":    err.Email,
+		"loginUrl": fmt.Sprintf("/api/users/sso/%s/login", err.TeamExternalID),
+	}
+}
@@ -2,7 +2,9 @@ package tokens
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base32"
+	"encoding/hex"
 	"net/http"
 	"strings"
 )
@@ -37,6 +39,13 @@ func Generate() (string, error) {
 	return zbase32.EncodeToString(randomData), nil
 }
 
+// Hash returns a hash of a token, for storing tokens we only need to
+// recognise rather than show again.
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // ExtractToken extracts an auth token from a request, if possible.
 func ExtractToken(r *http.Request) (string, bool) {
 	authHeader := r.Header.Get(AuthHeaderName)
@@ -0,0 +1,68 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The SHA1 test vectors from RFC 6238, truncated to six digits.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func Test_Code(t *testing.T) {
+	for _, test := range []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	} {
+		code, err := Code(rfcSecret, time.Unix(test.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, test.code, code, "at %d", test.unix)
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := Code(secret, now)
+	require.NoError(t, err)
+
+	step, ok := Validate(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// Codes are accepted for a period either side, to allow for clock drift
+	step, ok = Validate(secret, code, now.Add(Period))
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+	_, ok = Validate(secret, code, now.Add(-3*Period))
+	assert.False(t, ok)
+
+	_, ok = Validate(secret, code[:3]+" "+code[3:], now)
+	assert.True(t, ok)
+	_, ok = Validate(secret, "", now)
+	assert.False(t, ok)
+	_, ok = Validate("not base32!", code, now)
+	assert.False(t, ok)
+}
+
+func Test_ProvisioningURI(t *testing.T) {
+	u, err := url.Parse(ProvisioningURI("SECRET", "Weave Cloud", "alice@example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Weave Cloud:alice@example.com", u.Path)
+	assert.Equal(t, "SECRET", u.Query().Get("secret"))
+	assert.Equal(t, "Weave Cloud", u.Query().Get("issuer"))
+}
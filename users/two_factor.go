@@ -0,0 +1,67 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// UserTOTP is a user's enrollment in two-factor authentication through
+// time-based one-time passwords, as generated by authenticator apps.
+type UserTOTP struct {
+	UserID string
+	// Secret is shared with the user's authenticator app.
+	Secret string
+	// RecoveryCodeHashes are hashes of the unused codes the user can log in
+	// with instead, should they lose their authenticator.
+	RecoveryCodeHashes []string
+	// LastUsedStep is the time step of the last code used, as each code may
+	// only be used once.
+	LastUsedStep int64
+
+	// Failed attempts since the last successful one, to lock out guessing.
+	FailedAttempts int
+	LastFailedAt   *time.Time
+
+	CreatedAt time.Time
+	// ConfirmedAt is when the user proved their authenticator works. Until
+	// then, the enrollment has no effect.
+	ConfirmedAt *time.Time
+}
+
+// Confirmed tells whether the user has finished enrolling.
+func (t *UserTOTP) Confirmed() bool {
+	return t != nil && t.ConfirmedAt != nil
+}
+
+// ErrTooManyTwoFactorAttempts is returned when a user's second factor is
+// locked, after too many wrong codes.
+var ErrTooManyTwoFactorAttempts = errors.New("too many failed two-factor attempts, try again later")
+
+// TwoFactorRequiredError is returned when a member of a team which requires
+// two-factor authentication uses one of the sensitive permissions it is
+// required for without having enrolled.
+type TwoFactorRequiredError struct {
+	TeamExternalID string
+	TeamName       string
+	PermissionID   string
+}
+
+func (err *TwoFactorRequiredError) Error() string {
+	return fmt.Sprintf("Members of %q must enable two-factor authentication to use %s", err.TeamName, err.PermissionID)
+}
+
+// Status returns the HTTP status code appropriate for this error.
+func (err *TwoFactorRequiredError) Status() int {
+	return http.StatusForbidden
+}
+
+// Metadata implements WithMetadata
+func (err *TwoFactorRequiredError) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"teamId":       err.TeamExternalID,
+		"permissionId": err.PermissionID,
+		"enrollUrl":    "/api/users/user/totp",
+	}
+}
@@ -181,6 +181,9 @@ func (m *LookupOrgResponse) GetFeatureFlags() []string {
 type LookupUsingTokenRequest struct {
 	Token        string           `protobuf:"bytes,1,opt,name=Token,proto3" json:"Token,omitempty"`
 	AuthorizeFor AuthorizedAction `protobuf:"varint,2,opt,name=AuthorizeFor,proto3,enum=users.AuthorizedAction" json:"AuthorizeFor,omitempty"`
+	// Service the token is being used for, e.g. "prom" or "flux". Scoped API
+	// tokens are only valid for the services they are scoped to.
+	Service string `protobuf:"bytes,3,opt,name=Service,proto3" json:"Service,omitempty"`
 }
 
 func (m *LookupUsingTokenRequest) Reset()      { *m = LookupUsingTokenRequest{} }
@@ -229,9 +232,18 @@ func (m *LookupUsingTokenRequest) GetAuthorizeFor() AuthorizedAction {
 	return OTHER
 }
 
+func (m *LookupUsingTokenRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
 type LookupUsingTokenResponse struct {
 	OrganizationID string   `protobuf:"bytes,1,opt,name=OrganizationID,proto3" json:"organizationID,omitempty"`
 	FeatureFlags   []string `protobuf:"bytes,2,rep,name=FeatureFlags,proto3" json:"featureFlags,omitempty"`
+	// When the token expires, if it is an API token with an expiry.
+	TokenExpiresAt *time.Time `protobuf:"bytes,3,opt,name=TokenExpiresAt,proto3,stdtime" json:"tokenExpiresAt,omitempty"`
 }
 
 func (m *LookupUsingTokenResponse) Reset()      { *m = LookupUsingTokenResponse{} }
@@ -280,6 +292,13 @@ func (m *LookupUsingTokenResponse) GetFeatureFlags() []string {
 	return nil
 }
 
+func (m *LookupUsingTokenResponse) GetTokenExpiresAt() *time.Time {
+	if m != nil {
+		return m.TokenExpiresAt
+	}
+	return nil
+}
+
 type LookupAdminRequest struct {
 	Cookie string `protobuf:"bytes,1,opt,name=Cookie,proto3" json:"Cookie,omitempty"`
 }
@@ -565,6 +584,9 @@ type GetBillableOrganizationsRequest struct {
 	// The current time for the purposes of determining whether the trial
 	// period has expired.
 	Now time.Time `protobuf:"bytes,1,opt,name=Now,proto3,stdtime" json:"Now"`
+	// TeamIDs are teams billed as a whole, e.g. through Stripe, whose
+	// organizations are billable without a Zuora account or GCP subscription.
+	TeamIDs []string `protobuf:"bytes,2,rep,name=TeamIDs,proto3" json:"TeamIDs,omitempty"`
 }
 
 func (m *GetBillableOrganizationsRequest) Reset()      { *m = GetBillableOrganizationsRequest{} }
@@ -606,6 +628,13 @@ func (m *GetBillableOrganizationsRequest) GetNow() time.Time {
 	return time.Time{}
 }
 
+func (m *GetBillableOrganizationsRequest) GetTeamIDs() []string {
+	if m != nil {
+		return m.TeamIDs
+	}
+	return nil
+}
+
 type GetBillableOrganizationsResponse struct {
 	Organizations []Organization `protobuf:"bytes,1,rep,name=Organizations,proto3" json:"Organizations"`
 }
@@ -2835,6 +2864,124 @@ func (m *Webhook) GetFirstSeenAt() *time.Time {
 	return nil
 }
 
+// APIToken is a named token granting scoped access to an instance's data,
+// in addition to the instance's probe token.
+type APIToken struct {
+	ID             string `protobuf:"bytes,1,opt,name=ID,proto3" json:"id"`
+	OrganizationID string `protobuf:"bytes,2,opt,name=OrganizationID,proto3" json:"-"`
+	Name           string `protobuf:"bytes,3,opt,name=Name,proto3" json:"name"`
+	// The token itself is only known when it is created; only a hash is stored.
+	Token      string     `protobuf:"bytes,4,opt,name=Token,proto3" json:"token,omitempty"`
+	Scopes     []string   `protobuf:"bytes,5,rep,name=Scopes,proto3" json:"scopes"`
+	CreatedBy  string     `protobuf:"bytes,6,opt,name=CreatedBy,proto3" json:"createdBy"`
+	CreatedAt  time.Time  `protobuf:"bytes,7,opt,name=CreatedAt,proto3,stdtime" json:"createdAt"`
+	ExpiresAt  *time.Time `protobuf:"bytes,8,opt,name=ExpiresAt,proto3,stdtime" json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `protobuf:"bytes,9,opt,name=LastUsedAt,proto3,stdtime" json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `protobuf:"bytes,10,opt,name=RevokedAt,proto3,stdtime" json:"revokedAt,omitempty"`
+}
+
+func (m *APIToken) Reset()      { *m = APIToken{} }
+func (*APIToken) ProtoMessage() {}
+func (*APIToken) Descriptor() ([]byte, []int) {
+	return fileDescriptor_030765f334c86cea, []int{46}
+}
+func (m *APIToken) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *APIToken) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_APIToken.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *APIToken) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_APIToken.Merge(m, src)
+}
+func (m *APIToken) XXX_Size() int {
+	return m.Size()
+}
+func (m *APIToken) XXX_DiscardUnknown() {
+	xxx_messageInfo_APIToken.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_APIToken proto.InternalMessageInfo
+
+func (m *APIToken) GetID() string {
+	if m != nil {
+		return m.ID
+	}
+	return ""
+}
+
+func (m *APIToken) GetOrganizationID() string {
+	if m != nil {
+		return m.OrganizationID
+	}
+	return ""
+}
+
+func (m *APIToken) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *APIToken) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *APIToken) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *APIToken) GetCreatedBy() string {
+	if m != nil {
+		return m.CreatedBy
+	}
+	return ""
+}
+
+func (m *APIToken) GetCreatedAt() time.Time {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return time.Time{}
+}
+
+func (m *APIToken) GetExpiresAt() *time.Time {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return nil
+}
+
+func (m *APIToken) GetLastUsedAt() *time.Time {
+	if m != nil {
+		return m.LastUsedAt
+	}
+	return nil
+}
+
+func (m *APIToken) GetRevokedAt() *time.Time {
+	if m != nil {
+		return m.RevokedAt
+	}
+	return nil
+}
+
 type LookupOrganizationWebhookUsingSecretIDRequest struct {
 	SecretID string `protobuf:"bytes,1,opt,name=SecretID,proto3" json:"SecretID,omitempty"`
 }
@@ -2844,7 +2991,7 @@ func (m *LookupOrganizationWebhookUsingSecretIDRequest) Reset() {
 }
 func (*LookupOrganizationWebhookUsingSecretIDRequest) ProtoMessage() {}
 func (*LookupOrganizationWebhookUsingSecretIDRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_030765f334c86cea, []int{46}
+	return fileDescriptor_030765f334c86cea, []int{47}
 }
 func (m *LookupOrganizationWebhookUsingSecretIDRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2889,7 +3036,7 @@ func (m *LookupOrganizationWebhookUsingSecretIDResponse) Reset() {
 }
 func (*LookupOrganizationWebhookUsingSecretIDResponse) ProtoMessage() {}
 func (*LookupOrganizationWebhookUsingSecretIDResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_030765f334c86cea, []int{47}
+	return fileDescriptor_030765f334c86cea, []int{48}
 }
 func (m *LookupOrganizationWebhookUsingSecretIDResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2934,7 +3081,7 @@ func (m *SetOrganizationWebhookFirstSeenAtRequest) Reset() {
 }
 func (*SetOrganizationWebhookFirstSeenAtRequest) ProtoMessage() {}
 func (*SetOrganizationWebhookFirstSeenAtRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_030765f334c86cea, []int{48}
+	return fileDescriptor_030765f334c86cea, []int{49}
 }
 func (m *SetOrganizationWebhookFirstSeenAtRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2979,7 +3126,7 @@ func (m *SetOrganizationWebhookFirstSeenAtResponse) Reset() {
 }
 func (*SetOrganizationWebhookFirstSeenAtResponse) ProtoMessage() {}
 func (*SetOrganizationWebhookFirstSeenAtResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_030765f334c86cea, []int{49}
+	return fileDescriptor_030765f334c86cea, []int{50}
 }
 func (m *SetOrganizationWebhookFirstSeenAtResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -3024,7 +3171,7 @@ func (m *InformOrganizationBillingConfiguredRequest) Reset() {
 }
 func (*InformOrganizationBillingConfiguredRequest) ProtoMessage() {}
 func (*InformOrganizationBillingConfiguredRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_030765f334c86cea, []int{50}
+	return fileDescriptor_030765f334c86cea, []int{51}
 }
 func (m *InformOrganizationBillingConfiguredRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -3069,7 +3216,7 @@ type Permission struct {
 func (m *Permission) Reset()      { *m = Permission{} }
 func (*Permission) ProtoMessage() {}
 func (*Permission) Descriptor() ([]byte, []int) {
-	return fileDescriptor_030765f334c86cea, []int{51}
+	return fileDescriptor_030765f334c86cea, []int{52}
 }
 func (m *Permission) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -3123,12 +3270,14 @@ type Role struct {
 	ID          string `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
 	Name        string `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
 	Description string `protobuf:"bytes,3,opt,name=Description,proto3" json:"Description,omitempty"`
+	// Set for custom roles, which belong to a team; empty for built-in roles.
+	TeamID string `protobuf:"bytes,4,opt,name=TeamID,proto3" json:"TeamID,omitempty"`
 }
 
 func (m *Role) Reset()      { *m = Role{} }
 func (*Role) ProtoMessage() {}
 func (*Role) Descriptor() ([]byte, []int) {
-	return fileDescriptor_030765f334c86cea, []int{52}
+	return fileDescriptor_030765f334c86cea, []int{53}
 }
 func (m *Role) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -3178,6 +3327,13 @@ func (m *Role) GetDescription() string {
 	return ""
 }
 
+func (m *Role) GetTeamID() string {
+	if m != nil {
+		return m.TeamID
+	}
+	return ""
+}
+
 type RequireTeamMemberPermissionToRequest struct {
 	UserID string `protobuf:"bytes,1,opt,name=UserID,proto3" json:"UserID,omitempty"`
 	// Types that are valid to be assigned to TeamID:
@@ -3190,7 +3346,7 @@ type RequireTeamMemberPermissionToRequest struct {
 func (m *RequireTeamMemberPermissionToRequest) Reset()      { *m = RequireTeamMemberPermissionToRequest{} }
 func (*RequireTeamMemberPermissionToRequest) ProtoMessage() {}
 func (*RequireTeamMemberPermissionToRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_030765f334c86cea, []int{53}
+	return fileDescriptor_030765f334c86cea, []int{54}
 }
 func (m *RequireTeamMemberPermissionToRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -3293,7 +3449,7 @@ type RequireOrgMemberPermissionToRequest struct {
 func (m *RequireOrgMemberPermissionToRequest) Reset()      { *m = RequireOrgMemberPermissionToRequest{} }
 func (*RequireOrgMemberPermissionToRequest) ProtoMessage() {}
 func (*RequireOrgMemberPermissionToRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_030765f334c86cea, []int{54}
+	return fileDescriptor_030765f334c86cea, []int{55}
 }
 func (m *RequireOrgMemberPermissionToRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -3432,6 +3588,7 @@ func init() {
 	proto.RegisterType((*Summary)(nil), "users.Summary")
 	proto.RegisterType((*SummaryEntry)(nil), "users.SummaryEntry")
 	proto.RegisterType((*Webhook)(nil), "users.Webhook")
+	proto.RegisterType((*APIToken)(nil), "users.APIToken")
 	proto.RegisterType((*LookupOrganizationWebhookUsingSecretIDRequest)(nil), "users.LookupOrganizationWebhookUsingSecretIDRequest")
 	proto.RegisterType((*LookupOrganizationWebhookUsingSecretIDResponse)(nil), "users.LookupOrganizationWebhookUsingSecretIDResponse")
 	proto.RegisterType((*SetOrganizationWebhookFirstSeenAtRequest)(nil), "users.SetOrganizationWebhookFirstSeenAtRequest")
@@ -3446,192 +3603,203 @@ func init() {
 func init() { proto.RegisterFile("users.proto", fileDescriptor_030765f334c86cea) }
 
 var fileDescriptor_030765f334c86cea = []byte{
-	// 2949 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x3a, 0x3b, 0x74, 0xdb, 0xd6,
-	0xd9, 0x84, 0x44, 0x8a, 0xe4, 0x47, 0x49, 0x96, 0xaf, 0x1e, 0x86, 0x61, 0x99, 0x94, 0xe1, 0xc7,
-	0xaf, 0xd8, 0x92, 0xfc, 0x57, 0x49, 0xdb, 0xb4, 0x39, 0x69, 0xcc, 0x87, 0x24, 0xab, 0x51, 0x24,
-	0x19, 0x92, 0x9c, 0xd4, 0xc9, 0x49, 0x02, 0x91, 0x57, 0x34, 0x8e, 0x49, 0x80, 0x01, 0x40, 0x27,
-	0xcc, 0x94, 0xb1, 0x63, 0xda, 0x0e, 0xed, 0xd8, 0xb1, 0x73, 0xc7, 0x4e, 0xdd, 0x9a, 0xa1, 0x43,
-	0xba, 0x65, 0x52, 0x6a, 0x65, 0xe9, 0xd1, 0x94, 0x73, 0xba, 0x74, 0xec, 0xc1, 0xc5, 0x05, 0x70,
-	0xf1, 0x22, 0x81, 0x58, 0xa7, 0x1b, 0xf0, 0xbd, 0xef, 0xeb, 0x7b, 0xdd, 0x0b, 0xa5, 0xbe, 0x81,
-	0x75, 0x63, 0xad, 0xa7, 0x6b, 0xa6, 0x86, 0x72, 0xe4, 0x47, 0x58, 0x6d, 0x2b, 0xe6, 0xd3, 0xfe,
-	0xf1, 0x5a, 0x53, 0xeb, 0xde, 0x6f, 0x6b, 0x6d, 0xed, 0x3e, 0xc1, 0x1e, 0xf7, 0x4f, 0xc8, 0x1f,
-	0xf9, 0x21, 0x5f, 0x36, 0x97, 0x50, 0x69, 0x6b, 0x5a, 0xbb, 0x83, 0x3d, 0x2a, 0x53, 0xe9, 0x62,
-	0xc3, 0x94, 0xbb, 0x3d, 0x9b, 0x40, 0xfc, 0x1d, 0x07, 0x33, 0x3b, 0x9a, 0xf6, 0xac, 0xdf, 0xdb,
-	0xd3, 0xdb, 0x12, 0xfe, 0xa4, 0x8f, 0x0d, 0x13, 0x2d, 0xc0, 0x44, 0x5d, 0xd3, 0x9e, 0x29, 0x98,
-	0xe7, 0x96, 0xb8, 0xe5, 0xa2, 0x44, 0xff, 0xd0, 0x2d, 0x98, 0xda, 0xd3, 0xdb, 0x1b, 0x9f, 0x99,
-	0x58, 0x57, 0xe5, 0xce, 0x76, 0x83, 0x1f, 0x23, 0x68, 0x3f, 0x10, 0xbd, 0x01, 0x93, 0xd5, 0xbe,
-	0xf9, 0x54, 0xd3, 0x95, 0xcf, 0xf1, 0xa6, 0xa6, 0xf3, 0xd9, 0x25, 0x6e, 0x79, 0x7a, 0xfd, 0xca,
-	0x9a, 0x3d, 0x1a, 0x17, 0xd5, 0xaa, 0x36, 0x4d, 0x45, 0x53, 0x25, 0x1f, 0xf1, 0x2f, 0xb3, 0x85,
-	0xf1, 0x99, 0xac, 0xf8, 0x37, 0x0e, 0x2e, 0x33, 0x56, 0x19, 0x3d, 0x4d, 0x35, 0x30, 0x6a, 0xc0,
-	0xf4, 0x9e, 0xde, 0x96, 0x55, 0xe5, 0x73, 0xd9, 0xe2, 0xdc, 0x6e, 0xd8, 0xe6, 0xd5, 0x16, 0xcf,
-	0x4f, 0x2b, 0xbc, 0xe6, 0xc3, 0xac, 0x68, 0x5d, 0xc5, 0xc4, 0xdd, 0x9e, 0x39, 0x90, 0x02, 0x3c,
-	0x68, 0x05, 0x26, 0x8e, 0x0c, 0xac, 0x3b, 0xd6, 0xd7, 0xe6, 0xce, 0x4f, 0x2b, 0x33, 0x7d, 0x02,
-	0x61, 0xb8, 0x28, 0x0d, 0xfa, 0x05, 0x4c, 0x6e, 0x62, 0xd9, 0xec, 0xeb, 0x78, 0xb3, 0x23, 0xb7,
-	0x0d, 0x7e, 0x7c, 0x69, 0x7c, 0xb9, 0x58, 0x13, 0xce, 0x4f, 0x2b, 0x0b, 0x27, 0x0c, 0x9c, 0xe1,
-	0xf4, 0xd1, 0x8b, 0x1d, 0xb8, 0x62, 0x0f, 0xe4, 0xc8, 0x50, 0xd4, 0xf6, 0xa1, 0xf6, 0x0c, 0xab,
-	0xce, 0x2c, 0xcf, 0x41, 0x8e, 0xfc, 0xd3, 0x49, 0xb6, 0x7f, 0x42, 0xb3, 0x37, 0x96, 0x62, 0xf6,
-	0xc4, 0x3f, 0x72, 0xc0, 0x87, 0xd5, 0x5d, 0xe8, 0xf4, 0x05, 0x27, 0x64, 0x2c, 0xe5, 0x84, 0xac,
-	0x00, 0xb2, 0x2d, 0xac, 0xb6, 0xba, 0x8a, 0x3a, 0x62, 0xc7, 0x89, 0x9b, 0x30, 0xeb, 0xa3, 0xa6,
-	0x43, 0xb9, 0x0f, 0x79, 0x02, 0x70, 0xc7, 0x30, 0x7f, 0x7e, 0x5a, 0xb9, 0x2c, 0xdb, 0x20, 0x46,
-	0xb5, 0x43, 0x25, 0xde, 0x73, 0xf6, 0x93, 0xb5, 0xac, 0xa3, 0x94, 0xd6, 0x1c, 0x13, 0x6d, 0x62,
-	0xaa, 0xd3, 0xdb, 0x37, 0xdc, 0xe8, 0x7d, 0x23, 0x7e, 0x0a, 0x57, 0xb6, 0xb0, 0xc9, 0xce, 0x9d,
-	0xc1, 0xac, 0xfb, 0xa3, 0x3e, 0xd6, 0x07, 0xce, 0xba, 0x93, 0x1f, 0x54, 0x06, 0xd8, 0x97, 0xdb,
-	0x78, 0xb7, 0xdf, 0x3d, 0xc6, 0xf6, 0xaa, 0xe7, 0x24, 0x06, 0x82, 0xee, 0xc0, 0xf4, 0xb6, 0xda,
-	0xec, 0xf4, 0x5b, 0xb8, 0x81, 0x3b, 0xd8, 0xc4, 0x2d, 0x7e, 0x7c, 0x89, 0x5b, 0x2e, 0x48, 0x01,
-	0xa8, 0xf8, 0x3e, 0xf0, 0x61, 0xc5, 0x74, 0x08, 0x6f, 0x91, 0xf3, 0xeb, 0x21, 0x78, 0x6e, 0x69,
-	0x7c, 0xb9, 0xb4, 0x3e, 0x4b, 0x37, 0x17, 0x8b, 0xab, 0x65, 0xbf, 0x3a, 0xad, 0x64, 0x24, 0x3f,
-	0xbd, 0xf8, 0x2b, 0xa8, 0x6c, 0x61, 0xb3, 0xa6, 0x74, 0x3a, 0xf2, 0x71, 0x07, 0x47, 0x8e, 0xee,
-	0x27, 0x30, 0xbe, 0xab, 0x7d, 0x4a, 0xc6, 0x56, 0x5a, 0x17, 0xd6, 0x6c, 0xff, 0xb3, 0xe6, 0xf8,
-	0x9f, 0xb5, 0x43, 0xc7, 0xff, 0xd4, 0x0a, 0x96, 0x82, 0x2f, 0xbf, 0xad, 0x70, 0x92, 0xc5, 0x20,
-	0x36, 0x61, 0x29, 0x5e, 0xf4, 0x45, 0xd9, 0xff, 0x18, 0x16, 0xb7, 0xb0, 0x79, 0xa8, 0x2b, 0x72,
-	0xe7, 0x42, 0x8d, 0xff, 0x18, 0xae, 0xc7, 0xc8, 0xbd, 0x28, 0xcb, 0xdf, 0x87, 0x1b, 0x5b, 0xd8,
-	0x6c, 0xe0, 0x8e, 0xa2, 0x7e, 0xd2, 0xc7, 0xaa, 0x79, 0xa1, 0xe6, 0x63, 0x10, 0x87, 0x09, 0xbf,
-	0xa8, 0x31, 0xfc, 0x81, 0x83, 0x85, 0xc0, 0xde, 0x74, 0x2c, 0x5f, 0x02, 0x60, 0xc2, 0x0a, 0x39,
-	0x18, 0x0f, 0x33, 0x12, 0x03, 0x43, 0xaf, 0xc1, 0xdc, 0x56, 0x7d, 0xdf, 0x01, 0x54, 0x9b, 0x4d,
-	0xad, 0xaf, 0x9a, 0x8e, 0x13, 0x7f, 0x98, 0x91, 0x22, 0xb1, 0x96, 0xdc, 0x6d, 0xd5, 0x95, 0x3b,
-	0xee, 0xc8, 0xf5, 0x60, 0xb5, 0x2c, 0x8c, 0x6d, 0x37, 0xc4, 0xf7, 0x42, 0xc7, 0xd5, 0x1d, 0xf6,
-	0x9b, 0x30, 0xc9, 0xc2, 0xe9, 0xec, 0x0e, 0x19, 0xb5, 0x8f, 0x5c, 0xfc, 0xed, 0x94, 0x9f, 0x1f,
-	0x4d, 0x5b, 0x0a, 0xe9, 0xd9, 0x1f, 0xdb, 0x6e, 0x58, 0x07, 0x3f, 0x14, 0x51, 0xd9, 0x81, 0x23,
-	0xc8, 0xee, 0xca, 0x5d, 0x6c, 0x1b, 0x2f, 0x91, 0x6f, 0xe2, 0x2c, 0x74, 0xed, 0x18, 0xdb, 0xf1,
-	0x23, 0x6b, 0xf3, 0x78, 0x10, 0x54, 0x83, 0x62, 0x5d, 0xc7, 0xb2, 0x89, 0x5b, 0x55, 0x93, 0xcf,
-	0xa5, 0xd8, 0x0e, 0x1e, 0x1b, 0x12, 0x03, 0x8e, 0x7e, 0xc2, 0x72, 0xf4, 0x7e, 0x67, 0x8e, 0xee,
-	0xc2, 0x8c, 0x84, 0x4f, 0xfa, 0x06, 0x6e, 0xc8, 0xa6, 0x5c, 0x6d, 0x36, 0xb1, 0x61, 0xf0, 0x79,
-	0xe2, 0x96, 0x42, 0x70, 0x3f, 0xed, 0x51, 0xaf, 0xa3, 0xc9, 0x2d, 0xbe, 0x10, 0xa4, 0xb5, 0xe1,
-	0xe8, 0x3d, 0x98, 0xdb, 0x54, 0x74, 0xc3, 0x3c, 0xc0, 0x58, 0xad, 0x6b, 0xaa, 0x8a, 0x9b, 0xf6,
-	0x50, 0x8a, 0x89, 0x86, 0xc2, 0x91, 0xa1, 0x44, 0x4a, 0x40, 0x02, 0x14, 0xf6, 0x3b, 0xb2, 0x79,
-	0xa2, 0xe9, 0x5d, 0x1e, 0xc8, 0xbc, 0xb9, 0xff, 0x68, 0x09, 0x4a, 0x1b, 0xea, 0x73, 0x45, 0xd7,
-	0xd4, 0x2e, 0x56, 0x4d, 0xbe, 0x44, 0xd0, 0x2c, 0x08, 0xed, 0xc0, 0x34, 0x39, 0xe4, 0x1b, 0x9f,
-	0xf5, 0x14, 0x1d, 0x1b, 0x55, 0x93, 0x9f, 0x4c, 0x31, 0xb9, 0x01, 0x5e, 0xb4, 0x06, 0xe8, 0x49,
-	0x5f, 0xd3, 0x65, 0xba, 0x5d, 0xa9, 0xeb, 0x9f, 0x22, 0x6a, 0x23, 0x30, 0xe8, 0x09, 0xcc, 0xb3,
-	0x50, 0x6f, 0x85, 0xa7, 0x53, 0x4c, 0x4b, 0xb4, 0x08, 0xf4, 0x14, 0x16, 0x89, 0x75, 0xfb, 0x58,
-	0x6d, 0x29, 0x6a, 0x9b, 0x18, 0x39, 0xd8, 0xd5, 0x4c, 0xe5, 0x44, 0x21, 0x2a, 0x2e, 0xa5, 0x50,
-	0x31, 0x54, 0x12, 0xfa, 0x00, 0x16, 0x98, 0x79, 0x68, 0x31, 0x3a, 0x66, 0x52, 0xe8, 0x88, 0x91,
-	0x81, 0x56, 0x60, 0x7c, 0xab, 0xbe, 0xcf, 0x5f, 0xa6, 0xa2, 0xec, 0x43, 0xba, 0x45, 0x04, 0xd6,
-	0x3b, 0x5a, 0xbf, 0xe5, 0x2c, 0xb6, 0x64, 0x91, 0x59, 0x19, 0xc0, 0x21, 0x96, 0xbb, 0xdb, 0x0d,
-	0x1e, 0xd9, 0x19, 0x80, 0xfd, 0x67, 0x05, 0x5b, 0xeb, 0x8b, 0x39, 0x97, 0xb3, 0x04, 0x1f, 0x80,
-	0x5a, 0xe7, 0x8c, 0xc6, 0xdd, 0xaa, 0xc9, 0xcf, 0xa5, 0x39, 0x67, 0x2e, 0x1b, 0xe2, 0x21, 0x5f,
-	0xef, 0x60, 0x59, 0xed, 0xf7, 0xf8, 0x79, 0x72, 0x1c, 0x9c, 0x5f, 0xf4, 0x31, 0xf0, 0xee, 0x1e,
-	0xde, 0xec, 0xf4, 0x3f, 0x63, 0x4f, 0xc2, 0x42, 0x8a, 0xb9, 0x8a, 0x95, 0x82, 0x3e, 0x84, 0x2b,
-	0x2e, 0x6e, 0x17, 0x9b, 0xac, 0x82, 0x2b, 0x29, 0x14, 0xc4, 0x09, 0xf1, 0x8d, 0x60, 0x5f, 0xd7,
-	0xba, 0xac, 0x02, 0xfe, 0x07, 0x8d, 0x20, 0x20, 0x05, 0x1d, 0xc3, 0x55, 0x17, 0x77, 0xd0, 0xd4,
-	0x7a, 0x98, 0x55, 0x71, 0x35, 0x85, 0x8a, 0x78, 0x31, 0x7e, 0xcf, 0x25, 0x61, 0xd9, 0xd0, 0x54,
-	0x5e, 0x20, 0xfb, 0x21, 0x04, 0xb7, 0x76, 0xf7, 0x8e, 0x6c, 0xc9, 0x51, 0xcd, 0x77, 0x31, 0x7e,
-	0xd6, 0x19, 0x48, 0xb8, 0xa7, 0xe9, 0x66, 0xd5, 0xe4, 0xaf, 0xa5, 0xd9, 0xdd, 0xd1, 0x32, 0xd0,
-	0x32, 0x5c, 0x72, 0x36, 0xf0, 0x63, 0xac, 0x1b, 0x56, 0x38, 0x5a, 0x24, 0x86, 0x04, 0xc1, 0x68,
-	0xd1, 0xdd, 0x99, 0xb5, 0x01, 0x7f, 0x9d, 0xd0, 0x78, 0x00, 0xf1, 0xdb, 0x31, 0x98, 0x8d, 0x38,
-	0x14, 0xa1, 0xd8, 0xb4, 0x02, 0x97, 0x63, 0x22, 0xae, 0x14, 0x46, 0x58, 0x3a, 0xad, 0xaa, 0xe4,
-	0xb9, 0xec, 0x65, 0xa7, 0x1e, 0xc0, 0x1f, 0x93, 0xb2, 0x3f, 0x2c, 0x26, 0x95, 0x01, 0xea, 0x9a,
-	0x6a, 0xf4, 0xbb, 0x24, 0x0f, 0xcf, 0xd9, 0x71, 0xcf, 0x83, 0x58, 0x2b, 0x75, 0xd0, 0x3f, 0x36,
-	0x9a, 0xba, 0xd2, 0xb3, 0x62, 0x2d, 0x89, 0x9b, 0x13, 0xf6, 0x4a, 0x05, 0xe1, 0xd6, 0xd8, 0x58,
-	0xd8, 0x0e, 0x7e, 0x8e, 0x3b, 0x24, 0x78, 0x15, 0xa5, 0x30, 0xc2, 0xf2, 0xd5, 0x2c, 0xf0, 0xc0,
-	0x94, 0xcd, 0xbe, 0x41, 0xe2, 0x57, 0x51, 0x8a, 0xc0, 0x88, 0x6f, 0xc2, 0xd4, 0x16, 0x36, 0xb7,
-	0xea, 0xfb, 0x4e, 0x86, 0x13, 0x39, 0x95, 0x5c, 0xcc, 0x54, 0x8a, 0x0d, 0x98, 0x76, 0xd8, 0x69,
-	0x1a, 0xb2, 0x6e, 0x3b, 0x36, 0x6e, 0x94, 0x63, 0xa3, 0x49, 0x88, 0x45, 0x2c, 0x3e, 0x80, 0x99,
-	0xa3, 0x5e, 0x4b, 0x36, 0xb1, 0xcf, 0x8e, 0x64, 0x72, 0x6c, 0x09, 0xb3, 0x70, 0x99, 0x91, 0x60,
-	0x9b, 0x62, 0x55, 0x99, 0xe2, 0x81, 0x3f, 0x5b, 0x62, 0x83, 0x8a, 0xa3, 0xa9, 0x1c, 0xce, 0xe9,
-	0x7c, 0x89, 0xcd, 0x02, 0x4c, 0x30, 0xd5, 0x4e, 0x51, 0xa2, 0x7f, 0xfe, 0x8d, 0x32, 0x9e, 0xe2,
-	0xd4, 0x78, 0x6c, 0xe2, 0x6d, 0xb8, 0x39, 0xd4, 0x42, 0x3a, 0x92, 0x13, 0x10, 0x02, 0x64, 0x56,
-	0x5e, 0x93, 0x74, 0x00, 0x08, 0xb2, 0x16, 0x39, 0x35, 0x9f, 0x7c, 0x5b, 0xc5, 0xdd, 0x63, 0xb9,
-	0xd3, 0xc7, 0x74, 0xff, 0xdb, 0x3f, 0xe2, 0x75, 0xb8, 0x16, 0xa9, 0x87, 0x9a, 0xb1, 0x4c, 0x56,
-	0x3b, 0x50, 0x9a, 0xb2, 0xc5, 0xa6, 0x5b, 0x56, 0xbe, 0x0e, 0x97, 0x5c, 0x4a, 0xba, 0x31, 0x6e,
-	0x43, 0xd6, 0xfa, 0xa7, 0x2b, 0x5a, 0xa2, 0x2b, 0x6a, 0x81, 0xe8, 0x56, 0x20, 0x68, 0x11, 0xc3,
-	0xbd, 0x70, 0x5d, 0x28, 0xb7, 0x06, 0x9b, 0x9a, 0xce, 0x3a, 0x99, 0x97, 0x2d, 0x25, 0x34, 0x58,
-	0x49, 0xa6, 0xe6, 0xa2, 0x8a, 0x0a, 0xd3, 0x5a, 0x42, 0xb5, 0xb5, 0xd7, 0x37, 0x2f, 0x70, 0x18,
-	0xa3, 0x92, 0x72, 0x7b, 0x41, 0x23, 0xb4, 0xd2, 0x05, 0xfd, 0x7b, 0xd6, 0x5e, 0x14, 0x34, 0xef,
-	0x39, 0xd4, 0x5a, 0xee, 0xfc, 0xb4, 0xc2, 0xad, 0x12, 0xbf, 0x5a, 0x81, 0xdc, 0x46, 0x57, 0x56,
-	0x3a, 0xb4, 0x05, 0x55, 0x3c, 0x3f, 0xad, 0xe4, 0xb0, 0x05, 0x90, 0x6c, 0x38, 0xba, 0xe6, 0xf4,
-	0x86, 0xc6, 0x59, 0x56, 0xda, 0x22, 0x7a, 0x04, 0xd3, 0xe4, 0x23, 0x8d, 0x3b, 0x9d, 0xb2, 0xc6,
-	0x47, 0xa4, 0xd0, 0x54, 0xd4, 0x27, 0x00, 0xbd, 0x03, 0x93, 0x24, 0xfe, 0xed, 0x68, 0x6d, 0x45,
-	0x4d, 0x54, 0x33, 0x04, 0x04, 0xfa, 0xd8, 0xd1, 0x16, 0x7b, 0x84, 0x27, 0xd2, 0xca, 0x62, 0x1c,
-	0xfe, 0x35, 0xc8, 0x91, 0x16, 0x8e, 0x5d, 0x55, 0xb8, 0xf3, 0x40, 0x60, 0xe8, 0x6d, 0x28, 0x59,
-	0x71, 0xd2, 0xb1, 0xb9, 0x90, 0x56, 0x0f, 0xcb, 0x8d, 0x6e, 0x43, 0xbe, 0xae, 0x75, 0x7b, 0xb2,
-	0x3a, 0x20, 0x55, 0x46, 0xb1, 0x56, 0x3a, 0x3f, 0xad, 0xe4, 0x9b, 0x36, 0x48, 0x72, 0x70, 0x68,
-	0x91, 0x56, 0x63, 0xa4, 0x76, 0xa8, 0x15, 0xce, 0x4f, 0x2b, 0x59, 0x55, 0xee, 0x62, 0x5a, 0x97,
-	0xdd, 0x83, 0x22, 0x99, 0x07, 0x42, 0x42, 0xea, 0x87, 0xda, 0xd4, 0xf9, 0x69, 0xa5, 0x78, 0xe2,
-	0x00, 0x25, 0x0f, 0x8f, 0x96, 0xa1, 0x60, 0x19, 0x40, 0x68, 0x27, 0x09, 0xed, 0xe4, 0xf9, 0x69,
-	0xa5, 0xd0, 0xa1, 0x30, 0xc9, 0xc5, 0x8a, 0x55, 0xa8, 0x90, 0x14, 0x77, 0x10, 0x4e, 0xac, 0x13,
-	0xfa, 0x2a, 0x51, 0x84, 0xa5, 0x78, 0x11, 0x74, 0xd7, 0xbe, 0x01, 0x57, 0x19, 0x1a, 0x9a, 0x5b,
-	0x27, 0x55, 0xb0, 0x08, 0x42, 0x14, 0x33, 0x15, 0xfd, 0x16, 0x5c, 0xb7, 0xb1, 0xc1, 0x52, 0x2f,
-	0xa9, 0xf8, 0x25, 0x28, 0xc7, 0x09, 0xa0, 0x2a, 0xfe, 0x9c, 0x83, 0xac, 0x95, 0x9e, 0xc7, 0x9d,
-	0xb9, 0xab, 0x74, 0xe5, 0xc6, 0x58, 0x84, 0xbd, 0x6c, 0xb7, 0x7d, 0xca, 0x7d, 0x47, 0x8e, 0xf5,
-	0xf7, 0x3f, 0x8b, 0xac, 0xd7, 0xb2, 0xf6, 0x11, 0xb6, 0xa2, 0x90, 0xcd, 0x12, 0x55, 0xba, 0x7d,
-	0x14, 0x57, 0xba, 0x25, 0x3b, 0x68, 0x9c, 0xb7, 0x69, 0x63, 0xea, 0xb7, 0x47, 0xa1, 0xca, 0x74,
-	0x22, 0xbd, 0x4f, 0xf0, 0x97, 0xa7, 0xdd, 0x11, 0x25, 0x61, 0x3e, 0xad, 0xe9, 0xc3, 0xeb, 0x42,
-	0x39, 0xb6, 0x2e, 0x2c, 0xa4, 0x55, 0x14, 0x57, 0x1c, 0xfa, 0xdc, 0x52, 0xf1, 0x25, 0xdc, 0xd2,
-	0x16, 0x5b, 0xf7, 0x41, 0x5a, 0xf3, 0x3c, 0x5e, 0x31, 0x6f, 0x05, 0x82, 0x9e, 0x39, 0x10, 0x5f,
-	0x87, 0xfc, 0x41, 0xbf, 0xdb, 0x95, 0xf5, 0x01, 0x5a, 0x85, 0xfc, 0x86, 0x6a, 0xea, 0x0a, 0x0e,
-	0x06, 0x43, 0x4a, 0x60, 0x21, 0x07, 0x92, 0x43, 0x23, 0x7e, 0x01, 0x30, 0xc9, 0x62, 0xd0, 0x6a,
-	0xa8, 0x78, 0xf5, 0x9d, 0x85, 0x60, 0x0d, 0x7b, 0x03, 0x0a, 0x16, 0x24, 0x7c, 0x36, 0x5c, 0xb0,
-	0xe5, 0x85, 0xf7, 0xf4, 0x76, 0xf0, 0x68, 0xd8, 0x30, 0x74, 0x2f, 0x78, 0x29, 0x94, 0x65, 0x89,
-	0x02, 0x77, 0x43, 0x15, 0xc8, 0xef, 0xe9, 0x6d, 0xa2, 0x2b, 0xc7, 0x92, 0x39, 0x50, 0x2b, 0xf1,
-	0x21, 0x11, 0xd0, 0xe9, 0x37, 0xd1, 0x3f, 0xf4, 0x90, 0x74, 0xd1, 0xbc, 0xd5, 0xcb, 0xa7, 0x88,
-	0xe8, 0x3e, 0xce, 0xd8, 0xde, 0x52, 0xe1, 0x42, 0x7b, 0x4b, 0xc5, 0xe1, 0xbd, 0x25, 0x48, 0xd2,
-	0x5b, 0x2a, 0xbd, 0x44, 0x6f, 0x69, 0x54, 0x3f, 0x67, 0xf2, 0x7f, 0xd0, 0xcf, 0x99, 0xba, 0x80,
-	0x7e, 0xce, 0x2a, 0x4c, 0xd7, 0x94, 0x4e, 0xc7, 0x52, 0xac, 0xca, 0xc7, 0x1d, 0xdc, 0x22, 0xcd,
-	0x2e, 0x37, 0x13, 0x08, 0x20, 0x23, 0x1b, 0x92, 0x97, 0x52, 0x34, 0x24, 0x67, 0x62, 0x1a, 0x92,
-	0xd1, 0xad, 0xba, 0xcb, 0xe9, 0x5b, 0x75, 0xe8, 0xe5, 0x5b, 0x75, 0xeb, 0xa4, 0x13, 0x4e, 0xc1,
-	0xa1, 0x16, 0x55, 0x24, 0x0e, 0x3d, 0x86, 0x59, 0x0f, 0xee, 0x59, 0x93, 0xa6, 0x65, 0x15, 0x25,
-	0x00, 0x3d, 0x80, 0x6b, 0x1e, 0x38, 0x5c, 0x4e, 0xcf, 0x13, 0x93, 0x86, 0x91, 0xa0, 0x1a, 0x2c,
-	0x46, 0xa3, 0x69, 0x89, 0xbd, 0x40, 0x44, 0x0c, 0xa5, 0x11, 0xff, 0x3d, 0x0e, 0xf9, 0x77, 0xf1,
-	0xf1, 0x53, 0x4d, 0x7b, 0x16, 0x17, 0xfd, 0x57, 0x43, 0x97, 0x9f, 0x3e, 0x5f, 0x17, 0xbc, 0xe5,
-	0x7c, 0x13, 0x2e, 0x6d, 0xab, 0x26, 0x6e, 0xeb, 0x04, 0x70, 0x38, 0xe8, 0xd1, 0xfe, 0x7b, 0x6d,
-	0xf6, 0xfc, 0xb4, 0x72, 0x49, 0xf1, 0xa3, 0xa4, 0x20, 0xad, 0x95, 0xda, 0x1d, 0xe0, 0xa6, 0x8e,
-	0x4d, 0xd7, 0x1d, 0x92, 0xd4, 0xce, 0xa0, 0x30, 0xc9, 0xc5, 0xa2, 0x07, 0x30, 0x63, 0x7f, 0x1f,
-	0x28, 0x6d, 0x55, 0x51, 0xdb, 0x6f, 0xe3, 0x01, 0xf5, 0x8c, 0xe4, 0x7e, 0xd1, 0x08, 0xe0, 0xa4,
-	0x10, 0x35, 0xda, 0x4b, 0x97, 0x6b, 0xcf, 0xd3, 0xa0, 0x56, 0x6c, 0x3a, 0x4c, 0xc1, 0xe0, 0xb6,
-	0xc7, 0x06, 0xb7, 0x7c, 0x22, 0x81, 0x56, 0x70, 0x2b, 0xb6, 0x1c, 0xa6, 0x60, 0x87, 0xf3, 0x08,
-	0x4a, 0xae, 0xbf, 0x4c, 0xe4, 0x68, 0xaf, 0x50, 0x91, 0xa5, 0x13, 0x8f, 0xcd, 0xce, 0xd8, 0x19,
-	0x39, 0xe2, 0xdb, 0xb0, 0xea, 0xbe, 0x11, 0x70, 0xd7, 0x8e, 0x6e, 0x03, 0x72, 0xfd, 0xed, 0x4c,
-	0xb2, 0x93, 0x63, 0x0a, 0xcc, 0xaa, 0xd8, 0x19, 0xa6, 0xfb, 0x2f, 0x3e, 0x81, 0xb5, 0xa4, 0xc2,
-	0x68, 0xe5, 0xba, 0xec, 0xee, 0x39, 0x5a, 0x5e, 0x4e, 0xd3, 0x30, 0x4d, 0xa1, 0x92, 0x83, 0x16,
-	0x37, 0x61, 0x39, 0x50, 0xfd, 0x53, 0x0c, 0x33, 0x9a, 0x24, 0x36, 0x1a, 0xf0, 0x4a, 0x02, 0x39,
-	0xd4, 0xbc, 0x4d, 0xff, 0xa4, 0x73, 0x29, 0xfc, 0x8e, 0x6f, 0x96, 0x77, 0xe0, 0xee, 0xb6, 0x6a,
-	0x85, 0x30, 0x5f, 0x29, 0x6e, 0x7b, 0xdd, 0xba, 0xa6, 0x9e, 0x28, 0xed, 0x7e, 0x8a, 0x2a, 0x41,
-	0x02, 0xd8, 0xc7, 0x7a, 0x57, 0x31, 0x8c, 0xa8, 0xab, 0x30, 0xc4, 0xa6, 0xe8, 0x34, 0x37, 0x5f,
-	0x82, 0x52, 0x03, 0xbb, 0x07, 0x9e, 0xde, 0x82, 0xb1, 0x20, 0x71, 0x07, 0xb2, 0x92, 0xd6, 0xc1,
-	0x17, 0x24, 0xed, 0xaf, 0x1c, 0xdc, 0xb2, 0x46, 0xa3, 0xe8, 0xd8, 0xca, 0x7f, 0xde, 0xc1, 0x96,
-	0x3f, 0xf7, 0x6c, 0x3e, 0xd4, 0x46, 0xb4, 0x68, 0xd0, 0x72, 0x28, 0xfd, 0x72, 0xae, 0x28, 0x83,
-	0x99, 0x17, 0xa5, 0x64, 0x2e, 0x28, 0xb3, 0x2c, 0xa5, 0x07, 0x47, 0x22, 0x4c, 0x7a, 0x26, 0x38,
-	0x79, 0x98, 0xe4, 0x83, 0xd5, 0x0a, 0xce, 0x5d, 0x86, 0xf8, 0x17, 0x0e, 0x6e, 0xd2, 0x21, 0xec,
-	0xe9, 0xed, 0xf4, 0x23, 0xb8, 0x13, 0xf9, 0xcc, 0xe7, 0x61, 0x26, 0x98, 0xcc, 0xd9, 0x74, 0x11,
-	0xe6, 0xfb, 0xc1, 0x89, 0xac, 0xcf, 0xd3, 0x14, 0xf3, 0xee, 0x23, 0x98, 0x09, 0x3e, 0x72, 0x41,
-	0x45, 0xc8, 0xed, 0x1d, 0x3e, 0xdc, 0x90, 0x66, 0x32, 0x88, 0x87, 0xb9, 0xed, 0xdd, 0x83, 0xc3,
-	0xea, 0x6e, 0x7d, 0xe3, 0xa3, 0x46, 0xf5, 0xb0, 0xfa, 0x51, 0xb5, 0x5e, 0xdf, 0x38, 0x38, 0x98,
-	0xe1, 0xc2, 0x98, 0xa3, 0xfd, 0x9d, 0xbd, 0x6a, 0x63, 0x66, 0x6c, 0xfd, 0x1f, 0x08, 0x72, 0xd6,
-	0xd0, 0x0c, 0xf4, 0x00, 0x8a, 0xee, 0x29, 0x47, 0xce, 0x9b, 0x9a, 0xe0, 0xf3, 0x27, 0x81, 0x0f,
-	0x23, 0x68, 0x8d, 0x99, 0x41, 0x47, 0xce, 0x73, 0x29, 0xef, 0x81, 0x0d, 0x2a, 0xfb, 0xe8, 0x43,
-	0x0f, 0x7d, 0x84, 0x4a, 0x2c, 0xde, 0x15, 0xbb, 0x09, 0x25, 0xe6, 0x9d, 0x0b, 0xba, 0xea, 0xe3,
-	0x60, 0x5f, 0xca, 0x08, 0x42, 0x14, 0xca, 0x95, 0x53, 0x07, 0xf0, 0x9e, 0xae, 0x20, 0x3e, 0xa0,
-	0xd8, 0xed, 0x2f, 0x0a, 0x57, 0x23, 0x30, 0xec, 0x18, 0x83, 0x3d, 0x3c, 0x77, 0x8c, 0x31, 0x8f,
-	0x5a, 0xdc, 0x31, 0xc6, 0xbd, 0x3d, 0x11, 0x33, 0xa8, 0x4b, 0x5e, 0xa6, 0x44, 0xbe, 0xf0, 0x40,
-	0x77, 0x3c, 0xf6, 0x61, 0xaf, 0x4b, 0x84, 0xff, 0x1b, 0x49, 0xe7, 0xaa, 0x6b, 0xc1, 0x7c, 0xe4,
-	0x9b, 0x0c, 0x74, 0xd3, 0x93, 0x11, 0xfb, 0x12, 0x44, 0xb8, 0x35, 0x9c, 0xc8, 0xd5, 0x62, 0x80,
-	0x10, 0xff, 0x74, 0x02, 0x2d, 0x7b, 0x52, 0x86, 0x3f, 0xdd, 0x10, 0x5e, 0x49, 0x40, 0xe9, 0x2a,
-	0x95, 0x48, 0x17, 0xd8, 0xf7, 0xaa, 0xe0, 0x7a, 0xf4, 0xfc, 0x3b, 0xe2, 0xcb, 0x71, 0x68, 0x57,
-	0xe6, 0x87, 0x30, 0x1b, 0xd1, 0xa2, 0x46, 0x37, 0x9c, 0xda, 0x33, 0xb6, 0x4d, 0x2e, 0x88, 0xc3,
-	0x48, 0x5c, 0xf9, 0xcf, 0x43, 0x2d, 0x70, 0x36, 0xbb, 0x45, 0xaf, 0x44, 0x0b, 0x89, 0xb8, 0x57,
-	0x10, 0xee, 0x26, 0x21, 0x75, 0xf5, 0xfe, 0x14, 0x26, 0xec, 0x9b, 0x14, 0x34, 0xe7, 0xcd, 0x81,
-	0x77, 0x1f, 0x22, 0xcc, 0x07, 0xa0, 0x2e, 0xe3, 0x03, 0x28, 0xba, 0x57, 0x1f, 0xae, 0xaf, 0x08,
-	0x5e, 0xa7, 0xb8, 0xbe, 0x22, 0x7c, 0x4b, 0x92, 0x41, 0x3f, 0x87, 0x3c, 0x6d, 0xd6, 0x23, 0x46,
-	0x0b, 0x7b, 0x0c, 0x17, 0x82, 0x60, 0x97, 0xf7, 0x37, 0x1c, 0xdc, 0x4a, 0xd2, 0x48, 0x47, 0xeb,
-	0xb1, 0x07, 0x2f, 0xb6, 0xb9, 0x2f, 0xbc, 0x9a, 0x8a, 0xc7, 0xbf, 0x45, 0x42, 0x4d, 0x6f, 0x66,
-	0x8b, 0xc4, 0xb5, 0xe1, 0x99, 0x2d, 0x12, 0xdf, 0x33, 0x27, 0x0e, 0x22, 0xae, 0x47, 0xe9, 0x3a,
-	0x88, 0x11, 0x7d, 0x50, 0xd7, 0x41, 0x8c, 0x6c, 0x76, 0x66, 0xd0, 0xfb, 0x80, 0xc2, 0x1d, 0x4b,
-	0xb4, 0x14, 0x16, 0xe0, 0xef, 0x84, 0x0a, 0x37, 0x86, 0x50, 0xb8, 0xc2, 0xdb, 0xb0, 0x10, 0xdd,
-	0xaf, 0x44, 0xb7, 0x7c, 0xec, 0x31, 0xfd, 0x50, 0xe1, 0xf6, 0x08, 0x2a, 0x57, 0xd1, 0x0a, 0xc0,
-	0x16, 0x36, 0x9d, 0xde, 0xd1, 0x24, 0x65, 0x23, 0x4d, 0x25, 0x61, 0xda, 0xdf, 0x38, 0x12, 0x33,
-	0xe8, 0xf7, 0x1c, 0xdc, 0x49, 0x96, 0xe7, 0xa2, 0xd7, 0x82, 0x51, 0x30, 0x49, 0x8e, 0x2d, 0xfc,
-	0x38, 0x25, 0x97, 0x3b, 0x8e, 0x5f, 0x73, 0x70, 0x63, 0x64, 0x76, 0x8b, 0xee, 0x47, 0x9f, 0xfd,
-	0xd8, 0x7c, 0x5a, 0xf8, 0xff, 0xe4, 0x0c, 0xae, 0x29, 0x4f, 0xe1, 0x66, 0x82, 0x94, 0x17, 0xfd,
-	0x88, 0x8a, 0x4e, 0x9e, 0x1e, 0x0b, 0xbe, 0xe5, 0x11, 0x33, 0xe8, 0x03, 0xb8, 0x3e, 0x34, 0xd7,
-	0x44, 0xf7, 0x28, 0x43, 0x92, 0x8c, 0x34, 0x24, 0xfd, 0x09, 0x2c, 0x0e, 0x4b, 0x03, 0xd1, 0x5d,
-	0xbf, 0xf0, 0x61, 0xb9, 0x62, 0x50, 0x76, 0xed, 0xf5, 0xaf, 0x5f, 0x94, 0x33, 0xdf, 0xbc, 0x28,
-	0x67, 0xbe, 0x7f, 0x51, 0xe6, 0xfe, 0xf3, 0xa2, 0xcc, 0x7d, 0x71, 0x56, 0xe6, 0xfe, 0x74, 0x56,
-	0xe6, 0xbe, 0x3a, 0x2b, 0x73, 0x5f, 0x9f, 0x95, 0xb9, 0x7f, 0x9e, 0x95, 0xb9, 0x7f, 0x9d, 0x95,
-	0x33, 0xdf, 0x9f, 0x95, 0xb9, 0x2f, 0xbf, 0x2b, 0x67, 0xbe, 0xfe, 0xae, 0x9c, 0xf9, 0xe6, 0xbb,
-	0x72, 0xe6, 0x78, 0x82, 0xd4, 0x1e, 0xaf, 0xfe, 0x37, 0x00, 0x00, 0xff, 0xff, 0x0f, 0xe8, 0x09,
-	0xcb, 0xde, 0x2e, 0x00, 0x00,
+	// 3130 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x1a, 0x4b, 0x70, 0xdb, 0xc6,
+	0x95, 0x90, 0x48, 0x91, 0x7c, 0xfa, 0x58, 0x5e, 0x7d, 0x0c, 0xc3, 0x32, 0x29, 0xc3, 0x9f, 0xc8,
+	0x1f, 0xc9, 0xad, 0x93, 0xb6, 0x69, 0x33, 0x69, 0x4c, 0x52, 0x1f, 0xab, 0x51, 0x2c, 0x19, 0x92,
+	0x9c, 0xd4, 0xc9, 0x24, 0x81, 0xc8, 0x15, 0x8d, 0x31, 0x09, 0x30, 0x00, 0xe8, 0x84, 0x39, 0xe5,
+	0xd4, 0xe9, 0xf4, 0x94, 0xb6, 0x87, 0xf6, 0xd8, 0x63, 0xcf, 0x3d, 0xf6, 0xd4, 0x5b, 0x73, 0xe8,
+	0xc1, 0xbd, 0xe5, 0xc4, 0xd4, 0xce, 0xa5, 0xa3, 0x53, 0x66, 0x7a, 0xe9, 0xb1, 0xb3, 0x8b, 0x05,
+	0xb0, 0xf8, 0x91, 0x44, 0xac, 0xe9, 0x0d, 0x78, 0xdf, 0xfd, 0xbc, 0xdd, 0xf7, 0x5b, 0x98, 0xec,
+	0x5a, 0xd8, 0xb4, 0xd6, 0x3a, 0xa6, 0x61, 0x1b, 0x28, 0x47, 0x7f, 0xa4, 0xd5, 0xa6, 0x66, 0x3f,
+	0xee, 0x1e, 0xad, 0xd5, 0x8d, 0xf6, 0xed, 0xa6, 0xd1, 0x34, 0x6e, 0x53, 0xec, 0x51, 0xf7, 0x98,
+	0xfe, 0xd1, 0x1f, 0xfa, 0xe5, 0x70, 0x49, 0xe5, 0xa6, 0x61, 0x34, 0x5b, 0xd8, 0xa7, 0xb2, 0xb5,
+	0x36, 0xb6, 0x6c, 0xb5, 0xdd, 0x71, 0x08, 0xe4, 0xdf, 0x0b, 0x30, 0xbb, 0x63, 0x18, 0x4f, 0xba,
+	0x9d, 0x5d, 0xb3, 0xa9, 0xe0, 0x4f, 0xba, 0xd8, 0xb2, 0xd1, 0x22, 0x4c, 0xd4, 0x0c, 0xe3, 0x89,
+	0x86, 0x45, 0x61, 0x59, 0x58, 0x29, 0x2a, 0xec, 0x0f, 0x5d, 0x81, 0xe9, 0x5d, 0xb3, 0xb9, 0xf1,
+	0x99, 0x8d, 0x4d, 0x5d, 0x6d, 0x6d, 0xaf, 0x8b, 0x63, 0x14, 0x1d, 0x04, 0xa2, 0x37, 0x60, 0xaa,
+	0xd2, 0xb5, 0x1f, 0x1b, 0xa6, 0xf6, 0x39, 0xde, 0x34, 0x4c, 0x31, 0xbb, 0x2c, 0xac, 0xcc, 0xdc,
+	0x39, 0xb7, 0xe6, 0xcc, 0xc6, 0x43, 0x35, 0x2a, 0x75, 0x5b, 0x33, 0x74, 0x25, 0x40, 0xfc, 0x8b,
+	0x6c, 0x61, 0x7c, 0x36, 0x2b, 0xff, 0x5d, 0x80, 0xb3, 0xdc, 0xa8, 0xac, 0x8e, 0xa1, 0x5b, 0x18,
+	0xad, 0xc3, 0xcc, 0xae, 0xd9, 0x54, 0x75, 0xed, 0x73, 0x95, 0x70, 0x6e, 0xaf, 0x3b, 0xc3, 0xab,
+	0x2e, 0x9d, 0xf4, 0xcb, 0xa2, 0x11, 0xc0, 0xdc, 0x32, 0xda, 0x9a, 0x8d, 0xdb, 0x1d, 0xbb, 0xa7,
+	0x84, 0x78, 0xd0, 0x2d, 0x98, 0x38, 0xb4, 0xb0, 0xe9, 0x8e, 0xbe, 0x3a, 0x7f, 0xd2, 0x2f, 0xcf,
+	0x76, 0x29, 0x84, 0xe3, 0x62, 0x34, 0xe8, 0xe7, 0x30, 0xb5, 0x89, 0x55, 0xbb, 0x6b, 0xe2, 0xcd,
+	0x96, 0xda, 0xb4, 0xc4, 0xf1, 0xe5, 0xf1, 0x95, 0x62, 0x55, 0x3a, 0xe9, 0x97, 0x17, 0x8f, 0x39,
+	0x38, 0xc7, 0x19, 0xa0, 0x97, 0x7f, 0x25, 0xc0, 0x39, 0x67, 0x26, 0x87, 0x96, 0xa6, 0x37, 0x0f,
+	0x8c, 0x27, 0x58, 0x77, 0x97, 0x79, 0x1e, 0x72, 0xf4, 0x9f, 0xad, 0xb2, 0xf3, 0x13, 0x59, 0xbe,
+	0xb1, 0x14, 0xcb, 0x87, 0x44, 0xc8, 0xef, 0x63, 0xf3, 0xa9, 0x56, 0xc7, 0xe2, 0x38, 0x15, 0xea,
+	0xfe, 0xca, 0xbf, 0x19, 0x03, 0x31, 0x3a, 0x90, 0x53, 0x5d, 0xd9, 0xf0, 0x5a, 0x8d, 0xa5, 0x5b,
+	0x2b, 0xf4, 0x18, 0x66, 0xe8, 0xb0, 0x36, 0x3e, 0xeb, 0x68, 0x26, 0xb6, 0x2a, 0x36, 0x9d, 0xc3,
+	0xe4, 0x1d, 0x69, 0xcd, 0xb1, 0xe2, 0x35, 0xd7, 0x8a, 0xd7, 0x0e, 0x5c, 0x2b, 0xae, 0x5e, 0xf9,
+	0xaa, 0x5f, 0x16, 0xc8, 0x28, 0xed, 0x00, 0xa7, 0xaf, 0xe3, 0xcb, 0x6f, 0xca, 0x82, 0x12, 0x92,
+	0x2b, 0xdf, 0x02, 0xe4, 0xac, 0x45, 0xa5, 0xd1, 0xd6, 0xf4, 0x21, 0x66, 0x2f, 0x6f, 0xc2, 0x5c,
+	0x80, 0x9a, 0x2d, 0xda, 0x6d, 0xc8, 0x53, 0x80, 0xb7, 0x5a, 0x0b, 0x27, 0xfd, 0xf2, 0x59, 0xd5,
+	0x01, 0x71, 0x93, 0x74, 0xa9, 0xe4, 0x9b, 0xae, 0x51, 0x13, 0xdb, 0x1a, 0xa6, 0xb4, 0x0a, 0x88,
+	0x27, 0x66, 0x3a, 0x7d, 0xe3, 0x15, 0x86, 0x1b, 0xaf, 0xfc, 0x29, 0x9c, 0xdb, 0xc2, 0x36, 0xbf,
+	0x4b, 0x16, 0x67, 0x7b, 0x0f, 0xba, 0xd8, 0xec, 0xb9, 0xb6, 0x47, 0x7f, 0x50, 0x09, 0x60, 0x4f,
+	0x6d, 0xe2, 0xfb, 0xdd, 0xf6, 0x11, 0x76, 0x2c, 0x2f, 0xa7, 0x70, 0x10, 0x74, 0x0d, 0x66, 0xb6,
+	0xf5, 0x7a, 0xab, 0xdb, 0xc0, 0xeb, 0xb8, 0x85, 0x6d, 0xdc, 0xa0, 0x3b, 0x54, 0x50, 0x42, 0x50,
+	0xf9, 0x7d, 0x10, 0xa3, 0x8a, 0xd9, 0x14, 0xde, 0x82, 0xe9, 0x00, 0x42, 0x14, 0x96, 0xc7, 0x57,
+	0x26, 0xef, 0xcc, 0x31, 0x03, 0xe7, 0x71, 0xd5, 0xec, 0x57, 0xfd, 0x72, 0x46, 0x09, 0xd2, 0xcb,
+	0x16, 0x94, 0xb7, 0xb0, 0x5d, 0xd5, 0x5a, 0x2d, 0xf5, 0xa8, 0x85, 0x63, 0x67, 0xf7, 0x63, 0x18,
+	0xbf, 0x6f, 0x7c, 0x2a, 0x0a, 0x43, 0xcd, 0xa7, 0x40, 0x14, 0x50, 0x13, 0x21, 0x0c, 0xe4, 0xf8,
+	0x1c, 0x60, 0xb5, 0xbd, 0xbd, 0xce, 0x8c, 0x57, 0x71, 0x7f, 0xe5, 0x3a, 0x2c, 0x27, 0x2b, 0x3d,
+	0xad, 0x99, 0x3d, 0x84, 0xa5, 0x2d, 0x6c, 0x1f, 0x98, 0x9a, 0xda, 0x3a, 0xcd, 0x69, 0xc9, 0x1f,
+	0xc3, 0xc5, 0x04, 0xb9, 0xa7, 0x35, 0xf2, 0xf7, 0xe1, 0xd2, 0x16, 0xb6, 0xd7, 0x71, 0x4b, 0xd3,
+	0x3f, 0xe9, 0x62, 0xdd, 0x3e, 0xd5, 0xe1, 0x63, 0x90, 0x07, 0x09, 0x3f, 0xad, 0x39, 0xfc, 0x51,
+	0x80, 0xc5, 0x90, 0xd5, 0xba, 0x23, 0x5f, 0x06, 0xe0, 0xbc, 0x1e, 0x3d, 0x32, 0xf7, 0x32, 0x0a,
+	0x07, 0x43, 0xaf, 0xc1, 0xfc, 0x56, 0x6d, 0xcf, 0x05, 0x54, 0xea, 0x75, 0xa3, 0xab, 0xdb, 0xae,
+	0x8f, 0xb9, 0x97, 0x51, 0x62, 0xb1, 0x44, 0xee, 0xb6, 0xee, 0xc9, 0x1d, 0x77, 0xe5, 0xfa, 0xb0,
+	0x6a, 0x16, 0xc6, 0xb6, 0xd7, 0xe5, 0xf7, 0x22, 0x07, 0xd9, 0x9b, 0xf6, 0x9b, 0x30, 0xc5, 0xc3,
+	0xd9, 0xea, 0x0e, 0x98, 0x75, 0x80, 0x5c, 0xfe, 0xdd, 0x74, 0x90, 0x1f, 0xcd, 0x10, 0x85, 0xec,
+	0x56, 0x18, 0xdb, 0x5e, 0x27, 0x57, 0x42, 0xc4, 0xe1, 0xf3, 0x13, 0x47, 0x90, 0xbd, 0xaf, 0xb6,
+	0x5d, 0x77, 0x43, 0xbf, 0xe9, 0x35, 0x62, 0x1a, 0x47, 0xd8, 0xf1, 0x6e, 0x59, 0x87, 0xc7, 0x87,
+	0xa0, 0x2a, 0x14, 0x6b, 0x26, 0x56, 0x6d, 0xdc, 0xa8, 0xd8, 0x62, 0x2e, 0x85, 0x39, 0xf8, 0x6c,
+	0x48, 0x0e, 0x39, 0x9b, 0x09, 0x7a, 0x5e, 0x03, 0x30, 0x74, 0x03, 0x66, 0x15, 0x7c, 0xdc, 0xb5,
+	0xf0, 0xba, 0x6a, 0xab, 0x95, 0x7a, 0x1d, 0x5b, 0x96, 0x98, 0xa7, 0x17, 0x56, 0x04, 0x1e, 0xa4,
+	0x3d, 0xec, 0xb4, 0x0c, 0xb5, 0x21, 0x16, 0xc2, 0xb4, 0x0e, 0x1c, 0xbd, 0x07, 0xf3, 0x9b, 0x9a,
+	0x69, 0xd9, 0xfb, 0x18, 0xeb, 0x35, 0x43, 0xd7, 0x71, 0xdd, 0x99, 0x4a, 0x71, 0xa4, 0xa9, 0x08,
+	0x74, 0x2a, 0xb1, 0x12, 0x90, 0x04, 0x85, 0xbd, 0x96, 0x6a, 0x1f, 0x1b, 0x66, 0x5b, 0x04, 0xba,
+	0x6e, 0xde, 0x3f, 0x5a, 0x86, 0xc9, 0x0d, 0xfd, 0xa9, 0x66, 0x1a, 0x7a, 0x1b, 0xeb, 0xb6, 0x38,
+	0x49, 0xd1, 0x3c, 0x08, 0xed, 0xc0, 0x0c, 0x3d, 0xe4, 0xbe, 0x03, 0x9d, 0x4a, 0xb1, 0xb8, 0x21,
+	0x5e, 0xb4, 0x06, 0xe8, 0x51, 0xd7, 0x30, 0x55, 0x66, 0xae, 0xcc, 0x29, 0x4c, 0x53, 0xb5, 0x31,
+	0x18, 0xf4, 0x08, 0x16, 0x78, 0xa8, 0xbf, 0xc3, 0x33, 0x29, 0x96, 0x25, 0x5e, 0x04, 0x7a, 0x0c,
+	0x4b, 0x74, 0x74, 0x7b, 0x58, 0x6f, 0x68, 0x7a, 0x93, 0x0e, 0xb2, 0x77, 0xdf, 0xb0, 0xb5, 0x63,
+	0x8d, 0xaa, 0x38, 0x93, 0x42, 0xc5, 0x40, 0x49, 0xe8, 0x03, 0x58, 0xe4, 0xd6, 0xa1, 0xc1, 0xe9,
+	0x98, 0x4d, 0xa1, 0x23, 0x41, 0x06, 0xba, 0x05, 0xe3, 0x5b, 0xb5, 0x3d, 0xf1, 0x2c, 0x13, 0xe5,
+	0x1c, 0xd2, 0x2d, 0x2a, 0xb0, 0xd6, 0x32, 0xba, 0x0d, 0x77, 0xb3, 0x15, 0x42, 0x46, 0x62, 0x03,
+	0xc7, 0xff, 0x88, 0xc8, 0x89, 0x0d, 0x9c, 0x3f, 0xe2, 0x86, 0xc9, 0x17, 0x77, 0x2e, 0xe7, 0x28,
+	0x3e, 0x04, 0x25, 0xe7, 0x8c, 0x79, 0xe4, 0x8a, 0x2d, 0xce, 0xa7, 0x39, 0x67, 0x1e, 0x1b, 0x71,
+	0x89, 0xb5, 0x16, 0x56, 0xf5, 0x6e, 0x47, 0x5c, 0xa0, 0xc7, 0xc1, 0xfd, 0x45, 0x1f, 0x83, 0xe8,
+	0xd9, 0xf0, 0x66, 0xab, 0xfb, 0x19, 0x7f, 0x12, 0x16, 0x53, 0xac, 0x55, 0xa2, 0x14, 0xf4, 0x21,
+	0x9c, 0xf3, 0x70, 0xf7, 0xb1, 0xcd, 0x2b, 0x38, 0x97, 0x42, 0x41, 0x92, 0x90, 0xc0, 0x0c, 0xf6,
+	0x4c, 0xa3, 0xcd, 0x2b, 0x10, 0xbf, 0xd7, 0x0c, 0x42, 0x52, 0xd0, 0x11, 0x9c, 0xf7, 0x70, 0xfb,
+	0x75, 0xa3, 0x83, 0x79, 0x15, 0xe7, 0x53, 0xa8, 0x48, 0x16, 0x13, 0xbc, 0xb9, 0x14, 0xac, 0x5a,
+	0x86, 0x2e, 0x4a, 0xd4, 0x1e, 0x22, 0x70, 0x62, 0xdd, 0x3b, 0x2a, 0x91, 0xa3, 0xdb, 0xef, 0x62,
+	0xfc, 0xa4, 0xd5, 0x53, 0x70, 0xc7, 0x30, 0xed, 0x8a, 0x2d, 0x5e, 0x48, 0x63, 0xdd, 0xf1, 0x32,
+	0xd0, 0x0a, 0x9c, 0x71, 0x0d, 0xf8, 0x21, 0x36, 0x2d, 0xe2, 0x8e, 0x96, 0xe8, 0x40, 0xc2, 0x60,
+	0xb4, 0xe4, 0x59, 0x66, 0xb5, 0x27, 0x5e, 0xa4, 0x34, 0x3e, 0x40, 0xfe, 0x66, 0x0c, 0xe6, 0x62,
+	0x0e, 0x45, 0xc4, 0x37, 0xdd, 0x82, 0xb3, 0x09, 0x1e, 0x57, 0x89, 0x22, 0x88, 0x4e, 0x92, 0x33,
+	0x3d, 0x55, 0xfd, 0xb8, 0xd5, 0x07, 0x04, 0x7d, 0x52, 0xf6, 0xfb, 0xf9, 0xa4, 0x12, 0x40, 0xcd,
+	0xd0, 0xad, 0x6e, 0x9b, 0x46, 0xe8, 0x39, 0xc7, 0xef, 0xf9, 0x10, 0xb2, 0x53, 0xfb, 0xdd, 0x23,
+	0xab, 0x6e, 0x6a, 0x1d, 0xe2, 0x6b, 0xa9, 0xdf, 0x9c, 0x70, 0x76, 0x2a, 0x0c, 0x27, 0x73, 0xe3,
+	0x61, 0x3b, 0xf8, 0x29, 0x6e, 0x51, 0xe7, 0x55, 0x54, 0xa2, 0x08, 0x72, 0x57, 0xf3, 0xc0, 0x7d,
+	0x5b, 0xb5, 0xbb, 0x16, 0xf5, 0x5f, 0x45, 0x25, 0x06, 0x23, 0xbf, 0x09, 0xd3, 0x5b, 0xd8, 0xde,
+	0xaa, 0xed, 0xb9, 0x11, 0x4e, 0xec, 0x52, 0x0a, 0x09, 0x4b, 0x29, 0xaf, 0xc3, 0x8c, 0xcb, 0xce,
+	0xc2, 0x90, 0x3b, 0xce, 0xc5, 0x26, 0x0c, 0xbb, 0xd8, 0x58, 0x10, 0x42, 0x88, 0xe5, 0xbb, 0x30,
+	0x7b, 0xd8, 0x69, 0xa8, 0x36, 0x0e, 0x8c, 0x63, 0x34, 0x39, 0x8e, 0x84, 0x39, 0x38, 0xcb, 0x49,
+	0x70, 0x86, 0x22, 0xff, 0x49, 0x00, 0x79, 0x3f, 0x18, 0x2d, 0xf1, 0x4e, 0xc5, 0xd5, 0x54, 0x8a,
+	0xc6, 0x74, 0x81, 0xc0, 0x66, 0x11, 0x26, 0xb8, 0x3c, 0xa8, 0xa8, 0xb0, 0xbf, 0xa0, 0xa1, 0x8c,
+	0xa7, 0x38, 0x35, 0x3e, 0x9b, 0x7c, 0x15, 0x2e, 0x0f, 0x1c, 0x21, 0x9b, 0xc9, 0x31, 0x48, 0x21,
+	0x32, 0x12, 0xd7, 0x8c, 0x3a, 0x01, 0x04, 0x59, 0x42, 0xce, 0x86, 0x4f, 0xbf, 0x49, 0xda, 0xf7,
+	0x50, 0x6d, 0x75, 0x31, 0xb3, 0x7f, 0xe7, 0x47, 0xbe, 0x08, 0x17, 0x62, 0xf5, 0xb0, 0x61, 0xac,
+	0xd0, 0xdd, 0x0e, 0x25, 0xad, 0x7c, 0x1a, 0xea, 0x25, 0x9c, 0xaf, 0xc3, 0x19, 0x8f, 0x92, 0x19,
+	0xc6, 0x55, 0xc8, 0x92, 0x7f, 0xb6, 0xa3, 0x93, 0x6c, 0x47, 0x09, 0x88, 0x99, 0x02, 0x45, 0xcb,
+	0x18, 0x6e, 0x46, 0x33, 0x46, 0xb5, 0xd1, 0xdb, 0x34, 0x4c, 0xfe, 0x92, 0x79, 0xd9, 0x54, 0xc2,
+	0x80, 0x5b, 0xa3, 0xa9, 0x39, 0xad, 0xa4, 0xc2, 0x26, 0x5b, 0xa8, 0x37, 0x76, 0xbb, 0xf6, 0x29,
+	0x4e, 0x63, 0x58, 0x50, 0xee, 0x6c, 0x68, 0x8c, 0x56, 0xb6, 0xa1, 0xff, 0xc8, 0x3a, 0x9b, 0x82,
+	0x16, 0xfc, 0x0b, 0xb5, 0x9a, 0x3b, 0xe9, 0x97, 0x85, 0x55, 0x7a, 0xaf, 0x96, 0x21, 0xb7, 0xd1,
+	0x56, 0xb5, 0x16, 0xab, 0x90, 0x15, 0x4f, 0xfa, 0xe5, 0x1c, 0x26, 0x00, 0xc5, 0x81, 0xa3, 0x0b,
+	0x6e, 0xe5, 0x6a, 0x9c, 0x67, 0x75, 0x60, 0xe8, 0x01, 0x2b, 0xe3, 0xa4, 0xb9, 0x4e, 0xa7, 0xc9,
+	0xfc, 0xa8, 0x14, 0xae, 0x5e, 0xe3, 0x5f, 0xac, 0xef, 0xc0, 0x14, 0xf5, 0x7f, 0x3b, 0x46, 0x53,
+	0xd3, 0x47, 0xca, 0x19, 0x42, 0x02, 0x03, 0xec, 0x68, 0x8b, 0x3f, 0xc2, 0x13, 0x69, 0x65, 0xf9,
+	0xbc, 0x64, 0x1d, 0x68, 0x71, 0xc7, 0xc9, 0x2a, 0xbc, 0x75, 0xa0, 0x30, 0xf4, 0x36, 0x4c, 0xee,
+	0xa8, 0x9e, 0x52, 0xb1, 0x90, 0x56, 0x0f, 0xcf, 0x8d, 0xae, 0x42, 0xbe, 0x66, 0xb4, 0x3b, 0xaa,
+	0xde, 0xa3, 0x59, 0x46, 0xb1, 0x3a, 0x79, 0xd2, 0x2f, 0xe7, 0xeb, 0x0e, 0x48, 0x71, 0x71, 0x68,
+	0x89, 0x65, 0x63, 0x34, 0x77, 0xa8, 0x16, 0x4e, 0xfa, 0xe5, 0xac, 0xae, 0xb6, 0x31, 0xcb, 0xcb,
+	0x6e, 0x42, 0x91, 0xae, 0x03, 0x25, 0xa1, 0xf9, 0x43, 0x75, 0xfa, 0xa4, 0x5f, 0x2e, 0x1e, 0xbb,
+	0x40, 0xc5, 0xc7, 0xa3, 0x15, 0x28, 0xec, 0xa8, 0xce, 0x37, 0x4d, 0x23, 0x8a, 0xd5, 0xa9, 0x93,
+	0x7e, 0xb9, 0xd0, 0x62, 0x30, 0xc5, 0xc3, 0xca, 0x15, 0x28, 0xd3, 0x10, 0xb7, 0x17, 0x0d, 0xac,
+	0x47, 0xbc, 0xab, 0x64, 0x19, 0x96, 0x93, 0x45, 0x30, 0xab, 0x7d, 0x03, 0xce, 0x73, 0x34, 0x2c,
+	0xb6, 0x1e, 0x55, 0xc1, 0x12, 0x48, 0x71, 0xcc, 0x4c, 0xf4, 0x5b, 0x70, 0xd1, 0xc1, 0x86, 0x53,
+	0xbd, 0x51, 0xc5, 0x2f, 0x43, 0x29, 0x49, 0x00, 0x53, 0xf1, 0x97, 0x1c, 0x64, 0x49, 0x78, 0x9e,
+	0x74, 0xe6, 0xce, 0xb3, 0x9d, 0x1b, 0xe3, 0x11, 0xce, 0xb6, 0x5d, 0x0d, 0x28, 0x0f, 0x1c, 0x39,
+	0x0e, 0x81, 0x7e, 0x1a, 0x9b, 0xaf, 0x65, 0x9d, 0x23, 0xcc, 0xca, 0xa4, 0xc2, 0x6a, 0x6c, 0xea,
+	0xf6, 0x51, 0x52, 0xea, 0x36, 0xda, 0x41, 0x13, 0x7c, 0xa3, 0x4d, 0xc8, 0xdf, 0x1e, 0x44, 0x32,
+	0xd3, 0x89, 0xf4, 0x77, 0x42, 0x30, 0x3d, 0x6d, 0x0f, 0x49, 0x09, 0xf3, 0x69, 0x87, 0x3e, 0x38,
+	0x2f, 0x54, 0x13, 0xf3, 0xc2, 0x42, 0x5a, 0x45, 0x49, 0xc9, 0x61, 0xe0, 0x5a, 0x2a, 0xbe, 0xc4,
+	0xb5, 0xb4, 0xc5, 0xe7, 0x7d, 0x90, 0x76, 0x78, 0x3e, 0xaf, 0x9c, 0x27, 0x8e, 0xa0, 0x63, 0xf7,
+	0xe4, 0xd7, 0x21, 0xbf, 0xdf, 0x6d, 0xb7, 0x55, 0xb3, 0x87, 0x56, 0x21, 0xbf, 0xa1, 0xdb, 0xa6,
+	0x86, 0xc3, 0xce, 0x90, 0x11, 0x10, 0x64, 0x4f, 0x71, 0x69, 0xe4, 0x2f, 0x00, 0xa6, 0x78, 0x0c,
+	0x5a, 0x8d, 0x24, 0xaf, 0x81, 0xb3, 0x10, 0x42, 0xa2, 0x4b, 0x50, 0x20, 0x90, 0xe8, 0xd9, 0xf0,
+	0xc0, 0xe4, 0x16, 0xde, 0x35, 0x9b, 0xe1, 0xa3, 0xe1, 0xc0, 0xd0, 0xcd, 0x70, 0xcf, 0x2a, 0xcb,
+	0x13, 0x05, 0x71, 0xa8, 0x0c, 0xf9, 0x5d, 0xb3, 0x49, 0x75, 0xe5, 0x78, 0x32, 0x17, 0x4a, 0x02,
+	0x1f, 0xea, 0x01, 0xdd, 0x7a, 0x13, 0xfb, 0x43, 0xf7, 0x68, 0x15, 0xcd, 0xdf, 0xbd, 0x7c, 0x0a,
+	0x8f, 0x1e, 0xe0, 0x4c, 0xac, 0x2d, 0x15, 0x4e, 0xb5, 0xb6, 0x54, 0x1c, 0x5c, 0x5b, 0x82, 0x51,
+	0x6a, 0x4b, 0x93, 0x2f, 0x51, 0x5b, 0x1a, 0x56, 0xcf, 0x99, 0xfa, 0x3f, 0xd4, 0x73, 0xa6, 0x4f,
+	0xa1, 0x9e, 0xb3, 0x0a, 0x33, 0xa4, 0x27, 0x40, 0x14, 0xeb, 0xa4, 0x31, 0xd0, 0x10, 0x67, 0xf8,
+	0x48, 0x20, 0x84, 0x8c, 0x2d, 0x48, 0x9e, 0x49, 0x51, 0x90, 0x9c, 0x4d, 0x28, 0x48, 0xc6, 0x97,
+	0xea, 0xce, 0xa6, 0x2f, 0xd5, 0xa1, 0x97, 0x2f, 0xd5, 0xdd, 0xa1, 0x95, 0x70, 0x06, 0x8e, 0x94,
+	0xa8, 0x62, 0x71, 0xe8, 0x21, 0xcc, 0xf9, 0x70, 0x7f, 0x34, 0x69, 0x4a, 0x56, 0x71, 0x02, 0xd0,
+	0x5d, 0xb8, 0xe0, 0x83, 0xa3, 0xe9, 0xf4, 0x02, 0x1d, 0xd2, 0x20, 0x12, 0x54, 0x85, 0xa5, 0x78,
+	0x34, 0x4b, 0xb1, 0x17, 0xa9, 0x88, 0x81, 0x34, 0xf2, 0x7f, 0xc6, 0x21, 0xff, 0x2e, 0x3e, 0x7a,
+	0x6c, 0x18, 0x4f, 0x92, 0xbc, 0xff, 0x6a, 0xa4, 0x01, 0x1b, 0xb8, 0xeb, 0x42, 0x48, 0xf4, 0x26,
+	0x9c, 0x21, 0x3d, 0x82, 0xa6, 0x49, 0x01, 0x07, 0xbd, 0x0e, 0xab, 0xbf, 0x57, 0xe7, 0x4e, 0xfa,
+	0xe5, 0x33, 0x5a, 0x10, 0xa5, 0x84, 0x69, 0x49, 0x68, 0xb7, 0x8f, 0xeb, 0x26, 0xb6, 0xbd, 0xeb,
+	0x90, 0x86, 0x76, 0x16, 0x83, 0x29, 0x1e, 0x16, 0xdd, 0x85, 0x59, 0xe7, 0x7b, 0x5f, 0x6b, 0xea,
+	0x9a, 0xde, 0x7c, 0x1b, 0xf7, 0xc4, 0x9c, 0xdf, 0x79, 0xb4, 0x42, 0x38, 0x25, 0x42, 0x8d, 0x76,
+	0xd3, 0xc5, 0xda, 0x0b, 0xcc, 0xa9, 0x15, 0xeb, 0x2e, 0x53, 0xd8, 0xb9, 0xed, 0xf2, 0xce, 0x2d,
+	0x3f, 0x92, 0x40, 0xe2, 0xdc, 0x8a, 0x0d, 0x97, 0x29, 0x5c, 0xe1, 0x3c, 0x84, 0x49, 0xef, 0xbe,
+	0x1c, 0xe9, 0xa2, 0x3d, 0xc7, 0x44, 0x4e, 0x1e, 0xfb, 0x6c, 0x4e, 0xc4, 0xce, 0xc9, 0x91, 0x9f,
+	0x65, 0xa1, 0x50, 0xd9, 0xdb, 0x76, 0x72, 0xa2, 0x45, 0x6e, 0xdb, 0x27, 0x4e, 0xfa, 0xe5, 0x31,
+	0xad, 0xf1, 0x7d, 0xf6, 0x7d, 0x89, 0x6f, 0xb6, 0x44, 0xc2, 0xfb, 0xeb, 0x6e, 0x56, 0x96, 0xf5,
+	0x6d, 0x81, 0xb6, 0xc5, 0xb9, 0xd6, 0xb0, 0x43, 0x81, 0x64, 0x98, 0xa0, 0x75, 0x44, 0x4b, 0xcc,
+	0xd1, 0x26, 0x3d, 0x9c, 0xf4, 0xcb, 0x13, 0x16, 0x85, 0x28, 0x0c, 0x43, 0xb2, 0x05, 0xb6, 0xea,
+	0xd5, 0x9e, 0x38, 0xe1, 0x67, 0x0b, 0x75, 0x17, 0xa8, 0xf8, 0xf8, 0xe0, 0x36, 0xe7, 0x4f, 0x61,
+	0x9b, 0x7f, 0x09, 0x45, 0xdf, 0xd5, 0x0c, 0xdf, 0x93, 0x32, 0xdb, 0x93, 0x39, 0x9c, 0xf0, 0x04,
+	0xc0, 0x97, 0x86, 0x3e, 0x00, 0x20, 0xb9, 0xcb, 0xa1, 0x35, 0x62, 0xa0, 0xb5, 0xcc, 0x64, 0xcf,
+	0xb7, 0x3c, 0xae, 0x90, 0x70, 0x4e, 0x1e, 0x19, 0xb8, 0x82, 0x9f, 0x1a, 0x4f, 0x46, 0x0c, 0xbe,
+	0xbc, 0x81, 0x9b, 0x2e, 0x53, 0x78, 0xe0, 0x9e, 0x34, 0xf9, 0x6d, 0x58, 0xf5, 0x5e, 0xc5, 0x78,
+	0x66, 0xc1, 0x6e, 0x16, 0xfa, 0xaa, 0xc3, 0x3d, 0xb7, 0x6e, 0xda, 0x22, 0x71, 0x07, 0xdd, 0x49,
+	0x5a, 0xbc, 0x7f, 0xf9, 0x11, 0xac, 0x8d, 0x2a, 0x8c, 0x15, 0x43, 0x56, 0xbc, 0x6b, 0x8c, 0x55,
+	0x2c, 0x66, 0x58, 0xe4, 0xc7, 0xa0, 0x8a, 0x8b, 0x96, 0x37, 0x61, 0x25, 0x54, 0x50, 0x62, 0x18,
+	0xee, 0x80, 0x8c, 0x32, 0x46, 0x0b, 0xae, 0x8f, 0x20, 0x87, 0x0d, 0x6f, 0x33, 0x78, 0x8e, 0x85,
+	0x14, 0xae, 0x2c, 0x70, 0x70, 0x77, 0xe0, 0xc6, 0xb6, 0x4e, 0xa2, 0xa2, 0x40, 0x75, 0xc7, 0x71,
+	0xe4, 0x35, 0x43, 0x3f, 0xd6, 0x9a, 0xdd, 0x14, 0x89, 0xa7, 0x02, 0xb0, 0x87, 0xcd, 0xb6, 0x66,
+	0x59, 0x71, 0xdd, 0x55, 0xc4, 0x67, 0x7d, 0xec, 0x18, 0x2f, 0xc3, 0xe4, 0x3a, 0xf6, 0x7c, 0x08,
+	0x6b, 0xac, 0xf2, 0x20, 0xb9, 0x01, 0x59, 0xc5, 0x68, 0xe1, 0xd3, 0x91, 0xc6, 0x75, 0x99, 0xb2,
+	0x7c, 0x97, 0x49, 0xfe, 0x9b, 0x00, 0x57, 0xc8, 0x2c, 0x35, 0x13, 0x13, 0xc8, 0x3b, 0x98, 0x84,
+	0x0e, 0xfe, 0x5c, 0x0e, 0x8c, 0x21, 0xd5, 0x40, 0xb4, 0x12, 0x89, 0xf4, 0xdd, 0x6e, 0x78, 0x08,
+	0xee, 0x52, 0x72, 0xbd, 0xf0, 0x2c, 0x4f, 0xe9, 0xc3, 0x49, 0xdb, 0xd7, 0x1f, 0x82, 0x1b, 0xf2,
+	0x2b, 0x01, 0x58, 0xb5, 0xe0, 0x4e, 0x48, 0xfe, 0xab, 0x00, 0x97, 0xd9, 0x14, 0x76, 0xcd, 0x66,
+	0xfa, 0x19, 0x5c, 0x8b, 0x7d, 0xf0, 0x76, 0x2f, 0x13, 0xce, 0x1b, 0x1c, 0xba, 0x98, 0xe1, 0x07,
+	0xc1, 0x23, 0x8d, 0x3e, 0xcf, 0xb2, 0x99, 0x1b, 0x0f, 0x60, 0x36, 0xfc, 0xda, 0x0b, 0x15, 0x21,
+	0xb7, 0x7b, 0x70, 0x6f, 0x43, 0x99, 0xcd, 0x20, 0x11, 0xe6, 0xb7, 0xef, 0xef, 0x1f, 0x54, 0xee,
+	0xd7, 0x36, 0x3e, 0x5a, 0xaf, 0x1c, 0x54, 0x3e, 0xaa, 0xd4, 0x6a, 0x1b, 0xfb, 0xfb, 0xb3, 0x42,
+	0x14, 0x73, 0xb8, 0xb7, 0xb3, 0x5b, 0x59, 0x9f, 0x1d, 0xbb, 0xf3, 0x4f, 0x04, 0x39, 0x32, 0x35,
+	0x0b, 0xdd, 0x85, 0xa2, 0x77, 0xfa, 0x91, 0xfb, 0xb8, 0x2c, 0xfc, 0x10, 0x50, 0x12, 0xa3, 0x08,
+	0x56, 0xce, 0xc8, 0xa0, 0x43, 0xf7, 0xe1, 0xa0, 0xff, 0x9e, 0x0c, 0x95, 0x02, 0xf4, 0x91, 0x17,
+	0x6f, 0x52, 0x39, 0x11, 0xef, 0x89, 0xdd, 0x84, 0x49, 0xee, 0xb1, 0x15, 0x3a, 0x1f, 0xe0, 0xe0,
+	0x9f, 0x6b, 0x49, 0x52, 0x1c, 0xca, 0x93, 0x53, 0x03, 0xf0, 0xdf, 0x4f, 0x21, 0x31, 0xa4, 0xd8,
+	0x2b, 0x65, 0x4b, 0xe7, 0x63, 0x30, 0xfc, 0x1c, 0xc3, 0xe5, 0x62, 0x6f, 0x8e, 0x09, 0x2f, 0xab,
+	0xa4, 0x72, 0x22, 0xde, 0x13, 0xdb, 0xa6, 0xcf, 0xa3, 0x62, 0x1f, 0x13, 0xa1, 0x6b, 0x3e, 0xfb,
+	0xa0, 0x27, 0x4e, 0xd2, 0x2b, 0x43, 0xe9, 0x3c, 0x75, 0x0d, 0x58, 0x88, 0x7d, 0xfe, 0x83, 0x2e,
+	0xfb, 0x32, 0x12, 0x1f, 0x1d, 0x49, 0x57, 0x06, 0x13, 0x79, 0x5a, 0x2c, 0x90, 0x92, 0x5f, 0xe9,
+	0xa0, 0x15, 0x5f, 0xca, 0xe0, 0x57, 0x42, 0xd2, 0xf5, 0x11, 0x28, 0x3d, 0xa5, 0x0a, 0x6d, 0x38,
+	0xf0, 0x58, 0x74, 0x31, 0x7e, 0xfd, 0x5d, 0xf1, 0xa5, 0x24, 0xb4, 0x27, 0xf3, 0x43, 0x98, 0x8b,
+	0xe9, 0x86, 0xa0, 0x4b, 0x8c, 0x31, 0xb9, 0x23, 0x23, 0xc9, 0x83, 0x48, 0x3c, 0xf9, 0x4f, 0x23,
+	0xdd, 0x16, 0x3e, 0x91, 0x42, 0xd7, 0xe3, 0x85, 0xc4, 0xb4, 0xb0, 0xa4, 0x1b, 0xa3, 0x90, 0x7a,
+	0x7a, 0x7f, 0x02, 0x13, 0x4e, 0xd3, 0x0e, 0xcd, 0xfb, 0x6b, 0xe0, 0xb7, 0xde, 0xa4, 0x85, 0x10,
+	0xd4, 0x63, 0xbc, 0x0b, 0x45, 0xaf, 0xcb, 0xe6, 0xdd, 0x15, 0xe1, 0xce, 0x9d, 0x24, 0x46, 0x11,
+	0x9e, 0x84, 0x9f, 0x41, 0x9e, 0xf5, 0x85, 0x10, 0xa7, 0x85, 0x3f, 0x86, 0x8b, 0x61, 0xb0, 0xc7,
+	0xfb, 0x5b, 0x01, 0xae, 0x8c, 0xd2, 0xb3, 0x41, 0x77, 0x12, 0x0f, 0x5e, 0x62, 0x1f, 0x49, 0x7a,
+	0x35, 0x15, 0x4f, 0xd0, 0x44, 0x22, 0xfd, 0x15, 0xce, 0x44, 0x92, 0x3a, 0x3e, 0x92, 0x3c, 0x88,
+	0x84, 0xbf, 0x20, 0x92, 0xca, 0xe1, 0xde, 0x05, 0x31, 0xa4, 0xe4, 0x2e, 0xbd, 0x32, 0x94, 0xce,
+	0x53, 0xf7, 0x3e, 0xa0, 0x68, 0x71, 0x1c, 0x2d, 0x47, 0x05, 0x04, 0x8b, 0xee, 0xd2, 0xa5, 0x01,
+	0x14, 0x9e, 0xf0, 0x26, 0x2c, 0xc6, 0x97, 0xc6, 0xd1, 0x95, 0x00, 0x7b, 0x42, 0xe9, 0x5d, 0xba,
+	0x3a, 0x84, 0xca, 0x53, 0x74, 0x0b, 0x60, 0x0b, 0xdb, 0x6e, 0x99, 0x72, 0x8a, 0xb1, 0xd1, 0xfa,
+	0xa5, 0x34, 0x13, 0xac, 0x51, 0xca, 0x19, 0xf4, 0x07, 0x01, 0xae, 0x8d, 0x16, 0xff, 0xa2, 0xd7,
+	0xc2, 0x5e, 0x70, 0x94, 0xd8, 0x5b, 0xfa, 0x51, 0x4a, 0x2e, 0x6f, 0x1e, 0xbf, 0x16, 0xe0, 0xd2,
+	0xd0, 0xa8, 0x17, 0xdd, 0x8e, 0x3f, 0xfb, 0x89, 0x71, 0xb6, 0xf4, 0x83, 0xd1, 0x19, 0xbc, 0xa1,
+	0x3c, 0x86, 0xcb, 0x23, 0x84, 0xc2, 0xe8, 0x87, 0x4c, 0xf4, 0xe8, 0x61, 0xb3, 0x14, 0xd8, 0x1e,
+	0x39, 0x83, 0x3e, 0x80, 0x8b, 0x03, 0x63, 0x4d, 0x74, 0x93, 0x31, 0x8c, 0x12, 0x91, 0x46, 0xa4,
+	0x3f, 0x82, 0xa5, 0x41, 0x61, 0x20, 0xba, 0x11, 0x14, 0x3e, 0x28, 0x56, 0x0c, 0xcb, 0xae, 0xbe,
+	0xfe, 0xec, 0x79, 0x29, 0xf3, 0xf5, 0xf3, 0x52, 0xe6, 0xbb, 0xe7, 0x25, 0xe1, 0xbf, 0xcf, 0x4b,
+	0xc2, 0x17, 0x2f, 0x4a, 0xc2, 0x9f, 0x5f, 0x94, 0x84, 0xaf, 0x5e, 0x94, 0x84, 0x67, 0x2f, 0x4a,
+	0xc2, 0xbf, 0x5e, 0x94, 0x84, 0x7f, 0xbf, 0x28, 0x65, 0xbe, 0x7b, 0x51, 0x12, 0xbe, 0xfc, 0xb6,
+	0x94, 0x79, 0xf6, 0x6d, 0x29, 0xf3, 0xf5, 0xb7, 0xa5, 0xcc, 0xd1, 0x04, 0xcd, 0x49, 0x5e, 0xfd,
+	0xdf, 0x00, 0x6e, 0xd8, 0x58, 0xdb, 0xe8, 0x31, 0x00, 0x00,
 }
 
 func (x AuthorizedAction) String() string {
@@ -3731,6 +3899,9 @@ func (this *LookupUsingTokenRequest) Equal(that interface{}) bool {
 	if this.AuthorizeFor != that1.AuthorizeFor {
 		return false
 	}
+	if this.Service != that1.Service {
+		return false
+	}
 	return true
 }
 func (this *LookupUsingTokenResponse) Equal(that interface{}) bool {
@@ -3763,6 +3934,13 @@ func (this *LookupUsingTokenResponse) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if that1.TokenExpiresAt == nil {
+		if this.TokenExpiresAt != nil {
+			return false
+		}
+	} else if !this.TokenExpiresAt.Equal(*that1.TokenExpiresAt) {
+		return false
+	}
 	return true
 }
 func (this *LookupAdminRequest) Equal(that interface{}) bool {
@@ -3942,6 +4120,14 @@ func (this *GetBillableOrganizationsRequest) Equal(that interface{}) bool {
 	if !this.Now.Equal(that1.Now) {
 		return false
 	}
+	if len(this.TeamIDs) != len(that1.TeamIDs) {
+		return false
+	}
+	for i := range this.TeamIDs {
+		if this.TeamIDs[i] != that1.TeamIDs[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *GetBillableOrganizationsResponse) Equal(that interface{}) bool {
@@ -5217,6 +5403,74 @@ func (this *Webhook) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *APIToken) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*APIToken)
+	if !ok {
+		that2, ok := that.(APIToken)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.ID != that1.ID {
+		return false
+	}
+	if this.OrganizationID != that1.OrganizationID {
+		return false
+	}
+	if this.Name != that1.Name {
+		return false
+	}
+	if this.Token != that1.Token {
+		return false
+	}
+	if len(this.Scopes) != len(that1.Scopes) {
+		return false
+	}
+	for i := range this.Scopes {
+		if this.Scopes[i] != that1.Scopes[i] {
+			return false
+		}
+	}
+	if this.CreatedBy != that1.CreatedBy {
+		return false
+	}
+	if !this.CreatedAt.Equal(that1.CreatedAt) {
+		return false
+	}
+	if that1.ExpiresAt == nil {
+		if this.ExpiresAt != nil {
+			return false
+		}
+	} else if !this.ExpiresAt.Equal(*that1.ExpiresAt) {
+		return false
+	}
+	if that1.LastUsedAt == nil {
+		if this.LastUsedAt != nil {
+			return false
+		}
+	} else if !this.LastUsedAt.Equal(*that1.LastUsedAt) {
+		return false
+	}
+	if that1.RevokedAt == nil {
+		if this.RevokedAt != nil {
+			return false
+		}
+	} else if !this.RevokedAt.Equal(*that1.RevokedAt) {
+		return false
+	}
+	return true
+}
 func (this *LookupOrganizationWebhookUsingSecretIDRequest) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -5399,6 +5653,9 @@ func (this *Role) Equal(that interface{}) bool {
 	if this.Description != that1.Description {
 		return false
 	}
+	if this.TeamID != that1.TeamID {
+		return false
+	}
 	return true
 }
 func (this *RequireTeamMemberPermissionToRequest) Equal(that interface{}) bool {
@@ -5597,10 +5854,11 @@ func (this *LookupUsingTokenRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 6)
+	s := make([]string, 0, 7)
 	s = append(s, "&users.LookupUsingTokenRequest{")
 	s = append(s, "Token: "+fmt.Sprintf("%#v", this.Token)+",\n")
 	s = append(s, "AuthorizeFor: "+fmt.Sprintf("%#v", this.AuthorizeFor)+",\n")
+	s = append(s, "Service: "+fmt.Sprintf("%#v", this.Service)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -5608,10 +5866,11 @@ func (this *LookupUsingTokenResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 6)
+	s := make([]string, 0, 7)
 	s = append(s, "&users.LookupUsingTokenResponse{")
 	s = append(s, "OrganizationID: "+fmt.Sprintf("%#v", this.OrganizationID)+",\n")
 	s = append(s, "FeatureFlags: "+fmt.Sprintf("%#v", this.FeatureFlags)+",\n")
+	s = append(s, "TokenExpiresAt: "+fmt.Sprintf("%#v", this.TokenExpiresAt)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -5687,9 +5946,10 @@ func (this *GetBillableOrganizationsRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 5)
+	s := make([]string, 0, 6)
 	s = append(s, "&users.GetBillableOrganizationsRequest{")
 	s = append(s, "Now: "+fmt.Sprintf("%#v", this.Now)+",\n")
+	s = append(s, "TeamIDs: "+fmt.Sprintf("%#v", this.TeamIDs)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -6179,6 +6439,25 @@ func (this *Webhook) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *APIToken) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 14)
+	s = append(s, "&users.APIToken{")
+	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
+	s = append(s, "OrganizationID: "+fmt.Sprintf("%#v", this.OrganizationID)+",\n")
+	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
+	s = append(s, "Token: "+fmt.Sprintf("%#v", this.Token)+",\n")
+	s = append(s, "Scopes: "+fmt.Sprintf("%#v", this.Scopes)+",\n")
+	s = append(s, "CreatedBy: "+fmt.Sprintf("%#v", this.CreatedBy)+",\n")
+	s = append(s, "CreatedAt: "+fmt.Sprintf("%#v", this.CreatedAt)+",\n")
+	s = append(s, "ExpiresAt: "+fmt.Sprintf("%#v", this.ExpiresAt)+",\n")
+	s = append(s, "LastUsedAt: "+fmt.Sprintf("%#v", this.LastUsedAt)+",\n")
+	s = append(s, "RevokedAt: "+fmt.Sprintf("%#v", this.RevokedAt)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func (this *LookupOrganizationWebhookUsingSecretIDRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -6247,11 +6526,12 @@ func (this *Role) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 8)
 	s = append(s, "&users.Role{")
 	s = append(s, "ID: "+fmt.Sprintf("%#v", this.ID)+",\n")
 	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
 	s = append(s, "Description: "+fmt.Sprintf("%#v", this.Description)+",\n")
+	s = append(s, "TeamID: "+fmt.Sprintf("%#v", this.TeamID)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -7448,6 +7728,13 @@ func (m *LookupUsingTokenRequest) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	_ = i
 	var l int
 	_ = l
+	if len(m.Service) > 0 {
+		i -= len(m.Service)
+		copy(dAtA[i:], m.Service)
+		i = encodeVarintUsers(dAtA, i, uint64(len(m.Service)))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if m.AuthorizeFor != 0 {
 		i = encodeVarintUsers(dAtA, i, uint64(m.AuthorizeFor))
 		i--
@@ -7483,6 +7770,16 @@ func (m *LookupUsingTokenResponse) MarshalToSizedBuffer(dAtA []byte) (int, error
 	_ = i
 	var l int
 	_ = l
+	if m.TokenExpiresAt != nil {
+		n1, err1 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TokenExpiresAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TokenExpiresAt):])
+		if err1 != nil {
+			return 0, err1
+		}
+		i -= n1
+		i = encodeVarintUsers(dAtA, i, uint64(n1))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if len(m.FeatureFlags) > 0 {
 		for iNdEx := len(m.FeatureFlags) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.FeatureFlags[iNdEx])
@@ -7724,12 +8021,21 @@ func (m *GetBillableOrganizationsRequest) MarshalToSizedBuffer(dAtA []byte) (int
 	_ = i
 	var l int
 	_ = l
-	n1, err1 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Now, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Now):])
-	if err1 != nil {
-		return 0, err1
+	if len(m.TeamIDs) > 0 {
+		for iNdEx := len(m.TeamIDs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TeamIDs[iNdEx])
+			copy(dAtA[i:], m.TeamIDs[iNdEx])
+			i = encodeVarintUsers(dAtA, i, uint64(len(m.TeamIDs[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	n2, err2 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Now, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Now):])
+	if err2 != nil {
+		return 0, err2
 	}
-	i -= n1
-	i = encodeVarintUsers(dAtA, i, uint64(n1))
+	i -= n2
+	i = encodeVarintUsers(dAtA, i, uint64(n2))
 	i--
 	dAtA[i] = 0xa
 	return len(dAtA) - i, nil
@@ -7792,12 +8098,12 @@ func (m *GetTrialOrganizationsRequest) MarshalToSizedBuffer(dAtA []byte) (int, e
 	_ = i
 	var l int
 	_ = l
-	n2, err2 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Now, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Now):])
-	if err2 != nil {
-		return 0, err2
+	n3, err3 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Now, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Now):])
+	if err3 != nil {
+		return 0, err3
 	}
-	i -= n2
-	i = encodeVarintUsers(dAtA, i, uint64(n2))
+	i -= n3
+	i = encodeVarintUsers(dAtA, i, uint64(n3))
 	i--
 	dAtA[i] = 0xa
 	return len(dAtA) - i, nil
@@ -7860,12 +8166,12 @@ func (m *GetDelinquentOrganizationsRequest) MarshalToSizedBuffer(dAtA []byte) (i
 	_ = i
 	var l int
 	_ = l
-	n3, err3 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Now, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Now):])
-	if err3 != nil {
-		return 0, err3
+	n4, err4 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Now, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Now):])
+	if err4 != nil {
+		return 0, err4
 	}
-	i -= n3
-	i = encodeVarintUsers(dAtA, i, uint64(n3))
+	i -= n4
+	i = encodeVarintUsers(dAtA, i, uint64(n4))
 	i--
 	dAtA[i] = 0xa
 	return len(dAtA) - i, nil
@@ -8054,12 +8360,12 @@ func (m *Organization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		dAtA[i] = 0xe2
 	}
 	if m.LastSentWeeklyReportAt != nil {
-		n5, err5 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastSentWeeklyReportAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastSentWeeklyReportAt):])
-		if err5 != nil {
-			return 0, err5
+		n6, err6 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastSentWeeklyReportAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastSentWeeklyReportAt):])
+		if err6 != nil {
+			return 0, err6
 		}
-		i -= n5
-		i = encodeVarintUsers(dAtA, i, uint64(n5))
+		i -= n6
+		i = encodeVarintUsers(dAtA, i, uint64(n6))
 		i--
 		dAtA[i] = 0x1
 		i--
@@ -8075,19 +8381,7 @@ func (m *Organization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		dAtA[i] = 0xd2
 	}
 	if m.FirstSeenScopeConnectedAt != nil {
-		n6, err6 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenScopeConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenScopeConnectedAt):])
-		if err6 != nil {
-			return 0, err6
-		}
-		i -= n6
-		i = encodeVarintUsers(dAtA, i, uint64(n6))
-		i--
-		dAtA[i] = 0x1
-		i--
-		dAtA[i] = 0xca
-	}
-	if m.FirstSeenPromConnectedAt != nil {
-		n7, err7 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenPromConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenPromConnectedAt):])
+		n7, err7 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenScopeConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenScopeConnectedAt):])
 		if err7 != nil {
 			return 0, err7
 		}
@@ -8096,10 +8390,10 @@ func (m *Organization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x1
 		i--
-		dAtA[i] = 0xc2
+		dAtA[i] = 0xca
 	}
-	if m.FirstSeenNetConnectedAt != nil {
-		n8, err8 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenNetConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenNetConnectedAt):])
+	if m.FirstSeenPromConnectedAt != nil {
+		n8, err8 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenPromConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenPromConnectedAt):])
 		if err8 != nil {
 			return 0, err8
 		}
@@ -8108,10 +8402,10 @@ func (m *Organization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x1
 		i--
-		dAtA[i] = 0xba
+		dAtA[i] = 0xc2
 	}
-	if m.FirstSeenFluxConnectedAt != nil {
-		n9, err9 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenFluxConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenFluxConnectedAt):])
+	if m.FirstSeenNetConnectedAt != nil {
+		n9, err9 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenNetConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenNetConnectedAt):])
 		if err9 != nil {
 			return 0, err9
 		}
@@ -8120,7 +8414,19 @@ func (m *Organization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x1
 		i--
-		dAtA[i] = 0xb2
+		dAtA[i] = 0xba
+	}
+	if m.FirstSeenFluxConnectedAt != nil {
+		n10, err10 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenFluxConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenFluxConnectedAt):])
+		if err10 != nil {
+			return 0, err10
+		}
+		i -= n10
+		i = encodeVarintUsers(dAtA, i, uint64(n10))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0xb2
 	}
 	if m.Cleanup {
 		i--
@@ -8134,12 +8440,12 @@ func (m *Organization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0xa8
 	}
-	n10, err10 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.DeletedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.DeletedAt):])
-	if err10 != nil {
-		return 0, err10
+	n11, err11 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.DeletedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.DeletedAt):])
+	if err11 != nil {
+		return 0, err11
 	}
-	i -= n10
-	i = encodeVarintUsers(dAtA, i, uint64(n10))
+	i -= n11
+	i = encodeVarintUsers(dAtA, i, uint64(n11))
 	i--
 	dAtA[i] = 0x1
 	i--
@@ -8177,34 +8483,34 @@ func (m *Organization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		dAtA[i] = 0x8a
 	}
 	if m.TrialExpiredNotifiedAt != nil {
-		n12, err12 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialExpiredNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialExpiredNotifiedAt):])
-		if err12 != nil {
-			return 0, err12
+		n13, err13 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialExpiredNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialExpiredNotifiedAt):])
+		if err13 != nil {
+			return 0, err13
 		}
-		i -= n12
-		i = encodeVarintUsers(dAtA, i, uint64(n12))
+		i -= n13
+		i = encodeVarintUsers(dAtA, i, uint64(n13))
 		i--
 		dAtA[i] = 0x1
 		i--
 		dAtA[i] = 0x82
 	}
 	if m.TrialPendingExpiryNotifiedAt != nil {
-		n13, err13 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialPendingExpiryNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialPendingExpiryNotifiedAt):])
-		if err13 != nil {
-			return 0, err13
+		n14, err14 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialPendingExpiryNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialPendingExpiryNotifiedAt):])
+		if err14 != nil {
+			return 0, err14
 		}
-		i -= n13
-		i = encodeVarintUsers(dAtA, i, uint64(n13))
+		i -= n14
+		i = encodeVarintUsers(dAtA, i, uint64(n14))
 		i--
 		dAtA[i] = 0x7a
 	}
 	if m.ZuoraAccountCreatedAt != nil {
-		n14, err14 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.ZuoraAccountCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.ZuoraAccountCreatedAt):])
-		if err14 != nil {
-			return 0, err14
+		n15, err15 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.ZuoraAccountCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.ZuoraAccountCreatedAt):])
+		if err15 != nil {
+			return 0, err15
 		}
-		i -= n14
-		i = encodeVarintUsers(dAtA, i, uint64(n14))
+		i -= n15
+		i = encodeVarintUsers(dAtA, i, uint64(n15))
 		i--
 		dAtA[i] = 0x72
 	}
@@ -8215,12 +8521,12 @@ func (m *Organization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x6a
 	}
-	n15, err15 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.TrialExpiresAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.TrialExpiresAt):])
-	if err15 != nil {
-		return 0, err15
+	n16, err16 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.TrialExpiresAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.TrialExpiresAt):])
+	if err16 != nil {
+		return 0, err16
 	}
-	i -= n15
-	i = encodeVarintUsers(dAtA, i, uint64(n15))
+	i -= n16
+	i = encodeVarintUsers(dAtA, i, uint64(n16))
 	i--
 	dAtA[i] = 0x62
 	if len(m.Environment) > 0 {
@@ -8238,12 +8544,12 @@ func (m *Organization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		dAtA[i] = 0x52
 	}
 	if m.FirstSeenConnectedAt != nil {
-		n16, err16 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenConnectedAt):])
-		if err16 != nil {
-			return 0, err16
+		n17, err17 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenConnectedAt):])
+		if err17 != nil {
+			return 0, err17
 		}
-		i -= n16
-		i = encodeVarintUsers(dAtA, i, uint64(n16))
+		i -= n17
+		i = encodeVarintUsers(dAtA, i, uint64(n17))
 		i--
 		dAtA[i] = 0x4a
 	}
@@ -8276,12 +8582,12 @@ func (m *Organization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 			dAtA[i] = 0x32
 		}
 	}
-	n17, err17 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
-	if err17 != nil {
-		return 0, err17
+	n18, err18 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
+	if err18 != nil {
+		return 0, err18
 	}
-	i -= n17
-	i = encodeVarintUsers(dAtA, i, uint64(n17))
+	i -= n18
+	i = encodeVarintUsers(dAtA, i, uint64(n18))
 	i--
 	dAtA[i] = 0x2a
 	if len(m.ProbeToken) > 0 {
@@ -8363,12 +8669,12 @@ func (m *GoogleCloudPlatform) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x2a
 	}
-	n18, err18 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
-	if err18 != nil {
-		return 0, err18
+	n19, err19 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
+	if err19 != nil {
+		return 0, err19
 	}
-	i -= n18
-	i = encodeVarintUsers(dAtA, i, uint64(n18))
+	i -= n19
+	i = encodeVarintUsers(dAtA, i, uint64(n19))
 	i--
 	dAtA[i] = 0x22
 	if m.Activated {
@@ -8540,12 +8846,12 @@ func (m *SetOrganizationZuoraAccountRequest) MarshalToSizedBuffer(dAtA []byte) (
 	var l int
 	_ = l
 	if m.CreatedAt != nil {
-		n21, err21 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.CreatedAt):])
-		if err21 != nil {
-			return 0, err21
+		n22, err22 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.CreatedAt):])
+		if err22 != nil {
+			return 0, err22
 		}
-		i -= n21
-		i = encodeVarintUsers(dAtA, i, uint64(n21))
+		i -= n22
+		i = encodeVarintUsers(dAtA, i, uint64(n22))
 		i--
 		dAtA[i] = 0x1a
 	}
@@ -8742,12 +9048,12 @@ func (m *GetOrganizationsReadyForWeeklyReportRequest) MarshalToSizedBuffer(dAtA
 	_ = i
 	var l int
 	_ = l
-	n23, err23 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Now, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Now):])
-	if err23 != nil {
-		return 0, err23
+	n24, err24 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Now, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Now):])
+	if err24 != nil {
+		return 0, err24
 	}
-	i -= n23
-	i = encodeVarintUsers(dAtA, i, uint64(n23))
+	i -= n24
+	i = encodeVarintUsers(dAtA, i, uint64(n24))
 	i--
 	dAtA[i] = 0xa
 	return len(dAtA) - i, nil
@@ -8817,12 +9123,12 @@ func (m *SendOutWeeklyReportRequest) MarshalToSizedBuffer(dAtA []byte) (int, err
 		i--
 		dAtA[i] = 0x12
 	}
-	n24, err24 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Now, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Now):])
-	if err24 != nil {
-		return 0, err24
+	n25, err25 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Now, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Now):])
+	if err25 != nil {
+		return 0, err25
 	}
-	i -= n24
-	i = encodeVarintUsers(dAtA, i, uint64(n24))
+	i -= n25
+	i = encodeVarintUsers(dAtA, i, uint64(n25))
 	i--
 	dAtA[i] = 0xa
 	return len(dAtA) - i, nil
@@ -8899,12 +9205,12 @@ func (m *User) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x4a
 	}
-	n25, err25 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.LastLoginAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.LastLoginAt):])
-	if err25 != nil {
-		return 0, err25
+	n26, err26 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.LastLoginAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.LastLoginAt):])
+	if err26 != nil {
+		return 0, err26
 	}
-	i -= n25
-	i = encodeVarintUsers(dAtA, i, uint64(n25))
+	i -= n26
+	i = encodeVarintUsers(dAtA, i, uint64(n26))
 	i--
 	dAtA[i] = 0x42
 	if m.Admin {
@@ -8917,29 +9223,29 @@ func (m *User) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x38
 	}
-	n26, err26 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
-	if err26 != nil {
-		return 0, err26
-	}
-	i -= n26
-	i = encodeVarintUsers(dAtA, i, uint64(n26))
-	i--
-	dAtA[i] = 0x32
-	n27, err27 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.FirstLoginAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.FirstLoginAt):])
+	n27, err27 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
 	if err27 != nil {
 		return 0, err27
 	}
 	i -= n27
 	i = encodeVarintUsers(dAtA, i, uint64(n27))
 	i--
-	dAtA[i] = 0x2a
-	n28, err28 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.TokenCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.TokenCreatedAt):])
+	dAtA[i] = 0x32
+	n28, err28 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.FirstLoginAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.FirstLoginAt):])
 	if err28 != nil {
 		return 0, err28
 	}
 	i -= n28
 	i = encodeVarintUsers(dAtA, i, uint64(n28))
 	i--
+	dAtA[i] = 0x2a
+	n29, err29 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.TokenCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.TokenCreatedAt):])
+	if err29 != nil {
+		return 0, err29
+	}
+	i -= n29
+	i = encodeVarintUsers(dAtA, i, uint64(n29))
+	i--
 	dAtA[i] = 0x22
 	if len(m.Token) > 0 {
 		i -= len(m.Token)
@@ -9145,58 +9451,58 @@ func (m *Team) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	var l int
 	_ = l
 	if m.DeletedAt != nil {
-		n29, err29 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.DeletedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.DeletedAt):])
-		if err29 != nil {
-			return 0, err29
+		n30, err30 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.DeletedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.DeletedAt):])
+		if err30 != nil {
+			return 0, err30
 		}
-		i -= n29
-		i = encodeVarintUsers(dAtA, i, uint64(n29))
+		i -= n30
+		i = encodeVarintUsers(dAtA, i, uint64(n30))
 		i--
 		dAtA[i] = 0x52
 	}
-	n30, err30 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
-	if err30 != nil {
-		return 0, err30
+	n31, err31 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
+	if err31 != nil {
+		return 0, err31
 	}
-	i -= n30
-	i = encodeVarintUsers(dAtA, i, uint64(n30))
+	i -= n31
+	i = encodeVarintUsers(dAtA, i, uint64(n31))
 	i--
 	dAtA[i] = 0x4a
 	if m.TrialExpiredNotifiedAt != nil {
-		n31, err31 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialExpiredNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialExpiredNotifiedAt):])
-		if err31 != nil {
-			return 0, err31
+		n32, err32 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialExpiredNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialExpiredNotifiedAt):])
+		if err32 != nil {
+			return 0, err32
 		}
-		i -= n31
-		i = encodeVarintUsers(dAtA, i, uint64(n31))
+		i -= n32
+		i = encodeVarintUsers(dAtA, i, uint64(n32))
 		i--
 		dAtA[i] = 0x42
 	}
 	if m.TrialPendingExpiryNotifiedAt != nil {
-		n32, err32 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialPendingExpiryNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialPendingExpiryNotifiedAt):])
-		if err32 != nil {
-			return 0, err32
+		n33, err33 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialPendingExpiryNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialPendingExpiryNotifiedAt):])
+		if err33 != nil {
+			return 0, err33
 		}
-		i -= n32
-		i = encodeVarintUsers(dAtA, i, uint64(n32))
+		i -= n33
+		i = encodeVarintUsers(dAtA, i, uint64(n33))
 		i--
 		dAtA[i] = 0x3a
 	}
-	n33, err33 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.TrialExpiresAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.TrialExpiresAt):])
-	if err33 != nil {
-		return 0, err33
+	n34, err34 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.TrialExpiresAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.TrialExpiresAt):])
+	if err34 != nil {
+		return 0, err34
 	}
-	i -= n33
-	i = encodeVarintUsers(dAtA, i, uint64(n33))
+	i -= n34
+	i = encodeVarintUsers(dAtA, i, uint64(n34))
 	i--
 	dAtA[i] = 0x32
 	if m.ZuoraAccountCreatedAt != nil {
-		n34, err34 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.ZuoraAccountCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.ZuoraAccountCreatedAt):])
-		if err34 != nil {
-			return 0, err34
+		n35, err35 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.ZuoraAccountCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.ZuoraAccountCreatedAt):])
+		if err35 != nil {
+			return 0, err35
 		}
-		i -= n34
-		i = encodeVarintUsers(dAtA, i, uint64(n34))
+		i -= n35
+		i = encodeVarintUsers(dAtA, i, uint64(n35))
 		i--
 		dAtA[i] = 0x2a
 	}
@@ -9329,12 +9635,12 @@ func (m *SummaryEntry) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0xaa
 	}
-	n35, err35 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.GCPAccountCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.GCPAccountCreatedAt):])
-	if err35 != nil {
-		return 0, err35
+	n36, err36 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.GCPAccountCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.GCPAccountCreatedAt):])
+	if err36 != nil {
+		return 0, err36
 	}
-	i -= n35
-	i = encodeVarintUsers(dAtA, i, uint64(n35))
+	i -= n36
+	i = encodeVarintUsers(dAtA, i, uint64(n36))
 	i--
 	dAtA[i] = 0x1
 	i--
@@ -9349,12 +9655,12 @@ func (m *SummaryEntry) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		dAtA[i] = 0x9a
 	}
 	if m.ZuoraAccountCreatedAt != nil {
-		n36, err36 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.ZuoraAccountCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.ZuoraAccountCreatedAt):])
-		if err36 != nil {
-			return 0, err36
+		n37, err37 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.ZuoraAccountCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.ZuoraAccountCreatedAt):])
+		if err37 != nil {
+			return 0, err37
 		}
-		i -= n36
-		i = encodeVarintUsers(dAtA, i, uint64(n36))
+		i -= n37
+		i = encodeVarintUsers(dAtA, i, uint64(n37))
 		i--
 		dAtA[i] = 0x1
 		i--
@@ -9402,31 +9708,31 @@ func (m *SummaryEntry) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		dAtA[i] = 0x70
 	}
 	if m.TrialExpiredNotifiedAt != nil {
-		n37, err37 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialExpiredNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialExpiredNotifiedAt):])
-		if err37 != nil {
-			return 0, err37
+		n38, err38 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialExpiredNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialExpiredNotifiedAt):])
+		if err38 != nil {
+			return 0, err38
 		}
-		i -= n37
-		i = encodeVarintUsers(dAtA, i, uint64(n37))
+		i -= n38
+		i = encodeVarintUsers(dAtA, i, uint64(n38))
 		i--
 		dAtA[i] = 0x6a
 	}
 	if m.TrialPendingExpiryNotifiedAt != nil {
-		n38, err38 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialPendingExpiryNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialPendingExpiryNotifiedAt):])
-		if err38 != nil {
-			return 0, err38
+		n39, err39 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.TrialPendingExpiryNotifiedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.TrialPendingExpiryNotifiedAt):])
+		if err39 != nil {
+			return 0, err39
 		}
-		i -= n38
-		i = encodeVarintUsers(dAtA, i, uint64(n38))
+		i -= n39
+		i = encodeVarintUsers(dAtA, i, uint64(n39))
 		i--
 		dAtA[i] = 0x62
 	}
-	n39, err39 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.TrialExpiresAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.TrialExpiresAt):])
-	if err39 != nil {
-		return 0, err39
+	n40, err40 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.TrialExpiresAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.TrialExpiresAt):])
+	if err40 != nil {
+		return 0, err40
 	}
-	i -= n39
-	i = encodeVarintUsers(dAtA, i, uint64(n39))
+	i -= n40
+	i = encodeVarintUsers(dAtA, i, uint64(n40))
 	i--
 	dAtA[i] = 0x5a
 	if len(m.Environment) > 0 {
@@ -9444,21 +9750,21 @@ func (m *SummaryEntry) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		dAtA[i] = 0x4a
 	}
 	if m.FirstSeenConnectedAt != nil {
-		n40, err40 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenConnectedAt):])
-		if err40 != nil {
-			return 0, err40
+		n41, err41 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenConnectedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenConnectedAt):])
+		if err41 != nil {
+			return 0, err41
 		}
-		i -= n40
-		i = encodeVarintUsers(dAtA, i, uint64(n40))
+		i -= n41
+		i = encodeVarintUsers(dAtA, i, uint64(n41))
 		i--
 		dAtA[i] = 0x42
 	}
-	n41, err41 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.OrgCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.OrgCreatedAt):])
-	if err41 != nil {
-		return 0, err41
+	n42, err42 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.OrgCreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.OrgCreatedAt):])
+	if err42 != nil {
+		return 0, err42
 	}
-	i -= n41
-	i = encodeVarintUsers(dAtA, i, uint64(n41))
+	i -= n42
+	i = encodeVarintUsers(dAtA, i, uint64(n42))
 	i--
 	dAtA[i] = 0x3a
 	if len(m.Emails) > 0 {
@@ -9529,31 +9835,31 @@ func (m *Webhook) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	var l int
 	_ = l
 	if m.FirstSeenAt != nil {
-		n42, err42 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenAt):])
-		if err42 != nil {
-			return 0, err42
+		n43, err43 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenAt):])
+		if err43 != nil {
+			return 0, err43
 		}
-		i -= n42
-		i = encodeVarintUsers(dAtA, i, uint64(n42))
+		i -= n43
+		i = encodeVarintUsers(dAtA, i, uint64(n43))
 		i--
 		dAtA[i] = 0x42
 	}
 	if m.DeletedAt != nil {
-		n43, err43 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.DeletedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.DeletedAt):])
-		if err43 != nil {
-			return 0, err43
+		n44, err44 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.DeletedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.DeletedAt):])
+		if err44 != nil {
+			return 0, err44
 		}
-		i -= n43
-		i = encodeVarintUsers(dAtA, i, uint64(n43))
+		i -= n44
+		i = encodeVarintUsers(dAtA, i, uint64(n44))
 		i--
 		dAtA[i] = 0x3a
 	}
-	n44, err44 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
-	if err44 != nil {
-		return 0, err44
+	n45, err45 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
+	if err45 != nil {
+		return 0, err45
 	}
-	i -= n44
-	i = encodeVarintUsers(dAtA, i, uint64(n44))
+	i -= n45
+	i = encodeVarintUsers(dAtA, i, uint64(n45))
 	i--
 	dAtA[i] = 0x32
 	if len(m.SecretSigningKey) > 0 {
@@ -9594,6 +9900,111 @@ func (m *Webhook) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *APIToken) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *APIToken) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *APIToken) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.RevokedAt != nil {
+		n46, err46 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.RevokedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.RevokedAt):])
+		if err46 != nil {
+			return 0, err46
+		}
+		i -= n46
+		i = encodeVarintUsers(dAtA, i, uint64(n46))
+		i--
+		dAtA[i] = 0x52
+	}
+	if m.LastUsedAt != nil {
+		n47, err47 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastUsedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastUsedAt):])
+		if err47 != nil {
+			return 0, err47
+		}
+		i -= n47
+		i = encodeVarintUsers(dAtA, i, uint64(n47))
+		i--
+		dAtA[i] = 0x4a
+	}
+	if m.ExpiresAt != nil {
+		n48, err48 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.ExpiresAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.ExpiresAt):])
+		if err48 != nil {
+			return 0, err48
+		}
+		i -= n48
+		i = encodeVarintUsers(dAtA, i, uint64(n48))
+		i--
+		dAtA[i] = 0x42
+	}
+	n49, err49 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.CreatedAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt):])
+	if err49 != nil {
+		return 0, err49
+	}
+	i -= n49
+	i = encodeVarintUsers(dAtA, i, uint64(n49))
+	i--
+	dAtA[i] = 0x3a
+	if len(m.CreatedBy) > 0 {
+		i -= len(m.CreatedBy)
+		copy(dAtA[i:], m.CreatedBy)
+		i = encodeVarintUsers(dAtA, i, uint64(len(m.CreatedBy)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Scopes) > 0 {
+		for iNdEx := len(m.Scopes) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Scopes[iNdEx])
+			copy(dAtA[i:], m.Scopes[iNdEx])
+			i = encodeVarintUsers(dAtA, i, uint64(len(m.Scopes[iNdEx])))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if len(m.Token) > 0 {
+		i -= len(m.Token)
+		copy(dAtA[i:], m.Token)
+		i = encodeVarintUsers(dAtA, i, uint64(len(m.Token)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintUsers(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.OrganizationID) > 0 {
+		i -= len(m.OrganizationID)
+		copy(dAtA[i:], m.OrganizationID)
+		i = encodeVarintUsers(dAtA, i, uint64(len(m.OrganizationID)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.ID) > 0 {
+		i -= len(m.ID)
+		copy(dAtA[i:], m.ID)
+		i = encodeVarintUsers(dAtA, i, uint64(len(m.ID)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *LookupOrganizationWebhookUsingSecretIDRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -9710,12 +10121,12 @@ func (m *SetOrganizationWebhookFirstSeenAtResponse) MarshalToSizedBuffer(dAtA []
 	var l int
 	_ = l
 	if m.FirstSeenAt != nil {
-		n46, err46 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenAt):])
-		if err46 != nil {
-			return 0, err46
+		n51, err51 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.FirstSeenAt, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.FirstSeenAt):])
+		if err51 != nil {
+			return 0, err51
 		}
-		i -= n46
-		i = encodeVarintUsers(dAtA, i, uint64(n46))
+		i -= n51
+		i = encodeVarintUsers(dAtA, i, uint64(n51))
 		i--
 		dAtA[i] = 0xa
 	}
@@ -9816,6 +10227,13 @@ func (m *Role) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.TeamID) > 0 {
+		i -= len(m.TeamID)
+		copy(dAtA[i:], m.TeamID)
+		i = encodeVarintUsers(dAtA, i, uint64(len(m.TeamID)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if len(m.Description) > 0 {
 		i -= len(m.Description)
 		copy(dAtA[i:], m.Description)
@@ -10027,6 +10445,7 @@ func NewPopulatedLookupUsingTokenRequest(r randyUsers, easy bool) *LookupUsingTo
 	this := &LookupUsingTokenRequest{}
 	this.Token = string(randStringUsers(r))
 	this.AuthorizeFor = AuthorizedAction([]int32{0, 1, 2}[r.Intn(3)])
+	this.Service = string(randStringUsers(r))
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -10040,6 +10459,9 @@ func NewPopulatedLookupUsingTokenResponse(r randyUsers, easy bool) *LookupUsingT
 	for i := 0; i < v2; i++ {
 		this.FeatureFlags[i] = string(randStringUsers(r))
 	}
+	if r.Intn(5) != 0 {
+		this.TokenExpiresAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	}
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -10109,6 +10531,11 @@ func NewPopulatedGetBillableOrganizationsRequest(r randyUsers, easy bool) *GetBi
 	this := &GetBillableOrganizationsRequest{}
 	v5 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
 	this.Now = *v5
+	v6 := r.Intn(10)
+	this.TeamIDs = make([]string, v6)
+	for i := 0; i < v6; i++ {
+		this.TeamIDs[i] = string(randStringUsers(r))
+	}
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -10117,11 +10544,11 @@ func NewPopulatedGetBillableOrganizationsRequest(r randyUsers, easy bool) *GetBi
 func NewPopulatedGetBillableOrganizationsResponse(r randyUsers, easy bool) *GetBillableOrganizationsResponse {
 	this := &GetBillableOrganizationsResponse{}
 	if r.Intn(5) != 0 {
-		v6 := r.Intn(5)
-		this.Organizations = make([]Organization, v6)
-		for i := 0; i < v6; i++ {
-			v7 := NewPopulatedOrganization(r, easy)
-			this.Organizations[i] = *v7
+		v7 := r.Intn(5)
+		this.Organizations = make([]Organization, v7)
+		for i := 0; i < v7; i++ {
+			v8 := NewPopulatedOrganization(r, easy)
+			this.Organizations[i] = *v8
 		}
 	}
 	if !easy && r.Intn(10) != 0 {
@@ -10131,8 +10558,8 @@ func NewPopulatedGetBillableOrganizationsResponse(r randyUsers, easy bool) *GetB
 
 func NewPopulatedGetTrialOrganizationsRequest(r randyUsers, easy bool) *GetTrialOrganizationsRequest {
 	this := &GetTrialOrganizationsRequest{}
-	v8 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.Now = *v8
+	v9 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.Now = *v9
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -10141,11 +10568,11 @@ func NewPopulatedGetTrialOrganizationsRequest(r randyUsers, easy bool) *GetTrial
 func NewPopulatedGetTrialOrganizationsResponse(r randyUsers, easy bool) *GetTrialOrganizationsResponse {
 	this := &GetTrialOrganizationsResponse{}
 	if r.Intn(5) != 0 {
-		v9 := r.Intn(5)
-		this.Organizations = make([]Organization, v9)
-		for i := 0; i < v9; i++ {
-			v10 := NewPopulatedOrganization(r, easy)
-			this.Organizations[i] = *v10
+		v10 := r.Intn(5)
+		this.Organizations = make([]Organization, v10)
+		for i := 0; i < v10; i++ {
+			v11 := NewPopulatedOrganization(r, easy)
+			this.Organizations[i] = *v11
 		}
 	}
 	if !easy && r.Intn(10) != 0 {
@@ -10155,8 +10582,8 @@ func NewPopulatedGetTrialOrganizationsResponse(r randyUsers, easy bool) *GetTria
 
 func NewPopulatedGetDelinquentOrganizationsRequest(r randyUsers, easy bool) *GetDelinquentOrganizationsRequest {
 	this := &GetDelinquentOrganizationsRequest{}
-	v11 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.Now = *v11
+	v12 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.Now = *v12
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -10165,11 +10592,11 @@ func NewPopulatedGetDelinquentOrganizationsRequest(r randyUsers, easy bool) *Get
 func NewPopulatedGetDelinquentOrganizationsResponse(r randyUsers, easy bool) *GetDelinquentOrganizationsResponse {
 	this := &GetDelinquentOrganizationsResponse{}
 	if r.Intn(5) != 0 {
-		v12 := r.Intn(5)
-		this.Organizations = make([]Organization, v12)
-		for i := 0; i < v12; i++ {
-			v13 := NewPopulatedOrganization(r, easy)
-			this.Organizations[i] = *v13
+		v13 := r.Intn(5)
+		this.Organizations = make([]Organization, v13)
+		for i := 0; i < v13; i++ {
+			v14 := NewPopulatedOrganization(r, easy)
+			this.Organizations[i] = *v14
 		}
 	}
 	if !easy && r.Intn(10) != 0 {
@@ -10210,8 +10637,8 @@ func NewPopulatedGetOrganizationRequest_InternalID(r randyUsers, easy bool) *Get
 }
 func NewPopulatedGetOrganizationResponse(r randyUsers, easy bool) *GetOrganizationResponse {
 	this := &GetOrganizationResponse{}
-	v14 := NewPopulatedOrganization(r, easy)
-	this.Organization = *v14
+	v15 := NewPopulatedOrganization(r, easy)
+	this.Organization = *v15
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -10223,11 +10650,11 @@ func NewPopulatedOrganization(r randyUsers, easy bool) *Organization {
 	this.ExternalID = string(randStringUsers(r))
 	this.Name = string(randStringUsers(r))
 	this.ProbeToken = string(randStringUsers(r))
-	v15 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.CreatedAt = *v15
-	v16 := r.Intn(10)
-	this.FeatureFlags = make([]string, v16)
-	for i := 0; i < v16; i++ {
+	v16 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.CreatedAt = *v16
+	v17 := r.Intn(10)
+	this.FeatureFlags = make([]string, v17)
+	for i := 0; i < v17; i++ {
 		this.FeatureFlags[i] = string(randStringUsers(r))
 	}
 	this.RefuseDataAccess = bool(bool(r.Intn(2) == 0))
@@ -10237,8 +10664,8 @@ func NewPopulatedOrganization(r randyUsers, easy bool) *Organization {
 	}
 	this.Platform = string(randStringUsers(r))
 	this.Environment = string(randStringUsers(r))
-	v17 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.TrialExpiresAt = *v17
+	v18 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.TrialExpiresAt = *v18
 	this.ZuoraAccountNumber = string(randStringUsers(r))
 	if r.Intn(5) != 0 {
 		this.ZuoraAccountCreatedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
@@ -10254,8 +10681,8 @@ func NewPopulatedOrganization(r randyUsers, easy bool) *Organization {
 	}
 	this.TeamID = string(randStringUsers(r))
 	this.TeamExternalID = string(randStringUsers(r))
-	v18 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.DeletedAt = *v18
+	v19 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.DeletedAt = *v19
 	this.Cleanup = bool(bool(r.Intn(2) == 0))
 	if r.Intn(5) != 0 {
 		this.FirstSeenFluxConnectedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
@@ -10285,8 +10712,8 @@ func NewPopulatedGoogleCloudPlatform(r randyUsers, easy bool) *GoogleCloudPlatfo
 	this.ID = string(randStringUsers(r))
 	this.ExternalAccountID = string(randStringUsers(r))
 	this.Activated = bool(bool(r.Intn(2) == 0))
-	v19 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.CreatedAt = *v19
+	v20 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.CreatedAt = *v20
 	this.ConsumerID = string(randStringUsers(r))
 	this.SubscriptionName = string(randStringUsers(r))
 	this.SubscriptionLevel = string(randStringUsers(r))
@@ -10306,8 +10733,8 @@ func NewPopulatedGetGCPRequest(r randyUsers, easy bool) *GetGCPRequest {
 
 func NewPopulatedGetGCPResponse(r randyUsers, easy bool) *GetGCPResponse {
 	this := &GetGCPResponse{}
-	v20 := NewPopulatedGoogleCloudPlatform(r, easy)
-	this.GCP = *v20
+	v21 := NewPopulatedGoogleCloudPlatform(r, easy)
+	this.GCP = *v21
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -10376,8 +10803,8 @@ func NewPopulatedGetUserRequest(r randyUsers, easy bool) *GetUserRequest {
 
 func NewPopulatedGetUserResponse(r randyUsers, easy bool) *GetUserResponse {
 	this := &GetUserResponse{}
-	v21 := NewPopulatedUser(r, easy)
-	this.User = *v21
+	v22 := NewPopulatedUser(r, easy)
+	this.User = *v22
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -10385,8 +10812,8 @@ func NewPopulatedGetUserResponse(r randyUsers, easy bool) *GetUserResponse {
 
 func NewPopulatedGetOrganizationsReadyForWeeklyReportRequest(r randyUsers, easy bool) *GetOrganizationsReadyForWeeklyReportRequest {
 	this := &GetOrganizationsReadyForWeeklyReportRequest{}
-	v22 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.Now = *v22
+	v23 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.Now = *v23
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -10395,11 +10822,11 @@ func NewPopulatedGetOrganizationsReadyForWeeklyReportRequest(r randyUsers, easy
 func NewPopulatedGetOrganizationsReadyForWeeklyReportResponse(r randyUsers, easy bool) *GetOrganizationsReadyForWeeklyReportResponse {
 	this := &GetOrganizationsReadyForWeeklyReportResponse{}
 	if r.Intn(5) != 0 {
-		v23 := r.Intn(5)
-		this.Organizations = make([]Organization, v23)
-		for i := 0; i < v23; i++ {
-			v24 := NewPopulatedOrganization(r, easy)
-			this.Organizations[i] = *v24
+		v24 := r.Intn(5)
+		this.Organizations = make([]Organization, v24)
+		for i := 0; i < v24; i++ {
+			v25 := NewPopulatedOrganization(r, easy)
+			this.Organizations[i] = *v25
 		}
 	}
 	if !easy && r.Intn(10) != 0 {
@@ -10409,8 +10836,8 @@ func NewPopulatedGetOrganizationsReadyForWeeklyReportResponse(r randyUsers, easy
 
 func NewPopulatedSendOutWeeklyReportRequest(r randyUsers, easy bool) *SendOutWeeklyReportRequest {
 	this := &SendOutWeeklyReportRequest{}
-	v25 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.Now = *v25
+	v26 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.Now = *v26
 	this.ExternalID = string(randStringUsers(r))
 	if !easy && r.Intn(10) != 0 {
 	}
@@ -10429,15 +10856,15 @@ func NewPopulatedUser(r randyUsers, easy bool) *User {
 	this.ID = string(randStringUsers(r))
 	this.Email = string(randStringUsers(r))
 	this.Token = string(randStringUsers(r))
-	v26 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.TokenCreatedAt = *v26
 	v27 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.FirstLoginAt = *v27
+	this.TokenCreatedAt = *v27
 	v28 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.CreatedAt = *v28
-	this.Admin = bool(bool(r.Intn(2) == 0))
+	this.FirstLoginAt = *v28
 	v29 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.LastLoginAt = *v29
+	this.CreatedAt = *v29
+	this.Admin = bool(bool(r.Intn(2) == 0))
+	v30 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.LastLoginAt = *v30
 	this.Company = string(randStringUsers(r))
 	this.Name = string(randStringUsers(r))
 	this.FirstName = string(randStringUsers(r))
@@ -10501,16 +10928,16 @@ func NewPopulatedTeam(r randyUsers, easy bool) *Team {
 	if r.Intn(5) != 0 {
 		this.ZuoraAccountCreatedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
 	}
-	v30 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.TrialExpiresAt = *v30
+	v31 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.TrialExpiresAt = *v31
 	if r.Intn(5) != 0 {
 		this.TrialPendingExpiryNotifiedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
 	}
 	if r.Intn(5) != 0 {
 		this.TrialExpiredNotifiedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
 	}
-	v31 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.CreatedAt = *v31
+	v32 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.CreatedAt = *v32
 	if r.Intn(5) != 0 {
 		this.DeletedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
 	}
@@ -10529,9 +10956,9 @@ func NewPopulatedEmpty(r randyUsers, easy bool) *Empty {
 func NewPopulatedSummary(r randyUsers, easy bool) *Summary {
 	this := &Summary{}
 	if r.Intn(5) != 0 {
-		v32 := r.Intn(5)
-		this.Entries = make([]*SummaryEntry, v32)
-		for i := 0; i < v32; i++ {
+		v33 := r.Intn(5)
+		this.Entries = make([]*SummaryEntry, v33)
+		for i := 0; i < v33; i++ {
 			this.Entries[i] = NewPopulatedSummaryEntry(r, easy)
 		}
 	}
@@ -10547,20 +10974,20 @@ func NewPopulatedSummaryEntry(r randyUsers, easy bool) *SummaryEntry {
 	this.OrgID = string(randStringUsers(r))
 	this.OrgExternalID = string(randStringUsers(r))
 	this.OrgName = string(randStringUsers(r))
-	v33 := r.Intn(10)
-	this.Emails = make([]string, v33)
-	for i := 0; i < v33; i++ {
+	v34 := r.Intn(10)
+	this.Emails = make([]string, v34)
+	for i := 0; i < v34; i++ {
 		this.Emails[i] = string(randStringUsers(r))
 	}
-	v34 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.OrgCreatedAt = *v34
+	v35 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.OrgCreatedAt = *v35
 	if r.Intn(5) != 0 {
 		this.FirstSeenConnectedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
 	}
 	this.Platform = string(randStringUsers(r))
 	this.Environment = string(randStringUsers(r))
-	v35 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.TrialExpiresAt = *v35
+	v36 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.TrialExpiresAt = *v36
 	if r.Intn(5) != 0 {
 		this.TrialPendingExpiryNotifiedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
 	}
@@ -10575,8 +11002,8 @@ func NewPopulatedSummaryEntry(r randyUsers, easy bool) *SummaryEntry {
 		this.ZuoraAccountCreatedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
 	}
 	this.GCPAccountExternalID = string(randStringUsers(r))
-	v36 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.GCPAccountCreatedAt = *v36
+	v37 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.GCPAccountCreatedAt = *v37
 	this.GCPAccountSubscriptionLevel = string(randStringUsers(r))
 	this.GCPAccountSubscriptionStatus = string(randStringUsers(r))
 	if !easy && r.Intn(10) != 0 {
@@ -10591,8 +11018,8 @@ func NewPopulatedWebhook(r randyUsers, easy bool) *Webhook {
 	this.IntegrationType = string(randStringUsers(r))
 	this.SecretID = string(randStringUsers(r))
 	this.SecretSigningKey = string(randStringUsers(r))
-	v37 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
-	this.CreatedAt = *v37
+	v38 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.CreatedAt = *v38
 	if r.Intn(5) != 0 {
 		this.DeletedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
 	}
@@ -10604,6 +11031,34 @@ func NewPopulatedWebhook(r randyUsers, easy bool) *Webhook {
 	return this
 }
 
+func NewPopulatedAPIToken(r randyUsers, easy bool) *APIToken {
+	this := &APIToken{}
+	this.ID = string(randStringUsers(r))
+	this.OrganizationID = string(randStringUsers(r))
+	this.Name = string(randStringUsers(r))
+	this.Token = string(randStringUsers(r))
+	v39 := r.Intn(10)
+	this.Scopes = make([]string, v39)
+	for i := 0; i < v39; i++ {
+		this.Scopes[i] = string(randStringUsers(r))
+	}
+	this.CreatedBy = string(randStringUsers(r))
+	v40 := github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	this.CreatedAt = *v40
+	if r.Intn(5) != 0 {
+		this.ExpiresAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	}
+	if r.Intn(5) != 0 {
+		this.LastUsedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	}
+	if r.Intn(5) != 0 {
+		this.RevokedAt = github_com_gogo_protobuf_types.NewPopulatedStdTime(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+	}
+	return this
+}
+
 func NewPopulatedLookupOrganizationWebhookUsingSecretIDRequest(r randyUsers, easy bool) *LookupOrganizationWebhookUsingSecretIDRequest {
 	this := &LookupOrganizationWebhookUsingSecretIDRequest{}
 	this.SecretID = string(randStringUsers(r))
@@ -10663,6 +11118,7 @@ func NewPopulatedRole(r randyUsers, easy bool) *Role {
 	this.ID = string(randStringUsers(r))
 	this.Name = string(randStringUsers(r))
 	this.Description = string(randStringUsers(r))
+	this.TeamID = string(randStringUsers(r))
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -10740,9 +11196,9 @@ func randUTF8RuneUsers(r randyUsers) rune {
 	return rune(ru + 61)
 }
 func randStringUsers(r randyUsers) string {
-	v38 := r.Intn(100)
-	tmps := make([]rune, v38)
-	for i := 0; i < v38; i++ {
+	v41 := r.Intn(100)
+	tmps := make([]rune, v41)
+	for i := 0; i < v41; i++ {
 		tmps[i] = randUTF8RuneUsers(r)
 	}
 	return string(tmps)
@@ -10764,11 +11220,11 @@ func randFieldUsers(dAtA []byte, r randyUsers, fieldNumber int, wire int) []byte
 	switch wire {
 	case 0:
 		dAtA = encodeVarintPopulateUsers(dAtA, uint64(key))
-		v39 := r.Int63()
+		v42 := r.Int63()
 		if r.Intn(2) == 0 {
-			v39 *= -1
+			v42 *= -1
 		}
-		dAtA = encodeVarintPopulateUsers(dAtA, uint64(v39))
+		dAtA = encodeVarintPopulateUsers(dAtA, uint64(v42))
 	case 1:
 		dAtA = encodeVarintPopulateUsers(dAtA, uint64(key))
 		dAtA = append(dAtA, byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))
@@ -10849,6 +11305,10 @@ func (m *LookupUsingTokenRequest) Size() (n int) {
 	if m.AuthorizeFor != 0 {
 		n += 1 + sovUsers(uint64(m.AuthorizeFor))
 	}
+	l = len(m.Service)
+	if l > 0 {
+		n += 1 + l + sovUsers(uint64(l))
+	}
 	return n
 }
 
@@ -10868,6 +11328,10 @@ func (m *LookupUsingTokenResponse) Size() (n int) {
 			n += 1 + l + sovUsers(uint64(l))
 		}
 	}
+	if m.TokenExpiresAt != nil {
+		l = github_com_gogo_protobuf_types.SizeOfStdTime(*m.TokenExpiresAt)
+		n += 1 + l + sovUsers(uint64(l))
+	}
 	return n
 }
 
@@ -10965,6 +11429,12 @@ func (m *GetBillableOrganizationsRequest) Size() (n int) {
 	_ = l
 	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.Now)
 	n += 1 + l + sovUsers(uint64(l))
+	if len(m.TeamIDs) > 0 {
+		for _, s := range m.TeamIDs {
+			l = len(s)
+			n += 1 + l + sovUsers(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -11735,39 +12205,88 @@ func (m *Webhook) Size() (n int) {
 	return n
 }
 
-func (m *LookupOrganizationWebhookUsingSecretIDRequest) Size() (n int) {
+func (m *APIToken) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	l = len(m.SecretID)
+	l = len(m.ID)
 	if l > 0 {
 		n += 1 + l + sovUsers(uint64(l))
 	}
-	return n
-}
-
-func (m *LookupOrganizationWebhookUsingSecretIDResponse) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.Webhook != nil {
-		l = m.Webhook.Size()
+	l = len(m.OrganizationID)
+	if l > 0 {
 		n += 1 + l + sovUsers(uint64(l))
 	}
-	return n
-}
-
-func (m *SetOrganizationWebhookFirstSeenAtRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	l = len(m.SecretID)
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovUsers(uint64(l))
+	}
+	l = len(m.Token)
+	if l > 0 {
+		n += 1 + l + sovUsers(uint64(l))
+	}
+	if len(m.Scopes) > 0 {
+		for _, s := range m.Scopes {
+			l = len(s)
+			n += 1 + l + sovUsers(uint64(l))
+		}
+	}
+	l = len(m.CreatedBy)
+	if l > 0 {
+		n += 1 + l + sovUsers(uint64(l))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.CreatedAt)
+	n += 1 + l + sovUsers(uint64(l))
+	if m.ExpiresAt != nil {
+		l = github_com_gogo_protobuf_types.SizeOfStdTime(*m.ExpiresAt)
+		n += 1 + l + sovUsers(uint64(l))
+	}
+	if m.LastUsedAt != nil {
+		l = github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastUsedAt)
+		n += 1 + l + sovUsers(uint64(l))
+	}
+	if m.RevokedAt != nil {
+		l = github_com_gogo_protobuf_types.SizeOfStdTime(*m.RevokedAt)
+		n += 1 + l + sovUsers(uint64(l))
+	}
+	return n
+}
+
+func (m *LookupOrganizationWebhookUsingSecretIDRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.SecretID)
+	if l > 0 {
+		n += 1 + l + sovUsers(uint64(l))
+	}
+	return n
+}
+
+func (m *LookupOrganizationWebhookUsingSecretIDResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Webhook != nil {
+		l = m.Webhook.Size()
+		n += 1 + l + sovUsers(uint64(l))
+	}
+	return n
+}
+
+func (m *SetOrganizationWebhookFirstSeenAtRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.SecretID)
 	if l > 0 {
 		n += 1 + l + sovUsers(uint64(l))
 	}
@@ -11839,6 +12358,10 @@ func (m *Role) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovUsers(uint64(l))
 	}
+	l = len(m.TeamID)
+	if l > 0 {
+		n += 1 + l + sovUsers(uint64(l))
+	}
 	return n
 }
 
@@ -11960,6 +12483,7 @@ func (this *LookupUsingTokenRequest) String() string {
 	s := strings.Join([]string{`&LookupUsingTokenRequest{`,
 		`Token:` + fmt.Sprintf("%v", this.Token) + `,`,
 		`AuthorizeFor:` + fmt.Sprintf("%v", this.AuthorizeFor) + `,`,
+		`Service:` + fmt.Sprintf("%v", this.Service) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -11971,6 +12495,7 @@ func (this *LookupUsingTokenResponse) String() string {
 	s := strings.Join([]string{`&LookupUsingTokenResponse{`,
 		`OrganizationID:` + fmt.Sprintf("%v", this.OrganizationID) + `,`,
 		`FeatureFlags:` + fmt.Sprintf("%v", this.FeatureFlags) + `,`,
+		`TokenExpiresAt:` + strings.Replace(fmt.Sprintf("%v", this.TokenExpiresAt), "Timestamp", "timestamp.Timestamp", 1) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -12048,6 +12573,7 @@ func (this *GetBillableOrganizationsRequest) String() string {
 	}
 	s := strings.Join([]string{`&GetBillableOrganizationsRequest{`,
 		`Now:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Now), "Timestamp", "timestamp.Timestamp", 1), `&`, ``, 1) + `,`,
+		`TeamIDs:` + fmt.Sprintf("%v", this.TeamIDs) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -12537,6 +13063,25 @@ func (this *Webhook) String() string {
 	}, "")
 	return s
 }
+func (this *APIToken) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&APIToken{`,
+		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
+		`OrganizationID:` + fmt.Sprintf("%v", this.OrganizationID) + `,`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`Token:` + fmt.Sprintf("%v", this.Token) + `,`,
+		`Scopes:` + fmt.Sprintf("%v", this.Scopes) + `,`,
+		`CreatedBy:` + fmt.Sprintf("%v", this.CreatedBy) + `,`,
+		`CreatedAt:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.CreatedAt), "Timestamp", "timestamp.Timestamp", 1), `&`, ``, 1) + `,`,
+		`ExpiresAt:` + strings.Replace(fmt.Sprintf("%v", this.ExpiresAt), "Timestamp", "timestamp.Timestamp", 1) + `,`,
+		`LastUsedAt:` + strings.Replace(fmt.Sprintf("%v", this.LastUsedAt), "Timestamp", "timestamp.Timestamp", 1) + `,`,
+		`RevokedAt:` + strings.Replace(fmt.Sprintf("%v", this.RevokedAt), "Timestamp", "timestamp.Timestamp", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *LookupOrganizationWebhookUsingSecretIDRequest) String() string {
 	if this == nil {
 		return "nil"
@@ -12607,6 +13152,7 @@ func (this *Role) String() string {
 		`ID:` + fmt.Sprintf("%v", this.ID) + `,`,
 		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
 		`Description:` + fmt.Sprintf("%v", this.Description) + `,`,
+		`TeamID:` + fmt.Sprintf("%v", this.TeamID) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -12801,7 +13347,10 @@ func (m *LookupOrgRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -12947,7 +13496,10 @@ func (m *LookupOrgResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13042,13 +13594,48 @@ func (m *LookupUsingTokenRequest) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Service", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Service = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipUsers(dAtA[iNdEx:])
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13156,13 +13743,52 @@ func (m *LookupUsingTokenResponse) Unmarshal(dAtA []byte) error {
 			}
 			m.FeatureFlags = append(m.FeatureFlags, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TokenExpiresAt", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.TokenExpiresAt == nil {
+				m.TokenExpiresAt = new(time.Time)
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(m.TokenExpiresAt, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipUsers(dAtA[iNdEx:])
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13244,7 +13870,10 @@ func (m *LookupAdminRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13326,7 +13955,10 @@ func (m *LookupAdminResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13408,7 +14040,10 @@ func (m *LookupUserRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13490,7 +14125,10 @@ func (m *LookupUserResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13611,7 +14249,10 @@ func (m *GetOrganizationsRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13695,7 +14336,10 @@ func (m *GetOrganizationsResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13772,13 +14416,48 @@ func (m *GetBillableOrganizationsRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TeamIDs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TeamIDs = append(m.TeamIDs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipUsers(dAtA[iNdEx:])
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13862,7 +14541,10 @@ func (m *GetBillableOrganizationsResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -13945,7 +14627,10 @@ func (m *GetTrialOrganizationsRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -14029,7 +14714,10 @@ func (m *GetTrialOrganizationsResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -14112,7 +14800,10 @@ func (m *GetDelinquentOrganizationsRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -14196,7 +14887,10 @@ func (m *GetDelinquentOrganizationsResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -14342,7 +15036,10 @@ func (m *GetOrganizationRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -14425,7 +15122,10 @@ func (m *GetOrganizationResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -15410,7 +16110,10 @@ func (m *Organization) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -15705,7 +16408,10 @@ func (m *GoogleCloudPlatform) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -15787,7 +16493,10 @@ func (m *GetGCPRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -15870,7 +16579,10 @@ func (m *GetGCPResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -15956,7 +16668,10 @@ func (m *UpdateGCPRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16006,7 +16721,10 @@ func (m *UpdateGCPResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16156,7 +16874,10 @@ func (m *SetOrganizationZuoraAccountRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16206,7 +16927,10 @@ func (m *SetOrganizationZuoraAccountResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16340,7 +17064,10 @@ func (m *SetOrganizationFlagRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16390,7 +17117,10 @@ func (m *SetOrganizationFlagResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16472,7 +17202,10 @@ func (m *GetUserRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16555,7 +17288,10 @@ func (m *GetUserResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16638,7 +17374,10 @@ func (m *GetOrganizationsReadyForWeeklyReportRequest) Unmarshal(dAtA []byte) err
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16722,7 +17461,10 @@ func (m *GetOrganizationsReadyForWeeklyReportResponse) Unmarshal(dAtA []byte) er
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16837,7 +17579,10 @@ func (m *SendOutWeeklyReportRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -16887,7 +17632,10 @@ func (m *SendOutWeeklyReportResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -17313,7 +18061,10 @@ func (m *User) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -17395,7 +18146,10 @@ func (m *NotifyTrialPendingExpiryRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -17445,7 +18199,10 @@ func (m *NotifyTrialPendingExpiryResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -17527,7 +18284,10 @@ func (m *NotifyTrialExpiredRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -17577,7 +18337,10 @@ func (m *NotifyTrialExpiredResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -17659,7 +18422,10 @@ func (m *NotifyRefuseDataUploadRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -17709,7 +18475,10 @@ func (m *NotifyRefuseDataUploadResponse) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -18097,7 +18866,10 @@ func (m *Team) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -18147,7 +18919,10 @@ func (m *Empty) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -18231,7 +19006,10 @@ func (m *Summary) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -18968,10 +19746,13 @@ func (m *SummaryEntry) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
 				return ErrInvalidLengthUsers
 			}
-			if (iNdEx + skippy) > l {
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
 			iNdEx += skippy
@@ -19283,7 +20064,396 @@ func (m *Webhook) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *APIToken) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowUsers
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: APIToken: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: APIToken: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OrganizationID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.OrganizationID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Token", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Token = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Scopes", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Scopes = append(m.Scopes, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CreatedBy", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CreatedBy = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CreatedAt", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.CreatedAt, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpiresAt", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ExpiresAt == nil {
+				m.ExpiresAt = new(time.Time)
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(m.ExpiresAt, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastUsedAt", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LastUsedAt == nil {
+				m.LastUsedAt = new(time.Time)
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(m.LastUsedAt, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RevokedAt", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.RevokedAt == nil {
+				m.RevokedAt = new(time.Time)
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(m.RevokedAt, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipUsers(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -19365,7 +20535,10 @@ func (m *LookupOrganizationWebhookUsingSecretIDRequest) Unmarshal(dAtA []byte) e
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -19451,7 +20624,10 @@ func (m *LookupOrganizationWebhookUsingSecretIDResponse) Unmarshal(dAtA []byte)
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -19533,7 +20709,10 @@ func (m *SetOrganizationWebhookFirstSeenAtRequest) Unmarshal(dAtA []byte) error
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -19619,7 +20798,10 @@ func (m *SetOrganizationWebhookFirstSeenAtResponse) Unmarshal(dAtA []byte) error
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -19701,7 +20883,10 @@ func (m *InformOrganizationBillingConfiguredRequest) Unmarshal(dAtA []byte) erro
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -19847,7 +21032,10 @@ func (m *Permission) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -19987,13 +21175,48 @@ func (m *Role) Unmarshal(dAtA []byte) error {
 			}
 			m.Description = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TeamID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowUsers
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthUsers
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TeamID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipUsers(dAtA[iNdEx:])
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -20171,7 +21394,10 @@ func (m *RequireTeamMemberPermissionToRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {
@@ -20349,7 +21575,10 @@ func (m *RequireOrgMemberPermissionToRequest) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
+			if skippy < 0 {
+				return ErrInvalidLengthUsers
+			}
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthUsers
 			}
 			if (iNdEx + skippy) > l {